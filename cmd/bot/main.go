@@ -1,134 +1,486 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"math/rand"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/fardannozami/whatsapp-gateway/internal/app/customcmd"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/humanize"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/loadtest"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/metrics"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/scheduler"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/supervisor"
 	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
 	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/airtable"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/eventbus"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/httpapi"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/mailer"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/media"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/mqtt"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/oauth"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/pdf"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/queue"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/redis"
 	"github.com/fardannozami/whatsapp-gateway/internal/infra/repository"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/s3"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sentry"
 	"github.com/fardannozami/whatsapp-gateway/internal/infra/wa"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/webhook"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/xlsx"
 
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 	walog "go.mau.fi/whatsmeow/util/log"
+	"golang.org/x/term"
 )
 
 func main() {
 	// 1. Load Config
 	cfg := config.Load()
 
+	// "bot tenants" manages the tenant registry. Once tenants exist, "bot"
+	// runs each of them as a child process with TENANT set, and every
+	// command for a tenant's bot is run with TENANT set as well.
+	if len(os.Args) > 1 && os.Args[1] == "tenants" {
+		if err := manageTenants(usecase.NewManageTenantsUsecase(repository.NewTenantRepository(cfg)), os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if cfg.Tenant != "" {
+		cfg = tenantConfig(cfg)
+	} else if len(os.Args) == 1 && runTenants(cfg) {
+		return
+	}
+
+	// "bot loadtest" runs on its own SQLite file, never on the real database
+	var loadTest *loadTestOptions
+	if len(os.Args) > 1 && os.Args[1] == "loadtest" {
+		opts, err := parseLoadTestFlags(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		loadTest = opts
+		cfg.DBDriver, cfg.SQLitePath, cfg.WASessionPath, cfg.SupabaseURL, cfg.SupabaseKey = "sqlite", opts.db, opts.db, "", ""
+		cfg.RedisURL, cfg.EventsURL, cfg.MQTTURL, cfg.AirtableKey = "", "", "", ""
+	}
+
 	// 2. Logger
+	var reporter *sentry.Reporter
+	if cfg.SentryDSN != "" {
+		var err error
+		if reporter, err = sentry.New(cfg.SentryDSN, cfg.Tenant); err != nil {
+			log.Printf("Error reporting disabled: %v", err)
+		}
+	}
 	logger := walog.Stdout("Client", "INFO", true)
 
 	// 3. Database & Repositories
-	repo := repository.NewReportRepository(cfg)
+	repos := repository.NewRepositories(cfg)
+	repo := repos.Reports
+
+	// The challenge numbers the morning prompt and counts down in reports
+	challenge := domain.Challenge{Days: cfg.ChallengeDays}
+	if cfg.ChallengeStart != "" {
+		start, err := time.Parse("2006-01-02", cfg.ChallengeStart)
+		if err != nil {
+			log.Printf("Challenge start ignored: CHALLENGE_START must be YYYY-MM-DD")
+		}
+		challenge.Start = start
+	}
 
 	// 4. Use Cases
+	tieBreaks, err := usecase.ParseTieBreaks(cfg.TieBreaks)
+	if err != nil {
+		log.Fatalf("Invalid LEADERBOARD_TIEBREAK: %v", err)
+	}
+	// Everything that ranks the members shares one board
+	boards := usecase.NewBoardBuilder(repo, repos.Settings, tieBreaks)
+	if cfg.JWTSecret != "" && len(cfg.JWTSecret) < usecase.MinJWTSecretLength {
+		log.Fatalf("JWT_SECRET must be at least %d bytes, e.g. the output of: openssl rand -hex 32", usecase.MinJWTSecretLength)
+	}
+	// With USER_ID_SALT members are stored under a hash of their number
+	hasher := phone.NewHasher(cfg.UserIDSalt)
 	reportUC := usecase.NewReportActivityUsecase(repo)
+	reportUC.SetActivityRepository(repos.Activities)
+	reportUC.SetSettingsRepository(repos.Settings)
+	reportUC.SetEventRepository(repos.Events)
+	reportUC.SetTransactor(repos.Tx)
+	reportUC.SetOutbox(repos.Outbox, wa.NewMessageID)
+	reportUC.SetChallenge(challenge)
+	if cfg.CelebrationMedia != "" {
+		reportUC.SetCelebrations(media.New(cfg.CelebrationMedia))
+	}
+	if cfg.RewardsFile != "" {
+		rewards, err := loadRewardRules(cfg.RewardsFile)
+		if err != nil {
+			log.Printf("Reward rules disabled: %v", err)
+		} else {
+			reportUC.SetRewards(rewards, media.New(""))
+		}
+	}
+	// Data pipelines follow the reports on a message broker
+	var publishers usecase.EventPublishers
+	var eventPublisher eventbus.Publisher
+	if cfg.EventsURL != "" {
+		var err error
+		eventPublisher, err = eventbus.New(cfg.EventsURL, cfg.EventsTopic, cfg.Tenant)
+		if err != nil {
+			log.Fatalf("Failed to connect to the event broker: %v", err)
+		}
+		publishers = append(publishers, eventPublisher)
+	}
+	// Displays and Home Assistant follow the board on an MQTT broker, each
+	// tenant on its own topic. Only the bot runs the periodic check: reports
+	// handled by workers show up within a minute
+	var statePublisher *mqtt.Publisher
+	var publishStateUC *usecase.PublishStateUsecase
+	if cfg.MQTTURL != "" {
+		topic := cfg.MQTTTopic
+		if cfg.Tenant != "" {
+			topic += "/" + cfg.Tenant
+		}
+		var err error
+		statePublisher, err = mqtt.New(cfg.MQTTURL, topic, cfg.HADiscovery)
+		if err != nil {
+			log.Fatalf("Failed to connect to the MQTT broker: %v", err)
+		}
+		publishStateUC = usecase.NewPublishStateUsecase(repo, statePublisher)
+		publishStateUC.SetSettingsRepository(repos.Settings)
+		publishStateUC.SetBoards(boards)
+		publishStateUC.SetHomeAssistantPublisher(statePublisher)
+		publishers = append(publishers, publishStateUC)
+	}
+	// Organizers keep the reports in tools outside the bot, synced every
+	// minute by the bot and each worker
+	var syncUCs []*usecase.ExternalSyncUsecase
+	if cfg.AirtableKey != "" {
+		if cfg.AirtableBase == "" {
+			log.Fatal("AIRTABLE_BASE is required with AIRTABLE_API_KEY")
+		}
+		syncUCs = append(syncUCs, usecase.NewExternalSyncUsecase(
+			airtable.NewClient(airtable.DefaultBaseURL, cfg.AirtableKey, cfg.AirtableBase, cfg.AirtableReports, cfg.AirtableRanks), repo))
+	}
+	syncCtx, stopSync := context.WithCancel(context.Background())
+	for _, syncUC := range syncUCs {
+		syncUC.SetSettingsRepository(repos.Settings)
+		syncUC.SetBoards(boards)
+		publishers = append(publishers, syncUC)
+		go syncUC.Run(syncCtx, time.Minute)
+	}
+	// flushSyncs sends the reports still pending before the process exits
+	flushSyncs := func() {
+		stopSync()
+		for _, syncUC := range syncUCs {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			_ = syncUC.Sync(ctx, time.Now())
+			cancel()
+		}
+	}
+	if len(publishers) > 0 {
+		reportUC.SetEventPublisher(publishers)
+	}
 	leaderboardUC := usecase.NewGetLeaderboardUsecase(repo)
+	leaderboardUC.SetActivityRepository(repos.Activities)
+	leaderboardUC.SetSettingsRepository(repos.Settings)
+	leaderboardUC.SetTopSize(cfg.TopSize)
+	leaderboardUC.SetSnapshots(repos.Snapshots)
+	leaderboardUC.SetBoards(boards)
+	if cfg.LeaderboardLine != "" {
+		if err := leaderboardUC.SetLineTemplate(cfg.LeaderboardLine); err != nil {
+			log.Fatalf("Invalid LEADERBOARD_LINE: %v", err)
+		}
+	}
+	recapUC := usecase.NewGetRecapUsecase(repos.Activities)
+	recapUC.SetChallenge(challenge)
+	recapUC.SetSettingsRepository(repos.Settings)
+	finalReportUC := usecase.NewFinalReportUsecase(repo, repos.Activities, repos.Settings, pdf.NewRenderer())
+	finalReportUC.SetChallenge(challenge)
+	finalReportUC.SetBoards(boards)
+	spreadsheetUC := usecase.NewExportSpreadsheetUsecase(repo, repos.Activities, repos.Settings, xlsx.NewRenderer())
+	spreadsheetUC.SetChallenge(challenge)
+	spreadsheetUC.SetBoards(boards)
+	statsUC := usecase.NewGetStatsUsecase(repo, repos.Activities)
+	targetUC := usecase.NewSetTargetUsecase(repo, repos.Settings)
+	targetUC.SetChallenge(challenge, repos.Activities)
+	chartUC := usecase.NewGetChartUsecase(repos.Activities)
+	historyUC := usecase.NewGetHistoryUsecase(repos.Activities)
+	exportUC := usecase.NewExportUserDataUsecase(repo, repos.Activities, repos.Settings)
+	exportUC.SetEventRepository(repos.Events)
+	exportUC.SetIdentities(repos.Identities)
+	exportUC.SetSnapshots(repos.Snapshots)
+	deleteUC := usecase.NewDeleteUserDataUsecase(repo, repos.Activities, repos.Settings)
+	deleteUC.SetEventRepository(repos.Events)
+	deleteUC.SetIdentities(repos.Identities)
+	deleteUC.SetSnapshots(repos.Snapshots)
+	deleteUC.SetSessions(repos.Sessions)
+	pruneUC := usecase.NewPruneDataUsecase(repos.Activities, cfg.RetentionMonths)
+	pruneUC.SetOutbox(repos.Outbox)
+	pruneUC.SetSnapshots(repos.Snapshots)
+	searchUC := usecase.NewSearchArchiveUsecase(repos.Activities)
+	searchUC.SetSettingsRepository(repos.Settings)
+	if cfg.ArchiveMessages {
+		searchUC.SetMessageArchive(repos.Messages)
+		exportUC.SetMessageArchive(repos.Messages)
+		deleteUC.SetMessageArchive(repos.Messages)
+		pruneUC.SetMessageArchive(repos.Messages)
+	}
+	botMetrics := metrics.New()
+	botStatsUC := usecase.NewGetBotStatsUsecase(botMetrics)
+	if cfg.DBDriver == "sqlite" {
+		botStatsUC.SetDBPath(cfg.SQLitePath)
+	}
+	adminAuthUC := usecase.NewAdminAuthUsecase(repos.Admins, cfg.JWTSecret)
+	adminAuthUC.SetOAuthAllowlist(cfg.OAuthEmails)
+	apiKeyUC := usecase.NewAPIKeyUsecase(repos.APIKeys)
+	importUC := usecase.NewImportMembersUsecase(repo, repos.Settings)
+	importUC.SetEventRepository(repos.Events)
+	importUC.SetUserIDHasher(hasher)
+	eventsUC := usecase.NewReportEventsUsecase(repo, repos.Events)
+	eventsUC.SetActivityRepository(repos.Activities)
+	eventsUC.SetTransactor(repos.Tx)
+	seedUC := usecase.NewSeedDataUsecase(repo, repos.Activities)
+	profileUC := usecase.NewGetMemberProfileUsecase(repo, repos.Activities)
+	profileUC.SetSettingsRepository(repos.Settings)
+	profileUC.SetEventRepository(repos.Events)
+	profileUC.SetTransactor(repos.Tx)
 	handleMessageUC := usecase.NewHandleMessageUsecase(reportUC, leaderboardUC)
-
-	// 5. WhatsApp Service
-	waService := wa.NewService(cfg.SQLitePath, logger, cfg.SupabaseURL, cfg.SupabaseKey)
-
-	// 6. Register Message Handler
-	waService.SetMessageHandler(func(ctx context.Context, client *whatsmeow.Client, evt *events.Message) {
-		// Log all incoming messages with their Chat ID (useful for getting groupID)
-		fmt.Printf("[DEBUG] Incoming message from Chat ID: %s\n", evt.Info.Chat.String())
-
-		// Only handle messages from groups or specific sources if needed.
-		// For now, we filter by GroupID if configured.
-		if cfg.GroupID != "" && evt.Info.Chat.String() != cfg.GroupID {
-			return
+	handleMessageUC.SetRecapUsecase(recapUC)
+	handleMessageUC.SetStatsUsecase(statsUC)
+	handleMessageUC.SetTargetUsecase(targetUC)
+	handleMessageUC.SetSnoozeUsecase(usecase.NewSnoozeReminderUsecase(repos.Settings))
+	handleMessageUC.SetReminderUsecase(usecase.NewSetReminderUsecase(repos.Settings))
+	handleMessageUC.SetPrivateModeUsecase(usecase.NewSetPrivateModeUsecase(repos.Settings))
+	handleMessageUC.SetNoMentionUsecase(usecase.NewSetNoMentionUsecase(repos.Settings))
+	if cfg.SMTPURL != "" {
+		handleMessageUC.SetEmailUsecase(usecase.NewSetEmailUsecase(repos.Settings))
+	}
+	var calendarUC *usecase.CalendarUsecase
+	if cfg.CalendarURL != "" {
+		calendarUC = usecase.NewCalendarUsecase(repos.Settings, repos.Events, repos.Activities, cfg.CalendarURL)
+		handleMessageUC.SetCalendarUsecase(calendarUC)
+	}
+	if statePublisher != nil {
+		handleMessageUC.SetHomeAssistantUsecase(usecase.NewHomeAssistantUsecase(repo, statePublisher))
+	}
+	conversations := usecase.NewConversationManager(repos.Sessions)
+	handleMessageUC.SetConversations(conversations)
+	handleMessageUC.SetOnboardingUsecase(usecase.NewOnboardingUsecase(repos.Settings, conversations))
+	handleMessageUC.SetAliases(cfg.CommandAliases)
+	handleMessageUC.SetCommandPrefix(cfg.CommandPrefix)
+	handleMessageUC.SetSuggestions(cfg.SuggestCommands)
+	if cfg.CustomCommands != "" {
+		commands, err := customcmd.LoadFile(cfg.CustomCommands, repo, repos.Settings)
+		if err != nil {
+			log.Fatalf("Failed to load custom commands: %v", err)
 		}
-
-		// Ignore messages from self
-		if evt.Info.IsFromMe {
-			return
+		for _, cmd := range commands {
+			if err := handleMessageUC.RegisterCommand(cmd); err != nil {
+				log.Fatalf("Failed to load custom commands: %v", err)
+			}
 		}
+		log.Printf("Loaded %d custom commands from %s", len(commands), cfg.CustomCommands)
+	}
+	handleMessageUC.SetChartUsecase(chartUC)
+	handleMessageUC.SetHistoryUsecase(historyUC)
+	handleMessageUC.SetExportUsecase(exportUC)
+	handleMessageUC.SetDeleteUsecase(deleteUC)
+	handleMessageUC.SetBotStatsUsecase(botStatsUC)
+	handleMessageUC.SetSearchUsecase(searchUC)
+	handleMessageUC.SetMemberUsecase(profileUC)
+	handleMessageUC.SetUserIDHasher(hasher)
+	linkUC := usecase.NewLinkIdentityUsecase(repos.Identities)
+	linkUC.SetReportRepository(repo)
+	handleMessageUC.SetLinkUsecase(linkUC)
+	// Count #lapor sent before the bot was linked, or missed while it was
+	// down. It needs the archive, not the WhatsApp session, so workers have
+	// #admin backfill too.
+	backfillUC := usecase.NewBackfillReportsUsecase(eventsUC)
+	backfillUC.SetActivityRepository(repos.Activities)
+	backfillUC.SetUserIDHasher(hasher)
+	if cfg.ArchiveMessages {
+		backfillUC.SetMessageArchive(repos.Messages)
+		handleMessageUC.SetBackfillUsecase(backfillUC)
+	}
+	adminIDs := make([]string, 0, len(cfg.AdminIDs))
+	for _, id := range cfg.AdminIDs {
+		adminIDs = append(adminIDs, hasher.UserID(id))
+	}
+	handleMessageUC.SetAdmins(adminIDs)
 
-		// Get sender info - resolve LID to phone number for consistent user tracking
-		senderJID := evt.Info.Sender
-		var userID string
-		if senderJID.Server == "lid" || senderJID.Server == types.DefaultUserServer && len(senderJID.User) > 15 {
-			// Looks like a LID, try to resolve to phone number
-			userID = repo.ResolveLIDToPhone(ctx, senderJID.User)
-		} else {
-			// Already a phone number
-			userID = senderJID.User
+	// "bot worker" handles the messages queued on QUEUE_URL, without a
+	// WhatsApp session
+	if len(os.Args) > 1 && os.Args[1] == "worker" {
+		err := runWorker(cfg, repos, handleMessageUC, leaderboardUC, reportUC, botMetrics, reporter)
+		flushSyncs()
+		if eventPublisher != nil {
+			_ = eventPublisher.Close()
 		}
-
-		pushName := evt.Info.PushName
-		if pushName == "" {
-			pushName = "Unknown" // Fallback name
+		if statePublisher != nil {
+			_ = statePublisher.Close()
 		}
-
-		// Get message content
-		msg := ""
-		if evt.Message.Conversation != nil {
-			msg = *evt.Message.Conversation
-		} else if evt.Message.ExtendedTextMessage != nil && evt.Message.ExtendedTextMessage.Text != nil {
-			msg = *evt.Message.ExtendedTextMessage.Text
-		} else if evt.Message.ImageMessage != nil && evt.Message.ImageMessage.Caption != nil {
-			msg = *evt.Message.ImageMessage.Caption
-		} else if evt.Message.VideoMessage != nil && evt.Message.VideoMessage.Caption != nil {
-			msg = *evt.Message.VideoMessage.Caption
-		} else if evt.Message.DocumentMessage != nil && evt.Message.DocumentMessage.Caption != nil {
-			msg = *evt.Message.DocumentMessage.Caption
+		reporter.Flush(5 * time.Second)
+		if err != nil {
+			log.Fatal(err)
 		}
+		return
+	}
 
-		if msg == "" {
-			return
+	// 5. WhatsApp Service
+	// The session is kept in Supabase only when the data is
+	sessionURL, sessionKey := "", ""
+	if cfg.DBDriver == "supabase" {
+		sessionURL, sessionKey = cfg.SupabaseURL, cfg.SupabaseKey
+	}
+	waService := wa.NewService(cfg.WASessionPath, logger, sessionURL, sessionKey)
+	waService.SetGroupCacheTTL(time.Duration(cfg.GroupCacheTTL) * time.Minute)
+	waService.SetMaxMessageLength(cfg.MaxMessageLen)
+	waService.SetOutbox(repos.Outbox)
+	if hasher != nil {
+		waService.SetUserIDHasher(hasher, cfg.GroupID)
+		if cfg.WASessionPath == cfg.SQLitePath {
+			log.Println("Warning: USER_ID_SALT is set but the WhatsApp session, which holds members' numbers, is in SQLITE_PATH; set WA_SESSION_PATH to keep it in another file")
 		}
+	}
+	// The LID map belongs to the session
+	deleteUC.SetLIDMappings(waService)
+	humanizeSettings, err := humanize.Resolve(cfg.Humanize, humanizeOverrides(cfg))
+	if err != nil {
+		log.Fatalf("Invalid humanization settings: %v", err)
+	}
+	waService.SetPacing(wa.PacingProfile(humanizeSettings.PacingProfile()))
+	presence, _ := humanizeSettings.Presence()
+	if presence != nil {
+		waService.SetPresenceSchedule(presence)
+	}
+	botStatsUC.AddQueue("kirim", waService.Pacer().Waiting)
+	groupsUC := usecase.NewManageGroupsUsecase(repos.Groups, waService, cfg.GroupID)
+	if err := groupsUC.Load(context.Background()); err != nil {
+		log.Printf("Failed to load registered groups: %v", err)
+	}
+	groupsUC.SetLeaderboard(leaderboardUC, waService)
+	recapUC.SetGroupMembersGateway(waService)
+	handleMessageUC.SetGroupsUsecase(groupsUC)
 
-		fmt.Printf("Message from %s (%s): %s\n", pushName, userID, msg)
+	botStatsUC.SetConnectionCheck(func() bool {
+		return waService.GetClient() != nil && waService.GetClient().IsConnected()
+	})
 
-		// Execute Use Case
-		response, err := handleMessageUC.Execute(ctx, userID, pushName, msg)
-		if err != nil {
-			log.Printf("Error handling message: %v", err)
-			return
-		}
+	// Connection alerts to admins (DM) and/or webhook
+	var adminNotifier, webhookNotifier usecase.Notifier
+	if len(cfg.AdminIDs) > 0 {
+		adminNotifier = wa.NewAdminNotifier(waService, cfg.AdminIDs)
+	}
+	if cfg.AlertWebhookURL != "" {
+		webhookNotifier = webhook.NewClient(cfg.AlertWebhookURL)
+	}
+	connectionAlertUC := usecase.NewConnectionAlertUsecase(adminNotifier, webhookNotifier)
+	waService.SetConnectionHandler(connectionAlertUC.Execute)
 
-		if response != "" {
-			// Apply reply delay to appear more human-like
-			delayMs := cfg.ReplyDelayMinMs
-			if cfg.ReplyDelayMaxMs > cfg.ReplyDelayMinMs {
-				// Random delay between min and max
-				delayMs = cfg.ReplyDelayMinMs + rand.Intn(cfg.ReplyDelayMaxMs-cfg.ReplyDelayMinMs+1)
+	// New members must answer with #join before they are counted
+	if cfg.VerifyMembers {
+		verifyUC := usecase.NewVerifyMembersUsecase(repo, repos.Settings, waService)
+		waService.SetMemberJoinHandler(func(ctx context.Context, group types.JID, members []types.JID) {
+			if !groupsUC.IsServed(group.String()) {
+				return
 			}
-
-			if delayMs > 0 {
-				// Show typing indicator if enabled
-				if cfg.ShowTyping {
-					_ = waService.GetClient().SendChatPresence(ctx, evt.Info.Chat, types.ChatPresenceComposing, types.ChatPresenceMediaText)
+			userIDs := make([]string, 0, len(members))
+			for _, member := range members {
+				if member.Server == types.HiddenUserServer {
+					userIDs = append(userIDs, hasher.UserID(waService.ResolveLIDToPhone(ctx, member.User)))
+				} else {
+					userIDs = append(userIDs, hasher.UserID(member.User))
 				}
+			}
+			if err := verifyUC.MemberJoined(ctx, group.String(), userIDs); err != nil {
+				log.Printf("Failed to greet new members in %s: %v", group, err)
+			}
+		})
+	}
 
-				log.Printf("Delaying reply by %dms", delayMs)
-				time.Sleep(time.Duration(delayMs) * time.Millisecond)
-
-				// Clear typing indicator
-				if cfg.ShowTyping {
-					_ = waService.GetClient().SendChatPresence(ctx, evt.Info.Chat, types.ChatPresencePaused, types.ChatPresenceMediaText)
+	// History sent by the phone after linking goes through the backfill too
+	if cfg.HistoryBackfill {
+		waService.SetHistoryHandler(func(ctx context.Context, group types.JID, messages []*events.Message) {
+			if !groupsUC.IsServed(group.String()) {
+				return
+			}
+			var reports []usecase.HistoricalReport
+			for _, evt := range messages {
+				text := messageText(evt.Message)
+				if evt.Info.IsFromMe || !handleMessageUC.IsReport(group.String(), text) {
+					continue
+				}
+				name := evt.Info.PushName
+				if name == "" {
+					name = "Unknown"
 				}
+				reports = append(reports, usecase.HistoricalReport{
+					UserID:  senderUserID(ctx, waService, hasher, evt.Info.Sender),
+					Name:    name,
+					Message: text,
+					At:      evt.Info.Timestamp,
+				})
 			}
-
-			// Send response
-			resp := &waE2E.Message{
-				Conversation: &response,
+			if len(reports) == 0 {
+				return
 			}
-			_, err := waService.GetClient().SendMessage(ctx, evt.Info.Chat, resp)
+			added, err := backfillUC.Backfill(ctx, reports)
 			if err != nil {
-				log.Printf("Failed to send response: %v", err)
+				log.Printf("Failed to backfill reports from %s: %v", group, err)
+			}
+			log.Printf("Backfilled %d of %d past reports from %s", added, len(reports), group)
+		})
+	}
+
+	// 6. Register Message Handler
+	pipeline := newMessagePipeline(cfg, repos, groupsUC, handleMessageUC, reportUC, botMetrics, reporter)
+	var msgQueue *queue.Redis
+	if cfg.QueueURL != "" && len(os.Args) == 1 {
+		msgQueue, err = queue.NewRedis(cfg.QueueURL, cfg.Tenant)
+		if err != nil {
+			log.Fatalf("Failed to connect to the queue: %v", err)
+		}
+		log.Println("Queueing incoming messages for \"bot worker\"")
+	}
+	waService.SetMessageHandler(func(ctx context.Context, client *whatsmeow.Client, evt *events.Message) {
+		// Log all incoming messages with their Chat ID (useful for getting groupID)
+		fmt.Printf("[DEBUG] Incoming message from Chat ID: %s\n", evt.Info.Chat.String())
+
+		// Ignore messages from self
+		if evt.Info.IsFromMe {
+			return
+		}
+
+		// Resolve LID to phone number for consistent user tracking
+		in := incomingMessage(evt, senderUserID(ctx, waService, hasher, evt.Info.Sender))
+
+		// With QUEUE_URL a worker handles the message and queues the reply
+		if msgQueue != nil {
+			if err := msgQueue.PublishIncoming(ctx, in); err != nil {
+				log.Printf("Failed to queue message: %v", err)
+			}
+			return
+		}
+
+		if reply := pipeline.Handle(ctx, in); reply != nil {
+			if err := sendReply(ctx, waService, humanizeSettings, botMetrics, in, reply); err != nil {
+				log.Print(err)
+				reporter.Capture(err, messageTags(in))
 			}
 		}
 	})
@@ -138,6 +490,21 @@ func main() {
 		log.Fatalf("Failed to initialize WhatsApp service: %v", err)
 	}
 
+	// CLI subcommands (e.g. "bot groups list") run once and exit
+	if len(os.Args) > 1 {
+		var err error
+		if loadTest != nil {
+			err = runLoadTest(handleMessageUC, cfg.GroupID, loadTest)
+		} else {
+			isReport := func(text string) bool { return handleMessageUC.IsReport(cfg.GroupID, text) }
+			err = runCLI(os.Args[1:], waService, groupsUC, adminAuthUC, apiKeyUC, importUC, backfillUC, isReport, seedUC, eventsUC, spreadsheetUC, cfg.BackupPassword, repos.Jobs)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// 8. Connect / Login Logic
 	if !waService.IsLoggedIn() {
 		if cfg.BotPhone != "" {
@@ -171,14 +538,869 @@ func main() {
 		log.Println("Client is already logged in.")
 	}
 
+	// 9. Admin API (only when ADMIN_TOKEN, JWT_SECRET, FEED_URL or CALENDAR_URL is set, or an API key exists)
+	var adminAPI *httpapi.Server
+	if cfg.AdminToken != "" || cfg.JWTSecret != "" || cfg.FeedURL != "" || cfg.CalendarURL != "" || apiKeyUC.HasActive(context.Background()) {
+		profileUC.SetAvatarGateway(waService)
+		adminAPI = httpapi.NewServer(":"+cfg.Port, cfg.AdminToken, deleteUC)
+		adminAPI.SetUserIDHasher(hasher)
+		adminAPI.SetProfiles(profileUC)
+		adminAPI.SetReportEvents(eventsUC)
+		listReportsUC := usecase.NewListReportsUsecase(repo)
+		listReportsUC.SetGroupMembersGateway(waService)
+		adminAPI.SetReports(listReportsUC)
+		adminAPI.SetImporter(importUC)
+		adminAPI.SetAPIKeys(apiKeyUC)
+		adminAPI.SetSession(waService)
+		adminAPI.SetOutbox(repos.Outbox)
+		adminAPI.SetSnapshots(repos.Snapshots)
+		// Bridges and integrations link their accounts through the API
+		adminAPI.SetIdentities(linkUC, handleMessageUC)
+		adminAPI.SetRateLimits(cfg.APIRateLimit, cfg.APIKeyRateLimit)
+		if repos.Redis != nil {
+			// Instances behind a load balancer share one limit
+			adminAPI.SetLimiters(redisLimiter(repos.Redis, "ip", cfg.APIRateLimit), redisLimiter(repos.Redis, "token", cfg.APIKeyRateLimit))
+		}
+		if cfg.FeedURL != "" {
+			feedUC := usecase.NewFeedUsecase(recapUC, repos.Events)
+			feedUC.SetSettingsRepository(repos.Settings)
+			feedUC.SetChallenge(challenge)
+			adminAPI.SetFeed(feedUC, cfg.FeedURL, cfg.FeedTitle)
+		}
+		if calendarUC != nil {
+			adminAPI.SetCalendars(calendarUC)
+		}
+		adminAPI.SetFinalReports(finalReportUC)
+		adminAPI.SetSpreadsheets(spreadsheetUC)
+		if cfg.JWTSecret != "" {
+			adminAPI.SetAuthenticator(adminAuthUC)
+			if cfg.GoogleClientID != "" {
+				adminAPI.SetOAuth(oauth.NewGoogle(cfg.GoogleClientID, cfg.GoogleSecret, cfg.GoogleRedirect))
+			}
+		}
+		adminAPI.Start()
+	}
+
+	// 10. Background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobs := scheduler.New(repos.Jobs, time.Duration(cfg.JobCatchUp)*time.Minute)
+	scheduleJobs(jobsCtx, jobs, cfg, challenge, boards, repos, pruneUC, recapUC, finalReportUC, waService, presence, reporter)
+	go jobs.Run(jobsCtx)
+	if publishStateUC != nil {
+		go publishStateUC.Run(jobsCtx, time.Minute)
+	}
+
+	// Send the replies of the workers, and serve the groups they register
+	if msgQueue != nil {
+		go reloadGroups(jobsCtx, groupsUC)
+		go func() {
+			err := msgQueue.ConsumeReplies(jobsCtx, func(ctx context.Context, out *queue.Outgoing) error {
+				err := sendReply(ctx, waService, humanizeSettings, botMetrics, &out.To, &out.Reply)
+				reporter.Capture(err, messageTags(&out.To))
+				return err
+			})
+			if err != nil {
+				log.Printf("Stopped sending queued replies: %v", err)
+			}
+		}()
+	}
+
 	log.Println("Bot is running... Press Ctrl+C to exit.")
 
-	// 8. Wait for OS Signal
+	// 11. Wait for OS Signal
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	<-c
 
 	log.Println("Shutting down...")
+	stopJobs()
+	if adminAPI != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = adminAPI.Shutdown(shutdownCtx)
+		cancel()
+	}
 	waService.Disconnect()
+	flushSyncs()
+	if eventPublisher != nil {
+		_ = eventPublisher.Close()
+	}
+	if statePublisher != nil {
+		_ = statePublisher.Close()
+	}
+	reporter.Flush(5 * time.Second)
 	os.Exit(0)
 }
+
+// humanizeOverrides turns the humanization variables that are set into
+// overrides of the HUMANIZE preset.
+func humanizeOverrides(cfg config.Config) humanize.Overrides {
+	o := humanize.Overrides{
+		Typing:        cfg.ShowTyping,
+		MarkRead:      cfg.MarkRead,
+		PresenceHours: cfg.PresenceHours,
+		Pacing:        cfg.SendPacing,
+	}
+	if cfg.ReplyDelayMinMs != nil {
+		d := time.Duration(*cfg.ReplyDelayMinMs) * time.Millisecond
+		o.ReplyDelayMin = &d
+	}
+	if cfg.ReplyDelayMaxMs != nil {
+		d := time.Duration(*cfg.ReplyDelayMaxMs) * time.Millisecond
+		o.ReplyDelayMax = &d
+	}
+	return o
+}
+
+// redisLimiter counts a limit of the admin API in Redis, nil when the limit
+// is off.
+func redisLimiter(client *redis.Client, name string, perMinute int) httpapi.Limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return redis.NewRateLimiter(client, name, perMinute)
+}
+
+// replyChat is the chat a reply goes to, for humanize.
+type replyChat struct {
+	wa      *wa.Service
+	info    types.MessageInfo
+	replyTo types.JID
+}
+
+func (c *replyChat) MarkRead(ctx context.Context) error {
+	return c.wa.MarkRead(ctx, c.info)
+}
+
+func (c *replyChat) SetTyping(ctx context.Context, typing bool) error {
+	state := types.ChatPresencePaused
+	if typing {
+		state = types.ChatPresenceComposing
+	}
+	return c.wa.GetClient().SendChatPresence(ctx, c.replyTo, state, types.ChatPresenceMediaText)
+}
+
+// scheduleJobs registers the recurring jobs. A job that is switched off in
+// the config is not registered, and the scheduler drops its stored row.
+func scheduleJobs(ctx context.Context, jobs *scheduler.Scheduler, cfg config.Config, challenge domain.Challenge, boards *usecase.BoardBuilder, repos *repository.Repositories,
+	pruneUC *usecase.PruneDataUsecase, recapUC *usecase.GetRecapUsecase, finalReportUC *usecase.FinalReportUsecase, waService *wa.Service, presence *humanize.PresenceSchedule, reporter *sentry.Reporter) {
+	// Scheduled jobs send in the bulk lane, behind replies to members
+	every := func(name, schedule string, run scheduler.Handler) {
+		bulk := func(ctx context.Context, job *domain.Job) error {
+			tags := map[string]string{"job": name}
+			defer reporter.Recover(tags)
+			err := run(wa.WithLane(ctx, wa.LaneBulk), job)
+			reporter.Capture(err, tags)
+			return err
+		}
+		if err := jobs.Every(ctx, name, schedule, bulk); err != nil {
+			log.Printf("Job %s disabled: %v", name, err)
+		}
+	}
+
+	if presence != nil {
+		// Both jobs send whatever the schedule says for now, so a late run
+		// is still right
+		online, offline := presence.Cron()
+		updatePresence := func(ctx context.Context, _ *domain.Job) error {
+			return waService.UpdatePresence(ctx)
+		}
+		every("presence-online", online, updatePresence)
+		every("presence-offline", offline, updatePresence)
+	}
+
+	if cfg.RetentionMonths > 0 {
+		every("prune", "0 3 * * *", func(ctx context.Context, _ *domain.Job) error {
+			return pruneUC.Execute(ctx)
+		})
+	}
+
+	// Acknowledgments queued with their report that never went out; they
+	// are replies, so they skip ahead of the bulk lane
+	resendUC := usecase.NewResendRepliesUsecase(repos.Outbox, waService)
+	every("resend-replies", "* * * * *", func(ctx context.Context, _ *domain.Job) error {
+		return resendUC.Execute(wa.WithLane(ctx, wa.LaneReply), time.Now())
+	})
+
+	personalUC := usecase.NewSendPersonalReminderUsecase(repos.Reports, repos.Settings, waService)
+	every("personal-reminders", "* * * * *", func(ctx context.Context, job *domain.Job) error {
+		return personalUC.Execute(ctx, job.LastRun)
+	})
+
+	// A quote of the day below the prompt and the recap
+	var quoteUC *usecase.DailyQuoteUsecase
+	if cfg.QuotesFile != "" {
+		if quotes, err := loadQuotes(cfg.QuotesFile); err != nil {
+			log.Printf("Quotes disabled: %v", err)
+		} else {
+			quoteUC = usecase.NewDailyQuoteUsecase(quotes, repos.Quotes)
+		}
+	}
+
+	if cfg.PromptTime != "" {
+		at, err := time.Parse("15:04", cfg.PromptTime)
+		if err != nil || cfg.GroupID == "" {
+			log.Printf("Prompt disabled: PROMPT_TIME must be HH:MM and GROUP_ID must be set")
+		} else {
+			promptUC := usecase.NewSendPromptUsecase(repos.Prompts, waService, cfg.GroupID)
+			promptUC.SetReactions(cfg.ReactionReports)
+			promptUC.SetChallenge(challenge)
+			promptUC.SetQuotes(quoteUC)
+			every("prompt", fmt.Sprintf("%d %d * * *", at.Minute(), at.Hour()), func(ctx context.Context, _ *domain.Job) error {
+				return promptUC.Execute(ctx)
+			})
+		}
+	}
+
+	if cfg.ReminderTime != "" {
+		at, err := time.Parse("15:04", cfg.ReminderTime)
+		if err != nil || cfg.GroupID == "" {
+			log.Printf("Reminder disabled: REMINDER_TIME must be HH:MM and GROUP_ID must be set")
+		} else {
+			reminderUC := usecase.NewSendReminderUsecase(repos.Reports, waService, cfg.GroupID, cfg.ReminderStreak)
+			reminderUC.SetSettingsRepository(repos.Settings)
+			if cfg.ReactionReports {
+				reminderUC.SetPrompts(repos.Prompts, waService)
+			}
+			every("reminder", fmt.Sprintf("%d %d * * *", at.Minute(), at.Hour()), func(ctx context.Context, _ *domain.Job) error {
+				return reminderUC.Execute(ctx)
+			})
+		}
+	}
+
+	if cfg.GreetingTime != "" {
+		at, err := time.Parse("15:04", cfg.GreetingTime)
+		if err != nil {
+			err = fmt.Errorf("GREETING_TIME must be HH:MM")
+		}
+		rules := usecase.DefaultGreetingRules()
+		if err == nil && cfg.GreetingsFile != "" {
+			rules, err = loadGreetingRules(cfg.GreetingsFile)
+		}
+		switch {
+		case err != nil:
+			log.Printf("Greetings disabled: %v", err)
+		case cfg.GroupID == "":
+			log.Printf("Greetings disabled: GROUP_ID must be set")
+		default:
+			greetingsUC := usecase.NewSendGreetingsUsecase(repos.Reports, waService, cfg.GroupID, rules)
+			greetingsUC.SetSettingsRepository(repos.Settings)
+			if cfg.CelebrationMedia != "" {
+				greetingsUC.SetCelebrations(media.New(cfg.CelebrationMedia), waService)
+			}
+			every("greetings", fmt.Sprintf("%d %d * * *", at.Minute(), at.Hour()), func(ctx context.Context, job *domain.Job) error {
+				return greetingsUC.Execute(ctx, job.LastRun)
+			})
+		}
+	}
+
+	if cfg.RecapSchedule != "" {
+		if cfg.GroupID == "" {
+			log.Printf("Recap disabled: GROUP_ID must be set")
+		} else {
+			sendRecapUC := usecase.NewSendRecapUsecase(recapUC, waService, cfg.GroupID)
+			sendRecapUC.SetQuotes(quoteUC)
+			every("recap", cfg.RecapSchedule, func(ctx context.Context, _ *domain.Job) error {
+				return sendRecapUC.Execute(ctx)
+			})
+		}
+	}
+
+	// The day after the challenge, when it has a start date and a length
+	if cfg.FinalReportTime != "" && cfg.GroupID != "" && !challenge.Start.IsZero() && challenge.Days > 0 {
+		at, err := time.Parse("15:04", cfg.FinalReportTime)
+		if err != nil {
+			log.Printf("Final report disabled: FINAL_REPORT_TIME must be HH:MM")
+		} else {
+			sendFinalUC := usecase.NewSendFinalReportUsecase(finalReportUC, waService, cfg.GroupID)
+			every("final-report", fmt.Sprintf("%d %d * * *", at.Minute(), at.Hour()), func(ctx context.Context, _ *domain.Job) error {
+				return sendFinalUC.Execute(ctx, time.Now())
+			})
+		}
+	}
+
+	if cfg.SMTPURL != "" {
+		mail, err := mailer.NewSMTP(cfg.SMTPURL, cfg.SMTPFrom)
+		if err != nil {
+			log.Printf("Email digest disabled: %v", err)
+		} else {
+			digestUC := usecase.NewSendDigestUsecase(recapUC, repos.Reports, repos.Settings, mail, cfg.DigestTo)
+			digestUC.SetBoards(boards)
+			every("digest", cfg.DigestSchedule, func(ctx context.Context, _ *domain.Job) error {
+				return digestUC.Execute(ctx)
+			})
+		}
+	}
+
+	if cfg.SnapshotTime != "" {
+		at, err := time.Parse("15:04", cfg.SnapshotTime)
+		if err != nil {
+			log.Printf("Leaderboard snapshots disabled: SNAPSHOT_TIME must be HH:MM")
+		} else {
+			snapshotUC := usecase.NewSnapshotLeaderboardUsecase(repos.Reports, repos.Settings, repos.Snapshots)
+			snapshotUC.SetBoards(boards)
+			every("leaderboard-snapshot", fmt.Sprintf("%d %d * * *", at.Minute(), at.Hour()), func(ctx context.Context, _ *domain.Job) error {
+				return snapshotUC.Execute(ctx, time.Now())
+			})
+		}
+	}
+
+	if cfg.VerifyMembers {
+		if cfg.GroupID == "" {
+			log.Printf("Verification deadline notices disabled: GROUP_ID must be set")
+		} else {
+			verifyUC := usecase.NewVerifyMembersUsecase(repos.Reports, repos.Settings, waService)
+			every("verify-expiry", "@hourly", func(ctx context.Context, job *domain.Job) error {
+				return verifyUC.ExpireOverdue(ctx, cfg.GroupID, job.LastRun)
+			})
+		}
+	}
+
+	if cfg.BackupSchedule != "" {
+		if repos.Backup == nil {
+			log.Printf("Backup disabled: BACKUP_SCHEDULE only backs up SQLite; back up Supabase, Postgres or MySQL with their own tools")
+		} else {
+			backupUC := usecase.NewBackupDatabaseUsecase(repos.Backup, cfg.BackupDir, cfg.BackupKeep)
+			if cfg.BackupS3URL != "" {
+				if cfg.BackupPassword == "" {
+					log.Printf("Off-site backup disabled: set BACKUP_PASSPHRASE to encrypt the backups before they leave this host")
+				} else if store, err := s3.NewStore(cfg.BackupS3URL, cfg.BackupRegion, cfg.BackupAccessKey, cfg.BackupSecretKey, cfg.BackupBucket, cfg.BackupPrefix); err != nil {
+					log.Printf("Off-site backup disabled: %v", err)
+				} else {
+					backupUC.SetOffsite(store, cfg.BackupPassword, cfg.BackupS3Keep)
+				}
+			}
+			every("backup", cfg.BackupSchedule, func(ctx context.Context, job *domain.Job) error {
+				_, err := backupUC.Execute(ctx, job.LastRun)
+				return err
+			})
+		}
+	}
+}
+
+// loadGreetingRules reads the GREETINGS_FILE.
+func loadGreetingRules(path string) ([]usecase.GreetingRule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return usecase.LoadGreetingRules(f)
+}
+
+// loadRewardRules reads the REWARDS_FILE.
+func loadRewardRules(path string) ([]usecase.RewardRule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return usecase.LoadRewardRules(f)
+}
+
+// loadQuotes reads the QUOTES_FILE.
+func loadQuotes(path string) (usecase.QuoteList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return usecase.ParseQuoteList(f)
+}
+
+// senderUserID returns the user ID of a sender: their phone number, resolving
+// a LID when WhatsApp hides the number, hashed when USER_ID_SALT is set.
+func senderUserID(ctx context.Context, waService *wa.Service, hasher *phone.Hasher, sender types.JID) string {
+	if sender.Server == "lid" || sender.Server == types.DefaultUserServer && len(sender.User) > 15 {
+		// Looks like a LID, try to resolve to phone number
+		return hasher.UserID(waService.ResolveLIDToPhone(ctx, sender.User))
+	}
+	// Already a phone number
+	return hasher.UserID(sender.User)
+}
+
+// messageText returns the text or caption of a message, "" for anything
+// else.
+func messageText(m *waE2E.Message) string {
+	switch {
+	case m.Conversation != nil:
+		return *m.Conversation
+	case m.ExtendedTextMessage != nil && m.ExtendedTextMessage.Text != nil:
+		return *m.ExtendedTextMessage.Text
+	case m.ImageMessage != nil && m.ImageMessage.Caption != nil:
+		return *m.ImageMessage.Caption
+	case m.VideoMessage != nil && m.VideoMessage.Caption != nil:
+		return *m.VideoMessage.Caption
+	case m.DocumentMessage != nil && m.DocumentMessage.Caption != nil:
+		return *m.DocumentMessage.Caption
+	}
+	return ""
+}
+
+// quotedMessageID returns the ID of the message a reply quotes, "" when the
+// message is not a reply.
+func quotedMessageID(m *waE2E.Message) string {
+	switch {
+	case m.ExtendedTextMessage != nil:
+		return m.ExtendedTextMessage.GetContextInfo().GetStanzaID()
+	case m.ImageMessage != nil:
+		return m.ImageMessage.GetContextInfo().GetStanzaID()
+	case m.VideoMessage != nil:
+		return m.VideoMessage.GetContextInfo().GetStanzaID()
+	}
+	return ""
+}
+
+const cliUsage = `Usage:
+  bot                        run the bot, or every enabled tenant
+  bot worker                 handle the messages the bot queues on QUEUE_URL, without a WhatsApp session
+  bot tenants list           list tenants and their overrides
+  bot tenants add <id> [name]
+                             host another community with its own database and WhatsApp account
+  bot tenants set <id> <KEY>=<value>
+                             override a variable for a tenant, e.g. GROUP_ID (empty value = unset)
+  bot tenants enable|disable <id>
+                             start or stop running a tenant, keeping its data
+  bot tenants remove <id>    unregister a tenant, leaving its database on disk
+  bot groups list            list the groups the linked account is in
+  bot admins add <username>  create an admin API account (password read from stdin)
+  bot apikeys create <name> [read|admin]
+                             create an admin API key (default scope: read)
+  bot apikeys list           list API keys
+  bot apikeys revoke <id>    revoke an API key
+  bot import --csv <file>    pre-register members from phone,name[,streak[,total]] rows
+  bot import --whatsapp-export <file>
+                             count the #lapor of an exported group chat (.txt)
+  bot export --xlsx <file>   write the standings, who reported each day and the activity
+                             types to an Excel file
+  bot backup decrypt <file.enc> <file.db>
+                             decrypt an off-site backup with BACKUP_PASSPHRASE
+  bot seed [--users 50] [--days 40]
+                             fill an empty database with fake members for development
+  bot reports rebuild        recompute every report from its event history
+  bot jobs list              list scheduled jobs with their next and last run
+  bot loadtest [--rate 50] [--duration 30s] [--workers 4] [--users 200] [--db <file>]
+                             measure the message pipeline on a separate SQLite file
+
+Prefix any command with TENANT=<id> to run it for one tenant, e.g.
+  TENANT=lari-pagi bot import --csv members.csv`
+
+// runCLI handles one-off subcommands using the already-initialized session.
+// Incoming messages are ignored so a backlog is not answered from the CLI.
+func runCLI(args []string, waService *wa.Service, groupsUC *usecase.ManageGroupsUsecase, adminAuthUC *usecase.AdminAuthUsecase, apiKeyUC *usecase.APIKeyUsecase, importUC *usecase.ImportMembersUsecase, backfillUC *usecase.BackfillReportsUsecase, isReport func(text string) bool, seedUC *usecase.SeedDataUsecase, eventsUC *usecase.ReportEventsUsecase, spreadsheetUC *usecase.ExportSpreadsheetUsecase, backupPassword string, jobs domain.JobRepository) error {
+	if len(args) == 3 && args[0] == "admins" && args[1] == "add" {
+		return addAdminAccount(adminAuthUC, args[2])
+	}
+	if len(args) > 0 && args[0] == "apikeys" {
+		return manageAPIKeys(apiKeyUC, args[1:])
+	}
+	if len(args) == 3 && args[0] == "import" && args[1] == "--csv" {
+		return importMembers(importUC, args[2])
+	}
+	if len(args) == 3 && args[0] == "import" && args[1] == "--whatsapp-export" {
+		return importWhatsAppExport(backfillUC, isReport, args[2])
+	}
+	if len(args) == 3 && args[0] == "export" && args[1] == "--xlsx" {
+		return exportSpreadsheet(spreadsheetUC, args[2])
+	}
+	if len(args) == 4 && args[0] == "backup" && args[1] == "decrypt" {
+		return decryptBackup(backupPassword, args[2], args[3])
+	}
+	if len(args) > 0 && args[0] == "seed" {
+		return seedData(seedUC, args[1:])
+	}
+	if len(args) == 2 && args[0] == "reports" && args[1] == "rebuild" {
+		return rebuildReports(eventsUC)
+	}
+	if len(args) == 2 && args[0] == "jobs" && args[1] == "list" {
+		return listJobs(jobs)
+	}
+	if len(args) < 2 || args[0] != "groups" || args[1] != "list" {
+		return fmt.Errorf("unknown command\n%s", cliUsage)
+	}
+
+	if !waService.IsLoggedIn() {
+		return fmt.Errorf("not logged in, run the bot once to pair first")
+	}
+
+	waService.SetMessageHandler(nil)
+	waService.SetConnectionHandler(nil)
+	if err := waService.Connect(); err != nil {
+		return err
+	}
+	defer waService.Disconnect()
+
+	if !waService.GetClient().WaitForConnection(30 * time.Second) {
+		return fmt.Errorf("timed out connecting to WhatsApp")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	groups, err := groupsUC.ListGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Println("Groups:")
+	fmt.Println(usecase.FormatGroupList(groups))
+	return nil
+}
+
+// addAdminAccount reads a password from stdin so it does not end up in the
+// shell history, then creates or updates the account. On a terminal the
+// password is not echoed.
+func addAdminAccount(adminAuthUC *usecase.AdminAuthUsecase, username string) error {
+	fmt.Print("Password: ")
+	var password string
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		secret, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return fmt.Errorf("failed to read password: %w", err)
+		}
+		password = string(secret)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("failed to read password: %w", err)
+		}
+		password = line
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := adminAuthUC.CreateAccount(ctx, username, strings.TrimRight(password, "\r\n")); err != nil {
+		return err
+	}
+	log.Printf("Admin account %s saved", username)
+	return nil
+}
+
+// manageAPIKeys runs "bot apikeys create|list|revoke".
+func manageAPIKeys(apiKeyUC *usecase.APIKeyUsecase, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch {
+	case len(args) >= 2 && len(args) <= 3 && args[0] == "create":
+		scope := usecase.ScopeRead
+		if len(args) == 3 {
+			scope = args[2]
+		}
+		plain, key, err := apiKeyUC.Create(ctx, args[1], scope)
+		if err != nil {
+			return err
+		}
+		log.Printf("API key %s (%s) created. Store it now, it is not shown again:", key.ID, key.Scope)
+		fmt.Println(plain)
+		return nil
+
+	case len(args) == 1 && args[0] == "list":
+		keys, err := apiKeyUC.List(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(usecase.FormatAPIKeys(keys))
+		return nil
+
+	case len(args) == 2 && args[0] == "revoke":
+		found, err := apiKeyUC.Revoke(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("no API key with ID %s", args[1])
+		}
+		log.Printf("API key %s revoked", args[1])
+		return nil
+	}
+	return fmt.Errorf("unknown command\n%s", cliUsage)
+}
+
+// importMembers runs "bot import --csv <file>".
+func importMembers(importUC *usecase.ImportMembersUsecase, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	result, err := importUC.Import(context.Background(), f)
+	for _, rowErr := range result.Errors {
+		log.Printf("Skipped %s", rowErr)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Imported %d members, %d already had reports and were left unchanged, %d invalid rows", result.Imported, result.Skipped, len(result.Errors))
+	return nil
+}
+
+// importWhatsAppExport runs "bot import --whatsapp-export", reading the
+// export with GROUP_ID's prefix in local time.
+func importWhatsAppExport(backfillUC *usecase.BackfillReportsUsecase, isReport func(text string) bool, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	result, err := backfillUC.ImportWhatsAppExport(context.Background(), f, time.Local, isReport)
+	if err != nil {
+		return err
+	}
+	if len(result.Unknown) > 0 {
+		log.Printf("Skipped the reports of senders that match no member, import them with \"bot import --csv\" first: %s", strings.Join(result.Unknown, ", "))
+	}
+	log.Printf("Found %d reports, counted %d days that were missing", result.Reports, result.Added)
+	return nil
+}
+
+// exportSpreadsheet runs "bot export --xlsx <file>".
+func exportSpreadsheet(spreadsheetUC *usecase.ExportSpreadsheetUsecase, path string) error {
+	sheet, data, err := spreadsheetUC.XLSX(context.Background(), time.Now())
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	log.Printf("Exported %d members over %d days to %s", len(sheet.Members), len(sheet.Days), path)
+	return nil
+}
+
+// decryptBackup runs "bot backup decrypt", e.g. to restore an off-site
+// backup after losing the host.
+func decryptBackup(passphrase, in, out string) error {
+	if passphrase == "" {
+		return fmt.Errorf("set BACKUP_PASSPHRASE to the passphrase the backup was encrypted with")
+	}
+	sealed, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	data, err := usecase.DecryptBackup(sealed, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, data, 0o600); err != nil {
+		return err
+	}
+	log.Printf("Decrypted %s to %s", in, out)
+	return nil
+}
+
+// rebuildReports runs "bot reports rebuild", e.g. after the streak rules
+// changed.
+func rebuildReports(eventsUC *usecase.ReportEventsUsecase) error {
+	changed, err := eventsUC.RebuildAll(context.Background())
+	if err != nil {
+		return err
+	}
+	log.Printf("Rebuilt reports from their history, %d changed", changed)
+	return nil
+}
+
+// listJobs runs "bot jobs list".
+func listJobs(jobs domain.JobRepository) error {
+	list, err := jobs.GetJobs(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Println("No scheduled jobs")
+		return nil
+	}
+	for _, job := range list {
+		schedule := job.Schedule
+		if schedule == "" {
+			schedule = "once"
+		}
+		line := fmt.Sprintf("%-20s %-16s next %s", job.Name, schedule, job.NextRun.Local().Format("2006-01-02 15:04"))
+		if !job.LastRun.IsZero() {
+			line += ", last " + job.LastRun.Local().Format("2006-01-02 15:04")
+		}
+		if job.LastError != "" {
+			line += ", failed: " + job.LastError
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// seedData runs "bot seed".
+func seedData(seedUC *usecase.SeedDataUsecase, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	users := fs.Int("users", 50, "number of fake members")
+	days := fs.Int("days", 40, "days of history, ending today")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	reports, err := seedUC.Seed(context.Background(), *users, *days, rng)
+	if err != nil {
+		return err
+	}
+	log.Printf("Seeded %d members with %d reports over %d days", *users, reports, *days)
+	return nil
+}
+
+type loadTestOptions struct {
+	loadtest.Options
+	db   string
+	keep bool // keep the database file after the run
+}
+
+func parseLoadTestFlags(args []string) (*loadTestOptions, error) {
+	opts := &loadTestOptions{}
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	fs.IntVar(&opts.Rate, "rate", 50, "messages per second")
+	fs.DurationVar(&opts.Duration, "duration", 30*time.Second, "how long to send messages")
+	fs.IntVar(&opts.Workers, "workers", 4, "messages handled concurrently")
+	fs.IntVar(&opts.Users, "users", 200, "distinct senders")
+	fs.StringVar(&opts.db, "db", "", "SQLite file to create, kept after the run (default: a temporary file)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.Rate < 1 || opts.Duration <= 0 {
+		return nil, fmt.Errorf("rate and duration must be positive")
+	}
+
+	if opts.db == "" {
+		opts.db = filepath.Join(os.TempDir(), fmt.Sprintf("lapor-bot-loadtest-%d.db", time.Now().UnixNano()))
+		return opts, nil
+	}
+	// Refuse existing files so a typo cannot point the test at real data
+	if _, err := os.Stat(opts.db); err == nil {
+		return nil, fmt.Errorf("%s already exists, the load test needs a new file", opts.db)
+	}
+	opts.keep = true
+	return opts, nil
+}
+
+// runLoadTest runs "bot loadtest" against the fully wired message handler.
+func runLoadTest(handleMessageUC *usecase.HandleMessageUsecase, groupID string, opts *loadTestOptions) error {
+	if !opts.keep {
+		defer func() {
+			for _, suffix := range []string{"", "-wal", "-shm"} {
+				_ = os.Remove(opts.db + suffix)
+			}
+		}()
+	}
+	opts.ChatJID = groupID
+	if opts.ChatJID == "" {
+		opts.ChatJID = "120363000000000000@g.us"
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Load test: %d msg/s for %s, %d workers, %d users, database %s",
+		opts.Rate, opts.Duration, opts.Workers, opts.Users, opts.db)
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	result := loadtest.Run(ctx, handleMessageUC, opts.Options, rng)
+	fmt.Println(result)
+	return nil
+}
+
+// manageTenants runs "bot tenants list|add|set|enable|disable|remove".
+func manageTenants(tenantsUC *usecase.ManageTenantsUsecase, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		tenants, err := tenantsUC.List(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(usecase.FormatTenants(tenants))
+		return nil
+
+	case len(args) >= 2 && args[0] == "add":
+		tenant, err := tenantsUC.Add(ctx, args[1], strings.Join(args[2:], " "))
+		if err != nil {
+			return err
+		}
+		log.Printf("Tenant %s added. Set its GROUP_ID and BOT_PHONE with \"bot tenants set\", then pair it with \"TENANT=%s bot\"", tenant.ID, tenant.ID)
+		return nil
+
+	case len(args) == 3 && args[0] == "set":
+		key, value, ok := strings.Cut(args[2], "=")
+		if !ok {
+			return fmt.Errorf("expected KEY=value, got %q", args[2])
+		}
+		if _, err := tenantsUC.Set(ctx, args[1], key, value); err != nil {
+			return err
+		}
+		log.Printf("Tenant %s updated, restart the bot to apply", args[1])
+		return nil
+
+	case len(args) == 2 && (args[0] == "enable" || args[0] == "disable"):
+		if err := tenantsUC.SetEnabled(ctx, args[1], args[0] == "enable"); err != nil {
+			return err
+		}
+		log.Printf("Tenant %s %sd, restart the bot to apply", args[1], args[0])
+		return nil
+
+	case len(args) == 2 && args[0] == "remove":
+		if err := tenantsUC.Remove(ctx, args[1]); err != nil {
+			return err
+		}
+		log.Printf("Tenant %s removed, its database was kept", args[1])
+		return nil
+	}
+	return fmt.Errorf("unknown command\n%s", cliUsage)
+}
+
+// tenantConfig returns the configuration of the tenant in TENANT, exiting
+// if it does not exist.
+func tenantConfig(cfg config.Config) config.Config {
+	tenantsUC := usecase.NewManageTenantsUsecase(repository.NewTenantRepository(cfg))
+	tenant, err := tenantsUC.Get(context.Background(), cfg.Tenant)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tenantCfg, err := config.LoadTenant(cfg, tenant.ID, tenant.Env)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Running as tenant %s (%s)", tenant.ID, tenant.Name)
+	return tenantCfg
+}
+
+// runTenants runs every enabled tenant as a child process until the bot is
+// stopped. It returns false when no tenant is registered, so the bot runs
+// with the environment's configuration.
+func runTenants(cfg config.Config) bool {
+	tenantsUC := usecase.NewManageTenantsUsecase(repository.NewTenantRepository(cfg))
+	tenants, err := tenantsUC.List(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to load tenants: %v", err)
+	}
+	if len(tenants) == 0 {
+		return false
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		log.Fatalf("Failed to start tenants: %v", err)
+	}
+	var procs []supervisor.Process
+	for _, tenant := range tenants {
+		if tenant.Enabled {
+			procs = append(procs, supervisor.Process{Name: tenant.ID, Path: self, Env: []string{"TENANT=" + tenant.ID}})
+		}
+	}
+	if len(procs) == 0 {
+		log.Fatal("Every tenant is disabled, enable one with \"bot tenants enable <id>\"")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	log.Printf("Running %d tenants... Press Ctrl+C to exit.", len(procs))
+	supervisor.Run(ctx, procs)
+	log.Println("All tenants stopped")
+	return true
+}
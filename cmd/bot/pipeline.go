@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/humanize"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/metrics"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/queue"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/repository"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sentry"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/wa"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// messagePipeline turns an incoming message into the reply to send. It runs
+// in the bot process, or in "bot worker" processes when QUEUE_URL is set.
+type messagePipeline struct {
+	groupID   string
+	groups    *usecase.ManageGroupsUsecase
+	handler   *usecase.HandleMessageUsecase
+	archive   domain.MessageArchiveRepository // nil unless ARCHIVE_MESSAGES
+	reactions *usecase.ReactionReportUsecase  // nil unless REACTION_REPORTS
+	metrics   *metrics.Collector
+	reporter  *sentry.Reporter // nil unless SENTRY_DSN
+	dedup     domain.DedupStore
+}
+
+// dedupTTL is how long a handled message is remembered. WhatsApp redelivers
+// messages after a reconnect within minutes.
+const dedupTTL = time.Hour
+
+// dedupLockTTL is how long a message being handled is kept from other
+// processes. It runs out before the queue hands the message of a crashed
+// worker to another one, after a minute.
+const dedupLockTTL = 30 * time.Second
+
+func newMessagePipeline(cfg config.Config, repos *repository.Repositories, groups *usecase.ManageGroupsUsecase,
+	handler *usecase.HandleMessageUsecase, reportUC *usecase.ReportActivityUsecase, botMetrics *metrics.Collector, reporter *sentry.Reporter) *messagePipeline {
+	p := &messagePipeline{groupID: cfg.GroupID, groups: groups, handler: handler, metrics: botMetrics, reporter: reporter, dedup: repos.Dedup}
+	if cfg.ArchiveMessages {
+		p.archive = repos.Messages
+	}
+	// Members may report by reacting or replying to the bot's prompts
+	if cfg.ReactionReports {
+		p.reactions = usecase.NewReactionReportUsecase(repos.Prompts, reportUC)
+	}
+	return p
+}
+
+// Handle returns the reply to a message, nil when there is nothing to send.
+// Failures are reported with the message they happened on.
+func (p *messagePipeline) Handle(ctx context.Context, in *queue.Incoming) (reply *usecase.Reply) {
+	defer p.reporter.Recover(messageTags(in))
+
+	// Only handle groups the bot serves (GROUP_ID plus groups added via #admin add-group)
+	if in.IsGroup && !p.groups.IsServed(in.ChatID) {
+		return nil
+	}
+
+	// A message handled before, by this process or another, is not counted
+	// twice. It is only recorded once handled, so when a worker crashes
+	// halfway the queue's redelivery still gets through.
+	key := "message:" + in.ChatID + ":" + in.MessageID
+	if p.dedup != nil && in.MessageID != "" && p.duplicate(ctx, key) {
+		log.Printf("Skipping duplicate message %s", in.MessageID)
+		return nil
+	}
+	reply = p.handle(ctx, in)
+	if p.dedup != nil && in.MessageID != "" && ctx.Err() == nil {
+		if _, err := p.dedup.FirstSeen(ctx, key, dedupTTL); err != nil {
+			log.Printf("Failed to record a handled message: %v", err)
+		}
+	}
+	return reply
+}
+
+// duplicate reports whether the message under key was handled, or is being
+// handled by another process right now.
+func (p *messagePipeline) duplicate(ctx context.Context, key string) bool {
+	seen, err := p.dedup.Seen(ctx, key)
+	if err != nil {
+		log.Printf("Failed to check for a duplicate message: %v", err)
+		return false
+	}
+	if seen {
+		return true
+	}
+	first, err := p.dedup.FirstSeen(ctx, "handling:"+key, dedupLockTTL)
+	if err != nil {
+		log.Printf("Failed to check for a duplicate message: %v", err)
+		return false
+	}
+	return !first
+}
+
+// handle runs a message that is not a duplicate through the use cases.
+func (p *messagePipeline) handle(ctx context.Context, in *queue.Incoming) *usecase.Reply {
+	// Once a group is configured, direct messages are only for admin
+	// commands, answers in a conversation such as #join, and members in
+	// #privat mode
+	if !in.IsGroup && p.groupID != "" && !p.handler.IsAdmin(in.UserID) && !p.handler.InConversation(ctx, in.UserID) &&
+		!p.handler.InPrivateMode(ctx, in.UserID) {
+		return nil
+	}
+
+	if p.archive != nil {
+		if err := p.archive.ArchiveMessage(ctx, archivedMessage(in)); err != nil {
+			log.Printf("Failed to archive message: %v", err)
+			p.reporter.Capture(err, messageTags(in))
+		}
+	}
+
+	if in.Reaction != nil && p.reactions != nil && in.IsGroup {
+		counted, err := p.reactions.Execute(ctx, in.ChatID, in.Reaction.MessageID, in.UserID, in.PushName, in.Reaction.Text, time.Now())
+		if err != nil {
+			log.Printf("Failed to count reaction of %s: %v", in.UserID, err)
+			p.reporter.Capture(err, messageTags(in))
+		} else if counted {
+			log.Printf("Counted the %s reaction of %s (%s) as a report", in.Reaction.Text, in.PushName, in.UserID)
+		}
+		return nil
+	}
+
+	if in.Text == "" {
+		return nil
+	}
+
+	fmt.Printf("Message from %s (%s): %s\n", in.PushName, in.UserID, in.Text)
+	p.metrics.MessageProcessed()
+
+	// Execute Use Case
+	reply, err := p.handler.ExecuteInChat(ctx, in.ChatID, in.UserID, in.PushName, in.Text)
+	if err != nil {
+		log.Printf("Error handling message: %v", err)
+		p.reporter.Capture(err, messageTags(in))
+		return nil
+	}
+
+	// A reply to today's prompt such as "lari 5km" counts without #lapor
+	if reply.Text == "" && p.reactions != nil && in.IsGroup && in.QuotedID != "" {
+		text, counted, err := p.reactions.ExecuteReply(ctx, in.ChatID, in.QuotedID, in.UserID, in.PushName, in.Text, time.Now())
+		if err != nil {
+			log.Printf("Failed to count reply of %s: %v", in.UserID, err)
+			p.reporter.Capture(err, messageTags(in))
+			return nil
+		}
+		if counted {
+			reply.Text = text
+		}
+	}
+
+	if reply.Text == "" && reply.Image == nil && reply.Document == nil && reply.GIF == nil && reply.Sticker == nil {
+		return nil
+	}
+	return reply
+}
+
+// messageTags describes a message for error reports. The reporter masks
+// the phone numbers in them.
+func messageTags(in *queue.Incoming) map[string]string {
+	tags := map[string]string{"chat": in.ChatID, "user": in.UserID, "text": in.Text}
+	if fields := strings.Fields(in.Text); len(fields) > 0 {
+		tags["command"] = strings.ToLower(fields[0])
+	}
+	if in.Reaction != nil {
+		tags["reaction"] = in.Reaction.Text
+	}
+	return tags
+}
+
+// incomingMessage captures what the pipeline needs of a WhatsApp message.
+func incomingMessage(evt *events.Message, userID string) *queue.Incoming {
+	in := &queue.Incoming{
+		MessageID: evt.Info.ID,
+		ChatID:    evt.Info.Chat.String(),
+		SenderJID: evt.Info.Sender.String(),
+		UserID:    userID,
+		PushName:  evt.Info.PushName,
+		IsGroup:   evt.Info.IsGroup,
+		Text:      messageText(evt.Message),
+		QuotedID:  quotedMessageID(evt.Message),
+		SentAt:    evt.Info.Timestamp,
+	}
+	if in.PushName == "" {
+		in.PushName = "Unknown" // Fallback name
+	}
+
+	m := evt.Message
+	switch {
+	case m.ReactionMessage != nil:
+		in.Reaction = &queue.Reaction{MessageID: m.ReactionMessage.GetKey().GetID(), Text: m.ReactionMessage.GetText()}
+	case m.ImageMessage != nil:
+		in.MediaType, in.MediaMime, in.MediaSize = "image", m.ImageMessage.GetMimetype(), int64(m.ImageMessage.GetFileLength())
+	case m.VideoMessage != nil:
+		in.MediaType, in.MediaMime, in.MediaSize = "video", m.VideoMessage.GetMimetype(), int64(m.VideoMessage.GetFileLength())
+	case m.DocumentMessage != nil:
+		in.MediaType, in.MediaMime, in.MediaSize = "document", m.DocumentMessage.GetMimetype(), int64(m.DocumentMessage.GetFileLength())
+	case m.AudioMessage != nil:
+		in.MediaType, in.MediaMime, in.MediaSize = "audio", m.AudioMessage.GetMimetype(), int64(m.AudioMessage.GetFileLength())
+	case m.StickerMessage != nil:
+		in.MediaType, in.MediaMime, in.MediaSize = "sticker", m.StickerMessage.GetMimetype(), int64(m.StickerMessage.GetFileLength())
+	}
+	return in
+}
+
+// archivedMessage captures the sender, text, and media metadata of a message
+// for the message archive.
+func archivedMessage(in *queue.Incoming) *domain.ArchivedMessage {
+	return &domain.ArchivedMessage{
+		MessageID:  in.MessageID,
+		ChatID:     in.ChatID,
+		SenderID:   in.UserID,
+		SenderName: in.PushName,
+		Text:       in.Text,
+		MediaType:  in.MediaType,
+		MediaMime:  in.MediaMime,
+		MediaSize:  in.MediaSize,
+		SentAt:     in.SentAt,
+	}
+}
+
+// sendReply sends the reply to a message like a person would, see package
+// humanize.
+func sendReply(ctx context.Context, waService *wa.Service, humanizeSettings humanize.Settings, botMetrics *metrics.Collector,
+	in *queue.Incoming, reply *usecase.Reply) error {
+	chat, err := types.ParseJID(in.ChatID)
+	if err != nil {
+		return err
+	}
+	sender, err := types.ParseJID(in.SenderJID)
+	if err != nil {
+		return err
+	}
+
+	// Private replies go to the sender's personal chat
+	replyTo := chat
+	if reply.Private {
+		replyTo = sender.ToNonAD()
+	}
+
+	// Read receipt, typing and delay, see package humanize
+	info := types.MessageInfo{MessageSource: types.MessageSource{Chat: chat, Sender: sender, IsGroup: in.IsGroup}, ID: in.MessageID, Timestamp: in.SentAt}
+	humanizeSettings.BeforeReply(ctx, &replyChat{waService, info, replyTo}, nil, time.Now())
+
+	// Send response ahead of queued reminders and recaps, under the ID it
+	// was queued with in the outbox
+	ctx = wa.WithMessageID(wa.WithLane(ctx, wa.LaneReply), reply.OutboxID)
+	switch {
+	case reply.Document != nil:
+		err = waService.SendDocument(ctx, replyTo, reply.Document, reply.DocumentMimeType, reply.DocumentName, reply.Text)
+	case reply.Image != nil:
+		err = waService.SendImage(ctx, replyTo, reply.Image, reply.ImageMimeType, reply.Text)
+	case reply.GIF != nil:
+		err = waService.SendGIF(ctx, replyTo, reply.GIF, reply.Text)
+	case reply.Text != "":
+		err = waService.SendText(ctx, replyTo, reply.Text)
+	}
+	if err == nil && reply.Sticker != nil {
+		err = waService.SendSticker(ctx, replyTo, reply.Sticker)
+	}
+	if err != nil {
+		botMetrics.SendFailed()
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+	return nil
+}
+
+// errNoSession is what group commands that act on WhatsApp answer in a
+// worker, which has no WhatsApp session.
+var errNoSession = errors.New("butuh sesi WhatsApp, tidak tersedia di bot worker")
+
+// workerGroups stands in for the WhatsApp session in "bot worker".
+type workerGroups struct{}
+
+func (workerGroups) JoinGroup(ctx context.Context, linkOrJID string) (string, string, error) {
+	return "", "", errNoSession
+}
+
+func (workerGroups) JoinedGroups(ctx context.Context) ([]domain.JoinedGroup, error) {
+	return nil, errNoSession
+}
+
+func (workerGroups) LeaveGroup(ctx context.Context, jid, farewell string) error {
+	return errNoSession
+}
+
+// reloadGroups picks up groups registered by other processes sharing the
+// database until ctx is done.
+func reloadGroups(ctx context.Context, groupsUC *usecase.ManageGroupsUsecase) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := groupsUC.Load(ctx); err != nil {
+				log.Printf("Failed to reload registered groups: %v", err)
+			}
+		}
+	}
+}
+
+// runWorker handles the messages the bot process queued on QUEUE_URL until
+// it is stopped. Workers have no WhatsApp session, so any number of them
+// can run against the same database.
+func runWorker(cfg config.Config, repos *repository.Repositories, handleMessageUC *usecase.HandleMessageUsecase,
+	leaderboardUC *usecase.GetLeaderboardUsecase, reportUC *usecase.ReportActivityUsecase, botMetrics *metrics.Collector, reporter *sentry.Reporter) error {
+	if cfg.QueueURL == "" {
+		return errors.New("bot worker needs QUEUE_URL")
+	}
+	msgQueue, err := queue.NewRedis(cfg.QueueURL, cfg.Tenant)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the queue: %w", err)
+	}
+	defer msgQueue.Close()
+
+	groupsUC := usecase.NewManageGroupsUsecase(repos.Groups, workerGroups{}, cfg.GroupID)
+	if err := groupsUC.Load(context.Background()); err != nil {
+		log.Printf("Failed to load registered groups: %v", err)
+	}
+	groupsUC.SetLeaderboard(leaderboardUC, nil)
+	handleMessageUC.SetGroupsUsecase(groupsUC)
+	pipeline := newMessagePipeline(cfg, repos, groupsUC, handleMessageUC, reportUC, botMetrics, reporter)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go reloadGroups(ctx, groupsUC)
+
+	host, _ := os.Hostname()
+	workers := cfg.QueueWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		consumer := fmt.Sprintf("%s-%d-%d", host, os.Getpid(), i)
+		go func() {
+			defer wg.Done()
+			err := msgQueue.ConsumeIncoming(ctx, consumer, func(ctx context.Context, in *queue.Incoming) error {
+				reply := pipeline.Handle(ctx, in)
+				if reply == nil {
+					return nil
+				}
+				return msgQueue.PublishReply(ctx, &queue.Outgoing{To: *in, Reply: *reply})
+			})
+			if err != nil {
+				log.Printf("Worker %s stopped: %v", consumer, err)
+			}
+		}()
+	}
+
+	log.Printf("Worker handling queued messages with %d consumers... Press Ctrl+C to exit.", workers)
+	wg.Wait()
+	log.Println("Worker stopped")
+	return nil
+}
@@ -0,0 +1,238 @@
+// Package customcmd lets operators add simple chat commands without
+// recompiling the bot. Each command answers with a text/template reply that
+// can read, but not change, the report data.
+package customcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// maxReplyLen caps a reply so a template can't flood the group.
+const maxReplyLen = 4000
+
+// maxTop caps the lists returned by top and topstreak.
+const maxTop = 50
+
+var validName = regexp.MustCompile(`^[a-z]+$`)
+
+var errReplyTooLong = errors.New("reply is too long")
+
+// Definition is one command in the CUSTOM_COMMANDS_FILE.
+type Definition struct {
+	Name        string `json:"name"` // without the prefix, lowercase letters only
+	Usage       string `json:"usage"`
+	Description string `json:"description"`
+	Admin       bool   `json:"admin"`
+	Reply       string `json:"reply"` // text/template, see Data
+}
+
+// Data is what a reply template sees.
+type Data struct {
+	UserID string
+	Name   string
+	Args   []string // lowercased words after the command
+	Text   string   // the words after the command as typed
+	Report *Member  // the sender's report, nil if they never reported
+}
+
+// Member is a read-only copy of a report.
+type Member struct {
+	Name           string
+	Streak         int
+	ActivityCount  int
+	LastReportDate time.Time
+}
+
+// Command is a custom command. It implements usecase.Command.
+type Command struct {
+	def      Definition
+	tmpl     *template.Template
+	reports  domain.ReportRepository
+	settings domain.SettingsRepository
+}
+
+// New checks the definition and parses its reply template. Members left out
+// of the ranking or in #privat mode never appear in top and topstreak;
+// settings may be nil when nobody can be.
+func New(def Definition, reports domain.ReportRepository, settings domain.SettingsRepository) (*Command, error) {
+	def.Name = strings.ToLower(strings.TrimSpace(def.Name))
+	if !validName.MatchString(def.Name) {
+		return nil, fmt.Errorf("invalid command name %q: use lowercase letters only", def.Name)
+	}
+	if strings.TrimSpace(def.Reply) == "" {
+		return nil, fmt.Errorf("command #%s has no reply", def.Name)
+	}
+	if def.Description == "" {
+		def.Description = "perintah tambahan"
+	}
+
+	// The real functions need the request context and are bound per message
+	tmpl, err := template.New(def.Name).Funcs(funcs(context.Background(), nil, nil)).Parse(def.Reply)
+	if err != nil {
+		return nil, fmt.Errorf("command #%s: %w", def.Name, err)
+	}
+	return &Command{def: def, tmpl: tmpl, reports: reports, settings: settings}, nil
+}
+
+// Load reads a JSON array of definitions.
+func Load(r io.Reader, reports domain.ReportRepository, settings domain.SettingsRepository) ([]*Command, error) {
+	var defs []Definition
+	if err := json.NewDecoder(r).Decode(&defs); err != nil {
+		return nil, fmt.Errorf("invalid custom commands: %w", err)
+	}
+
+	commands := make([]*Command, 0, len(defs))
+	seen := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		cmd, err := New(def, reports, settings)
+		if err != nil {
+			return nil, err
+		}
+		if seen[cmd.def.Name] {
+			return nil, fmt.Errorf("command #%s is defined twice", cmd.def.Name)
+		}
+		seen[cmd.def.Name] = true
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
+
+// LoadFile reads the definitions from a file.
+func LoadFile(path string, reports domain.ReportRepository, settings domain.SettingsRepository) ([]*Command, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f, reports, settings)
+}
+
+// Match accepts the command alone or followed by arguments, so #jadwal does
+// not also answer #jadwalku.
+func (c *Command) Match(lower string) bool {
+	words := strings.Fields(lower)
+	return len(words) > 0 && words[0] == "#"+c.def.Name
+}
+
+func (c *Command) Execute(ctx context.Context, req usecase.CommandRequest) (*usecase.Reply, error) {
+	data := Data{UserID: req.UserID, Name: req.Name, Args: req.Args}
+	if words := strings.Fields(req.Message); len(words) > 1 {
+		data.Text = strings.Join(words[1:], " ")
+	}
+	report, err := c.reports.GetReport(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if report != nil {
+		data.Report = toMember(report)
+	}
+
+	tmpl, err := c.tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Funcs(funcs(ctx, c.reports, c.settings)).Execute(&limitedWriter{buf: &out}, data); err != nil {
+		return nil, fmt.Errorf("custom command #%s: %w", c.def.Name, err)
+	}
+	return &usecase.Reply{Text: strings.TrimSpace(out.String())}, nil
+}
+
+func (c *Command) Help() usecase.CommandHelp {
+	return usecase.CommandHelp{Name: c.def.Name, Usage: c.def.Usage, Description: c.def.Description}
+}
+
+func (c *Command) Permissions() usecase.Permission {
+	if c.def.Admin {
+		return usecase.PermissionAdmin
+	}
+	return usecase.PermissionMember
+}
+
+// funcs are the only functions a template can call. They read reports and
+// format values; none of them writes.
+func funcs(ctx context.Context, reports domain.ReportRepository, settings domain.SettingsRepository) template.FuncMap {
+	list := func(sort string, n int) ([]*Member, error) {
+		if n < 1 || n > maxTop {
+			return nil, fmt.Errorf("top takes 1 to %d members, got %d", maxTop, n)
+		}
+		unranked, err := unrankedUsers(ctx, settings)
+		if err != nil {
+			return nil, err
+		}
+		// Ask for enough rows to fill n after leaving the unranked out
+		found, err := reports.ListReports(ctx, domain.ReportQuery{Sort: sort, Limit: n + len(unranked)})
+		if err != nil {
+			return nil, err
+		}
+		members := make([]*Member, 0, n)
+		for _, r := range found {
+			if unranked[r.UserID] {
+				continue
+			}
+			if members = append(members, toMember(r)); len(members) == n {
+				break
+			}
+		}
+		return members, nil
+	}
+
+	return template.FuncMap{
+		// top N: the members with the most days reported
+		"top": func(n int) ([]*Member, error) { return list(domain.ReportSortTotal, n) },
+		// topstreak N: the members with the longest streaks
+		"topstreak": func(n int) ([]*Member, error) { return list(domain.ReportSortStreak, n) },
+		// add 1 .: numbering in range loops
+		"add": func(a, b int) int { return a + b },
+		// date .Report.LastReportDate: "2 Jan 2006"
+		"date":  func(t time.Time) string { return t.Format("2 Jan 2006") },
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+// unrankedUsers returns the members admins left out of the ranking or in
+// #privat mode, none without a settings repository.
+func unrankedUsers(ctx context.Context, settings domain.SettingsRepository) (map[string]bool, error) {
+	if settings == nil {
+		return nil, nil
+	}
+	list, err := settings.GetUnrankedSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unranked := make(map[string]bool, len(list))
+	for _, s := range list {
+		unranked[s.UserID] = true
+	}
+	return unranked, nil
+}
+
+func toMember(r *domain.Report) *Member {
+	return &Member{Name: r.Name, Streak: r.Streak, ActivityCount: r.ActivityCount, LastReportDate: r.LastReportDate}
+}
+
+// limitedWriter fails once a reply passes maxReplyLen.
+type limitedWriter struct {
+	buf *bytes.Buffer
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > maxReplyLen {
+		return 0, errReplyTooLong
+	}
+	return w.buf.Write(p)
+}
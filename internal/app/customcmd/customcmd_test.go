@@ -0,0 +1,184 @@
+package customcmd_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/customcmd"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// CUSTOM COMMAND TESTS
+// =============================================================================
+
+// mockReports only implements the reads templates can reach
+type mockReports struct {
+	domain.ReportRepository
+	reports map[string]*domain.Report
+}
+
+func (m *mockReports) GetReport(ctx context.Context, userID string) (*domain.Report, error) {
+	return m.reports[userID], nil
+}
+
+func (m *mockReports) ListReports(ctx context.Context, q domain.ReportQuery) ([]*domain.Report, error) {
+	var list []*domain.Report
+	for _, r := range m.reports {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ActivityCount > list[j].ActivityCount })
+	if len(list) > q.Limit {
+		list = list[:q.Limit]
+	}
+	return list, nil
+}
+
+// mockSettings only implements the reads templates can reach
+type mockSettings struct {
+	domain.SettingsRepository
+	unranked []*domain.UserSettings
+}
+
+func (m *mockSettings) GetUnrankedSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	return m.unranked, nil
+}
+
+const definitions = `[
+	{"name": "jadwal", "description": "jadwal lari bareng", "reply": "Lari bareng tiap Minggu 06.00"},
+	{"name": "streakku", "reply": "{{if .Report}}{{.Name}}: streak {{.Report.Streak}} hari{{else}}{{.Name}} belum pernah #lapor{{end}}"},
+	{"name": "juara", "admin": true, "reply": "{{range $i, $m := top 2}}{{add $i 1}}. {{$m.Name}} - {{$m.ActivityCount}}\n{{end}}"},
+	{"name": "ulang", "reply": "{{upper .Text}}"}
+]`
+
+func newHandler(t *testing.T) *usecase.HandleMessageUsecase {
+	t.Helper()
+
+	repo := &mockReports{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 10, LastReportDate: time.Now()},
+		"user2": {UserID: "user2", Name: "Budi", Streak: 1, ActivityCount: 4, LastReportDate: time.Now()},
+		"user3": {UserID: "user3", Name: "Citra", Streak: 7, ActivityCount: 7, LastReportDate: time.Now()},
+	}}
+	commands, err := customcmd.Load(strings.NewReader(definitions), repo, nil)
+	if err != nil {
+		t.Fatalf("Failed to load commands: %v", err)
+	}
+
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	handleUC.SetAdmins([]string{"user1"})
+	for _, cmd := range commands {
+		if err := handleUC.RegisterCommand(cmd); err != nil {
+			t.Fatalf("Failed to register #%s: %v", cmd.Help().Name, err)
+		}
+	}
+	return handleUC
+}
+
+func TestCustomCommands_Replies(t *testing.T) {
+	handleUC := newHandler(t)
+	ctx := context.Background()
+
+	cases := []struct {
+		userID, name, message, expected string
+	}{
+		{"user2", "Budi", "#jadwal", "Lari bareng tiap Minggu 06.00"},
+		{"user2", "Budi", "#jadwalku", ""},
+		{"user1", "Alice", "#streakku", "Alice: streak 3 hari"},
+		{"user9", "Dewi", "#streakku", "Dewi belum pernah #lapor"},
+		{"user1", "Alice", "#juara", "1. Alice - 10\n2. Citra - 7"},
+		{"user2", "Budi", "#juara", "Perintah ini khusus admin."},
+		{"user2", "Budi", "#ulang Ayo Lari", "AYO LARI"},
+	}
+	for _, c := range cases {
+		result, err := handleUC.Execute(ctx, c.userID, c.name, c.message)
+		if err != nil {
+			t.Fatalf("'%s': unexpected error: %v", c.message, err)
+		}
+		if result != c.expected {
+			t.Errorf("'%s': expected '%s', got '%s'", c.message, c.expected, result)
+		}
+	}
+
+	help, _ := handleUC.Execute(ctx, "user2", "Budi", "#help")
+	if !strings.Contains(help, "#jadwal - jadwal lari bareng") || !strings.Contains(help, "#streakku - perintah tambahan") {
+		t.Errorf("Expected custom commands in #help, got '%s'", help)
+	}
+}
+
+func TestCustomCommands_InvalidDefinitions(t *testing.T) {
+	repo := &mockReports{}
+	invalid := map[string]string{
+		"bad name":       `[{"name": "top-5", "reply": "x"}]`,
+		"empty reply":    `[{"name": "kosong", "reply": " "}]`,
+		"bad template":   `[{"name": "rusak", "reply": "{{if .Report}}"}]`,
+		"unknown func":   `[{"name": "hapus", "reply": "{{delete .UserID}}"}]`,
+		"defined twice":  `[{"name": "a", "reply": "x"}, {"name": "a", "reply": "y"}]`,
+		"not json array": `{"name": "a", "reply": "x"}`,
+	}
+	for name, defs := range invalid {
+		if _, err := customcmd.Load(strings.NewReader(defs), repo, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// Built-in commands can't be replaced
+	commands, err := customcmd.Load(strings.NewReader(`[{"name": "lapor", "reply": "x"}]`), repo, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	if err := handleUC.RegisterCommand(commands[0]); err == nil {
+		t.Error("Expected an error for a custom #lapor")
+	}
+}
+
+func TestCustomCommands_LimitsReplies(t *testing.T) {
+	repo := &mockReports{reports: map[string]*domain.Report{}}
+	commands, err := customcmd.Load(strings.NewReader(`[
+		{"name": "banjir", "reply": "{{range $i, $m := top 50}}{{end}}{{range .Args}}{{.}}{{.}}{{.}}{{.}}{{.}}{{.}}{{.}}{{.}}{{end}}"},
+		{"name": "semua", "reply": "{{top 1000}}"}
+	]`), repo, nil)
+	if err != nil {
+		t.Fatalf("Failed to load commands: %v", err)
+	}
+	ctx := context.Background()
+
+	long := strings.Repeat("x", 600)
+	req := usecase.CommandRequest{UserID: "user1", Name: "Alice", Message: "#banjir " + long, Args: []string{long}}
+	if _, err := commands[0].Execute(ctx, req); err == nil {
+		t.Error("Expected an error for a reply over the limit")
+	}
+	if _, err := commands[1].Execute(ctx, usecase.CommandRequest{UserID: "user1", Message: "#semua"}); err == nil {
+		t.Error("Expected an error for more than 50 members")
+	}
+}
+
+func TestCustomCommands_TopLeavesOutUnranked(t *testing.T) {
+	repo := &mockReports{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", ActivityCount: 10},
+		"user2": {UserID: "user2", Name: "Budi", ActivityCount: 8},
+		"user3": {UserID: "user3", Name: "Citra", ActivityCount: 6},
+		"user4": {UserID: "user4", Name: "Dewi", ActivityCount: 4},
+	}}
+	// Budi is in #privat mode, Citra was left out by an admin
+	settings := &mockSettings{unranked: []*domain.UserSettings{
+		{UserID: "user2", Private: true},
+		{UserID: "user3", Unranked: true},
+	}}
+	commands, err := customcmd.Load(strings.NewReader(`[{"name": "juara", "reply": "{{range top 2}}{{.Name}} {{end}}"}]`), repo, settings)
+	if err != nil {
+		t.Fatalf("Failed to load commands: %v", err)
+	}
+
+	reply, err := commands[0].Execute(context.Background(), usecase.CommandRequest{UserID: "user1", Message: "#juara"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reply.Text != "Alice Dewi" {
+		t.Errorf("Expected the top 2 without Budi and Citra, got '%s'", reply.Text)
+	}
+}
@@ -0,0 +1,92 @@
+package format
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+)
+
+// ChartDay is one column of the activity chart.
+type ChartDay struct {
+	Date     time.Time
+	Reported bool
+	Minutes  int
+}
+
+const (
+	chartBarWidth   = 16
+	chartBarGap     = 4
+	chartPadding    = 20
+	chartHeight     = 240
+	chartMinBar     = 24 // Height of a reported day without duration
+	chartMissedBar  = 4
+	chartMaxMinutes = 120 // Durations above this are drawn at full height
+)
+
+var (
+	chartBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	chartGrid       = color.RGBA{R: 0xe5, G: 0xe7, B: 0xeb, A: 0xff}
+	chartReported   = color.RGBA{R: 0x22, G: 0xc5, B: 0x5e, A: 0xff}
+	chartMissed     = color.RGBA{R: 0xd1, G: 0xd5, B: 0xdb, A: 0xff}
+	chartWeekend    = color.RGBA{R: 0xf3, G: 0xf4, B: 0xf6, A: 0xff}
+)
+
+// RenderActivityChart draws one bar per day as a PNG: green bars for reported
+// days (taller with longer duration), short grey stubs for missed days, and a
+// light band behind weekends.
+func RenderActivityChart(days []ChartDay) ([]byte, error) {
+	width := chartPadding*2 + len(days)*(chartBarWidth+chartBarGap) - chartBarGap
+	if width < chartPadding*2 {
+		width = chartPadding * 2
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, chartHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: chartBackground}, image.Point{}, draw.Src)
+
+	baseline := chartHeight - chartPadding
+	plotHeight := baseline - chartPadding
+
+	// Horizontal grid lines at 30, 60, 90 and 120 minutes
+	for m := 30; m <= chartMaxMinutes; m += 30 {
+		y := baseline - barHeight(m, plotHeight)
+		draw.Draw(img, image.Rect(chartPadding, y, width-chartPadding, y+1), &image.Uniform{C: chartGrid}, image.Point{}, draw.Src)
+	}
+
+	for i, day := range days {
+		x := chartPadding + i*(chartBarWidth+chartBarGap)
+		if wd := day.Date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			draw.Draw(img, image.Rect(x, chartPadding, x+chartBarWidth, baseline), &image.Uniform{C: chartWeekend}, image.Point{}, draw.Src)
+		}
+
+		h, c := chartMissedBar, chartMissed
+		if day.Reported {
+			h, c = barHeight(day.Minutes, plotHeight), chartReported
+		}
+		draw.Draw(img, image.Rect(x, baseline-h, x+chartBarWidth, baseline), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	}
+
+	// Baseline
+	draw.Draw(img, image.Rect(chartPadding, baseline, width-chartPadding, baseline+2), &image.Uniform{C: chartMissed}, image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func barHeight(minutes, plotHeight int) int {
+	if minutes <= 0 {
+		return chartMinBar
+	}
+	if minutes > chartMaxMinutes {
+		minutes = chartMaxMinutes
+	}
+	h := minutes * plotHeight / chartMaxMinutes
+	if h < chartMinBar {
+		h = chartMinBar
+	}
+	return h
+}
@@ -0,0 +1,53 @@
+package format
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	heatmapReported = "🟩"
+	heatmapMissed   = "⬜"
+	heatmapBlank    = "▫️" // Padding before the 1st and days that haven't happened yet
+)
+
+// HeatmapHeader labels the Monday-first columns of EmojiHeatmap.
+const HeatmapHeader = "S  S  R  K  J  S  M"
+
+var monthNames = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// MonthName returns the Indonesian name of a month.
+func MonthName(m time.Month) string {
+	return monthNames[m-1]
+}
+
+// EmojiHeatmap renders days as rows of one week each (Monday first), using
+// 🟩 for reported days and ⬜ for missed ones. Days after today are blank.
+func EmojiHeatmap(days []ChartDay, today time.Time) string {
+	if len(days) == 0 {
+		return ""
+	}
+
+	sb := strings.Builder{}
+	col := (int(days[0].Date.Weekday()) + 6) % 7 // Monday = 0
+	sb.WriteString(strings.Repeat(heatmapBlank, col))
+
+	for _, day := range days {
+		switch {
+		case day.Date.After(today):
+			sb.WriteString(heatmapBlank)
+		case day.Reported:
+			sb.WriteString(heatmapReported)
+		default:
+			sb.WriteString(heatmapMissed)
+		}
+
+		col++
+		if col == 7 {
+			sb.WriteString("\n")
+			col = 0
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
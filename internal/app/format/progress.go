@@ -0,0 +1,33 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	progressFilled = "▓"
+	progressEmpty  = "░"
+)
+
+// DefaultProgressWidth is the number of cells used by report acknowledgments.
+const DefaultProgressWidth = 10
+
+// ProgressBar renders current/total as a fixed-width bar followed by the
+// numbers, e.g. "▓▓▓▓▓░░░░░ 15/30". Values above total show a full bar but
+// keep the real count.
+func ProgressBar(current, total, width int) string {
+	if total <= 0 || width <= 0 {
+		return fmt.Sprintf("%d/%d", current, total)
+	}
+
+	filled := 0
+	if current > 0 {
+		filled = (current*width + total/2) / total
+	}
+	if filled > width {
+		filled = width
+	}
+
+	return fmt.Sprintf("%s%s %d/%d", strings.Repeat(progressFilled, filled), strings.Repeat(progressEmpty, width-filled), current, total)
+}
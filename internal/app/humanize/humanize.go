@@ -0,0 +1,174 @@
+// Package humanize holds the settings that make the bot behave like a
+// person's phone rather than a script, which WhatsApp is quicker to ban.
+//
+// The features work together on every reply:
+//
+//  1. MarkRead: the command turns blue first, as if someone opened the chat.
+//  2. Typing: "sedang mengetik..." is shown for the reply delay.
+//  3. ReplyDelay: a random pause before answering.
+//  4. Pacing: the message then waits for its slot in the send queue, behind
+//     other replies but ahead of scheduled broadcasts. This wait comes on
+//     top of the reply delay, and also applies to reminders and recaps,
+//     which skip steps 1-3.
+//
+// Presence runs on its own schedule. Outside PresenceHours the bot still
+// answers, but it does not show typing: an account that is not online
+// cannot be typing.
+package humanize
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Settings is the resolved humanization config.
+type Settings struct {
+	ReplyDelayMin time.Duration
+	ReplyDelayMax time.Duration // Random delay between min and max, max <= min = fixed min
+	Typing        bool          // Show typing during the reply delay
+	MarkRead      bool          // Send read receipts for answered commands
+	PresenceHours string        // Appear online only during these hours, empty = leave presence alone
+	Pacing        string        // Name of the PacingProfile for all outgoing messages
+}
+
+// Presets are the bundles HUMANIZE can name. "normal" matches what the bot
+// did before presets existed.
+var Presets = map[string]Settings{
+	"off":    {Pacing: "off"},
+	"normal": {Pacing: "normal"},
+	"human": {
+		ReplyDelayMin: time.Second, ReplyDelayMax: 3 * time.Second,
+		Typing: true, MarkRead: true, PresenceHours: "07:00-22:00", Pacing: "normal",
+	},
+	"cautious": {
+		ReplyDelayMin: 2 * time.Second, ReplyDelayMax: 6 * time.Second,
+		Typing: true, MarkRead: true, PresenceHours: "07:00-22:00", Pacing: "conservative",
+	},
+}
+
+// Overrides replace single settings of a preset. Nil keeps the preset's
+// value.
+type Overrides struct {
+	ReplyDelayMin *time.Duration
+	ReplyDelayMax *time.Duration
+	Typing        *bool
+	MarkRead      *bool
+	PresenceHours *string // "off" turns the preset's presence hours off
+	Pacing        *string
+}
+
+// Resolve applies the overrides to the named preset and checks the result.
+func Resolve(preset string, o Overrides) (Settings, error) {
+	s, ok := Presets[preset]
+	if !ok {
+		names := make([]string, 0, len(Presets))
+		for name := range Presets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return Settings{}, fmt.Errorf("unknown preset %q, use %s", preset, strings.Join(names, ", "))
+	}
+
+	if o.ReplyDelayMin != nil {
+		s.ReplyDelayMin = *o.ReplyDelayMin
+	}
+	if o.ReplyDelayMax != nil {
+		s.ReplyDelayMax = *o.ReplyDelayMax
+	}
+	if o.Typing != nil {
+		s.Typing = *o.Typing
+	}
+	if o.MarkRead != nil {
+		s.MarkRead = *o.MarkRead
+	}
+	if o.PresenceHours != nil {
+		s.PresenceHours = *o.PresenceHours
+		if s.PresenceHours == "off" {
+			s.PresenceHours = ""
+		}
+	}
+	if o.Pacing != nil {
+		s.Pacing = *o.Pacing
+	}
+
+	if _, err := LookupPacingProfile(s.Pacing); err != nil {
+		return Settings{}, err
+	}
+	if _, err := s.Presence(); err != nil {
+		return Settings{}, err
+	}
+	return s, nil
+}
+
+// Presence returns the presence schedule, nil when presence is left alone.
+func (s Settings) Presence() (*PresenceSchedule, error) {
+	if s.PresenceHours == "" {
+		return nil, nil
+	}
+	return ParsePresenceHours(s.PresenceHours)
+}
+
+// PacingProfile returns the profile named by Pacing.
+func (s Settings) PacingProfile() PacingProfile {
+	profile, _ := LookupPacingProfile(s.Pacing)
+	return profile
+}
+
+// ReplyDelay draws the pause before a reply from rnd, or from the shared
+// source when rnd is nil.
+func (s Settings) ReplyDelay(rnd *rand.Rand) time.Duration {
+	if s.ReplyDelayMax > s.ReplyDelayMin {
+		n := int64(s.ReplyDelayMax-s.ReplyDelayMin) + 1
+		if rnd == nil {
+			return s.ReplyDelayMin + time.Duration(rand.Int63n(n))
+		}
+		return s.ReplyDelayMin + time.Duration(rnd.Int63n(n))
+	}
+	return s.ReplyDelayMin
+}
+
+// Chat is the chat a reply goes to.
+type Chat interface {
+	MarkRead(ctx context.Context) error
+	SetTyping(ctx context.Context, typing bool) error
+}
+
+// BeforeReply runs steps 1-3 of the reply sequence: mark the command as
+// read, then show typing for the reply delay. Failures are logged; the reply
+// goes out either way.
+func (s Settings) BeforeReply(ctx context.Context, chat Chat, rnd *rand.Rand, now time.Time) {
+	if s.MarkRead {
+		if err := chat.MarkRead(ctx); err != nil {
+			log.Printf("Failed to mark message as read: %v", err)
+		}
+	}
+
+	delay := s.ReplyDelay(rnd)
+	if delay <= 0 {
+		return
+	}
+	log.Printf("Delaying reply by %s", delay)
+	typing := s.Typing
+	if presence, _ := s.Presence(); presence != nil && !presence.Online(now) {
+		typing = false
+	}
+	if typing {
+		_ = chat.SetTyping(ctx, true)
+	}
+
+	timer := time.NewTimer(delay)
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	timer.Stop()
+
+	if typing {
+		_ = chat.SetTyping(ctx, false)
+	}
+}
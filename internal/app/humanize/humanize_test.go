@@ -0,0 +1,124 @@
+package humanize_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/humanize"
+)
+
+// =============================================================================
+// HUMANIZE SETTINGS TESTS
+// =============================================================================
+
+type fakeChat struct {
+	calls []string
+}
+
+func (c *fakeChat) MarkRead(ctx context.Context) error {
+	c.calls = append(c.calls, "read")
+	return nil
+}
+
+func (c *fakeChat) SetTyping(ctx context.Context, typing bool) error {
+	if typing {
+		c.calls = append(c.calls, "typing")
+	} else {
+		c.calls = append(c.calls, "paused")
+	}
+	return nil
+}
+
+func TestResolve_PresetsAndOverrides(t *testing.T) {
+	normal, err := humanize.Resolve("normal", humanize.Overrides{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if normal.Typing || normal.MarkRead || normal.ReplyDelayMin != 0 || normal.PresenceHours != "" || normal.Pacing != "normal" {
+		t.Errorf("Expected normal to only pace sends, got %+v", normal)
+	}
+
+	off, typing, delay := "off", false, 500*time.Millisecond
+	human, err := humanize.Resolve("human", humanize.Overrides{PresenceHours: &off, Typing: &typing, ReplyDelayMin: &delay})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if human.PresenceHours != "" || human.Typing || human.ReplyDelayMin != delay || !human.MarkRead || human.ReplyDelayMax != 3*time.Second {
+		t.Errorf("Expected the overrides on top of the human preset, got %+v", human)
+	}
+	if presence, err := human.Presence(); presence != nil || err != nil {
+		t.Errorf("Expected no presence schedule, got %v, %v", presence, err)
+	}
+
+	if cautious, _ := humanize.Resolve("cautious", humanize.Overrides{}); cautious.PacingProfile().PerMinute != 10 {
+		t.Errorf("Expected the conservative profile, got %+v", cautious.PacingProfile())
+	}
+
+	bad, hours := "agresif", "pagi"
+	if _, err := humanize.Resolve("robot", humanize.Overrides{}); err == nil {
+		t.Error("Expected an error for an unknown preset")
+	}
+	if _, err := humanize.Resolve("normal", humanize.Overrides{Pacing: &bad}); err == nil {
+		t.Error("Expected an error for an unknown pacing profile")
+	}
+	if _, err := humanize.Resolve("normal", humanize.Overrides{PresenceHours: &hours}); err == nil {
+		t.Error("Expected an error for invalid presence hours")
+	}
+}
+
+func TestSettings_ReplyDelayWithinRange(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	s := humanize.Settings{ReplyDelayMin: time.Second, ReplyDelayMax: 3 * time.Second}
+	for i := 0; i < 50; i++ {
+		if d := s.ReplyDelay(rnd); d < time.Second || d > 3*time.Second {
+			t.Fatalf("Expected a delay between 1s and 3s, got %s", d)
+		}
+	}
+
+	fixed := humanize.Settings{ReplyDelayMin: 2 * time.Second}
+	if d := fixed.ReplyDelay(nil); d != 2*time.Second {
+		t.Errorf("Expected a fixed delay of 2s, got %s", d)
+	}
+}
+
+func TestSettings_BeforeReply(t *testing.T) {
+	ctx := context.Background()
+	rnd := rand.New(rand.NewSource(1))
+	s := humanize.Settings{
+		ReplyDelayMin: time.Millisecond,
+		Typing:        true,
+		MarkRead:      true,
+		PresenceHours: "07:00-22:00",
+	}
+
+	chat := &fakeChat{}
+	s.BeforeReply(ctx, chat, rnd, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	if len(chat.calls) != 3 || chat.calls[0] != "read" || chat.calls[1] != "typing" || chat.calls[2] != "paused" {
+		t.Errorf("Expected read, typing, paused, got %v", chat.calls)
+	}
+
+	// Nobody types while offline
+	chat = &fakeChat{}
+	s.BeforeReply(ctx, chat, rnd, time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC))
+	if len(chat.calls) != 1 || chat.calls[0] != "read" {
+		t.Errorf("Expected only the read receipt at night, got %v", chat.calls)
+	}
+
+	// No delay, no typing
+	chat = &fakeChat{}
+	humanize.Settings{Typing: true}.BeforeReply(ctx, chat, rnd, time.Now())
+	if len(chat.calls) != 0 {
+		t.Errorf("Expected nothing without a delay, got %v", chat.calls)
+	}
+}
+
+func TestLookupPacingProfile(t *testing.T) {
+	if _, err := humanize.LookupPacingProfile("agresif"); err == nil {
+		t.Error("Expected an error for an unknown profile")
+	}
+	if off, err := humanize.LookupPacingProfile("off"); err != nil || off.PerMinute != 0 || off.MaxGap != 0 {
+		t.Errorf("Expected the off profile to pace nothing, got %+v, %v", off, err)
+	}
+}
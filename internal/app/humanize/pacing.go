@@ -0,0 +1,36 @@
+package humanize
+
+import (
+	"fmt"
+	"time"
+)
+
+// PacingProfile limits how fast the bot sends. WhatsApp bans accounts that
+// send like a machine, e.g. fifty reminders in the same second, so every
+// outgoing message waits for a random gap after the previous one and no
+// more than PerMinute messages go out in any minute.
+type PacingProfile struct {
+	PerMinute int           // Messages in any 60 seconds, 0 = no cap
+	MinGap    time.Duration // Random gap between two messages
+	MaxGap    time.Duration
+	// The gap grows as the minute fills up: with the window half full it is
+	// 1.5 times as long. A burst is spread out instead of hitting the cap
+	// and then stalling.
+	Smooth bool
+}
+
+// PacingProfiles are the profiles SEND_PACING can name.
+var PacingProfiles = map[string]PacingProfile{
+	"off":          {},
+	"normal":       {PerMinute: 30, MinGap: 500 * time.Millisecond, MaxGap: 1500 * time.Millisecond, Smooth: true},
+	"conservative": {PerMinute: 10, MinGap: 3 * time.Second, MaxGap: 8 * time.Second, Smooth: true},
+}
+
+// LookupPacingProfile returns the named profile.
+func LookupPacingProfile(name string) (PacingProfile, error) {
+	profile, ok := PacingProfiles[name]
+	if !ok {
+		return PacingProfile{}, fmt.Errorf("unknown pacing profile %q, use off, normal or conservative", name)
+	}
+	return profile, nil
+}
@@ -0,0 +1,50 @@
+package humanize
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PresenceSchedule is when the bot shows as online. Outside these hours it
+// is unavailable, so the linked account is not online all night, which is a
+// typical sign of a bot. Commands are still answered at any hour.
+type PresenceSchedule struct {
+	start, end int // Minutes after local midnight; end before start wraps past midnight
+}
+
+// ParsePresenceHours parses "07:00-22:00". "20:00-02:00" runs past
+// midnight.
+func ParsePresenceHours(hours string) (*PresenceSchedule, error) {
+	from, to, ok := strings.Cut(hours, "-")
+	if !ok {
+		return nil, fmt.Errorf("presence hours %q: want HH:MM-HH:MM", hours)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(from))
+	if err != nil {
+		return nil, fmt.Errorf("presence hours %q: want HH:MM-HH:MM", hours)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(to))
+	if err != nil {
+		return nil, fmt.Errorf("presence hours %q: want HH:MM-HH:MM", hours)
+	}
+	p := &PresenceSchedule{start: start.Hour()*60 + start.Minute(), end: end.Hour()*60 + end.Minute()}
+	if p.start == p.end {
+		return nil, fmt.Errorf("presence hours %q: start and end are the same", hours)
+	}
+	return p, nil
+}
+
+// Online reports whether the bot should be online at t, in t's location.
+func (p *PresenceSchedule) Online(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if p.start < p.end {
+		return m >= p.start && m < p.end
+	}
+	return m >= p.start || m < p.end
+}
+
+// Cron returns the daily schedules of going online and offline.
+func (p *PresenceSchedule) Cron() (online, offline string) {
+	return fmt.Sprintf("%d %d * * *", p.start%60, p.start/60), fmt.Sprintf("%d %d * * *", p.end%60, p.end/60)
+}
@@ -0,0 +1,57 @@
+package humanize_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/humanize"
+)
+
+// =============================================================================
+// PRESENCE SCHEDULE TESTS
+// =============================================================================
+
+func TestPresenceSchedule_Online(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 3, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	day, err := humanize.ParsePresenceHours("07:00-22:00")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	night, err := humanize.ParsePresenceHours("20:00 - 02:30")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		schedule *humanize.PresenceSchedule
+		at       time.Time
+		want     bool
+	}{
+		{day, at(4, 0), false},
+		{day, at(7, 0), true},
+		{day, at(21, 59), true},
+		{day, at(22, 0), false},
+		{night, at(23, 0), true},
+		{night, at(2, 29), true},
+		{night, at(2, 30), false},
+		{night, at(12, 0), false},
+	}
+	for _, tt := range tests {
+		if got := tt.schedule.Online(tt.at); got != tt.want {
+			t.Errorf("Online(%s) = %v, want %v", tt.at.Format("15:04"), got, tt.want)
+		}
+	}
+
+	if online, offline := night.Cron(); online != "0 20 * * *" || offline != "30 2 * * *" {
+		t.Errorf("Expected cron 0 20 and 30 2, got %q and %q", online, offline)
+	}
+
+	for _, bad := range []string{"", "07:00", "7-22", "07:00-25:00", "08:00-08:00"} {
+		if _, err := humanize.ParsePresenceHours(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package loadtest pumps synthetic group messages through the message
+// pipeline to measure throughput and latency against a real database.
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+// Handler is the message pipeline under test.
+type Handler interface {
+	ExecuteInChat(ctx context.Context, chatJID, userID, name, message string) (*usecase.Reply, error)
+}
+
+type Options struct {
+	Rate     int           // messages per second
+	Duration time.Duration // how long to generate messages
+	Workers  int           // messages handled concurrently
+	Users    int           // distinct senders
+	ChatJID  string
+}
+
+// Result is the outcome of a run. Latency counts from the moment a message
+// was generated, so time waiting for a free worker is included.
+type Result struct {
+	Sent    int // generated
+	Dropped int // the queue was full, the pipeline could not keep up
+	Failed  int // the handler returned an error
+	Locked  int // of Failed, errors from SQLite lock contention
+	Elapsed time.Duration
+
+	latencies []time.Duration
+	errors    map[string]int
+}
+
+// Run generates messages at opts.Rate until opts.Duration has passed or ctx
+// is cancelled, then waits for the queue to drain.
+func Run(ctx context.Context, h Handler, opts Options, rng *rand.Rand) *Result {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.Users < 1 {
+		opts.Users = 1
+	}
+
+	type job struct {
+		at      time.Time
+		userID  string
+		message string
+	}
+	jobs := make(chan job, opts.Workers*10)
+	result := &Result{errors: make(map[string]int)}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				_, err := h.ExecuteInChat(ctx, opts.ChatJID, j.userID, "Load "+j.userID, j.message)
+				latency := time.Since(j.at)
+
+				mu.Lock()
+				result.latencies = append(result.latencies, latency)
+				if err != nil {
+					result.Failed++
+					if isLockError(err) {
+						result.Locked++
+					}
+					result.errors[err.Error()]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Second / time.Duration(max(opts.Rate, 1)))
+	deadline := time.NewTimer(opts.Duration)
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-deadline.C:
+			break loop
+		case now := <-ticker.C:
+			result.Sent++
+			j := job{at: now, userID: fmt.Sprintf("6289%08d", rng.Intn(opts.Users)), message: message(rng)}
+			select {
+			case jobs <- j:
+			default:
+				result.Dropped++
+			}
+		}
+	}
+	ticker.Stop()
+	deadline.Stop()
+	close(jobs)
+	wg.Wait()
+	result.Elapsed = time.Since(start)
+	return result
+}
+
+// message picks a message with roughly the mix of a busy group: mostly
+// reports, some queries and some chatter the bot ignores.
+func message(rng *rand.Rand) string {
+	switch n := rng.Intn(100); {
+	case n < 60:
+		return []string{"#lapor", "#lapor lari 5km 30 menit", "#lapor gym 45 menit", "#lapor yoga"}[rng.Intn(4)]
+	case n < 70:
+		return "#leaderboard"
+	case n < 80:
+		return "#stats"
+	case n < 85:
+		return "#history"
+	default:
+		return "semangat semuanya!"
+	}
+}
+
+func isLockError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "SQLITE_BUSY")
+}
+
+// Percentile returns the p-th percentile latency (0-100) of handled
+// messages.
+func (r *Result) Percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	i := int(float64(len(sorted)-1) * p / 100)
+	return sorted[i]
+}
+
+// Handled is the number of messages that went through the pipeline.
+func (r *Result) Handled() int {
+	return len(r.latencies)
+}
+
+func (r *Result) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Generated %d messages in %s, handled %d (%.1f msg/s)\n",
+		r.Sent, r.Elapsed.Round(time.Millisecond), r.Handled(), float64(r.Handled())/r.Elapsed.Seconds())
+	fmt.Fprintf(&sb, "Latency p50 %s, p95 %s, p99 %s, max %s\n",
+		r.Percentile(50).Round(time.Microsecond), r.Percentile(95).Round(time.Microsecond),
+		r.Percentile(99).Round(time.Microsecond), r.Percentile(100).Round(time.Microsecond))
+	fmt.Fprintf(&sb, "Dropped %d (queue full), failed %d, of which %d database locked", r.Dropped, r.Failed, r.Locked)
+
+	if len(r.errors) > 0 {
+		msgs := make([]string, 0, len(r.errors))
+		for msg := range r.errors {
+			msgs = append(msgs, msg)
+		}
+		sort.Slice(msgs, func(i, j int) bool { return r.errors[msgs[i]] > r.errors[msgs[j]] })
+		for i, msg := range msgs {
+			if i == 5 {
+				break
+			}
+			fmt.Fprintf(&sb, "\n  %dx %s", r.errors[msg], msg)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+package loadtest_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/loadtest"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+type fakeHandler struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (h *fakeHandler) ExecuteInChat(ctx context.Context, chatJID, userID, name, message string) (*usecase.Reply, error) {
+	h.mu.Lock()
+	h.calls++
+	n := h.calls
+	h.mu.Unlock()
+	if n%4 == 0 {
+		return nil, errors.New("failed to save report: database is locked (5) (SQLITE_BUSY)")
+	}
+	return nil, nil
+}
+
+func TestRun(t *testing.T) {
+	h := &fakeHandler{}
+	opts := loadtest.Options{Rate: 200, Duration: 200 * time.Millisecond, Workers: 2, Users: 10, ChatJID: "group@g.us"}
+	result := loadtest.Run(context.Background(), h, opts, rand.New(rand.NewSource(1)))
+
+	if result.Sent == 0 {
+		t.Fatal("expected messages to be generated")
+	}
+	if result.Handled()+result.Dropped != result.Sent {
+		t.Errorf("handled %d + dropped %d != sent %d", result.Handled(), result.Dropped, result.Sent)
+	}
+	if result.Handled() != h.calls {
+		t.Errorf("handled %d, handler called %d times", result.Handled(), h.calls)
+	}
+	if result.Failed != h.calls/4 || result.Locked != result.Failed {
+		t.Errorf("failed %d locked %d, want %d", result.Failed, result.Locked, h.calls/4)
+	}
+	if result.Percentile(50) > result.Percentile(100) {
+		t.Errorf("p50 %s above max %s", result.Percentile(50), result.Percentile(100))
+	}
+	if !strings.Contains(result.String(), "database is locked") {
+		t.Errorf("expected the lock error in the summary:\n%s", result)
+	}
+}
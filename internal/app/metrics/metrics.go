@@ -0,0 +1,73 @@
+// Package metrics keeps in-memory operational counters of the running bot.
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Snapshot is a point-in-time copy of the counters.
+type Snapshot struct {
+	StartedAt         time.Time
+	MessagesToday     int
+	SendFailuresToday int
+	SendFailuresTotal int
+}
+
+// Collector counts processed messages and send failures. Daily counters
+// reset at local midnight. Safe for concurrent use.
+type Collector struct {
+	mu                sync.Mutex
+	startedAt         time.Time
+	day               string
+	messagesToday     int
+	sendFailuresToday int
+	sendFailuresTotal int
+}
+
+func New() *Collector {
+	now := time.Now()
+	return &Collector{startedAt: now, day: now.Format("2006-01-02")}
+}
+
+// MessageProcessed records an incoming message handled by the bot.
+func (c *Collector) MessageProcessed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rollover()
+	c.messagesToday++
+}
+
+// SendFailed records a reply that could not be delivered.
+func (c *Collector) SendFailed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rollover()
+	c.sendFailuresToday++
+	c.sendFailuresTotal++
+}
+
+func (c *Collector) Snapshot() Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rollover()
+	return Snapshot{
+		StartedAt:         c.startedAt,
+		MessagesToday:     c.messagesToday,
+		SendFailuresToday: c.sendFailuresToday,
+		SendFailuresTotal: c.sendFailuresTotal,
+	}
+}
+
+// rollover resets the daily counters on the first call of a new day.
+func (c *Collector) rollover() {
+	today := time.Now().Format("2006-01-02")
+	if today != c.day {
+		c.day = today
+		c.messagesToday = 0
+		c.sendFailuresToday = 0
+	}
+}
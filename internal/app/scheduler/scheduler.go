@@ -0,0 +1,164 @@
+// Package scheduler runs the bot's background jobs: recurring jobs on a cron
+// schedule and one-shot jobs at a given time. Jobs are stored in the
+// database, so a restart neither loses one-shot jobs nor forgets when a
+// recurring job last ran. Runs missed while the bot was offline are caught
+// up once if they are not older than the catch-up window, and skipped
+// otherwise, so a bot that was down all weekend does not post Saturday's
+// reminder on Monday.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// Handler runs a job. A returned error is logged and kept in LastError;
+// recurring jobs still run again at their next time.
+type Handler func(ctx context.Context, job *domain.Job) error
+
+type Scheduler struct {
+	jobs    domain.JobRepository
+	catchUp time.Duration
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+}
+
+// tick is how often Run checks for due jobs, so how late a job may run
+// without having been missed.
+const tick = time.Minute
+
+// New returns a scheduler that catches up runs missed by at most catchUp.
+// A catchUp under one tick still runs jobs that are due, it only stops
+// catching up.
+func New(jobs domain.JobRepository, catchUp time.Duration) *Scheduler {
+	if catchUp < tick {
+		catchUp = tick
+	}
+	return &Scheduler{jobs: jobs, catchUp: catchUp, handlers: make(map[string]Handler)}
+}
+
+// Handle sets the handler for jobs of a kind, e.g. the one-shot jobs added
+// with Once.
+func (s *Scheduler) Handle(kind string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = h
+}
+
+// Every stores a recurring job and its handler. The job's kind is its name.
+// If the job is already stored with the same schedule its next run is kept,
+// so a run that was due while the bot was offline is caught up.
+func (s *Scheduler) Every(ctx context.Context, name, schedule string, h Handler) error {
+	cron, err := ParseCron(schedule)
+	if err != nil {
+		return err
+	}
+	next := cron.Next(time.Now())
+	if next.IsZero() {
+		return fmt.Errorf("cron %q never runs", schedule)
+	}
+	s.Handle(name, h)
+
+	job, err := s.jobs.GetJob(ctx, name)
+	if err != nil {
+		return err
+	}
+	if job != nil && job.Schedule == schedule && job.Kind == name {
+		return nil
+	}
+	return s.jobs.SaveJob(ctx, &domain.Job{Name: name, Kind: name, Schedule: schedule, NextRun: next})
+}
+
+// Once stores a one-shot job that runs the kind's handler at the given
+// time with payload. A job with the same name is replaced.
+func (s *Scheduler) Once(ctx context.Context, name, kind string, at time.Time, payload string) error {
+	return s.jobs.SaveJob(ctx, &domain.Job{Name: name, Kind: kind, Payload: payload, NextRun: at})
+}
+
+// Cancel deletes a job. Cancelling a job that does not exist is not an
+// error.
+func (s *Scheduler) Cancel(ctx context.Context, name string) error {
+	return s.jobs.DeleteJob(ctx, name)
+}
+
+// Jobs lists the stored jobs, the next one to run first.
+func (s *Scheduler) Jobs(ctx context.Context) ([]*domain.Job, error) {
+	return s.jobs.GetJobs(ctx)
+}
+
+// RunDue runs every job whose time has come by now. Jobs without a
+// handler, e.g. a reminder whose REMINDER_TIME was removed, are deleted.
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) error {
+	jobs, err := s.jobs.GetJobs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.NextRun.After(now) {
+			continue
+		}
+		if err := s.run(ctx, job, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) run(ctx context.Context, job *domain.Job, now time.Time) error {
+	s.mu.Lock()
+	h := s.handlers[job.Kind]
+	s.mu.Unlock()
+	if h == nil {
+		log.Printf("Removing job %s: nothing handles %q anymore", job.Name, job.Kind)
+		return s.jobs.DeleteJob(ctx, job.Name)
+	}
+
+	if late := now.Sub(job.NextRun); late > s.catchUp {
+		log.Printf("Skipping job %s: missed by %s", job.Name, late.Round(time.Minute))
+	} else {
+		job.LastRun, job.LastError = now, ""
+		if err := h(ctx, job); err != nil {
+			log.Printf("Job %s failed: %v", job.Name, err)
+			job.LastError = err.Error()
+		}
+	}
+
+	if job.Schedule == "" {
+		return s.jobs.DeleteJob(ctx, job.Name)
+	}
+	cron, err := ParseCron(job.Schedule)
+	if err != nil {
+		log.Printf("Removing job %s: %v", job.Name, err)
+		return s.jobs.DeleteJob(ctx, job.Name)
+	}
+	if job.NextRun = cron.Next(now.In(time.Local)); job.NextRun.IsZero() {
+		log.Printf("Removing job %s: %q never runs again", job.Name, job.Schedule)
+		return s.jobs.DeleteJob(ctx, job.Name)
+	}
+	return s.jobs.SaveJob(ctx, job)
+}
+
+// Run runs due jobs right away, which catches up runs missed while the bot
+// was offline, and then every minute until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunDue(ctx, time.Now()); err != nil {
+			log.Printf("Failed to run scheduled jobs: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
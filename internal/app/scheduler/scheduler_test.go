@@ -0,0 +1,263 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/scheduler"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// SCHEDULER TESTS
+// =============================================================================
+
+type mockJobs struct {
+	jobs map[string]domain.Job
+}
+
+func newMockJobs() *mockJobs {
+	return &mockJobs{jobs: make(map[string]domain.Job)}
+}
+
+func (m *mockJobs) GetJob(ctx context.Context, name string) (*domain.Job, error) {
+	job, ok := m.jobs[name]
+	if !ok {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func (m *mockJobs) GetJobs(ctx context.Context) ([]*domain.Job, error) {
+	var list []*domain.Job
+	for _, job := range m.jobs {
+		job := job
+		list = append(list, &job)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].NextRun.Before(list[j].NextRun) })
+	return list, nil
+}
+
+func (m *mockJobs) SaveJob(ctx context.Context, job *domain.Job) error {
+	m.jobs[job.Name] = *job
+	return nil
+}
+
+func (m *mockJobs) DeleteJob(ctx context.Context, name string) error {
+	delete(m.jobs, name)
+	return nil
+}
+
+func (m *mockJobs) InitTable(ctx context.Context) error { return nil }
+
+func TestParseCron_Next(t *testing.T) {
+	loc := time.UTC
+	// Sunday 1 March 2026, 10:17
+	from := time.Date(2026, 3, 1, 10, 17, 30, 0, loc)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 1, 10, 18, 0, 0, loc)},
+		{"30 19 * * *", time.Date(2026, 3, 1, 19, 30, 0, 0, loc)},
+		{"0 9 * * *", time.Date(2026, 3, 2, 9, 0, 0, 0, loc)},
+		{"*/15 * * * *", time.Date(2026, 3, 1, 10, 30, 0, 0, loc)},
+		{"0 20 * * 0", time.Date(2026, 3, 1, 20, 0, 0, 0, loc)},
+		{"0 20 * * 7", time.Date(2026, 3, 1, 20, 0, 0, 0, loc)},
+		{"0 6 * * 1-5", time.Date(2026, 3, 2, 6, 0, 0, 0, loc)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, loc)},
+		{"@weekly", time.Date(2026, 3, 8, 0, 0, 0, 0, loc)},
+		// Either day field matches: the 15th or any Monday
+		{"0 8 15 * 1", time.Date(2026, 3, 2, 8, 0, 0, 0, loc)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		cron, err := scheduler.ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if got := cron.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: expected %s, got %s", tt.expr, tt.want, got)
+		}
+	}
+
+	never, err := scheduler.ParseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := never.Next(from); !got.IsZero() {
+		t.Errorf("Expected no run for 31 February, got %s", got)
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "x * * * *"} {
+		if _, err := scheduler.ParseCron(expr); err == nil {
+			t.Errorf("Expected an error for %q", expr)
+		}
+	}
+}
+
+func TestScheduler_EveryRunsAndReschedules(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockJobs()
+	s := scheduler.New(repo, time.Hour)
+
+	runs := 0
+	run := func(ctx context.Context, job *domain.Job) error {
+		runs++
+		return errors.New("boom")
+	}
+	if err := s.Every(ctx, "recap", "0 20 * * 0", run); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	due := repo.jobs["recap"].NextRun
+	if due.Weekday() != time.Sunday || due.Hour() != 20 {
+		t.Fatalf("Expected the next Sunday 20:00, got %s", due)
+	}
+
+	// Nothing runs early
+	if err := s.RunDue(ctx, due.Add(-time.Minute)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if runs != 0 {
+		t.Fatalf("Expected no run before the job is due, got %d", runs)
+	}
+
+	// A restart keeps the stored next run, so a missed run is caught up
+	s = scheduler.New(repo, time.Hour)
+	if err := s.Every(ctx, "recap", "0 20 * * 0", run); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !repo.jobs["recap"].NextRun.Equal(due) {
+		t.Fatalf("Expected the next run to be kept, got %s", repo.jobs["recap"].NextRun)
+	}
+	late := due.Add(30 * time.Minute)
+	if err := s.RunDue(ctx, late); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if runs != 1 {
+		t.Fatalf("Expected the missed run to be caught up once, got %d runs", runs)
+	}
+	job := repo.jobs["recap"]
+	if !job.LastRun.Equal(late) || job.LastError != "boom" || !job.NextRun.Equal(due.AddDate(0, 0, 7)) {
+		t.Errorf("Expected the failed run recorded and the job moved to next week, got %+v", job)
+	}
+
+	// A new schedule replaces the stored one
+	if err := s.Every(ctx, "recap", "0 8 * * 1", run); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if next := repo.jobs["recap"].NextRun; next.Weekday() != time.Monday || next.Hour() != 8 {
+		t.Errorf("Expected the next Monday 08:00, got %s", next)
+	}
+
+	if err := s.Every(ctx, "bad", "0 0 31 2 *", run); err == nil {
+		t.Error("Expected an error for a schedule that never runs")
+	}
+}
+
+func TestScheduler_SkipsRunsOutsideCatchUpWindow(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockJobs()
+	s := scheduler.New(repo, time.Hour)
+
+	runs := 0
+	if err := s.Every(ctx, "reminder", "30 19 * * *", func(ctx context.Context, job *domain.Job) error {
+		runs++
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	due := repo.jobs["reminder"].NextRun
+
+	// Offline for two days: yesterday's reminder is not sent now
+	now := due.Add(48*time.Hour + 10*time.Minute)
+	if err := s.RunDue(ctx, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if runs != 0 {
+		t.Errorf("Expected the stale run to be skipped, got %d runs", runs)
+	}
+	if next := repo.jobs["reminder"].NextRun; !next.Equal(due.AddDate(0, 0, 3)) {
+		t.Errorf("Expected the next run after now, got %s", next)
+	}
+}
+
+func TestScheduler_NoCatchUpStillRunsDueJobs(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockJobs()
+	s := scheduler.New(repo, 0)
+
+	runs := 0
+	if err := s.Every(ctx, "reminder", "30 19 * * *", func(ctx context.Context, job *domain.Job) error {
+		runs++
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	due := repo.jobs["reminder"].NextRun
+
+	// The ticker fires some seconds after the minute starts
+	if err := s.RunDue(ctx, due.Add(20*time.Second)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if runs != 1 {
+		t.Errorf("Expected the due run to happen without catch-up, got %d runs", runs)
+	}
+
+	// A run missed while offline is still skipped
+	if err := s.RunDue(ctx, due.AddDate(0, 0, 1).Add(10*time.Minute)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if runs != 1 {
+		t.Errorf("Expected the missed run to be skipped, got %d runs", runs)
+	}
+}
+
+func TestScheduler_OnceAndOrphanedJobs(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockJobs()
+	s := scheduler.New(repo, time.Hour)
+
+	var payloads []string
+	s.Handle("announce", func(ctx context.Context, job *domain.Job) error {
+		payloads = append(payloads, job.Payload)
+		return nil
+	})
+
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if err := s.Once(ctx, "announce:1", "announce", at, "halo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := s.Once(ctx, "announce:2", "announce", at.Add(time.Hour), "nanti"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := s.Cancel(ctx, "announce:2"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Stored by an earlier version, nothing handles it now
+	repo.jobs["old"] = domain.Job{Name: "old", Kind: "old", Schedule: "* * * * *", NextRun: at}
+
+	if err := s.RunDue(ctx, at.Add(5*time.Hour)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(payloads) != 0 {
+		t.Fatalf("Expected a one-shot job past the window to be skipped, got %v", payloads)
+	}
+
+	if err := s.Once(ctx, "announce:1", "announce", at, "halo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := s.RunDue(ctx, at); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(payloads) != 1 || payloads[0] != "halo" {
+		t.Errorf("Expected the one-shot job to run once, got %v", payloads)
+	}
+	if len(repo.jobs) != 0 {
+		t.Errorf("Expected one-shot and orphaned jobs to be removed, got %v", repo.jobs)
+	}
+}
@@ -0,0 +1,147 @@
+// Package supervisor keeps a set of child processes running: the bots of
+// the tenants one deployment hosts. Each child's output is prefixed with
+// its name, a child that exits is restarted after a delay that grows while
+// it keeps crashing, and all children are stopped together on shutdown.
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = 5 * time.Minute
+	// A child that ran this long before exiting is restarted right away
+	stableAfter = time.Minute
+	// How long a child may take to shut down before it is killed
+	stopTimeout = 15 * time.Second
+)
+
+// Process is a command to keep running.
+type Process struct {
+	Name string // Prefixes its output, e.g. the tenant ID
+	Path string
+	Args []string
+	Env  []string // "KEY=VALUE" added to the supervisor's environment
+}
+
+// Run starts every process and restarts the ones that exit until ctx is
+// done. Then it sends SIGTERM to the children and returns once all of them
+// have exited.
+func Run(ctx context.Context, procs []Process) {
+	var wg sync.WaitGroup
+	for _, p := range procs {
+		wg.Add(1)
+		go func(p Process) {
+			defer wg.Done()
+			keepRunning(ctx, p, os.Stdout, os.Stderr)
+		}(p)
+	}
+	wg.Wait()
+}
+
+func keepRunning(ctx context.Context, p Process, stdout, stderr io.Writer) {
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := runOnce(ctx, p, stdout, stderr)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(started) >= stableAfter {
+			backoff = minBackoff
+		}
+		log.Printf("Tenant %s exited (%v), restarting in %s", p.Name, err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// runOnce runs the process until it exits or ctx is done.
+func runOnce(ctx context.Context, p Process, stdout, stderr io.Writer) error {
+	out, errOut := newPrefixWriter(stdout, p.Name), newPrefixWriter(stderr, p.Name)
+	defer out.Flush()
+	defer errOut.Flush()
+
+	cmd := exec.Command(p.Path, p.Args...)
+	cmd.Env = append(os.Environ(), p.Env...)
+	cmd.Stdout, cmd.Stderr = out, errOut
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(stopTimeout):
+		log.Printf("Tenant %s did not stop in %s, killing it", p.Name, stopTimeout)
+		_ = cmd.Process.Kill()
+		return <-done
+	}
+}
+
+// prefixWriter writes whole lines, each starting with "[name] ", so the
+// output of several children stays readable.
+type prefixWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	prefix []byte
+	buf    []byte
+}
+
+func newPrefixWriter(w io.Writer, name string) *prefixWriter {
+	return &prefixWriter{w: w, prefix: []byte("[" + name + "] ")}
+}
+
+func (pw *prefixWriter) Write(b []byte) (int, error) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	pw.buf = append(pw.buf, b...)
+	for {
+		i := bytes.IndexByte(pw.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := append(append([]byte{}, pw.prefix...), pw.buf[:i+1]...)
+		if _, err := pw.w.Write(line); err != nil {
+			return len(b), err
+		}
+		pw.buf = pw.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+// Flush writes a last line that did not end with a newline.
+func (pw *prefixWriter) Flush() {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if len(pw.buf) > 0 {
+		_, _ = pw.w.Write(append(append(append([]byte{}, pw.prefix...), pw.buf...), '\n'))
+		pw.buf = nil
+	}
+}
@@ -0,0 +1,51 @@
+package supervisor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/supervisor"
+)
+
+// =============================================================================
+// SUPERVISOR TESTS
+// =============================================================================
+
+func TestRun_RestartsExitedProcesses(t *testing.T) {
+	starts := filepath.Join(t.TempDir(), "starts")
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	supervisor.Run(ctx, []supervisor.Process{{
+		Name: "crashy",
+		Path: "sh",
+		Args: []string{"-c", `echo "$TENANT" >> "$STARTS"; exit 1`},
+		Env:  []string{"TENANT=crashy", "STARTS=" + starts},
+	}})
+
+	data, err := os.ReadFile(starts)
+	if err != nil {
+		t.Fatalf("Expected the process to run: %v", err)
+	}
+	if lines := strings.Fields(string(data)); len(lines) != 2 || lines[0] != "crashy" {
+		t.Errorf("Expected a start and a restart after 1s with the tenant's environment, got %q", data)
+	}
+}
+
+func TestRun_StopsProcessesOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	supervisor.Run(ctx, []supervisor.Process{
+		{Name: "a", Path: "sleep", Args: []string{"30"}},
+		{Name: "b", Path: "sleep", Args: []string{"30"}},
+	})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the processes stopped on shutdown, took %s", elapsed)
+	}
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// adminSessionTTL is how long a login token stays valid.
+const adminSessionTTL = 12 * time.Hour
+
+const minAdminPasswordLength = 8
+
+// MinJWTSecretLength is the shortest JWT_SECRET accepted: HS256 keys
+// shorter than its 32-byte output can be brute-forced offline.
+const MinJWTSecretLength = 32
+
+// dummyPasswordHash is compared against for unknown usernames, so a login
+// takes as long whether or not the account exists.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("no such account"), bcrypt.DefaultCost)
+
+// Admin API roles. Viewers may only read; password accounts and
+// ADMIN_TOKEN are always admins.
+const (
+	RoleAdmin  = "admin"
+	RoleViewer = "viewer"
+)
+
+// adminClaims are the claims of a session token.
+type adminClaims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// ErrInvalidLogin is returned for a wrong username or password. Both cases
+// look the same to the caller.
+var ErrInvalidLogin = errors.New("invalid username or password")
+
+// AdminAuthUsecase manages local admin accounts and issues JWT session
+// tokens for the admin API.
+type AdminAuthUsecase struct {
+	accounts domain.AdminAccountRepository
+	secret   []byte
+	emails   map[string]string // OAuth email -> role
+}
+
+func NewAdminAuthUsecase(accounts domain.AdminAccountRepository, secret string) *AdminAuthUsecase {
+	return &AdminAuthUsecase{accounts: accounts, secret: []byte(secret)}
+}
+
+// SetOAuthAllowlist sets which emails may log in with OAuth and their role,
+// from OAUTH_ALLOWED_EMAILS. Entries with an unknown role are skipped.
+func (uc *AdminAuthUsecase) SetOAuthAllowlist(emails map[string]string) {
+	uc.emails = make(map[string]string, len(emails))
+	for email, role := range emails {
+		role = strings.ToLower(role)
+		if role != RoleAdmin && role != RoleViewer {
+			log.Printf("Ignoring OAuth email %s: unknown role %q", email, role)
+			continue
+		}
+		uc.emails[strings.ToLower(email)] = role
+	}
+}
+
+// CreateAccount adds an account, or changes the password of an existing one.
+func (uc *AdminAuthUsecase) CreateAccount(ctx context.Context, username, password string) error {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return errors.New("username must not be empty")
+	}
+	if len(password) < minAdminPasswordLength {
+		return errors.New("password must be at least 8 characters")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	account, err := uc.accounts.GetAdminAccount(ctx, username)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		account = &domain.AdminAccount{Username: username, CreatedAt: time.Now()}
+	}
+	account.PasswordHash = string(hash)
+	return uc.accounts.SaveAdminAccount(ctx, account)
+}
+
+// Login checks the password and returns a signed session token.
+func (uc *AdminAuthUsecase) Login(ctx context.Context, username, password string) (string, time.Time, error) {
+	account, err := uc.accounts.GetAdminAccount(ctx, strings.TrimSpace(username))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if account == nil {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return "", time.Time{}, ErrInvalidLogin
+	}
+	if bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(password)) != nil {
+		return "", time.Time{}, ErrInvalidLogin
+	}
+
+	return uc.issue(account.Username, RoleAdmin)
+}
+
+// LoginEmail issues a session token for an email address verified by an
+// OAuth provider, with the role it has in the allowlist.
+func (uc *AdminAuthUsecase) LoginEmail(ctx context.Context, email string) (string, time.Time, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	role, ok := uc.emails[email]
+	if !ok {
+		return "", time.Time{}, ErrInvalidLogin
+	}
+	return uc.issue(email, role)
+}
+
+func (uc *AdminAuthUsecase) issue(subject, role string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(adminSessionTTL)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, adminClaims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString(uc.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// Verify checks a session token and returns who it was issued to and their
+// role.
+func (uc *AdminAuthUsecase) Verify(token string) (string, string, error) {
+	var claims adminClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return uc.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return "", "", err
+	}
+	if claims.Role != RoleAdmin && claims.Role != RoleViewer {
+		return "", "", errors.New("token has no valid role")
+	}
+	return claims.Subject, claims.Role, nil
+}
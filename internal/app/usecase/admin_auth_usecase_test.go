@@ -0,0 +1,90 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockAdminAccountRepo struct {
+	accounts map[string]*domain.AdminAccount
+}
+
+func (m *mockAdminAccountRepo) GetAdminAccount(ctx context.Context, username string) (*domain.AdminAccount, error) {
+	return m.accounts[username], nil
+}
+
+func (m *mockAdminAccountRepo) SaveAdminAccount(ctx context.Context, account *domain.AdminAccount) error {
+	m.accounts[account.Username] = account
+	return nil
+}
+
+func (m *mockAdminAccountRepo) InitTable(ctx context.Context) error {
+	return nil
+}
+
+func TestAdminAuth_LoginAndVerify(t *testing.T) {
+	repo := &mockAdminAccountRepo{accounts: make(map[string]*domain.AdminAccount)}
+	uc := usecase.NewAdminAuthUsecase(repo, "test-secret")
+	ctx := context.Background()
+
+	if err := uc.CreateAccount(ctx, "faris", "short"); err == nil {
+		t.Error("Expected short password to be rejected")
+	}
+	if err := uc.CreateAccount(ctx, "faris", "rahasia123"); err != nil {
+		t.Fatalf("Failed to create account: %v", err)
+	}
+	if hash := repo.accounts["faris"].PasswordHash; hash == "" || hash == "rahasia123" {
+		t.Fatalf("Expected a password hash, got '%s'", hash)
+	}
+
+	if _, _, err := uc.Login(ctx, "faris", "salah12345"); !errors.Is(err, usecase.ErrInvalidLogin) {
+		t.Errorf("Expected ErrInvalidLogin for wrong password, got %v", err)
+	}
+	if _, _, err := uc.Login(ctx, "nobody", "rahasia123"); !errors.Is(err, usecase.ErrInvalidLogin) {
+		t.Errorf("Expected ErrInvalidLogin for unknown user, got %v", err)
+	}
+
+	token, _, err := uc.Login(ctx, "faris", "rahasia123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	username, role, err := uc.Verify(token)
+	if err != nil || username != "faris" || role != usecase.RoleAdmin {
+		t.Errorf("Expected admin token for faris, got '%s' '%s', %v", username, role, err)
+	}
+
+	// Tokens signed with another secret are rejected
+	other := usecase.NewAdminAuthUsecase(repo, "other-secret")
+	if _, _, err := other.Verify(token); err == nil {
+		t.Error("Expected token from another secret to be rejected")
+	}
+}
+
+func TestAdminAuth_LoginEmailUsesAllowlistRole(t *testing.T) {
+	uc := usecase.NewAdminAuthUsecase(&mockAdminAccountRepo{accounts: make(map[string]*domain.AdminAccount)}, "test-secret")
+	uc.SetOAuthAllowlist(map[string]string{
+		"Ani@Gmail.com":  "viewer",
+		"budi@gmail.com": "owner", // unknown role, skipped
+	})
+	ctx := context.Background()
+
+	if _, _, err := uc.LoginEmail(ctx, "budi@gmail.com"); !errors.Is(err, usecase.ErrInvalidLogin) {
+		t.Errorf("Expected ErrInvalidLogin for unknown role, got %v", err)
+	}
+	if _, _, err := uc.LoginEmail(ctx, "citra@gmail.com"); !errors.Is(err, usecase.ErrInvalidLogin) {
+		t.Errorf("Expected ErrInvalidLogin for email not in allowlist, got %v", err)
+	}
+
+	token, _, err := uc.LoginEmail(ctx, "ani@gmail.com")
+	if err != nil {
+		t.Fatalf("LoginEmail failed: %v", err)
+	}
+	username, role, err := uc.Verify(token)
+	if err != nil || username != "ani@gmail.com" || role != usecase.RoleViewer {
+		t.Errorf("Expected viewer token for ani@gmail.com, got '%s' '%s', %v", username, role, err)
+	}
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// API key scopes
+const (
+	ScopeRead  = "read"
+	ScopeAdmin = "admin"
+)
+
+// apiKeyPrefix marks API keys so they can be told apart from session tokens.
+const apiKeyPrefix = "lb_"
+
+// APIKeyUsecase creates, lists and revokes API keys for scripts that call
+// the admin API. A key is shown once when it is created; only its hash is
+// stored.
+type APIKeyUsecase struct {
+	keys domain.APIKeyRepository
+}
+
+func NewAPIKeyUsecase(keys domain.APIKeyRepository) *APIKeyUsecase {
+	return &APIKeyUsecase{keys: keys}
+}
+
+// Create stores a new key and returns it in plain text.
+func (uc *APIKeyUsecase) Create(ctx context.Context, name, scope string) (string, *domain.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, errors.New("name must not be empty")
+	}
+	if scope != ScopeRead && scope != ScopeAdmin {
+		return "", nil, fmt.Errorf("scope must be %q or %q", ScopeRead, ScopeAdmin)
+	}
+
+	id, err := randomHex(4)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return "", nil, err
+	}
+	plain := apiKeyPrefix + id + "_" + secret
+
+	key := &domain.APIKey{
+		ID:        id,
+		Name:      name,
+		KeyHash:   hashAPIKey(plain),
+		Scope:     scope,
+		CreatedAt: time.Now(),
+	}
+	if err := uc.keys.SaveAPIKey(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return plain, key, nil
+}
+
+func (uc *APIKeyUsecase) List(ctx context.Context) ([]*domain.APIKey, error) {
+	return uc.keys.ListAPIKeys(ctx)
+}
+
+// HasActive reports whether at least one key is not revoked.
+func (uc *APIKeyUsecase) HasActive(ctx context.Context) bool {
+	keys, err := uc.keys.ListAPIKeys(ctx)
+	if err != nil {
+		log.Printf("Failed to list API keys: %v", err)
+		return false
+	}
+	for _, key := range keys {
+		if key.RevokedAt.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// Revoke disables a key by its ID. It returns false if there is no such key.
+func (uc *APIKeyUsecase) Revoke(ctx context.Context, id string) (bool, error) {
+	key, err := uc.keys.GetAPIKey(ctx, id)
+	if err != nil || key == nil {
+		return false, err
+	}
+	if key.RevokedAt.IsZero() {
+		key.RevokedAt = time.Now()
+		if err := uc.keys.SaveAPIKey(ctx, key); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Role returns the admin API role an active key grants, or "" for unknown
+// and revoked keys.
+func (uc *APIKeyUsecase) Role(ctx context.Context, plain string) (string, error) {
+	if !strings.HasPrefix(plain, apiKeyPrefix) {
+		return "", nil
+	}
+	key, err := uc.keys.GetAPIKeyByHash(ctx, hashAPIKey(plain))
+	if err != nil || key == nil || !key.RevokedAt.IsZero() {
+		return "", err
+	}
+	if key.Scope == ScopeAdmin {
+		return RoleAdmin, nil
+	}
+	return RoleViewer, nil
+}
+
+// FormatAPIKeys lists keys for the CLI.
+func FormatAPIKeys(keys []*domain.APIKey) string {
+	if len(keys) == 0 {
+		return "No API keys"
+	}
+	var sb strings.Builder
+	for _, key := range keys {
+		status := "active"
+		if !key.RevokedAt.IsZero() {
+			status = "revoked " + key.RevokedAt.Local().Format("2006-01-02")
+		}
+		fmt.Fprintf(&sb, "%s  %-5s  %s  (created %s, %s)\n", key.ID, key.Scope, key.Name,
+			key.CreatedAt.Local().Format("2006-01-02"), status)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// hashAPIKey hashes a key for storage. Keys are long and random, so a plain
+// SHA-256 is enough and lets keys be looked up by hash.
+func hashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
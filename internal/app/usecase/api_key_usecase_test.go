@@ -0,0 +1,92 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockAPIKeyRepo struct {
+	keys map[string]*domain.APIKey
+}
+
+func (m *mockAPIKeyRepo) GetAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	return m.keys[id], nil
+}
+
+func (m *mockAPIKeyRepo) GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	for _, key := range m.keys {
+		if key.KeyHash == hash {
+			return key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAPIKeyRepo) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	for _, key := range m.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyRepo) SaveAPIKey(ctx context.Context, key *domain.APIKey) error {
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *mockAPIKeyRepo) InitTable(ctx context.Context) error {
+	return nil
+}
+
+func TestAPIKey_CreateScopesAndRevoke(t *testing.T) {
+	repo := &mockAPIKeyRepo{keys: make(map[string]*domain.APIKey)}
+	uc := usecase.NewAPIKeyUsecase(repo)
+	ctx := context.Background()
+
+	if _, _, err := uc.Create(ctx, "sheets", "write"); err == nil {
+		t.Error("Expected unknown scope to be rejected")
+	}
+	if uc.HasActive(ctx) {
+		t.Error("Expected no active keys yet")
+	}
+
+	readKey, read, err := uc.Create(ctx, "sheets", usecase.ScopeRead)
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if read.KeyHash == "" || strings.Contains(read.KeyHash, readKey) {
+		t.Errorf("Expected only a hash to be stored, got '%s'", read.KeyHash)
+	}
+	adminKey, admin, err := uc.Create(ctx, "backup", usecase.ScopeAdmin)
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	if role, _ := uc.Role(ctx, readKey); role != usecase.RoleViewer {
+		t.Errorf("Expected read key to grant viewer, got '%s'", role)
+	}
+	if role, _ := uc.Role(ctx, adminKey); role != usecase.RoleAdmin {
+		t.Errorf("Expected admin key to grant admin, got '%s'", role)
+	}
+	if role, _ := uc.Role(ctx, "lb_forged_key"); role != "" {
+		t.Errorf("Expected no role for unknown key, got '%s'", role)
+	}
+
+	if found, err := uc.Revoke(ctx, admin.ID); !found || err != nil {
+		t.Fatalf("Expected key revoked, got %v, %v", found, err)
+	}
+	if role, _ := uc.Role(ctx, adminKey); role != "" {
+		t.Errorf("Expected no role for revoked key, got '%s'", role)
+	}
+	if found, _ := uc.Revoke(ctx, "missing"); found {
+		t.Error("Expected revoking an unknown key to report not found")
+	}
+	if !uc.HasActive(ctx) {
+		t.Error("Expected the read key to still be active")
+	}
+}
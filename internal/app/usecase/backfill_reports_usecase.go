@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+)
+
+// HistoricalReport is a #lapor found in chat history that the phone synced
+// to the bot after linking.
+type HistoricalReport struct {
+	UserID  string
+	Name    string
+	Message string
+	At      time.Time
+}
+
+// BackfillReportsUsecase counts #lapor sent before the bot joined, so
+// switching to the bot mid-challenge doesn't zero everyone's streak. The
+// reports go into the event stream like live ones, and the report rows are
+// rebuilt from it.
+type BackfillReportsUsecase struct {
+	events     *ReportEventsUsecase
+	activities domain.ActivityRepository
+	messages   domain.MessageArchiveRepository
+	hasher     *phone.Hasher
+}
+
+func NewBackfillReportsUsecase(events *ReportEventsUsecase) *BackfillReportsUsecase {
+	return &BackfillReportsUsecase{events: events}
+}
+
+// SetActivityRepository also logs the backfilled reports as activities, so
+// #stats and #recap count them.
+func (uc *BackfillReportsUsecase) SetActivityRepository(activities domain.ActivityRepository) {
+	uc.activities = activities
+}
+
+// Backfill adds the reports on days a member has no report for yet, one per
+// day, and returns how many were added. Days already counted, or revoked by
+// an admin, are left alone, so the same history can be synced again.
+func (uc *BackfillReportsUsecase) Backfill(ctx context.Context, reports []HistoricalReport) (int, error) {
+	byUser := make(map[string][]HistoricalReport)
+	for _, r := range reports {
+		byUser[r.UserID] = append(byUser[r.UserID], r)
+	}
+	userIDs := make([]string, 0, len(byUser))
+	for userID := range byUser {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	added := 0
+	for _, userID := range userIDs {
+		n, err := uc.backfillMember(ctx, userID, byUser[userID])
+		added += n
+		if err != nil {
+			return added, err
+		}
+	}
+	return added, nil
+}
+
+func (uc *BackfillReportsUsecase) backfillMember(ctx context.Context, userID string, reports []HistoricalReport) (int, error) {
+	sort.SliceStable(reports, func(i, j int) bool { return reports[i].At.Before(reports[j].At) })
+
+	added := 0
+	err := withTx(ctx, uc.events.tx, func(ctx context.Context) error {
+		existing, err := uc.events.events.GetEvents(ctx, userID)
+		if err != nil {
+			return err
+		}
+		// Days are compared in the bot's location: the archive keeps times
+		// in UTC, which puts a report sent just after midnight on the day
+		// before
+		days := make(map[string]bool)
+		for _, e := range existing {
+			if e.Type == domain.ReportSubmitted {
+				days[e.At.In(time.Local).Format("2006-01-02")] = true
+			}
+		}
+
+		for _, r := range reports {
+			at := r.At.In(time.Local)
+			day := at.Format("2006-01-02")
+			if days[day] {
+				continue
+			}
+			days[day] = true
+
+			event := &domain.ReportEvent{UserID: userID, Type: domain.ReportSubmitted, At: at, Name: r.Name, Actor: "history"}
+			if err := uc.events.events.AppendEvent(ctx, event); err != nil {
+				return err
+			}
+			if uc.activities != nil {
+				detail := activity.Parse(r.Message)
+				if err := uc.activities.AddActivity(ctx, &domain.Activity{
+					UserID:          userID,
+					Name:            r.Name,
+					ActivityType:    detail.Type,
+					DurationMinutes: detail.DurationMinutes,
+					DistanceKm:      detail.DistanceKm,
+					Message:         r.Message,
+					ReportedAt:      at,
+				}); err != nil {
+					return err
+				}
+			}
+			added++
+		}
+
+		if added == 0 {
+			return nil
+		}
+		_, err = uc.events.rebuild(ctx, userID)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// SetUserIDHasher matches the numbers of a chat export to hashed user IDs.
+func (uc *BackfillReportsUsecase) SetUserIDHasher(hasher *phone.Hasher) {
+	uc.hasher = hasher
+}
+
+// SetMessageArchive enables FromArchive.
+func (uc *BackfillReportsUsecase) SetMessageArchive(messages domain.MessageArchiveRepository) {
+	uc.messages = messages
+}
+
+// FromArchive backfills the #lapor in the message archive since the given
+// time, for reports the bot archived but never counted, e.g. because it
+// crashed halfway through a message. isReport tells which archived texts
+// are a #lapor in their chat. It returns how many were found and how many
+// of those were added.
+func (uc *BackfillReportsUsecase) FromArchive(ctx context.Context, since time.Time, isReport func(chatID, text string) bool) (int, int, error) {
+	if uc.messages == nil {
+		return 0, 0, nil
+	}
+	messages, err := uc.messages.GetMessages(ctx, domain.MessageFilter{Since: since})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var reports []HistoricalReport
+	for _, m := range messages {
+		if m.SenderID == "" || !isReport(m.ChatID, m.Text) {
+			continue
+		}
+		name := m.SenderName
+		if name == "" {
+			name = "Unknown"
+		}
+		reports = append(reports, HistoricalReport{UserID: m.SenderID, Name: name, Message: m.Text, At: m.SentAt})
+	}
+	if len(reports) == 0 {
+		return 0, 0, nil
+	}
+	added, err := uc.Backfill(ctx, reports)
+	return len(reports), added, err
+}
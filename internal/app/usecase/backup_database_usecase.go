@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// backupPattern matches the files written by BackupDatabaseUsecase, so
+// other files in the directory are never deleted.
+const backupPattern = "backup-*.db"
+
+// encryptedSuffix is appended to the name of off-site backups.
+const encryptedSuffix = ".enc"
+
+// BackupDatabaseUsecase copies the database into a directory and keeps only
+// the newest copies.
+type BackupDatabaseUsecase struct {
+	backup domain.DatabaseBackup
+	dir    string
+	keep   int
+
+	offsite     domain.BackupStore
+	passphrase  string
+	offsiteKeep int
+}
+
+// NewBackupDatabaseUsecase keeps the newest keep backups in dir; 0 keeps
+// them all.
+func NewBackupDatabaseUsecase(backup domain.DatabaseBackup, dir string, keep int) *BackupDatabaseUsecase {
+	return &BackupDatabaseUsecase{backup: backup, dir: dir, keep: keep}
+}
+
+// SetOffsite also uploads every backup to store, encrypted with passphrase,
+// and keeps the newest keep there; 0 keeps them all.
+func (uc *BackupDatabaseUsecase) SetOffsite(store domain.BackupStore, passphrase string, keep int) {
+	uc.offsite = store
+	uc.passphrase = passphrase
+	uc.offsiteKeep = keep
+}
+
+// Execute writes backup-<time>.db, uploads it when off-site backups are set
+// up and deletes the oldest backups beyond keep. It returns the path of the
+// new backup.
+func (uc *BackupDatabaseUsecase) Execute(ctx context.Context, now time.Time) (string, error) {
+	if err := os.MkdirAll(uc.dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(uc.dir, "backup-"+now.Format("20060102-150405")+".db")
+	if err := uc.backup.Backup(ctx, path); err != nil {
+		return "", err
+	}
+	log.Printf("Database backed up to %s", path)
+
+	if uc.offsite != nil {
+		if err := uc.upload(ctx, path); err != nil {
+			return path, fmt.Errorf("off-site backup failed: %w", err)
+		}
+	}
+
+	if uc.keep <= 0 {
+		return path, nil
+	}
+	backups, err := filepath.Glob(filepath.Join(uc.dir, backupPattern))
+	if err != nil {
+		return path, err
+	}
+	for _, old := range oldestBackups(backups, uc.keep) {
+		if err := os.Remove(old); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+// upload encrypts the backup at path, stores it as <name>.enc and deletes
+// the oldest off-site backups beyond offsiteKeep.
+func (uc *BackupDatabaseUsecase) upload(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sealed, err := EncryptBackup(data, uc.passphrase)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(path) + encryptedSuffix
+	if err := uc.offsite.Upload(ctx, name, sealed); err != nil {
+		return err
+	}
+	log.Printf("Database backup uploaded as %s", name)
+
+	if uc.offsiteKeep <= 0 {
+		return nil
+	}
+	names, err := uc.offsite.List(ctx)
+	if err != nil {
+		return err
+	}
+	var backups []string
+	for _, n := range names {
+		if ok, _ := filepath.Match(backupPattern+encryptedSuffix, n); ok {
+			backups = append(backups, n)
+		}
+	}
+	for _, old := range oldestBackups(backups, uc.offsiteKeep) {
+		if err := uc.offsite.Delete(ctx, old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// oldestBackups returns the backups beyond the newest keep.
+func oldestBackups(backups []string, keep int) []string {
+	// The timestamp in the name sorts oldest first
+	sort.Strings(backups)
+	if len(backups) <= keep {
+		return nil
+	}
+	return backups[:len(backups)-keep]
+}
+
+// backupMagic starts every encrypted backup, followed by the scrypt salt,
+// the AES-GCM nonce and the sealed database.
+const backupMagic = "lapor-bot backup v1\n"
+
+const backupSaltSize = 16
+
+// ErrBackupPassphrase is returned when a backup cannot be decrypted, either
+// because the passphrase is wrong or the file was changed.
+var ErrBackupPassphrase = errors.New("wrong passphrase or damaged backup")
+
+// backupCipher derives the AES-256 key from the passphrase and salt.
+func backupCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptBackup seals a backup with a key derived from passphrase, so the
+// storage provider cannot read the members' data.
+func EncryptBackup(data []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty backup passphrase")
+	}
+	salt := make([]byte, backupSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := backupCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := append(append([]byte(backupMagic), salt...), nonce...)
+	// The header is authenticated too
+	return append(header, aead.Seal(nil, nonce, data, header)...), nil
+}
+
+// DecryptBackup opens a backup sealed by EncryptBackup.
+func DecryptBackup(sealed []byte, passphrase string) ([]byte, error) {
+	if !bytes.HasPrefix(sealed, []byte(backupMagic)) {
+		return nil, errors.New("not an encrypted lapor-bot backup")
+	}
+	rest := sealed[len(backupMagic):]
+	if len(rest) < backupSaltSize {
+		return nil, ErrBackupPassphrase
+	}
+	aead, err := backupCipher(passphrase, rest[:backupSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	headerSize := len(backupMagic) + backupSaltSize + aead.NonceSize()
+	if len(sealed) < headerSize {
+		return nil, ErrBackupPassphrase
+	}
+	header := sealed[:headerSize]
+	data, err := aead.Open(nil, header[headerSize-aead.NonceSize():], sealed[headerSize:], header)
+	if err != nil {
+		return nil, ErrBackupPassphrase
+	}
+	return data, nil
+}
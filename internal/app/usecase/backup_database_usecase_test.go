@@ -0,0 +1,87 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+type mockDatabaseBackup struct{}
+
+func (m *mockDatabaseBackup) Backup(ctx context.Context, path string) error {
+	return os.WriteFile(path, []byte("SQLite format 3"), 0o644)
+}
+
+type mockBackupStore struct {
+	objects map[string][]byte
+}
+
+func (m *mockBackupStore) Upload(ctx context.Context, name string, data []byte) error {
+	m.objects[name] = data
+	return nil
+}
+
+func (m *mockBackupStore) List(ctx context.Context) ([]string, error) {
+	var names []string
+	for name := range m.objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *mockBackupStore) Delete(ctx context.Context, name string) error {
+	delete(m.objects, name)
+	return nil
+}
+
+// =============================================================================
+// BACKUP TESTS
+// =============================================================================
+
+func TestBackupDatabase_UploadsEncryptedAndRotates(t *testing.T) {
+	dir := t.TempDir()
+	store := &mockBackupStore{objects: map[string][]byte{"notes.txt": []byte("kept")}}
+	uc := usecase.NewBackupDatabaseUsecase(&mockDatabaseBackup{}, dir, 2)
+	uc.SetOffsite(store, "rahasia", 3)
+
+	start := time.Date(2026, 10, 1, 2, 0, 0, 0, time.UTC)
+	for day := 0; day < 5; day++ {
+		if _, err := uc.Execute(context.Background(), start.AddDate(0, 0, day)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	local, _ := filepath.Glob(filepath.Join(dir, "backup-*.db"))
+	if len(local) != 2 {
+		t.Errorf("Expected 2 local backups, got %v", local)
+	}
+	names, _ := store.List(context.Background())
+	want := []string{"backup-20261003-020000.db.enc", "backup-20261004-020000.db.enc", "backup-20261005-020000.db.enc", "notes.txt"}
+	if len(names) != len(want) {
+		t.Fatalf("Expected the 3 newest off-site backups and other files kept, got %v", names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Expected %s, got %s", want[i], names[i])
+		}
+	}
+
+	sealed := store.objects["backup-20261005-020000.db.enc"]
+	if string(sealed) == "SQLite format 3" {
+		t.Fatal("Expected the off-site backup to be encrypted")
+	}
+	data, err := usecase.DecryptBackup(sealed, "rahasia")
+	if err != nil || string(data) != "SQLite format 3" {
+		t.Errorf("Expected the backup to decrypt, got %q, %v", data, err)
+	}
+	if _, err := usecase.DecryptBackup(sealed, "salah"); !errors.Is(err, usecase.ErrBackupPassphrase) {
+		t.Errorf("Expected ErrBackupPassphrase for a wrong passphrase, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// BoardBuilder ranks the members for everything that shows the board:
+// #leaderboard, the published state, the final report, the spreadsheet,
+// the digest, the snapshots and the external syncs. The bot builds one and
+// hands it to each of them, so they all rank alike. Unranked and #privat
+// members are left out.
+type BoardBuilder struct {
+	reports   domain.ReportRepository
+	settings  domain.SettingsRepository
+	tieBreaks []TieBreak
+}
+
+// NewBoardBuilder ranks the reports with tieBreaks between members with the
+// same total, DefaultTieBreaks when nil. settings may be nil to rank
+// everyone.
+func NewBoardBuilder(reports domain.ReportRepository, settings domain.SettingsRepository, tieBreaks []TieBreak) *BoardBuilder {
+	if tieBreaks == nil {
+		tieBreaks = DefaultTieBreaks
+	}
+	return &BoardBuilder{reports: reports, settings: settings, tieBreaks: tieBreaks}
+}
+
+// Board ranks all members as of now.
+func (b *BoardBuilder) Board(ctx context.Context, now time.Time) (*BoardState, error) {
+	all, err := b.reports.GetAllReports(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return b.Rank(ctx, all, now)
+}
+
+// Rank ranks the given reports, e.g. the members of one group, as of now.
+func (b *BoardBuilder) Rank(ctx context.Context, all []*domain.Report, now time.Time) (*BoardState, error) {
+	unranked, err := unrankedUsers(ctx, b.settings)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &BoardState{Date: now.Format("2006-01-02"), Members: []BoardMember{}}
+	for _, r := range all {
+		if unranked[r.UserID] {
+			continue
+		}
+		m := newMemberState(r, now)
+		if m.Today {
+			state.ReportedToday++
+		}
+		state.Members = append(state.Members, BoardMember{
+			UserID:     r.UserID,
+			Name:       r.Name,
+			Streak:     m.Streak,
+			Total:      m.Total,
+			Today:      m.Today,
+			LastReport: r.LastReportDate,
+			BestStreak: max(r.BestStreak, r.Streak),
+		})
+	}
+
+	sort.Slice(state.Members, func(i, j int) bool {
+		return rankBefore(state.Members[i], state.Members[j], b.tieBreaks)
+	})
+	for i := range state.Members {
+		state.Members[i].Rank = i + 1
+		m := state.Members[i]
+		if m.Streak > 0 && (state.Leader == nil || m.Streak > state.Leader.Streak) {
+			state.Leader = &m
+		}
+	}
+	return state, nil
+}
+
+// boardsOr returns boards, or for a use case set up without them, a
+// builder with the default tie-breaks over its own repositories.
+func boardsOr(boards *BoardBuilder, reports domain.ReportRepository, settings domain.SettingsRepository) *BoardBuilder {
+	if boards != nil {
+		return boards
+	}
+	return NewBoardBuilder(reports, settings, nil)
+}
@@ -0,0 +1,377 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
+)
+
+// builtinCommand is a command backed by one of the use cases set on
+// HandleMessageUsecase. It is hidden until that use case is wired.
+type builtinCommand struct {
+	help       CommandHelp
+	permission Permission
+	enabled    func() bool
+	run        func(ctx context.Context, req CommandRequest) (*Reply, error)
+	// match overrides the default prefix match, e.g. for a subcommand
+	match func(lower string) bool
+}
+
+func (c *builtinCommand) Match(lower string) bool {
+	if c.match != nil {
+		return c.match(lower)
+	}
+	return strings.HasPrefix(lower, canonicalPrefix+c.help.Name)
+}
+
+func (c *builtinCommand) Execute(ctx context.Context, req CommandRequest) (*Reply, error) {
+	return c.run(ctx, req)
+}
+
+func (c *builtinCommand) Help() CommandHelp {
+	return c.help
+}
+
+func (c *builtinCommand) Permissions() Permission {
+	return c.permission
+}
+
+// textReply adapts a use case that answers with text only.
+func textReply(text string, err error) (*Reply, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &Reply{Text: text}, nil
+}
+
+// builtinCommands returns the commands of the bot itself, in #help order.
+func builtinCommands(uc *HandleMessageUsecase) []Command {
+	return []Command{
+		&builtinCommand{
+			help:    CommandHelp{Name: "join", Description: "daftar challenge lewat chat pribadi"},
+			enabled: func() bool { return uc.onboardingUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return uc.onboardingUC.Start(ctx, req.UserID, req.Name)
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "lapor", Usage: "[jenis] [durasi] [jarak]", Description: "catat olahraga hari ini"},
+			enabled: func() bool { return uc.reportUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return uc.reportUC.ExecuteReply(ctx, req.ChatID, req.UserID, req.Name, req.Message)
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "leaderboard", Usage: "[jenis | durasi | minggu ini | bulan ini | YYYY-MM-DD]", Description: "klasemen"},
+			enabled: func() bool { return uc.leaderboardUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.leaderboard(ctx, req.ChatID, req.Args))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "top", Usage: "[jumlah]", Description: "klasemen teratas saja"},
+			enabled: func() bool { return uc.leaderboardUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.top(ctx, req.ChatID, req.Args))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "recap", Usage: "[bulan]", Description: "rekap mingguan atau bulanan"},
+			enabled: func() bool { return uc.recapUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				if len(req.Args) > 0 && (req.Args[0] == "bulan" || req.Args[0] == "bulanan") {
+					return textReply(uc.recapUC.ExecuteMonthly(ctx))
+				}
+				return textReply(uc.recapUC.ExecuteWeekly(ctx, req.ChatID))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "stats", Description: "statistik pribadi"},
+			enabled: func() bool { return uc.statsUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.statsUC.Execute(ctx, req.UserID, req.Name))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "history", Description: "riwayat bulan ini"},
+			enabled: func() bool { return uc.historyUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.historyUC.Execute(ctx, req.UserID, req.Name))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "grafik", Description: "grafik 30 hari terakhir"},
+			enabled: func() bool { return uc.chartUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return uc.chartUC.Execute(ctx, req.UserID, req.Name)
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "target", Usage: "[hari | hapus]", Description: "target pribadi"},
+			enabled: func() bool { return uc.targetUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.targetUC.Execute(ctx, req.UserID, req.Name, req.Args))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "ingatkan", Usage: "[HH:MM [WIB/WITA/WIT] | off]", Description: "pengingat pribadi lewat chat"},
+			enabled: func() bool { return uc.reminderUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.reminderUC.Execute(ctx, req.UserID, req.Name, req.Args))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "snooze", Usage: "[hari | off]", Description: "jeda pengingat"},
+			enabled: func() bool { return uc.snoozeUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.snoozeUC.Execute(ctx, req.UserID, req.Name, req.Args))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "privat", Usage: "[on | off]", Description: "lapor tanpa muncul di klasemen & recap"},
+			enabled: func() bool { return uc.privateUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.privateUC.Execute(ctx, req.UserID, req.Name, req.Args))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "jangan-tag", Usage: "[on | off]", Description: "tidak di-tag di pengingat & ucapan"},
+			enabled: func() bool { return uc.noMentionUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.noMentionUC.Execute(ctx, req.UserID, req.Name, req.Args))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "email", Usage: "[alamat | off]", Description: "digest klasemen mingguan lewat email"},
+			enabled: func() bool { return uc.emailUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return uc.emailUC.Execute(ctx, req.UserID, req.Name, req.Args)
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "kalender", Usage: "[baru | off]", Description: "riwayat lapor di aplikasi kalender"},
+			enabled: func() bool { return uc.calendarUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return uc.calendarUC.Execute(ctx, req.UserID, req.Name, req.Args)
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "homeassistant", Usage: "[on | off]", Description: "sensor streak untuk Home Assistant"},
+			enabled: func() bool { return uc.homeUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.homeUC.Execute(ctx, req.UserID, req.Name, req.Args))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "link", Usage: "[kode]", Description: "hubungkan akun Telegram/Discord/Strava"},
+			enabled: func() bool { return uc.linkUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.linkUC.Execute(ctx, req.UserID, req.Args))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "mydata", Usage: "[csv]", Description: "unduh semua data kamu"},
+			enabled: func() bool { return uc.exportUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return uc.exportUC.Execute(ctx, req.UserID, req.Name, req.Args)
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "hapusdata", Description: "hapus semua data kamu"},
+			enabled: func() bool { return uc.deleteUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.deleteUC.Execute(ctx, req.UserID, req.Name, req.Args))
+			},
+		},
+		&builtinCommand{
+			help:    CommandHelp{Name: "help", Description: "daftar perintah ini"},
+			enabled: func() bool { return true },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return &Reply{Text: uc.help(req.UserID)}, nil
+			},
+		},
+		&builtinCommand{
+			help:       CommandHelp{Name: "botstats", Description: "kesehatan bot"},
+			permission: PermissionAdmin,
+			enabled:    func() bool { return uc.botStatsUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return &Reply{Text: uc.botStatsUC.Execute()}, nil
+			},
+		},
+		&builtinCommand{
+			help:       CommandHelp{Name: "cari", Usage: "<kata> [tanggal]", Description: "cari di arsip pesan"},
+			permission: PermissionAdmin,
+			enabled:    func() bool { return uc.searchUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.searchUC.Execute(ctx, req.Args))
+			},
+		},
+		&builtinCommand{
+			help:       CommandHelp{Name: "admin", Usage: "set <@member> [streak=N] [total=N] [nama=\"...\"] [klasemen=ya|tidak]", Description: "koreksi data member"},
+			permission: PermissionAdmin,
+			enabled:    func() bool { return uc.memberUC != nil },
+			match: func(lower string) bool {
+				words := strings.Fields(lower)
+				return len(words) > 1 && words[0] == canonicalPrefix+"admin" && words[1] == "set"
+			},
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.correctMember(ctx, SplitArgs(req.Message)[2:]))
+			},
+		},
+		&builtinCommand{
+			help:       CommandHelp{Name: "admin", Usage: "backfill [hari]", Description: "catat #lapor yang terlewat dari arsip pesan"},
+			permission: PermissionAdmin,
+			enabled:    func() bool { return uc.backfillUC != nil },
+			match: func(lower string) bool {
+				words := strings.Fields(lower)
+				return len(words) > 1 && words[0] == canonicalPrefix+"admin" && words[1] == "backfill"
+			},
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				return textReply(uc.backfill(ctx, req.Args[1:], time.Now()))
+			},
+		},
+		&builtinCommand{
+			help:       CommandHelp{Name: "admin", Usage: "<subperintah>", Description: "kelola grup, ketik #admin untuk detail"},
+			permission: PermissionAdmin,
+			enabled:    func() bool { return uc.groupsUC != nil },
+			run: func(ctx context.Context, req CommandRequest) (*Reply, error) {
+				// Subcommands such as the welcome text keep their case
+				return textReply(uc.groupsUC.Execute(ctx, req.UserID, strings.Fields(req.Message)[1:]))
+			},
+		},
+	}
+}
+
+// leaderboard handles #leaderboard [jenis aktivitas | durasi | periode |
+// tanggal].
+func (uc *HandleMessageUsecase) leaderboard(ctx context.Context, chatJID string, args []string) (string, error) {
+	if len(args) > 0 {
+		if args[0] == "durasi" || args[0] == "menit" {
+			return uc.leaderboardUC.ExecuteByDuration(ctx)
+		}
+		if since, until, ok := parsePeriod(args, time.Now()); ok {
+			return uc.leaderboardUC.ExecuteByPeriod(ctx, since, until)
+		}
+		if day, err := time.ParseInLocation("2006-01-02", args[0], time.Local); err == nil && len(args) == 1 {
+			return uc.leaderboardUC.ExecuteOn(ctx, day, uc.leaderboardStyle(chatJID))
+		}
+		if activityType, ok := activity.LookupType(args[0]); ok {
+			return uc.leaderboardUC.ExecuteByType(ctx, activityType)
+		}
+	}
+	return uc.leaderboardUC.ExecuteStyled(ctx, uc.leaderboardStyle(chatJID))
+}
+
+// leaderboardStyle returns the extras the chat turned on with
+// "#admin style".
+func (uc *HandleMessageUsecase) leaderboardStyle(chatJID string) LeaderboardStyle {
+	if uc.groupsUC == nil {
+		return LeaderboardStyle{}
+	}
+	return uc.groupsUC.LeaderboardStyle(chatJID)
+}
+
+// maxTopSize keeps "#top 1000" from posting the whole leaderboard anyway.
+const maxTopSize = 50
+
+// top handles "#top [jumlah]".
+func (uc *HandleMessageUsecase) top(ctx context.Context, chatJID string, args []string) (string, error) {
+	n := 0
+	if len(args) > 0 {
+		var err error
+		n, err = strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > maxTopSize {
+			return fmt.Sprintf("Format: #top [jumlah], 1 sampai %d\nContoh: #top 5", maxTopSize), nil
+		}
+	}
+	return uc.leaderboardUC.ExecuteTop(ctx, n, uc.leaderboardStyle(chatJID))
+}
+
+const (
+	defaultBackfillDays = 7
+	maxBackfillDays     = 90
+)
+
+// backfill handles "#admin backfill [hari]": it counts the #lapor of the
+// last days that are in the message archive but were never recorded, e.g.
+// while the bot was down or crashed mid-message.
+func (uc *HandleMessageUsecase) backfill(ctx context.Context, args []string, now time.Time) (string, error) {
+	days := defaultBackfillDays
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > maxBackfillDays {
+			return fmt.Sprintf("Format: #admin backfill [hari], 1 sampai %d hari\nContoh: #admin backfill 7", maxBackfillDays), nil
+		}
+		days = n
+	}
+
+	y, m, d := now.Date()
+	since := time.Date(y, m, d, 0, 0, 0, 0, now.Location()).AddDate(0, 0, -(days - 1))
+	found, added, err := uc.backfillUC.FromArchive(ctx, since, uc.IsReport)
+	if err != nil {
+		return "", err
+	}
+	if found == 0 {
+		return fmt.Sprintf("Tidak ada #lapor di arsip pesan %d hari terakhir.", days), nil
+	}
+	if added == 0 {
+		return fmt.Sprintf("✅ Semua %d #lapor di arsip %d hari terakhir sudah tercatat.", found, days), nil
+	}
+	return fmt.Sprintf("✅ %d #lapor yang terlewat dicatat (dari %d di arsip %d hari terakhir).", added, found, days), nil
+}
+
+// memberCorrection is the argument list of "#admin set".
+type memberCorrection struct {
+	Member Mention `arg:"member,required"`
+	Streak *int    `flag:"streak"`
+	Total  *int    `flag:"total"`
+	Name   *string `flag:"nama"`
+	Ranked *bool   `flag:"klasemen"`
+}
+
+const memberCorrectionUsage = "Format: #admin set @member [streak=N] [total=N] [nama=\"Nama Baru\"] [klasemen=ya|tidak]\nContoh: #admin set @628123456789 streak=12"
+
+// correctMember handles "#admin set", the chat version of PATCH
+// /api/users/{id}.
+func (uc *HandleMessageUsecase) correctMember(ctx context.Context, args []string) (string, error) {
+	var c memberCorrection
+	if err := ParseArgs(args, &c); err != nil {
+		return "⚠️ " + err.Error() + "\n" + memberCorrectionUsage, nil
+	}
+	if c.Streak == nil && c.Total == nil && c.Name == nil && c.Ranked == nil {
+		return memberCorrectionUsage, nil
+	}
+	if (c.Name != nil && strings.TrimSpace(*c.Name) == "") ||
+		(c.Streak != nil && *c.Streak < 0) || (c.Total != nil && *c.Total < 0) {
+		return "⚠️ Nama tidak boleh kosong dan angka tidak boleh negatif.", nil
+	}
+
+	update := MemberUpdate{Name: c.Name, Streak: c.Streak, ActivityCount: c.Total}
+	if c.Ranked != nil {
+		unranked := !*c.Ranked
+		update.Unranked = &unranked
+	}
+	report, err := uc.memberUC.Update(ctx, uc.hasher.UserID(string(c.Member)), update)
+	if err != nil {
+		return "", err
+	}
+	var text string
+	switch {
+	case report != nil:
+		text = fmt.Sprintf("✅ Data %s diperbarui: streak %d hari, total %d hari.", report.Name, report.Streak, report.ActivityCount)
+	case c.Ranked != nil && c.Streak == nil && c.Total == nil && c.Name == nil:
+		text = fmt.Sprintf("✅ Data %s diperbarui.", c.Member)
+	default:
+		text = fmt.Sprintf("Member %s belum pernah lapor.", c.Member)
+	}
+	if c.Ranked != nil && *c.Ranked {
+		text += "\nKembali masuk klasemen dan recap."
+	} else if c.Ranked != nil {
+		text += "\nTidak masuk klasemen dan recap, tapi tetap bisa memakai semua perintah."
+	}
+	return text, nil
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// ErrCalendarNotFound is returned for a calendar token no member has, e.g.
+// after #kalender baru or #kalender off.
+var ErrCalendarNotFound = errors.New("calendar not found")
+
+// Calendar is a member's report history as calendar events.
+type Calendar struct {
+	Name string
+	Days []CalendarDay
+}
+
+// CalendarDay is one reported day, shown as an all-day event.
+type CalendarDay struct {
+	UID         string    // stable across requests
+	Date        time.Time // the reported day, only the date is used
+	At          time.Time // when the report was made
+	Summary     string    // e.g. "Lari 🏃 (30 menit, 5 km)"
+	Description string
+}
+
+// CalendarUsecase gives every member who asks for it a secret calendar URL
+// with their reported days, so they can overlay their workouts on the
+// calendar app they already use.
+type CalendarUsecase struct {
+	settings   domain.SettingsRepository
+	events     domain.ReportEventRepository
+	activities domain.ActivityRepository
+	baseURL    string
+}
+
+// NewCalendarUsecase serves the calendars under baseURL, the public address
+// of /calendar on the admin API, e.g. "https://bot.example.com/calendar".
+func NewCalendarUsecase(settings domain.SettingsRepository, events domain.ReportEventRepository, activities domain.ActivityRepository, baseURL string) *CalendarUsecase {
+	return &CalendarUsecase{settings: settings, events: events, activities: activities, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Execute handles "#kalender" to show the member's calendar URL, creating
+// it the first time, "#kalender baru" for a new URL when the old one was
+// shared by mistake, and "#kalender off". The URL goes to the member's
+// private chat, since anyone with it can read the calendar.
+func (uc *CalendarUsecase) Execute(ctx context.Context, userID, name string, args []string) (*Reply, error) {
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &domain.UserSettings{UserID: userID}
+	}
+
+	renew := false
+	if len(args) > 0 {
+		switch args[0] {
+		case "baru", "ganti":
+			renew = true
+		case "off", "tidak", "mati":
+			if settings.CalendarToken == "" {
+				return privateReply(fmt.Sprintf("%s belum punya kalender laporan.", name)), nil
+			}
+			settings.CalendarToken = ""
+			if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+				return nil, err
+			}
+			return privateReply(fmt.Sprintf("Kalender laporan %s dimatikan, URL lamanya tidak bisa dibuka lagi.", name)), nil
+		default:
+			return privateReply("Format: #kalender [baru | off]"), nil
+		}
+	}
+
+	if settings.CalendarToken == "" || renew {
+		token, err := randomHex(16)
+		if err != nil {
+			return nil, err
+		}
+		settings.CalendarToken = token
+		if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+			return nil, err
+		}
+	}
+
+	text := fmt.Sprintf("📅 Kalender laporan %s:\n%s\n\n"+
+		"Tambahkan sebagai kalender langganan (Google Calendar: *Tambahkan kalender › Dari URL*, iPhone: *Pengaturan › Kalender › Akun › Tambah Akun › Lainnya › Kalender Langganan*). Setiap hari lapor muncul sebagai acara seharian.\n\n"+
+		"Jangan bagikan URL ini. Ketik #kalender baru untuk mengganti URL, atau #kalender off untuk mematikannya.",
+		name, uc.URL(settings.CalendarToken))
+	if renew {
+		text = "URL lama sudah tidak berlaku.\n\n" + text
+	}
+	return privateReply(text), nil
+}
+
+// URL returns the address of the calendar with token.
+func (uc *CalendarUsecase) URL(token string) string {
+	return uc.baseURL + "/" + token + ".ics"
+}
+
+// Calendar returns the reported days of the member with token, oldest
+// first, or ErrCalendarNotFound.
+func (uc *CalendarUsecase) Calendar(ctx context.Context, token string) (*Calendar, error) {
+	if token == "" {
+		return nil, ErrCalendarNotFound
+	}
+	settings, err := uc.settings.GetSettingsByCalendarToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return nil, ErrCalendarNotFound
+	}
+
+	events, err := uc.events.GetEvents(ctx, settings.UserID)
+	if err != nil {
+		return nil, err
+	}
+	logged, err := uc.activities.GetActivities(ctx, domain.ActivityFilter{UserID: settings.UserID})
+	if err != nil {
+		return nil, err
+	}
+	// Days are keyed in the bot's location: activity_logs keeps times in
+	// UTC, which puts a report sent before 07:00 WIB on the day before
+	byDay := make(map[string][]string)
+	for _, a := range logged {
+		day := a.ReportedAt.In(time.Local).Format("2006-01-02")
+		byDay[day] = append(byDay[day], formatActivity(a))
+	}
+
+	calendar := &Calendar{Name: settings.DisplayName}
+	report := domain.ReplayReport(settings.UserID, events, func(e *domain.ReportEvent, report *domain.Report) {
+		at := e.At.In(time.Local)
+		day := at.Format("2006-01-02")
+		summary := "✅ Lapor"
+		if activities := byDay[day]; len(activities) > 0 {
+			summary = strings.Join(activities, ", ")
+		}
+		calendar.Days = append(calendar.Days, CalendarDay{
+			UID:         fmt.Sprintf("report-%d@lapor-bot", e.ID),
+			Date:        time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC),
+			At:          e.At,
+			Summary:     summary,
+			Description: fmt.Sprintf("Streak %d hari · total %d hari lapor", report.Streak, report.ActivityCount),
+		})
+	})
+	if calendar.Name == "" && report != nil {
+		calendar.Name = report.Name
+	}
+	return calendar, nil
+}
@@ -0,0 +1,120 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// CALENDAR TESTS
+// =============================================================================
+
+func TestCalendar_TokenizedURL(t *testing.T) {
+	settings := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+	events := &mockEventRepo{events: make(map[string][]*domain.ReportEvent)}
+	uc := usecase.NewCalendarUsecase(settings, events, &mockActivityRepo{}, "https://bot.contoh.com/calendar/")
+	ctx := context.Background()
+
+	reply, err := uc.Execute(ctx, "user1", "Alice", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	token := settings.settings["user1"].CalendarToken
+	if len(token) != 32 || !reply.Private || !strings.Contains(reply.Text, "https://bot.contoh.com/calendar/"+token+".ics") {
+		t.Fatalf("Expected a private reply with the calendar URL, got %q (token %q)", reply.Text, token)
+	}
+
+	// Asking again shows the same URL
+	if _, err := uc.Execute(ctx, "user1", "Alice", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings.settings["user1"].CalendarToken != token {
+		t.Error("Expected the token kept")
+	}
+
+	if _, err := uc.Execute(ctx, "user1", "Alice", []string{"baru"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := uc.Calendar(ctx, token); !errors.Is(err, usecase.ErrCalendarNotFound) {
+		t.Errorf("Expected the old URL to stop working, got %v", err)
+	}
+
+	if _, err := uc.Execute(ctx, "user1", "Alice", []string{"off"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings.settings["user1"].CalendarToken != "" {
+		t.Error("Expected the token removed")
+	}
+	if _, err := uc.Calendar(ctx, ""); !errors.Is(err, usecase.ErrCalendarNotFound) {
+		t.Errorf("Expected no calendar without a token, got %v", err)
+	}
+}
+
+func TestCalendar_ReportedDays(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 7, 0, 0, 0, time.UTC) }
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{"user1": {UserID: "user1", CalendarToken: "rahasia"}}}
+	events := &mockEventRepo{events: make(map[string][]*domain.ReportEvent)}
+	ctx := context.Background()
+	for _, d := range []int{1, 2, 4} {
+		_ = events.AppendEvent(ctx, &domain.ReportEvent{UserID: "user1", Type: domain.ReportSubmitted, Name: "Alice", At: day(d)})
+	}
+	// The report of the 2nd was undone
+	_ = events.AppendEvent(ctx, &domain.ReportEvent{UserID: "user1", Type: domain.ReportRevoked, RefID: 2, At: day(3)})
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", DistanceKm: 5, ReportedAt: day(4)},
+	}}
+	uc := usecase.NewCalendarUsecase(settings, events, activities, "https://bot.contoh.com/calendar")
+
+	calendar, err := uc.Calendar(ctx, "rahasia")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calendar.Name != "Alice" || len(calendar.Days) != 2 {
+		t.Fatalf("Expected Alice's two counted days, got %+v", calendar)
+	}
+	first, last := calendar.Days[0], calendar.Days[1]
+	if first.Date.Day() != 1 || first.Summary != "✅ Lapor" {
+		t.Errorf("Expected a plain report on the 1st, got %+v", first)
+	}
+	if last.Date.Day() != 4 || !strings.Contains(last.Summary, "5 km") || last.Description != "Streak 1 hari · total 2 hari lapor" {
+		t.Errorf("Expected the run on the 4th, got %+v", last)
+	}
+	if first.UID == last.UID {
+		t.Error("Expected a UID per day")
+	}
+}
+
+func TestCalendar_ReportedDaysBeforeLocalMorning(t *testing.T) {
+	// The bot runs in UTC+7 while activity_logs keeps times in UTC
+	local := time.Local
+	time.Local = time.FixedZone("WIB", 7*3600)
+	defer func() { time.Local = local }()
+
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{"user1": {UserID: "user1", CalendarToken: "rahasia"}}}
+	events := &mockEventRepo{events: make(map[string][]*domain.ReportEvent)}
+	ctx := context.Background()
+	// Alice reported at 06:00 WIB on the 4th, 23:00 UTC on the 3rd
+	at := time.Date(2026, 10, 4, 6, 0, 0, 0, time.Local)
+	_ = events.AppendEvent(ctx, &domain.ReportEvent{UserID: "user1", Type: domain.ReportSubmitted, Name: "Alice", At: at})
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", DistanceKm: 5, ReportedAt: at.UTC()},
+	}}
+	uc := usecase.NewCalendarUsecase(settings, events, activities, "https://bot.contoh.com/calendar")
+
+	calendar, err := uc.Calendar(ctx, "rahasia")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(calendar.Days) != 1 {
+		t.Fatalf("Expected one day, got %+v", calendar.Days)
+	}
+	if got := calendar.Days[0]; got.Date.Day() != 4 || !strings.Contains(got.Summary, "5 km") {
+		t.Errorf("Expected the run on the 4th, got %+v", got)
+	}
+}
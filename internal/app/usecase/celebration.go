@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"log"
+)
+
+// CelebrationMedia picks a random GIF, video, or image for big milestones.
+type CelebrationMedia interface {
+	Random(ctx context.Context) (data []byte, mimeType string, err error)
+}
+
+// MediaSender sends media to a chat: an MP4 as a GIF, anything else as an
+// image.
+type MediaSender interface {
+	SendMedia(ctx context.Context, chatJID string, data []byte, mimeType, caption string) error
+}
+
+// celebrate attaches a random celebration to the reply, which keeps its text
+// as the caption. Without media, or when picking one fails, the reply stays
+// text only.
+func celebrate(ctx context.Context, media CelebrationMedia, reply *Reply) *Reply {
+	if media == nil {
+		return reply
+	}
+	data, mimeType, err := media.Random(ctx)
+	if err != nil {
+		log.Printf("Failed to pick celebration media: %v", err)
+		return reply
+	}
+	return attach(reply, data, mimeType)
+}
+
+// attach sends an MP4 with the reply as a GIF, and anything else as an
+// image.
+func attach(reply *Reply, data []byte, mimeType string) *Reply {
+	if mimeType == "video/mp4" {
+		reply.GIF = data
+	} else {
+		reply.Image, reply.ImageMimeType = data, mimeType
+	}
+	return reply
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"sort"
+	"strings"
+)
+
+// defaultAliases map everyday phrases to commands. The alias is replaced
+// by the command and the rest of the message is kept, so "sudah lari 5 km"
+// becomes "#lapor lari 5 km".
+var defaultAliases = map[string]string{
+	"done":           "#lapor",
+	"udah olahraga":  "#lapor",
+	"sudah olahraga": "#lapor",
+	"udah lari":      "#lapor lari",
+	"sudah lari":     "#lapor lari",
+	"klasemen":       "#leaderboard",
+}
+
+// commandAlias is one entry of the alias table, kept sorted longest phrase
+// first so "sudah lari" wins over a shorter "sudah".
+type commandAlias struct {
+	phrase  string
+	command string
+}
+
+func buildAliases(aliases map[string]string) []commandAlias {
+	list := make([]commandAlias, 0, len(aliases))
+	for phrase, command := range aliases {
+		list = append(list, commandAlias{phrase: phrase, command: command})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if len(list[i].phrase) != len(list[j].phrase) {
+			return len(list[i].phrase) > len(list[j].phrase)
+		}
+		return list[i].phrase < list[j].phrase
+	})
+	return list
+}
+
+// SetAliases adds to or overrides the default alias table, e.g. from
+// COMMAND_ALIASES. An empty command removes the phrase.
+func (uc *HandleMessageUsecase) SetAliases(aliases map[string]string) {
+	merged := make(map[string]string, len(defaultAliases)+len(aliases))
+	for phrase, command := range defaultAliases {
+		merged[phrase] = command
+	}
+	for phrase, command := range aliases {
+		phrase = strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+		if command = strings.TrimSpace(command); command == "" {
+			delete(merged, phrase)
+		} else {
+			merged[phrase] = command
+		}
+	}
+	uc.aliases = buildAliases(merged)
+}
+
+// resolveAlias rewrites a message that starts with an alias phrase into the
+// command it stands for. Other messages are returned unchanged.
+func (uc *HandleMessageUsecase) resolveAlias(msg string) string {
+	words := strings.Fields(msg)
+	lower := strings.ToLower(strings.Join(words, " "))
+	for _, alias := range uc.aliases {
+		if lower == alias.phrase {
+			return alias.command
+		}
+		if strings.HasPrefix(lower, alias.phrase+" ") {
+			rest := words[len(strings.Fields(alias.phrase)):]
+			return alias.command + " " + strings.Join(rest, " ")
+		}
+	}
+	return msg
+}
@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+)
+
+// Mention is a member named in a command, e.g. "@628123456789" or
+// "08123456789", normalized to the phone number (or LID) without "@".
+type Mention string
+
+// ArgError is a command argument that could not be parsed. Its message is
+// meant for the member who typed the command.
+type ArgError struct {
+	Arg    string
+	Reason string
+}
+
+func (e *ArgError) Error() string {
+	if e.Arg == "" {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s: %s", e.Arg, e.Reason)
+}
+
+// SplitArgs splits the words of a command like strings.Fields, but keeps
+// text in double quotes together, so nama="Budi Santoso" is one argument.
+func SplitArgs(text string) []string {
+	var args []string
+	var current strings.Builder
+	inQuotes, started := false, false
+	for _, r := range text {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			started = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// ParseArgs fills the struct dst points to from command arguments, as
+// described by its field tags:
+//
+//	Member Mention   `arg:"member,required"` // next positional argument
+//	Words  []string  `arg:"kata,rest"`        // all remaining positionals
+//	Streak *int      `flag:"streak"`          // streak=12; nil when absent
+//	Force  bool      `flag:"paksa"`           // paksa or paksa=ya
+//
+// Fields may be string, int, bool, time.Time (a date such as 2026-03-01,
+// 1/3 or 1/3/2026), time.Duration (30m, 1h30m, 45menit, 2jam), Mention, or
+// a pointer to one of them; a rest field may also be []string. Flag names
+// are case-insensitive. Unknown flags and surplus arguments are errors.
+func ParseArgs(args []string, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic("ParseArgs: dst must point to a struct")
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	flags := make(map[string]int)
+	var positional []int
+	rest := -1
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name, ok := f.Tag.Lookup("flag"); ok {
+			flags[strings.ToLower(name)] = i
+		}
+		if tag, ok := f.Tag.Lookup("arg"); ok {
+			if strings.Contains(tag, ",rest") {
+				rest = i
+			} else {
+				positional = append(positional, i)
+			}
+		}
+	}
+
+	var values []string
+	for _, arg := range args {
+		key, value, hasValue := strings.Cut(arg, "=")
+		i, isFlag := flags[strings.ToLower(key)]
+		if !isFlag && hasValue && key != "" && isWord(key) {
+			return &ArgError{Arg: key, Reason: "opsi tidak dikenal"}
+		}
+		if !isFlag {
+			values = append(values, arg)
+			continue
+		}
+		if !hasValue {
+			if t.Field(i).Type.Kind() != reflect.Bool {
+				return &ArgError{Arg: key, Reason: "isi dengan " + key + "=..."}
+			}
+			value = "ya"
+		}
+		if err := setArg(v.Field(i), value); err != nil {
+			return &ArgError{Arg: key, Reason: err.Error()}
+		}
+	}
+
+	for n, i := range positional {
+		name, opts := argTag(t.Field(i))
+		if n >= len(values) {
+			if strings.Contains(opts, "required") {
+				return &ArgError{Arg: name, Reason: "wajib diisi"}
+			}
+			continue
+		}
+		if err := setArg(v.Field(i), values[n]); err != nil {
+			return &ArgError{Arg: name, Reason: err.Error()}
+		}
+	}
+
+	var extra []string
+	if len(values) > len(positional) {
+		extra = values[len(positional):]
+	}
+	if rest < 0 {
+		if len(extra) > 0 {
+			return &ArgError{Arg: extra[0], Reason: "tidak dikenal"}
+		}
+		return nil
+	}
+
+	name, opts := argTag(t.Field(rest))
+	if len(extra) == 0 {
+		if strings.Contains(opts, "required") {
+			return &ArgError{Arg: name, Reason: "wajib diisi"}
+		}
+		return nil
+	}
+	field := v.Field(rest)
+	if field.Kind() == reflect.Slice {
+		field.Set(reflect.ValueOf(extra))
+		return nil
+	}
+	if err := setArg(field, strings.Join(extra, " ")); err != nil {
+		return &ArgError{Arg: name, Reason: err.Error()}
+	}
+	return nil
+}
+
+func argTag(f reflect.StructField) (name, opts string) {
+	name, opts, _ = strings.Cut(f.Tag.Get("arg"), ",")
+	if name == "" {
+		name = strings.ToLower(f.Name)
+	}
+	return name, opts
+}
+
+// isWord reports whether s looks like a flag name rather than part of a
+// value such as a URL with "=" in it.
+func isWord(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	mentionType  = reflect.TypeOf(Mention(""))
+)
+
+// setArg parses s into field. The error is shown to the user.
+func setArg(field reflect.Value, s string) error {
+	if field.Kind() == reflect.Ptr {
+		value := reflect.New(field.Type().Elem())
+		if err := setArg(value.Elem(), s); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+
+	switch field.Type() {
+	case timeType:
+		d, err := parseDate(s, time.Now())
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(d))
+		return nil
+	case durationType:
+		d, err := parseDuration(s)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	case mentionType:
+		m, err := parseMention(s)
+		if err != nil {
+			return err
+		}
+		field.SetString(string(m))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("harus angka, bukan %q", s)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		switch strings.ToLower(s) {
+		case "ya", "on", "true", "1":
+			field.SetBool(true)
+		case "tidak", "off", "false", "0":
+			field.SetBool(false)
+		default:
+			return fmt.Errorf("harus ya atau tidak, bukan %q", s)
+		}
+	default:
+		panic("ParseArgs: unsupported field type " + field.Type().String())
+	}
+	return nil
+}
+
+// parseDate accepts 2026-03-01, 1/3/2026 and 1/3 (this year), in local time.
+func parseDate(s string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2/1/2006"} {
+		if d, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return d, nil
+		}
+	}
+	if d, err := time.ParseInLocation("2/1", s, now.Location()); err == nil {
+		return d.AddDate(now.Year(), 0, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("tanggal tidak valid %q, contoh: 2026-03-01 atau 1/3", s)
+}
+
+// parseDuration accepts Go durations such as 1h30m and the Indonesian
+// units in 45menit or 2jam.
+func parseDuration(s string) (time.Duration, error) {
+	lower := strings.ToLower(s)
+	for suffix, unit := range map[string]time.Duration{"menit": time.Minute, "jam": time.Hour} {
+		if n, ok := strings.CutSuffix(lower, suffix); ok {
+			if v, err := strconv.Atoi(n); err == nil && v >= 0 {
+				return time.Duration(v) * unit, nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(lower); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("durasi tidak valid %q, contoh: 30menit atau 1h30m", s)
+}
+
+// parseMention accepts "@628123...", "+62 812..." and local "0812..." numbers.
+func parseMention(s string) (Mention, error) {
+	raw := strings.TrimPrefix(strings.TrimSpace(s), "@")
+	if strings.HasPrefix(raw, "0") {
+		raw = "62" + raw[1:]
+	}
+	number, err := phone.Normalize(raw)
+	if err != nil {
+		return "", fmt.Errorf("sebut member dengan @nomor, bukan %q", s)
+	}
+	return Mention(number), nil
+}
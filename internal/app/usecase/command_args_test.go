@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+// =============================================================================
+// COMMAND ARGUMENT PARSING TESTS
+// =============================================================================
+
+type leaveArgs struct {
+	Start  time.Time       `arg:"mulai,required"`
+	End    *time.Time      `arg:"selesai"`
+	Member usecase.Mention `flag:"untuk"`
+	Daily  time.Duration   `flag:"durasi"`
+	Quiet  bool            `flag:"diam"`
+	Note   string          `arg:"catatan,rest"`
+}
+
+func TestSplitArgs_KeepsQuotedText(t *testing.T) {
+	got := usecase.SplitArgs(`@628123  nama="Budi Santoso" streak=12`)
+	want := []string{"@628123", "nama=Budi Santoso", "streak=12"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestParseArgs_TypedFields(t *testing.T) {
+	var a leaveArgs
+	args := usecase.SplitArgs(`2026-03-01 5/3 untuk=@0812345678 durasi=45menit diam ke "luar kota"`)
+	if err := usecase.ParseArgs(args, &a); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if a.Start.Format("2006-01-02") != "2026-03-01" {
+		t.Errorf("Expected start 2026-03-01, got %v", a.Start)
+	}
+	if a.End == nil || a.End.Day() != 5 || a.End.Month() != time.March || a.End.Year() != time.Now().Year() {
+		t.Errorf("Expected end on 5 March this year, got %v", a.End)
+	}
+	if a.Member != "62812345678" {
+		t.Errorf("Expected the mention as 62812345678, got %q", a.Member)
+	}
+	if a.Daily != 45*time.Minute || !a.Quiet {
+		t.Errorf("Expected 45m and quiet, got %v %v", a.Daily, a.Quiet)
+	}
+	if a.Note != "ke luar kota" {
+		t.Errorf("Expected the rest as note, got %q", a.Note)
+	}
+}
+
+func TestParseArgs_OptionalFieldsStayZero(t *testing.T) {
+	var a leaveArgs
+	if err := usecase.ParseArgs([]string{"1/3/2026", "durasi=1h30m"}, &a); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a.End != nil || a.Member != "" || a.Quiet || a.Note != "" {
+		t.Errorf("Expected unset fields to stay zero, got %+v", a)
+	}
+	if a.Daily != 90*time.Minute {
+		t.Errorf("Expected 1h30m, got %v", a.Daily)
+	}
+}
+
+func TestParseArgs_Errors(t *testing.T) {
+	cases := map[string][]string{
+		"missing required": {},
+		"bad date":         {"kemarin"},
+		"bad mention":      {"2026-03-01", "untuk=@budi"},
+		"bad duration":     {"2026-03-01", "durasi=lama"},
+		"flag needs value": {"2026-03-01", "durasi"},
+		"unknown flag":     {"2026-03-01", "warna=merah"},
+	}
+	for name, args := range cases {
+		var a leaveArgs
+		err := usecase.ParseArgs(args, &a)
+		var argErr *usecase.ArgError
+		if !errors.As(err, &argErr) {
+			t.Errorf("%s: expected an ArgError, got %v", name, err)
+		}
+	}
+
+	// Without a rest field, surplus words are errors too
+	var strict struct {
+		Days int `arg:"hari"`
+	}
+	if err := usecase.ParseArgs([]string{"3", "lagi"}, &strict); err == nil {
+		t.Error("Expected an error for the surplus argument")
+	}
+	if err := usecase.ParseArgs([]string{"tiga"}, &strict); err == nil {
+		t.Error("Expected an error for a non-number")
+	}
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// canonicalPrefix is the prefix the router and all replies are written
+// with. Other prefixes are translated to and from it in ExecuteInChat.
+const canonicalPrefix = "#"
+
+// commandMention matches command names such as "#lapor" and "#jangan-tag".
+var commandMention = regexp.MustCompile(`#([a-z]+(?:-[a-z]+)*)`)
+
+// SetCommandPrefix sets the default prefix, e.g. "!" or "/", from
+// COMMAND_PREFIX. Groups may override it with "#admin prefix".
+func (uc *HandleMessageUsecase) SetCommandPrefix(prefix string) {
+	uc.prefix = prefix
+}
+
+// prefixFor returns the command prefix used in a chat.
+func (uc *HandleMessageUsecase) prefixFor(chatJID string) string {
+	if uc.groupsUC != nil {
+		if prefix := uc.groupsUC.CommandPrefix(chatJID); prefix != "" {
+			return prefix
+		}
+	}
+	if uc.prefix != "" {
+		return uc.prefix
+	}
+	return canonicalPrefix
+}
+
+const unknownCommandHint = "Perintah tidak dikenal. Ketik #help untuk daftar perintah."
+
+// ExecuteInChat is the entry point for incoming messages. It translates the
+// chat's command prefix to "#", routes the message like ExecuteReply, and
+// writes command names in the reply with the chat's prefix again.
+func (uc *HandleMessageUsecase) ExecuteInChat(ctx context.Context, chatJID, userID, name, message string) (*Reply, error) {
+	prefix := uc.prefixFor(chatJID)
+
+	// Answers in a conversation such as #join arrive as plain private messages
+	if uc.conversations != nil && !strings.HasSuffix(chatJID, "@g.us") {
+		text, ok, err := uc.conversations.Handle(ctx, userID, message)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &Reply{Text: uc.localizePrefix(text, prefix)}, nil
+		}
+	}
+
+	msg := strings.TrimSpace(message)
+	if prefix != canonicalPrefix {
+		switch {
+		case strings.HasPrefix(msg, prefix):
+			msg = canonicalPrefix + msg[len(prefix):]
+		case strings.HasPrefix(msg, canonicalPrefix):
+			// "#" is plain text in chats that use another prefix
+			return &Reply{}, nil
+		}
+	}
+
+	reply, err := uc.executeReply(ctx, chatJID, userID, name, msg)
+	if err != nil {
+		return nil, err
+	}
+	if reply.Text == "" && reply.Image == nil && reply.Document == nil && uc.wantsHint(chatJID, msg) {
+		reply.Text = unknownCommandHint
+	}
+	if prefix != canonicalPrefix {
+		reply.Text = uc.localizePrefix(reply.Text, prefix)
+	}
+	return reply, nil
+}
+
+// wantsHint reports whether msg looks like a command, e.g. "#lapr", and the
+// chat turned on hints for unknown commands. Chatter such as "# 1" or
+// "#" alone is not treated as a command.
+func (uc *HandleMessageUsecase) wantsHint(chatJID, msg string) bool {
+	if uc.groupsUC == nil || !uc.groupsUC.CommandHint(chatJID) {
+		return false
+	}
+	rest, ok := strings.CutPrefix(msg, canonicalPrefix)
+	if !ok || rest == "" {
+		return false
+	}
+	return unicode.IsLetter([]rune(rest)[0])
+}
+
+// localizePrefix rewrites "#lapor" and the other commands in text to use
+// prefix instead of "#". Hashtags that are not commands are left alone.
+func (uc *HandleMessageUsecase) localizePrefix(text, prefix string) string {
+	return commandMention.ReplaceAllStringFunc(text, func(m string) string {
+		if uc.isCommandName(m[1:]) {
+			return prefix + m[1:]
+		}
+		return m
+	})
+}
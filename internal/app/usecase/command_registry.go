@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Permission says who may run a command.
+type Permission int
+
+const (
+	PermissionMember Permission = iota // everyone in a served chat
+	PermissionAdmin                    // only the numbers set with SetAdmins
+)
+
+// CommandHelp describes a command for #help, typo suggestions and prefix
+// rewriting.
+type CommandHelp struct {
+	Name        string // without the prefix, e.g. "lapor"
+	Usage       string // Arguments shown after the command, may be empty
+	Description string
+}
+
+// CommandRequest is a message routed to a command.
+type CommandRequest struct {
+	ChatID  string // empty when the message did not come from a chat, e.g. in tests
+	UserID  string
+	Name    string
+	Message string   // trimmed, with aliases resolved and "#" as the prefix
+	Args    []string // lowercased words after the command
+}
+
+// Command is one chat command. The built-in commands wrap the use cases of
+// HandleMessageUsecase; other packages add their own with RegisterCommand at
+// startup.
+type Command interface {
+	// Match reports whether the command handles the message. lower is the
+	// lowercased message with "#" as the prefix.
+	Match(lower string) bool
+	Execute(ctx context.Context, req CommandRequest) (*Reply, error)
+	Help() CommandHelp
+	Permissions() Permission
+}
+
+// RegisterCommand adds a command after the built-in ones. The first command
+// that matches a message handles it. Names must be unique.
+func (uc *HandleMessageUsecase) RegisterCommand(cmd Command) error {
+	if name := cmd.Help().Name; uc.isCommandName(name) {
+		return fmt.Errorf("command #%s already exists", name)
+	}
+	uc.commands = append(uc.commands, cmd)
+	return nil
+}
+
+// findCommand returns the first enabled command that matches, or nil.
+func (uc *HandleMessageUsecase) findCommand(lower string) Command {
+	for _, c := range uc.commands {
+		if commandEnabled(c) && c.Match(lower) {
+			return c
+		}
+	}
+	return nil
+}
+
+// commandEnabled reports whether the use case behind a built-in command is
+// wired. Registered commands are always enabled.
+func commandEnabled(c Command) bool {
+	b, ok := c.(*builtinCommand)
+	return !ok || b.enabled()
+}
+
+// isCommandName reports whether name is a command, wired or not.
+func (uc *HandleMessageUsecase) isCommandName(name string) bool {
+	for _, c := range uc.commands {
+		if c.Help().Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedCommands returns the commands the user can run here, in #help
+// order.
+func (uc *HandleMessageUsecase) allowedCommands(userID string) []Command {
+	var allowed []Command
+	for _, c := range uc.commands {
+		if commandEnabled(c) && (c.Permissions() != PermissionAdmin || uc.IsAdmin(userID)) {
+			allowed = append(allowed, c)
+		}
+	}
+	return allowed
+}
+
+// availableCommands lists the names of the commands the user can run,
+// sorted by name.
+func (uc *HandleMessageUsecase) availableCommands(userID string) []string {
+	var names []string
+	for _, c := range uc.allowedCommands(userID) {
+		names = append(names, c.Help().Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// help lists only the commands the user is allowed to run; admins get an
+// extra section.
+func (uc *HandleMessageUsecase) help(userID string) string {
+	var member, admin strings.Builder
+	for _, c := range uc.allowedCommands(userID) {
+		h := c.Help()
+		line := canonicalPrefix + h.Name
+		if h.Usage != "" {
+			line += " " + h.Usage
+		}
+		line += " - " + h.Description + "\n"
+
+		if c.Permissions() == PermissionAdmin {
+			admin.WriteString(line)
+		} else {
+			member.WriteString(line)
+		}
+	}
+
+	text := "Daftar perintah 📋\n\n" + member.String()
+	if admin.Len() > 0 {
+		text += "\nKhusus admin 🔑\n" + admin.String()
+	}
+	return strings.TrimRight(text, "\n")
+}
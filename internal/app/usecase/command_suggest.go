@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetSuggestions turns the "maksud kamu #lapor?" hint for mistyped
+// commands on or off.
+func (uc *HandleMessageUsecase) SetSuggestions(enabled bool) {
+	uc.suggest = enabled
+}
+
+// suggestCommand returns a hint for a mistyped command such as "#lapr", or
+// "" when nothing is close enough. lower is the lowercased message.
+func (uc *HandleMessageUsecase) suggestCommand(userID, lower string) string {
+	if !uc.suggest || !strings.HasPrefix(lower, canonicalPrefix) {
+		return ""
+	}
+	word := strings.TrimPrefix(strings.Fields(lower)[0], canonicalPrefix)
+	if word == "" {
+		return ""
+	}
+
+	// Allow one typo in short words and two in longer ones
+	maxDistance := 1
+	if len(word) > 5 {
+		maxDistance = 2
+	}
+
+	best, bestDistance := "", maxDistance+1
+	for _, name := range uc.availableCommands(userID) {
+		if d := editDistance(word, name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" || bestDistance == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Maksud kamu #%s? 🤔", best)
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
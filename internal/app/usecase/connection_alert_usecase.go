@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// Notifier delivers an operational alert, e.g. as a WhatsApp DM to the admins
+// or a webhook call.
+type Notifier interface {
+	Notify(ctx context.Context, event, text string) error
+}
+
+// ConnectionAlertUsecase tells admins about WhatsApp session changes. While
+// the bot is offline it cannot DM anyone, so outages go to the webhook right
+// away and to the admins' DMs once the connection is back.
+type ConnectionAlertUsecase struct {
+	admins  Notifier
+	webhook Notifier
+
+	mu        sync.Mutex
+	downSince time.Time
+}
+
+// NewConnectionAlertUsecase accepts nil for either channel.
+func NewConnectionAlertUsecase(admins, webhook Notifier) *ConnectionAlertUsecase {
+	return &ConnectionAlertUsecase{admins: admins, webhook: webhook}
+}
+
+func (uc *ConnectionAlertUsecase) Execute(ctx context.Context, event domain.ConnectionEvent) {
+	now := time.Now()
+
+	uc.mu.Lock()
+	downSince := uc.downSince
+	switch event {
+	case domain.ConnectionDisconnected, domain.ConnectionLoggedOut, domain.ConnectionReplaced, domain.ConnectionBanned:
+		if downSince.IsZero() {
+			uc.downSince = now
+		}
+	case domain.ConnectionConnected:
+		uc.downSince = time.Time{}
+	}
+	uc.mu.Unlock()
+
+	switch event {
+	case domain.ConnectionDisconnected:
+		if downSince.IsZero() {
+			uc.send(ctx, event, fmt.Sprintf("⚠️ Bot terputus dari WhatsApp pada %s, mencoba menyambung ulang...", now.Format("15:04")), false)
+		}
+	case domain.ConnectionLoggedOut:
+		uc.send(ctx, event, "🚨 Sesi WhatsApp bot logout (perangkat di-unlink). Semua pengiriman berhenti sampai bot di-pair ulang: restart bot, atau POST /api/session/pair di admin API.", false)
+	case domain.ConnectionBanned:
+		uc.send(ctx, event, "🚫 Nomor bot diblokir WhatsApp. Semua pengiriman berhenti; cek WhatsApp di HP bot. Setelah blokir dicabut, restart bot atau pair ulang lewat POST /api/session/pair.", false)
+	case domain.ConnectionReplaced:
+		uc.send(ctx, event, "🚨 Sesi WhatsApp bot diambil alih perangkat lain. Bot berhenti sampai di-restart.", false)
+	case domain.ConnectionPaired:
+		uc.send(ctx, event, "✅ Bot berhasil di-pair ulang dengan WhatsApp.", true)
+	case domain.ConnectionConnected:
+		// The first connect after start is not news
+		if !downSince.IsZero() {
+			text := fmt.Sprintf("✅ Bot tersambung kembali setelah terputus %s (sejak %s).",
+				now.Sub(downSince).Round(time.Second), downSince.Format("15:04"))
+			uc.send(ctx, event, text, true)
+		}
+	}
+}
+
+// send always tries the webhook; admin DMs only make sense while connected.
+func (uc *ConnectionAlertUsecase) send(ctx context.Context, event domain.ConnectionEvent, text string, online bool) {
+	if uc.webhook != nil {
+		if err := uc.webhook.Notify(ctx, string(event), text); err != nil {
+			log.Printf("Failed to call alert webhook: %v", err)
+		}
+	}
+	if online && uc.admins != nil {
+		if err := uc.admins.Notify(ctx, string(event), text); err != nil {
+			log.Printf("Failed to notify admins: %v", err)
+		}
+	}
+}
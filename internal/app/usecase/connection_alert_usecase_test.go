@@ -0,0 +1,78 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockNotifier struct {
+	events []string
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, event, text string) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+// =============================================================================
+// CONNECTION ALERT TESTS
+// =============================================================================
+
+func TestConnectionAlert_OutageAndRecovery(t *testing.T) {
+	admins := &mockNotifier{}
+	hook := &mockNotifier{}
+	uc := usecase.NewConnectionAlertUsecase(admins, hook)
+	ctx := context.Background()
+
+	// Initial connect after start is not reported
+	uc.Execute(ctx, domain.ConnectionConnected)
+	if len(admins.events)+len(hook.events) != 0 {
+		t.Fatalf("Startup connect should not alert, got admins=%v webhook=%v", admins.events, hook.events)
+	}
+
+	// Outage goes to the webhook only, repeated disconnects are reported once
+	uc.Execute(ctx, domain.ConnectionDisconnected)
+	uc.Execute(ctx, domain.ConnectionDisconnected)
+	if len(hook.events) != 1 || len(admins.events) != 0 {
+		t.Fatalf("Expected one webhook alert and no DM while offline, got admins=%v webhook=%v", admins.events, hook.events)
+	}
+
+	// Recovery is sent to both
+	uc.Execute(ctx, domain.ConnectionConnected)
+	if len(admins.events) != 1 || admins.events[0] != "connected" || len(hook.events) != 2 {
+		t.Errorf("Expected recovery alert on both channels, got admins=%v webhook=%v", admins.events, hook.events)
+	}
+}
+
+func TestConnectionAlert_LoggedOutWithoutWebhook(t *testing.T) {
+	admins := &mockNotifier{}
+	uc := usecase.NewConnectionAlertUsecase(admins, nil)
+	ctx := context.Background()
+
+	uc.Execute(ctx, domain.ConnectionLoggedOut)
+	uc.Execute(ctx, domain.ConnectionPaired)
+	if len(admins.events) != 1 || admins.events[0] != "paired" {
+		t.Errorf("Expected only the re-pair DM, got %v", admins.events)
+	}
+}
+
+func TestConnectionAlert_BannedGoesToWebhook(t *testing.T) {
+	admins := &mockNotifier{}
+	hook := &mockNotifier{}
+	uc := usecase.NewConnectionAlertUsecase(admins, hook)
+	ctx := context.Background()
+
+	uc.Execute(ctx, domain.ConnectionBanned)
+	if len(hook.events) != 1 || hook.events[0] != "banned" || len(admins.events) != 0 {
+		t.Fatalf("Expected the ban on the webhook only, got admins=%v webhook=%v", admins.events, hook.events)
+	}
+
+	// Reconnecting after the ban counts as recovery
+	uc.Execute(ctx, domain.ConnectionConnected)
+	if len(admins.events) != 1 || admins.events[0] != "connected" {
+		t.Errorf("Expected a recovery DM, got %v", admins.events)
+	}
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// conversationTimeout is how long a conversation waits for the next answer.
+const conversationTimeout = 30 * time.Minute
+
+// ConversationFlow is one multi-message conversation, e.g. the #join
+// questions. The manager keeps its state between messages.
+type ConversationFlow interface {
+	// Name identifies the flow in the stored conversation.
+	Name() string
+	// Step handles one answer. It may change conv.Step and conv.Data, and
+	// returns the reply and whether the conversation is finished.
+	Step(ctx context.Context, conv *domain.Conversation, answer string) (reply string, done bool, err error)
+	// Cancelled is the reply when the member types "batal".
+	Cancelled() string
+}
+
+// ConversationManager routes private messages to the flow a member is in.
+// State is kept in a SessionStore so flows survive a restart; unanswered
+// conversations expire after conversationTimeout.
+type ConversationManager struct {
+	repo  domain.SessionStore
+	flows map[string]ConversationFlow
+}
+
+func NewConversationManager(repo domain.SessionStore) *ConversationManager {
+	return &ConversationManager{repo: repo, flows: make(map[string]ConversationFlow)}
+}
+
+// Register makes a flow available to Start.
+func (m *ConversationManager) Register(flow ConversationFlow) {
+	m.flows[flow.Name()] = flow
+}
+
+// Start opens a conversation, replacing any other one the member was in.
+func (m *ConversationManager) Start(ctx context.Context, userID, flow string, data map[string]string) error {
+	if data == nil {
+		data = make(map[string]string)
+	}
+	return m.repo.SaveConversation(ctx, &domain.Conversation{
+		UserID:    userID,
+		Flow:      flow,
+		Data:      data,
+		ExpiresAt: time.Now().Add(conversationTimeout),
+	})
+}
+
+// Active returns the open conversation of a member, or nil. Expired and
+// unknown conversations are removed.
+func (m *ConversationManager) Active(ctx context.Context, userID string) (*domain.Conversation, error) {
+	conv, err := m.repo.GetConversation(ctx, userID)
+	if err != nil || conv == nil {
+		return nil, err
+	}
+	if time.Now().After(conv.ExpiresAt) || m.flows[conv.Flow] == nil {
+		return nil, m.repo.DeleteConversation(ctx, userID)
+	}
+	return conv, nil
+}
+
+// Handle passes a message to the member's open conversation. ok is false
+// when there is none, so the message should be handled as usual.
+func (m *ConversationManager) Handle(ctx context.Context, userID, message string) (reply string, ok bool, err error) {
+	conv, err := m.Active(ctx, userID)
+	if err != nil || conv == nil {
+		return "", false, err
+	}
+	flow := m.flows[conv.Flow]
+
+	answer := strings.TrimSpace(message)
+	if lower := strings.ToLower(answer); lower == "batal" || lower == canonicalPrefix+"batal" {
+		return flow.Cancelled(), true, m.repo.DeleteConversation(ctx, userID)
+	}
+
+	reply, done, err := flow.Step(ctx, conv, answer)
+	if err != nil {
+		return "", true, err
+	}
+	if done {
+		return reply, true, m.repo.DeleteConversation(ctx, userID)
+	}
+
+	conv.ExpiresAt = time.Now().Add(conversationTimeout)
+	return reply, true, m.repo.SaveConversation(ctx, conv)
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// QuoteSource provides the motivational quotes to rotate through. QuoteList
+// is the local one; a quote API can implement it as well.
+type QuoteSource interface {
+	Quotes(ctx context.Context) ([]string, error)
+}
+
+// QuoteList is a fixed list of quotes, e.g. read from QUOTES_FILE.
+type QuoteList []string
+
+func (l QuoteList) Quotes(ctx context.Context) ([]string, error) {
+	return l, nil
+}
+
+// ParseQuoteList reads one quote per line. Blank lines and lines starting
+// with # are skipped.
+func ParseQuoteList(r io.Reader) (QuoteList, error) {
+	var quotes QuoteList
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		quotes = append(quotes, line)
+	}
+	return quotes, scanner.Err()
+}
+
+// DailyQuoteUsecase picks the motivational quote of the day for the prompt
+// and the recap. Every quote has its turn before one repeats.
+type DailyQuoteUsecase struct {
+	source QuoteSource
+	repo   domain.QuoteRepository
+
+	mu sync.Mutex
+}
+
+func NewDailyQuoteUsecase(source QuoteSource, repo domain.QuoteRepository) *DailyQuoteUsecase {
+	return &DailyQuoteUsecase{source: source, repo: repo}
+}
+
+// Today returns the quote of the day of now, the same one for every call
+// that day. Once every quote was shown the rotation starts over, without
+// repeating the last one right away. Returns "" when there are no quotes.
+func (uc *DailyQuoteUsecase) Today(ctx context.Context, now time.Time) (string, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	quotes, err := uc.source.Quotes(ctx)
+	if err != nil || len(quotes) == 0 {
+		return "", err
+	}
+	uses, err := uc.repo.GetQuoteUses(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	day := now.Format("2006-01-02")
+	byHash := make(map[string]string, len(quotes))
+	for _, q := range quotes {
+		byHash[quoteHash(q)] = q
+	}
+	used := make(map[string]bool, len(uses))
+	last := &domain.QuoteUse{}
+	for _, use := range uses {
+		if q, ok := byHash[use.Hash]; ok && use.Day == day {
+			return q, nil
+		}
+		used[use.Hash] = true
+		if use.Day > last.Day {
+			last = use
+		}
+	}
+
+	fresh := unusedQuotes(quotes, used)
+	if len(fresh) == 0 {
+		if err := uc.repo.DeleteQuoteUses(ctx); err != nil {
+			return "", err
+		}
+		fresh = unusedQuotes(quotes, map[string]bool{last.Hash: len(quotes) > 1})
+	}
+
+	q := fresh[rand.Intn(len(fresh))]
+	if err := uc.repo.SaveQuoteUse(ctx, &domain.QuoteUse{Hash: quoteHash(q), Day: day}); err != nil {
+		return "", err
+	}
+	return q, nil
+}
+
+func unusedQuotes(quotes []string, used map[string]bool) []string {
+	var fresh []string
+	for _, q := range quotes {
+		if !used[quoteHash(q)] {
+			fresh = append(fresh, q)
+		}
+	}
+	return fresh
+}
+
+func quoteHash(quote string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(quote)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// withQuote appends the quote of the day to a scheduled message. A failing
+// quote source does not hold the message back.
+func withQuote(ctx context.Context, quotes *DailyQuoteUsecase, text string) string {
+	if quotes == nil {
+		return text
+	}
+	quote, err := quotes.Today(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failed to pick the quote of the day: %v", err)
+		return text
+	}
+	if quote == "" {
+		return text
+	}
+	return text + "\n\n💬 " + quote
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// deleteConfirmWindow is how long a #hapusdata request waits for "#hapusdata ya".
+const deleteConfirmWindow = 2 * time.Minute
+
+type DeleteUserDataUsecase struct {
+	repo       domain.ReportRepository
+	activities domain.ActivityRepository
+	settings   domain.SettingsRepository
+	messages   domain.MessageArchiveRepository
+	events     domain.ReportEventRepository
+	identities domain.IdentityRepository
+	snapshots  domain.SnapshotRepository
+	sessions   domain.SessionStore
+	lids       LIDMappings
+
+	mu      sync.Mutex
+	pending map[string]time.Time // userID -> confirmation deadline
+}
+
+func NewDeleteUserDataUsecase(repo domain.ReportRepository, activities domain.ActivityRepository, settings domain.SettingsRepository) *DeleteUserDataUsecase {
+	return &DeleteUserDataUsecase{
+		repo:       repo,
+		activities: activities,
+		settings:   settings,
+		pending:    make(map[string]time.Time),
+	}
+}
+
+// SetMessageArchive also deletes the user's archived messages.
+func (uc *DeleteUserDataUsecase) SetMessageArchive(messages domain.MessageArchiveRepository) {
+	uc.messages = messages
+}
+
+// SetEventRepository also deletes the user's report history.
+func (uc *DeleteUserDataUsecase) SetEventRepository(events domain.ReportEventRepository) {
+	uc.events = events
+}
+
+// LIDMappings forgets which LIDs belong to a user.
+type LIDMappings interface {
+	DeleteLIDMappings(ctx context.Context, userID string) error
+}
+
+// SetLIDMappings deletes the user's LID mappings from the WhatsApp session,
+// which may be in another database than the reports, instead of through the
+// report repository.
+func (uc *DeleteUserDataUsecase) SetLIDMappings(lids LIDMappings) {
+	uc.lids = lids
+}
+
+// SetIdentities also unlinks the user's accounts on other platforms.
+func (uc *DeleteUserDataUsecase) SetIdentities(identities domain.IdentityRepository) {
+	uc.identities = identities
+}
+
+// SetSnapshots also removes the user from past leaderboard snapshots.
+func (uc *DeleteUserDataUsecase) SetSnapshots(snapshots domain.SnapshotRepository) {
+	uc.snapshots = snapshots
+}
+
+// SetSessions also closes the user's open conversation, such as the
+// #join questions.
+func (uc *DeleteUserDataUsecase) SetSessions(sessions domain.SessionStore) {
+	uc.sessions = sessions
+}
+
+// Execute handles #hapusdata. The first call only asks for confirmation;
+// "#hapusdata ya" within the confirmation window deletes everything.
+func (uc *DeleteUserDataUsecase) Execute(ctx context.Context, userID, name string, args []string) (string, error) {
+	if len(args) > 0 && args[0] == "ya" {
+		if !uc.confirm(userID) {
+			return "Tidak ada permintaan hapus data yang aktif. Ketik #hapusdata dulu.", nil
+		}
+		if err := uc.Delete(ctx, userID); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Semua data %s sudah dihapus permanen. Terima kasih sudah berkeringat bareng 🙏", name), nil
+	}
+
+	uc.mu.Lock()
+	uc.pending[userID] = time.Now().Add(deleteConfirmWindow)
+	uc.mu.Unlock()
+
+	return fmt.Sprintf("⚠️ %s, ini akan menghapus PERMANEN semua laporan, streak, riwayat aktivitas, pesan yang diarsipkan, dan pengaturan kamu.\n"+
+		"Ketik *#hapusdata ya* dalam %d menit untuk konfirmasi.", name, int(deleteConfirmWindow.Minutes())), nil
+}
+
+// confirm consumes a pending request, reporting whether it was still valid.
+func (uc *DeleteUserDataUsecase) confirm(userID string) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	deadline, ok := uc.pending[userID]
+	delete(uc.pending, userID)
+	return ok && time.Now().Before(deadline)
+}
+
+// Delete permanently removes the report row and history, activity log,
+// archived messages, settings, linked accounts, leaderboard snapshots, open
+// conversation and LID mappings of a user. Used directly by the admin API.
+func (uc *DeleteUserDataUsecase) Delete(ctx context.Context, userID string) error {
+	if uc.activities != nil {
+		if err := uc.activities.DeleteActivities(ctx, domain.ActivityFilter{UserID: userID}); err != nil {
+			return fmt.Errorf("failed to delete activities: %w", err)
+		}
+	}
+	if uc.messages != nil {
+		if err := uc.messages.DeleteMessages(ctx, domain.MessageFilter{SenderID: userID}); err != nil {
+			return fmt.Errorf("failed to delete archived messages: %w", err)
+		}
+	}
+	if uc.settings != nil {
+		if err := uc.settings.DeleteSettings(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete settings: %w", err)
+		}
+	}
+	if uc.events != nil {
+		if err := uc.events.DeleteEvents(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete report events: %w", err)
+		}
+	}
+	if uc.identities != nil {
+		if err := uc.identities.DeleteIdentities(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete linked identities: %w", err)
+		}
+	}
+	if uc.snapshots != nil {
+		if err := uc.snapshots.DeleteSnapshots(ctx, domain.SnapshotFilter{UserID: userID}); err != nil {
+			return fmt.Errorf("failed to delete leaderboard snapshots: %w", err)
+		}
+	}
+	if uc.sessions != nil {
+		if err := uc.sessions.DeleteConversation(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete conversation: %w", err)
+		}
+	}
+	if err := uc.repo.DeleteReport(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete report: %w", err)
+	}
+	var lids LIDMappings = uc.repo
+	if uc.lids != nil {
+		lids = uc.lids
+	}
+	if err := lids.DeleteLIDMappings(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete LID mappings: %w", err)
+	}
+	return nil
+}
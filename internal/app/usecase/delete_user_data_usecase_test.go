@@ -0,0 +1,81 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// DATA DELETION TESTS
+// =============================================================================
+
+func TestHapusData_RequiresConfirmation(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 2, ActivityCount: 5, LastReportDate: now},
+		"user2": {UserID: "user2", Name: "Bob", Streak: 1, ActivityCount: 1, LastReportDate: now},
+	}}
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", ActivityType: "lari", ReportedAt: now},
+		{UserID: "user2", ActivityType: "gym", ReportedAt: now},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{"user1": {UserID: "user1", Target: 20}}}
+
+	sessions := &mockConversationRepo{conversations: map[string]*domain.Conversation{
+		"user1": {UserID: "user1", Flow: "join", Step: 1},
+		"user2": {UserID: "user2", Flow: "join", Step: 1},
+	}}
+
+	deleteUC := usecase.NewDeleteUserDataUsecase(repo, activities, settings)
+	deleteUC.SetSessions(sessions)
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetDeleteUsecase(deleteUC)
+	ctx := context.Background()
+
+	// Confirming without a request does nothing
+	result, err := handleUC.Execute(ctx, "user1", "Alice", "#hapusdata ya")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if repo.reports["user1"] == nil || !containsSubstring(result, "Tidak ada permintaan") {
+		t.Fatalf("Expected no deletion without request, got '%s'", result)
+	}
+
+	// First call only asks for confirmation
+	result, err = handleUC.Execute(ctx, "user1", "Alice", "#hapusdata")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if repo.reports["user1"] == nil || !containsSubstring(result, "#hapusdata ya") {
+		t.Fatalf("Expected confirmation prompt, got '%s'", result)
+	}
+
+	// Another member cannot confirm on Alice's behalf
+	if _, err := handleUC.Execute(ctx, "user2", "Bob", "#hapusdata ya"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if repo.reports["user2"] == nil {
+		t.Fatalf("Bob's data must not be deleted")
+	}
+
+	result, err = handleUC.Execute(ctx, "user1", "Alice", "#hapusdata ya")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "dihapus permanen") {
+		t.Errorf("Expected deletion message, got '%s'", result)
+	}
+	if repo.reports["user1"] != nil || settings.settings["user1"] != nil {
+		t.Errorf("Expected report and settings deleted")
+	}
+	if len(activities.activities) != 1 || activities.activities[0].UserID != "user2" {
+		t.Errorf("Expected only Bob's activity left, got %+v", activities.activities)
+	}
+	if sessions.conversations["user1"] != nil || sessions.conversations["user2"] == nil {
+		t.Errorf("Expected only Alice's conversation deleted, got %+v", sessions.conversations)
+	}
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// EventPublisher sends domain events to a message broker such as NATS or
+// Kafka. Publishing should not wait for the broker, it happens while the
+// member waits for the reply.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *domain.DomainEvent) error
+}
+
+// EventPublishers publishes every event to each of its publishers.
+type EventPublishers []EventPublisher
+
+func (p EventPublishers) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// publishEvents gives each event an ID and publishes it. A broker that is
+// down only loses the events, the report already counted.
+func publishEvents(ctx context.Context, publisher EventPublisher, events ...*domain.DomainEvent) {
+	if publisher == nil {
+		return
+	}
+	for _, event := range events {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			log.Printf("Failed to publish %s event: %v", event.Type, err)
+			continue
+		}
+		event.ID = hex.EncodeToString(b)
+		if err := publisher.Publish(ctx, event); err != nil {
+			log.Printf("Failed to publish %s event of %s: %v", event.Type, event.UserID, err)
+		}
+	}
+}
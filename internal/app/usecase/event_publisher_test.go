@@ -0,0 +1,120 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockPublisher struct {
+	events []*domain.DomainEvent
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockPublisher) types() []string {
+	var types []string
+	for _, e := range m.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// =============================================================================
+// DOMAIN EVENT TESTS
+// =============================================================================
+
+func TestReportEvents_ReportAccepted(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	publisher := &mockPublisher{}
+	uc := usecase.NewReportActivityUsecase(repo)
+	uc.SetEventPublisher(publisher)
+
+	if _, err := uc.ExecuteWithMessage(context.Background(), "user1", "Alice", "#lapor lari 5km 30 menit"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(publisher.events) != 1 {
+		t.Fatalf("Expected one event for a first report, got %v", publisher.types())
+	}
+	e := publisher.events[0]
+	if e.Type != domain.EventReportAccepted || e.UserID != "user1" || e.Name != "Alice" || e.Streak != 1 || e.ActivityCount != 1 {
+		t.Errorf("Expected report_accepted with the new row, got %+v", e)
+	}
+	if e.ActivityType != "lari" || e.DistanceKm != 5 || e.DurationMinutes != 30 {
+		t.Errorf("Expected the parsed activity, got %+v", e)
+	}
+	if e.ID == "" || e.At.IsZero() {
+		t.Errorf("Expected an ID and time, got %+v", e)
+	}
+}
+
+func TestReportEvents_StreakBroken(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Bob", Streak: 12, ActivityCount: 20, LastReportDate: time.Now().AddDate(0, 0, -3)},
+		"user2": {UserID: "user2", Name: "Cici", Streak: 4, ActivityCount: 4, LastReportDate: time.Now().AddDate(0, 0, -1)},
+	}}
+	publisher := &mockPublisher{}
+	uc := usecase.NewReportActivityUsecase(repo)
+	uc.SetEventPublisher(publisher)
+
+	if _, err := uc.Execute(context.Background(), "user1", "Bob"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := publisher.types(); len(got) != 2 || got[1] != domain.EventStreakBroken {
+		t.Fatalf("Expected report_accepted and streak_broken after a missed day, got %v", got)
+	}
+	if broken := publisher.events[1]; broken.PreviousStreak != 12 || broken.Streak != 1 {
+		t.Errorf("Expected the 12-day streak to have ended, got %+v", broken)
+	}
+
+	publisher.events = nil
+	if _, err := uc.Execute(context.Background(), "user2", "Cici"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := publisher.types(); len(got) != 1 {
+		t.Errorf("Expected only report_accepted for a growing streak, got %v", got)
+	}
+}
+
+func TestReportEvents_ChallengeFinished(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Dedi", Streak: 29, ActivityCount: 29, LastReportDate: time.Now().AddDate(0, 0, -1)},
+	}}
+	publisher := &mockPublisher{}
+	uc := usecase.NewReportActivityUsecase(repo)
+	uc.SetChallenge(domain.Challenge{Days: 30})
+	uc.SetEventPublisher(publisher)
+
+	if _, err := uc.Execute(context.Background(), "user1", "Dedi"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := publisher.types(); len(got) != 2 || got[1] != domain.EventChallengeFinished {
+		t.Fatalf("Expected challenge_finished on the last day, got %v", got)
+	}
+	if finished := publisher.events[1]; finished.ChallengeDays != 30 || finished.ActivityCount != 30 {
+		t.Errorf("Expected the finished 30-day challenge, got %+v", finished)
+	}
+}
+
+func TestReportEvents_NoneForRejectedReport(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Eka", Streak: 3, ActivityCount: 3, LastReportDate: time.Now()},
+	}}
+	publisher := &mockPublisher{}
+	uc := usecase.NewReportActivityUsecase(repo)
+	uc.SetEventPublisher(publisher)
+
+	if _, err := uc.Execute(context.Background(), "user1", "Eka"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(publisher.events) != 0 {
+		t.Errorf("Expected no events for a second report of the day, got %v", publisher.types())
+	}
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
+)
+
+// SpreadsheetRenderer lays the spreadsheet export out as a workbook, e.g.
+// XLSX.
+type SpreadsheetRenderer interface {
+	RenderSpreadsheet(sheet *Spreadsheet) ([]byte, error)
+}
+
+// Spreadsheet is the challenge so far for organizers: the standings, who
+// reported on which day and the activity types.
+type Spreadsheet struct {
+	Title     string
+	Since     time.Time
+	Until     time.Time // last day included
+	Standings []BoardMember
+	Days      []time.Time
+	Members   []SpreadsheetMember // in the order of Standings
+	Types     []TypeTotal
+}
+
+// SpreadsheetMember is a row of the per-day matrix.
+type SpreadsheetMember struct {
+	Name     string
+	Days     []string // for each of Spreadsheet.Days the activities reported, empty when missed
+	Reported int
+}
+
+// TypeTotal sums up the reports of one activity type.
+type TypeTotal struct {
+	Label   string
+	Reports int
+	Members int
+	Km      float64
+	Minutes int
+}
+
+// ExportSpreadsheetUsecase builds the spreadsheet export. Members that
+// admins excluded from the ranking or who use #privat are left out.
+type ExportSpreadsheetUsecase struct {
+	reports    domain.ReportRepository
+	activities domain.ActivityRepository
+	settings   domain.SettingsRepository
+	renderer   SpreadsheetRenderer
+	challenge  domain.Challenge
+	boards     *BoardBuilder
+}
+
+func NewExportSpreadsheetUsecase(reports domain.ReportRepository, activities domain.ActivityRepository, settings domain.SettingsRepository, renderer SpreadsheetRenderer) *ExportSpreadsheetUsecase {
+	return &ExportSpreadsheetUsecase{reports: reports, activities: activities, settings: settings, renderer: renderer}
+}
+
+// SetBoards ranks the standings sheet like #leaderboard.
+func (uc *ExportSpreadsheetUsecase) SetBoards(boards *BoardBuilder) {
+	uc.boards = boards
+}
+
+// SetChallenge limits the per-day matrix to the days of the challenge.
+// Without a start date it covers the whole activity log.
+func (uc *ExportSpreadsheetUsecase) SetChallenge(challenge domain.Challenge) {
+	uc.challenge = challenge
+}
+
+// Spreadsheet collects the export as of now.
+func (uc *ExportSpreadsheetUsecase) Spreadsheet(ctx context.Context, now time.Time) (*Spreadsheet, error) {
+	since, until, err := challengePeriod(ctx, uc.activities, uc.challenge, now)
+	if err != nil {
+		return nil, err
+	}
+	activities, err := rankedActivities(ctx, uc.activities, uc.settings, domain.ActivityFilter{Since: since, Until: until})
+	if err != nil {
+		return nil, err
+	}
+	board, err := boardsOr(uc.boards, uc.reports, uc.settings).Board(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "Rekap Tantangan"
+	if uc.challenge.Days > 0 {
+		title = fmt.Sprintf("Rekap Tantangan %d Hari", uc.challenge.Days)
+	}
+	sheet := &Spreadsheet{
+		Title:     title,
+		Since:     since,
+		Until:     until.AddDate(0, 0, -1),
+		Standings: board.Members,
+	}
+
+	index := make(map[string]int)
+	for day := since; day.Before(until); day = day.AddDate(0, 0, 1) {
+		index[day.Format("2006-01-02")] = len(sheet.Days)
+		sheet.Days = append(sheet.Days, day)
+	}
+
+	// Labels per member and day, in the order they were reported
+	labels := make(map[string][][]string)
+	totals := make(map[string]*TypeTotal)
+	members := make(map[string]map[string]bool)
+	for _, a := range activities {
+		if i, ok := index[a.ReportedAt.In(since.Location()).Format("2006-01-02")]; ok {
+			if labels[a.UserID] == nil {
+				labels[a.UserID] = make([][]string, len(sheet.Days))
+			}
+			labels[a.UserID][i] = append(labels[a.UserID][i], activity.Label(a.ActivityType))
+		}
+
+		total := totals[a.ActivityType]
+		if total == nil {
+			total = &TypeTotal{Label: activity.Label(a.ActivityType)}
+			totals[a.ActivityType] = total
+			members[a.ActivityType] = make(map[string]bool)
+		}
+		total.Reports++
+		total.Km += a.DistanceKm
+		total.Minutes += a.DurationMinutes
+		members[a.ActivityType][a.UserID] = true
+	}
+
+	for _, m := range board.Members {
+		row := SpreadsheetMember{Name: m.Name, Days: make([]string, len(sheet.Days))}
+		for i, day := range labels[m.UserID] {
+			if len(day) > 0 {
+				row.Days[i] = strings.Join(day, ", ")
+				row.Reported++
+			}
+		}
+		sheet.Members = append(sheet.Members, row)
+	}
+
+	// Most reported first, like the recaps
+	for _, t := range countTypes(activities) {
+		total := totals[t.Type]
+		total.Members = len(members[t.Type])
+		sheet.Types = append(sheet.Types, *total)
+	}
+	return sheet, nil
+}
+
+// FileName returns e.g. "rekap-2026-10-31.xlsx".
+func (s *Spreadsheet) FileName() string {
+	return "rekap-" + s.Until.Format("2006-01-02") + ".xlsx"
+}
+
+// XLSX renders the export as of now.
+func (uc *ExportSpreadsheetUsecase) XLSX(ctx context.Context, now time.Time) (*Spreadsheet, []byte, error) {
+	sheet, err := uc.Spreadsheet(ctx, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := uc.renderer.RenderSpreadsheet(sheet)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sheet, data, nil
+}
@@ -0,0 +1,77 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockSpreadsheetRenderer struct {
+	rendered []*usecase.Spreadsheet
+}
+
+func (m *mockSpreadsheetRenderer) RenderSpreadsheet(sheet *usecase.Spreadsheet) ([]byte, error) {
+	m.rendered = append(m.rendered, sheet)
+	return []byte("PK"), nil
+}
+
+// =============================================================================
+// SPREADSHEET EXPORT TESTS
+// =============================================================================
+
+func TestExportSpreadsheet_MatrixAndTypes(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	at := func(day int) time.Time { return start.AddDate(0, 0, day).Add(7 * time.Hour) }
+
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 3, LastReportDate: at(2)},
+		"user2": {UserID: "user2", Name: "Bob", Streak: 1, ActivityCount: 2, LastReportDate: at(2)},
+		"user3": {UserID: "user3", Name: "Cici", Streak: 1, ActivityCount: 1, LastReportDate: at(2)},
+	}}
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", DistanceKm: 5, DurationMinutes: 30, ReportedAt: at(0)},
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", DistanceKm: 3, ReportedAt: at(1)},
+		{UserID: "user1", Name: "Alice", ActivityType: "yoga", ReportedAt: at(2)},
+		{UserID: "user2", Name: "Bob", ActivityType: "sepeda", DistanceKm: 20, ReportedAt: at(0)},
+		{UserID: "user2", Name: "Bob", ActivityType: "lari", DistanceKm: 2, ReportedAt: at(2)},
+		{UserID: "user3", Name: "Cici", ActivityType: "yoga", ReportedAt: at(2)},
+		// Before the challenge
+		{UserID: "user2", Name: "Bob", ActivityType: "lari", DistanceKm: 10, ReportedAt: at(-3)},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{"user3": {UserID: "user3", Private: true}}}
+
+	renderer := &mockSpreadsheetRenderer{}
+	uc := usecase.NewExportSpreadsheetUsecase(repo, activities, settings, renderer)
+	uc.SetChallenge(domain.Challenge{Start: start, Days: 5})
+
+	// Two days into the challenge: only the days so far
+	sheet, data, err := uc.XLSX(context.Background(), at(2))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != "PK" || len(renderer.rendered) != 1 {
+		t.Fatal("Expected the spreadsheet to be rendered")
+	}
+	if len(sheet.Days) != 3 || sheet.FileName() != "rekap-2026-10-03.xlsx" {
+		t.Fatalf("Expected the 3 days so far, got %d days, %s", len(sheet.Days), sheet.FileName())
+	}
+	if len(sheet.Standings) != 2 || len(sheet.Members) != 2 {
+		t.Fatalf("Expected the private member left out, got %+v", sheet.Members)
+	}
+
+	bob := sheet.Members[1]
+	if bob.Name != "Bob" || bob.Reported != 2 || bob.Days[0] != "Sepeda 🚴" || bob.Days[1] != "" {
+		t.Errorf("Expected Bob's days, got %+v", bob)
+	}
+
+	if len(sheet.Types) != 3 {
+		t.Fatalf("Expected 3 activity types, got %+v", sheet.Types)
+	}
+	run := sheet.Types[0]
+	if run.Label != "Lari 🏃" || run.Reports != 3 || run.Members != 2 || run.Km != 10 || run.Minutes != 30 {
+		t.Errorf("Expected running first without the earlier run, got %+v", run)
+	}
+}
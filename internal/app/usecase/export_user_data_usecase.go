@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// UserDataExport is everything the bot stores about a single member, the
+// same data #hapusdata deletes.
+type UserDataExport struct {
+	UserID     string                    `json:"user_id"`
+	ExportedAt time.Time                 `json:"exported_at"`
+	Report     *domain.Report            `json:"report"`
+	Settings   *domain.UserSettings      `json:"settings"`
+	Activities []*domain.Activity        `json:"activities"`
+	Messages   []*domain.ArchivedMessage `json:"messages,omitempty"`
+	Events     []*domain.ReportEvent     `json:"report_events,omitempty"`
+	Identities []*domain.LinkedIdentity  `json:"linked_accounts,omitempty"`
+	Snapshots  []*domain.SnapshotEntry   `json:"leaderboard_snapshots,omitempty"`
+}
+
+type ExportUserDataUsecase struct {
+	repo       domain.ReportRepository
+	activities domain.ActivityRepository
+	settings   domain.SettingsRepository
+	messages   domain.MessageArchiveRepository
+	events     domain.ReportEventRepository
+	identities domain.IdentityRepository
+	snapshots  domain.SnapshotRepository
+}
+
+func NewExportUserDataUsecase(repo domain.ReportRepository, activities domain.ActivityRepository, settings domain.SettingsRepository) *ExportUserDataUsecase {
+	return &ExportUserDataUsecase{repo: repo, activities: activities, settings: settings}
+}
+
+// SetMessageArchive includes the user's archived messages in the export.
+func (uc *ExportUserDataUsecase) SetMessageArchive(messages domain.MessageArchiveRepository) {
+	uc.messages = messages
+}
+
+// SetEventRepository includes the user's report history in the export.
+func (uc *ExportUserDataUsecase) SetEventRepository(events domain.ReportEventRepository) {
+	uc.events = events
+}
+
+// SetIdentities includes the user's linked accounts on other platforms.
+func (uc *ExportUserDataUsecase) SetIdentities(identities domain.IdentityRepository) {
+	uc.identities = identities
+}
+
+// SetSnapshots includes the user's places in past leaderboard snapshots.
+func (uc *ExportUserDataUsecase) SetSnapshots(snapshots domain.SnapshotRepository) {
+	uc.snapshots = snapshots
+}
+
+// Collect gathers the stored data of a user.
+func (uc *ExportUserDataUsecase) Collect(ctx context.Context, userID string) (*UserDataExport, error) {
+	report, err := uc.repo.GetReport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &UserDataExport{
+		UserID:     userID,
+		ExportedAt: time.Now().UTC(),
+		Report:     report,
+		Activities: []*domain.Activity{},
+	}
+
+	if uc.activities != nil {
+		activities, err := uc.activities.GetActivities(ctx, domain.ActivityFilter{UserID: userID})
+		if err != nil {
+			return nil, err
+		}
+		if activities != nil {
+			export.Activities = activities
+		}
+	}
+
+	if uc.messages != nil {
+		if export.Messages, err = uc.messages.GetMessages(ctx, domain.MessageFilter{SenderID: userID}); err != nil {
+			return nil, err
+		}
+	}
+
+	if uc.settings != nil {
+		if export.Settings, err = uc.settings.GetSettings(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if uc.events != nil {
+		if export.Events, err = uc.events.GetEvents(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if uc.identities != nil {
+		if export.Identities, err = uc.identities.ListIdentities(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if uc.snapshots != nil {
+		if export.Snapshots, err = uc.snapshots.GetSnapshots(ctx, domain.SnapshotFilter{UserID: userID}); err != nil {
+			return nil, err
+		}
+	}
+
+	return export, nil
+}
+
+// Execute sends the sender their data as a JSON file (#mydata), or the
+// activity log as CSV with "#mydata csv". The file goes to their personal
+// chat so it is never posted in the group.
+func (uc *ExportUserDataUsecase) Execute(ctx context.Context, userID, name string, args []string) (*Reply, error) {
+	export, err := uc.Collect(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) > 0 && args[0] == "csv" {
+		data, err := activitiesCSV(export.Activities)
+		if err != nil {
+			return nil, err
+		}
+		return &Reply{
+			Text:             "Riwayat laporan " + name + " (CSV) 📄",
+			Document:         data,
+			DocumentName:     "lapor-bot-" + userID + ".csv",
+			DocumentMimeType: "text/csv",
+			Private:          true,
+		}, nil
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &Reply{
+		Text:             "Semua data " + name + " yang tersimpan di bot 📄",
+		Document:         data,
+		DocumentName:     "lapor-bot-" + userID + ".json",
+		DocumentMimeType: "application/json",
+		Private:          true,
+	}, nil
+}
+
+func activitiesCSV(activities []*domain.Activity) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"reported_at", "activity_type", "duration_minutes", "distance_km", "message"})
+	for _, a := range activities {
+		_ = w.Write([]string{
+			a.ReportedAt.UTC().Format(time.RFC3339),
+			a.ActivityType,
+			strconv.Itoa(a.DurationMinutes),
+			strconv.FormatFloat(a.DistanceKm, 'f', -1, 64),
+			a.Message,
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,112 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// DATA EXPORT TESTS
+// =============================================================================
+
+func TestMyData_JSONExport(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 2, ActivityCount: 5, LastReportDate: now},
+		"user2": {UserID: "user2", Name: "Bob", Streak: 1, ActivityCount: 1, LastReportDate: now},
+	}}
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", ActivityType: "lari", DurationMinutes: 30, ReportedAt: now},
+		{UserID: "user2", ActivityType: "gym", ReportedAt: now},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{"user1": {UserID: "user1", Target: 20}}}
+
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetExportUsecase(usecase.NewExportUserDataUsecase(repo, activities, settings))
+
+	reply, err := handleUC.ExecuteReply(context.Background(), "user1", "Alice", "#mydata")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reply.Private {
+		t.Errorf("Export must be sent privately")
+	}
+	if reply.DocumentMimeType != "application/json" {
+		t.Fatalf("Expected JSON document, got '%s'", reply.DocumentMimeType)
+	}
+
+	var export usecase.UserDataExport
+	if err := json.Unmarshal(reply.Document, &export); err != nil {
+		t.Fatalf("Invalid JSON export: %v", err)
+	}
+	if export.Report == nil || export.Report.ActivityCount != 5 {
+		t.Errorf("Expected report in export, got %+v", export.Report)
+	}
+	if len(export.Activities) != 1 || export.Activities[0].UserID != "user1" {
+		t.Errorf("Expected only the sender's activities, got %+v", export.Activities)
+	}
+	if export.Settings == nil || export.Settings.Target != 20 {
+		t.Errorf("Expected settings in export, got %+v", export.Settings)
+	}
+}
+
+func TestMyData_CSVExport(t *testing.T) {
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", ActivityType: "lari", DurationMinutes: 30, DistanceKm: 5, Message: "#lapor lari 5km", ReportedAt: time.Now()},
+	}}
+	uc := usecase.NewExportUserDataUsecase(&mockRepo{reports: make(map[string]*domain.Report)}, activities, nil)
+
+	reply, err := uc.Execute(context.Background(), "user1", "Alice", []string{"csv"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(reply.Document)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected header + 1 row, got %d lines", len(lines))
+	}
+	if !strings.HasSuffix(lines[1], ",lari,30,5,#lapor lari 5km") {
+		t.Errorf("Unexpected CSV row '%s'", lines[1])
+	}
+}
+
+func TestMyData_IncludesReportEvents(t *testing.T) {
+	now := time.Now()
+	events := &mockEventRepo{events: map[string][]*domain.ReportEvent{
+		"user1": {{UserID: "user1", Type: domain.ReportSubmitted, At: now}},
+		"user2": {{UserID: "user2", Type: domain.ReportSubmitted, At: now}},
+	}}
+	uc := usecase.NewExportUserDataUsecase(&mockRepo{reports: make(map[string]*domain.Report)}, nil, nil)
+	uc.SetEventRepository(events)
+
+	export, err := uc.Collect(context.Background(), "user1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(export.Events) != 1 || export.Events[0].UserID != "user1" {
+		t.Errorf("Expected only the sender's report history, got %+v", export.Events)
+	}
+}
+
+func TestMyData_IncludesSnapshots(t *testing.T) {
+	snapshots := &mockSnapshotRepo{days: map[string][]*domain.SnapshotEntry{
+		"2026-03-02": {{Day: "2026-03-02", UserID: "user2", Rank: 1}, {Day: "2026-03-02", UserID: "user1", Rank: 2}},
+		"2026-03-01": {{Day: "2026-03-01", UserID: "user1", Rank: 1}},
+	}}
+	uc := usecase.NewExportUserDataUsecase(&mockRepo{reports: make(map[string]*domain.Report)}, nil, nil)
+	uc.SetSnapshots(snapshots)
+
+	export, err := uc.Collect(context.Background(), "user1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(export.Snapshots) != 2 || export.Snapshots[0].Day != "2026-03-01" || export.Snapshots[1].Rank != 2 {
+		t.Errorf("Expected the sender's places by day, got %+v", export.Snapshots)
+	}
+}
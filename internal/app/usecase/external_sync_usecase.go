@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// ExternalSync is a tool outside the bot that organizers keep the reports
+// in, such as Airtable. A new target only needs these three methods; the
+// batching, retries and change detection are done by ExternalSyncUsecase.
+type ExternalSync interface {
+	// Name is used in logs, e.g. "Airtable".
+	Name() string
+	// AddReports appends one row per counted #lapor.
+	AddReports(ctx context.Context, rows []SyncReport) error
+	// UpdateStandings creates or updates one row per member, matched on
+	// SyncStanding.Member.
+	UpdateStandings(ctx context.Context, rows []SyncStanding) error
+}
+
+// SyncReport is a counted #lapor.
+type SyncReport struct {
+	Member          string // see syncKey
+	Name            string
+	At              time.Time
+	ActivityType    string
+	DurationMinutes int
+	DistanceKm      float64
+	Streak          int
+	Total           int
+}
+
+// SyncStanding is a member's row in the standings.
+type SyncStanding struct {
+	Member string // see syncKey
+	Rank   int
+	Name   string
+	Streak int // 0 once a day was missed
+	Total  int
+	Today  bool
+}
+
+// maxPendingReports caps the rows kept while the target is down; older
+// rows are dropped first.
+const maxPendingReports = 1000
+
+// ExternalSyncUsecase pushes the reports and standings to an ExternalSync.
+// It receives the counted reports as an EventPublisher and sends them in
+// batches on every Sync, together with the standings when they changed.
+// Unranked and #privat members are left out of both.
+type ExternalSyncUsecase struct {
+	target   ExternalSync
+	repo     domain.ReportRepository
+	settings domain.SettingsRepository
+	boards   *BoardBuilder
+
+	mu      sync.Mutex // guards pending, Publish must not wait for a Sync
+	pending []SyncReport
+
+	syncMu        sync.Mutex
+	lastStandings []byte
+}
+
+func NewExternalSyncUsecase(target ExternalSync, repo domain.ReportRepository) *ExternalSyncUsecase {
+	return &ExternalSyncUsecase{target: target, repo: repo}
+}
+
+// SetBoards ranks the synced members like #leaderboard.
+func (uc *ExternalSyncUsecase) SetBoards(boards *BoardBuilder) {
+	uc.boards = boards
+}
+
+// SetSettingsRepository leaves unranked and #privat members out.
+func (uc *ExternalSyncUsecase) SetSettingsRepository(settings domain.SettingsRepository) {
+	uc.settings = settings
+}
+
+// Publish keeps a counted #lapor for the next Sync.
+func (uc *ExternalSyncUsecase) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	if event.Type != domain.EventReportAccepted {
+		return nil
+	}
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.pending = append(uc.pending, SyncReport{
+		Member:          syncKey(event.UserID),
+		Name:            event.Name,
+		At:              event.At,
+		ActivityType:    event.ActivityType,
+		DurationMinutes: event.DurationMinutes,
+		DistanceKm:      event.DistanceKm,
+		Streak:          event.Streak,
+		Total:           event.ActivityCount,
+	})
+	uc.trimPending()
+	return nil
+}
+
+// retry puts rows that failed back before the ones counted since.
+func (uc *ExternalSyncUsecase) retry(rows []SyncReport) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.pending = append(rows, uc.pending...)
+	uc.trimPending()
+}
+
+// trimPending drops the oldest rows over maxPendingReports. Callers hold
+// uc.mu.
+func (uc *ExternalSyncUsecase) trimPending() {
+	if len(uc.pending) > maxPendingReports {
+		log.Printf("%s sync is behind, dropping %d old reports", uc.target.Name(), len(uc.pending)-maxPendingReports)
+		uc.pending = uc.pending[len(uc.pending)-maxPendingReports:]
+	}
+}
+
+// Run syncs every interval until ctx is done.
+func (uc *ExternalSyncUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := uc.Sync(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to sync to %s: %v", uc.target.Name(), err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync sends the reports counted since the last Sync and the standings if
+// they changed. Reports that fail are kept for the next Sync, so a target
+// that failed halfway may get some of them twice.
+func (uc *ExternalSyncUsecase) Sync(ctx context.Context, now time.Time) error {
+	uc.syncMu.Lock()
+	defer uc.syncMu.Unlock()
+
+	unranked, err := unrankedUsers(ctx, uc.settings)
+	if err != nil {
+		return err
+	}
+	hidden := make(map[string]bool, len(unranked))
+	for userID := range unranked {
+		hidden[syncKey(userID)] = true
+	}
+
+	uc.mu.Lock()
+	pending := uc.pending
+	uc.pending = nil
+	uc.mu.Unlock()
+	rows := make([]SyncReport, 0, len(pending))
+	for _, row := range pending {
+		if !hidden[row.Member] {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) > 0 {
+		if err := uc.target.AddReports(ctx, rows); err != nil {
+			uc.retry(rows)
+			return fmt.Errorf("reports: %w", err)
+		}
+	}
+
+	board, err := boardsOr(uc.boards, uc.repo, uc.settings).Board(ctx, now)
+	if err != nil {
+		return err
+	}
+	standings := make([]SyncStanding, 0, len(board.Members))
+	for _, m := range board.Members {
+		standings = append(standings, SyncStanding{Member: syncKey(m.UserID), Rank: m.Rank, Name: m.Name, Streak: m.Streak, Total: m.Total, Today: m.Today})
+	}
+	data, err := json.Marshal(standings)
+	if err != nil {
+		return err
+	}
+	if len(standings) == 0 || string(data) == string(uc.lastStandings) {
+		return nil
+	}
+	if err := uc.target.UpdateStandings(ctx, standings); err != nil {
+		return fmt.Errorf("standings: %w", err)
+	}
+	uc.lastStandings = data
+	return nil
+}
+
+// syncKey identifies a member in the external tool without showing their
+// phone number.
+func syncKey(userID string) string {
+	sum := sha256.Sum256([]byte("lapor-bot/sync:" + userID))
+	return hex.EncodeToString(sum[:6])
+}
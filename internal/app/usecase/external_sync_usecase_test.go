@@ -0,0 +1,135 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockSync struct {
+	reports   []usecase.SyncReport
+	standings [][]usecase.SyncStanding
+	fail      bool
+}
+
+func (m *mockSync) Name() string { return "mock" }
+
+func (m *mockSync) AddReports(ctx context.Context, rows []usecase.SyncReport) error {
+	if m.fail {
+		return errors.New("target down")
+	}
+	m.reports = append(m.reports, rows...)
+	return nil
+}
+
+func (m *mockSync) UpdateStandings(ctx context.Context, rows []usecase.SyncStanding) error {
+	if m.fail {
+		return errors.New("target down")
+	}
+	m.standings = append(m.standings, rows)
+	return nil
+}
+
+// =============================================================================
+// EXTERNAL SYNC TESTS
+// =============================================================================
+
+func TestExternalSync_ReportsAndStandings(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	target := &mockSync{}
+	syncUC := usecase.NewExternalSyncUsecase(target, repo)
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	reportUC.SetEventPublisher(syncUC)
+	ctx := context.Background()
+
+	if _, err := reportUC.ExecuteWithMessage(ctx, "628111", "Alice", "#lapor lari 5km"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := reportUC.Execute(ctx, "628222", "Bob"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(target.reports) != 0 {
+		t.Fatal("Expected the reports kept until the next sync")
+	}
+
+	if err := syncUC.Sync(ctx, time.Now()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(target.reports) != 2 || target.reports[0].Name != "Alice" || target.reports[0].ActivityType != "lari" || target.reports[0].DistanceKm != 5 {
+		t.Fatalf("Expected both reports in order, got %+v", target.reports)
+	}
+	if target.reports[0].Member == "" || target.reports[0].Member == "628111" {
+		t.Errorf("Expected a member key hiding the number, got %q", target.reports[0].Member)
+	}
+	if len(target.standings) != 1 || len(target.standings[0]) != 2 || target.standings[0][0].Rank != 1 || !target.standings[0][0].Today {
+		t.Fatalf("Expected the standings of both members, got %+v", target.standings)
+	}
+	if target.standings[0][0].Member != target.reports[0].Member {
+		t.Errorf("Expected the same key in reports and standings, got %q and %q", target.standings[0][0].Member, target.reports[0].Member)
+	}
+
+	// Nothing changed
+	if err := syncUC.Sync(ctx, time.Now()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(target.reports) != 2 || len(target.standings) != 1 {
+		t.Errorf("Expected nothing sent again, got %d reports and %d standings", len(target.reports), len(target.standings))
+	}
+}
+
+func TestExternalSync_RetriesAfterFailure(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	target := &mockSync{fail: true}
+	syncUC := usecase.NewExternalSyncUsecase(target, repo)
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	reportUC.SetEventPublisher(syncUC)
+	ctx := context.Background()
+
+	if _, err := reportUC.Execute(ctx, "628111", "Alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := syncUC.Sync(ctx, time.Now()); err == nil {
+		t.Fatal("Expected the failure returned")
+	}
+	if _, err := reportUC.Execute(ctx, "628222", "Bob"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	target.fail = false
+	if err := syncUC.Sync(ctx, time.Now()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(target.reports) != 2 || target.reports[0].Name != "Alice" || target.reports[1].Name != "Bob" {
+		t.Errorf("Expected the failed report sent first, got %+v", target.reports)
+	}
+}
+
+func TestExternalSync_LeavesOutPrivateMembers(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{"628222": {UserID: "628222", Private: true}}}
+	target := &mockSync{}
+	syncUC := usecase.NewExternalSyncUsecase(target, repo)
+	syncUC.SetSettingsRepository(settings)
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	reportUC.SetEventPublisher(syncUC)
+	ctx := context.Background()
+
+	for _, id := range []string{"628111", "628222"} {
+		if _, err := reportUC.Execute(ctx, id, id); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := syncUC.Sync(ctx, time.Now()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(target.reports) != 1 || target.reports[0].Name != "628111" {
+		t.Errorf("Expected only the public report, got %+v", target.reports)
+	}
+	if len(target.standings) != 1 || len(target.standings[0]) != 1 {
+		t.Errorf("Expected only the public member in the standings, got %+v", target.standings)
+	}
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
+)
+
+const (
+	// feedDays is how far back the feed goes.
+	feedDays = 30
+	// feedCacheTTL is how long the entries are reused, so feed readers
+	// polling often don't replay every member's history each time.
+	feedCacheTTL = 5 * time.Minute
+)
+
+// feedStreaks are the streaks the feed announces.
+var feedStreaks = []int{7, 14, 30, 60, 100}
+
+// FeedEntry is one entry of the public feed.
+type FeedEntry struct {
+	ID      string // stable, e.g. "recap/2026-10-15"
+	Title   string
+	Text    string
+	Updated time.Time
+}
+
+// FeedUsecase lists the entries of the public feed: a recap of every past
+// day with reports and the milestones members reached, such as a 30-day
+// streak or finishing the challenge. Unranked and private members are left
+// out, like in the recaps.
+type FeedUsecase struct {
+	recap     *GetRecapUsecase
+	events    domain.ReportEventRepository
+	settings  domain.SettingsRepository
+	challenge domain.Challenge
+
+	mu       sync.Mutex
+	entries  []FeedEntry
+	cachedAt time.Time
+}
+
+func NewFeedUsecase(recap *GetRecapUsecase, events domain.ReportEventRepository) *FeedUsecase {
+	return &FeedUsecase{recap: recap, events: events}
+}
+
+// SetSettingsRepository leaves the milestones of unranked and private
+// members out. The recaps follow the recap usecase's own settings.
+func (uc *FeedUsecase) SetSettingsRepository(settings domain.SettingsRepository) {
+	uc.settings = settings
+}
+
+// SetChallenge announces the members who report every day of the
+// challenge.
+func (uc *FeedUsecase) SetChallenge(challenge domain.Challenge) {
+	uc.challenge = challenge
+}
+
+// Entries returns the entries of the last feedDays days until now, newest
+// first. Today's recap is only added once the day is over.
+func (uc *FeedUsecase) Entries(ctx context.Context, now time.Time) ([]FeedEntry, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.entries != nil && now.Sub(uc.cachedAt) < feedCacheTTL && !now.Before(uc.cachedAt) {
+		return uc.entries, nil
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	since := today.AddDate(0, 0, -feedDays)
+
+	recaps, err := uc.recap.Daily(ctx, since, today)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]FeedEntry, 0, len(recaps))
+	for _, r := range recaps {
+		entries = append(entries, recapEntry(r))
+	}
+
+	milestones, err := uc.milestones(ctx, since, now)
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, milestones...)
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Updated.After(entries[j].Updated) })
+	uc.entries, uc.cachedAt = entries, now
+	return entries, nil
+}
+
+// recapEntry is published when the day is over.
+func recapEntry(r *DailyRecap) FeedEntry {
+	sb := strings.Builder{}
+	if r.ChallengeLine != "" {
+		sb.WriteString(r.ChallengeLine + "\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("Total laporan: %d\n", r.Reports))
+	sb.WriteString(fmt.Sprintf("Member aktif: %d\n", r.Members))
+	if r.Km > 0 {
+		sb.WriteString(fmt.Sprintf("Total jarak: %s\n", activity.FormatDistance(r.Km)))
+	}
+	if breakdown := formatTypes(r.Types); breakdown != "" {
+		sb.WriteString("\nBreakdown aktivitas:\n")
+		sb.WriteString(breakdown)
+	}
+	sb.WriteString("\nYang lapor: " + strings.Join(r.Names, ", "))
+
+	return FeedEntry{
+		ID:      "recap/" + r.Day.Format("2006-01-02"),
+		Title:   fmt.Sprintf("Recap Harian %s", r.Day.Format("02-01-2006")),
+		Text:    sb.String(),
+		Updated: r.Day.AddDate(0, 0, 1),
+	}
+}
+
+// milestones replays every member's report history and returns the
+// milestones reached after since and up to now.
+func (uc *FeedUsecase) milestones(ctx context.Context, since, now time.Time) ([]FeedEntry, error) {
+	userIDs, err := uc.events.EventUserIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unranked, err := unrankedUsers(ctx, uc.settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []FeedEntry
+	for _, userID := range userIDs {
+		if unranked[userID] {
+			continue
+		}
+		events, err := uc.events.GetEvents(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		domain.ReplayReport(userID, events, func(e *domain.ReportEvent, report *domain.Report) {
+			if e.At.Before(since) || e.At.After(now) {
+				return
+			}
+			for _, m := range uc.reached(report) {
+				m.ID = fmt.Sprintf("milestone/%d/%s", e.ID, m.ID)
+				m.Updated = e.At
+				entries = append(entries, m)
+			}
+		})
+	}
+	return entries, nil
+}
+
+// reached returns the milestones the report just counted reached, with the
+// kind of milestone as ID.
+func (uc *FeedUsecase) reached(report *domain.Report) []FeedEntry {
+	var entries []FeedEntry
+	if uc.challenge.Days > 0 && report.ActivityCount == uc.challenge.Days {
+		entries = append(entries, FeedEntry{
+			ID:    "challenge",
+			Title: fmt.Sprintf("🏁 %s menuntaskan %d hari tantangan!", report.Name, uc.challenge.Days),
+			Text:  fmt.Sprintf("Selamat %s, %d hari tantangan tuntas! 🏆", report.Name, uc.challenge.Days),
+		})
+	}
+	for _, streak := range feedStreaks {
+		if report.Streak == streak {
+			entries = append(entries, FeedEntry{
+				ID:    "streak",
+				Title: fmt.Sprintf("🔥 %s mencapai streak %d hari!", report.Name, streak),
+				Text:  fmt.Sprintf("%s sudah lapor %d hari berturut-turut, total %d hari. Lanjutkan! 💪", report.Name, streak, report.ActivityCount),
+			})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,138 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockEventRepo struct {
+	events map[string][]*domain.ReportEvent
+}
+
+func (m *mockEventRepo) AppendEvent(ctx context.Context, event *domain.ReportEvent) error {
+	event.ID = int64(len(m.events[event.UserID]) + 1)
+	m.events[event.UserID] = append(m.events[event.UserID], event)
+	return nil
+}
+
+func (m *mockEventRepo) GetEvents(ctx context.Context, userID string) ([]*domain.ReportEvent, error) {
+	return m.events[userID], nil
+}
+
+func (m *mockEventRepo) EventUserIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	for id := range m.events {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (m *mockEventRepo) DeleteEvents(ctx context.Context, userID string) error {
+	delete(m.events, userID)
+	return nil
+}
+
+func (m *mockEventRepo) InitTable(ctx context.Context) error { return nil }
+
+// =============================================================================
+// FEED TESTS
+// =============================================================================
+
+func TestFeed_RecapsAndMilestones(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	start := now.AddDate(0, 0, -7)
+
+	events := &mockEventRepo{events: make(map[string][]*domain.ReportEvent)}
+	activities := &mockActivityRepo{}
+	ctx := context.Background()
+	for _, id := range []string{"user1", "user2"} {
+		name := map[string]string{"user1": "Alice", "user2": "Bob"}[id]
+		// Alice reports every day, Bob every other day
+		for day := 0; day <= 7; day++ {
+			if id == "user2" && day%2 == 1 {
+				continue
+			}
+			at := start.AddDate(0, 0, day).Add(time.Hour)
+			_ = events.AppendEvent(ctx, &domain.ReportEvent{UserID: id, Type: domain.ReportSubmitted, Name: name, At: at})
+			activities.activities = append(activities.activities, &domain.Activity{UserID: id, Name: name, ActivityType: "lari", DistanceKm: 5, ReportedAt: at})
+		}
+	}
+
+	recapUC := usecase.NewGetRecapUsecase(activities)
+	uc := usecase.NewFeedUsecase(recapUC, events)
+	uc.SetChallenge(domain.Challenge{Days: 4})
+
+	entries, err := uc.Entries(ctx, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var recaps, streaks, finished []usecase.FeedEntry
+	for _, e := range entries {
+		switch {
+		case strings.HasPrefix(e.ID, "recap/"):
+			recaps = append(recaps, e)
+		case strings.HasSuffix(e.ID, "/streak"):
+			streaks = append(streaks, e)
+		case strings.HasSuffix(e.ID, "/challenge"):
+			finished = append(finished, e)
+		}
+	}
+
+	// Today is not over yet
+	if len(recaps) != 7 || recaps[0].ID != "recap/2026-10-15" {
+		t.Fatalf("Expected a recap for each past day, newest first, got %+v", recaps)
+	}
+	if !strings.Contains(recaps[len(recaps)-1].Text, "Total laporan: 2") || !strings.Contains(recaps[len(recaps)-1].Text, "Yang lapor: Alice, Bob") {
+		t.Errorf("Expected both members in the first recap, got %q", recaps[len(recaps)-1].Text)
+	}
+	if len(streaks) != 1 || !strings.Contains(streaks[0].Title, "Alice") || !strings.Contains(streaks[0].Title, "7 hari") {
+		t.Errorf("Expected Alice's 7-day streak, got %+v", streaks)
+	}
+	if len(finished) != 2 {
+		t.Errorf("Expected both members finishing the 4-day challenge, got %+v", finished)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Updated.After(entries[i-1].Updated) {
+			t.Fatal("Expected the newest entries first")
+		}
+	}
+}
+
+func TestFeed_LeavesOutPrivateMembers(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	yesterday := now.AddDate(0, 0, -1)
+
+	events := &mockEventRepo{events: make(map[string][]*domain.ReportEvent)}
+	activities := &mockActivityRepo{}
+	ctx := context.Background()
+	for _, id := range []string{"user1", "user2"} {
+		_ = events.AppendEvent(ctx, &domain.ReportEvent{UserID: id, Type: domain.ReportSubmitted, Name: id, At: yesterday})
+		activities.activities = append(activities.activities, &domain.Activity{UserID: id, Name: id, ActivityType: "yoga", ReportedAt: yesterday})
+	}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{"user2": {UserID: "user2", Private: true}}}
+
+	recapUC := usecase.NewGetRecapUsecase(activities)
+	recapUC.SetSettingsRepository(settings)
+	uc := usecase.NewFeedUsecase(recapUC, events)
+	uc.SetSettingsRepository(settings)
+	uc.SetChallenge(domain.Challenge{Days: 1})
+
+	entries, err := uc.Entries(ctx, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected one recap and one milestone, got %+v", entries)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Title+e.Text, "user2") {
+			t.Errorf("Expected the private member left out, got %+v", e)
+		}
+	}
+}
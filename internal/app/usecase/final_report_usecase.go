@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/format"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// FinalReportRenderer lays the final report out as a document, e.g. a PDF.
+type FinalReportRenderer interface {
+	RenderFinalReport(report *FinalReport) ([]byte, error)
+}
+
+// DocumentSender sends a file to a chat given as a JID string.
+type DocumentSender interface {
+	SendDocumentTo(ctx context.Context, chatJID string, data []byte, mimeType, fileName, caption string) error
+}
+
+// FinalReport is the summary of the whole challenge: the totals, the
+// standings, the highlights and a chart per member.
+type FinalReport struct {
+	Title      string // e.g. "Laporan Akhir Tantangan 30 Hari"
+	Since      time.Time
+	Until      time.Time // last day included
+	Reports    int
+	Members    int
+	Km         float64
+	Minutes    int
+	Finished   int // members who reported on every day of the challenge
+	Types      []TypeCount
+	Highlights []Highlight
+	Standings  []BoardMember
+	Charts     []MemberChart
+}
+
+// MemberChart is a member's reported days during the challenge.
+type MemberChart struct {
+	Name     string
+	Reported int // days reported during the challenge
+	Days     int
+	PNG      []byte // format.RenderActivityChart
+}
+
+// FinalReportUsecase builds the final report of the challenge. Members that
+// admins excluded from the ranking or who use #privat are left out.
+type FinalReportUsecase struct {
+	reports    domain.ReportRepository
+	activities domain.ActivityRepository
+	settings   domain.SettingsRepository
+	renderer   FinalReportRenderer
+	challenge  domain.Challenge
+	boards     *BoardBuilder
+}
+
+func NewFinalReportUsecase(reports domain.ReportRepository, activities domain.ActivityRepository, settings domain.SettingsRepository, renderer FinalReportRenderer) *FinalReportUsecase {
+	return &FinalReportUsecase{reports: reports, activities: activities, settings: settings, renderer: renderer}
+}
+
+// SetBoards ranks the final standings like #leaderboard.
+func (uc *FinalReportUsecase) SetBoards(boards *BoardBuilder) {
+	uc.boards = boards
+}
+
+// SetChallenge limits the report to the days of the challenge. Without a
+// start date the report covers the whole activity log.
+func (uc *FinalReportUsecase) SetChallenge(challenge domain.Challenge) {
+	uc.challenge = challenge
+}
+
+// challengePeriod returns the first day of the challenge and the day after
+// the last one covered, which is today while the challenge runs. Without a
+// start date the period begins on the day of the first activity.
+func challengePeriod(ctx context.Context, activities domain.ActivityRepository, challenge domain.Challenge, now time.Time) (since, until time.Time, err error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	until = today.AddDate(0, 0, 1)
+	if !challenge.Start.IsZero() {
+		start := challenge.Start
+		since = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, now.Location())
+		if challenge.Days > 0 {
+			if end := since.AddDate(0, 0, challenge.Days); end.Before(until) {
+				until = end
+			}
+		}
+		return since, until, nil
+	}
+
+	all, err := activities.GetActivities(ctx, domain.ActivityFilter{Until: until})
+	if err != nil || len(all) == 0 {
+		return today, until, err
+	}
+	first := all[0].ReportedAt
+	for _, a := range all {
+		if a.ReportedAt.Before(first) {
+			first = a.ReportedAt
+		}
+	}
+	first = first.In(now.Location())
+	return time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, now.Location()), until, nil
+}
+
+// Report collects the final report as of now.
+func (uc *FinalReportUsecase) Report(ctx context.Context, now time.Time) (*FinalReport, error) {
+	since, until, err := challengePeriod(ctx, uc.activities, uc.challenge, now)
+	if err != nil {
+		return nil, err
+	}
+	activities, err := rankedActivities(ctx, uc.activities, uc.settings, domain.ActivityFilter{Since: since, Until: until})
+	if err != nil {
+		return nil, err
+	}
+	board, err := boardsOr(uc.boards, uc.reports, uc.settings).Board(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "Laporan Akhir Tantangan"
+	if uc.challenge.Days > 0 {
+		title = fmt.Sprintf("Laporan Akhir Tantangan %d Hari", uc.challenge.Days)
+	}
+	report := &FinalReport{
+		Title:      title,
+		Since:      since,
+		Until:      until.AddDate(0, 0, -1),
+		Reports:    len(activities),
+		Members:    countMembers(activities),
+		Km:         totalDistance(activities),
+		Types:      countTypes(activities),
+		Highlights: weekHighlights(activities, board.Leader),
+		Standings:  board.Members,
+	}
+	for _, a := range activities {
+		report.Minutes += a.DurationMinutes
+	}
+
+	days := int(until.Sub(since).Hours()/24 + 0.5)
+	for _, m := range board.Members {
+		chartDays, err := activityDays(ctx, uc.activities, m.UserID, since, days)
+		if err != nil {
+			return nil, err
+		}
+		reported := 0
+		for _, d := range chartDays {
+			if d.Reported {
+				reported++
+			}
+		}
+		if reported == 0 {
+			continue
+		}
+		if reported == days {
+			report.Finished++
+		}
+		png, err := format.RenderActivityChart(chartDays)
+		if err != nil {
+			return nil, err
+		}
+		report.Charts = append(report.Charts, MemberChart{Name: m.Name, Reported: reported, Days: days, PNG: png})
+	}
+	return report, nil
+}
+
+// FileName returns e.g. "laporan-akhir-2026-10-31.pdf".
+func (r *FinalReport) FileName() string {
+	return "laporan-akhir-" + r.Until.Format("2006-01-02") + ".pdf"
+}
+
+// PDF renders the final report as of now.
+func (uc *FinalReportUsecase) PDF(ctx context.Context, now time.Time) (*FinalReport, []byte, error) {
+	report, err := uc.Report(ctx, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := uc.renderer.RenderFinalReport(report)
+	if err != nil {
+		return nil, nil, err
+	}
+	return report, data, nil
+}
+
+// SendFinalReportUsecase posts the final report to the group as a PDF the
+// day after the challenge ends.
+type SendFinalReportUsecase struct {
+	report   *FinalReportUsecase
+	sender   DocumentSender
+	groupJID string
+}
+
+func NewSendFinalReportUsecase(report *FinalReportUsecase, sender DocumentSender, groupJID string) *SendFinalReportUsecase {
+	return &SendFinalReportUsecase{report: report, sender: sender, groupJID: groupJID}
+}
+
+// Execute sends the report when now is the first day after the challenge,
+// and does nothing on other days.
+func (uc *SendFinalReportUsecase) Execute(ctx context.Context, now time.Time) error {
+	challenge := uc.report.challenge
+	if challenge.Days <= 0 || challenge.Day(now) != challenge.Days+1 {
+		return nil
+	}
+	report, data, err := uc.report.PDF(ctx, now)
+	if err != nil {
+		return err
+	}
+	caption := fmt.Sprintf("🏁 Tantangan %d hari selesai! Terima kasih semuanya 🙏\n\n%d laporan dari %d member, %d menuntaskan setiap hari. Klasemen akhir, highlight, dan grafik tiap member ada di PDF ini.",
+		challenge.Days, report.Reports, report.Members, report.Finished)
+	return uc.sender.SendDocumentTo(ctx, uc.groupJID, data, "application/pdf", report.FileName(), caption)
+}
@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockRenderer struct {
+	rendered []*usecase.FinalReport
+}
+
+func (m *mockRenderer) RenderFinalReport(report *usecase.FinalReport) ([]byte, error) {
+	m.rendered = append(m.rendered, report)
+	return []byte("%PDF-1.3"), nil
+}
+
+type sentDocument struct {
+	chatJID, mimeType, fileName, caption string
+	data                                 []byte
+}
+
+type mockDocumentSender struct {
+	sent []sentDocument
+}
+
+func (m *mockDocumentSender) SendDocumentTo(ctx context.Context, chatJID string, data []byte, mimeType, fileName, caption string) error {
+	m.sent = append(m.sent, sentDocument{chatJID, mimeType, fileName, caption, data})
+	return nil
+}
+
+// =============================================================================
+// FINAL REPORT TESTS
+// =============================================================================
+
+func newFinalReportUsecase(renderer *mockRenderer) (*usecase.FinalReportUsecase, domain.Challenge) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	at := func(day int) time.Time { return start.AddDate(0, 0, day).Add(7 * time.Hour) }
+
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 3, LastReportDate: at(2)},
+		"user2": {UserID: "user2", Name: "Bob", Streak: 1, ActivityCount: 2, LastReportDate: at(2)},
+		"user3": {UserID: "user3", Name: "Cici", Streak: 1, ActivityCount: 1, LastReportDate: at(2)},
+	}}
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", DistanceKm: 5, DurationMinutes: 30, ReportedAt: at(0)},
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", DistanceKm: 3, ReportedAt: at(1)},
+		{UserID: "user1", Name: "Alice", ActivityType: "yoga", ReportedAt: at(2)},
+		{UserID: "user2", Name: "Bob", ActivityType: "sepeda", DistanceKm: 20, ReportedAt: at(0)},
+		{UserID: "user2", Name: "Bob", ActivityType: "sepeda", ReportedAt: at(2)},
+		{UserID: "user3", Name: "Cici", ActivityType: "yoga", ReportedAt: at(2)},
+		// Before the challenge
+		{UserID: "user2", Name: "Bob", ActivityType: "lari", DistanceKm: 10, ReportedAt: at(-3)},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{"user3": {UserID: "user3", Private: true}}}
+
+	challenge := domain.Challenge{Start: start, Days: 3}
+	uc := usecase.NewFinalReportUsecase(repo, activities, settings, renderer)
+	uc.SetChallenge(challenge)
+	return uc, challenge
+}
+
+func TestFinalReport_CoversTheChallenge(t *testing.T) {
+	uc, challenge := newFinalReportUsecase(&mockRenderer{})
+
+	report, err := uc.Report(context.Background(), challenge.Start.AddDate(0, 0, 5))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Title != "Laporan Akhir Tantangan 3 Hari" || report.Until.Day() != 3 {
+		t.Errorf("Expected the 3 days of the challenge, got %q until %v", report.Title, report.Until)
+	}
+	if report.Reports != 5 || report.Members != 2 || report.Km != 28 || report.Minutes != 30 {
+		t.Errorf("Expected the totals without the private member and the earlier run, got %+v", report)
+	}
+	if report.Finished != 1 {
+		t.Errorf("Expected Alice to have reported every day, got %d", report.Finished)
+	}
+	if len(report.Standings) != 2 || report.Standings[0].Name != "Alice" {
+		t.Errorf("Expected Alice on top of the standings, got %+v", report.Standings)
+	}
+	if len(report.Charts) != 2 || report.Charts[1].Name != "Bob" || report.Charts[1].Reported != 2 || report.Charts[1].Days != 3 || len(report.Charts[1].PNG) == 0 {
+		t.Errorf("Expected a chart per member, got %+v", report.Charts)
+	}
+}
+
+func TestSendFinalReport_DayAfterTheChallenge(t *testing.T) {
+	renderer := &mockRenderer{}
+	uc, challenge := newFinalReportUsecase(renderer)
+	sender := &mockDocumentSender{}
+	sendUC := usecase.NewSendFinalReportUsecase(uc, sender, "group@g.us")
+	ctx := context.Background()
+
+	for _, day := range []int{2, 4} {
+		if err := sendUC.Execute(ctx, challenge.Start.AddDate(0, 0, day).Add(9*time.Hour)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("Expected nothing sent on the last day or later, got %d", len(sender.sent))
+	}
+
+	if err := sendUC.Execute(ctx, challenge.Start.AddDate(0, 0, 3).Add(9*time.Hour)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("Expected the report sent the day after, got %d", len(sender.sent))
+	}
+	doc := sender.sent[0]
+	if doc.chatJID != "group@g.us" || doc.mimeType != "application/pdf" || doc.fileName != "laporan-akhir-2026-10-03.pdf" || string(doc.data) != "%PDF-1.3" {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/metrics"
+)
+
+type GetBotStatsUsecase struct {
+	metrics     *metrics.Collector
+	isConnected func() bool
+	dbPath      string
+	queues      map[string]func() int
+}
+
+func NewGetBotStatsUsecase(collector *metrics.Collector) *GetBotStatsUsecase {
+	return &GetBotStatsUsecase{metrics: collector, queues: make(map[string]func() int)}
+}
+
+// SetConnectionCheck reports the WhatsApp connection state in #botstats.
+func (uc *GetBotStatsUsecase) SetConnectionCheck(isConnected func() bool) {
+	uc.isConnected = isConnected
+}
+
+// SetDBPath reports the size of the SQLite file in #botstats.
+func (uc *GetBotStatsUsecase) SetDBPath(path string) {
+	uc.dbPath = path
+}
+
+// AddQueue reports the depth of a named queue in #botstats.
+func (uc *GetBotStatsUsecase) AddQueue(name string, depth func() int) {
+	uc.queues[name] = depth
+}
+
+// Execute returns a health snapshot of the bot (#botstats, admin only).
+func (uc *GetBotStatsUsecase) Execute() string {
+	snap := uc.metrics.Snapshot()
+
+	sb := strings.Builder{}
+	sb.WriteString("Status Bot 🤖\n\n")
+	sb.WriteString(fmt.Sprintf("Uptime: %s\n", formatUptime(time.Since(snap.StartedAt))))
+	if uc.isConnected != nil {
+		state := "terputus ❌"
+		if uc.isConnected() {
+			state = "terhubung ✅"
+		}
+		sb.WriteString(fmt.Sprintf("WhatsApp: %s\n", state))
+	}
+	sb.WriteString(fmt.Sprintf("Pesan diproses hari ini: %d\n", snap.MessagesToday))
+	sb.WriteString(fmt.Sprintf("Gagal kirim: %d hari ini, %d sejak start\n", snap.SendFailuresToday, snap.SendFailuresTotal))
+	if uc.dbPath != "" {
+		sb.WriteString(fmt.Sprintf("Ukuran database: %s\n", formatBytes(fileSize(uc.dbPath)+fileSize(uc.dbPath+"-wal"))))
+	}
+
+	names := make([]string, 0, len(uc.queues))
+	for name := range uc.queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("Antrian %s: %d\n", name, uc.queues[name]()))
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// fileSize returns 0 for missing files, e.g. when there is no WAL yet.
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// formatUptime renders "2h 3j 15m" (hari, jam, menit).
+func formatUptime(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dh %dj %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dj %dm", hours, minutes)
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
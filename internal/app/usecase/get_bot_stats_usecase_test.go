@@ -0,0 +1,46 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/metrics"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+// =============================================================================
+// BOT STATS TESTS
+// =============================================================================
+
+func TestBotStats_AdminOnly(t *testing.T) {
+	collector := metrics.New()
+	collector.MessageProcessed()
+	collector.MessageProcessed()
+	collector.SendFailed()
+
+	botStatsUC := usecase.NewGetBotStatsUsecase(collector)
+	botStatsUC.SetConnectionCheck(func() bool { return true })
+	botStatsUC.AddQueue("outbox", func() int { return 4 })
+
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetBotStatsUsecase(botStatsUC)
+	handleUC.SetAdmins([]string{"admin1"})
+
+	result, err := handleUC.Execute(context.Background(), "user1", "Alice", "#botstats")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "khusus admin") {
+		t.Errorf("Expected non-admin to be refused, got '%s'", result)
+	}
+
+	result, err = handleUC.Execute(context.Background(), "admin1", "Admin", "#botstats")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{"Uptime:", "WhatsApp: terhubung", "Pesan diproses hari ini: 2", "Gagal kirim: 1 hari ini", "Antrian outbox: 4"} {
+		if !containsSubstring(result, expected) {
+			t.Errorf("Expected '%s' in bot stats, got '%s'", expected, result)
+		}
+	}
+}
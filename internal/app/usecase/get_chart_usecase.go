@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/format"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const chartDays = 30
+
+type GetChartUsecase struct {
+	activities domain.ActivityRepository
+}
+
+func NewGetChartUsecase(activities domain.ActivityRepository) *GetChartUsecase {
+	return &GetChartUsecase{activities: activities}
+}
+
+// Execute renders the sender's last 30 days as a PNG bar chart (#grafik).
+func (uc *GetChartUsecase) Execute(ctx context.Context, userID, name string) (*Reply, error) {
+	days, err := lastDays(ctx, uc.activities, userID, chartDays)
+	if err != nil {
+		return nil, err
+	}
+
+	reported := 0
+	for _, d := range days {
+		if d.Reported {
+			reported++
+		}
+	}
+	if reported == 0 {
+		return &Reply{Text: fmt.Sprintf("%s belum ada laporan dalam %d hari terakhir.", name, chartDays)}, nil
+	}
+
+	img, err := format.RenderActivityChart(days)
+	if err != nil {
+		return nil, err
+	}
+
+	caption := fmt.Sprintf("Grafik %d hari terakhir %s: %d/%d hari lapor 🔥", chartDays, name, reported, chartDays)
+	return &Reply{Text: caption, Image: img, ImageMimeType: "image/png"}, nil
+}
+
+// lastDays builds one entry per local calendar day for the last n days
+// (oldest first, today last) from the user's activity log.
+func lastDays(ctx context.Context, activities domain.ActivityRepository, userID string, n int) ([]format.ChartDay, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return activityDays(ctx, activities, userID, today.AddDate(0, 0, -(n-1)), n)
+}
+
+// activityDays builds n consecutive local calendar days starting at since,
+// marking the days that appear in the user's activity log.
+func activityDays(ctx context.Context, activities domain.ActivityRepository, userID string, since time.Time, n int) ([]format.ChartDay, error) {
+	entries, err := activities.GetActivities(ctx, domain.ActivityFilter{UserID: userID, Since: since, Until: since.AddDate(0, 0, n)})
+	if err != nil {
+		return nil, err
+	}
+
+	days := make([]format.ChartDay, n)
+	index := make(map[string]int, n)
+	for i := range days {
+		days[i].Date = since.AddDate(0, 0, i)
+		index[days[i].Date.Format("2006-01-02")] = i
+	}
+	for _, a := range entries {
+		if i, ok := index[a.ReportedAt.In(since.Location()).Format("2006-01-02")]; ok {
+			days[i].Reported = true
+			days[i].Minutes += a.DurationMinutes
+		}
+	}
+	return days, nil
+}
@@ -0,0 +1,58 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// CHART TESTS
+// =============================================================================
+
+func TestChart_RendersPNG(t *testing.T) {
+	now := time.Now()
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", ActivityType: "lari", DurationMinutes: 30, ReportedAt: now.AddDate(0, 0, -2)},
+		{UserID: "user1", ActivityType: "gym", ReportedAt: now.AddDate(0, 0, -1)},
+		{UserID: "user1", ActivityType: "gym", ReportedAt: now.AddDate(0, 0, -45)}, // outside window
+		{UserID: "user2", ActivityType: "gym", ReportedAt: now},
+	}}
+
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetChartUsecase(usecase.NewGetChartUsecase(activities))
+
+	reply, err := handleUC.ExecuteReply(context.Background(), "user1", "Alice", "#grafik")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reply.Image == nil || reply.ImageMimeType != "image/png" {
+		t.Fatalf("Expected PNG image reply, got %+v", reply)
+	}
+	if _, err := png.Decode(bytes.NewReader(reply.Image)); err != nil {
+		t.Errorf("Image is not a valid PNG: %v", err)
+	}
+	if !containsSubstring(reply.Text, "2/30 hari lapor") {
+		t.Errorf("Expected caption with 2/30, got '%s'", reply.Text)
+	}
+}
+
+func TestChart_NoReports(t *testing.T) {
+	uc := usecase.NewGetChartUsecase(&mockActivityRepo{})
+
+	reply, err := uc.Execute(context.Background(), "user1", "Alice")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reply.Image != nil {
+		t.Errorf("Expected text-only reply without reports")
+	}
+	if !containsSubstring(reply.Text, "belum ada laporan") {
+		t.Errorf("Expected empty-state message, got '%s'", reply.Text)
+	}
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/format"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
+)
+
+const historyRecentLimit = 5
+
+type GetHistoryUsecase struct {
+	activities domain.ActivityRepository
+}
+
+func NewGetHistoryUsecase(activities domain.ActivityRepository) *GetHistoryUsecase {
+	return &GetHistoryUsecase{activities: activities}
+}
+
+// Execute shows the sender's current month as an emoji heatmap plus their
+// most recent reports (#history). Works without image support.
+func (uc *GetHistoryUsecase) Execute(ctx context.Context, userID, name string) (string, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+
+	days, err := activityDays(ctx, uc.activities, userID, monthStart, daysInMonth)
+	if err != nil {
+		return "", err
+	}
+
+	reported := 0
+	for _, d := range days {
+		if d.Reported {
+			reported++
+		}
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Riwayat %s – %s %d\n\n", name, format.MonthName(now.Month()), now.Year()))
+	sb.WriteString(format.HeatmapHeader + "\n")
+	sb.WriteString(format.EmojiHeatmap(days, today) + "\n\n")
+	sb.WriteString(fmt.Sprintf("%d/%d hari lapor bulan ini\n", reported, now.Day()))
+
+	recent, err := uc.activities.GetActivities(ctx, domain.ActivityFilter{UserID: userID})
+	if err != nil {
+		return "", err
+	}
+	if len(recent) > 0 {
+		sb.WriteString("\nLaporan terakhir:\n")
+		for i := len(recent) - 1; i >= 0 && i >= len(recent)-historyRecentLimit; i-- {
+			sb.WriteString("- " + formatHistoryEntry(recent[i]) + "\n")
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// formatHistoryEntry renders "15-02-2026 Lari 🏃 (30 menit, 5 km)".
+func formatHistoryEntry(a *domain.Activity) string {
+	return a.ReportedAt.Local().Format("02-01-2006") + " " + formatActivity(a)
+}
+
+// formatActivity renders "Lari 🏃 (30 menit, 5 km)".
+func formatActivity(a *domain.Activity) string {
+	line := activity.Label(a.ActivityType)
+
+	var details []string
+	if a.DurationMinutes > 0 {
+		details = append(details, activity.FormatDuration(a.DurationMinutes))
+	}
+	if a.DistanceKm > 0 {
+		details = append(details, activity.FormatDistance(a.DistanceKm))
+	}
+	if len(details) > 0 {
+		line += " (" + strings.Join(details, ", ") + ")"
+	}
+	return line
+}
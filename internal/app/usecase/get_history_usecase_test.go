@@ -0,0 +1,46 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// HISTORY TESTS
+// =============================================================================
+
+func TestHistory_EmojiHeatmap(t *testing.T) {
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 12, 0, 0, 0, now.Location())
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", ActivityType: "gym", ReportedAt: monthStart.AddDate(0, 0, -3)}, // previous month
+		{UserID: "user1", ActivityType: "lari", DurationMinutes: 30, DistanceKm: 5, ReportedAt: monthStart},
+		{UserID: "user2", ActivityType: "gym", ReportedAt: monthStart},
+	}}
+
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetHistoryUsecase(usecase.NewGetHistoryUsecase(activities))
+
+	result, err := handleUC.Execute(context.Background(), "user1", "Alice", "#history")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := strings.Count(result, "🟩"); got != 1 {
+		t.Errorf("Expected 1 reported day in heatmap, got %d: '%s'", got, result)
+	}
+	if got := strings.Count(result, "⬜"); got != now.Day()-1 {
+		t.Errorf("Expected %d missed days in heatmap, got %d: '%s'", now.Day()-1, got, result)
+	}
+	if !containsSubstring(result, "1/") || !containsSubstring(result, "hari lapor bulan ini") {
+		t.Errorf("Expected monthly count, got '%s'", result)
+	}
+	if !containsSubstring(result, "Lari 🏃 (30 menit, 5 km)") {
+		t.Errorf("Expected recent report details, got '%s'", result)
+	}
+}
@@ -3,65 +3,230 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"io"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
 )
 
+// defaultTopSize is how many members #top shows without a number.
+const defaultTopSize = 5
+
+// DefaultLeaderboardLine lays out a member on #leaderboard and #top as the
+// move since yesterday, the current streak and the days reported in total,
+// e.g. "1. ▲ Budi – 12🔥 (total 25)" or "2. = Ani – 0💔 (total 30)".
+const DefaultLeaderboardLine = "{{.Number}} {{with .Trend}}{{.}} {{end}}{{.Name}} – {{.Streak}}{{if .Active}}🔥{{else}}💔{{end}} (total {{.Total}})"
+
+var defaultLeaderboardLine = template.Must(template.New("leaderboard").Parse(DefaultLeaderboardLine))
+
+// LeaderboardLineData is what a leaderboard line template sees.
+type LeaderboardLineData struct {
+	Rank   int
+	Number string // the rank as the group's style writes it: "1.", "01." or "🥇"
+	Name   string
+	Streak int  // current streak, 0 once a day was missed
+	Total  int  // days reported in total
+	Active bool // reported today or yesterday, so the streak is alive
+	Today  bool // reported today
+	// "▲", "▼" or "=" against the rank in yesterday's snapshot, empty
+	// without one
+	Trend string
+	Moved int // places gained since yesterday, negative when dropped
+}
+
+// Rank-change marks on a leaderboard line.
+const (
+	trendUp   = "▲"
+	trendDown = "▼"
+	trendSame = "="
+)
+
+// leaderboardDivider separates the active members from the lapsed ones when
+// a group turned the divider on.
+const leaderboardDivider = "┈┈┈┈┈ 💔 streak putus ┈┈┈┈┈"
+
+var podium = []string{"🥇", "🥈", "🥉"}
+
+// LeaderboardStyle holds the extras a group turned on for #leaderboard and
+// #top with "#admin style". The zero value is the plain list.
+type LeaderboardStyle struct {
+	Podium  bool // 🥇🥈🥉 instead of the rank for the top three
+	Divider bool // the active members first, then a divider and the lapsed ones
+	Aligned bool // ranks padded with zeros to the same width, e.g. "07." above "12."
+}
+
+// leaderboardStyleNames are the names of the extras in "#admin style" and
+// domain.Group.LeaderboardStyle.
+var leaderboardStyleNames = []string{"podium", "pemisah", "rata"}
+
+// ParseLeaderboardStyle reads a comma-separated list of extras, e.g.
+// "podium,pemisah". Unknown names are ignored.
+func ParseLeaderboardStyle(s string) LeaderboardStyle {
+	var style LeaderboardStyle
+	for _, name := range strings.Split(s, ",") {
+		style.set(strings.TrimSpace(name), true)
+	}
+	return style
+}
+
+// set turns the extra called name on or off and reports whether the name
+// is known.
+func (s *LeaderboardStyle) set(name string, on bool) bool {
+	switch name {
+	case "podium":
+		s.Podium = on
+	case "pemisah":
+		s.Divider = on
+	case "rata":
+		s.Aligned = on
+	default:
+		return false
+	}
+	return true
+}
+
+// String returns the extras that are on, e.g. "podium,rata".
+func (s LeaderboardStyle) String() string {
+	var names []string
+	for i, on := range []bool{s.Podium, s.Divider, s.Aligned} {
+		if on {
+			names = append(names, leaderboardStyleNames[i])
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// number writes a rank in the style, width being the digits of the last
+// rank shown.
+func (s LeaderboardStyle) number(rank, width int) string {
+	if s.Podium && rank <= len(podium) {
+		return podium[rank-1]
+	}
+	if s.Aligned {
+		return fmt.Sprintf("%0*d.", width, rank)
+	}
+	return fmt.Sprintf("%d.", rank)
+}
+
 type GetLeaderboardUsecase struct {
-	repo domain.ReportRepository
+	repo       domain.ReportRepository
+	activities domain.ActivityRepository
+	settings   domain.SettingsRepository
+	snapshots  domain.SnapshotRepository
+	topSize    int
+	line       *template.Template
+	boards     *BoardBuilder
 }
 
 func NewGetLeaderboardUsecase(repo domain.ReportRepository) *GetLeaderboardUsecase {
 	return &GetLeaderboardUsecase{repo: repo}
 }
 
+// SetBoards sets how #leaderboard ranks members, see BoardBuilder.
+func (uc *GetLeaderboardUsecase) SetBoards(boards *BoardBuilder) {
+	uc.boards = boards
+}
+
+// SetActivityRepository enables leaderboards computed from the activity log,
+// such as ExecuteByType.
+func (uc *GetLeaderboardUsecase) SetActivityRepository(activities domain.ActivityRepository) {
+	uc.activities = activities
+}
+
+// SetSettingsRepository leaves members that admins excluded from the
+// ranking out of every leaderboard.
+func (uc *GetLeaderboardUsecase) SetSettingsRepository(settings domain.SettingsRepository) {
+	uc.settings = settings
+}
+
+// SetSnapshots marks every line with the move since yesterday's snapshot.
+func (uc *GetLeaderboardUsecase) SetSnapshots(snapshots domain.SnapshotRepository) {
+	uc.snapshots = snapshots
+}
+
+// SetLineTemplate replaces DefaultLeaderboardLine with a text/template that
+// sees LeaderboardLineData, e.g. "{{.Rank}}. {{.Name}} ({{.Total}} hari)".
+func (uc *GetLeaderboardUsecase) SetLineTemplate(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("leaderboard line is empty")
+	}
+	line, err := template.New("leaderboard").Parse(text)
+	if err != nil {
+		return err
+	}
+	// Catch unknown fields now rather than on the first #leaderboard
+	if err := line.Execute(io.Discard, LeaderboardLineData{Rank: 1, Number: "1.", Name: "Budi", Streak: 1, Total: 1, Active: true, Trend: trendUp, Moved: 1}); err != nil {
+		return err
+	}
+	uc.line = line
+	return nil
+}
+
+// SetTopSize sets how many members #top shows without a number.
+func (uc *GetLeaderboardUsecase) SetTopSize(n int) {
+	uc.topSize = n
+}
+
 func (uc *GetLeaderboardUsecase) Execute(ctx context.Context) (string, error) {
-	reports, err := uc.repo.GetAllReports(ctx)
+	return uc.ExecuteStyled(ctx, LeaderboardStyle{})
+}
+
+// ExecuteStyled is Execute with the extras of a group.
+func (uc *GetLeaderboardUsecase) ExecuteStyled(ctx context.Context, style LeaderboardStyle) (string, error) {
+	all, err := uc.repo.GetAllReports(ctx)
 	if err != nil {
 		return "", err
 	}
+	return uc.render(ctx, all, style)
+}
+
+// ExecuteForMembers is ExecuteStyled ranking only userIDs, e.g. the
+// participants of one group.
+func (uc *GetLeaderboardUsecase) ExecuteForMembers(ctx context.Context, userIDs []string, style LeaderboardStyle) (string, error) {
+	all, err := uc.repo.GetAllReports(ctx)
+	if err != nil {
+		return "", err
+	}
+	wanted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+	members := make([]*domain.Report, 0, len(all))
+	for _, r := range all {
+		if wanted[r.UserID] {
+			members = append(members, r)
+		}
+	}
+	return uc.render(ctx, members, style)
+}
 
+func (uc *GetLeaderboardUsecase) render(ctx context.Context, all []*domain.Report, style LeaderboardStyle) (string, error) {
 	now := time.Now()
-	// Global Challenge Day Calculation (Optional: Fix a start date or assume max streak represents it?
-	// The prompt says "Day 37 (06-02-2026)".
-	// Let's use the current Max Streak or a fixed start date if provided.
-	// For now, let's look at the highest streak in the DB to infer "Day X" or just use the current highest streak as the "Day".
-
-	// Logic for "Keep the streak" vs "Lose the streak":
-	// Keep streak: Reported Today OR Reported Yesterday (still have time to report today).
-	// Lose streak: Last report < Yesterday.
-	// New submission: Reported Today AND Streak == 1 (and maybe created today?).
-
-	// Sort all reports by ActivityCount (total days) descending
-	sort.Slice(reports, func(i, j int) bool {
-		return reports[i].ActivityCount > reports[j].ActivityCount
-	})
+	// Ranked by total days, not by streak
+	board, err := boardsOr(uc.boards, uc.repo, uc.settings).Rank(ctx, all, now)
+	if err != nil {
+		return "", err
+	}
 
-	// Count active vs lost for recap
+	// Count active vs lost for recap: a streak is alive while the member
+	// reported today or yesterday
 	activeCount := 0
-	lostCount := 0
-	for _, r := range reports {
-		// Active if streak equals activity_count (never lost streak)
-		if r.Streak == r.ActivityCount {
+	maxDay := 0
+	for _, m := range board.Members {
+		if m.Streak > 0 {
 			activeCount++
-		} else {
-			lostCount++
 		}
-	}
-
-	// Header
-	// Use max activity count to represent the current "Day" of the challenge
-	maxDay := 0
-	if len(reports) > 0 {
-		for _, r := range reports {
-			if r.ActivityCount > maxDay {
-				maxDay = r.ActivityCount
-			}
+		// Use max activity count to represent the current "Day" of the challenge
+		if m.Total > maxDay {
+			maxDay = m.Total
 		}
 	}
+	lostCount := len(board.Members) - activeCount
 
 	sb := strings.Builder{}
 	dateStr := now.Format("02-01-2006")
@@ -73,17 +238,384 @@ func (uc *GetLeaderboardUsecase) Execute(ctx context.Context) (string, error) {
 	sb.WriteString(fmt.Sprintf("%d lose the streak 💔\n", lostCount))
 	sb.WriteString("\nUpdate klasemen sementara:\n")
 
-	// Single unified ranking by ActivityCount
-	for rank, r := range reports {
-		// Active if streak equals activity_count (never lost streak)
-		if r.Streak == r.ActivityCount {
-			sb.WriteString(fmt.Sprintf("%d. %s - %d days 🔥\n", rank+1, r.Name, r.ActivityCount))
-		} else {
-			sb.WriteString(fmt.Sprintf("%d. %s - %d days 💔\n", rank+1, r.Name, r.ActivityCount))
-		}
+	previous, err := uc.previousRanks(ctx, now)
+	if err != nil {
+		return "", err
+	}
+	if err := uc.writeMembers(&sb, board.Members, previous, style); err != nil {
+		return "", err
 	}
 
 	sb.WriteString("\nYang udah keringetan langsung update/posting aja nanti dimasukkin klasemen 💪\n\nSemangat🔥")
 
 	return sb.String(), nil
 }
+
+// ExecuteTop shows only the first n members of #leaderboard, and how many
+// reported today, for a quick check during the day ("#top 5"). n <= 0 uses
+// the size set with SetTopSize.
+func (uc *GetLeaderboardUsecase) ExecuteTop(ctx context.Context, n int, style LeaderboardStyle) (string, error) {
+	if n <= 0 {
+		n = uc.topSize
+	}
+	if n <= 0 {
+		n = defaultTopSize
+	}
+
+	now := time.Now()
+	board, err := boardsOr(uc.boards, uc.repo, uc.settings).Board(ctx, now)
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("🏆 Top %d Klasemen (%s)\n\n", n, now.Format("02-01-2006")))
+	if len(board.Members) == 0 {
+		sb.WriteString("Belum ada yang lapor.")
+		return sb.String(), nil
+	}
+	top := board.Members
+	if len(top) > n {
+		top = top[:n]
+	}
+	previous, err := uc.previousRanks(ctx, now)
+	if err != nil {
+		return "", err
+	}
+	if err := uc.writeMembers(&sb, top, previous, style); err != nil {
+		return "", err
+	}
+	sb.WriteString(fmt.Sprintf("\nSudah lapor hari ini: %d dari %d member", board.ReportedToday, len(board.Members)))
+
+	return sb.String(), nil
+}
+
+// ExecuteOn shows the standings at the end of a past day from its snapshot
+// ("#leaderboard 2026-02-01"), with the marks against the day before.
+// Today is the live board.
+func (uc *GetLeaderboardUsecase) ExecuteOn(ctx context.Context, day time.Time, style LeaderboardStyle) (string, error) {
+	now := time.Now()
+	date := day.Format("2006-01-02")
+	switch {
+	case date == now.Format("2006-01-02"):
+		return uc.ExecuteStyled(ctx, style)
+	case day.After(now):
+		return fmt.Sprintf("Tanggal %s belum lewat.", day.Format("02-01-2006")), nil
+	case uc.snapshots == nil:
+		return "Riwayat klasemen belum tersedia.", nil
+	}
+
+	entries, err := uc.snapshots.GetSnapshot(ctx, date)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return fmt.Sprintf("Tidak ada riwayat klasemen untuk %s. Klasemen disimpan setiap malam.", day.Format("02-01-2006")), nil
+	}
+	members := make([]BoardMember, 0, len(entries))
+	reported := 0
+	for _, e := range entries {
+		members = append(members, BoardMember{UserID: e.UserID, Rank: e.Rank, Name: e.Name, Streak: e.Streak, Total: e.Total, Today: e.Reported})
+		if e.Reported {
+			reported++
+		}
+	}
+	previous, err := uc.previousRanks(ctx, day)
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("📜 Klasemen %s\n\n", day.Format("02-01-2006")))
+	if err := uc.writeMembers(&sb, members, previous, style); err != nil {
+		return "", err
+	}
+	sb.WriteString(fmt.Sprintf("\nLapor hari itu: %d dari %d member", reported, len(members)))
+
+	return sb.String(), nil
+}
+
+// previousRanks returns the ranks in the snapshot of the day before now,
+// none without snapshots.
+func (uc *GetLeaderboardUsecase) previousRanks(ctx context.Context, now time.Time) (map[string]int, error) {
+	if uc.snapshots == nil {
+		return nil, nil
+	}
+	entries, err := uc.snapshots.GetSnapshot(ctx, now.AddDate(0, 0, -1).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	ranks := make(map[string]int, len(entries))
+	for _, e := range entries {
+		ranks[e.UserID] = e.Rank
+	}
+	return ranks, nil
+}
+
+// trend compares a rank with the one in previous. Members who were not on
+// the board yesterday get no mark.
+func trend(userID string, rank int, previous map[string]int) (string, int) {
+	before, ok := previous[userID]
+	switch {
+	case !ok:
+		return "", 0
+	case rank < before:
+		return trendUp, before - rank
+	case rank > before:
+		return trendDown, before - rank
+	}
+	return trendSame, 0
+}
+
+// writeMembers writes the members of #leaderboard or #top, in rank order,
+// with the line template and the extras of style. previous holds the ranks
+// of yesterday for the trend marks.
+func (uc *GetLeaderboardUsecase) writeMembers(sb *strings.Builder, members []BoardMember, previous map[string]int, style LeaderboardStyle) error {
+	if len(members) == 0 {
+		return nil
+	}
+	width := len(strconv.Itoa(members[len(members)-1].Rank))
+
+	active := len(members)
+	if style.Divider {
+		// Keep the ranks, the lapsed just move below the divider
+		var alive, lapsed []BoardMember
+		for _, m := range members {
+			if m.Streak > 0 {
+				alive = append(alive, m)
+			} else {
+				lapsed = append(lapsed, m)
+			}
+		}
+		members, active = append(alive, lapsed...), len(alive)
+	}
+
+	line := uc.line
+	if line == nil {
+		line = defaultLeaderboardLine
+	}
+	for i, m := range members {
+		if i == active && i > 0 {
+			sb.WriteString(leaderboardDivider + "\n")
+		}
+		data := LeaderboardLineData{Rank: m.Rank, Number: style.number(m.Rank, width), Name: m.Name, Streak: m.Streak, Total: m.Total, Active: m.Streak > 0, Today: m.Today}
+		data.Trend, data.Moved = trend(m.UserID, m.Rank, previous)
+		if err := line.Execute(sb, data); err != nil {
+			return fmt.Errorf("leaderboard line: %w", err)
+		}
+		sb.WriteString("\n")
+	}
+	return nil
+}
+
+// ExecuteByType ranks members by how many days they reported the given
+// activity type (e.g. "#leaderboard lari").
+func (uc *GetLeaderboardUsecase) ExecuteByType(ctx context.Context, activityType string) (string, error) {
+	if uc.activities == nil {
+		return "Leaderboard per jenis aktivitas belum tersedia.", nil
+	}
+
+	activities, err := rankedActivities(ctx, uc.activities, uc.settings, domain.ActivityFilter{ActivityType: activityType})
+	if err != nil {
+		return "", err
+	}
+
+	ranking := rankActivities(activities)
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Leaderboard %s (%s)\n\n", activity.Label(activityType), time.Now().Format("02-01-2006")))
+	if len(ranking) == 0 {
+		sb.WriteString("Belum ada laporan untuk aktivitas ini.")
+		return sb.String(), nil
+	}
+	for rank, e := range ranking {
+		sb.WriteString(fmt.Sprintf("%d. %s - %d days\n", rank+1, e.Name, e.Count))
+	}
+
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// ExecuteByDuration ranks members by total logged minutes
+// (e.g. "#leaderboard durasi"). Reports without a duration count as zero.
+func (uc *GetLeaderboardUsecase) ExecuteByDuration(ctx context.Context) (string, error) {
+	if uc.activities == nil {
+		return "Leaderboard durasi belum tersedia.", nil
+	}
+
+	activities, err := rankedActivities(ctx, uc.activities, uc.settings, domain.ActivityFilter{})
+	if err != nil {
+		return "", err
+	}
+
+	ranking := rankActivities(activities)
+	totals := make(map[string]int)
+	for _, a := range activities {
+		totals[a.UserID] += a.DurationMinutes
+	}
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return totals[ranking[i].UserID] > totals[ranking[j].UserID]
+	})
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Leaderboard Total Durasi ⏱️ (%s)\n\n", time.Now().Format("02-01-2006")))
+	rank := 0
+	for _, e := range ranking {
+		if totals[e.UserID] == 0 {
+			continue
+		}
+		rank++
+		sb.WriteString(fmt.Sprintf("%d. %s - %s\n", rank, e.Name, activity.FormatDuration(totals[e.UserID])))
+	}
+	if rank == 0 {
+		sb.WriteString("Belum ada laporan dengan durasi. Contoh: #lapor lari 30m")
+	}
+
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// ExecuteByPeriod ranks members by days reported within [since, until)
+// (e.g. "#leaderboard minggu ini", "#leaderboard 2026-03-01..2026-03-07").
+func (uc *GetLeaderboardUsecase) ExecuteByPeriod(ctx context.Context, since, until time.Time) (string, error) {
+	if uc.activities == nil {
+		return "Leaderboard per periode belum tersedia.", nil
+	}
+
+	activities, err := rankedActivities(ctx, uc.activities, uc.settings, domain.ActivityFilter{Since: since, Until: until})
+	if err != nil {
+		return "", err
+	}
+
+	ranking := rankActivities(activities)
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Leaderboard %s – %s 📅\n\n", since.Format("02-01-2006"), until.AddDate(0, 0, -1).Format("02-01-2006")))
+	if len(ranking) == 0 {
+		sb.WriteString("Belum ada laporan di periode ini.")
+		return sb.String(), nil
+	}
+	for rank, e := range ranking {
+		sb.WriteString(fmt.Sprintf("%d. %s - %d days\n", rank+1, e.Name, e.Count))
+	}
+
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// parsePeriod understands "minggu ini", "bulan ini", and
+// "YYYY-MM-DD..YYYY-MM-DD" (both days included). It returns a half-open
+// range of local days.
+func parsePeriod(args []string, now time.Time) (since, until time.Time, ok bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.Join(args, " ") {
+	case "minggu ini":
+		return startOfWeek(now), today.AddDate(0, 0, 1), true
+	case "bulan ini":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), today.AddDate(0, 0, 1), true
+	}
+
+	if len(args) != 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	from, to, found := strings.Cut(args[0], "..")
+	if !found {
+		return time.Time{}, time.Time{}, false
+	}
+	start, err := time.ParseInLocation("2006-01-02", from, now.Location())
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	end, err := time.ParseInLocation("2006-01-02", to, now.Location())
+	if err != nil || end.Before(start) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end.AddDate(0, 0, 1), true
+}
+
+// unrankedUsers returns the members admins left out of the ranking, none
+// without a settings repository.
+func unrankedUsers(ctx context.Context, settings domain.SettingsRepository) (map[string]bool, error) {
+	if settings == nil {
+		return nil, nil
+	}
+	list, err := settings.GetUnrankedSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unranked := make(map[string]bool, len(list))
+	for _, s := range list {
+		unranked[s.UserID] = true
+	}
+	return unranked, nil
+}
+
+// privateUsers returns the members in #privat mode, none without a settings
+// repository.
+func privateUsers(ctx context.Context, settings domain.SettingsRepository) (map[string]bool, error) {
+	if settings == nil {
+		return nil, nil
+	}
+	list, err := settings.GetUnrankedSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	private := make(map[string]bool)
+	for _, s := range list {
+		if s.Private {
+			private[s.UserID] = true
+		}
+	}
+	return private, nil
+}
+
+// rankedActivities returns the activities matching filter, without those of
+// unranked members.
+func rankedActivities(ctx context.Context, activities domain.ActivityRepository, settings domain.SettingsRepository, filter domain.ActivityFilter) ([]*domain.Activity, error) {
+	all, err := activities.GetActivities(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	unranked, err := unrankedUsers(ctx, settings)
+	if err != nil || len(unranked) == 0 {
+		return all, err
+	}
+	ranked := make([]*domain.Activity, 0, len(all))
+	for _, a := range all {
+		if !unranked[a.UserID] {
+			ranked = append(ranked, a)
+		}
+	}
+	return ranked, nil
+}
+
+type activityRankEntry struct {
+	UserID string
+	Name   string
+	Count  int
+}
+
+// rankActivities counts the distinct local days each user logged, most
+// active first, so two reports on one day count once. The latest logged
+// name is used for display.
+func rankActivities(activities []*domain.Activity) []activityRankEntry {
+	index := make(map[string]int)
+	seen := make(map[string]bool)
+	var ranking []activityRankEntry
+	for _, a := range activities {
+		i, ok := index[a.UserID]
+		if !ok {
+			i = len(ranking)
+			index[a.UserID] = i
+			ranking = append(ranking, activityRankEntry{UserID: a.UserID})
+		}
+		ranking[i].Name = a.Name
+		day := a.UserID + "|" + a.ReportedAt.In(time.Local).Format("2006-01-02")
+		if !seen[day] {
+			seen[day] = true
+			ranking[i].Count++
+		}
+	}
+
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return ranking[i].Count > ranking[j].Count
+	})
+	return ranking
+}
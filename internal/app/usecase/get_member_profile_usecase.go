@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/format"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const profileRecentLimit = 10
+
+// AvatarGateway looks up a member's WhatsApp profile picture.
+type AvatarGateway interface {
+	// AvatarURL returns "" when the member has no (visible) picture.
+	AvatarURL(ctx context.Context, userID string) (string, error)
+}
+
+// MemberProfile is everything the admin API shows about one member.
+type MemberProfile struct {
+	UserID         string             `json:"user_id"`
+	Name           string             `json:"name"`
+	AvatarURL      string             `json:"avatar_url,omitempty"`
+	Streak         int                `json:"streak"`
+	ActivityCount  int                `json:"activity_count"`
+	LastReportDate time.Time          `json:"last_report_date"`
+	Unranked       bool               `json:"unranked"` // Left out of leaderboards and recaps
+	Chart          []ProfileDay       `json:"chart"`    // Last 30 days, oldest first
+	Calendar       []ProfileDay       `json:"calendar"` // Current month
+	Recent         []*domain.Activity `json:"recent"`   // Newest first
+}
+
+// ProfileDay is one calendar day of a member's activity.
+type ProfileDay struct {
+	Date     string `json:"date"` // YYYY-MM-DD
+	Reported bool   `json:"reported"`
+	Minutes  int    `json:"minutes"`
+}
+
+// MemberUpdate holds the fields an admin may correct. Nil fields are left
+// unchanged.
+type MemberUpdate struct {
+	Name          *string `json:"name"`
+	Streak        *int    `json:"streak"`
+	ActivityCount *int    `json:"activity_count"`
+	Unranked      *bool   `json:"unranked"`
+}
+
+type GetMemberProfileUsecase struct {
+	repo       domain.ReportRepository
+	activities domain.ActivityRepository
+	events     domain.ReportEventRepository
+	tx         domain.Transactor
+	avatars    AvatarGateway
+	settings   domain.SettingsRepository
+}
+
+func NewGetMemberProfileUsecase(repo domain.ReportRepository, activities domain.ActivityRepository) *GetMemberProfileUsecase {
+	return &GetMemberProfileUsecase{repo: repo, activities: activities}
+}
+
+// SetEventRepository records admin corrections in the report event stream.
+func (uc *GetMemberProfileUsecase) SetEventRepository(events domain.ReportEventRepository) {
+	uc.events = events
+}
+
+// SetTransactor writes a correction and its event together.
+func (uc *GetMemberProfileUsecase) SetTransactor(tx domain.Transactor) {
+	uc.tx = tx
+}
+
+// SetSettingsRepository lets admins leave a member out of the ranking.
+func (uc *GetMemberProfileUsecase) SetSettingsRepository(settings domain.SettingsRepository) {
+	uc.settings = settings
+}
+
+// SetAvatarGateway adds profile pictures to the profile.
+func (uc *GetMemberProfileUsecase) SetAvatarGateway(avatars AvatarGateway) {
+	uc.avatars = avatars
+}
+
+// Get returns the member's profile, or nil if they never reported.
+func (uc *GetMemberProfileUsecase) Get(ctx context.Context, userID string) (*MemberProfile, error) {
+	report, err := uc.repo.GetReport(ctx, userID)
+	if err != nil || report == nil {
+		return nil, err
+	}
+
+	chart, err := lastDays(ctx, uc.activities, userID, chartDays)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	calendar, err := activityDays(ctx, uc.activities, userID, monthStart, monthStart.AddDate(0, 1, -1).Day())
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := uc.activities.GetActivities(ctx, domain.ActivityFilter{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	recent := make([]*domain.Activity, 0, profileRecentLimit)
+	for i := len(all) - 1; i >= 0 && len(recent) < profileRecentLimit; i-- {
+		recent = append(recent, all[i])
+	}
+
+	profile := &MemberProfile{
+		UserID:         report.UserID,
+		Name:           report.Name,
+		Streak:         report.Streak,
+		ActivityCount:  report.ActivityCount,
+		LastReportDate: report.LastReportDate,
+		Chart:          toProfileDays(chart),
+		Calendar:       toProfileDays(calendar),
+		Recent:         recent,
+	}
+
+	if uc.settings != nil {
+		settings, err := uc.settings.GetSettings(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		profile.Unranked = settings != nil && settings.Unranked
+	}
+
+	if uc.avatars != nil {
+		// A missing avatar should not break the profile
+		if profile.AvatarURL, err = uc.avatars.AvatarURL(ctx, userID); err != nil {
+			log.Printf("Failed to get avatar of %s: %v", userID, err)
+		}
+	}
+	return profile, nil
+}
+
+// Chart renders the last 30 days as the same PNG #grafik sends.
+func (uc *GetMemberProfileUsecase) Chart(ctx context.Context, userID string) ([]byte, error) {
+	days, err := lastDays(ctx, uc.activities, userID, chartDays)
+	if err != nil {
+		return nil, err
+	}
+	return format.RenderActivityChart(days)
+}
+
+// Update applies an admin correction to the member's report. Returns nil if
+// the member never reported; Unranked is stored anyway, so a member can be
+// left out of the ranking before their first report.
+func (uc *GetMemberProfileUsecase) Update(ctx context.Context, userID string, update MemberUpdate) (*domain.Report, error) {
+	var report *domain.Report
+	err := withTx(ctx, uc.tx, func(ctx context.Context) error {
+		var err error
+		report, err = uc.repo.GetReport(ctx, userID)
+		if err != nil || report == nil {
+			return err
+		}
+
+		if update.Name != nil {
+			report.Name = *update.Name
+		}
+		if update.Streak != nil {
+			report.Streak = *update.Streak
+		}
+		if update.ActivityCount != nil {
+			report.ActivityCount = *update.ActivityCount
+		}
+		if err := uc.repo.UpsertReport(ctx, report); err != nil {
+			return err
+		}
+		return recordAdjusted(ctx, uc.events, report, "admin")
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.setUnranked(ctx, userID, update.Unranked); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// setUnranked stores whether the member is left out of the ranking, when
+// the update sets it.
+func (uc *GetMemberProfileUsecase) setUnranked(ctx context.Context, userID string, unranked *bool) error {
+	if unranked == nil || uc.settings == nil {
+		return nil
+	}
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if settings == nil {
+		settings = &domain.UserSettings{UserID: userID}
+	}
+	settings.Unranked = *unranked
+	return uc.settings.SaveSettings(ctx, settings)
+}
+
+func toProfileDays(days []format.ChartDay) []ProfileDay {
+	out := make([]ProfileDay, len(days))
+	for i, d := range days {
+		out[i] = ProfileDay{Date: d.Date.Format("2006-01-02"), Reported: d.Reported, Minutes: d.Minutes}
+	}
+	return out
+}
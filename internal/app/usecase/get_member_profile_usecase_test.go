@@ -0,0 +1,79 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// mockAvatars returns a fixed URL, or an error for unknown users
+type mockAvatars struct {
+	urls map[string]string
+}
+
+func (m *mockAvatars) AvatarURL(ctx context.Context, userID string) (string, error) {
+	if url, ok := m.urls[userID]; ok {
+		return url, nil
+	}
+	return "", errors.New("not reachable")
+}
+
+func TestMemberProfile_GetAndUpdate(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 2, ActivityCount: 3, LastReportDate: now},
+		"user2": {UserID: "user2", Name: "Bob", Streak: 1, ActivityCount: 1, LastReportDate: now},
+	}}
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", ActivityType: "lari", DurationMinutes: 30, ReportedAt: now.AddDate(0, 0, -1)},
+		{UserID: "user1", ActivityType: "gym", DurationMinutes: 45, ReportedAt: now},
+		{UserID: "user2", ActivityType: "yoga", ReportedAt: now},
+	}}
+
+	uc := usecase.NewGetMemberProfileUsecase(repo, activities)
+	uc.SetAvatarGateway(&mockAvatars{urls: map[string]string{"user1": "https://pps.whatsapp.net/alice.jpg"}})
+	ctx := context.Background()
+
+	profile, err := uc.Get(ctx, "user1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if profile.Name != "Alice" || profile.Streak != 2 || profile.AvatarURL != "https://pps.whatsapp.net/alice.jpg" {
+		t.Errorf("Unexpected profile %+v", profile)
+	}
+	if len(profile.Chart) != 30 {
+		t.Fatalf("Expected 30 chart days, got %d", len(profile.Chart))
+	}
+	if today := profile.Chart[29]; today.Date != now.Format("2006-01-02") || !today.Reported || today.Minutes != 45 {
+		t.Errorf("Expected today reported with 45 minutes, got %+v", today)
+	}
+	if len(profile.Calendar) < 28 || profile.Calendar[0].Date[8:] != "01" {
+		t.Errorf("Expected the calendar to cover the current month, got %d days", len(profile.Calendar))
+	}
+	if len(profile.Recent) != 2 || profile.Recent[0].ActivityType != "gym" {
+		t.Errorf("Expected Alice's reports newest first, got %+v", profile.Recent)
+	}
+
+	// A failing avatar lookup does not break the profile
+	profile, err = uc.Get(ctx, "user2")
+	if err != nil || profile == nil || profile.AvatarURL != "" {
+		t.Errorf("Expected profile without avatar, got %+v, %v", profile, err)
+	}
+
+	if profile, err := uc.Get(ctx, "nobody"); err != nil || profile != nil {
+		t.Errorf("Expected nil for unknown user, got %+v, %v", profile, err)
+	}
+
+	streak := 5
+	report, err := uc.Update(ctx, "user1", usecase.MemberUpdate{Streak: &streak})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Streak != 5 || report.Name != "Alice" || repo.reports["user1"].Streak != 5 {
+		t.Errorf("Expected only the streak to change, got %+v", report)
+	}
+}
@@ -0,0 +1,385 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
+)
+
+type GetRecapUsecase struct {
+	activities domain.ActivityRepository
+	settings   domain.SettingsRepository
+	challenge  domain.Challenge
+	members    GroupMembersGateway
+}
+
+func NewGetRecapUsecase(activities domain.ActivityRepository) *GetRecapUsecase {
+	return &GetRecapUsecase{activities: activities}
+}
+
+// SetSettingsRepository leaves the reports of members that admins excluded
+// from the ranking out of the recap.
+func (uc *GetRecapUsecase) SetSettingsRepository(settings domain.SettingsRepository) {
+	uc.settings = settings
+}
+
+// SetChallenge puts the day of the challenge and the days left in the recap
+// header while the challenge runs.
+func (uc *GetRecapUsecase) SetChallenge(challenge domain.Challenge) {
+	uc.challenge = challenge
+}
+
+// SetGroupMembersGateway limits the challenge distance in a group's #recap to
+// the group's participants.
+func (uc *GetRecapUsecase) SetGroupMembersGateway(members GroupMembersGateway) {
+	uc.members = members
+}
+
+// challengeLine returns e.g. "Hari ke-17 dari 30 · 13 hari tersisa ⏳", ""
+// when the challenge is not running.
+func (uc *GetRecapUsecase) challengeLine(now time.Time) string {
+	if !uc.challenge.Running(now) {
+		return ""
+	}
+	day := uc.challenge.Day(now)
+	switch left := uc.challenge.DaysLeft(now); {
+	case left < 0:
+		return fmt.Sprintf("Hari ke-%d tantangan\n", day)
+	case left == 0:
+		return fmt.Sprintf("Hari ke-%d dari %d · hari terakhir ⏳\n", day, uc.challenge.Days)
+	default:
+		return fmt.Sprintf("Hari ke-%d dari %d · %d hari tersisa ⏳\n", day, uc.challenge.Days, left)
+	}
+}
+
+// WeeklyRecap is the data of the weekly recap, rendered as text for
+// WhatsApp by ExecuteWeekly and as HTML for the email digest.
+type WeeklyRecap struct {
+	Since         time.Time // Monday of the week
+	Until         time.Time
+	ChallengeLine string // see challengeLine, may be empty
+	Reports       int
+	Members       int
+	WeekKm        float64
+	ChallengeKm   float64 // accumulated since the challenge started
+	Types         []TypeCount
+	// Activities are the reports of the week, without unranked members
+	Activities []*domain.Activity
+	// The same part of the week before, e.g. Monday to Sunday 20:00 when the
+	// recap is sent on Sunday 20:00
+	LastReports int
+	LastMembers int
+}
+
+// ReportsChange compares the reports with the week before, e.g.
+// "▲ 17% dari 36", "" when there were none the week before.
+func (r *WeeklyRecap) ReportsChange() string {
+	return weekChange(r.Reports, r.LastReports)
+}
+
+// MembersChange compares the active members with the week before, like
+// ReportsChange.
+func (r *WeeklyRecap) MembersChange() string {
+	return weekChange(r.Members, r.LastMembers)
+}
+
+func weekChange(current, last int) string {
+	if last == 0 {
+		return ""
+	}
+	pct := int(math.Round(float64(current-last) * 100 / float64(last)))
+	switch {
+	case current > last:
+		return fmt.Sprintf("%s %d%% dari %d", trendUp, pct, last)
+	case current < last:
+		return fmt.Sprintf("%s %d%% dari %d", trendDown, -pct, last)
+	}
+	return fmt.Sprintf("%s sama dengan %d", trendSame, last)
+}
+
+// TypeCount is the number of reports of one activity type.
+type TypeCount struct {
+	Type  string
+	Label string
+	Count int
+}
+
+// Weekly collects the activity log from Monday of the week of now until
+// now.
+func (uc *GetRecapUsecase) Weekly(ctx context.Context, now time.Time) (*WeeklyRecap, error) {
+	since := startOfWeek(now)
+
+	activities, err := rankedActivities(ctx, uc.activities, uc.settings, domain.ActivityFilter{Since: since})
+	if err != nil {
+		return nil, err
+	}
+
+	// Up to the same weekday and time, so a recap sent mid-week is not
+	// compared with a whole week
+	lastWeek, err := rankedActivities(ctx, uc.activities, uc.settings, domain.ActivityFilter{Since: since.AddDate(0, 0, -7), Until: now.AddDate(0, 0, -7)})
+	if err != nil {
+		return nil, err
+	}
+
+	challengeKm, err := uc.challengeDistance(ctx, now, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WeeklyRecap{
+		Since:         since,
+		Until:         now,
+		ChallengeLine: strings.TrimSuffix(uc.challengeLine(now), "\n"),
+		Reports:       len(activities),
+		Members:       countMembers(activities),
+		WeekKm:        totalDistance(activities),
+		ChallengeKm:   challengeKm,
+		Types:         countTypes(activities),
+		Activities:    activities,
+		LastReports:   len(lastWeek),
+		LastMembers:   countMembers(lastWeek),
+	}, nil
+}
+
+// challengeDistance totals the distance reported since the first day of the
+// challenge, or ever without a start date. Only userIDs count when given.
+func (uc *GetRecapUsecase) challengeDistance(ctx context.Context, now time.Time, userIDs []string) (float64, error) {
+	var filter domain.ActivityFilter
+	if start := uc.challenge.Start; !start.IsZero() {
+		filter.Since = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, now.Location())
+	}
+	activities, err := rankedActivities(ctx, uc.activities, uc.settings, filter)
+	if err != nil {
+		return 0, err
+	}
+	if userIDs == nil {
+		return totalDistance(activities), nil
+	}
+
+	wanted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+	total := 0.0
+	for _, a := range activities {
+		if wanted[a.UserID] {
+			total += a.DistanceKm
+		}
+	}
+	return total, nil
+}
+
+// DailyRecap is the data of one day of the activity log, e.g. for the
+// public feed.
+type DailyRecap struct {
+	Day           time.Time // local midnight
+	ChallengeLine string    // see challengeLine, may be empty
+	Reports       int
+	Members       int
+	Km            float64
+	Types         []TypeCount
+	Names         []string // members who reported, first report first
+}
+
+// Daily collects one recap per day with reports from since until until,
+// oldest first. Days are split in the location of since.
+func (uc *GetRecapUsecase) Daily(ctx context.Context, since, until time.Time) ([]*DailyRecap, error) {
+	activities, err := rankedActivities(ctx, uc.activities, uc.settings, domain.ActivityFilter{Since: since, Until: until})
+	if err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[time.Time][]*domain.Activity)
+	var days []time.Time
+	for _, a := range activities {
+		t := a.ReportedAt.In(since.Location())
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, since.Location())
+		if _, ok := byDay[day]; !ok {
+			days = append(days, day)
+		}
+		byDay[day] = append(byDay[day], a)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	recaps := make([]*DailyRecap, 0, len(days))
+	for _, day := range days {
+		list := byDay[day]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].ReportedAt.Before(list[j].ReportedAt) })
+		recap := &DailyRecap{
+			Day:           day,
+			ChallengeLine: strings.TrimSuffix(uc.challengeLine(day), "\n"),
+			Reports:       len(list),
+			Members:       countMembers(list),
+			Km:            totalDistance(list),
+			Types:         countTypes(list),
+		}
+		seen := make(map[string]bool)
+		for _, a := range list {
+			if !seen[a.UserID] {
+				seen[a.UserID] = true
+				recap.Names = append(recap.Names, a.Name)
+			}
+		}
+		recaps = append(recaps, recap)
+	}
+	return recaps, nil
+}
+
+// ExecuteWeekly summarizes the activity log from Monday of the current week
+// until now, including a breakdown per activity type. In a group chat the
+// challenge distance only counts the group's participants.
+func (uc *GetRecapUsecase) ExecuteWeekly(ctx context.Context, chatJID string) (string, error) {
+	now := time.Now()
+	recap, err := uc.Weekly(ctx, now)
+	if err != nil {
+		return "", err
+	}
+	if uc.members != nil && strings.HasSuffix(chatJID, "@g.us") {
+		userIDs, err := uc.members.GroupMembers(ctx, chatJID)
+		if err != nil {
+			return "", err
+		}
+		// Never nil, so a group without participants totals nothing
+		if recap.ChallengeKm, err = uc.challengeDistance(ctx, now, append([]string{}, userIDs...)); err != nil {
+			return "", err
+		}
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Recap Mingguan (%s – %s)\n", recap.Since.Format("02-01-2006"), recap.Until.Format("02-01-2006")))
+	if recap.ChallengeLine != "" {
+		sb.WriteString(recap.ChallengeLine + "\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("Total laporan: %d\n", recap.Reports))
+	sb.WriteString(fmt.Sprintf("Member aktif: %d\n", recap.Members))
+	if recap.WeekKm > 0 {
+		sb.WriteString(fmt.Sprintf("Total jarak minggu ini: %s\n", activity.FormatDistance(recap.WeekKm)))
+	}
+	if recap.ChallengeKm > 0 {
+		sb.WriteString(fmt.Sprintf("Total jarak tantangan: %s 📏\n", activity.FormatDistance(recap.ChallengeKm)))
+	}
+
+	if recap.LastReports > 0 {
+		sb.WriteString("\nDibanding minggu lalu:\n")
+		sb.WriteString(fmt.Sprintf("Laporan: %d (%s)\n", recap.Reports, recap.ReportsChange()))
+		sb.WriteString(fmt.Sprintf("Member aktif: %d (%s)\n", recap.Members, recap.MembersChange()))
+	}
+
+	if breakdown := formatTypes(recap.Types); breakdown != "" {
+		sb.WriteString("\nBreakdown aktivitas:\n")
+		sb.WriteString(breakdown)
+	}
+
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// ExecuteMonthly summarizes the current calendar month, including total
+// duration and an estimate of calories burned.
+func (uc *GetRecapUsecase) ExecuteMonthly(ctx context.Context) (string, error) {
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	activities, err := rankedActivities(ctx, uc.activities, uc.settings, domain.ActivityFilter{Since: since})
+	if err != nil {
+		return "", err
+	}
+
+	totalMinutes := 0
+	for _, a := range activities {
+		totalMinutes += a.DurationMinutes
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Recap Bulanan (%s – %s)\n", since.Format("02-01-2006"), now.Format("02-01-2006")))
+	sb.WriteString(uc.challengeLine(now) + "\n")
+	sb.WriteString(fmt.Sprintf("Total laporan: %d\n", len(activities)))
+	sb.WriteString(fmt.Sprintf("Member aktif: %d\n", countMembers(activities)))
+	if totalMinutes > 0 {
+		sb.WriteString(fmt.Sprintf("Total durasi: %s ⏱️\n", activity.FormatDuration(totalMinutes)))
+	}
+	if km := totalDistance(activities); km > 0 {
+		sb.WriteString(fmt.Sprintf("Total jarak: %s 📏\n", activity.FormatDistance(km)))
+	}
+	if kcal := totalCalories(activities); kcal > 0 {
+		sb.WriteString(fmt.Sprintf("Estimasi kalori terbakar: ~%d kkal 🔥\n", kcal))
+	}
+
+	if breakdown := formatTypeBreakdown(activities); breakdown != "" {
+		sb.WriteString("\nBreakdown aktivitas:\n")
+		sb.WriteString(breakdown)
+	}
+
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+func countMembers(activities []*domain.Activity) int {
+	members := make(map[string]bool)
+	for _, a := range activities {
+		members[a.UserID] = true
+	}
+	return len(members)
+}
+
+// totalCalories sums the estimated kcal of every report that has a duration.
+func totalCalories(activities []*domain.Activity) int {
+	total := 0
+	for _, a := range activities {
+		total += activity.EstimateCalories(a.ActivityType, a.DurationMinutes)
+	}
+	return total
+}
+
+// countTypes counts the reports per activity type, most reported first.
+func countTypes(activities []*domain.Activity) []TypeCount {
+	counts := make(map[string]int)
+	for _, a := range activities {
+		counts[a.ActivityType]++
+	}
+
+	types := make([]TypeCount, 0, len(counts))
+	for t, n := range counts {
+		types = append(types, TypeCount{Type: t, Label: activity.Label(t), Count: n})
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].Count != types[j].Count {
+			return types[i].Count > types[j].Count
+		}
+		return types[i].Type < types[j].Type
+	})
+	return types
+}
+
+// formatTypeBreakdown renders one line per activity type, most reported first.
+func formatTypeBreakdown(activities []*domain.Activity) string {
+	return formatTypes(countTypes(activities))
+}
+
+func formatTypes(types []TypeCount) string {
+	sb := strings.Builder{}
+	for _, t := range types {
+		sb.WriteString(fmt.Sprintf("%s - %d laporan\n", t.Label, t.Count))
+	}
+	return sb.String()
+}
+
+func totalDistance(activities []*domain.Activity) float64 {
+	total := 0.0
+	for _, a := range activities {
+		total += a.DistanceKm
+	}
+	return total
+}
+
+// startOfWeek returns local midnight of the Monday on or before t.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7 // Monday = 0
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -offset)
+}
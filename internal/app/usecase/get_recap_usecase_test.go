@@ -0,0 +1,559 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// mockActivityRepo implements domain.ActivityRepository for testing
+type mockActivityRepo struct {
+	activities []*domain.Activity
+}
+
+func (m *mockActivityRepo) AddActivity(ctx context.Context, activity *domain.Activity) error {
+	activity.ID = int64(len(m.activities) + 1)
+	m.activities = append(m.activities, activity)
+	return nil
+}
+
+func (m *mockActivityRepo) GetActivities(ctx context.Context, filter domain.ActivityFilter) ([]*domain.Activity, error) {
+	var result []*domain.Activity
+	for _, a := range m.activities {
+		if filter.UserID != "" && a.UserID != filter.UserID {
+			continue
+		}
+		if filter.ActivityType != "" && a.ActivityType != filter.ActivityType {
+			continue
+		}
+		if !filter.Since.IsZero() && a.ReportedAt.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !a.ReportedAt.Before(filter.Until) {
+			continue
+		}
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+func (m *mockActivityRepo) DeleteActivities(ctx context.Context, filter domain.ActivityFilter) error {
+	matched, _ := m.GetActivities(ctx, filter)
+	remove := make(map[*domain.Activity]bool, len(matched))
+	for _, a := range matched {
+		remove[a] = true
+	}
+
+	var kept []*domain.Activity
+	for _, a := range m.activities {
+		if !remove[a] {
+			kept = append(kept, a)
+		}
+	}
+	m.activities = kept
+	return nil
+}
+
+func (m *mockActivityRepo) InitTable(ctx context.Context) error {
+	return nil
+}
+
+// =============================================================================
+// ACTIVITY TYPE TESTS
+// =============================================================================
+
+func TestReport_LogsActivityType(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	activities := &mockActivityRepo{}
+	uc := usecase.NewReportActivityUsecase(repo)
+	uc.SetActivityRepository(activities)
+
+	if _, err := uc.ExecuteWithMessage(context.Background(), "user1", "Alice", "#lapor lari pagi 5km"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(activities.activities) != 1 {
+		t.Fatalf("Expected 1 logged activity, got %d", len(activities.activities))
+	}
+	if got := activities.activities[0].ActivityType; got != "lari" {
+		t.Errorf("Expected activity type 'lari', got '%s'", got)
+	}
+
+	// Rejected duplicate must not be logged
+	if _, err := uc.ExecuteWithMessage(context.Background(), "user1", "Alice", "#lapor gym"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(activities.activities) != 1 {
+		t.Errorf("Duplicate report should not be logged, got %d entries", len(activities.activities))
+	}
+}
+
+func TestLeaderboard_ByType(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	activities := &mockActivityRepo{}
+	now := time.Now()
+	noon := time.Date(now.Year(), now.Month(), now.Day()-3, 12, 0, 0, 0, time.Local)
+	activities.activities = []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", ReportedAt: now.AddDate(0, 0, -2)},
+		{UserID: "user2", Name: "Bob", ActivityType: "lari", ReportedAt: now.AddDate(0, 0, -2)},
+		{UserID: "user2", Name: "Bob", ActivityType: "lari", ReportedAt: now.AddDate(0, 0, -1)},
+		{UserID: "user1", Name: "Alice", ActivityType: "gym", ReportedAt: now.AddDate(0, 0, -1)},
+		{UserID: "user3", Name: "Carol", ActivityType: "lari", ReportedAt: noon},
+		{UserID: "user3", Name: "Carol", ActivityType: "lari", ReportedAt: noon.Add(time.Hour)}, // same day
+	}
+
+	leaderboardUC := usecase.NewGetLeaderboardUsecase(repo)
+	leaderboardUC.SetActivityRepository(activities)
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), leaderboardUC)
+
+	result, err := handleUC.Execute(context.Background(), "user1", "Alice", "#leaderboard lari")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !containsSubstring(result, "Leaderboard Lari") {
+		t.Errorf("Expected per-type header, got '%s'", result)
+	}
+	if !containsSubstring(result, "1. Bob - 2 days") || !containsSubstring(result, "Alice - 1 days") {
+		t.Errorf("Expected Bob ranked above Alice for lari, got '%s'", result)
+	}
+	if !containsSubstring(result, "Carol - 1 days") {
+		t.Errorf("Expected two reports on one day to count once, got '%s'", result)
+	}
+}
+
+func TestRecap_WeeklyBreakdown(t *testing.T) {
+	activities := &mockActivityRepo{}
+	now := time.Now()
+	activities.activities = []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", ReportedAt: now},
+		{UserID: "user2", Name: "Bob", ActivityType: "lari", ReportedAt: now},
+		{UserID: "user3", Name: "Carol", ActivityType: "gym", ReportedAt: now},
+		{UserID: "user1", Name: "Alice", ActivityType: "gym", ReportedAt: now.AddDate(0, 0, -14)}, // previous week
+	}
+
+	uc := usecase.NewGetRecapUsecase(activities)
+	result, err := uc.ExecuteWeekly(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !containsSubstring(result, "Total laporan: 3") {
+		t.Errorf("Expected 3 reports this week, got '%s'", result)
+	}
+	if !containsSubstring(result, "Member aktif: 3") {
+		t.Errorf("Expected 3 active members, got '%s'", result)
+	}
+	if !containsSubstring(result, "Lari 🏃 - 2 laporan") || !containsSubstring(result, "Gym 🏋️ - 1 laporan") {
+		t.Errorf("Expected type breakdown, got '%s'", result)
+	}
+	if indexOf(result, "Lari") > indexOf(result, "Gym") {
+		t.Errorf("Most reported type should come first, got '%s'", result)
+	}
+}
+
+func TestRecap_WeekOverWeek(t *testing.T) {
+	activities := &mockActivityRepo{}
+	// Sunday evening, when the recap is sent
+	now := time.Date(2026, 3, 15, 20, 0, 0, 0, time.Local)
+	activities.activities = []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", ReportedAt: now.AddDate(0, 0, -6)},
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", ReportedAt: now.AddDate(0, 0, -1)},
+		{UserID: "user2", Name: "Bob", ActivityType: "gym", ReportedAt: now.Add(-time.Hour)},
+		// The week before
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", ReportedAt: now.AddDate(0, 0, -10)},
+		{UserID: "user2", Name: "Bob", ActivityType: "gym", ReportedAt: now.AddDate(0, 0, -8)},
+		{UserID: "user3", Name: "Carol", ActivityType: "gym", ReportedAt: now.AddDate(0, 0, -7).Add(-time.Hour)},
+		{UserID: "user3", Name: "Carol", ActivityType: "gym", ReportedAt: now.AddDate(0, 0, -7).Add(time.Hour)}, // later than now last week
+	}
+
+	uc := usecase.NewGetRecapUsecase(activities)
+	recap, err := uc.Weekly(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if recap.LastReports != 3 || recap.LastMembers != 3 {
+		t.Errorf("Expected 3 reports by 3 members up to the same time last week, got %d, %d", recap.LastReports, recap.LastMembers)
+	}
+	if got := recap.ReportsChange(); got != "= sama dengan 3" {
+		t.Errorf("Expected the same number of reports, got %q", got)
+	}
+	if got := recap.MembersChange(); got != "▼ 33% dari 3" {
+		t.Errorf("Expected a third fewer members, got %q", got)
+	}
+
+	recap.Reports = 4
+	if got := recap.ReportsChange(); got != "▲ 33% dari 3" {
+		t.Errorf("Expected a third more reports, got %q", got)
+	}
+	recap.LastReports = 0
+	if got := recap.ReportsChange(); got != "" {
+		t.Errorf("Expected no comparison without reports last week, got %q", got)
+	}
+
+	// The recap posts the comparison only when there was a week before
+	now = time.Now()
+	monday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, -(int(now.Weekday())+6)%7)
+	activities.activities = []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", ReportedAt: monday},
+	}
+	result, err := uc.ExecuteWeekly(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if containsSubstring(result, "Dibanding minggu lalu") {
+		t.Errorf("Expected no comparison in the first week, got '%s'", result)
+	}
+
+	activities.activities = append(activities.activities,
+		&domain.Activity{UserID: "user1", Name: "Alice", ActivityType: "lari", ReportedAt: monday.AddDate(0, 0, -7)},
+		&domain.Activity{UserID: "user2", Name: "Bob", ActivityType: "gym", ReportedAt: monday.AddDate(0, 0, -7)},
+	)
+	result, err = uc.ExecuteWeekly(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Dibanding minggu lalu:\nLaporan: 1 (▼ 50% dari 2)\nMember aktif: 1 (▼ 50% dari 2)") {
+		t.Errorf("Expected the comparison with last week, got '%s'", result)
+	}
+}
+
+func TestRecap_ChallengeCountdownInHeader(t *testing.T) {
+	uc := usecase.NewGetRecapUsecase(&mockActivityRepo{})
+	uc.SetChallenge(domain.Challenge{Start: time.Now().AddDate(0, 0, -16), Days: 30})
+
+	result, err := uc.ExecuteWeekly(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lines := strings.Split(result, "\n"); len(lines) < 2 || lines[1] != "Hari ke-17 dari 30 · 13 hari tersisa ⏳" {
+		t.Errorf("Expected the countdown below the title, got '%s'", result)
+	}
+
+	uc.SetChallenge(domain.Challenge{Start: time.Now().AddDate(0, 0, -40), Days: 30})
+	if result, _ := uc.ExecuteMonthly(context.Background()); containsSubstring(result, "Hari ke-") {
+		t.Errorf("Expected no countdown after the challenge, got '%s'", result)
+	}
+}
+
+// =============================================================================
+// DURATION TESTS
+// =============================================================================
+
+func TestReport_ParsesDuration(t *testing.T) {
+	testCases := map[string]int{
+		"#lapor lari 30m":          30,
+		"#lapor lari 400m":         0,
+		"#lapor renang 400m":       0,
+		"#lapor renang 40m":        0,
+		"#lapor renang 45 menit":   45,
+		"#lapor renang 1j30m":      90,
+		"#lapor gym 45 menit":      45,
+		"#lapor sepeda 1 jam":      60,
+		"#lapor renang 1.5 jam":    90,
+		"#lapor jalan 1j30m":       90,
+		"#lapor jalan 1h30menit":   90,
+		"#lapor yoga 1 jam 15 mnt": 75,
+		"#lapor lari 5km":          0,
+		"#lapor":                   0,
+	}
+
+	for msg, expected := range testCases {
+		repo := &mockRepo{reports: make(map[string]*domain.Report)}
+		activities := &mockActivityRepo{}
+		uc := usecase.NewReportActivityUsecase(repo)
+		uc.SetActivityRepository(activities)
+
+		if _, err := uc.ExecuteWithMessage(context.Background(), "user1", "Alice", msg); err != nil {
+			t.Fatalf("Unexpected error for '%s': %v", msg, err)
+		}
+		if got := activities.activities[0].DurationMinutes; got != expected {
+			t.Errorf("'%s': expected %d minutes, got %d", msg, expected, got)
+		}
+	}
+}
+
+func TestLeaderboard_ByDuration(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	activities := &mockActivityRepo{}
+	now := time.Now()
+	activities.activities = []*domain.Activity{
+		{UserID: "user1", Name: "Alice", DurationMinutes: 30, ReportedAt: now},
+		{UserID: "user2", Name: "Bob", DurationMinutes: 60, ReportedAt: now},
+		{UserID: "user2", Name: "Bob", DurationMinutes: 30, ReportedAt: now},
+		{UserID: "user3", Name: "Carol", ReportedAt: now},
+	}
+
+	uc := usecase.NewGetLeaderboardUsecase(repo)
+	uc.SetActivityRepository(activities)
+
+	result, err := uc.ExecuteByDuration(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "1. Bob - 1 jam 30 menit") || !containsSubstring(result, "2. Alice - 30 menit") {
+		t.Errorf("Expected Bob above Alice by minutes, got '%s'", result)
+	}
+	if containsSubstring(result, "Carol") {
+		t.Errorf("Members without duration should be omitted, got '%s'", result)
+	}
+}
+
+// =============================================================================
+// DISTANCE TESTS
+// =============================================================================
+
+func TestReport_ParsesDistance(t *testing.T) {
+	testCases := map[string]float64{
+		"#lapor lari 5km":             5,
+		"#lapor sepeda 10.5 km":       10.5,
+		"#lapor lari 10,5km 45m":      10.5,
+		"#lapor lari 5k":              5,
+		"#lapor gym 45 menit":         0,
+		"#lapor lari 3km + jalan 2km": 5,
+	}
+
+	for msg, expected := range testCases {
+		repo := &mockRepo{reports: make(map[string]*domain.Report)}
+		activities := &mockActivityRepo{}
+		uc := usecase.NewReportActivityUsecase(repo)
+		uc.SetActivityRepository(activities)
+
+		if _, err := uc.ExecuteWithMessage(context.Background(), "user1", "Alice", msg); err != nil {
+			t.Fatalf("Unexpected error for '%s': %v", msg, err)
+		}
+		if got := activities.activities[0].DistanceKm; got != expected {
+			t.Errorf("'%s': expected %.1f km, got %.1f", msg, expected, got)
+		}
+	}
+}
+
+func TestRecap_TotalDistance(t *testing.T) {
+	now := time.Now()
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", ActivityType: "lari", DistanceKm: 5, ReportedAt: now},
+		{UserID: "user2", ActivityType: "sepeda", DistanceKm: 20.5, ReportedAt: now},
+		{UserID: "user1", ActivityType: "lari", DistanceKm: 10, ReportedAt: now.AddDate(0, 0, -14)},
+	}}
+
+	result, err := usecase.NewGetRecapUsecase(activities).ExecuteWeekly(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Total jarak minggu ini: 25.5 km") {
+		t.Errorf("Expected weekly distance, got '%s'", result)
+	}
+	if !containsSubstring(result, "Total jarak tantangan: 35.5 km") {
+		t.Errorf("Expected challenge distance, got '%s'", result)
+	}
+}
+
+func TestRecap_ChallengeDistancePerGroup(t *testing.T) {
+	now := time.Now()
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", ActivityType: "lari", DistanceKm: 5, ReportedAt: now},
+		{UserID: "user2", ActivityType: "sepeda", DistanceKm: 20.5, ReportedAt: now},
+		{UserID: "user1", ActivityType: "lari", DistanceKm: 10, ReportedAt: now.AddDate(0, 0, -9)},
+		// Before the challenge started
+		{UserID: "user1", ActivityType: "lari", DistanceKm: 100, ReportedAt: now.AddDate(0, 0, -30)},
+	}}
+	uc := usecase.NewGetRecapUsecase(activities)
+	uc.SetChallenge(domain.Challenge{Start: now.AddDate(0, 0, -10), Days: 30})
+	uc.SetGroupMembersGateway(mockGroupMembers{"grup@g.us": {"user1"}})
+
+	result, err := uc.ExecuteWeekly(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Total jarak tantangan: 35.5 km") {
+		t.Errorf("Expected distance since the challenge started, got '%s'", result)
+	}
+
+	result, err = uc.ExecuteWeekly(context.Background(), "grup@g.us")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Total jarak tantangan: 15 km") {
+		t.Errorf("Expected only the group's distance, got '%s'", result)
+	}
+}
+
+// =============================================================================
+// MONTHLY RECAP / CALORIE TESTS
+// =============================================================================
+
+func TestRecap_MonthlyCalories(t *testing.T) {
+	now := time.Now()
+	lastMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, 0, -1)
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		// 9.8 MET × 70 kg × 0.5 h = 343 kkal
+		{UserID: "user1", ActivityType: "lari", DurationMinutes: 30, ReportedAt: now},
+		// 2.5 MET × 70 kg × 1 h = 175 kkal
+		{UserID: "user2", ActivityType: "yoga", DurationMinutes: 60, ReportedAt: now},
+		// No duration, no estimate
+		{UserID: "user3", ActivityType: "gym", ReportedAt: now},
+		{UserID: "user1", ActivityType: "lari", DurationMinutes: 600, ReportedAt: lastMonth},
+	}}
+
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetRecapUsecase(usecase.NewGetRecapUsecase(activities))
+
+	result, err := handleUC.Execute(context.Background(), "user1", "Alice", "#recap bulan")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{"Recap Bulanan", "Total laporan: 3", "Total durasi: 1 jam 30 menit", "Estimasi kalori terbakar: ~518 kkal"} {
+		if !containsSubstring(result, expected) {
+			t.Errorf("Expected '%s' in monthly recap, got '%s'", expected, result)
+		}
+	}
+}
+
+// =============================================================================
+// PERIOD LEADERBOARD TESTS
+// =============================================================================
+
+func TestLeaderboard_ByPeriod(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	day := time.Date(2026, 3, 3, 7, 0, 0, 0, time.Local)
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ReportedAt: day.AddDate(0, 0, -10)},
+		{UserID: "user1", Name: "Alice", ReportedAt: day.AddDate(0, 0, -9)},
+		{UserID: "user2", Name: "Bob", ReportedAt: day},
+		{UserID: "user2", Name: "Bob", ReportedAt: day.AddDate(0, 0, 4)}, // last day, included
+		{UserID: "user1", Name: "Alice", ReportedAt: day.AddDate(0, 0, 1)},
+		{UserID: "user1", Name: "Alice", ReportedAt: day.AddDate(0, 0, 5)}, // after range
+	}}
+
+	leaderboardUC := usecase.NewGetLeaderboardUsecase(repo)
+	leaderboardUC.SetActivityRepository(activities)
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), leaderboardUC)
+
+	result, err := handleUC.Execute(context.Background(), "user1", "Alice", "#leaderboard 2026-03-01..2026-03-07")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "01-03-2026 – 07-03-2026") {
+		t.Errorf("Expected period header, got '%s'", result)
+	}
+	if !containsSubstring(result, "1. Bob - 2 days") || !containsSubstring(result, "2. Alice - 1 days") {
+		t.Errorf("Expected Bob above Alice within the range, got '%s'", result)
+	}
+
+	result, err = handleUC.Execute(context.Background(), "user1", "Alice", "#leaderboard minggu ini")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Leaderboard") || containsSubstring(result, "keep the streak") {
+		t.Errorf("Expected period leaderboard for 'minggu ini', got '%s'", result)
+	}
+}
+
+// =============================================================================
+// RANKING EXCLUSION TESTS
+// =============================================================================
+
+func TestLeaderboard_ExcludedMembersStillUseCommands(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1":        {UserID: "user1", Name: "Alice", Streak: 5, ActivityCount: 5, LastReportDate: now},
+		"628123456789": {UserID: "628123456789", Name: "Coach", Streak: 9, ActivityCount: 9, LastReportDate: now.AddDate(0, 0, -1)},
+	}}
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", DurationMinutes: 30, ReportedAt: now},
+		{UserID: "628123456789", Name: "Coach", ActivityType: "lari", DurationMinutes: 90, ReportedAt: now},
+	}}
+	settings := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+
+	leaderboardUC := usecase.NewGetLeaderboardUsecase(repo)
+	leaderboardUC.SetActivityRepository(activities)
+	leaderboardUC.SetSettingsRepository(settings)
+	recapUC := usecase.NewGetRecapUsecase(activities)
+	recapUC.SetSettingsRepository(settings)
+	memberUC := usecase.NewGetMemberProfileUsecase(repo, activities)
+	memberUC.SetSettingsRepository(settings)
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), leaderboardUC)
+	handleUC.SetRecapUsecase(recapUC)
+	handleUC.SetMemberUsecase(memberUC)
+	handleUC.SetAdmins([]string{"admin"})
+	ctx := context.Background()
+
+	result, err := handleUC.Execute(ctx, "admin", "Admin", "#admin set @628123456789 klasemen=tidak")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Tidak masuk klasemen") || !settings.settings["628123456789"].Unranked {
+		t.Fatalf("Expected the coach excluded, got '%s'", result)
+	}
+
+	for _, command := range []string{"#leaderboard", "#leaderboard lari", "#leaderboard durasi", "#leaderboard minggu ini"} {
+		result, err := handleUC.Execute(ctx, "user1", "Alice", command)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if containsSubstring(result, "Coach") || !containsSubstring(result, "1. Alice") {
+			t.Errorf("%s: expected only Alice ranked, got '%s'", command, result)
+		}
+	}
+	result, err = handleUC.Execute(ctx, "user1", "Alice", "#recap")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Total laporan: 1") || !containsSubstring(result, "Member aktif: 1") {
+		t.Errorf("Expected the coach's report left out of the recap, got '%s'", result)
+	}
+
+	// The coach keeps reporting
+	result, err = handleUC.Execute(ctx, "628123456789", "Coach", "#lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Laporan diterima") {
+		t.Errorf("Expected the coach's report counted, got '%s'", result)
+	}
+
+	if _, err := handleUC.Execute(ctx, "admin", "Admin", "#admin set @628123456789 klasemen=ya"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err = handleUC.Execute(ctx, "user1", "Alice", "#leaderboard")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "1. Coach – 10🔥 (total 10)") {
+		t.Errorf("Expected the coach back in the ranking, got '%s'", result)
+	}
+}
+
+func TestMemberUpdate_UnrankedBeforeFirstReport(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	settings := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+	memberUC := usecase.NewGetMemberProfileUsecase(repo, &mockActivityRepo{})
+	memberUC.SetSettingsRepository(settings)
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	handleUC.SetMemberUsecase(memberUC)
+	handleUC.SetAdmins([]string{"admin"})
+	ctx := context.Background()
+
+	// The coach is left out before they ever reported
+	result, err := handleUC.Execute(ctx, "admin", "Admin", "#admin set @628123456789 klasemen=tidak")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Tidak masuk klasemen") || settings.settings["628123456789"] == nil || !settings.settings["628123456789"].Unranked {
+		t.Fatalf("Expected the coach excluded without a report, got '%s'", result)
+	}
+
+	// Correcting the numbers still needs a report
+	result, err = handleUC.Execute(ctx, "admin", "Admin", "#admin set @628123456789 streak=3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "belum pernah lapor") {
+		t.Errorf("Expected the missing report reported, got '%s'", result)
+	}
+}
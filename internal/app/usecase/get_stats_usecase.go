@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
+)
+
+type GetStatsUsecase struct {
+	repo       domain.ReportRepository
+	activities domain.ActivityRepository
+}
+
+func NewGetStatsUsecase(repo domain.ReportRepository, activities domain.ActivityRepository) *GetStatsUsecase {
+	return &GetStatsUsecase{repo: repo, activities: activities}
+}
+
+// Execute returns the personal statistics of the sender (#stats).
+func (uc *GetStatsUsecase) Execute(ctx context.Context, userID, name string) (string, error) {
+	report, err := uc.repo.GetReport(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if report == nil {
+		return fmt.Sprintf("%s belum pernah laporan. Ketik #lapor untuk mulai 💪", name), nil
+	}
+
+	activities, err := uc.activities.GetActivities(ctx, domain.ActivityFilter{UserID: userID})
+	if err != nil {
+		return "", err
+	}
+
+	totalMinutes, timedReports := 0, 0
+	typeCounts := make(map[string]int)
+	for _, a := range activities {
+		if a.DurationMinutes > 0 {
+			totalMinutes += a.DurationMinutes
+			timedReports++
+		}
+		typeCounts[a.ActivityType]++
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Statistik %s 📊\n\n", report.Name))
+	sb.WriteString(fmt.Sprintf("Streak: %d hari 🔥\n", report.Streak))
+	sb.WriteString(fmt.Sprintf("Total: %d hari\n", report.ActivityCount))
+	if totalMinutes > 0 {
+		sb.WriteString(fmt.Sprintf("Total durasi: %s ⏱️\n", activity.FormatDuration(totalMinutes)))
+		sb.WriteString(fmt.Sprintf("Rata-rata: %s per laporan\n", activity.FormatDuration(totalMinutes/timedReports)))
+	}
+	if kcal := totalCalories(activities); kcal > 0 {
+		sb.WriteString(fmt.Sprintf("Estimasi kalori: ~%d kkal 🔥\n", kcal))
+	}
+	if km := totalDistance(activities); km > 0 {
+		sb.WriteString(fmt.Sprintf("Total jarak: %s 📏\n", activity.FormatDistance(km)))
+	}
+	if favorite := favoriteType(typeCounts); favorite != "" {
+		sb.WriteString(fmt.Sprintf("Aktivitas favorit: %s\n", activity.Label(favorite)))
+	}
+
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// favoriteType returns the most reported type. Ties go to the earlier entry
+// of activity.Types, so a specific type beats "lainnya".
+func favoriteType(counts map[string]int) string {
+	favorite := ""
+	for _, t := range activity.Types {
+		if counts[t] > counts[favorite] {
+			favorite = t
+		}
+	}
+	return favorite
+}
@@ -0,0 +1,42 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestStats_ShowsTotals(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 4, LastReportDate: time.Now()},
+	}}
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", ActivityType: "lari", DurationMinutes: 30, DistanceKm: 5},
+		{UserID: "user1", ActivityType: "lari", DurationMinutes: 60, DistanceKm: 2.5},
+		{UserID: "user1", ActivityType: "gym"},
+		{UserID: "user2", ActivityType: "gym", DurationMinutes: 120},
+	}}
+
+	uc := usecase.NewGetStatsUsecase(repo, activities)
+	result, err := uc.Execute(context.Background(), "user1", "Alice")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{"Streak: 3 hari", "Total: 4 hari", "Total durasi: 1 jam 30 menit", "Rata-rata: 45 menit", "Total jarak: 7.5 km", "Estimasi kalori: ~1029 kkal", "Aktivitas favorit: Lari"} {
+		if !containsSubstring(result, expected) {
+			t.Errorf("Expected '%s' in stats, got '%s'", expected, result)
+		}
+	}
+
+	result, err = uc.Execute(context.Background(), "nobody", "Zed")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "belum pernah laporan") {
+		t.Errorf("Expected hint for new user, got '%s'", result)
+	}
+}
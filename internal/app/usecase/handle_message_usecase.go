@@ -2,33 +2,259 @@ package usecase
 
 import (
 	"context"
+	"log"
 	"strings"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
 )
 
 type HandleMessageUsecase struct {
 	reportUC      *ReportActivityUsecase
 	leaderboardUC *GetLeaderboardUsecase
+	recapUC       *GetRecapUsecase
+	statsUC       *GetStatsUsecase
+	targetUC      *SetTargetUsecase
+	chartUC       *GetChartUsecase
+	historyUC     *GetHistoryUsecase
+	exportUC      *ExportUserDataUsecase
+	deleteUC      *DeleteUserDataUsecase
+	botStatsUC    *GetBotStatsUsecase
+	searchUC      *SearchArchiveUsecase
+	groupsUC      *ManageGroupsUsecase
+	memberUC      *GetMemberProfileUsecase
+	backfillUC    *BackfillReportsUsecase
+	linkUC        *LinkIdentityUsecase
+	snoozeUC      *SnoozeReminderUsecase
+	reminderUC    *SetReminderUsecase
+	privateUC     *SetPrivateModeUsecase
+	noMentionUC   *SetNoMentionUsecase
+	homeUC        *HomeAssistantUsecase
+	emailUC       *SetEmailUsecase
+	calendarUC    *CalendarUsecase
+	onboardingUC  *OnboardingUsecase
+	conversations *ConversationManager
+	commands      []Command
+	admins        map[string]bool
+	aliases       []commandAlias
+	prefix        string
+	suggest       bool
+	hasher        *phone.Hasher
 }
 
 func NewHandleMessageUsecase(reportUC *ReportActivityUsecase, leaderboardUC *GetLeaderboardUsecase) *HandleMessageUsecase {
-	return &HandleMessageUsecase{
+	uc := &HandleMessageUsecase{
 		reportUC:      reportUC,
 		leaderboardUC: leaderboardUC,
+		aliases:       buildAliases(defaultAliases),
 	}
+	uc.commands = builtinCommands(uc)
+	return uc
 }
 
-func (uc *HandleMessageUsecase) Execute(ctx context.Context, userID, name, message string) (string, error) {
-	msg := strings.TrimSpace(message)
+// SetRecapUsecase enables the #recap command.
+func (uc *HandleMessageUsecase) SetRecapUsecase(recapUC *GetRecapUsecase) {
+	uc.recapUC = recapUC
+}
+
+// SetStatsUsecase enables the #stats command.
+func (uc *HandleMessageUsecase) SetStatsUsecase(statsUC *GetStatsUsecase) {
+	uc.statsUC = statsUC
+}
+
+// SetTargetUsecase enables the #target command.
+func (uc *HandleMessageUsecase) SetTargetUsecase(targetUC *SetTargetUsecase) {
+	uc.targetUC = targetUC
+}
+
+// SetChartUsecase enables the #grafik command.
+func (uc *HandleMessageUsecase) SetChartUsecase(chartUC *GetChartUsecase) {
+	uc.chartUC = chartUC
+}
+
+// SetHistoryUsecase enables the #history command.
+func (uc *HandleMessageUsecase) SetHistoryUsecase(historyUC *GetHistoryUsecase) {
+	uc.historyUC = historyUC
+}
+
+// SetExportUsecase enables the #mydata command.
+func (uc *HandleMessageUsecase) SetExportUsecase(exportUC *ExportUserDataUsecase) {
+	uc.exportUC = exportUC
+}
+
+// SetDeleteUsecase enables the #hapusdata command.
+func (uc *HandleMessageUsecase) SetDeleteUsecase(deleteUC *DeleteUserDataUsecase) {
+	uc.deleteUC = deleteUC
+}
+
+// SetBotStatsUsecase enables the #botstats admin command.
+func (uc *HandleMessageUsecase) SetBotStatsUsecase(botStatsUC *GetBotStatsUsecase) {
+	uc.botStatsUC = botStatsUC
+}
+
+// SetSearchUsecase enables the #cari admin command.
+func (uc *HandleMessageUsecase) SetSearchUsecase(searchUC *SearchArchiveUsecase) {
+	uc.searchUC = searchUC
+}
+
+// SetGroupsUsecase enables the #admin group commands.
+func (uc *HandleMessageUsecase) SetGroupsUsecase(groupsUC *ManageGroupsUsecase) {
+	uc.groupsUC = groupsUC
+}
+
+// SetMemberUsecase enables the "#admin set" admin command.
+func (uc *HandleMessageUsecase) SetMemberUsecase(memberUC *GetMemberProfileUsecase) {
+	uc.memberUC = memberUC
+}
+
+// SetBackfillUsecase enables the "#admin backfill" admin command. The use
+// case needs the message archive.
+func (uc *HandleMessageUsecase) SetBackfillUsecase(backfillUC *BackfillReportsUsecase) {
+	uc.backfillUC = backfillUC
+}
+
+// SetLinkUsecase enables the #link command.
+func (uc *HandleMessageUsecase) SetLinkUsecase(linkUC *LinkIdentityUsecase) {
+	uc.linkUC = linkUC
+}
+
+// SetSnoozeUsecase enables the #snooze command.
+func (uc *HandleMessageUsecase) SetSnoozeUsecase(snoozeUC *SnoozeReminderUsecase) {
+	uc.snoozeUC = snoozeUC
+}
+
+// SetReminderUsecase enables the #ingatkan command.
+func (uc *HandleMessageUsecase) SetReminderUsecase(reminderUC *SetReminderUsecase) {
+	uc.reminderUC = reminderUC
+}
+
+// SetPrivateModeUsecase enables the #privat command.
+func (uc *HandleMessageUsecase) SetPrivateModeUsecase(privateUC *SetPrivateModeUsecase) {
+	uc.privateUC = privateUC
+}
+
+// SetNoMentionUsecase enables the #jangan-tag command.
+func (uc *HandleMessageUsecase) SetNoMentionUsecase(noMentionUC *SetNoMentionUsecase) {
+	uc.noMentionUC = noMentionUC
+}
 
-	// Handle #lapor
-	if strings.HasPrefix(strings.ToLower(msg), "#lapor") {
-		return uc.reportUC.Execute(ctx, userID, name)
+// SetHomeAssistantUsecase enables the #homeassistant command.
+func (uc *HandleMessageUsecase) SetHomeAssistantUsecase(homeUC *HomeAssistantUsecase) {
+	uc.homeUC = homeUC
+}
+
+// SetEmailUsecase enables the #email command.
+func (uc *HandleMessageUsecase) SetEmailUsecase(emailUC *SetEmailUsecase) {
+	uc.emailUC = emailUC
+}
+
+// SetCalendarUsecase enables the #kalender command.
+func (uc *HandleMessageUsecase) SetCalendarUsecase(calendarUC *CalendarUsecase) {
+	uc.calendarUC = calendarUC
+}
+
+// SetOnboardingUsecase enables the #join command. Its questions are answered
+// through the conversations set with SetConversations.
+func (uc *HandleMessageUsecase) SetOnboardingUsecase(onboardingUC *OnboardingUsecase) {
+	uc.onboardingUC = onboardingUC
+}
+
+// SetConversations routes private messages to multi-message flows such as
+// #join while a member is in one.
+func (uc *HandleMessageUsecase) SetConversations(conversations *ConversationManager) {
+	uc.conversations = conversations
+}
+
+// SetUserIDHasher looks up members named in commands, e.g. "#admin set
+// @628...", by their hashed user ID.
+func (uc *HandleMessageUsecase) SetUserIDHasher(hasher *phone.Hasher) {
+	uc.hasher = hasher
+}
+
+// InConversation reports whether the user is in the middle of a flow. Their
+// private messages must reach the bot even when other private chats are
+// ignored.
+func (uc *HandleMessageUsecase) InConversation(ctx context.Context, userID string) bool {
+	if uc.conversations == nil {
+		return false
 	}
+	conv, err := uc.conversations.Active(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load conversation of %s: %v", userID, err)
+		return false
+	}
+	return conv != nil
+}
 
-	// Handle #leaderboard
-	if strings.HasPrefix(strings.ToLower(msg), "#leaderboard") {
-		return uc.leaderboardUC.Execute(ctx)
+// InPrivateMode reports whether the user turned on #privat. They may report
+// in a private chat, so nobody in the group sees their #lapor.
+func (uc *HandleMessageUsecase) InPrivateMode(ctx context.Context, userID string) bool {
+	if uc.privateUC == nil {
+		return false
 	}
+	return uc.privateUC.Enabled(ctx, userID)
+}
+
+// SetAdmins sets the user IDs (phone numbers) allowed to run admin commands.
+func (uc *HandleMessageUsecase) SetAdmins(userIDs []string) {
+	uc.admins = make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		uc.admins[id] = true
+	}
+}
 
-	return "", nil
+// IsAdmin reports whether the user may run admin commands.
+func (uc *HandleMessageUsecase) IsAdmin(userID string) bool {
+	return uc.admins[userID]
+}
+
+// ExecuteReply routes a message to the first command that matches it. Admin
+// commands answer non-admins with a refusal; messages no command matches get
+// a typo suggestion or an empty reply.
+func (uc *HandleMessageUsecase) ExecuteReply(ctx context.Context, userID, name, message string) (*Reply, error) {
+	return uc.executeReply(ctx, "", userID, name, message)
+}
+
+func (uc *HandleMessageUsecase) executeReply(ctx context.Context, chatJID, userID, name, message string) (*Reply, error) {
+	msg := strings.TrimSpace(uc.resolveAlias(message))
+	lower := strings.ToLower(msg)
+
+	cmd := uc.findCommand(lower)
+	if cmd == nil {
+		return &Reply{Text: uc.suggestCommand(userID, lower)}, nil
+	}
+	if cmd.Permissions() == PermissionAdmin && !uc.IsAdmin(userID) {
+		return &Reply{Text: "Perintah ini khusus admin."}, nil
+	}
+
+	args := strings.Fields(lower)
+	if len(args) > 0 {
+		args = args[1:]
+	}
+	return cmd.Execute(ctx, CommandRequest{ChatID: chatJID, UserID: userID, Name: name, Message: msg, Args: args})
+}
+
+// IsReport reports whether a message in chatJID is a #lapor, with the
+// chat's prefix and the aliases, without running it.
+func (uc *HandleMessageUsecase) IsReport(chatJID, message string) bool {
+	msg := strings.TrimSpace(message)
+	if prefix := uc.prefixFor(chatJID); prefix != canonicalPrefix {
+		switch {
+		case strings.HasPrefix(msg, prefix):
+			msg = canonicalPrefix + msg[len(prefix):]
+		case strings.HasPrefix(msg, canonicalPrefix):
+			return false
+		}
+	}
+	cmd := uc.findCommand(strings.ToLower(strings.TrimSpace(uc.resolveAlias(msg))))
+	return cmd != nil && cmd.Help().Name == "lapor"
+}
+
+// Execute routes a message like ExecuteReply and returns only the text, e.g.
+// the caption of a #grafik reply.
+func (uc *HandleMessageUsecase) Execute(ctx context.Context, userID, name, message string) (string, error) {
+	reply, err := uc.ExecuteReply(ctx, userID, name, message)
+	if err != nil {
+		return "", err
+	}
+	return reply.Text, nil
 }
@@ -2,7 +2,10 @@ package usecase_test
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
@@ -57,6 +60,15 @@ func (m *mockReportRepo) UpsertReport(ctx context.Context, report *domain.Report
 	return nil
 }
 
+func (m *mockReportRepo) SubmitReport(ctx context.Context, userID, name string, at time.Time) (*domain.Report, error) {
+	report, err := domain.ApplyReport(m.reports[userID], userID, name, at)
+	if err != nil {
+		return nil, err
+	}
+	m.reports[userID] = report
+	return report, nil
+}
+
 func (m *mockReportRepo) GetAllReports(ctx context.Context) ([]*domain.Report, error) {
 	var result []*domain.Report
 	for _, r := range m.reports {
@@ -65,6 +77,19 @@ func (m *mockReportRepo) GetAllReports(ctx context.Context) ([]*domain.Report, e
 	return result, nil
 }
 
+func (m *mockReportRepo) ListReports(ctx context.Context, query domain.ReportQuery) ([]*domain.Report, error) {
+	return m.GetAllReports(ctx)
+}
+
+func (m *mockReportRepo) DeleteReport(ctx context.Context, userID string) error {
+	delete(m.reports, userID)
+	return nil
+}
+
+func (m *mockReportRepo) DeleteLIDMappings(ctx context.Context, phone string) error {
+	return nil
+}
+
 func (m *mockReportRepo) ResolveLIDToPhone(ctx context.Context, lid string) string {
 	return lid
 }
@@ -206,7 +231,6 @@ func TestHandleMessage_UnknownCommand_ReturnsEmpty(t *testing.T) {
 		"hello",
 		"random message",
 		"#invalid",
-		"#help",
 		"lapor",       // missing #
 		"leaderboard", // missing #
 	}
@@ -267,3 +291,551 @@ func TestHandleMessage_EmptyMessage(t *testing.T) {
 		t.Errorf("Empty message should return empty string, got '%s'", result)
 	}
 }
+
+func TestHandleMessage_Aliases(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	activities := &mockActivityRepo{}
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	reportUC.SetActivityRepository(activities)
+	leaderboardUC := usecase.NewGetLeaderboardUsecase(repo)
+	handleUC := usecase.NewHandleMessageUsecase(reportUC, leaderboardUC)
+	handleUC.SetAliases(map[string]string{"Gas Pol": "#lapor", "done": ""})
+	ctx := context.Background()
+
+	result, err := handleUC.Execute(ctx, "user1", "Alice", "Sudah lari 5 km")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Laporan diterima") {
+		t.Fatalf("Expected 'sudah lari' to report, got '%s'", result)
+	}
+	if len(activities.activities) != 1 || activities.activities[0].ActivityType != "lari" || activities.activities[0].DistanceKm != 5 {
+		t.Errorf("Expected a 5 km run logged, got %+v", activities.activities)
+	}
+
+	result, err = handleUC.Execute(ctx, "user2", "Bob", "gas pol")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Laporan diterima") {
+		t.Errorf("Expected configured alias to report, got '%s'", result)
+	}
+
+	result, err = handleUC.Execute(ctx, "user1", "Alice", "klasemen")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Alice") {
+		t.Errorf("Expected 'klasemen' to show the leaderboard, got '%s'", result)
+	}
+
+	// Removed default and ordinary chat are left alone
+	for _, msg := range []string{"done", "sudah makan belum?", "klasemennya gimana"} {
+		result, err = handleUC.Execute(ctx, "user3", "Carol", msg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result != "" {
+			t.Errorf("'%s' should not trigger a command, got '%s'", msg, result)
+		}
+	}
+}
+
+func TestHandleMessage_CommandPrefix(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	handleUC.SetCommandPrefix("!")
+	handleUC.SetNoMentionUsecase(usecase.NewSetNoMentionUsecase(&mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}))
+
+	groupRepo := &mockGroupRepo{groups: make(map[string]*domain.Group)}
+	gateway := &mockGroupGateway{joined: []domain.JoinedGroup{
+		{JID: "111@g.us", Name: "Challenge Utama"},
+		{JID: "222@g.us", Name: "Lari Pagi"},
+	}}
+	groupsUC := usecase.NewManageGroupsUsecase(groupRepo, gateway, "111@g.us")
+	handleUC.SetGroupsUsecase(groupsUC)
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	// Default prefix: "!" works, "#" is plain text
+	reply, err := handleUC.ExecuteInChat(ctx, "111@g.us", "user1", "Alice", "!lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(reply.Text, "Laporan diterima") {
+		t.Fatalf("Expected !lapor to report, got '%s'", reply.Text)
+	}
+	reply, err = handleUC.ExecuteInChat(ctx, "111@g.us", "user2", "Bob", "#lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reply.Text != "" || repo.reports["user2"] != nil {
+		t.Errorf("Expected #lapor ignored when the prefix is !, got '%s'", reply.Text)
+	}
+
+	// Per-group override, and replies mention commands with the group's prefix
+	reply, err = handleUC.ExecuteInChat(ctx, "111@g.us", "admin1", "Admin", "!admin prefix 2 /")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(reply.Text, "/lapor") || groupsUC.CommandPrefix("222@g.us") != "/" || groupRepo.groups["222@g.us"].Prefix != "/" {
+		t.Fatalf("Expected group 2 to use /, got '%s'", reply.Text)
+	}
+	reply, err = handleUC.ExecuteInChat(ctx, "222@g.us", "user1", "Alice", "/lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(reply.Text, "sudah laporan hari ini") {
+		t.Errorf("Expected /lapor routed in group 2, got '%s'", reply.Text)
+	}
+	reply, err = handleUC.ExecuteInChat(ctx, "222@g.us", "admin1", "Admin", "/admin")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(reply.Text, "/admin groups") || containsSubstring(reply.Text, "#admin") {
+		t.Errorf("Expected help written with /, got '%s'", reply.Text)
+	}
+	// Command names with a dash are rewritten whole
+	reply, err = handleUC.ExecuteInChat(ctx, "222@g.us", "user1", "Alice", "/jangan-tag")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(reply.Text, "/jangan-tag off") || containsSubstring(reply.Text, "#jangan") {
+		t.Errorf("Expected /jangan-tag in the reply, got '%s'", reply.Text)
+	}
+
+	reply, err = handleUC.ExecuteInChat(ctx, "111@g.us", "admin1", "Admin", "!admin prefix 2 abc")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if groupsUC.CommandPrefix("222@g.us") != "/" {
+		t.Errorf("Letters must be rejected as prefix, got '%s'", reply.Text)
+	}
+}
+
+func TestHandleMessage_SuggestsMistypedCommand(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	handleUC.SetBotStatsUsecase(usecase.NewGetBotStatsUsecase(nil))
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	// Off by default
+	if result, _ := handleUC.Execute(ctx, "user1", "Alice", "#lapr"); result != "" {
+		t.Errorf("Expected no suggestion while disabled, got '%s'", result)
+	}
+
+	handleUC.SetSuggestions(true)
+	cases := map[string]string{
+		"#lapr":              "Maksud kamu #lapor?",
+		"#leaderbord":        "Maksud kamu #leaderboard?",
+		"#LEADERBORD minggu": "Maksud kamu #leaderboard?",
+		"#semangat":          "",
+		"#bootstats":         "", // admin-only command is not suggested to members
+		"lapr":               "",
+	}
+	for msg, expected := range cases {
+		result, err := handleUC.Execute(ctx, "user1", "Alice", msg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if (expected == "" && result != "") || !containsSubstring(result, expected) {
+			t.Errorf("'%s': expected '%s', got '%s'", msg, expected, result)
+		}
+	}
+
+	result, err := handleUC.Execute(ctx, "admin1", "Admin", "#bootstats")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Maksud kamu #botstats?") {
+		t.Errorf("Expected admin suggestion, got '%s'", result)
+	}
+}
+
+func TestHandleMessage_HelpShowsOnlyAllowedCommands(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	handleUC.SetBotStatsUsecase(usecase.NewGetBotStatsUsecase(nil))
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	member, err := handleUC.Execute(ctx, "user1", "Alice", "#help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{"#lapor", "#leaderboard", "#help"} {
+		if !containsSubstring(member, want) {
+			t.Errorf("Expected '%s' in member help, got '%s'", want, member)
+		}
+	}
+	// Admin commands and commands that are not wired stay hidden
+	for _, hidden := range []string{"#botstats", "#admin", "Khusus admin", "#stats", "#grafik"} {
+		if containsSubstring(member, hidden) {
+			t.Errorf("Did not expect '%s' in member help, got '%s'", hidden, member)
+		}
+	}
+
+	admin, err := handleUC.Execute(ctx, "admin1", "Admin", "#help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(admin, "Khusus admin") || !containsSubstring(admin, "#botstats") {
+		t.Errorf("Expected admin section in admin help, got '%s'", admin)
+	}
+	if containsSubstring(admin, "#admin") {
+		t.Errorf("Did not expect #admin without a groups usecase, got '%s'", admin)
+	}
+}
+
+func TestHandleMessage_LeaderboardStylePerGroup(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	groupRepo := &mockGroupRepo{groups: make(map[string]*domain.Group)}
+	gateway := &mockGroupGateway{joined: []domain.JoinedGroup{
+		{JID: "111@g.us", Name: "Challenge Utama"},
+		{JID: "222@g.us", Name: "Lari Pagi"},
+	}}
+	groupsUC := usecase.NewManageGroupsUsecase(groupRepo, gateway, "111@g.us")
+	handleUC.SetGroupsUsecase(groupsUC)
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	now := time.Now()
+	for i := 0; i < 12; i++ {
+		last := now
+		if i == 1 {
+			last = now.AddDate(0, 0, -5) // lapsed, ranked second
+		}
+		id := fmt.Sprintf("user%02d", i)
+		repo.reports[id] = &domain.Report{UserID: id, Name: fmt.Sprintf("Member%02d", i), Streak: 3, ActivityCount: 30 - i, LastReportDate: last}
+	}
+
+	reply, err := handleUC.ExecuteInChat(ctx, "111@g.us", "admin1", "Admin", "#admin style 2 semua on")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if groupRepo.groups["222@g.us"].LeaderboardStyle != "podium,pemisah,rata" {
+		t.Fatalf("Expected every extra stored, got '%s' (%s)", groupRepo.groups["222@g.us"].LeaderboardStyle, reply.Text)
+	}
+
+	reply, _ = handleUC.ExecuteInChat(ctx, "222@g.us", "user00", "Member00", "#leaderboard")
+	board := reply.Text
+	for _, want := range []string{"🥇 Member00 – 3🔥", "🥉 Member02", "04. Member03", "12. Member11", "┈ 💔 streak putus ┈┈┈┈┈\n🥈 Member01 – 0💔 (total 29)"} {
+		if !containsSubstring(board, want) {
+			t.Errorf("Expected '%s' in the styled leaderboard, got '%s'", want, board)
+		}
+	}
+	if indexOf(board, "Member11") > indexOf(board, "streak putus") {
+		t.Errorf("Expected the active members above the divider, got '%s'", board)
+	}
+
+	// The other group keeps the plain list, and extras can be turned off
+	reply, _ = handleUC.ExecuteInChat(ctx, "111@g.us", "user00", "Member00", "#top 3")
+	if !containsSubstring(reply.Text, "1. Member00") || !containsSubstring(reply.Text, "2. Member01") {
+		t.Errorf("Expected the plain list in group 1, got '%s'", reply.Text)
+	}
+	_, _ = handleUC.ExecuteInChat(ctx, "111@g.us", "admin1", "Admin", "#admin style 2 pemisah off")
+	if style := groupsUC.LeaderboardStyle("222@g.us"); !style.Podium || style.Divider || !style.Aligned {
+		t.Errorf("Expected only the divider off, got %+v", style)
+	}
+
+	reply, _ = handleUC.ExecuteInChat(ctx, "111@g.us", "admin1", "Admin", "#admin style 2 pelangi on")
+	if !strings.HasPrefix(reply.Text, "Format: #admin style") {
+		t.Errorf("Expected the usage for an unknown extra, got '%s'", reply.Text)
+	}
+}
+
+func TestHandleMessage_UnknownCommandHint(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+
+	groupRepo := &mockGroupRepo{groups: make(map[string]*domain.Group)}
+	gateway := &mockGroupGateway{joined: []domain.JoinedGroup{
+		{JID: "111@g.us", Name: "Challenge Utama"},
+		{JID: "222@g.us", Name: "Lari Pagi"},
+	}}
+	groupsUC := usecase.NewManageGroupsUsecase(groupRepo, gateway, "111@g.us")
+	handleUC.SetGroupsUsecase(groupsUC)
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	// Off by default
+	reply, err := handleUC.ExecuteInChat(ctx, "222@g.us", "user1", "Alice", "#semangat")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reply.Text != "" {
+		t.Errorf("Expected unknown command ignored by default, got '%s'", reply.Text)
+	}
+
+	reply, err = handleUC.ExecuteInChat(ctx, "111@g.us", "admin1", "Admin", "#admin hint 2 on")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !groupsUC.CommandHint("222@g.us") || !groupRepo.groups["222@g.us"].CommandHint {
+		t.Fatalf("Expected hints on in group 2, got '%s'", reply.Text)
+	}
+
+	cases := map[string]string{
+		"#semangat":        "Ketik #help",
+		"#lapor":           "Laporan diterima",
+		"semangat pagi":    "",
+		"#":                "",
+		"# 1 dulu ya":      "",
+		"#123":             "",
+		"  #Semangat pagi": "Ketik #help",
+	}
+	for msg, expected := range cases {
+		reply, err := handleUC.ExecuteInChat(ctx, "222@g.us", "user1", "Alice", msg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if (expected == "" && reply.Text != "") || !containsSubstring(reply.Text, expected) {
+			t.Errorf("'%s': expected '%s', got '%s'", msg, expected, reply.Text)
+		}
+	}
+
+	// Other groups keep ignoring unknown commands
+	reply, err = handleUC.ExecuteInChat(ctx, "111@g.us", "user1", "Alice", "#semangat")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reply.Text != "" {
+		t.Errorf("Expected no hint in group 1, got '%s'", reply.Text)
+	}
+}
+
+// quoteCommand is a command from outside the usecase package
+type quoteCommand struct {
+	permission usecase.Permission
+}
+
+func (c quoteCommand) Match(lower string) bool {
+	return lower == "#quote" || strings.HasPrefix(lower, "#quote ")
+}
+
+func (c quoteCommand) Execute(ctx context.Context, req usecase.CommandRequest) (*usecase.Reply, error) {
+	return &usecase.Reply{Text: "Semangat, " + req.Name + "! #lapor setelah olahraga ya"}, nil
+}
+
+func (c quoteCommand) Help() usecase.CommandHelp {
+	return usecase.CommandHelp{Name: "quote", Description: "kata-kata penyemangat"}
+}
+
+func (c quoteCommand) Permissions() usecase.Permission {
+	return c.permission
+}
+
+func TestHandleMessage_RegisteredCommand(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	if err := handleUC.RegisterCommand(quoteCommand{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := handleUC.RegisterCommand(quoteCommand{}); err == nil {
+		t.Error("Expected an error for a second #quote")
+	}
+	handleUC.SetSuggestions(true)
+	ctx := context.Background()
+
+	result, err := handleUC.Execute(ctx, "user1", "Alice", "#Quote")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "Semangat, Alice! #lapor setelah olahraga ya" {
+		t.Errorf("Expected the registered command to answer, got '%s'", result)
+	}
+
+	help, _ := handleUC.Execute(ctx, "user1", "Alice", "#help")
+	if !containsSubstring(help, "#quote - kata-kata penyemangat") {
+		t.Errorf("Expected the command in #help, got '%s'", help)
+	}
+	if hint, _ := handleUC.Execute(ctx, "user1", "Alice", "#quot"); !containsSubstring(hint, "Maksud kamu #quote?") {
+		t.Errorf("Expected a suggestion for the registered command, got '%s'", hint)
+	}
+
+	handleUC.SetCommandPrefix("!")
+	reply, err := handleUC.ExecuteInChat(ctx, "111@g.us", "user1", "Alice", "!quote")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reply.Text != "Semangat, Alice! !lapor setelah olahraga ya" {
+		t.Errorf("Expected the chat prefix in the reply, got '%s'", reply.Text)
+	}
+}
+
+func TestHandleMessage_RegisteredAdminCommand(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	if err := handleUC.RegisterCommand(quoteCommand{permission: usecase.PermissionAdmin}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	if result, _ := handleUC.Execute(ctx, "user1", "Alice", "#quote"); result != "Perintah ini khusus admin." {
+		t.Errorf("Expected members to be refused, got '%s'", result)
+	}
+	if help, _ := handleUC.Execute(ctx, "user1", "Alice", "#help"); containsSubstring(help, "#quote") {
+		t.Errorf("Did not expect the admin command in member help, got '%s'", help)
+	}
+	if result, _ := handleUC.Execute(ctx, "admin1", "Admin", "#quote"); !containsSubstring(result, "Semangat, Admin!") {
+		t.Errorf("Expected admins to run the command, got '%s'", result)
+	}
+}
+
+func TestHandleMessage_AdminSetCorrectsMember(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628123456789": {UserID: "628123456789", Name: "Budi", Streak: 2, ActivityCount: 5, Version: 1},
+	}}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	handleUC.SetMemberUsecase(usecase.NewGetMemberProfileUsecase(repo, nil))
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	if result, _ := handleUC.Execute(ctx, "user1", "Alice", "#admin set @628123456789 streak=12"); result != "Perintah ini khusus admin." {
+		t.Errorf("Expected members to be refused, got '%s'", result)
+	}
+
+	result, err := handleUC.Execute(ctx, "admin1", "Admin", `#admin set @628123456789 streak=12 total=40 nama="Budi Santoso"`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Budi Santoso diperbarui: streak 12 hari, total 40 hari") {
+		t.Errorf("Expected the correction confirmed, got '%s'", result)
+	}
+	if r := repo.reports["628123456789"]; r.Streak != 12 || r.ActivityCount != 40 || r.Name != "Budi Santoso" {
+		t.Errorf("Expected the report corrected, got %+v", r)
+	}
+
+	cases := map[string]string{
+		"#admin set":                          "wajib diisi",
+		"#admin set @628123456789 streak=dua": "harus angka",
+		"#admin set @628123456789 warna=biru": "opsi tidak dikenal",
+		"#admin set @628123456789 streak=-1":  "tidak boleh negatif",
+		"#admin set @0899999999 streak=1":     "belum pernah lapor",
+	}
+	for msg, expected := range cases {
+		result, err := handleUC.Execute(ctx, "admin1", "Admin", msg)
+		if err != nil {
+			t.Fatalf("'%s': unexpected error: %v", msg, err)
+		}
+		if !containsSubstring(result, expected) {
+			t.Errorf("'%s': expected '%s', got '%s'", msg, expected, result)
+		}
+	}
+}
+
+func TestHandleMessage_IsReport(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	handleUC.SetAliases(map[string]string{"gas": "#lapor"})
+
+	for msg, want := range map[string]bool{
+		"#lapor":          true,
+		"#LAPOR lari 5km": true,
+		"gas":             true,
+		"#leaderboard":    false,
+		"mau lapor dulu":  false,
+	} {
+		if got := handleUC.IsReport("111@g.us", msg); got != want {
+			t.Errorf("IsReport(%q): expected %v, got %v", msg, want, got)
+		}
+	}
+
+	handleUC.SetCommandPrefix("!")
+	if !handleUC.IsReport("111@g.us", "!lapor") || handleUC.IsReport("111@g.us", "#lapor") {
+		t.Error("Expected only !lapor to count with the ! prefix")
+	}
+	if len(repo.reports) != 0 {
+		t.Errorf("Expected nothing reported, got %v", repo.reports)
+	}
+}
+
+func TestHandleMessage_TopCommand(t *testing.T) {
+	repo := &mockReportRepo{reports: make(map[string]*domain.Report)}
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	leaderboardUC := usecase.NewGetLeaderboardUsecase(repo)
+	leaderboardUC.SetTopSize(2)
+	handleUC := usecase.NewHandleMessageUsecase(reportUC, leaderboardUC)
+	ctx := context.Background()
+
+	now := time.Now()
+	for i, name := range []string{"Alice", "Budi", "Citra", "Dewi"} {
+		repo.reports[name] = &domain.Report{UserID: name, Name: name, Streak: 10 - i, ActivityCount: 10 - i, LastReportDate: now}
+	}
+
+	// Without a number the configured size is used
+	msg, err := handleUC.Execute(ctx, "user1", "User", "#top")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(msg, "1. Alice – 10🔥 (total 10)") || !strings.Contains(msg, "2. Budi") || strings.Contains(msg, "Citra") {
+		t.Errorf("Expected the top 2 only, got %q", msg)
+	}
+	if !strings.Contains(msg, "Sudah lapor hari ini: 4 dari 4 member") {
+		t.Errorf("Expected today's count, got %q", msg)
+	}
+
+	msg, _ = handleUC.Execute(ctx, "user1", "User", "#top 3")
+	if !strings.Contains(msg, "3. Citra") || strings.Contains(msg, "Dewi") {
+		t.Errorf("Expected the top 3, got %q", msg)
+	}
+
+	for _, bad := range []string{"#top nol", "#top 0", "#top 51"} {
+		msg, _ = handleUC.Execute(ctx, "user1", "User", bad)
+		if !strings.HasPrefix(msg, "Format: #top") {
+			t.Errorf("%s: expected the usage, got %q", bad, msg)
+		}
+	}
+}
+
+func TestHandleMessage_LeaderboardOnDate(t *testing.T) {
+	repo := &mockReportRepo{reports: map[string]*domain.Report{
+		"Alice": {UserID: "Alice", Name: "Alice", Streak: 12, ActivityCount: 12, LastReportDate: time.Now()},
+	}}
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	leaderboardUC := usecase.NewGetLeaderboardUsecase(repo)
+	handleUC := usecase.NewHandleMessageUsecase(reportUC, leaderboardUC)
+	ctx := context.Background()
+
+	msg, _ := handleUC.Execute(ctx, "user1", "User", "#leaderboard 2026-02-01")
+	if msg != "Riwayat klasemen belum tersedia." {
+		t.Errorf("Expected no history without snapshots, got %q", msg)
+	}
+
+	leaderboardUC.SetSnapshots(&mockSnapshotRepo{days: map[string][]*domain.SnapshotEntry{
+		"2026-01-31": {
+			{Day: "2026-01-31", UserID: "Budi", Name: "Budi", Rank: 1, Streak: 4, Total: 4},
+			{Day: "2026-01-31", UserID: "Alice", Name: "Alice", Rank: 2, Streak: 3, Total: 3},
+		},
+		"2026-02-01": {
+			{Day: "2026-02-01", UserID: "Alice", Name: "Alice", Rank: 1, Streak: 4, Total: 4, Reported: true},
+			{Day: "2026-02-01", UserID: "Budi", Name: "Budi", Rank: 2, Streak: 0, Total: 4},
+		},
+	}})
+	msg, err := handleUC.Execute(ctx, "user1", "User", "#leaderboard 2026-02-01")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "📜 Klasemen 01-02-2026\n\n1. ▲ Alice – 4🔥 (total 4)\n2. ▼ Budi – 0💔 (total 4)\n\nLapor hari itu: 1 dari 2 member"
+	if msg != want {
+		t.Errorf("Expected the standings of that day:\n%s\ngot:\n%s", want, msg)
+	}
+
+	msg, _ = handleUC.Execute(ctx, "user1", "User", "#leaderboard 2026-01-15")
+	if !strings.HasPrefix(msg, "Tidak ada riwayat klasemen untuk 15-01-2026") {
+		t.Errorf("Expected no snapshot for that day, got %q", msg)
+	}
+
+	msg, _ = handleUC.Execute(ctx, "user1", "User", "#leaderboard "+time.Now().Format("2006-01-02"))
+	if !strings.Contains(msg, "Update klasemen sementara") || !strings.Contains(msg, "Alice – 12🔥") {
+		t.Errorf("Expected today's live board, got %q", msg)
+	}
+
+	msg, _ = handleUC.Execute(ctx, "user1", "User", "#leaderboard "+time.Now().AddDate(0, 0, 2).Format("2006-01-02"))
+	if !strings.HasSuffix(msg, "belum lewat.") {
+		t.Errorf("Expected a future date refused, got %q", msg)
+	}
+}
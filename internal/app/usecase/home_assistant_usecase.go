@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// HomeAssistantPublisher publishes a member's own state and the Home
+// Assistant entities reading it, so members can automate on their streak
+// (a light that turns red at 8 PM while they have not reported).
+type HomeAssistantPublisher interface {
+	PublishMemberState(ctx context.Context, key string, state []byte) error
+	// AddMemberEntities announces the member's sensors through MQTT
+	// discovery, named after the member.
+	AddMemberEntities(ctx context.Context, key, name string) error
+	RemoveMemberEntities(ctx context.Context, key string) error
+}
+
+// MemberState is what a member's Home Assistant sensors read.
+type MemberState struct {
+	Date   string `json:"date"`   // YYYY-MM-DD
+	Streak int    `json:"streak"` // 0 once a day was missed
+	Total  int    `json:"total"`
+	Today  bool   `json:"today"` // reported today
+}
+
+func newMemberState(r *domain.Report, now time.Time) MemberState {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+	last := time.Date(r.LastReportDate.Year(), r.LastReportDate.Month(), r.LastReportDate.Day(), 0, 0, 0, 0, time.UTC)
+
+	state := MemberState{Date: now.Format("2006-01-02"), Total: r.ActivityCount, Today: last.Equal(today)}
+	// The stored streak only starts over at the next report
+	if state.Today || last.Equal(yesterday) {
+		state.Streak = r.Streak
+	}
+	return state
+}
+
+// HomeAssistantKey identifies a member in topics and entity IDs without
+// showing their phone number.
+func HomeAssistantKey(userID string) string {
+	sum := sha256.Sum256([]byte("lapor-bot/homeassistant:" + userID))
+	return hex.EncodeToString(sum[:6])
+}
+
+// HomeAssistantUsecase handles #homeassistant, which adds the member's
+// sensors to the Home Assistant instances connected to the MQTT broker.
+// The entities are retained on the broker, so nothing is stored.
+type HomeAssistantUsecase struct {
+	repo      domain.ReportRepository
+	publisher HomeAssistantPublisher
+}
+
+func NewHomeAssistantUsecase(repo domain.ReportRepository, publisher HomeAssistantPublisher) *HomeAssistantUsecase {
+	return &HomeAssistantUsecase{repo: repo, publisher: publisher}
+}
+
+// Execute handles "#homeassistant" or "#homeassistant on" to add the
+// sensors and "#homeassistant off" to remove them.
+func (uc *HomeAssistantUsecase) Execute(ctx context.Context, userID, name string, args []string) (string, error) {
+	key := HomeAssistantKey(userID)
+	enable := true
+	if len(args) > 0 {
+		switch args[0] {
+		case "on", "ya", "aktif":
+		case "off", "tidak", "mati":
+			enable = false
+		default:
+			return "Format: #homeassistant [on | off]", nil
+		}
+	}
+
+	if !enable {
+		if err := uc.publisher.RemoveMemberEntities(ctx, key); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Sensor Home Assistant %s sudah dihapus.", name), nil
+	}
+
+	// Sensors show the state right away, also before the first #lapor
+	report, err := uc.repo.GetReport(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if report == nil {
+		report = &domain.Report{UserID: userID, Name: name}
+	}
+	data, err := json.Marshal(newMemberState(report, time.Now()))
+	if err != nil {
+		return "", err
+	}
+	if err := uc.publisher.PublishMemberState(ctx, key, data); err != nil {
+		return "", err
+	}
+	if err := uc.publisher.AddMemberEntities(ctx, key, name); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("🏠 Sensor Home Assistant %s siap! Perangkat *Lapor Bot %s* berisi *Streak* dan *Lapor hari ini* muncul otomatis di Home Assistant yang tersambung ke broker MQTT komunitas.\n\n"+
+		"Contoh otomasi: jam 20.00, jika *Lapor hari ini* masih off, nyalakan lampu merah.\n\n"+
+		"Ketik #homeassistant off untuk menghapusnya.", name, name), nil
+}
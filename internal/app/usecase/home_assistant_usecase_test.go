@@ -0,0 +1,125 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockHomeAssistant struct {
+	states   map[string][]byte
+	entities map[string]string
+}
+
+func newMockHomeAssistant() *mockHomeAssistant {
+	return &mockHomeAssistant{states: make(map[string][]byte), entities: make(map[string]string)}
+}
+
+func (m *mockHomeAssistant) PublishMemberState(ctx context.Context, key string, state []byte) error {
+	m.states[key] = state
+	return nil
+}
+
+func (m *mockHomeAssistant) AddMemberEntities(ctx context.Context, key, name string) error {
+	m.entities[key] = name
+	return nil
+}
+
+func (m *mockHomeAssistant) RemoveMemberEntities(ctx context.Context, key string) error {
+	delete(m.entities, key)
+	return nil
+}
+
+func (m *mockHomeAssistant) state(t *testing.T, userID string) usecase.MemberState {
+	t.Helper()
+	var state usecase.MemberState
+	data, ok := m.states[usecase.HomeAssistantKey(userID)]
+	if !ok {
+		t.Fatalf("Expected a state published for %s", userID)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("Expected JSON, got %v", err)
+	}
+	return state
+}
+
+// =============================================================================
+// HOME ASSISTANT TESTS
+// =============================================================================
+
+func TestHomeAssistant_AddAndRemove(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 5, ActivityCount: 9, LastReportDate: time.Now().AddDate(0, 0, -1)},
+	}}
+	ha := newMockHomeAssistant()
+	uc := usecase.NewHomeAssistantUsecase(repo, ha)
+	ctx := context.Background()
+	key := usecase.HomeAssistantKey("user1")
+
+	msg, err := uc.Execute(ctx, "user1", "Alice", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ha.entities[key] != "Alice" || !strings.Contains(msg, "Lapor Bot Alice") {
+		t.Errorf("Expected Alice's entities added, got %v: %s", ha.entities, msg)
+	}
+	if state := ha.state(t, "user1"); state.Streak != 5 || state.Today {
+		t.Errorf("Expected the current state published right away, got %+v", state)
+	}
+	if strings.Contains(key, "user1") {
+		t.Errorf("Expected the key to hide the user ID, got %s", key)
+	}
+
+	if _, err := uc.Execute(ctx, "user1", "Alice", []string{"off"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := ha.entities[key]; ok {
+		t.Error("Expected the entities removed")
+	}
+
+	msg, _ = uc.Execute(ctx, "user1", "Alice", []string{"maybe"})
+	if !strings.Contains(msg, "Format") {
+		t.Errorf("Expected the usage, got %s", msg)
+	}
+}
+
+func TestHomeAssistant_NewMember(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	ha := newMockHomeAssistant()
+	uc := usecase.NewHomeAssistantUsecase(repo, ha)
+
+	if _, err := uc.Execute(context.Background(), "user1", "Budi", []string{"on"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if state := ha.state(t, "user1"); state.Streak != 0 || state.Today || state.Total != 0 {
+		t.Errorf("Expected an empty state before the first #lapor, got %+v", state)
+	}
+}
+
+func TestHomeAssistant_RefreshPublishesMembers(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 5, ActivityCount: 9, LastReportDate: now},
+		"user2": {UserID: "user2", Name: "Bob", Streak: 8, ActivityCount: 12, LastReportDate: now.AddDate(0, 0, -2)},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{"user1": {UserID: "user1", Private: true}}}
+	ha := newMockHomeAssistant()
+	uc := usecase.NewPublishStateUsecase(repo, &mockStatePublisher{})
+	uc.SetSettingsRepository(settings)
+	uc.SetHomeAssistantPublisher(ha)
+
+	if err := uc.Refresh(context.Background(), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if state := ha.state(t, "user1"); state.Streak != 5 || !state.Today {
+		t.Errorf("Expected Alice's own state also in private mode, got %+v", state)
+	}
+	if state := ha.state(t, "user2"); state.Streak != 0 || state.Today || state.Total != 12 {
+		t.Errorf("Expected Bob's streak lost, got %+v", state)
+	}
+}
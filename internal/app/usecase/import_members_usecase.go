@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+)
+
+// ImportResult summarizes a member import.
+type ImportResult struct {
+	Imported int      `json:"imported"`
+	Skipped  int      `json:"skipped"` // already had reports, left unchanged
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// ImportMembersUsecase pre-registers members from a CSV export of the
+// spreadsheet a group used before the bot, with the columns
+// phone,name[,streak[,total]]. A header row is optional.
+//
+// Imported members count as joined, so the verification gate does not ask
+// them for #join. A streak or total becomes their report, as if they last
+// reported yesterday, so the next #lapor continues the streak. Members who
+// already have reports keep them.
+type ImportMembersUsecase struct {
+	repo     domain.ReportRepository
+	settings domain.SettingsRepository
+	events   domain.ReportEventRepository
+	hasher   *phone.Hasher
+}
+
+func NewImportMembersUsecase(repo domain.ReportRepository, settings domain.SettingsRepository) *ImportMembersUsecase {
+	return &ImportMembersUsecase{repo: repo, settings: settings}
+}
+
+// SetEventRepository records imported counts in the report event stream.
+func (uc *ImportMembersUsecase) SetEventRepository(events domain.ReportEventRepository) {
+	uc.events = events
+}
+
+// SetUserIDHasher stores the imported numbers as hashed user IDs.
+func (uc *ImportMembersUsecase) SetUserIDHasher(hasher *phone.Hasher) {
+	uc.hasher = hasher
+}
+
+// Import reads the CSV and registers every valid row. Invalid rows are
+// listed in the result and do not stop the import.
+func (uc *ImportMembersUsecase) Import(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	result := &ImportResult{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		member, err := parseImportRecord(record)
+		if err != nil {
+			// A header has no digits in the phone column
+			if line == 1 && !strings.ContainsAny(record[0], "0123456789") {
+				continue
+			}
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		member.UserID = uc.hasher.UserID(member.UserID)
+
+		imported, err := uc.importMember(ctx, member)
+		if err != nil {
+			return result, fmt.Errorf("line %d: %w", line, err)
+		}
+		if imported {
+			result.Imported++
+		} else {
+			result.Skipped++
+		}
+	}
+	return result, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseImportRecord validates one row. The returned report has zero counts
+// when the row has no streak or total.
+func parseImportRecord(record []string) (*domain.Report, error) {
+	if len(record) < 2 || len(record) > 4 {
+		return nil, errors.New("expected phone,name[,streak[,total]]")
+	}
+
+	raw := strings.TrimSpace(record[0])
+	// Spreadsheets often keep local numbers, e.g. 0812...
+	if strings.HasPrefix(raw, "0") {
+		raw = "62" + raw[1:]
+	}
+	userID, err := phone.Normalize(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(record[1])
+	if name == "" || utf8.RuneCountInString(name) > maxDisplayNameLength {
+		return nil, fmt.Errorf("name must be 1-%d characters", maxDisplayNameLength)
+	}
+
+	member := &domain.Report{UserID: userID, Name: name}
+	if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+		if member.Streak, err = strconv.Atoi(strings.TrimSpace(record[2])); err != nil || member.Streak < 0 {
+			return nil, errors.New("streak must be a number of days")
+		}
+	}
+	member.ActivityCount = member.Streak
+	if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+		if member.ActivityCount, err = strconv.Atoi(strings.TrimSpace(record[3])); err != nil || member.ActivityCount < member.Streak {
+			return nil, errors.New("total must be a number of days, at least the streak")
+		}
+	}
+	return member, nil
+}
+
+// importMember saves the name and, for members without reports, the
+// starting counts. It returns false when existing reports were kept.
+func (uc *ImportMembersUsecase) importMember(ctx context.Context, member *domain.Report) (bool, error) {
+	existing, err := uc.repo.GetReport(ctx, member.UserID)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return false, nil
+	}
+
+	settings, err := uc.settings.GetSettings(ctx, member.UserID)
+	if err != nil {
+		return false, err
+	}
+	if settings == nil {
+		settings = &domain.UserSettings{UserID: member.UserID}
+	}
+	settings.DisplayName = member.Name
+	if settings.JoinedAt.IsZero() {
+		settings.JoinedAt = time.Now()
+	}
+	if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+		return false, err
+	}
+
+	if member.ActivityCount == 0 {
+		return true, nil
+	}
+	// Yesterday keeps the streak going; without a streak, the day before
+	// so the next report starts a new one
+	now := time.Now()
+	member.LastReportDate = time.Date(now.Year(), now.Month(), now.Day()-1, 12, 0, 0, 0, now.Location())
+	if member.Streak == 0 {
+		member.LastReportDate = member.LastReportDate.AddDate(0, 0, -1)
+	}
+	if err := uc.repo.UpsertReport(ctx, member); err != nil {
+		return false, err
+	}
+	return true, recordAdjusted(ctx, uc.events, member, "import")
+}
@@ -0,0 +1,110 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+)
+
+func TestImportMembers_FromCSV(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628444444": {UserID: "628444444", Name: "Dewi", Streak: 9, ActivityCount: 9, LastReportDate: time.Now()},
+	}}
+	settingsRepo := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+	uc := usecase.NewImportMembersUsecase(repo, settingsRepo)
+
+	csv := "phone,name,streak,total\n" +
+		"0811111111,Ani,5,12\n" +
+		"+62 822-2222-222,Budi\n" +
+		"628333333,Citra,0,4\n" +
+		"628444444,Dewi Baru,1,1\n" +
+		"abc,Eko\n" +
+		"628555555,Fajar,7,3\n" +
+		"\n"
+	result, err := uc.Import(context.Background(), strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Imported != 3 || result.Skipped != 1 || len(result.Errors) != 2 {
+		t.Fatalf("Expected 3 imported, 1 skipped, 2 errors, got %+v", result)
+	}
+	if !strings.HasPrefix(result.Errors[0], "line 6:") || !strings.HasPrefix(result.Errors[1], "line 7:") {
+		t.Errorf("Expected errors on lines 6 and 7, got %v", result.Errors)
+	}
+
+	// Local numbers become 62..., the starting streak continues with the next #lapor
+	ani := repo.reports["62811111111"]
+	if ani == nil || ani.Name != "Ani" || ani.Streak != 5 || ani.ActivityCount != 12 {
+		t.Fatalf("Expected Ani with streak 5 of 12 days, got %+v", ani)
+	}
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	if _, err := reportUC.Execute(context.Background(), "62811111111", "Ani"); err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	ani = repo.reports["62811111111"]
+	if ani.Streak != 6 || ani.ActivityCount != 13 {
+		t.Errorf("Expected streak 6 of 13 days after #lapor, got %d of %d", ani.Streak, ani.ActivityCount)
+	}
+
+	// Name only: registered as joined, but no report yet
+	if _, ok := repo.reports["628222222222"]; ok {
+		t.Error("Expected no report for a member without counts")
+	}
+	budi := settingsRepo.settings["628222222222"]
+	if budi == nil || budi.DisplayName != "Budi" || budi.JoinedAt.IsZero() {
+		t.Errorf("Expected Budi registered with display name, got %+v", budi)
+	}
+
+	if citra := repo.reports["628333333"]; citra == nil || citra.Streak != 0 || citra.ActivityCount != 4 {
+		t.Errorf("Expected Citra with 4 days and no streak, got %+v", citra)
+	}
+	if dewi := repo.reports["628444444"]; dewi.Name != "Dewi" || dewi.Streak != 9 {
+		t.Errorf("Expected existing report kept, got %+v", dewi)
+	}
+}
+
+func TestImportMembers_HashedUserIDs(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	settingsRepo := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+	hasher := phone.NewHasher("rahasia")
+	uc := usecase.NewImportMembersUsecase(repo, settingsRepo)
+	uc.SetUserIDHasher(hasher)
+
+	if _, err := uc.Import(context.Background(), strings.NewReader("phone,name,streak,total\n0811111111,Ani,5,12\n")); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	userID := hasher.UserID("62811111111")
+	if !phone.IsHashed(userID) || hasher.UserID(userID) != userID || phone.NewHasher("lain").UserID("62811111111") == userID {
+		t.Fatalf("Expected a stable salted hash, got %s", userID)
+	}
+	if _, ok := repo.reports["62811111111"]; ok || repo.reports[userID] == nil {
+		t.Fatalf("Expected Ani stored under her hashed ID only, got %v", repo.reports)
+	}
+
+	// Her next #lapor arrives with the same hashed ID and continues the streak
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	if _, err := reportUC.Execute(context.Background(), userID, "Ani"); err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if ani := repo.reports[userID]; ani.Streak != 6 || ani.ActivityCount != 13 {
+		t.Errorf("Expected streak 6 of 13 days after #lapor, got %d of %d", ani.Streak, ani.ActivityCount)
+	}
+
+	// Admins still name members by their number
+	handleUC := usecase.NewHandleMessageUsecase(reportUC, usecase.NewGetLeaderboardUsecase(repo))
+	handleUC.SetMemberUsecase(usecase.NewGetMemberProfileUsecase(repo, &mockActivityRepo{}))
+	handleUC.SetUserIDHasher(hasher)
+	handleUC.SetAdmins([]string{hasher.UserID("628999")})
+	result, err := handleUC.Execute(context.Background(), hasher.UserID("628999"), "Admin", "#admin set @62811111111 streak=2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "streak 2 hari") || repo.reports[userID].Streak != 2 {
+		t.Errorf("Expected Ani's streak corrected, got '%s'", result)
+	}
+}
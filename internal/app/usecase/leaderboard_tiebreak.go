@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// TieBreak decides the order of members with the same number of days
+// reported on the leaderboard.
+type TieBreak string
+
+const (
+	// TieBreakLastReport ranks the earlier last report first: that member
+	// reached the total first.
+	TieBreakLastReport TieBreak = "last_report"
+	// TieBreakBestStreak ranks the longer best streak first.
+	TieBreakBestStreak TieBreak = "best_streak"
+	// TieBreakName ranks alphabetically.
+	TieBreakName TieBreak = "name"
+)
+
+// DefaultTieBreaks is the order of the tie-breaks without
+// LEADERBOARD_TIEBREAK. Members still tied after them are ranked by user
+// ID, so the same board always comes out the same.
+var DefaultTieBreaks = []TieBreak{TieBreakLastReport, TieBreakBestStreak, TieBreakName}
+
+// ParseTieBreaks reads a comma-separated list of tie-breaks, e.g.
+// "best_streak,name". Tie-breaks left out are not applied; an empty list is
+// DefaultTieBreaks.
+func ParseTieBreaks(s string) ([]TieBreak, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultTieBreaks, nil
+	}
+	var rules []TieBreak
+	seen := make(map[TieBreak]bool)
+	for _, name := range strings.Split(s, ",") {
+		rule := TieBreak(strings.TrimSpace(name))
+		switch rule {
+		case TieBreakLastReport, TieBreakBestStreak, TieBreakName:
+		default:
+			return nil, fmt.Errorf("unknown tie-break %q, expected %s, %s or %s", name, TieBreakLastReport, TieBreakBestStreak, TieBreakName)
+		}
+		if seen[rule] {
+			return nil, fmt.Errorf("tie-break %q listed twice", rule)
+		}
+		seen[rule] = true
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// compare is negative when a ranks above b, positive when b does and zero
+// when the tie-break can't tell them apart.
+func (t TieBreak) compare(a, b BoardMember) int {
+	switch t {
+	case TieBreakLastReport:
+		return a.LastReport.Compare(b.LastReport)
+	case TieBreakBestStreak:
+		return cmp.Compare(b.BestStreak, a.BestStreak)
+	case TieBreakName:
+		return strings.Compare(a.Name, b.Name)
+	}
+	return 0
+}
+
+// rankBefore reports whether a ranks above b: more days reported first,
+// then the tie-breaks in order and finally the user ID.
+func rankBefore(a, b BoardMember, tieBreaks []TieBreak) bool {
+	if a.Total != b.Total {
+		return a.Total > b.Total
+	}
+	for _, t := range tieBreaks {
+		if c := t.compare(a, b); c != 0 {
+			return c < 0
+		}
+	}
+	return a.UserID < b.UserID
+}
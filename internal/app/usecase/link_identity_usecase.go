@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const (
+	linkCodeLength = 6
+	linkCodeTTL    = 15 * time.Minute
+	// Letters and digits that are hard to mistake for each other
+	linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+const linkUsage = "Format: #link <kode>\nMinta kodenya di platform lain (mis. bot Telegram/Discord atau integrasi Strava), lalu kirim di sini dalam 15 menit."
+
+// LinkIdentityUsecase maps accounts on other platforms to WhatsApp members,
+// so a member's reports through a bridge or Strava count for the same
+// streak. The other platform asks for a code for its account (RequestCode)
+// and shows it to the person, who sends "#link <code>" from WhatsApp to
+// prove both accounts are theirs. Only the WhatsApp number is a member;
+// linked accounts report as that member.
+type LinkIdentityUsecase struct {
+	identities domain.IdentityRepository
+	reports    domain.ReportRepository
+}
+
+func NewLinkIdentityUsecase(identities domain.IdentityRepository) *LinkIdentityUsecase {
+	return &LinkIdentityUsecase{identities: identities}
+}
+
+// SetReportRepository makes MemberName return the name a member already
+// reports under.
+func (uc *LinkIdentityUsecase) SetReportRepository(reports domain.ReportRepository) {
+	uc.reports = reports
+}
+
+// RequestCode returns a new code for an account on another platform.
+// Earlier codes of the account stop working; an existing link stays until
+// the code is used.
+func (uc *LinkIdentityUsecase) RequestCode(ctx context.Context, platform, externalID string) (string, time.Time, error) {
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	externalID = strings.TrimSpace(externalID)
+	if platform == "" || externalID == "" {
+		return "", time.Time{}, errors.New("platform and external ID must not be empty")
+	}
+
+	identity, err := uc.identities.GetIdentity(ctx, platform, externalID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if identity == nil {
+		identity = &domain.LinkedIdentity{Platform: platform, ExternalID: externalID}
+	}
+
+	identity.Code, err = linkCode()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	identity.CodeExpiresAt = time.Now().Add(linkCodeTTL)
+	if err := uc.identities.SaveIdentity(ctx, identity); err != nil {
+		return "", time.Time{}, err
+	}
+	return identity.Code, identity.CodeExpiresAt, nil
+}
+
+func linkCode() (string, error) {
+	code := make([]byte, linkCodeLength)
+	max := big.NewInt(int64(len(linkCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = linkCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// Resolve returns the member an account is linked to, or "" when it is not
+// linked.
+func (uc *LinkIdentityUsecase) Resolve(ctx context.Context, platform, externalID string) (string, error) {
+	identity, err := uc.identities.GetIdentity(ctx, strings.ToLower(strings.TrimSpace(platform)), strings.TrimSpace(externalID))
+	if err != nil || identity == nil {
+		return "", err
+	}
+	return identity.UserID, nil
+}
+
+// MemberName returns the name a linked member reports under, so their name
+// on another platform doesn't replace the one the group knows. fallback is
+// used for members without a report yet.
+func (uc *LinkIdentityUsecase) MemberName(ctx context.Context, userID, fallback string) (string, error) {
+	if uc.reports == nil {
+		return fallback, nil
+	}
+	report, err := uc.reports.GetReport(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if report == nil || report.Name == "" {
+		return fallback, nil
+	}
+	return report.Name, nil
+}
+
+// Execute handles "#link <code>", and "#link" alone, which lists the
+// member's linked accounts.
+func (uc *LinkIdentityUsecase) Execute(ctx context.Context, userID string, args []string) (string, error) {
+	if len(args) == 0 {
+		return uc.list(ctx, userID)
+	}
+
+	identity, err := uc.identities.GetIdentityByCode(ctx, strings.ToUpper(args[0]))
+	if err != nil {
+		return "", err
+	}
+	if identity == nil || time.Now().After(identity.CodeExpiresAt) {
+		return "⚠️ Kode tidak dikenal atau sudah kedaluwarsa. Minta kode baru lalu kirim dalam 15 menit.", nil
+	}
+
+	identity.UserID = userID
+	identity.Code = ""
+	identity.CodeExpiresAt = time.Time{}
+	identity.LinkedAt = time.Now()
+	if err := uc.identities.SaveIdentity(ctx, identity); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("✅ Akun %s %s sekarang terhubung. Laporan dari sana dihitung ke streak kamu.", identity.Platform, identity.ExternalID), nil
+}
+
+func (uc *LinkIdentityUsecase) list(ctx context.Context, userID string) (string, error) {
+	identities, err := uc.identities.ListIdentities(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if len(identities) == 0 {
+		return linkUsage, nil
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("Akun yang terhubung:\n")
+	for _, identity := range identities {
+		sb.WriteString(fmt.Sprintf("- %s %s (sejak %s)\n", identity.Platform, identity.ExternalID, identity.LinkedAt.Local().Format("02-01-2006")))
+	}
+	sb.WriteString("\n" + linkUsage)
+	return sb.String(), nil
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const (
+	defaultReportPageSize = 50
+	maxReportPageSize     = 200
+)
+
+// ErrInvalidCursor is returned for a cursor that was not issued by
+// ListReports or was issued for another sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+var ErrInvalidSort = errors.New("sort must be streak, total or name")
+
+// GroupMembersGateway lists the phone numbers of a group's participants.
+type GroupMembersGateway interface {
+	GroupMembers(ctx context.Context, groupJID string) ([]string, error)
+}
+
+// ReportListOptions are the filters of the reports list. Zero values mean
+// "no filter".
+type ReportListOptions struct {
+	Sort       string    // domain.ReportSortStreak, ReportSortTotal (default) or ReportSortName
+	ActiveOnly bool      // only members who still keep their streak
+	GroupJID   string    // only participants of this group
+	Since      time.Time // last report at or after
+	Until      time.Time // last report before
+	Cursor     string    // NextCursor of the previous page
+	Limit      int
+}
+
+// ReportPage is one page of the reports list. NextCursor is empty on the
+// last page.
+type ReportPage struct {
+	Reports    []*domain.Report `json:"reports"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// reportCursor is the last row of a page, enough to continue after it.
+type reportCursor struct {
+	Sort          string `json:"s"`
+	UserID        string `json:"u"`
+	Name          string `json:"n,omitempty"`
+	Streak        int    `json:"st,omitempty"`
+	ActivityCount int    `json:"c,omitempty"`
+}
+
+// ListReportsUsecase pages through all reports for dashboards, using
+// cursors so deep pages stay as fast as the first.
+type ListReportsUsecase struct {
+	repo    domain.ReportRepository
+	members GroupMembersGateway
+}
+
+func NewListReportsUsecase(repo domain.ReportRepository) *ListReportsUsecase {
+	return &ListReportsUsecase{repo: repo}
+}
+
+// SetGroupMembersGateway enables the group filter.
+func (uc *ListReportsUsecase) SetGroupMembersGateway(members GroupMembersGateway) {
+	uc.members = members
+}
+
+func (uc *ListReportsUsecase) Execute(ctx context.Context, opts ReportListOptions) (*ReportPage, error) {
+	switch opts.Sort {
+	case "":
+		opts.Sort = domain.ReportSortTotal
+	case domain.ReportSortStreak, domain.ReportSortTotal, domain.ReportSortName:
+	default:
+		return nil, ErrInvalidSort
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultReportPageSize
+	}
+	if opts.Limit > maxReportPageSize {
+		opts.Limit = maxReportPageSize
+	}
+
+	query := domain.ReportQuery{Sort: opts.Sort, Since: opts.Since, Until: opts.Until, Limit: opts.Limit + 1}
+	if opts.ActiveOnly {
+		// The streak is kept while the last report is from yesterday or today
+		now := time.Now()
+		yesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, now.Location())
+		if yesterday.After(query.Since) {
+			query.Since = yesterday
+		}
+	}
+	if opts.GroupJID != "" {
+		if uc.members == nil {
+			return nil, errors.New("group filter is not available")
+		}
+		ids, err := uc.members.GroupMembers(ctx, opts.GroupJID)
+		if err != nil {
+			return nil, err
+		}
+		query.UserIDs = append([]string{}, ids...)
+	}
+	if opts.Cursor != "" {
+		after, err := decodeReportCursor(opts.Cursor, opts.Sort)
+		if err != nil {
+			return nil, err
+		}
+		query.After = after
+	}
+
+	reports, err := uc.repo.ListReports(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra row was fetched to know whether there is a next page
+	page := &ReportPage{Reports: reports}
+	if len(reports) > opts.Limit {
+		page.Reports = reports[:opts.Limit]
+		page.NextCursor = encodeReportCursor(page.Reports[opts.Limit-1], opts.Sort)
+	}
+	if page.Reports == nil {
+		page.Reports = []*domain.Report{}
+	}
+	return page, nil
+}
+
+func encodeReportCursor(last *domain.Report, sort string) string {
+	c := reportCursor{Sort: sort, UserID: last.UserID}
+	switch sort {
+	case domain.ReportSortStreak:
+		c.Streak = last.Streak
+	case domain.ReportSortName:
+		c.Name = last.Name
+	default:
+		c.ActivityCount = last.ActivityCount
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeReportCursor(cursor, sort string) (*domain.Report, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c reportCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort || c.UserID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &domain.Report{UserID: c.UserID, Name: c.Name, Streak: c.Streak, ActivityCount: c.ActivityCount}, nil
+}
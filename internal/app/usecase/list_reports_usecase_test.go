@@ -0,0 +1,108 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockGroupMembers map[string][]string
+
+func (m mockGroupMembers) GroupMembers(ctx context.Context, groupJID string) ([]string, error) {
+	return m[groupJID], nil
+}
+
+func newListReportsRepo() *mockRepo {
+	now := time.Now()
+	return &mockRepo{reports: map[string]*domain.Report{
+		"1": {UserID: "1", Name: "Dewi", Streak: 5, ActivityCount: 9, LastReportDate: now},
+		"2": {UserID: "2", Name: "Budi", Streak: 1, ActivityCount: 7, LastReportDate: now.AddDate(0, 0, -5)},
+		"3": {UserID: "3", Name: "Ani", Streak: 7, ActivityCount: 7, LastReportDate: now.AddDate(0, 0, -1)},
+		"4": {UserID: "4", Name: "Citra", Streak: 2, ActivityCount: 3, LastReportDate: now.AddDate(0, 0, -10)},
+		"5": {UserID: "5", Name: "Eko", Streak: 4, ActivityCount: 4, LastReportDate: now},
+	}}
+}
+
+func TestListReports_PagesWithCursor(t *testing.T) {
+	uc := usecase.NewListReportsUsecase(newListReportsRepo())
+	ctx := context.Background()
+
+	var got []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("Too many pages")
+		}
+		page, err := uc.Execute(ctx, usecase.ReportListOptions{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		for _, r := range page.Reports {
+			got = append(got, r.UserID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	// Most days first, ties by user ID
+	want := []string{"1", "2", "3", "5", "4"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+
+	// A cursor only works with the sort it was issued for
+	first, _ := uc.Execute(ctx, usecase.ReportListOptions{Limit: 2})
+	if _, err := uc.Execute(ctx, usecase.ReportListOptions{Sort: domain.ReportSortName, Cursor: first.NextCursor}); !errors.Is(err, usecase.ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+	if _, err := uc.Execute(ctx, usecase.ReportListOptions{Cursor: "garbage!"}); !errors.Is(err, usecase.ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor for garbage, got %v", err)
+	}
+	if _, err := uc.Execute(ctx, usecase.ReportListOptions{Sort: "age"}); !errors.Is(err, usecase.ErrInvalidSort) {
+		t.Errorf("Expected ErrInvalidSort, got %v", err)
+	}
+}
+
+func TestListReports_Filters(t *testing.T) {
+	uc := usecase.NewListReportsUsecase(newListReportsRepo())
+	uc.SetGroupMembersGateway(mockGroupMembers{"grup@g.us": {"1", "2", "4"}})
+	ctx := context.Background()
+
+	ids := func(opts usecase.ReportListOptions) []string {
+		page, err := uc.Execute(ctx, opts)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		var ids []string
+		for _, r := range page.Reports {
+			ids = append(ids, r.UserID)
+		}
+		return ids
+	}
+
+	if got := ids(usecase.ReportListOptions{Sort: domain.ReportSortName, ActiveOnly: true}); len(got) != 3 || got[0] != "3" || got[1] != "1" || got[2] != "5" {
+		t.Errorf("Expected active members 3, 1, 5 by name, got %v", got)
+	}
+	if got := ids(usecase.ReportListOptions{Sort: domain.ReportSortStreak, GroupJID: "grup@g.us"}); len(got) != 3 || got[0] != "1" || got[1] != "4" || got[2] != "2" {
+		t.Errorf("Expected group members 1, 4, 2 by streak, got %v", got)
+	}
+	if got := ids(usecase.ReportListOptions{GroupJID: "kosong@g.us"}); len(got) != 0 {
+		t.Errorf("Expected no reports for an empty group, got %v", got)
+	}
+	since := time.Now().AddDate(0, 0, -7)
+	until := time.Now().AddDate(0, 0, -2)
+	if got := ids(usecase.ReportListOptions{Since: since, Until: until}); len(got) != 1 || got[0] != "2" {
+		t.Errorf("Expected only member 2 in date range, got %v", got)
+	}
+}
@@ -0,0 +1,508 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// GroupGateway talks to WhatsApp on behalf of the group admin commands.
+type GroupGateway interface {
+	// JoinGroup joins via an invite link, or looks up a group the bot is
+	// already in when given a JID. Returns the group JID and name.
+	JoinGroup(ctx context.Context, linkOrJID string) (jid, name string, err error)
+	JoinedGroups(ctx context.Context) ([]domain.JoinedGroup, error)
+	// LeaveGroup posts farewell to the group and leaves it.
+	LeaveGroup(ctx context.Context, jid, farewell string) error
+}
+
+// GroupStatus is a joined group and whether the bot answers commands there.
+type GroupStatus struct {
+	domain.JoinedGroup
+	Served  bool
+	Default bool // Configured via GROUP_ID, cannot be toggled
+}
+
+// ManageGroupsUsecase keeps track of which groups the bot serves and handles
+// the "#admin" group commands. GROUP_ID is always served; more groups can be
+// registered at runtime. Without GROUP_ID every group is served unless an
+// admin disabled it: a group's row only overrides the default.
+type ManageGroupsUsecase struct {
+	repo         domain.GroupRepository
+	gateway      GroupGateway
+	defaultGroup string
+	leaderboard  *GetLeaderboardUsecase
+	members      GroupMembersGateway
+
+	mu       sync.RWMutex
+	served   map[string]bool
+	prefixes map[string]string
+	hints    map[string]bool
+	styles   map[string]LeaderboardStyle
+}
+
+func NewManageGroupsUsecase(repo domain.GroupRepository, gateway GroupGateway, defaultGroup string) *ManageGroupsUsecase {
+	return &ManageGroupsUsecase{
+		repo:         repo,
+		gateway:      gateway,
+		defaultGroup: defaultGroup,
+		served:       make(map[string]bool),
+		prefixes:     make(map[string]string),
+		hints:        make(map[string]bool),
+		styles:       make(map[string]LeaderboardStyle),
+	}
+}
+
+// SetLeaderboard adds the final standings of the group's participants,
+// looked up through members, to the "#admin leave-group" farewell message.
+func (uc *ManageGroupsUsecase) SetLeaderboard(leaderboard *GetLeaderboardUsecase, members GroupMembersGateway) {
+	uc.leaderboard = leaderboard
+	uc.members = members
+}
+
+// Load reads the registered groups into memory. Call once at startup.
+func (uc *ManageGroupsUsecase) Load(ctx context.Context) error {
+	groups, err := uc.repo.GetGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	for _, g := range groups {
+		uc.served[g.JID] = g.Enabled
+		if g.Prefix != "" {
+			uc.prefixes[g.JID] = g.Prefix
+		}
+		uc.hints[g.JID] = g.CommandHint
+		uc.styles[g.JID] = ParseLeaderboardStyle(g.LeaderboardStyle)
+	}
+	return nil
+}
+
+// CommandPrefix returns the command prefix set for a group with
+// "#admin prefix", or "" to use the default.
+func (uc *ManageGroupsUsecase) CommandPrefix(chatJID string) string {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	return uc.prefixes[chatJID]
+}
+
+// CommandHint reports whether unknown commands in a group get a pointer to
+// #help, as turned on with "#admin hint".
+func (uc *ManageGroupsUsecase) CommandHint(chatJID string) bool {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	return uc.hints[chatJID]
+}
+
+// LeaderboardStyle returns the extras of #leaderboard turned on for a group
+// with "#admin style".
+func (uc *ManageGroupsUsecase) LeaderboardStyle(chatJID string) LeaderboardStyle {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	return uc.styles[chatJID]
+}
+
+// IsServed reports whether the bot should answer commands in a group chat.
+func (uc *ManageGroupsUsecase) IsServed(chatJID string) bool {
+	if chatJID == uc.defaultGroup {
+		return true
+	}
+
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	if served, ok := uc.served[chatJID]; ok {
+		return served
+	}
+	return uc.defaultGroup == ""
+}
+
+// Execute handles "#admin <subcommand>". args keep their original case
+// because invite codes are case-sensitive.
+func (uc *ManageGroupsUsecase) Execute(ctx context.Context, userID string, args []string) (string, error) {
+	if len(args) == 0 {
+		return adminHelp, nil
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "add-group":
+		if len(args) < 2 {
+			return "Format: #admin add-group <link undangan atau JID grup>", nil
+		}
+		return uc.addGroup(ctx, userID, args[1])
+	case "groups":
+		groups, err := uc.ListGroups(ctx)
+		if err != nil {
+			return "", err
+		}
+		return FormatGroupList(groups), nil
+	case "enable", "disable":
+		if len(args) < 2 {
+			return fmt.Sprintf("Format: #admin %s <nomor dari #admin groups atau JID>", strings.ToLower(args[0])), nil
+		}
+		return uc.setServed(ctx, userID, args[1], strings.ToLower(args[0]) == "enable")
+	case "leave-group":
+		if len(args) < 2 {
+			return "Format: #admin leave-group <nomor dari #admin groups atau JID>", nil
+		}
+		return uc.leaveGroup(ctx, userID, args[1])
+	case "prefix":
+		if len(args) < 3 {
+			return "Format: #admin prefix <nomor / JID> <prefix, cth: ! atau /> (atau \"default\")", nil
+		}
+		return uc.setPrefix(ctx, userID, args[1], args[2])
+	case "hint":
+		if len(args) < 3 || (!strings.EqualFold(args[2], "on") && !strings.EqualFold(args[2], "off")) {
+			return "Format: #admin hint <nomor / JID> <on / off>", nil
+		}
+		return uc.setHint(ctx, userID, args[1], strings.EqualFold(args[2], "on"))
+	case "style":
+		if len(args) < 4 || (!strings.EqualFold(args[3], "on") && !strings.EqualFold(args[3], "off")) {
+			return leaderboardStyleUsage, nil
+		}
+		return uc.setStyle(ctx, userID, args[1], strings.ToLower(args[2]), strings.EqualFold(args[3], "on"))
+	default:
+		return adminHelp, nil
+	}
+}
+
+const adminHelp = "Perintah admin:\n" +
+	"#admin add-group <link undangan / JID> - mulai melayani grup baru\n" +
+	"#admin groups - daftar grup yang diikuti bot\n" +
+	"#admin enable <nomor / JID> - layani grup\n" +
+	"#admin disable <nomor / JID> - berhenti melayani grup\n" +
+	"#admin leave-group <nomor / JID> - pamit, arsipkan klasemen, dan keluar dari grup\n" +
+	"#admin prefix <nomor / JID> <prefix> - ganti awalan perintah di grup, cth: !lapor\n" +
+	"#admin hint <nomor / JID> <on / off> - balas perintah yang tidak dikenal dengan petunjuk #help\n" +
+	"#admin style <nomor / JID> <podium / pemisah / rata> <on / off> - hiasan #leaderboard di grup"
+
+const leaderboardStyleUsage = "Format: #admin style <nomor / JID> <podium / pemisah / rata / semua> <on / off>\n" +
+	"podium: 🥇🥈🥉 untuk tiga teratas\n" +
+	"pemisah: yang streak-nya putus dipisah di bawah garis\n" +
+	"rata: nomor urut sama lebar, cth: 07. dan 12."
+
+func (uc *ManageGroupsUsecase) addGroup(ctx context.Context, userID, target string) (string, error) {
+	jid, name, err := uc.gateway.JoinGroup(ctx, target)
+	if err != nil {
+		log.Printf("Failed to join group %s: %v", target, err)
+		return fmt.Sprintf("Gagal bergabung ke grup: %v", err), nil
+	}
+
+	group, err := uc.repo.GetGroup(ctx, jid)
+	if err != nil {
+		return "", err
+	}
+	if group == nil {
+		group = &domain.Group{JID: jid, AddedBy: userID, AddedAt: time.Now()}
+	}
+	group.Name = name
+	group.Enabled = true
+	group.LeftAt = time.Time{}
+	if err := uc.repo.SaveGroup(ctx, group); err != nil {
+		return "", err
+	}
+
+	uc.mu.Lock()
+	uc.served[jid] = true
+	uc.mu.Unlock()
+
+	return fmt.Sprintf("✅ Grup \"%s\" (%s) sekarang dilayani bot.", name, jid), nil
+}
+
+// ListGroups returns every group the account is in, sorted by name, with
+// its serving state.
+func (uc *ManageGroupsUsecase) ListGroups(ctx context.Context) ([]GroupStatus, error) {
+	joined, err := uc.gateway.JoinedGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]GroupStatus, 0, len(joined))
+	for _, g := range joined {
+		statuses = append(statuses, GroupStatus{
+			JoinedGroup: g,
+			Served:      uc.IsServed(g.JID),
+			Default:     g.JID == uc.defaultGroup,
+		})
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return strings.ToLower(statuses[i].Name) < strings.ToLower(statuses[j].Name)
+	})
+	return statuses, nil
+}
+
+// FormatGroupList renders the numbered list used by "#admin groups" and the
+// "groups list" CLI. The numbers can be passed to "#admin enable/disable".
+func FormatGroupList(groups []GroupStatus) string {
+	if len(groups) == 0 {
+		return "Bot belum bergabung ke grup mana pun."
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("Grup yang diikuti bot 👥\n\n")
+	for i, g := range groups {
+		state := "⏸️ tidak dilayani"
+		if g.Served {
+			state = "✅ dilayani"
+		}
+		if g.Default {
+			state += " (GROUP_ID)"
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s – %s\n   %s, %d anggota\n", i+1, g.Name, state, g.JID, g.Participants))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// setServed enables or disables a joined group, picked by its number in
+// "#admin groups" or by JID.
+func (uc *ManageGroupsUsecase) setServed(ctx context.Context, userID, target string, enabled bool) (string, error) {
+	picked, err := uc.pickGroup(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	if picked == nil {
+		return "Grup tidak ditemukan. Cek nomor di #admin groups.", nil
+	}
+	if picked.Default {
+		return "Grup ini diatur lewat GROUP_ID dan selalu dilayani.", nil
+	}
+
+	group, err := uc.repo.GetGroup(ctx, picked.JID)
+	if err != nil {
+		return "", err
+	}
+	if group == nil {
+		group = &domain.Group{JID: picked.JID, AddedBy: userID, AddedAt: time.Now()}
+	}
+	group.Name = picked.Name
+	group.Enabled = enabled
+	if err := uc.repo.SaveGroup(ctx, group); err != nil {
+		return "", err
+	}
+
+	uc.mu.Lock()
+	uc.served[picked.JID] = enabled
+	uc.mu.Unlock()
+
+	if enabled {
+		return fmt.Sprintf("✅ Grup \"%s\" sekarang dilayani bot.", picked.Name), nil
+	}
+	return fmt.Sprintf("⏸️ Bot berhenti melayani grup \"%s\".", picked.Name), nil
+}
+
+// leaveGroup says goodbye with the group's final standings, archives them
+// with the group record, and leaves the group. Members of other groups are
+// left out of the standings.
+func (uc *ManageGroupsUsecase) leaveGroup(ctx context.Context, userID, target string) (string, error) {
+	picked, err := uc.pickGroup(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	if picked == nil {
+		return "Grup tidak ditemukan. Cek nomor di #admin groups.", nil
+	}
+	if picked.Default {
+		return "Grup ini diatur lewat GROUP_ID. Ubah GROUP_ID dulu sebelum keluar.", nil
+	}
+
+	standings := ""
+	if uc.leaderboard != nil && uc.members != nil {
+		userIDs, err := uc.members.GroupMembers(ctx, picked.JID)
+		if err != nil {
+			return "", err
+		}
+		standings, err = uc.leaderboard.ExecuteForMembers(ctx, userIDs, uc.LeaderboardStyle(picked.JID))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	farewell := "👋 Bot pamit dari grup ini. Terima kasih sudah ikut challenge, tetap semangat bergerak!"
+	if standings != "" {
+		farewell += "\n\nKlasemen akhir:\n" + standings
+	}
+	if err := uc.gateway.LeaveGroup(ctx, picked.JID, farewell); err != nil {
+		log.Printf("Failed to leave group %s: %v", picked.JID, err)
+		return fmt.Sprintf("Gagal keluar dari grup: %v", err), nil
+	}
+
+	group, err := uc.repo.GetGroup(ctx, picked.JID)
+	if err != nil {
+		return "", err
+	}
+	if group == nil {
+		group = &domain.Group{JID: picked.JID, AddedBy: userID, AddedAt: time.Now()}
+	}
+	group.Name = picked.Name
+	group.Enabled = false
+	group.LeftAt = time.Now()
+	group.FinalStandings = standings
+	if err := uc.repo.SaveGroup(ctx, group); err != nil {
+		return "", err
+	}
+
+	uc.mu.Lock()
+	uc.served[picked.JID] = false
+	uc.mu.Unlock()
+
+	return fmt.Sprintf("👋 Bot sudah keluar dari grup \"%s\". Klasemen akhir disimpan.", picked.Name), nil
+}
+
+// pickGroup finds a joined group by its number in "#admin groups" or by JID.
+// Returns nil if there is no match.
+func (uc *ManageGroupsUsecase) pickGroup(ctx context.Context, target string) (*GroupStatus, error) {
+	groups, err := uc.ListGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := strconv.Atoi(target); err == nil && n >= 1 && n <= len(groups) {
+		return &groups[n-1], nil
+	}
+	for i := range groups {
+		if groups[i].JID == target {
+			return &groups[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// setPrefix changes the command prefix of one group. "default" goes back to
+// COMMAND_PREFIX.
+func (uc *ManageGroupsUsecase) setPrefix(ctx context.Context, userID, target, prefix string) (string, error) {
+	if strings.EqualFold(prefix, "default") {
+		prefix = ""
+	} else if !validPrefix(prefix) {
+		return "Prefix harus 1-3 simbol tanpa huruf atau angka, cth: ! / . atau #", nil
+	}
+
+	picked, err := uc.pickGroup(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	if picked == nil {
+		return "Grup tidak ditemukan. Cek nomor di #admin groups.", nil
+	}
+
+	group, err := uc.repo.GetGroup(ctx, picked.JID)
+	if err != nil {
+		return "", err
+	}
+	if group == nil {
+		group = &domain.Group{JID: picked.JID, Enabled: picked.Served, AddedBy: userID, AddedAt: time.Now()}
+	}
+	group.Name = picked.Name
+	group.Prefix = prefix
+	if err := uc.repo.SaveGroup(ctx, group); err != nil {
+		return "", err
+	}
+
+	uc.mu.Lock()
+	if prefix == "" {
+		delete(uc.prefixes, picked.JID)
+	} else {
+		uc.prefixes[picked.JID] = prefix
+	}
+	uc.mu.Unlock()
+
+	if prefix == "" {
+		return fmt.Sprintf("Grup \"%s\" kembali memakai awalan perintah bawaan.", picked.Name), nil
+	}
+	return fmt.Sprintf("Perintah di grup \"%s\" sekarang diawali %s, cth: %slapor", picked.Name, prefix, prefix), nil
+}
+
+// setHint turns the #help pointer for unknown commands on or off in one
+// group.
+func (uc *ManageGroupsUsecase) setHint(ctx context.Context, userID, target string, enabled bool) (string, error) {
+	picked, err := uc.pickGroup(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	if picked == nil {
+		return "Grup tidak ditemukan. Cek nomor di #admin groups.", nil
+	}
+
+	group, err := uc.repo.GetGroup(ctx, picked.JID)
+	if err != nil {
+		return "", err
+	}
+	if group == nil {
+		group = &domain.Group{JID: picked.JID, Enabled: picked.Served, AddedBy: userID, AddedAt: time.Now()}
+	}
+	group.Name = picked.Name
+	group.CommandHint = enabled
+	if err := uc.repo.SaveGroup(ctx, group); err != nil {
+		return "", err
+	}
+
+	uc.mu.Lock()
+	uc.hints[picked.JID] = enabled
+	uc.mu.Unlock()
+
+	if enabled {
+		return fmt.Sprintf("Perintah yang tidak dikenal di grup \"%s\" sekarang dibalas dengan petunjuk #help.", picked.Name), nil
+	}
+	return fmt.Sprintf("Perintah yang tidak dikenal di grup \"%s\" kembali diabaikan.", picked.Name), nil
+}
+
+// setStyle turns an extra of #leaderboard, or all of them with "semua", on
+// or off in one group.
+func (uc *ManageGroupsUsecase) setStyle(ctx context.Context, userID, target, extra string, enabled bool) (string, error) {
+	picked, err := uc.pickGroup(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	if picked == nil {
+		return "Grup tidak ditemukan. Cek nomor di #admin groups.", nil
+	}
+
+	group, err := uc.repo.GetGroup(ctx, picked.JID)
+	if err != nil {
+		return "", err
+	}
+	if group == nil {
+		group = &domain.Group{JID: picked.JID, Enabled: picked.Served, AddedBy: userID, AddedAt: time.Now()}
+	}
+	style := ParseLeaderboardStyle(group.LeaderboardStyle)
+	if extra == "semua" {
+		style = LeaderboardStyle{Podium: enabled, Divider: enabled, Aligned: enabled}
+	} else if !style.set(extra, enabled) {
+		return leaderboardStyleUsage, nil
+	}
+	group.Name = picked.Name
+	group.LeaderboardStyle = style.String()
+	if err := uc.repo.SaveGroup(ctx, group); err != nil {
+		return "", err
+	}
+
+	uc.mu.Lock()
+	uc.styles[picked.JID] = style
+	uc.mu.Unlock()
+
+	if style == (LeaderboardStyle{}) {
+		return fmt.Sprintf("#leaderboard di grup \"%s\" kembali polos.", picked.Name), nil
+	}
+	return fmt.Sprintf("Hiasan #leaderboard di grup \"%s\": %s.", picked.Name, strings.ReplaceAll(style.String(), ",", ", ")), nil
+}
+
+// validPrefix accepts 1-3 symbols such as "!", "/" or "..".
+func validPrefix(prefix string) bool {
+	if prefix == "" || len([]rune(prefix)) > 3 {
+		return false
+	}
+	for _, r := range prefix {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
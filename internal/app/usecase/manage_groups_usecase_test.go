@@ -0,0 +1,275 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// mockGroupRepo implements domain.GroupRepository for testing
+type mockGroupRepo struct {
+	groups map[string]*domain.Group
+}
+
+func (m *mockGroupRepo) GetGroup(ctx context.Context, jid string) (*domain.Group, error) {
+	return m.groups[jid], nil
+}
+
+func (m *mockGroupRepo) GetGroups(ctx context.Context) ([]*domain.Group, error) {
+	var result []*domain.Group
+	for _, g := range m.groups {
+		result = append(result, g)
+	}
+	return result, nil
+}
+
+func (m *mockGroupRepo) SaveGroup(ctx context.Context, group *domain.Group) error {
+	m.groups[group.JID] = group
+	return nil
+}
+
+func (m *mockGroupRepo) InitTable(ctx context.Context) error {
+	return nil
+}
+
+// mockGroupGateway resolves invite codes from a fixed table
+type mockGroupGateway struct {
+	invites map[string]string // code -> JID
+	joined  []domain.JoinedGroup
+	left    map[string]string // JID -> farewell
+}
+
+func (m *mockGroupGateway) LeaveGroup(ctx context.Context, jid, farewell string) error {
+	if m.left == nil {
+		m.left = make(map[string]string)
+	}
+	m.left[jid] = farewell
+	return nil
+}
+
+func (m *mockGroupGateway) JoinedGroups(ctx context.Context) ([]domain.JoinedGroup, error) {
+	return m.joined, nil
+}
+
+func (m *mockGroupGateway) JoinGroup(ctx context.Context, linkOrJID string) (string, string, error) {
+	if jid, ok := m.invites[linkOrJID]; ok {
+		return jid, "Grup " + jid, nil
+	}
+	return "", "", errors.New("invalid invite")
+}
+
+// =============================================================================
+// GROUP ONBOARDING TESTS
+// =============================================================================
+
+func TestGroups_AddGroupViaInvite(t *testing.T) {
+	repo := &mockGroupRepo{groups: make(map[string]*domain.Group)}
+	gateway := &mockGroupGateway{invites: map[string]string{"https://chat.whatsapp.com/AbC123": "222@g.us"}}
+	groupsUC := usecase.NewManageGroupsUsecase(repo, gateway, "111@g.us")
+
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetGroupsUsecase(groupsUC)
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	if groupsUC.IsServed("222@g.us") || !groupsUC.IsServed("111@g.us") {
+		t.Fatalf("Only GROUP_ID should be served initially")
+	}
+
+	result, err := handleUC.Execute(ctx, "user1", "Alice", "#admin add-group https://chat.whatsapp.com/AbC123")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "khusus admin") || groupsUC.IsServed("222@g.us") {
+		t.Fatalf("Non-admin must not add groups, got '%s'", result)
+	}
+
+	// Invite codes are case-sensitive and must not be lowercased
+	result, err = handleUC.Execute(ctx, "admin1", "Admin", "#admin add-group https://chat.whatsapp.com/AbC123")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "sekarang dilayani") {
+		t.Fatalf("Expected confirmation, got '%s'", result)
+	}
+	if !groupsUC.IsServed("222@g.us") {
+		t.Errorf("Expected new group to be served")
+	}
+	if g := repo.groups["222@g.us"]; g == nil || !g.Enabled || g.AddedBy != "admin1" {
+		t.Errorf("Expected group persisted, got %+v", g)
+	}
+
+	// Registration survives a restart
+	reloaded := usecase.NewManageGroupsUsecase(repo, gateway, "111@g.us")
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reloaded.IsServed("222@g.us") {
+		t.Errorf("Expected registered group to be served after reload")
+	}
+
+	result, err = handleUC.Execute(ctx, "admin1", "Admin", "#admin add-group https://chat.whatsapp.com/wrong")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Gagal bergabung") {
+		t.Errorf("Expected join failure message, got '%s'", result)
+	}
+}
+
+func TestGroups_ServeAllWithoutConfiguration(t *testing.T) {
+	groupsUC := usecase.NewManageGroupsUsecase(&mockGroupRepo{groups: make(map[string]*domain.Group)}, &mockGroupGateway{}, "")
+	if !groupsUC.IsServed("333@g.us") {
+		t.Errorf("Without GROUP_ID or registered groups every group is served")
+	}
+}
+
+func TestGroups_DisableOneGroupWithoutConfiguration(t *testing.T) {
+	repo := &mockGroupRepo{groups: make(map[string]*domain.Group)}
+	gateway := &mockGroupGateway{joined: []domain.JoinedGroup{
+		{JID: "222@g.us", Name: "Lari Pagi"},
+		{JID: "333@g.us", Name: "Yoga Club"},
+	}}
+	groupsUC := usecase.NewManageGroupsUsecase(repo, gateway, "")
+
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetGroupsUsecase(groupsUC)
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	if _, err := handleUC.Execute(ctx, "admin1", "Admin", "#admin disable 222@g.us"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if groupsUC.IsServed("222@g.us") {
+		t.Errorf("Expected Lari Pagi to be disabled")
+	}
+	if !groupsUC.IsServed("333@g.us") || !groupsUC.IsServed("444@g.us") {
+		t.Errorf("Disabling one group must not stop the bot in the others")
+	}
+
+	// Per-group settings don't register a group either
+	if _, err := handleUC.Execute(ctx, "admin1", "Admin", "#admin prefix 333@g.us !"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reloaded := usecase.NewManageGroupsUsecase(repo, gateway, "")
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reloaded.IsServed("222@g.us") || !reloaded.IsServed("333@g.us") || !reloaded.IsServed("444@g.us") {
+		t.Errorf("Expected only Lari Pagi to stay disabled after reload")
+	}
+}
+
+func TestGroups_ListAndToggle(t *testing.T) {
+	repo := &mockGroupRepo{groups: make(map[string]*domain.Group)}
+	gateway := &mockGroupGateway{joined: []domain.JoinedGroup{
+		{JID: "333@g.us", Name: "Yoga Club", Participants: 12},
+		{JID: "111@g.us", Name: "Challenge Utama", Participants: 40},
+		{JID: "222@g.us", Name: "Lari Pagi", Participants: 8},
+	}}
+	groupsUC := usecase.NewManageGroupsUsecase(repo, gateway, "111@g.us")
+
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetGroupsUsecase(groupsUC)
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	result, err := handleUC.Execute(ctx, "admin1", "Admin", "#admin groups")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{"1. Challenge Utama – ✅ dilayani (GROUP_ID)", "2. Lari Pagi – ⏸️ tidak dilayani", "3. Yoga Club", "8 anggota"} {
+		if !containsSubstring(result, expected) {
+			t.Errorf("Expected '%s' in group list, got '%s'", expected, result)
+		}
+	}
+
+	// Enable by number from the list
+	if _, err := handleUC.Execute(ctx, "admin1", "Admin", "#admin enable 2"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !groupsUC.IsServed("222@g.us") || repo.groups["222@g.us"] == nil || !repo.groups["222@g.us"].Enabled {
+		t.Errorf("Expected Lari Pagi to be enabled and persisted")
+	}
+
+	// Disable by JID
+	if _, err := handleUC.Execute(ctx, "admin1", "Admin", "#admin disable 222@g.us"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if groupsUC.IsServed("222@g.us") || repo.groups["222@g.us"].Enabled {
+		t.Errorf("Expected Lari Pagi to be disabled")
+	}
+
+	// GROUP_ID cannot be toggled
+	result, err = handleUC.Execute(ctx, "admin1", "Admin", "#admin disable 1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !groupsUC.IsServed("111@g.us") || !containsSubstring(result, "GROUP_ID") {
+		t.Errorf("GROUP_ID must stay served, got '%s'", result)
+	}
+}
+
+func TestGroups_LeaveGroupArchivesStandings(t *testing.T) {
+	repo := &mockGroupRepo{groups: make(map[string]*domain.Group)}
+	gateway := &mockGroupGateway{joined: []domain.JoinedGroup{
+		{JID: "111@g.us", Name: "Challenge Utama"},
+		{JID: "222@g.us", Name: "Lari Pagi"},
+	}}
+	reports := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", ActivityCount: 12, Streak: 3, LastReportDate: time.Now()},
+		"user2": {UserID: "user2", Name: "Bob", ActivityCount: 20, Streak: 5, LastReportDate: time.Now()},
+	}}
+	members := mockGroupMembers{"222@g.us": {"user1"}, "111@g.us": {"user1", "user2"}}
+	groupsUC := usecase.NewManageGroupsUsecase(repo, gateway, "111@g.us")
+	groupsUC.SetLeaderboard(usecase.NewGetLeaderboardUsecase(reports), members)
+
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetGroupsUsecase(groupsUC)
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	if _, err := handleUC.Execute(ctx, "admin1", "Admin", "#admin enable 222@g.us"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// GROUP_ID cannot be left
+	result, err := handleUC.Execute(ctx, "admin1", "Admin", "#admin leave-group 111@g.us")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := gateway.left["111@g.us"]; ok || !containsSubstring(result, "GROUP_ID") {
+		t.Fatalf("Must not leave the GROUP_ID group, got '%s'", result)
+	}
+
+	result, err = handleUC.Execute(ctx, "admin1", "Admin", "#admin leave-group 2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "sudah keluar") {
+		t.Errorf("Expected confirmation, got '%s'", result)
+	}
+
+	farewell, ok := gateway.left["222@g.us"]
+	if !ok {
+		t.Fatalf("Expected the bot to leave Lari Pagi")
+	}
+	if !containsSubstring(farewell, "pamit") || !containsSubstring(farewell, "Klasemen akhir") || !containsSubstring(farewell, "Alice") {
+		t.Errorf("Expected goodbye with final standings, got '%s'", farewell)
+	}
+	if containsSubstring(farewell, "Bob") {
+		t.Errorf("Expected only the group's members in the standings, got '%s'", farewell)
+	}
+
+	group := repo.groups["222@g.us"]
+	if group == nil || group.Enabled || group.LeftAt.IsZero() || !containsSubstring(group.FinalStandings, "Alice") {
+		t.Errorf("Expected the group to be archived with its standings, got %+v", group)
+	}
+	if groupsUC.IsServed("222@g.us") {
+		t.Errorf("Expected the left group to no longer be served")
+	}
+}
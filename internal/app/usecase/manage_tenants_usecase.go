@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+var (
+	tenantIDPattern  = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+	tenantEnvPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+// ManageTenantsUsecase registers the communities one deployment hosts. Each
+// tenant runs as its own bot process with its own database and WhatsApp
+// session; this registry only holds what differs from the environment.
+type ManageTenantsUsecase struct {
+	tenants domain.TenantRepository
+}
+
+func NewManageTenantsUsecase(tenants domain.TenantRepository) *ManageTenantsUsecase {
+	return &ManageTenantsUsecase{tenants: tenants}
+}
+
+// Add registers an enabled tenant without overrides.
+func (uc *ManageTenantsUsecase) Add(ctx context.Context, id, name string) (*domain.Tenant, error) {
+	if !tenantIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("tenant ID must be 1-32 lowercase letters, digits or \"-\", got %q", id)
+	}
+	existing, err := uc.tenants.GetTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("tenant %s already exists", id)
+	}
+
+	tenant := &domain.Tenant{ID: id, Name: strings.TrimSpace(name), Enabled: true, Env: make(map[string]string), CreatedAt: time.Now()}
+	if tenant.Name == "" {
+		tenant.Name = id
+	}
+	if err := uc.tenants.SaveTenant(ctx, tenant); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// Set overrides an environment variable for a tenant, e.g. GROUP_ID. An
+// empty value removes the override.
+func (uc *ManageTenantsUsecase) Set(ctx context.Context, id, key, value string) (*domain.Tenant, error) {
+	if !tenantEnvPattern.MatchString(key) || key == "TENANT" {
+		return nil, fmt.Errorf("%q is not a variable a tenant can override", key)
+	}
+	tenant, err := uc.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		delete(tenant.Env, key)
+	} else {
+		tenant.Env[key] = value
+	}
+	return tenant, uc.tenants.SaveTenant(ctx, tenant)
+}
+
+// SetEnabled starts or stops running a tenant, keeping its data.
+func (uc *ManageTenantsUsecase) SetEnabled(ctx context.Context, id string, enabled bool) error {
+	tenant, err := uc.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	tenant.Enabled = enabled
+	return uc.tenants.SaveTenant(ctx, tenant)
+}
+
+// Remove unregisters a tenant. Its database and session are left on disk.
+func (uc *ManageTenantsUsecase) Remove(ctx context.Context, id string) error {
+	if _, err := uc.get(ctx, id); err != nil {
+		return err
+	}
+	return uc.tenants.DeleteTenant(ctx, id)
+}
+
+// Get returns a tenant, or an error if it does not exist.
+func (uc *ManageTenantsUsecase) Get(ctx context.Context, id string) (*domain.Tenant, error) {
+	return uc.get(ctx, id)
+}
+
+func (uc *ManageTenantsUsecase) List(ctx context.Context) ([]*domain.Tenant, error) {
+	return uc.tenants.GetTenants(ctx)
+}
+
+func (uc *ManageTenantsUsecase) get(ctx context.Context, id string) (*domain.Tenant, error) {
+	tenant, err := uc.tenants.GetTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, fmt.Errorf("no tenant with ID %s", id)
+	}
+	return tenant, nil
+}
+
+// FormatTenants lists tenants and their overrides for "bot tenants list".
+// Values of variables that look secret are hidden.
+func FormatTenants(tenants []*domain.Tenant) string {
+	if len(tenants) == 0 {
+		return "No tenants, the bot runs with the environment's configuration"
+	}
+	var sb strings.Builder
+	for _, tenant := range tenants {
+		status := "enabled"
+		if !tenant.Enabled {
+			status = "disabled"
+		}
+		fmt.Fprintf(&sb, "%s  %s  (%s, created %s)\n", tenant.ID, tenant.Name, status, tenant.CreatedAt.Local().Format("2006-01-02"))
+
+		keys := make([]string, 0, len(tenant.Env))
+		for key := range tenant.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := tenant.Env[key]
+			if isSecretEnv(key) {
+				value = "********"
+			}
+			fmt.Fprintf(&sb, "    %s=%s\n", key, value)
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// isSecretEnv reports whether a variable holds a credential.
+func isSecretEnv(key string) bool {
+	for _, word := range []string{"KEY", "TOKEN", "SECRET", "SALT", "PASSWORD"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
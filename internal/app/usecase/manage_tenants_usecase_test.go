@@ -0,0 +1,117 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// TENANT TESTS
+// =============================================================================
+
+type mockTenantRepo struct {
+	tenants map[string]domain.Tenant
+}
+
+func (m *mockTenantRepo) GetTenant(ctx context.Context, id string) (*domain.Tenant, error) {
+	tenant, ok := m.tenants[id]
+	if !ok {
+		return nil, nil
+	}
+	env := make(map[string]string)
+	for k, v := range tenant.Env {
+		env[k] = v
+	}
+	tenant.Env = env
+	return &tenant, nil
+}
+
+func (m *mockTenantRepo) GetTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	var tenants []*domain.Tenant
+	for id := range m.tenants {
+		tenant, _ := m.GetTenant(ctx, id)
+		tenants = append(tenants, tenant)
+	}
+	return tenants, nil
+}
+
+func (m *mockTenantRepo) SaveTenant(ctx context.Context, tenant *domain.Tenant) error {
+	m.tenants[tenant.ID] = *tenant
+	return nil
+}
+
+func (m *mockTenantRepo) DeleteTenant(ctx context.Context, id string) error {
+	delete(m.tenants, id)
+	return nil
+}
+
+func (m *mockTenantRepo) InitTable(ctx context.Context) error {
+	return nil
+}
+
+func TestManageTenants(t *testing.T) {
+	repo := &mockTenantRepo{tenants: make(map[string]domain.Tenant)}
+	uc := usecase.NewManageTenantsUsecase(repo)
+	ctx := context.Background()
+
+	for _, id := range []string{"", "Lari", "../lari", "lari pagi"} {
+		if _, err := uc.Add(ctx, id, ""); err == nil {
+			t.Errorf("Expected an error for tenant ID %q", id)
+		}
+	}
+	tenant, err := uc.Add(ctx, "lari-pagi", "Lari Pagi")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !tenant.Enabled || tenant.Name != "Lari Pagi" {
+		t.Errorf("Expected an enabled tenant, got %+v", tenant)
+	}
+	if _, err := uc.Add(ctx, "lari-pagi", ""); err == nil {
+		t.Error("Expected an error for a duplicate tenant")
+	}
+
+	if _, err := uc.Set(ctx, "lari-pagi", "GROUP_ID", "111@g.us"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := uc.Set(ctx, "lari-pagi", "SUPABASE_KEY", "rahasia"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, key := range []string{"TENANT", "group_id", "GROUP-ID"} {
+		if _, err := uc.Set(ctx, "lari-pagi", key, "x"); err == nil {
+			t.Errorf("Expected an error for variable %q", key)
+		}
+	}
+	if _, err := uc.Set(ctx, "yoga", "GROUP_ID", "x"); err == nil {
+		t.Error("Expected an error for an unknown tenant")
+	}
+	if err := uc.SetEnabled(ctx, "lari-pagi", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tenants, err := uc.List(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	list := usecase.FormatTenants(tenants)
+	if !strings.Contains(list, "lari-pagi  Lari Pagi  (disabled") || !strings.Contains(list, "GROUP_ID=111@g.us") ||
+		strings.Contains(list, "rahasia") {
+		t.Errorf("Expected the tenant listed with secrets hidden, got:\n%s", list)
+	}
+
+	if _, err := uc.Set(ctx, "lari-pagi", "GROUP_ID", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := repo.tenants["lari-pagi"].Env["GROUP_ID"]; ok {
+		t.Error("Expected an empty value to remove the override")
+	}
+	if err := uc.Remove(ctx, "lari-pagi"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(repo.tenants) != 0 {
+		t.Errorf("Expected the tenant removed, got %v", repo.tenants)
+	}
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const onboardingFlow = "join"
+
+const maxDisplayNameLength = 30
+
+// Steps of the #join conversation
+const (
+	stepDisplayName = iota
+	stepTimezone
+	stepReminder
+)
+
+// OnboardingUsecase runs the "#join" flow in a private chat: confirm the
+// display name, pick a timezone and a reminder, then save everything at
+// once. Nothing is stored in the settings until the last answer.
+type OnboardingUsecase struct {
+	settings      domain.SettingsRepository
+	conversations *ConversationManager
+}
+
+// NewOnboardingUsecase registers the #join flow with conversations.
+func NewOnboardingUsecase(settings domain.SettingsRepository, conversations *ConversationManager) *OnboardingUsecase {
+	uc := &OnboardingUsecase{settings: settings, conversations: conversations}
+	conversations.Register(uc)
+	return uc
+}
+
+// Start begins (or restarts) the flow and asks the first question. The
+// reply goes to the member's private chat.
+func (uc *OnboardingUsecase) Start(ctx context.Context, userID, name string) (*Reply, error) {
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil && settings.DisplayName != "" {
+		name = settings.DisplayName
+	}
+
+	if err := uc.conversations.Start(ctx, userID, onboardingFlow, map[string]string{"name": name}); err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf("Halo %s! 👋 Yuk daftar challenge, cuma 3 langkah.\n\n"+
+		"1/3 Nama yang tampil di klasemen: *%s*\n"+
+		"Balas *ya* kalau sudah benar, atau ketik nama lain.\n\n"+
+		"Ketik *batal* kapan saja untuk berhenti.", name, name)
+	return &Reply{Text: text, Private: true}, nil
+}
+
+func (uc *OnboardingUsecase) Name() string {
+	return onboardingFlow
+}
+
+func (uc *OnboardingUsecase) Cancelled() string {
+	return "Pendaftaran dibatalkan. Ketik #join lagi kapan saja 👍"
+}
+
+// Step takes the answer to the current question and asks the next one.
+func (uc *OnboardingUsecase) Step(ctx context.Context, conv *domain.Conversation, answer string) (string, bool, error) {
+	lower := strings.ToLower(answer)
+
+	switch conv.Step {
+	case stepDisplayName:
+		if lower != "ya" && lower != "y" && lower != "ok" {
+			if utf8.RuneCountInString(answer) > maxDisplayNameLength || strings.HasPrefix(answer, canonicalPrefix) {
+				return fmt.Sprintf("Nama maksimal %d karakter. Balas *ya* atau ketik nama lain.", maxDisplayNameLength), false, nil
+			}
+			conv.Data["name"] = answer
+		}
+		conv.Step = stepTimezone
+		return "2/3 Kamu di zona waktu mana? Balas *WIB*, *WITA*, atau *WIT*.", false, nil
+
+	case stepTimezone:
+		timezone := strings.ToUpper(answer)
+		if _, ok := timezones[timezone]; !ok {
+			return "Balas *WIB*, *WITA*, atau *WIT* ya.", false, nil
+		}
+		conv.Data["timezone"] = timezone
+		conv.Step = stepReminder
+		return "3/3 Mau diingatkan lewat chat pribadi kalau belum lapor? Balas jam (cth: *19:30*) atau *tidak*.", false, nil
+	}
+
+	reminderTime := ""
+	switch lower {
+	case "tidak", "nggak", "gak", "ga", "no":
+	default:
+		at, err := time.Parse("15:04", strings.ReplaceAll(answer, ".", ":"))
+		if err != nil {
+			return "Format jam salah. Balas jam seperti *19:30*, atau *tidak*.", false, nil
+		}
+		reminderTime = at.Format("15:04")
+	}
+
+	reply, err := uc.finish(ctx, conv.UserID, conv.Data["name"], conv.Data["timezone"], reminderTime)
+	return reply, err == nil, err
+}
+
+// finish saves the answers and activates the member.
+func (uc *OnboardingUsecase) finish(ctx context.Context, userID, name, timezone, reminderTime string) (string, error) {
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if settings == nil {
+		settings = &domain.UserSettings{UserID: userID}
+	}
+	settings.DisplayName = name
+	settings.Timezone = timezone
+	settings.ReminderTime = reminderTime
+	if settings.JoinedAt.IsZero() {
+		settings.JoinedAt = time.Now()
+	}
+	if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+		return "", err
+	}
+
+	reminder := "tidak"
+	if reminderTime != "" {
+		reminder = fmt.Sprintf("jam %s %s", reminderTime, timezone)
+	}
+	return fmt.Sprintf("✅ Selesai, %s sudah terdaftar!\n\n"+
+		"Nama: %s\nZona waktu: %s\nPengingat: %s\n\n"+
+		"Ketik #lapor di grup setiap selesai olahraga. Semangat! 💪", name, name, timezone, reminder), nil
+}
@@ -0,0 +1,141 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockConversationRepo struct {
+	conversations map[string]*domain.Conversation
+}
+
+func (m *mockConversationRepo) GetConversation(ctx context.Context, userID string) (*domain.Conversation, error) {
+	return m.conversations[userID], nil
+}
+
+func (m *mockConversationRepo) SaveConversation(ctx context.Context, conv *domain.Conversation) error {
+	m.conversations[conv.UserID] = conv
+	return nil
+}
+
+func (m *mockConversationRepo) DeleteConversation(ctx context.Context, userID string) error {
+	delete(m.conversations, userID)
+	return nil
+}
+
+func (m *mockConversationRepo) InitTable(ctx context.Context) error {
+	return nil
+}
+
+func newOnboardingHandler(repo *mockRepo, settingsRepo *mockSettingsRepo, convRepo *mockConversationRepo) *usecase.HandleMessageUsecase {
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	reportUC.SetSettingsRepository(settingsRepo)
+	handleUC := usecase.NewHandleMessageUsecase(reportUC, usecase.NewGetLeaderboardUsecase(repo))
+	conversations := usecase.NewConversationManager(convRepo)
+	handleUC.SetConversations(conversations)
+	handleUC.SetOnboardingUsecase(usecase.NewOnboardingUsecase(settingsRepo, conversations))
+	return handleUC
+}
+
+func TestOnboarding_JoinFlow(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	settingsRepo := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+	convRepo := &mockConversationRepo{conversations: make(map[string]*domain.Conversation)}
+	handleUC := newOnboardingHandler(repo, settingsRepo, convRepo)
+	ctx := context.Background()
+
+	reply, err := handleUC.ExecuteInChat(ctx, "111@g.us", "user1", "Alice W", "#join")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reply.Private || !containsSubstring(reply.Text, "1/3") || !containsSubstring(reply.Text, "Alice W") {
+		t.Fatalf("Expected first question in private chat, got %+v", reply)
+	}
+	if !handleUC.InConversation(ctx, "user1") {
+		t.Fatal("Expected user1 to be in a conversation")
+	}
+
+	// Answers only count in the private chat
+	reply, err = handleUC.ExecuteInChat(ctx, "111@g.us", "user1", "Alice W", "Alice")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reply.Text != "" {
+		t.Errorf("Expected group chatter ignored, got '%s'", reply.Text)
+	}
+
+	steps := []struct {
+		answer   string
+		expected string
+	}{
+		{"Alice", "2/3"},
+		{"GMT", "WIB"},
+		{"wita", "3/3"},
+		{"jam tujuh", "Format jam salah"},
+		{"19.30", "Selesai, Alice sudah terdaftar"},
+	}
+	for i, step := range steps {
+		// The flow survives a restart halfway through
+		if i == 2 {
+			handleUC = newOnboardingHandler(repo, settingsRepo, convRepo)
+		}
+		reply, err := handleUC.ExecuteInChat(ctx, "user1@s.whatsapp.net", "user1", "Alice W", step.answer)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !containsSubstring(reply.Text, step.expected) {
+			t.Errorf("'%s': expected '%s', got '%s'", step.answer, step.expected, reply.Text)
+		}
+	}
+
+	if handleUC.InConversation(ctx, "user1") || len(convRepo.conversations) != 0 {
+		t.Error("Expected the conversation to be finished")
+	}
+	s := settingsRepo.settings["user1"]
+	if s == nil || s.DisplayName != "Alice" || s.Timezone != "WITA" || s.ReminderTime != "19:30" || s.JoinedAt.IsZero() {
+		t.Fatalf("Unexpected settings %+v", s)
+	}
+
+	// The chosen name is used in reports
+	if _, err := handleUC.ExecuteInChat(ctx, "111@g.us", "user1", "Alice W", "#lapor"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if repo.reports["user1"].Name != "Alice" {
+		t.Errorf("Expected report under display name, got '%s'", repo.reports["user1"].Name)
+	}
+}
+
+func TestOnboarding_CancelAndTimeout(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	settingsRepo := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+	convRepo := &mockConversationRepo{conversations: make(map[string]*domain.Conversation)}
+	handleUC := newOnboardingHandler(repo, settingsRepo, convRepo)
+	ctx := context.Background()
+
+	if _, err := handleUC.ExecuteInChat(ctx, "111@g.us", "user1", "Bob", "#join"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reply, err := handleUC.ExecuteInChat(ctx, "user1@s.whatsapp.net", "user1", "Bob", "Batal")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(reply.Text, "dibatalkan") || handleUC.InConversation(ctx, "user1") {
+		t.Errorf("Expected flow cancelled, got '%s'", reply.Text)
+	}
+	if settingsRepo.settings["user1"] != nil {
+		t.Error("Expected nothing saved after cancelling")
+	}
+
+	// An unanswered conversation expires
+	if _, err := handleUC.ExecuteInChat(ctx, "111@g.us", "user1", "Bob", "#join"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	convRepo.conversations["user1"].ExpiresAt = time.Now().Add(-time.Minute)
+	if handleUC.InConversation(ctx, "user1") || convRepo.conversations["user1"] != nil {
+		t.Error("Expected expired conversation to be removed")
+	}
+}
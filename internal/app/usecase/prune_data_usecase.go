@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// PruneDataUsecase enforces the retention policy: raw activity-log rows,
+// archived messages, the outbox and leaderboard snapshots older than the
+// retention period are deleted, while the per-member aggregates in
+// user_reports (streak, total days) are kept.
+type PruneDataUsecase struct {
+	activities      domain.ActivityRepository
+	messages        domain.MessageArchiveRepository
+	outbox          domain.OutboxRepository
+	snapshots       domain.SnapshotRepository
+	retentionMonths int
+}
+
+func NewPruneDataUsecase(activities domain.ActivityRepository, retentionMonths int) *PruneDataUsecase {
+	return &PruneDataUsecase{activities: activities, retentionMonths: retentionMonths}
+}
+
+// SetMessageArchive also prunes the message archive.
+func (uc *PruneDataUsecase) SetMessageArchive(messages domain.MessageArchiveRepository) {
+	uc.messages = messages
+}
+
+// SetOutbox also prunes the delivery status of sent messages.
+func (uc *PruneDataUsecase) SetOutbox(outbox domain.OutboxRepository) {
+	uc.outbox = outbox
+}
+
+// SetSnapshots also prunes the leaderboard snapshots.
+func (uc *PruneDataUsecase) SetSnapshots(snapshots domain.SnapshotRepository) {
+	uc.snapshots = snapshots
+}
+
+// Execute deletes everything older than the retention period. It is a no-op
+// when retention is disabled (0 months).
+func (uc *PruneDataUsecase) Execute(ctx context.Context) error {
+	if uc.retentionMonths <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().AddDate(0, -uc.retentionMonths, 0)
+	if err := uc.activities.DeleteActivities(ctx, domain.ActivityFilter{Until: cutoff}); err != nil {
+		return err
+	}
+	if uc.messages != nil {
+		if err := uc.messages.DeleteMessages(ctx, domain.MessageFilter{Until: cutoff}); err != nil {
+			return err
+		}
+	}
+
+	if uc.outbox != nil {
+		if err := uc.outbox.DeleteOutbox(ctx, domain.OutboxFilter{Until: cutoff}); err != nil {
+			return err
+		}
+	}
+	if uc.snapshots != nil {
+		if err := uc.snapshots.DeleteSnapshots(ctx, domain.SnapshotFilter{Before: cutoff.Format("2006-01-02")}); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Pruned data older than %s", cutoff.Format("2006-01-02"))
+	return nil
+}
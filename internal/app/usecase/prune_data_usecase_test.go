@@ -0,0 +1,43 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// RETENTION TESTS
+// =============================================================================
+
+func TestPrune_DeletesOldActivities(t *testing.T) {
+	now := time.Now()
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", ActivityType: "lari", ReportedAt: now.AddDate(0, -7, 0)},
+		{UserID: "user1", ActivityType: "gym", ReportedAt: now.AddDate(0, -5, 0)},
+		{UserID: "user2", ActivityType: "gym", ReportedAt: now},
+	}}
+
+	if err := usecase.NewPruneDataUsecase(activities, 6).Execute(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(activities.activities) != 2 {
+		t.Errorf("Expected 2 activities within retention, got %d", len(activities.activities))
+	}
+}
+
+func TestPrune_DisabledKeepsEverything(t *testing.T) {
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", ActivityType: "lari", ReportedAt: time.Now().AddDate(-3, 0, 0)},
+	}}
+
+	if err := usecase.NewPruneDataUsecase(activities, 0).Execute(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(activities.activities) != 1 {
+		t.Errorf("Retention 0 must keep everything, got %d", len(activities.activities))
+	}
+}
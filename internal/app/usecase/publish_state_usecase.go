@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// StatePublisher keeps the latest board state where displays can read it,
+// e.g. as a retained MQTT message. leader is a short text for small
+// displays, like "Budi 12 hari", empty while nobody has a streak.
+type StatePublisher interface {
+	PublishState(ctx context.Context, state []byte, leader string) error
+}
+
+// BoardState is the compact state of the challenge for displays such as an
+// office LED board.
+type BoardState struct {
+	Date          string        `json:"date"`             // YYYY-MM-DD
+	Leader        *BoardMember  `json:"leader,omitempty"` // longest current streak, nil until someone has one
+	ReportedToday int           `json:"reported_today"`
+	Members       []BoardMember `json:"members"` // ranked by days reported, like #leaderboard
+}
+
+// BoardMember is one member of the board. Members left out of the ranking
+// by admins or #privat are not on it.
+type BoardMember struct {
+	UserID string `json:"-"`
+	Rank   int    `json:"rank"`
+	Name   string `json:"name"`
+	Streak int    `json:"streak"` // 0 once a day was missed
+	Total  int    `json:"total"`
+	Today  bool   `json:"today"` // reported today
+
+	// For the tie-breaks between members with the same total
+	LastReport time.Time `json:"-"`
+	BestStreak int       `json:"-"`
+}
+
+// PublishStateUsecase publishes the board state whenever it changes. A
+// counted #lapor triggers a check right away (it is an EventPublisher for
+// the report events); anything else, like admin edits or a streak lost at
+// midnight, is picked up by the periodic check of Run.
+type PublishStateUsecase struct {
+	repo      domain.ReportRepository
+	settings  domain.SettingsRepository
+	publisher StatePublisher
+	boards    *BoardBuilder
+
+	members HomeAssistantPublisher
+
+	changed     chan struct{}
+	mu          sync.Mutex
+	last        []byte
+	lastMembers map[string][]byte // by Home Assistant key
+}
+
+func NewPublishStateUsecase(repo domain.ReportRepository, publisher StatePublisher) *PublishStateUsecase {
+	return &PublishStateUsecase{repo: repo, publisher: publisher, changed: make(chan struct{}, 1)}
+}
+
+// SetBoards ranks the published board like #leaderboard.
+func (uc *PublishStateUsecase) SetBoards(boards *BoardBuilder) {
+	uc.boards = boards
+}
+
+// SetSettingsRepository leaves unranked and #privat members off the board.
+func (uc *PublishStateUsecase) SetSettingsRepository(settings domain.SettingsRepository) {
+	uc.settings = settings
+}
+
+// SetHomeAssistantPublisher also publishes each member's own state, for
+// the sensors added with #homeassistant.
+func (uc *PublishStateUsecase) SetHomeAssistantPublisher(members HomeAssistantPublisher) {
+	uc.members = members
+}
+
+// Publish schedules a check after a report event, without making the
+// member wait for it.
+func (uc *PublishStateUsecase) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	if event.Type != domain.EventReportAccepted {
+		return nil
+	}
+	select {
+	case uc.changed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run publishes the state now, after every report and every interval, until
+// ctx is done.
+func (uc *PublishStateUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := uc.Refresh(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to publish board state: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-uc.changed:
+		}
+	}
+}
+
+// Refresh publishes the state when it differs from the last one published,
+// and the members' own states for Home Assistant that changed.
+func (uc *PublishStateUsecase) Refresh(ctx context.Context, now time.Time) error {
+	if uc.publisher == nil {
+		return errors.New("no state publisher")
+	}
+	all, err := uc.repo.GetAllReports(ctx)
+	if err != nil {
+		return err
+	}
+	state, err := boardsOr(uc.boards, uc.repo, uc.settings).Rank(ctx, all, now)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if !bytes.Equal(data, uc.last) {
+		leader := ""
+		if state.Leader != nil {
+			leader = fmt.Sprintf("%s %d hari", state.Leader.Name, state.Leader.Streak)
+		}
+		if err := uc.publisher.PublishState(ctx, data, leader); err != nil {
+			return err
+		}
+		uc.last = data
+	}
+	return uc.refreshMembers(ctx, all, now)
+}
+
+// refreshMembers publishes every member's own state, private and unranked
+// members included: it is only found under their key. Callers hold uc.mu.
+func (uc *PublishStateUsecase) refreshMembers(ctx context.Context, all []*domain.Report, now time.Time) error {
+	if uc.members == nil {
+		return nil
+	}
+	if uc.lastMembers == nil {
+		uc.lastMembers = make(map[string][]byte)
+	}
+	for _, r := range all {
+		key := HomeAssistantKey(r.UserID)
+		data, err := json.Marshal(newMemberState(r, now))
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(data, uc.lastMembers[key]) {
+			continue
+		}
+		if err := uc.members.PublishMemberState(ctx, key, data); err != nil {
+			return err
+		}
+		uc.lastMembers[key] = data
+	}
+	return nil
+}
+
+// State returns the board as of now.
+func (uc *PublishStateUsecase) State(ctx context.Context, now time.Time) (*BoardState, error) {
+	return boardsOr(uc.boards, uc.repo, uc.settings).Board(ctx, now)
+}
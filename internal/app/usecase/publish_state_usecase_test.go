@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockStatePublisher struct {
+	states  [][]byte
+	leaders []string
+}
+
+func (m *mockStatePublisher) PublishState(ctx context.Context, state []byte, leader string) error {
+	m.states = append(m.states, state)
+	m.leaders = append(m.leaders, leader)
+	return nil
+}
+
+// =============================================================================
+// PUBLISH STATE TESTS
+// =============================================================================
+
+func TestPublishState_Board(t *testing.T) {
+	now := time.Date(2026, 3, 10, 19, 0, 0, 0, time.Local)
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 5, ActivityCount: 9, LastReportDate: now.Add(-2 * time.Hour)},
+		"user2": {UserID: "user2", Name: "Bob", Streak: 8, ActivityCount: 12, LastReportDate: now.AddDate(0, 0, -1)},
+		"user3": {UserID: "user3", Name: "Cici", Streak: 20, ActivityCount: 20, LastReportDate: now.AddDate(0, 0, -3)},
+		"user4": {UserID: "user4", Name: "Dedi", Streak: 30, ActivityCount: 30, LastReportDate: now},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{"user4": {UserID: "user4", Private: true}}}
+	uc := usecase.NewPublishStateUsecase(repo, &mockStatePublisher{})
+	uc.SetSettingsRepository(settings)
+
+	state, err := uc.State(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if state.Date != "2026-03-10" || state.ReportedToday != 1 {
+		t.Errorf("Expected one report on 2026-03-10, got %+v", state)
+	}
+	if len(state.Members) != 3 {
+		t.Fatalf("Expected the private member left out, got %+v", state.Members)
+	}
+	names := []string{state.Members[0].Name, state.Members[1].Name, state.Members[2].Name}
+	if names[0] != "Cici" || names[1] != "Bob" || names[2] != "Alice" || state.Members[2].Rank != 3 {
+		t.Errorf("Expected the members ranked by days reported, got %v", names)
+	}
+	if state.Members[0].Streak != 0 {
+		t.Errorf("Expected Cici's streak lost after missed days, got %d", state.Members[0].Streak)
+	}
+	if state.Leader == nil || state.Leader.Name != "Bob" || state.Leader.Streak != 8 {
+		t.Errorf("Expected Bob to lead with a streak still going, got %+v", state.Leader)
+	}
+}
+
+func TestPublishState_OnlyOnChange(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 5, ActivityCount: 9, LastReportDate: now.AddDate(0, 0, -1)},
+	}}
+	publisher := &mockStatePublisher{}
+	uc := usecase.NewPublishStateUsecase(repo, publisher)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := uc.Refresh(ctx, now); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(publisher.states) != 1 || publisher.leaders[0] != "Alice 5 hari" {
+		t.Fatalf("Expected the state published once, got %d with leaders %v", len(publisher.states), publisher.leaders)
+	}
+
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	reportUC.SetEventPublisher(uc)
+	if _, err := reportUC.Execute(ctx, "user1", "Alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := uc.Refresh(ctx, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(publisher.states) != 2 {
+		t.Fatalf("Expected the new report published, got %d states", len(publisher.states))
+	}
+	var state usecase.BoardState
+	if err := json.Unmarshal(publisher.states[1], &state); err != nil {
+		t.Fatalf("Expected JSON, got %v", err)
+	}
+	if state.ReportedToday != 1 || state.Leader == nil || state.Leader.Streak != 6 || !state.Leader.Today {
+		t.Errorf("Expected Alice's report today in the state, got %+v", state)
+	}
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
+)
+
+// reportReactions are the reactions that count as a #lapor.
+var reportReactions = map[string]bool{"🔥": true, "✅": true}
+
+// PromptSender posts a group message members can answer to report and
+// returns the IDs of its parts.
+type PromptSender interface {
+	SendMentionIDs(ctx context.Context, chatJID, text string, userIDs []string) ([]string, error)
+}
+
+// sendPrompt posts text and stores every part as a prompt.
+func sendPrompt(ctx context.Context, sender PromptSender, prompts domain.PromptRepository, chatJID, text string, userIDs []string) error {
+	ids, err := sender.SendMentionIDs(ctx, chatJID, text, userIDs)
+	now := time.Now()
+	for _, id := range ids {
+		if err := prompts.SavePrompt(ctx, &domain.ReportPrompt{MessageID: id, ChatID: chatJID, SentAt: now}); err != nil {
+			log.Printf("Failed to store prompt %s: %v", id, err)
+		}
+	}
+	return err
+}
+
+// ReactionReportUsecase lets members report by reacting 🔥 or ✅ to one of
+// today's prompts, or by replying to it, for members who read along but
+// rarely type a command. Reactions are not answered, so a prompt with many
+// reactions does not flood the group; #stats shows that the report counted.
+type ReactionReportUsecase struct {
+	prompts  domain.PromptRepository
+	reportUC *ReportActivityUsecase
+}
+
+func NewReactionReportUsecase(prompts domain.PromptRepository, reportUC *ReportActivityUsecase) *ReactionReportUsecase {
+	return &ReactionReportUsecase{prompts: prompts, reportUC: reportUC}
+}
+
+// Execute counts a reaction to messageID in chatID as the member's report
+// of the day and reports whether it counted. Other reactions, reactions to
+// other messages or to an earlier day's prompt, and a second report of the
+// day are ignored, so taking a reaction back and adding it again changes
+// nothing.
+func (uc *ReactionReportUsecase) Execute(ctx context.Context, chatID, messageID, userID, name, emoji string, now time.Time) (bool, error) {
+	// Some phones add a variation selector to ✅
+	emoji = strings.TrimSuffix(emoji, "\ufe0f")
+	if !reportReactions[emoji] {
+		return false, nil
+	}
+
+	today, err := uc.today(ctx, chatID, messageID, now)
+	if err != nil || !today {
+		return false, err
+	}
+
+	_, counted, err := uc.reportUC.Submit(ctx, userID, name, "#lapor")
+	return counted, err
+}
+
+// ExecuteReply counts a reply to one of today's prompts that describes an
+// activity, e.g. "lari 5km", as a #lapor and returns the answer to it.
+// Replies without an activity type, duration, or distance, such as
+// "semangat!", are not reports and return false.
+func (uc *ReactionReportUsecase) ExecuteReply(ctx context.Context, chatID, quotedID, userID, name, text string, now time.Time) (string, bool, error) {
+	detail := activity.Parse(text)
+	if detail.Type == activity.TypeLainnya && detail.DurationMinutes == 0 && detail.DistanceKm == 0 {
+		return "", false, nil
+	}
+	today, err := uc.today(ctx, chatID, quotedID, now)
+	if err != nil || !today {
+		return "", false, err
+	}
+
+	reply, _, err := uc.reportUC.Submit(ctx, userID, name, "#lapor "+text)
+	return reply, true, err
+}
+
+// today reports whether messageID in chatID is a prompt sent on the day of
+// now.
+func (uc *ReactionReportUsecase) today(ctx context.Context, chatID, messageID string, now time.Time) (bool, error) {
+	prompt, err := uc.prompts.GetPrompt(ctx, chatID, messageID)
+	if err != nil || prompt == nil {
+		return false, err
+	}
+	return prompt.SentAt.In(now.Location()).Format("2006-01-02") == now.Format("2006-01-02"), nil
+}
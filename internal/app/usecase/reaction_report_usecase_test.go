@@ -0,0 +1,107 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockPromptRepo struct {
+	prompts map[string]*domain.ReportPrompt
+}
+
+func (m *mockPromptRepo) SavePrompt(ctx context.Context, prompt *domain.ReportPrompt) error {
+	m.prompts[prompt.ChatID+"/"+prompt.MessageID] = prompt
+	return nil
+}
+
+func (m *mockPromptRepo) GetPrompt(ctx context.Context, chatID, messageID string) (*domain.ReportPrompt, error) {
+	return m.prompts[chatID+"/"+messageID], nil
+}
+
+func (m *mockPromptRepo) InitTable(ctx context.Context) error { return nil }
+
+func TestReactionReport_CountsReactionsToTodaysPrompt(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", Streak: 7, ActivityCount: 7, LastReportDate: now.AddDate(0, 0, -1)},
+	}}
+	prompts := &mockPromptRepo{prompts: map[string]*domain.ReportPrompt{
+		"111@g.us/OLD": {MessageID: "OLD", ChatID: "111@g.us", SentAt: now.AddDate(0, 0, -1)},
+	}}
+
+	// The reminder becomes today's prompt
+	sender := &mockMentionSender{}
+	reminderUC := usecase.NewSendReminderUsecase(repo, sender, "111@g.us", 5)
+	reminderUC.SetPrompts(prompts, sender)
+	if err := reminderUC.Execute(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if prompts.prompts["111@g.us/MSG1"] == nil {
+		t.Fatalf("Expected the reminder stored as a prompt, got %v", prompts.prompts)
+	}
+
+	uc := usecase.NewReactionReportUsecase(prompts, usecase.NewReportActivityUsecase(repo))
+	react := func(messageID, userID, emoji string) bool {
+		counted, err := uc.Execute(ctx, "111@g.us", messageID, userID, "Member", emoji, now)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return counted
+	}
+
+	if react("MSG1", "628111", "👍") || react("OTHER", "628111", "🔥") || react("OLD", "628111", "🔥") {
+		t.Error("Expected other reactions, other messages and yesterday's prompt ignored")
+	}
+	if !react("MSG1", "628111", "✅\ufe0f") {
+		t.Error("Expected ✅ on today's prompt to count")
+	}
+	if react("MSG1", "628111", "🔥") {
+		t.Error("Expected a second reaction on the same day not to count")
+	}
+	if alice := repo.reports["628111"]; alice.Streak != 8 || alice.ActivityCount != 8 {
+		t.Errorf("Expected Alice's streak to grow once, got %+v", alice)
+	}
+	if !react("MSG1", "628999", "🔥") || repo.reports["628999"] == nil {
+		t.Error("Expected a lurker's first reaction to count as their first report")
+	}
+}
+
+func TestReactionReport_CountsRepliesDescribingAnActivity(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{}}
+	prompts := &mockPromptRepo{prompts: map[string]*domain.ReportPrompt{}}
+
+	promptUC := usecase.NewSendPromptUsecase(prompts, &mockMentionSender{}, "111@g.us")
+	if err := promptUC.Execute(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	uc := usecase.NewReactionReportUsecase(prompts, usecase.NewReportActivityUsecase(repo))
+	reply := func(quotedID, text string) (string, bool) {
+		answer, counted, err := uc.ExecuteReply(ctx, "111@g.us", quotedID, "628111", "Alice", text, now)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return answer, counted
+	}
+
+	if _, counted := reply("MSG1", "semangat semua!"); counted {
+		t.Error("Expected a reply without an activity ignored")
+	}
+	if _, counted := reply("OTHER", "lari 5km"); counted {
+		t.Error("Expected a reply to another message ignored")
+	}
+	answer, counted := reply("MSG1", "lari 5km")
+	if !counted || answer == "" {
+		t.Fatalf("Expected a reply with an activity to count and be answered, got %q", answer)
+	}
+	if alice := repo.reports["628111"]; alice == nil || alice.ActivityCount != 1 {
+		t.Errorf("Expected Alice's first report, got %+v", alice)
+	}
+}
@@ -0,0 +1,26 @@
+package usecase
+
+// Reply is what the bot sends back for a command. Text-only commands fill
+// Text; media commands attach an image, document, or GIF and use Text as
+// the caption. A sticker has no caption and follows the text.
+type Reply struct {
+	Text          string
+	Image         []byte
+	ImageMimeType string
+
+	Document         []byte
+	DocumentName     string
+	DocumentMimeType string
+
+	GIF     []byte // MP4 sent as a looping GIF
+	Sticker []byte // WebP sent as a sticker after the text
+
+	// Private sends the reply to the sender's personal chat instead of the
+	// chat the command came from.
+	Private bool
+
+	// OutboxID is the message ID the reply was queued with in the outbox,
+	// in the transaction of the change it acknowledges. The reply must be
+	// sent with it, so the queued row is marked sent. Empty = not queued.
+	OutboxID string
+}
@@ -2,79 +2,372 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/fardannozami/whatsapp-gateway/internal/app/format"
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
 )
 
 type ReportActivityUsecase struct {
-	repo domain.ReportRepository
+	repo       domain.ReportRepository
+	activities domain.ActivityRepository
+	events     domain.ReportEventRepository
+	settings   domain.SettingsRepository
+	tx         domain.Transactor
+	challenge  domain.Challenge
+	media      CelebrationMedia
+	rewards    []RewardRule
+	loader     RewardMedia
+	publisher  EventPublisher
+	outbox     domain.OutboxRepository
+	newID      func() string
+
+	// Two #lapor of the same member arriving together are handled one
+	// after the other, so the second sees the first as already reported
+	members keyedMutex
 }
 
 func NewReportActivityUsecase(repo domain.ReportRepository) *ReportActivityUsecase {
 	return &ReportActivityUsecase{repo: repo}
 }
 
+// SetActivityRepository enables the per-report activity log. Without it only
+// the aggregated streak/count row is kept.
+func (uc *ReportActivityUsecase) SetActivityRepository(activities domain.ActivityRepository) {
+	uc.activities = activities
+}
+
+// SetEventRepository records every counted #lapor in the report event
+// stream, so it can be undone or replayed later.
+func (uc *ReportActivityUsecase) SetEventRepository(events domain.ReportEventRepository) {
+	uc.events = events
+}
+
+// SetSettingsRepository enables personal targets in the acknowledgment.
+func (uc *ReportActivityUsecase) SetSettingsRepository(settings domain.SettingsRepository) {
+	uc.settings = settings
+}
+
+// SetTransactor writes the report row, its activity-log entry and the
+// queued acknowledgment in one transaction, so a failure can't leave one
+// without the others.
+func (uc *ReportActivityUsecase) SetTransactor(tx domain.Transactor) {
+	uc.tx = tx
+}
+
+// SetOutbox queues the acknowledgment of a #lapor in the outbox together
+// with the report, under a message ID from newID that the reply is then
+// sent with. A report saved just before the bot stops is still answered by
+// whatever resends unsent replies.
+func (uc *ReportActivityUsecase) SetOutbox(outbox domain.OutboxRepository, newID func() string) {
+	uc.outbox = outbox
+	uc.newID = newID
+}
+
+// SetChallenge enables a progress bar toward the challenge length in the
+// acknowledgment. A personal target, when set, takes precedence. With a
+// start date the acknowledgment also counts down the days left and tells
+// whether the member is on pace.
+func (uc *ReportActivityUsecase) SetChallenge(challenge domain.Challenge) {
+	uc.challenge = challenge
+}
+
+// SetRewards adds the text of the reward rules a report reaches to its
+// acknowledgment, with the media of the first one. Reward media takes the
+// place of a random celebration.
+func (uc *ReportActivityUsecase) SetRewards(rules []RewardRule, media RewardMedia) {
+	uc.rewards = rules
+	uc.loader = media
+}
+
+// SetCelebrations sends a random GIF or image with the acknowledgment of
+// the report that reaches the member's target or completes the challenge.
+func (uc *ReportActivityUsecase) SetCelebrations(media CelebrationMedia) {
+	uc.media = media
+}
+
+// SetEventPublisher publishes a domain event for every counted #lapor, a
+// streak that started over and a finished challenge.
+func (uc *ReportActivityUsecase) SetEventPublisher(publisher EventPublisher) {
+	uc.publisher = publisher
+}
+
 func (uc *ReportActivityUsecase) Execute(ctx context.Context, userID, name string) (string, error) {
-	report, err := uc.repo.GetReport(ctx, userID)
+	return uc.ExecuteWithMessage(ctx, userID, name, "")
+}
+
+// ExecuteWithMessage records a report like Execute, keeping the original
+// message text so the activity type (e.g. "#lapor lari") can be logged.
+func (uc *ReportActivityUsecase) ExecuteWithMessage(ctx context.Context, userID, name, message string) (string, error) {
+	reply, _, err := uc.Submit(ctx, userID, name, message)
+	return reply, err
+}
+
+// ExecuteReply is ExecuteWithMessage for the #lapor command in chatID,
+// which also sends the media of reward rules and celebrates big milestones.
+// With an outbox the acknowledgment is queued for chatID.
+func (uc *ReportActivityUsecase) ExecuteReply(ctx context.Context, chatID, userID, name, message string) (*Reply, error) {
+	result, err := uc.submit(ctx, chatID, userID, name, message)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	reply := &Reply{Text: result.text, OutboxID: result.outboxID}
+	switch {
+	case result.media != "":
+		return reward(ctx, uc.loader, result.media, reply), nil
+	case result.milestone:
+		return celebrate(ctx, uc.media, reply), nil
 	}
+	return reply, nil
+}
 
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+// Submit is ExecuteWithMessage that also tells whether the report counted,
+// as opposed to a second report of the day or one from an unverified
+// member.
+func (uc *ReportActivityUsecase) Submit(ctx context.Context, userID, name, message string) (string, bool, error) {
+	result, err := uc.submit(ctx, "", userID, name, message)
+	if err != nil {
+		return "", false, err
+	}
+	return result.text, result.counted, nil
+}
+
+// submission is the outcome of a #lapor.
+type submission struct {
+	text      string
+	counted   bool
+	milestone bool   // reached the member's target or completed the challenge
+	media     string // of the reward rules reached, empty = none
+	outboxID  string // the acknowledgment was queued under, empty = not queued
+}
 
-	if report != nil {
-		lastReport := report.LastReportDate
-		lastReportDate := time.Date(lastReport.Year(), lastReport.Month(), lastReport.Day(), 0, 0, 0, 0, time.UTC)
+// submit records the report and builds its acknowledgment, queued for
+// chatID when there is an outbox and a chat.
+func (uc *ReportActivityUsecase) submit(ctx context.Context, chatID, userID, name, message string) (*submission, error) {
+	unlock := uc.members.Lock(userID)
+	defer unlock()
 
-		if lastReportDate.Equal(today) {
-			return fmt.Sprintf("%s sudah laporan hari ini, ayo jangan curang! 😉", name), nil
+	var settings *domain.UserSettings
+	if uc.settings != nil {
+		var err error
+		settings, err = uc.settings.GetSettings(ctx, userID)
+		if err != nil {
+			return nil, err
 		}
+	}
+	// New members are counted once they answered the verification with #join
+	if unverified(settings) {
+		return &submission{text: fmt.Sprintf("Halo %s, ketik #join dulu untuk ikut tantangan ya 🙏", name)}, nil
+	}
+	// The name chosen in #join wins over the WhatsApp name
+	if settings != nil && settings.DisplayName != "" {
+		name = settings.DisplayName
+	}
+
+	// The streak a #lapor after a missed day ends, for EventStreakBroken
+	var previous *domain.Report
+	if uc.publisher != nil {
+		var err error
+		if previous, err = uc.repo.GetReport(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	var result *submission
+	report, err := uc.saveReport(ctx, userID, name, message, func(ctx context.Context, report *domain.Report) error {
+		var err error
+		if result, err = uc.acknowledge(ctx, report, name, settings); err != nil {
+			return err
+		}
+		return uc.queueReply(ctx, chatID, result)
+	})
+	if errors.Is(err, domain.ErrAlreadyReported) {
+		return &submission{text: fmt.Sprintf("%s sudah laporan hari ini, ayo jangan curang! 😉", name)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.publisher != nil {
+		publishEvents(ctx, uc.publisher, reportEvents(report, previous, message, uc.challenge)...)
+	}
+	return result, nil
+}
+
+// acknowledge builds the reply to a counted #lapor.
+func (uc *ReportActivityUsecase) acknowledge(ctx context.Context, report *domain.Report, name string, settings *domain.UserSettings) (*submission, error) {
+	days, err := challengeDays(ctx, uc.activities, uc.challenge, report)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := fmt.Sprintf("Laporan diterima, %s sudah berkeringat %d hari. Lanjutkan 🔥 (streak %d hari)", name, report.ActivityCount, report.Streak)
 
-		// Calculate streak (simplified: if last report was yesterday, increment. Else reset?
-		// Requirement says "36 days streak", "Day 31 💔".
-		// 💔 implies they broke the streak but we still track "Day X".
-		// The prompt says "Recap day 37... 45 lose the streak 💔". This implies if they missed a day, they lose streak status but maybe the day count is preserved or it's just a display thing?
-		// Let's assume for #lapor:
-		// if last report was yesterday -> streak++
-		// if last report was older -> reset streak to 1? or just increment count?
-		// The prompt example "Laporan diterima, {wa name} sudah berkeringat {counting day} hari." suggests a cumulative count or current streak.
-		// Let's implement: If reported yesterday -> Streak++. Else -> Streak = 1 (new streak).
-
-		// Wait, the prompt says "Day 31 💔". This implies a Challenge context where "Day X" is the global challenge day, and "Streak" is personal.
-		// BUT, the specific response for #lapor is: "sudah berkeringat {counting day} hari."
-		// And the leaderboard splits into "Streak 🔥" and "Day X 💔".
-		// This implies we track the Streak. If they report today, we update the streak.
-
-		// Let's implement robust streak logic:
-		// If last report was yesterday (today - 1 day), streak++.
-		// If last report was today (already handled above).
-		// If last report was before yesterday, streak = 1.
-
-		yesterday := today.AddDate(0, 0, -1)
-		if lastReportDate.Equal(yesterday) {
-			report.Streak++
-		} else {
-			report.Streak = 1
+	target := 0
+	if settings != nil {
+		target = settings.Target
+	}
+
+	milestone := false
+	switch {
+	case target > 0:
+		reply += "\n🎯 " + format.ProgressBar(days, target, format.DefaultProgressWidth)
+		if days == target {
+			reply += fmt.Sprintf("\n\n🎉 Selamat %s, target %d hari tercapai! 🏆", name, target)
+			milestone = true
 		}
-		report.ActivityCount++
-		report.Name = name // Update name if changed
-		report.LastReportDate = now
-	} else {
-		report = &domain.Report{
-			UserID:         userID,
-			Name:           name,
-			Streak:         1,
-			ActivityCount:  1,
-			LastReportDate: now,
+	case uc.challenge.Days > 0:
+		reply += "\n" + format.ProgressBar(days, uc.challenge.Days, format.DefaultProgressWidth)
+		if days == uc.challenge.Days {
+			reply += fmt.Sprintf("\n\n🏁 Selamat %s, %d hari tantangan tuntas! 🏆", name, uc.challenge.Days)
+			milestone = true
 		}
 	}
 
-	if err := uc.repo.UpsertReport(ctx, report); err != nil {
-		return "", err
+	reply, media := rewards(uc.rewards, report, name, reply)
+
+	goal := target
+	if goal == 0 {
+		goal = uc.challenge.Days
+	}
+	if pace := paceLine(uc.challenge, days, goal, time.Now()); pace != "" {
+		reply += "\n" + pace
+	}
+	return &submission{text: reply, counted: true, milestone: milestone, media: media}, nil
+}
+
+// challengeDays returns the days the member reported since the challenge
+// started, so a target or a second challenge doesn't start half full with
+// earlier reports. Without a start date or activity log every report counts.
+func challengeDays(ctx context.Context, activities domain.ActivityRepository, challenge domain.Challenge, report *domain.Report) (int, error) {
+	start := challenge.Start
+	if start.IsZero() || activities == nil {
+		return report.ActivityCount, nil
+	}
+
+	loc := report.LastReportDate.Location()
+	reported, err := activities.GetActivities(ctx, domain.ActivityFilter{
+		UserID: report.UserID,
+		Since:  time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc),
+	})
+	if err != nil {
+		return 0, err
+	}
+	days := make(map[string]bool)
+	for _, a := range reported {
+		days[a.ReportedAt.In(loc).Format("2006-01-02")] = true
 	}
+	return len(days), nil
+}
+
+// queueReply records the acknowledgment in the outbox before it is sent.
+func (uc *ReportActivityUsecase) queueReply(ctx context.Context, chatID string, result *submission) error {
+	if uc.outbox == nil || chatID == "" {
+		return nil
+	}
+	msg := &domain.OutboxMessage{MessageID: uc.newID(), ChatID: chatID, Text: result.text, QueuedAt: time.Now()}
+	if err := uc.outbox.QueueReply(ctx, msg); err != nil {
+		return fmt.Errorf("failed to queue reply: %w", err)
+	}
+	result.outboxID = msg.MessageID
+	return nil
+}
 
-	return fmt.Sprintf("Laporan diterima, %s sudah berkeringat %d hari. Lanjutkan 🔥 (streak %d hari)", name, report.ActivityCount, report.Streak), nil
+// reportEvents returns the domain events of a counted #lapor.
+func reportEvents(report, previous *domain.Report, message string, challenge domain.Challenge) []*domain.DomainEvent {
+	event := func(eventType string) *domain.DomainEvent {
+		return &domain.DomainEvent{
+			Type:          eventType,
+			At:            report.LastReportDate,
+			UserID:        report.UserID,
+			Name:          report.Name,
+			Streak:        report.Streak,
+			ActivityCount: report.ActivityCount,
+		}
+	}
+
+	accepted := event(domain.EventReportAccepted)
+	detail := activity.Parse(message)
+	accepted.ActivityType, accepted.DurationMinutes, accepted.DistanceKm = detail.Type, detail.DurationMinutes, detail.DistanceKm
+	events := []*domain.DomainEvent{accepted}
+
+	if previous != nil && report.Streak == 1 && previous.Streak > 0 {
+		broken := event(domain.EventStreakBroken)
+		broken.PreviousStreak = previous.Streak
+		events = append(events, broken)
+	}
+	if challenge.Days > 0 && report.ActivityCount == challenge.Days {
+		finished := event(domain.EventChallengeFinished)
+		finished.ChallengeDays = challenge.Days
+		events = append(events, finished)
+	}
+	return events
+}
+
+// paceLine counts down the days left of a running challenge and tells
+// whether count reports keep the member on pace for goal days.
+func paceLine(challenge domain.Challenge, count, goal int, now time.Time) string {
+	left := challenge.DaysLeft(now)
+	if left < 0 {
+		return ""
+	}
+	countdown := fmt.Sprintf("⏳ %d hari tersisa", left)
+	if left == 0 {
+		countdown = "⏳ Hari terakhir tantangan"
+	}
+
+	switch {
+	case goal <= 0 || count >= goal:
+		return countdown
+	case goal-count > left:
+		return fmt.Sprintf("%s, target %d hari tidak terkejar lagi – tetap semangat 💪", countdown, goal)
+	case count < challenge.Expected(goal, now):
+		return fmt.Sprintf("%s, tertinggal %d hari dari target %d hari 🏃", countdown, challenge.Expected(goal, now)-count, goal)
+	}
+	return fmt.Sprintf("%s, sesuai target %d hari 👍", countdown, goal)
+}
+
+// saveReport counts one #lapor on the member's report, records the event,
+// logs the activity and runs then with the updated report, all in the same
+// transaction when the backend has them.
+func (uc *ReportActivityUsecase) saveReport(ctx context.Context, userID, name, message string, then func(ctx context.Context, report *domain.Report) error) (*domain.Report, error) {
+	now := time.Now()
+
+	var report *domain.Report
+	err := withTx(ctx, uc.tx, func(ctx context.Context) error {
+		var err error
+		report, err = uc.repo.SubmitReport(ctx, userID, name, now)
+		if err != nil {
+			return err
+		}
+		if uc.events != nil {
+			event := &domain.ReportEvent{UserID: userID, Type: domain.ReportSubmitted, At: now, Name: name, Actor: userID}
+			if err := uc.events.AppendEvent(ctx, event); err != nil {
+				return err
+			}
+		}
+		if uc.activities != nil {
+			detail := activity.Parse(message)
+			err := uc.activities.AddActivity(ctx, &domain.Activity{
+				UserID:          userID,
+				Name:            name,
+				ActivityType:    detail.Type,
+				DurationMinutes: detail.DurationMinutes,
+				DistanceKm:      detail.DistanceKm,
+				Message:         message,
+				ReportedAt:      now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return then(ctx, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
 }
@@ -2,6 +2,10 @@ package usecase_test
 
 import (
 	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -22,6 +26,15 @@ func (m *mockRepo) UpsertReport(ctx context.Context, report *domain.Report) erro
 	return nil
 }
 
+func (m *mockRepo) SubmitReport(ctx context.Context, userID, name string, at time.Time) (*domain.Report, error) {
+	report, err := domain.ApplyReport(m.reports[userID], userID, name, at)
+	if err != nil {
+		return nil, err
+	}
+	m.reports[userID] = report
+	return report, nil
+}
+
 func (m *mockRepo) GetAllReports(ctx context.Context) ([]*domain.Report, error) {
 	var result []*domain.Report
 	for _, r := range m.reports {
@@ -30,6 +43,56 @@ func (m *mockRepo) GetAllReports(ctx context.Context) ([]*domain.Report, error)
 	return result, nil
 }
 
+// ListReports mirrors the SQL implementation: filter, sort with user ID as
+// tie-breaker, skip up to query.After and cut at the limit.
+func (m *mockRepo) ListReports(ctx context.Context, q domain.ReportQuery) ([]*domain.Report, error) {
+	less := func(a, b *domain.Report) bool {
+		switch q.Sort {
+		case domain.ReportSortStreak:
+			if a.Streak != b.Streak {
+				return a.Streak > b.Streak
+			}
+		case domain.ReportSortName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		default:
+			if a.ActivityCount != b.ActivityCount {
+				return a.ActivityCount > b.ActivityCount
+			}
+		}
+		return a.UserID < b.UserID
+	}
+
+	var result []*domain.Report
+	for _, r := range m.reports {
+		if q.UserIDs != nil && !slices.Contains(q.UserIDs, r.UserID) {
+			continue
+		}
+		if (!q.Since.IsZero() && r.LastReportDate.Before(q.Since)) || (!q.Until.IsZero() && !r.LastReportDate.Before(q.Until)) {
+			continue
+		}
+		if q.After != nil && !less(q.After, r) {
+			continue
+		}
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool { return less(result[i], result[j]) })
+	if q.Limit > 0 && len(result) > q.Limit {
+		result = result[:q.Limit]
+	}
+	return result, nil
+}
+
+func (m *mockRepo) DeleteReport(ctx context.Context, userID string) error {
+	delete(m.reports, userID)
+	return nil
+}
+
+func (m *mockRepo) DeleteLIDMappings(ctx context.Context, phone string) error {
+	return nil
+}
+
 func (m *mockRepo) ResolveLIDToPhone(ctx context.Context, lid string) string {
 	return lid
 }
@@ -209,15 +272,54 @@ func TestStreak_LongGap_StreakResets(t *testing.T) {
 	}
 }
 
+func TestStreak_SimultaneousReports_HandledOneByOne(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	uc := usecase.NewReportActivityUsecase(repo)
+	ctx := context.Background()
+
+	// mockRepo is not safe for concurrent use: the race detector catches
+	// two reports of the same member running at once
+	replies := make(chan string, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			msg, err := uc.Execute(ctx, "user1", "Gita")
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			replies <- msg
+		}()
+	}
+	wg.Wait()
+	close(replies)
+
+	accepted := 0
+	for msg := range replies {
+		if strings.HasPrefix(msg, "Laporan diterima") {
+			accepted++
+		}
+	}
+	if accepted != 1 {
+		t.Errorf("Expected exactly 1 accepted report, got %d", accepted)
+	}
+	if r := repo.reports["user1"]; r.ActivityCount != 1 {
+		t.Errorf("Expected the day to be counted once, got %d", r.ActivityCount)
+	}
+}
+
 // =============================================================================
 // LEADERBOARD DISPLAY LOGIC
 // =============================================================================
 //
 // Active 🔥: Reported today OR yesterday (still has time to report today)
-// Lost 💔: Last report was before yesterday (streak broken)
+// Lost 💔: Last report was before yesterday (streak broken, shown as 0)
+//
+// Each line shows the current streak and the total: "Nama – 12🔥 (total 25)"
 //
 // Ranking: By ActivityCount (total days), NOT by streak
-// Someone with 30 days 💔 ranks above someone with 25 days 🔥
+// Someone with 0💔 (total 30) ranks above someone with 25🔥 (total 25)
 //
 // =============================================================================
 
@@ -270,14 +372,191 @@ func TestLeaderboard_RanksByActivityCount(t *testing.T) {
 	}
 
 	// Verify emojis
-	if !containsSubstring(result, "HighTotal_LostStreak - 30 days 💔") {
+	if !containsSubstring(result, "HighTotal_LostStreak – 0💔 (total 30)") {
 		t.Errorf("Lost streak user should have 💔 emoji")
 	}
-	if !containsSubstring(result, "MediumTotal_ActiveStreak - 25 days 🔥") {
+	if !containsSubstring(result, "MediumTotal_ActiveStreak – 25🔥 (total 25)") {
 		t.Errorf("Active streak user should have 🔥 emoji")
 	}
 }
 
+func TestLeaderboard_LineTemplate(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 12, LastReportDate: time.Now()},
+	}}
+	uc := usecase.NewGetLeaderboardUsecase(repo)
+	ctx := context.Background()
+
+	if err := uc.SetLineTemplate("{{.Rank}}) {{.Name}}: {{.Total}} hari{{if .Today}} ✅{{end}}"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, run := range []func(ctx context.Context) (string, error){uc.Execute, func(ctx context.Context) (string, error) { return uc.ExecuteTop(ctx, 0, usecase.LeaderboardStyle{}) }} {
+		result, err := run(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !containsSubstring(result, "1) Alice: 12 hari ✅\n") {
+			t.Errorf("Expected the custom line, got '%s'", result)
+		}
+	}
+
+	for _, bad := range []string{"", "{{.Rank", "{{.Umur}}"} {
+		if err := uc.SetLineTemplate(bad); err == nil {
+			t.Errorf("'%s': expected an error", bad)
+		}
+	}
+}
+
+func TestLeaderboard_TieBreaks(t *testing.T) {
+	now := time.Now()
+	morning := time.Date(now.Year(), now.Month(), now.Day(), 7, 0, 0, 0, now.Location())
+	// Everyone has reported 10 days
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Budi", Streak: 4, BestStreak: 9, ActivityCount: 10, LastReportDate: morning},
+		"user2": {UserID: "user2", Name: "Ani", Streak: 4, BestStreak: 4, ActivityCount: 10, LastReportDate: morning},
+		"user3": {UserID: "user3", Name: "Cici", Streak: 2, BestStreak: 9, ActivityCount: 10, LastReportDate: morning},
+		"user4": {UserID: "user4", Name: "Dodi", Streak: 3, BestStreak: 3, ActivityCount: 10, LastReportDate: morning.AddDate(0, 0, -1)},
+	}}
+	uc := usecase.NewGetLeaderboardUsecase(repo)
+	ctx := context.Background()
+	if err := uc.SetLineTemplate("{{.Rank}}. {{.Name}}"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Earlier last report, then longer best streak, then the name; the
+	// same on every call even though the reports come in random order
+	want := "1. Dodi\n2. Budi\n3. Cici\n4. Ani\n"
+	for i := 0; i < 10; i++ {
+		result, err := uc.Execute(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !containsSubstring(result, want) {
+			t.Fatalf("Expected %q, got '%s'", want, result)
+		}
+	}
+
+	tieBreaks, err := usecase.ParseTieBreaks("name")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	uc.SetBoards(usecase.NewBoardBuilder(repo, nil, tieBreaks))
+	result, err := uc.Execute(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "1. Ani\n2. Budi\n3. Cici\n4. Dodi\n"; !containsSubstring(result, want) {
+		t.Errorf("Expected %q by name only, got '%s'", want, result)
+	}
+
+	if got, err := usecase.ParseTieBreaks(""); err != nil || len(got) != len(usecase.DefaultTieBreaks) {
+		t.Errorf("Expected the default tie-breaks for an empty list, got %v, %v", got, err)
+	}
+	for _, bad := range []string{"umur", "name,name", "name,"} {
+		if _, err := usecase.ParseTieBreaks(bad); err == nil {
+			t.Errorf("'%s': expected an error", bad)
+		}
+	}
+}
+
+type mockSnapshotRepo struct {
+	days map[string][]*domain.SnapshotEntry
+}
+
+func (m *mockSnapshotRepo) SaveSnapshot(ctx context.Context, day string, entries []*domain.SnapshotEntry) error {
+	m.days[day] = entries
+	return nil
+}
+
+func (m *mockSnapshotRepo) GetSnapshot(ctx context.Context, day string) ([]*domain.SnapshotEntry, error) {
+	return m.days[day], nil
+}
+
+func (m *mockSnapshotRepo) GetSnapshots(ctx context.Context, filter domain.SnapshotFilter) ([]*domain.SnapshotEntry, error) {
+	days := make([]string, 0, len(m.days))
+	for day := range m.days {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	var entries []*domain.SnapshotEntry
+	for _, day := range days {
+		for _, e := range m.days[day] {
+			if filter.UserID == "" || e.UserID == filter.UserID {
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries, nil
+}
+
+func (m *mockSnapshotRepo) DeleteSnapshots(ctx context.Context, filter domain.SnapshotFilter) error {
+	return nil
+}
+
+func (m *mockSnapshotRepo) InitTable(ctx context.Context) error {
+	return nil
+}
+
+func TestLeaderboard_TrendSinceYesterday(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 12, ActivityCount: 12, LastReportDate: now},
+		"user2": {UserID: "user2", Name: "Bob", Streak: 11, ActivityCount: 11, LastReportDate: now},
+		"user3": {UserID: "user3", Name: "Cici", Streak: 9, ActivityCount: 9, LastReportDate: now},
+		"user4": {UserID: "user4", Name: "Dodi", Streak: 1, ActivityCount: 1, LastReportDate: now},
+	}}
+	uc := usecase.NewGetLeaderboardUsecase(repo)
+	ctx := context.Background()
+
+	// Without snapshots the lines have no mark
+	result, err := uc.Execute(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "1. Alice – 12🔥 (total 12)\n") {
+		t.Errorf("Expected no trend without snapshots, got '%s'", result)
+	}
+
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+	uc.SetSnapshots(&mockSnapshotRepo{days: map[string][]*domain.SnapshotEntry{
+		yesterday: {
+			{Day: yesterday, UserID: "user2", Name: "Bob", Rank: 1},
+			{Day: yesterday, UserID: "user1", Name: "Alice", Rank: 2},
+			{Day: yesterday, UserID: "user3", Name: "Cici", Rank: 3},
+		},
+		// Only yesterday counts
+		now.AddDate(0, 0, -2).Format("2006-01-02"): {
+			{UserID: "user4", Name: "Dodi", Rank: 1},
+		},
+	}})
+	result, err = uc.Execute(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"1. ▲ Alice – 12🔥 (total 12)\n",
+		"2. ▼ Bob – 11🔥 (total 11)\n",
+		"3. = Cici – 9🔥 (total 9)\n",
+		"4. Dodi – 1🔥 (total 1)\n", // new on the board
+	} {
+		if !containsSubstring(result, want) {
+			t.Errorf("Expected '%s', got '%s'", want, result)
+		}
+	}
+
+	if err := uc.SetLineTemplate("{{.Rank}}. {{.Name}} {{.Trend}}{{if .Moved}}{{.Moved}}{{end}}"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err = uc.ExecuteTop(ctx, 2, usecase.LeaderboardStyle{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "1. Alice ▲1\n2. Bob ▼-1\n") {
+		t.Errorf("Expected the places moved in #top, got '%s'", result)
+	}
+}
+
 // Helper functions
 func indexOf(s, substr string) int {
 	for i := 0; i <= len(s)-len(substr); i++ {
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// ErrNothingToUndo is returned by Undo when none of the member's reports
+// still counts.
+var ErrNothingToUndo = errors.New("no report to undo")
+
+// ReportEventsUsecase works on the report event stream, the history behind
+// user_reports: audit, undoing a report, resetting a streak, and rebuilding
+// the report rows after the streak rules changed.
+type ReportEventsUsecase struct {
+	repo       domain.ReportRepository
+	events     domain.ReportEventRepository
+	activities domain.ActivityRepository
+	tx         domain.Transactor
+}
+
+func NewReportEventsUsecase(repo domain.ReportRepository, events domain.ReportEventRepository) *ReportEventsUsecase {
+	return &ReportEventsUsecase{repo: repo, events: events}
+}
+
+// SetActivityRepository also removes the activity-log entry of an undone
+// report.
+func (uc *ReportEventsUsecase) SetActivityRepository(activities domain.ActivityRepository) {
+	uc.activities = activities
+}
+
+// SetTransactor writes the event and the rebuilt report row together.
+func (uc *ReportEventsUsecase) SetTransactor(tx domain.Transactor) {
+	uc.tx = tx
+}
+
+// History returns the member's events, oldest first.
+func (uc *ReportEventsUsecase) History(ctx context.Context, userID string) ([]*domain.ReportEvent, error) {
+	return uc.events.GetEvents(ctx, userID)
+}
+
+// Undo revokes the member's latest counted report and returns the report
+// row without it (nil when nothing is left).
+func (uc *ReportEventsUsecase) Undo(ctx context.Context, userID, actor string) (*domain.Report, error) {
+	var report *domain.Report
+	err := withTx(ctx, uc.tx, func(ctx context.Context) error {
+		events, err := uc.events.GetEvents(ctx, userID)
+		if err != nil {
+			return err
+		}
+		last := lastCounted(events)
+		if last == nil {
+			return ErrNothingToUndo
+		}
+
+		revoke := &domain.ReportEvent{UserID: userID, Type: domain.ReportRevoked, At: time.Now(), RefID: last.ID, Actor: actor}
+		if err := uc.events.AppendEvent(ctx, revoke); err != nil {
+			return err
+		}
+		if uc.activities != nil {
+			// The activity was logged with the report's timestamp
+			filter := domain.ActivityFilter{UserID: userID, Since: last.At, Until: last.At.Add(time.Second)}
+			if err := uc.activities.DeleteActivities(ctx, filter); err != nil {
+				return err
+			}
+		}
+		report, err = uc.rebuild(ctx, userID)
+		return err
+	})
+	return report, err
+}
+
+// lastCounted returns the latest ReportSubmitted that wasn't revoked.
+func lastCounted(events []*domain.ReportEvent) *domain.ReportEvent {
+	revoked := make(map[int64]bool)
+	for _, e := range events {
+		if e.Type == domain.ReportRevoked {
+			revoked[e.RefID] = true
+		}
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if e := events[i]; e.Type == domain.ReportSubmitted && !revoked[e.ID] {
+			return e
+		}
+	}
+	return nil
+}
+
+// ResetStreak sets the member's streak back to 0; their total days stay.
+// Returns nil if the member never reported.
+func (uc *ReportEventsUsecase) ResetStreak(ctx context.Context, userID, actor string) (*domain.Report, error) {
+	var report *domain.Report
+	err := withTx(ctx, uc.tx, func(ctx context.Context) error {
+		current, err := uc.repo.GetReport(ctx, userID)
+		if err != nil || current == nil {
+			return err
+		}
+
+		reset := &domain.ReportEvent{UserID: userID, Type: domain.StreakReset, At: time.Now(), Actor: actor}
+		if err := uc.events.AppendEvent(ctx, reset); err != nil {
+			return err
+		}
+		report, err = uc.rebuild(ctx, userID)
+		return err
+	})
+	return report, err
+}
+
+// RebuildAll replays every member's events into user_reports, e.g. after
+// the streak rules changed. Returns how many rows changed.
+func (uc *ReportEventsUsecase) RebuildAll(ctx context.Context) (int, error) {
+	userIDs, err := uc.events.EventUserIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, userID := range userIDs {
+		before, err := uc.repo.GetReport(ctx, userID)
+		if err != nil {
+			return changed, err
+		}
+		after, err := uc.rebuild(ctx, userID)
+		if err != nil {
+			return changed, err
+		}
+		if !sameReport(before, after) {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// rebuild projects the member's events and stores the result when it
+// differs from the current row.
+func (uc *ReportEventsUsecase) rebuild(ctx context.Context, userID string) (*domain.Report, error) {
+	events, err := uc.events.GetEvents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := uc.repo.GetReport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	projected := domain.ProjectReport(userID, events)
+	if projected == nil {
+		if current != nil {
+			return nil, uc.repo.DeleteReport(ctx, userID)
+		}
+		return nil, nil
+	}
+	// Members from before the event stream only have a snapshot, and the
+	// log only knows the streaks since it started
+	if current != nil && projected.FirstReportDate.IsZero() {
+		projected.FirstReportDate = current.FirstReportDate
+	}
+	if current != nil {
+		projected.BestStreak = max(projected.BestStreak, current.BestStreak)
+	}
+	if sameReport(current, projected) {
+		return current, nil
+	}
+	if current != nil {
+		projected.Version = current.Version
+	}
+	if err := uc.repo.UpsertReport(ctx, projected); err != nil {
+		return nil, err
+	}
+	return projected, nil
+}
+
+// recordAdjusted appends an AdminAdjusted snapshot of the report, when the
+// backend keeps events.
+func recordAdjusted(ctx context.Context, events domain.ReportEventRepository, report *domain.Report, actor string) error {
+	if events == nil {
+		return nil
+	}
+	return events.AppendEvent(ctx, &domain.ReportEvent{
+		UserID:         report.UserID,
+		Type:           domain.AdminAdjusted,
+		At:             time.Now(),
+		Name:           report.Name,
+		Streak:         report.Streak,
+		ActivityCount:  report.ActivityCount,
+		LastReportDate: report.LastReportDate,
+		Actor:          actor,
+	})
+}
+
+func sameReport(a, b *domain.Report) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Name == b.Name && a.Streak == b.Streak && a.BestStreak == b.BestStreak && a.ActivityCount == b.ActivityCount &&
+		a.LastReportDate.Equal(b.LastReportDate) && a.FirstReportDate.Equal(b.FirstReportDate)
+}
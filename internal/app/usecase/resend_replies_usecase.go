@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const (
+	// resendAfter gives a queued reply time to go out the normal way,
+	// behind the reply delay, the pacer and, with QUEUE_URL, the workers.
+	resendAfter = 5 * time.Minute
+	// resendWithin leaves older replies alone: the member has moved on.
+	resendWithin = 24 * time.Hour
+)
+
+// QueuedSender sends a reply that was queued in the outbox, under the ID it
+// was queued with.
+type QueuedSender interface {
+	SendQueued(ctx context.Context, msg *domain.OutboxMessage) error
+}
+
+// ResendRepliesUsecase sends the replies that were queued together with
+// the report they acknowledge but never went out, e.g. because the bot
+// stopped right after saving the report. Only the text is resent.
+type ResendRepliesUsecase struct {
+	outbox domain.OutboxRepository
+	sender QueuedSender
+}
+
+func NewResendRepliesUsecase(outbox domain.OutboxRepository, sender QueuedSender) *ResendRepliesUsecase {
+	return &ResendRepliesUsecase{outbox: outbox, sender: sender}
+}
+
+// Execute resends the replies queued between a day and a few minutes
+// before now, oldest first, and stops at the first one that fails.
+func (uc *ResendRepliesUsecase) Execute(ctx context.Context, now time.Time) error {
+	unsent, err := uc.outbox.GetUnsent(ctx, now.Add(-resendWithin), now.Add(-resendAfter))
+	if err != nil {
+		return err
+	}
+	for _, msg := range unsent {
+		if err := uc.sender.SendQueued(ctx, msg); err != nil {
+			return fmt.Errorf("failed to resend reply %s: %w", msg.MessageID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// RESEND REPLIES TESTS
+// =============================================================================
+
+// mockOutbox only implements the reads the resend job makes
+type mockOutbox struct {
+	domain.OutboxRepository
+	unsent       []*domain.OutboxMessage
+	since, until time.Time
+}
+
+func (m *mockOutbox) GetUnsent(ctx context.Context, since, until time.Time) ([]*domain.OutboxMessage, error) {
+	m.since, m.until = since, until
+	return m.unsent, nil
+}
+
+type mockQueuedSender struct {
+	sent []string
+	err  error
+}
+
+func (m *mockQueuedSender) SendQueued(ctx context.Context, msg *domain.OutboxMessage) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg.MessageID)
+	return nil
+}
+
+func TestResendReplies_SendsUnsent(t *testing.T) {
+	now := time.Date(2026, 4, 15, 8, 0, 0, 0, time.Local)
+	outbox := &mockOutbox{unsent: []*domain.OutboxMessage{
+		{MessageID: "3EB01", ChatID: "111@g.us", Text: "Laporan diterima, Alice", QueuedAt: now.Add(-time.Hour)},
+		{MessageID: "3EB02", ChatID: "111@g.us", Text: "Laporan diterima, Bob", QueuedAt: now.Add(-10 * time.Minute)},
+	}}
+	sender := &mockQueuedSender{}
+
+	if err := usecase.NewResendRepliesUsecase(outbox, sender).Execute(context.Background(), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sender.sent) != 2 || sender.sent[0] != "3EB01" || sender.sent[1] != "3EB02" {
+		t.Errorf("Expected both replies resent under their IDs, got %v", sender.sent)
+	}
+	// Recent replies may still be on their way, old ones are stale
+	if !outbox.until.Before(now.Add(-time.Minute)) || !outbox.since.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("Expected replies from the last day but not the last minutes, got %v to %v", outbox.since, outbox.until)
+	}
+
+	sender = &mockQueuedSender{err: errors.New("disconnected")}
+	if err := usecase.NewResendRepliesUsecase(outbox, sender).Execute(context.Background(), now); err == nil {
+		t.Error("Expected the send error")
+	}
+}
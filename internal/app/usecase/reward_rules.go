@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// RewardRule is a message, and optionally media, added to the #lapor that
+// reaches a milestone, so a group can keep its own rituals (7 days → a
+// shout-out, 30 days → a medal sticker) without code changes. Exactly one
+// of Days and Streak is set.
+type RewardRule struct {
+	Days   int    `json:"days"`   // total days reported
+	Streak int    `json:"streak"` // days in a row
+	Text   string `json:"text"`   // text/template, see RewardData
+	Media  string `json:"media"`  // file or http(s) URL: .mp4 as a GIF, .webp as a sticker, other images as a photo
+
+	tmpl *template.Template
+}
+
+// RewardData is what a reward template sees.
+type RewardData struct {
+	Name   string
+	Days   int
+	Streak int
+}
+
+// RewardMedia loads the media of a reward rule.
+type RewardMedia interface {
+	Load(ctx context.Context, source string) (data []byte, mimeType string, err error)
+}
+
+// LoadRewardRules reads a JSON array of rules.
+func LoadRewardRules(r io.Reader) ([]RewardRule, error) {
+	var rules []RewardRule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid reward rules: %w", err)
+	}
+	for i := range rules {
+		if err := rules[i].parse(); err != nil {
+			return nil, fmt.Errorf("reward rule %d: %w", i+1, err)
+		}
+	}
+	return rules, nil
+}
+
+func (r *RewardRule) parse() error {
+	if (r.Days > 0) == (r.Streak > 0) {
+		return fmt.Errorf("set either days or streak")
+	}
+	if strings.TrimSpace(r.Text) == "" && r.Media == "" {
+		return fmt.Errorf("set text, media, or both")
+	}
+	tmpl, err := template.New("reward").Parse(r.Text)
+	if err != nil {
+		return err
+	}
+	r.tmpl = tmpl
+	return nil
+}
+
+// reached reports whether the report just counted reached the rule's
+// milestone.
+func (r *RewardRule) reached(report *domain.Report) bool {
+	if r.Days > 0 {
+		return report.ActivityCount == r.Days
+	}
+	return report.Streak == r.Streak
+}
+
+// rewards appends the text of the rules the report reached to reply, and
+// returns the media of the first of them that has one.
+func rewards(rules []RewardRule, report *domain.Report, name, reply string) (string, string) {
+	media := ""
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.reached(report) {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := rule.tmpl.Execute(&buf, RewardData{Name: name, Days: report.ActivityCount, Streak: report.Streak}); err != nil {
+			log.Printf("Failed to render reward rule: %v", err)
+			continue
+		}
+		if text := strings.TrimSpace(buf.String()); text != "" {
+			reply += "\n\n" + text
+		}
+		if media == "" {
+			media = rule.Media
+		}
+	}
+	return reply, media
+}
+
+// reward attaches the media of a reward rule to the reply. When loading it
+// fails, the reply stays text only.
+func reward(ctx context.Context, loader RewardMedia, source string, reply *Reply) *Reply {
+	if loader == nil {
+		return reply
+	}
+	data, mimeType, err := loader.Load(ctx, source)
+	if err != nil {
+		log.Printf("Failed to load reward media: %v", err)
+		return reply
+	}
+	if mimeType == "image/webp" {
+		reply.Sticker = data
+		return reply
+	}
+	return attach(reply, data, mimeType)
+}
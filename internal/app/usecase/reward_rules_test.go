@@ -0,0 +1,97 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// REWARD RULE TESTS
+// =============================================================================
+
+type fakeRewardMedia struct {
+	loaded []string
+}
+
+func (f *fakeRewardMedia) Load(ctx context.Context, source string) ([]byte, string, error) {
+	f.loaded = append(f.loaded, source)
+	if strings.HasSuffix(source, ".webp") {
+		return []byte("sticker"), "image/webp", nil
+	}
+	return []byte("mp4"), "video/mp4", nil
+}
+
+const testRewards = `[
+	{"days": 7, "text": "🥉 Seminggu penuh, {{.Name}}!"},
+	{"days": 30, "text": "🥇 {{.Days}} hari, {{.Name}} resmi jadi legenda!", "media": "medal.webp"},
+	{"streak": 30, "text": "🔥 Streak {{.Streak}} hari!", "media": "fire.mp4"}
+]`
+
+func TestLoadRewardRules_Validation(t *testing.T) {
+	if _, err := usecase.LoadRewardRules(strings.NewReader(testRewards)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, bad := range []string{
+		`[{"text": "no milestone"}]`,
+		`[{"days": 7, "streak": 7, "text": "both"}]`,
+		`[{"days": 7}]`,
+		`[{"days": 7, "text": "{{.Name"}]`,
+		`{"days": 7}`,
+	} {
+		if _, err := usecase.LoadRewardRules(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected an error for %s", bad)
+		}
+	}
+}
+
+func TestReport_RewardRules(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1)
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 6, ActivityCount: 6, LastReportDate: yesterday},
+		"user2": {UserID: "user2", Name: "Bob", Streak: 29, ActivityCount: 29, LastReportDate: yesterday},
+		"user3": {UserID: "user3", Name: "Citra", Streak: 2, ActivityCount: 10, LastReportDate: yesterday},
+	}}
+	rules, err := usecase.LoadRewardRules(strings.NewReader(testRewards))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	loader := &fakeRewardMedia{}
+	uc := usecase.NewReportActivityUsecase(repo)
+	uc.SetRewards(rules, loader)
+	uc.SetCelebrations(&fakeCelebrations{data: []byte("random"), mimeType: "video/mp4"})
+
+	// Text only
+	reply, err := uc.ExecuteReply(context.Background(), "111@g.us", "user1", "Alice", "#lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(reply.Text, "\n\n🥉 Seminggu penuh, Alice!") || reply.GIF != nil || reply.Sticker != nil {
+		t.Errorf("Expected the 7-day message without media, got %+v", reply)
+	}
+
+	// Both 30-day rules, the media of the first
+	reply, err = uc.ExecuteReply(context.Background(), "111@g.us", "user2", "Bob", "#lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(reply.Text, "🥇 30 hari, Bob resmi jadi legenda!") || !strings.Contains(reply.Text, "🔥 Streak 30 hari!") {
+		t.Errorf("Expected both 30-day messages, got %q", reply.Text)
+	}
+	if string(reply.Sticker) != "sticker" || reply.GIF != nil || len(loader.loaded) != 1 || loader.loaded[0] != "medal.webp" {
+		t.Errorf("Expected only the medal sticker, got %+v after loading %v", reply, loader.loaded)
+	}
+
+	reply, err = uc.ExecuteReply(context.Background(), "111@g.us", "user3", "Citra", "#lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(reply.Text, "\n\n") || reply.Sticker != nil || reply.GIF != nil {
+		t.Errorf("Expected no reward on day 11, got %+v", reply)
+	}
+}
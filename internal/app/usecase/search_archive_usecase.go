@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const (
+	searchResultLimit   = 20
+	searchSnippetLength = 80
+)
+
+// SearchArchiveUsecase looks up past messages by keyword (#cari). It searches
+// the message archive when enabled, otherwise the #lapor texts in the
+// activity log.
+type SearchArchiveUsecase struct {
+	activities domain.ActivityRepository
+	messages   domain.MessageArchiveRepository
+	settings   domain.SettingsRepository
+}
+
+func NewSearchArchiveUsecase(activities domain.ActivityRepository) *SearchArchiveUsecase {
+	return &SearchArchiveUsecase{activities: activities}
+}
+
+// SetMessageArchive searches the full message archive instead of the activity log.
+func (uc *SearchArchiveUsecase) SetMessageArchive(messages domain.MessageArchiveRepository) {
+	uc.messages = messages
+}
+
+// SetSettingsRepository leaves the messages of members in #privat mode out
+// of the results.
+func (uc *SearchArchiveUsecase) SetSettingsRepository(settings domain.SettingsRepository) {
+	uc.settings = settings
+}
+
+// Execute handles "#cari <kata kunci> [dari YYYY-MM-DD] [sampai YYYY-MM-DD]",
+// where dates may also be written as 1/3 or 1/3/2026.
+// A single date limits the search to that day onwards; a second date is the
+// last day included.
+func (uc *SearchArchiveUsecase) Execute(ctx context.Context, args []string) (string, error) {
+	var words []string
+	var dates []time.Time
+	for _, arg := range args {
+		if d, err := parseDate(arg, time.Now()); err == nil {
+			dates = append(dates, d)
+			continue
+		}
+		words = append(words, arg)
+	}
+
+	keyword := strings.Join(words, " ")
+	if keyword == "" {
+		return "Format: #cari <kata kunci> [YYYY-MM-DD] [YYYY-MM-DD]\nContoh: #cari lari 2026-03-01 2026-03-31", nil
+	}
+
+	var since, until time.Time
+	if len(dates) > 0 {
+		since = dates[0]
+	}
+	if len(dates) > 1 {
+		until = dates[1].AddDate(0, 0, 1)
+	}
+
+	private, err := privateUsers(ctx, uc.settings)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	if uc.messages != nil {
+		lines, err = uc.searchMessages(ctx, keyword, since, until, private)
+	} else {
+		lines, err = uc.searchActivities(ctx, keyword, since, until, private)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(lines) == 0 {
+		return fmt.Sprintf("Tidak ada hasil untuk \"%s\" 🔍", keyword), nil
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Hasil pencarian \"%s\" 🔍\n\n", keyword))
+	for _, line := range lines {
+		sb.WriteString(line + "\n")
+	}
+	if len(lines) == searchResultLimit {
+		sb.WriteString(fmt.Sprintf("\n(maks. %d hasil terbaru, persempit dengan tanggal)", searchResultLimit))
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+func (uc *SearchArchiveUsecase) searchMessages(ctx context.Context, keyword string, since, until time.Time, private map[string]bool) ([]string, error) {
+	// Private members could fill a page each, fetch enough to fill it without them
+	messages, err := uc.messages.SearchMessages(ctx, keyword, domain.MessageFilter{Since: since, Until: until, Limit: searchResultLimit * (len(private) + 1)})
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(messages))
+	for _, m := range messages {
+		if private[m.SenderID] {
+			continue
+		}
+		if len(lines) == searchResultLimit {
+			break
+		}
+		lines = append(lines, formatSearchLine(m.SentAt, m.SenderName, m.Text))
+	}
+	return lines, nil
+}
+
+// searchActivities filters the activity log in memory; it only holds one row
+// per member per day, so this stays small.
+func (uc *SearchArchiveUsecase) searchActivities(ctx context.Context, keyword string, since, until time.Time, private map[string]bool) ([]string, error) {
+	activities, err := uc.activities.GetActivities(ctx, domain.ActivityFilter{Since: since, Until: until})
+	if err != nil {
+		return nil, err
+	}
+
+	words := strings.Fields(strings.ToLower(keyword))
+	var lines []string
+	for i := len(activities) - 1; i >= 0 && len(lines) < searchResultLimit; i-- {
+		a := activities[i]
+		if !private[a.UserID] && containsAll(strings.ToLower(a.Message), words) {
+			lines = append(lines, formatSearchLine(a.ReportedAt, a.Name, a.Message))
+		}
+	}
+	return lines, nil
+}
+
+func containsAll(text string, words []string) bool {
+	for _, w := range words {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatSearchLine renders "02-03-2026 07:00 Alice: #lapor lari pagi".
+func formatSearchLine(at time.Time, name, text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) > searchSnippetLength {
+		text = string([]rune(text)[:searchSnippetLength]) + "…"
+	}
+	return fmt.Sprintf("%s %s: %s", at.Local().Format("02-01-2006 15:04"), name, text)
+}
@@ -0,0 +1,55 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// SEARCH TESTS
+// =============================================================================
+
+func TestSearch_ActivityLogFallback(t *testing.T) {
+	march := time.Date(2026, 3, 10, 7, 0, 0, 0, time.Local)
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", Name: "Alice", Message: "#lapor lari pagi 5km", ReportedAt: march.AddDate(0, -1, 0)},
+		{UserID: "user2", Name: "Bob", Message: "#lapor gym", ReportedAt: march},
+		{UserID: "user1", Name: "Alice", Message: "#lapor Lari sore", ReportedAt: march},
+	}}
+
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetSearchUsecase(usecase.NewSearchArchiveUsecase(activities))
+	handleUC.SetAdmins([]string{"admin1"})
+	ctx := context.Background()
+
+	result, err := handleUC.Execute(ctx, "user1", "Alice", "#cari lari")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "khusus admin") {
+		t.Errorf("Expected non-admin to be refused, got '%s'", result)
+	}
+
+	result, err = handleUC.Execute(ctx, "admin1", "Admin", "#cari lari")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "lari pagi") || !containsSubstring(result, "Lari sore") || containsSubstring(result, "gym") {
+		t.Errorf("Expected both lari reports, got '%s'", result)
+	}
+	if indexOf(result, "sore") > indexOf(result, "pagi") {
+		t.Errorf("Expected newest first, got '%s'", result)
+	}
+
+	result, err = handleUC.Execute(ctx, "admin1", "Admin", "#cari lari 2026-03-01 2026-03-31")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if containsSubstring(result, "pagi") || !containsSubstring(result, "sore") {
+		t.Errorf("Expected date range to exclude February, got '%s'", result)
+	}
+}
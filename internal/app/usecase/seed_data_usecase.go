@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
+)
+
+var seedFirstNames = []string{
+	"Ani", "Budi", "Citra", "Dewi", "Eko", "Fajar", "Gita", "Hendra", "Indah", "Joko",
+	"Kartika", "Lukman", "Maya", "Nanda", "Oki", "Putri", "Rizky", "Sari", "Teguh", "Umi",
+	"Vina", "Wahyu", "Yogi", "Zahra", "Agus", "Bayu", "Dian", "Fitri", "Galih", "Rina",
+}
+
+var seedLastNames = []string{
+	"Saputra", "Lestari", "Wijaya", "Pratama", "Hidayat", "Kusuma", "Nugroho", "Rahmawati",
+	"Santoso", "Permata", "Siregar", "Hasibuan", "Wibowo", "Utami", "Setiawan",
+}
+
+// SeedDataUsecase fills an empty database with made-up members and report
+// histories, for development and demos without a live group.
+type SeedDataUsecase struct {
+	repo       domain.ReportRepository
+	activities domain.ActivityRepository
+}
+
+func NewSeedDataUsecase(repo domain.ReportRepository, activities domain.ActivityRepository) *SeedDataUsecase {
+	return &SeedDataUsecase{repo: repo, activities: activities}
+}
+
+// Seed creates users members with up to days days of history ending today.
+// Each member has their own habits: some join late, some report almost
+// daily, some slump after a missed day and some drop out. It refuses to
+// touch a database that already has reports.
+func (uc *SeedDataUsecase) Seed(ctx context.Context, users, days int, rng *rand.Rand) (int, error) {
+	if users < 1 || days < 1 {
+		return 0, errors.New("users and days must be at least 1")
+	}
+	existing, err := uc.repo.GetAllReports(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) > 0 {
+		return 0, fmt.Errorf("database already has %d members, seed an empty database (e.g. another SQLITE_PATH)", len(existing))
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	first := today.AddDate(0, 0, -(days - 1))
+
+	total := 0
+	for i := 0; i < users; i++ {
+		userID := fmt.Sprintf("6280000%06d", i+1)
+		name := seedFirstNames[rng.Intn(len(seedFirstNames))] + " " + seedLastNames[rng.Intn(len(seedLastNames))]
+
+		adherence := 0.35 + rng.Float64()*0.6
+		start := 0
+		if rng.Float64() < 0.3 {
+			start = rng.Intn(days/3 + 1) // joined late
+		}
+		stop := days
+		if rng.Float64() < 0.15 {
+			stop = start + rng.Intn(days-start) + 1 // dropped out
+		}
+
+		var report *domain.Report
+		reportedYesterday := true
+		for day := start; day < stop; day++ {
+			chance := adherence
+			if !reportedYesterday {
+				chance *= 0.6 // one missed day makes the next one likelier
+			}
+			reportedYesterday = rng.Float64() < chance
+			if !reportedYesterday {
+				continue
+			}
+
+			at := first.AddDate(0, 0, day).Add(time.Duration(5*60+rng.Intn(16*60)) * time.Minute)
+			if at.After(now) {
+				at = now
+			}
+			if report == nil {
+				report = &domain.Report{UserID: userID, Name: name}
+			}
+			if !report.LastReportDate.IsZero() && report.LastReportDate.AddDate(0, 0, 1).Format("2006-01-02") == at.Format("2006-01-02") {
+				report.Streak++
+			} else {
+				report.Streak = 1
+			}
+			report.ActivityCount++
+			report.LastReportDate = at
+
+			if uc.activities != nil {
+				message := seedMessage(rng)
+				detail := activity.Parse(message)
+				entry := &domain.Activity{
+					UserID:          userID,
+					Name:            name,
+					ActivityType:    detail.Type,
+					DurationMinutes: detail.DurationMinutes,
+					DistanceKm:      detail.DistanceKm,
+					Message:         message,
+					ReportedAt:      at,
+				}
+				if err := uc.activities.AddActivity(ctx, entry); err != nil {
+					return total, err
+				}
+			}
+			total++
+		}
+
+		if report == nil {
+			continue
+		}
+		if err := uc.repo.UpsertReport(ctx, report); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// seedMessage writes a #lapor message the way members do, sometimes with
+// a duration or distance and sometimes with nothing at all.
+func seedMessage(rng *rand.Rand) string {
+	kind := activity.Types[rng.Intn(len(activity.Types))]
+	if kind == activity.TypeLainnya || rng.Float64() < 0.15 {
+		return "#lapor"
+	}
+
+	message := "#lapor " + kind
+	switch kind {
+	case activity.TypeLari, activity.TypeSepeda, activity.TypeJalan:
+		km := 2 + rng.Float64()*8
+		if kind == activity.TypeSepeda {
+			km *= 3
+		}
+		message += fmt.Sprintf(" %.1fkm", km)
+	}
+	if rng.Float64() < 0.7 {
+		message += fmt.Sprintf(" %d menit", 20+rng.Intn(15)*5)
+	}
+	return message
+}
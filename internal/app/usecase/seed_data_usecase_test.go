@@ -0,0 +1,56 @@
+package usecase_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestSeedData_ConsistentHistories(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report)}
+	activities := &mockActivityRepo{}
+	uc := usecase.NewSeedDataUsecase(repo, activities)
+	ctx := context.Background()
+
+	total, err := uc.Seed(ctx, 30, 40, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	if total == 0 || total != len(activities.activities) {
+		t.Fatalf("Expected one activity per report, got %d reports and %d activities", total, len(activities.activities))
+	}
+
+	// Every report row must match its activity log, so charts and
+	// leaderboards agree
+	byUser := make(map[string][]*domain.Activity)
+	for _, a := range activities.activities {
+		byUser[a.UserID] = append(byUser[a.UserID], a)
+	}
+	broken := false
+	for userID, report := range repo.reports {
+		history := byUser[userID]
+		if report.ActivityCount != len(history) {
+			t.Errorf("%s: count %d but %d activities", userID, report.ActivityCount, len(history))
+		}
+		if !report.LastReportDate.Equal(history[len(history)-1].ReportedAt) || report.LastReportDate.After(time.Now()) {
+			t.Errorf("%s: last report %s does not match the log", userID, report.LastReportDate)
+		}
+		if report.Streak < 1 || report.Streak > report.ActivityCount {
+			t.Errorf("%s: streak %d out of range", userID, report.Streak)
+		}
+		if report.Streak < report.ActivityCount {
+			broken = true
+		}
+	}
+	if !broken {
+		t.Error("Expected some members to have missed days")
+	}
+
+	if _, err := uc.Seed(ctx, 5, 5, rand.New(rand.NewSource(2))); err == nil {
+		t.Error("Expected seeding a non-empty database to be refused")
+	}
+}
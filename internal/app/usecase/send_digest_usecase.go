@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
+)
+
+// Mailer sends an HTML email to one address.
+type Mailer interface {
+	SendMail(ctx context.Context, to, subject, html string) error
+}
+
+// digestStandings is how many members the digest lists.
+const digestStandings = 10
+
+// Digest is the weekly email: the recap of the WhatsApp message, the
+// standings and the highlights of the week.
+type Digest struct {
+	Recap      *WeeklyRecap
+	Standings  []BoardMember // the top of the ranking, like #leaderboard
+	Highlights []Highlight
+}
+
+// Highlight is one member who stood out this week.
+type Highlight struct {
+	Title string // e.g. "Paling rajin"
+	Name  string
+	Value string // e.g. "6 laporan"
+}
+
+// SendDigestUsecase emails the weekly digest to the organizers and to the
+// members who opted in with #email. Each address gets its own email, so
+// members don't see each other's addresses.
+type SendDigestUsecase struct {
+	recap      *GetRecapUsecase
+	reports    domain.ReportRepository
+	settings   domain.SettingsRepository
+	mailer     Mailer
+	organizers []string
+	boards     *BoardBuilder
+}
+
+func NewSendDigestUsecase(recap *GetRecapUsecase, reports domain.ReportRepository, settings domain.SettingsRepository, mailer Mailer, organizers []string) *SendDigestUsecase {
+	return &SendDigestUsecase{recap: recap, reports: reports, settings: settings, mailer: mailer, organizers: organizers}
+}
+
+// SetBoards ranks the digest's standings like #leaderboard.
+func (uc *SendDigestUsecase) SetBoards(boards *BoardBuilder) {
+	uc.boards = boards
+}
+
+// Digest collects the digest of the week of now.
+func (uc *SendDigestUsecase) Digest(ctx context.Context, now time.Time) (*Digest, error) {
+	recap, err := uc.recap.Weekly(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	board, err := boardsOr(uc.boards, uc.reports, uc.settings).Board(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &Digest{Recap: recap, Standings: board.Members}
+	if len(digest.Standings) > digestStandings {
+		digest.Standings = digest.Standings[:digestStandings]
+	}
+	digest.Highlights = weekHighlights(recap.Activities, board.Leader)
+	return digest, nil
+}
+
+// weekHighlights picks the member with the most reports and the longest
+// distance of the week, and the streak leader.
+func weekHighlights(activities []*domain.Activity, leader *BoardMember) []Highlight {
+	type member struct {
+		name    string
+		reports int
+		km      float64
+	}
+	members := make(map[string]*member)
+	for _, a := range activities {
+		m := members[a.UserID]
+		if m == nil {
+			m = &member{}
+			members[a.UserID] = m
+		}
+		m.name = a.Name
+		m.reports++
+		m.km += a.DistanceKm
+	}
+	list := make([]*member, 0, len(members))
+	for _, m := range members {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+
+	var highlights []Highlight
+	var busiest, farthest *member
+	for _, m := range list {
+		if busiest == nil || m.reports > busiest.reports {
+			busiest = m
+		}
+		if m.km > 0 && (farthest == nil || m.km > farthest.km) {
+			farthest = m
+		}
+	}
+	if busiest != nil {
+		highlights = append(highlights, Highlight{Title: "Paling rajin", Name: busiest.name, Value: fmt.Sprintf("%d laporan", busiest.reports)})
+	}
+	if farthest != nil {
+		highlights = append(highlights, Highlight{Title: "Jarak terjauh", Name: farthest.name, Value: activity.FormatDistance(farthest.km)})
+	}
+	if leader != nil {
+		highlights = append(highlights, Highlight{Title: "Streak terpanjang", Name: leader.Name, Value: fmt.Sprintf("%d hari", leader.Streak)})
+	}
+	return highlights
+}
+
+// Render returns the subject and HTML body of the digest.
+func (d *Digest) Render() (subject, html string, err error) {
+	subject = fmt.Sprintf("Recap Mingguan %s – %s", d.Recap.Since.Format("02-01-2006"), d.Recap.Until.Format("02-01-2006"))
+	var buf bytes.Buffer
+	if err := digestTemplate.Execute(&buf, struct {
+		Subject string
+		*Digest
+	}{subject, d}); err != nil {
+		return "", "", err
+	}
+	return subject, buf.String(), nil
+}
+
+// Execute sends the digest of the current week. A failed address does not
+// stop the others.
+func (uc *SendDigestUsecase) Execute(ctx context.Context) error {
+	digest, err := uc.Digest(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	subject, html, err := digest.Render()
+	if err != nil {
+		return err
+	}
+
+	recipients := append([]string{}, uc.organizers...)
+	subscribers, err := uc.settings.GetEmailSettings(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range subscribers {
+		recipients = append(recipients, s.Email)
+	}
+
+	seen := make(map[string]bool)
+	sent := 0
+	for _, to := range recipients {
+		if seen[to] {
+			continue
+		}
+		seen[to] = true
+		if err := uc.mailer.SendMail(ctx, to, subject, html); err != nil {
+			log.Printf("Failed to send the digest to %s: %v", to, err)
+			continue
+		}
+		sent++
+	}
+	if sent == 0 && len(seen) > 0 {
+		return fmt.Errorf("digest not sent to any of %d addresses", len(seen))
+	}
+	return nil
+}
+
+var digestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"km": activity.FormatDistance,
+}).Parse(`<!DOCTYPE html>
+<html lang="id">
+<head><meta charset="utf-8"><title>{{.Subject}}</title></head>
+<body style="margin:0;padding:24px;background:#f4f4f5;font-family:Arial,Helvetica,sans-serif;color:#18181b">
+<div style="max-width:560px;margin:0 auto;background:#ffffff;border-radius:8px;padding:24px">
+<h1 style="margin:0 0 4px;font-size:20px">{{.Subject}}</h1>
+{{with .Recap.ChallengeLine}}<p style="margin:0 0 16px;color:#52525b">{{.}}</p>{{end}}
+<table style="width:100%;border-collapse:collapse;margin:16px 0">
+<tr><td style="padding:4px 0">Total laporan</td><td style="text-align:right"><strong>{{.Recap.Reports}}</strong>{{with .Recap.ReportsChange}} <span style="color:#52525b;font-size:13px">({{.}})</span>{{end}}</td></tr>
+<tr><td style="padding:4px 0">Member aktif</td><td style="text-align:right"><strong>{{.Recap.Members}}</strong>{{with .Recap.MembersChange}} <span style="color:#52525b;font-size:13px">({{.}})</span>{{end}}</td></tr>
+{{if gt .Recap.WeekKm 0.0}}<tr><td style="padding:4px 0">Total jarak minggu ini</td><td style="text-align:right"><strong>{{km .Recap.WeekKm}}</strong></td></tr>{{end}}
+{{if gt .Recap.ChallengeKm 0.0}}<tr><td style="padding:4px 0">Total jarak tantangan</td><td style="text-align:right"><strong>{{km .Recap.ChallengeKm}}</strong></td></tr>{{end}}
+</table>
+{{if .Highlights}}<h2 style="font-size:16px;margin:24px 0 8px">Highlight</h2>
+<ul style="padding-left:20px;margin:0">
+{{range .Highlights}}<li style="margin:4px 0">{{.Title}}: <strong>{{.Name}}</strong> ({{.Value}})</li>
+{{end}}</ul>{{end}}
+{{if .Standings}}<h2 style="font-size:16px;margin:24px 0 8px">Klasemen</h2>
+<table style="width:100%;border-collapse:collapse">
+<tr style="color:#52525b;font-size:13px"><th style="text-align:left;padding:4px">#</th><th style="text-align:left;padding:4px">Nama</th><th style="text-align:right;padding:4px">Total</th><th style="text-align:right;padding:4px">Streak</th></tr>
+{{range .Standings}}<tr style="border-top:1px solid #e4e4e7"><td style="padding:4px">{{.Rank}}</td><td style="padding:4px">{{.Name}}</td><td style="text-align:right;padding:4px">{{.Total}} hari</td><td style="text-align:right;padding:4px">{{if .Streak}}🔥 {{.Streak}}{{else}}-{{end}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Recap.Types}}<h2 style="font-size:16px;margin:24px 0 8px">Breakdown aktivitas</h2>
+<ul style="padding-left:20px;margin:0">
+{{range .Recap.Types}}<li style="margin:4px 0">{{.Label}} - {{.Count}} laporan</li>
+{{end}}</ul>{{end}}
+<p style="margin:24px 0 0;font-size:12px;color:#a1a1aa">Berhenti menerima email ini dengan mengirim #email off ke bot.</p>
+</div>
+</body>
+</html>
+`))
@@ -0,0 +1,138 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type sentMail struct {
+	to, subject, html string
+}
+
+type mockMailer struct {
+	sent []sentMail
+	fail map[string]bool
+}
+
+func (m *mockMailer) SendMail(ctx context.Context, to, subject, html string) error {
+	if m.fail[to] {
+		return errors.New("mailbox unavailable")
+	}
+	m.sent = append(m.sent, sentMail{to, subject, html})
+	return nil
+}
+
+// =============================================================================
+// EMAIL DIGEST TESTS
+// =============================================================================
+
+func newDigestUsecase(mailer *mockMailer, settings *mockSettingsRepo) *usecase.SendDigestUsecase {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 10, LastReportDate: now},
+		"user2": {UserID: "user2", Name: "Bob <b>", Streak: 7, ActivityCount: 8, LastReportDate: now},
+		"user3": {UserID: "user3", Name: "Cici", Streak: 20, ActivityCount: 20, LastReportDate: now},
+	}}
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", DistanceKm: 5, ReportedAt: now},
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", DistanceKm: 3, ReportedAt: now},
+		{UserID: "user2", Name: "Bob <b>", ActivityType: "sepeda", DistanceKm: 21, ReportedAt: now},
+		{UserID: "user3", Name: "Cici", ActivityType: "yoga", ReportedAt: now},
+	}}
+	recapUC := usecase.NewGetRecapUsecase(activities)
+	recapUC.SetSettingsRepository(settings)
+	return usecase.NewSendDigestUsecase(recapUC, repo, settings, mailer, []string{"panitia@contoh.com"})
+}
+
+func TestDigest_SameDataAsRecap(t *testing.T) {
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{"user3": {UserID: "user3", Private: true}}}
+	uc := newDigestUsecase(&mockMailer{}, settings)
+
+	digest, err := uc.Digest(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if digest.Recap.Reports != 3 || digest.Recap.Members != 2 || digest.Recap.WeekKm != 29 {
+		t.Errorf("Expected the recap without the private member, got %+v", digest.Recap)
+	}
+	if len(digest.Standings) != 2 || digest.Standings[0].Name != "Alice" {
+		t.Errorf("Expected Alice on top of the standings, got %+v", digest.Standings)
+	}
+
+	want := []string{"Paling rajin Alice 2 laporan", "Jarak terjauh Bob <b> 21 km", "Streak terpanjang Bob <b> 7 hari"}
+	if len(digest.Highlights) != len(want) {
+		t.Fatalf("Expected %d highlights, got %+v", len(want), digest.Highlights)
+	}
+	for i, h := range digest.Highlights {
+		if got := h.Title + " " + h.Name + " " + h.Value; got != want[i] {
+			t.Errorf("Expected highlight %q, got %q", want[i], got)
+		}
+	}
+
+	subject, html, err := digest.Render()
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if !strings.HasPrefix(subject, "Recap Mingguan ") {
+		t.Errorf("Expected the recap title as subject, got %q", subject)
+	}
+	if !strings.Contains(html, "Bob &lt;b&gt;") || strings.Contains(html, "Bob <b>") {
+		t.Error("Expected names escaped in the HTML")
+	}
+	if strings.Contains(html, "Cici") {
+		t.Error("Expected the private member left out of the email")
+	}
+}
+
+func TestDigest_SendsToOrganizersAndSubscribers(t *testing.T) {
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{
+		"user1": {UserID: "user1", Email: "alice@contoh.com"},
+		"user2": {UserID: "user2", Email: "panitia@contoh.com"},
+		"user3": {UserID: "user3", Email: "cici@contoh.com"},
+	}}
+	mailer := &mockMailer{fail: map[string]bool{"cici@contoh.com": true}}
+	uc := newDigestUsecase(mailer, settings)
+
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var to []string
+	for _, m := range mailer.sent {
+		to = append(to, m.to)
+	}
+	if len(to) != 2 || to[0] != "panitia@contoh.com" {
+		t.Errorf("Expected one email per address, the failed one skipped, got %v", to)
+	}
+}
+
+func TestSetEmail(t *testing.T) {
+	settings := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+	uc := usecase.NewSetEmailUsecase(settings)
+	ctx := context.Background()
+
+	reply, err := uc.Execute(ctx, "user1", "Alice", []string{"alice@contoh.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings.settings["user1"].Email != "alice@contoh.com" || !reply.Private {
+		t.Errorf("Expected the address saved and a private reply, got %+v", reply)
+	}
+
+	reply, _ = uc.Execute(ctx, "user1", "Alice", []string{"bukan-email"})
+	if !strings.Contains(reply.Text, "tidak valid") || settings.settings["user1"].Email != "alice@contoh.com" {
+		t.Errorf("Expected an invalid address refused, got %q", reply.Text)
+	}
+
+	if _, err := uc.Execute(ctx, "user1", "Alice", []string{"off"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings.settings["user1"].Email != "" {
+		t.Error("Expected the address removed")
+	}
+}
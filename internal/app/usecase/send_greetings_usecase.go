@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// greetingActiveDays is how recently a member must have reported to get an
+// anniversary greeting, so members who left are not pinged.
+const greetingActiveDays = 7
+
+// GreetingRule is one milestone the group congratulates a member on. Exactly
+// one of Months and Reports is set.
+type GreetingRule struct {
+	Months  int    `json:"months"`  // months since the member's first report
+	Reports int    `json:"reports"` // lifetime reports
+	Text    string `json:"text"`    // text/template, see GreetingData
+
+	tmpl *template.Template
+}
+
+// GreetingData is what a greeting template sees.
+type GreetingData struct {
+	Mention string // "@628...", mentions the member; their name after #jangan-tag
+	Name    string
+	Months  int
+	Reports int
+}
+
+// DefaultGreetingRules are used when no GREETINGS_FILE is given.
+func DefaultGreetingRules() []GreetingRule {
+	rules := []GreetingRule{
+		{Months: 1, Text: "🎉 {{.Mention}} sudah 1 bulan bersama tantangan! Terima kasih sudah konsisten 💪"},
+		{Months: 12, Text: "🎂 {{.Mention}} sudah 1 tahun bersama tantangan! Luar biasa 🏆"},
+		{Reports: 100, Text: "💯 {{.Mention}} baru saja mencatat laporan ke-100! 🏆"},
+	}
+	for i := range rules {
+		_ = rules[i].parse()
+	}
+	return rules
+}
+
+// LoadGreetingRules reads a JSON array of rules.
+func LoadGreetingRules(r io.Reader) ([]GreetingRule, error) {
+	var rules []GreetingRule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid greeting rules: %w", err)
+	}
+	for i := range rules {
+		if err := rules[i].parse(); err != nil {
+			return nil, fmt.Errorf("greeting rule %d: %w", i+1, err)
+		}
+	}
+	return rules, nil
+}
+
+func (r *GreetingRule) parse() error {
+	if (r.Months > 0) == (r.Reports > 0) {
+		return fmt.Errorf("set either months or reports")
+	}
+	if strings.TrimSpace(r.Text) == "" {
+		return fmt.Errorf("text is empty")
+	}
+	tmpl, err := template.New("greeting").Parse(r.Text)
+	if err != nil {
+		return err
+	}
+	r.tmpl = tmpl
+	return nil
+}
+
+// reached reports whether the member reached the rule's milestone after
+// since and up to now.
+func (r *GreetingRule) reached(report *domain.Report, since, now time.Time) bool {
+	if r.Reports > 0 {
+		return report.ActivityCount == r.Reports && report.LastReportDate.After(since) && !report.LastReportDate.After(now)
+	}
+	if report.FirstReportDate.IsZero() || report.LastReportDate.Before(now.AddDate(0, 0, -greetingActiveDays)) {
+		return false
+	}
+	first := report.FirstReportDate.In(now.Location())
+	anniversary := time.Date(first.Year(), first.Month()+time.Month(r.Months), first.Day(), 0, 0, 0, 0, now.Location())
+	return anniversary.After(since) && !anniversary.After(now)
+}
+
+// SendGreetingsUsecase congratulates members in the group on milestones
+// such as a month in the challenge or their 100th report.
+type SendGreetingsUsecase struct {
+	repo     domain.ReportRepository
+	settings domain.SettingsRepository
+	sender   MentionSender
+	groupJID string
+	rules    []GreetingRule
+
+	media       CelebrationMedia
+	mediaSender MediaSender
+}
+
+func NewSendGreetingsUsecase(repo domain.ReportRepository, sender MentionSender, groupJID string, rules []GreetingRule) *SendGreetingsUsecase {
+	return &SendGreetingsUsecase{repo: repo, sender: sender, groupJID: groupJID, rules: rules}
+}
+
+// SetSettingsRepository names members who used #jangan-tag without
+// mentioning them, and leaves out members in #privat mode.
+func (uc *SendGreetingsUsecase) SetSettingsRepository(settings domain.SettingsRepository) {
+	uc.settings = settings
+}
+
+// SetCelebrations follows the greetings with a random GIF or image.
+func (uc *SendGreetingsUsecase) SetCelebrations(media CelebrationMedia, sender MediaSender) {
+	uc.media = media
+	uc.mediaSender = sender
+}
+
+// Greetings returns the greetings for the milestones reached after since
+// and up to now, and the members they mention.
+func (uc *SendGreetingsUsecase) Greetings(ctx context.Context, since, now time.Time) ([]string, []string, error) {
+	reports, err := uc.repo.GetAllReports(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	private, err := privateUsers(ctx, uc.settings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lines []string
+	mentions := newMentionList(uc.settings)
+	for _, report := range reports {
+		if private[report.UserID] {
+			continue
+		}
+		var who string
+		greeted := false
+		for i := range uc.rules {
+			rule := &uc.rules[i]
+			if !rule.reached(report, since, now) {
+				continue
+			}
+			if !greeted {
+				if who, err = mentions.add(ctx, report.UserID, report.Name); err != nil {
+					return nil, nil, err
+				}
+				greeted = true
+			}
+			data := GreetingData{Mention: who, Name: report.Name, Months: rule.Months, Reports: report.ActivityCount}
+			var buf bytes.Buffer
+			if err := rule.tmpl.Execute(&buf, data); err != nil {
+				return nil, nil, err
+			}
+			lines = append(lines, strings.TrimSpace(buf.String()))
+		}
+	}
+	return lines, mentions.userIDs, nil
+}
+
+// Execute posts the greetings for the milestones reached in the day before
+// now, the daily run of the job. Nothing is sent when nobody reached one.
+func (uc *SendGreetingsUsecase) Execute(ctx context.Context, now time.Time) error {
+	lines, userIDs, err := uc.Greetings(ctx, now.AddDate(0, 0, -1), now)
+	if err != nil || len(lines) == 0 {
+		return err
+	}
+	if err := uc.sender.SendMention(ctx, uc.groupJID, strings.Join(lines, "\n"), userIDs); err != nil {
+		return err
+	}
+
+	celebration := celebrate(ctx, uc.media, &Reply{})
+	switch {
+	case celebration.GIF != nil:
+		return uc.mediaSender.SendMedia(ctx, uc.groupJID, celebration.GIF, "video/mp4", "")
+	case celebration.Image != nil:
+		return uc.mediaSender.SendMedia(ctx, uc.groupJID, celebration.Image, celebration.ImageMimeType, "")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// MILESTONE GREETING TESTS
+// =============================================================================
+
+func TestGreetings_AnniversariesAndReportCounts(t *testing.T) {
+	now := time.Date(2026, 4, 15, 8, 0, 0, 0, time.Local)
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", ActivityCount: 25, LastReportDate: now.AddDate(0, 0, -1), FirstReportDate: time.Date(2026, 3, 15, 6, 0, 0, 0, time.Local)},
+		"628222": {UserID: "628222", Name: "Bob", ActivityCount: 100, LastReportDate: now.Add(-2 * time.Hour), FirstReportDate: time.Date(2025, 12, 1, 6, 0, 0, 0, time.Local)},
+		// A month ago too, but stopped reporting
+		"628333": {UserID: "628333", Name: "Gone", ActivityCount: 3, LastReportDate: now.AddDate(0, 0, -20), FirstReportDate: time.Date(2026, 3, 15, 6, 0, 0, 0, time.Local)},
+		// 100 reports, reached last week
+		"628444": {UserID: "628444", Name: "Citra", ActivityCount: 100, LastReportDate: now.AddDate(0, 0, -7)},
+	}}
+	sender := &mockMentionSender{}
+	uc := usecase.NewSendGreetingsUsecase(repo, sender, "111@g.us", usecase.DefaultGreetingRules())
+
+	if err := uc.Execute(context.Background(), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(sender.text, "🎉 @628111 sudah 1 bulan bersama tantangan!") {
+		t.Errorf("Expected Alice's first month, got %q", sender.text)
+	}
+	if !strings.Contains(sender.text, "💯 @628222 baru saja mencatat laporan ke-100!") {
+		t.Errorf("Expected Bob's 100th report, got %q", sender.text)
+	}
+	if strings.Contains(sender.text, "628333") || strings.Contains(sender.text, "628444") || len(sender.mentions) != 2 {
+		t.Errorf("Expected only Alice and Bob greeted, got %q mentioning %v", sender.text, sender.mentions)
+	}
+
+	// Nothing to celebrate the next day
+	sender = &mockMentionSender{}
+	uc = usecase.NewSendGreetingsUsecase(repo, sender, "111@g.us", usecase.DefaultGreetingRules())
+	if err := uc.Execute(context.Background(), now.AddDate(0, 0, 1)); err != nil || sender.sent != 0 {
+		t.Errorf("Expected no greetings the next day, got %q, %v", sender.text, err)
+	}
+}
+
+func TestGreetings_LoadRules(t *testing.T) {
+	rules, err := usecase.LoadGreetingRules(strings.NewReader(`[{"reports": 50, "text": "Hore {{.Name}}, {{.Reports}} laporan!"}]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", ActivityCount: 50, LastReportDate: now.Add(-time.Hour)},
+	}}
+	lines, _, err := usecase.NewSendGreetingsUsecase(repo, &mockMentionSender{}, "111@g.us", rules).Greetings(context.Background(), now.AddDate(0, 0, -1), now)
+	if err != nil || len(lines) != 1 || lines[0] != "Hore Alice, 50 laporan!" {
+		t.Errorf("Expected the custom greeting, got %q, %v", lines, err)
+	}
+
+	for _, bad := range []string{
+		`[{"text": "no milestone"}]`,
+		`[{"months": 1, "reports": 10, "text": "both"}]`,
+		`[{"months": 1, "text": "{{.Nope"}]`,
+		`{"months": 1}`,
+	} {
+		if _, err := usecase.LoadGreetingRules(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected an error for %s", bad)
+		}
+	}
+}
+
+type mockMediaSender struct {
+	chatJID  string
+	data     []byte
+	mimeType string
+}
+
+func (m *mockMediaSender) SendMedia(ctx context.Context, chatJID string, data []byte, mimeType, caption string) error {
+	m.chatJID, m.data, m.mimeType = chatJID, data, mimeType
+	return nil
+}
+
+func TestGreetings_FollowedByCelebration(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", ActivityCount: 100, LastReportDate: now.Add(-time.Hour)},
+	}}
+	mediaSender := &mockMediaSender{}
+	uc := usecase.NewSendGreetingsUsecase(repo, &mockMentionSender{}, "111@g.us", usecase.DefaultGreetingRules())
+	uc.SetCelebrations(&fakeCelebrations{data: []byte("png"), mimeType: "image/png"}, mediaSender)
+
+	if err := uc.Execute(context.Background(), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mediaSender.chatJID != "111@g.us" || string(mediaSender.data) != "png" || mediaSender.mimeType != "image/png" {
+		t.Errorf("Expected the image sent to the group, got %+v", mediaSender)
+	}
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// personalReminderWindow is how late a reminder may still go out, e.g. when
+// the bot was offline at the chosen time.
+const personalReminderWindow = 30 * time.Minute
+
+// DirectSender sends a private chat message to a member by phone number.
+type DirectSender interface {
+	SendDirect(ctx context.Context, userID, text string) error
+}
+
+// SendPersonalReminderUsecase DMs every member who set "#ingatkan" at their
+// chosen time, unless they already reported today or used #snooze.
+type SendPersonalReminderUsecase struct {
+	repo     domain.ReportRepository
+	settings domain.SettingsRepository
+	sender   DirectSender
+
+	mu     sync.Mutex
+	sentOn map[string]string // userID -> local date of the last reminder
+}
+
+func NewSendPersonalReminderUsecase(repo domain.ReportRepository, settings domain.SettingsRepository, sender DirectSender) *SendPersonalReminderUsecase {
+	return &SendPersonalReminderUsecase{
+		repo:     repo,
+		settings: settings,
+		sender:   sender,
+		sentOn:   make(map[string]string),
+	}
+}
+
+// Execute sends the reminders that are due at now.
+func (uc *SendPersonalReminderUsecase) Execute(ctx context.Context, now time.Time) error {
+	list, err := uc.settings.GetReminderSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range list {
+		local := now.In(timezoneLocation(s.Timezone))
+		if !uc.due(s, local) || now.Before(s.SnoozeUntil) || unverified(s) {
+			continue
+		}
+
+		report, err := uc.repo.GetReport(ctx, s.UserID)
+		if err != nil {
+			return err
+		}
+		if report != nil && report.LastReportDate.In(local.Location()).Format("2006-01-02") == local.Format("2006-01-02") {
+			continue
+		}
+
+		text := "⏰ Jangan lupa #lapor hari ini ya!"
+		if report != nil && report.Streak > 0 {
+			text = fmt.Sprintf("⏰ Hai %s, jangan lupa #lapor hari ini ya! Streak kamu %d hari 🔥", report.Name, report.Streak)
+		}
+		if err := uc.sender.SendDirect(ctx, s.UserID, text); err != nil {
+			log.Printf("Failed to send reminder to %s: %v", s.UserID, err)
+			continue
+		}
+
+		uc.mu.Lock()
+		uc.sentOn[s.UserID] = local.Format("2006-01-02")
+		uc.mu.Unlock()
+	}
+	return nil
+}
+
+// due reports whether the reminder time has passed today (in the user's
+// timezone) within the window and no reminder went out today yet.
+func (uc *SendPersonalReminderUsecase) due(s *domain.UserSettings, local time.Time) bool {
+	at, err := time.ParseInLocation("15:04", s.ReminderTime, local.Location())
+	if err != nil {
+		return false
+	}
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), at.Hour(), at.Minute(), 0, 0, local.Location())
+	if local.Before(scheduled) || local.Sub(scheduled) > personalReminderWindow {
+		return false
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	return uc.sentOn[s.UserID] != local.Format("2006-01-02")
+}
@@ -0,0 +1,100 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// mockDirectSender records private messages per user
+type mockDirectSender struct {
+	sent map[string]string
+}
+
+func (m *mockDirectSender) SendDirect(ctx context.Context, userID, text string) error {
+	if m.sent == nil {
+		m.sent = make(map[string]string)
+	}
+	m.sent[userID] = text
+	return nil
+}
+
+func TestIngatkan_SetAndShow(t *testing.T) {
+	settings := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetReminderUsecase(usecase.NewSetReminderUsecase(settings))
+	ctx := context.Background()
+
+	result, err := handleUC.Execute(ctx, "628111", "Alice", "#ingatkan 19.30 wita")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "19:30 WITA") {
+		t.Errorf("Expected confirmation with time and zone, got '%s'", result)
+	}
+	if s := settings.settings["628111"]; s.ReminderTime != "19:30" || s.Timezone != "WITA" {
+		t.Errorf("Expected 19:30 WITA stored, got %+v", s)
+	}
+
+	for _, msg := range []string{"#ingatkan 25:00", "#ingatkan 19:30 pst"} {
+		result, err = handleUC.Execute(ctx, "628111", "Alice", msg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if settings.settings["628111"].ReminderTime != "19:30" || settings.settings["628111"].Timezone != "WITA" {
+			t.Errorf("'%s' must not change the reminder, got '%s'", msg, result)
+		}
+	}
+
+	if _, err := handleUC.Execute(ctx, "628111", "Alice", "#ingatkan off"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings.settings["628111"].ReminderTime != "" {
+		t.Errorf("Expected reminder turned off")
+	}
+}
+
+func TestPersonalReminder_SentAtUserTime(t *testing.T) {
+	// 12:30 UTC = 19:30 WIB = 20:30 WITA
+	now := time.Date(2026, 3, 10, 12, 30, 0, 0, time.UTC)
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", Streak: 4, LastReportDate: now.AddDate(0, 0, -1)},
+		"628222": {UserID: "628222", Name: "Bob", Streak: 9, LastReportDate: now.Add(-2 * time.Hour)},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{
+		"628111": {UserID: "628111", ReminderTime: "19:30", Timezone: "WIB"},
+		"628222": {UserID: "628222", ReminderTime: "19:30", Timezone: "WIB"},
+		"628333": {UserID: "628333", ReminderTime: "21:00", Timezone: "WITA"},
+		"628444": {UserID: "628444", ReminderTime: "19:15", Timezone: "WIB", SnoozeUntil: now.Add(time.Hour)},
+	}}
+	sender := &mockDirectSender{}
+	uc := usecase.NewSendPersonalReminderUsecase(repo, settings, sender)
+	ctx := context.Background()
+
+	if err := uc.Execute(ctx, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 || !containsSubstring(sender.sent["628111"], "Streak kamu 4 hari") {
+		t.Fatalf("Expected only Alice reminded (Bob reported, Casey later, Dian snoozed), got %v", sender.sent)
+	}
+
+	// Not sent twice on the same day
+	sender.sent = nil
+	if err := uc.Execute(ctx, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Errorf("Expected no repeat reminder, got %v", sender.sent)
+	}
+
+	// 21:00 WITA for a user who never reported
+	if err := uc.Execute(ctx, now.Add(30*time.Minute)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := sender.sent["628333"]; !ok || len(sender.sent) != 1 {
+		t.Errorf("Expected the WITA user reminded at 21:00 WITA, got %v", sender.sent)
+	}
+}
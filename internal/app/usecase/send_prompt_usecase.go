@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// SendPromptUsecase posts the morning prompt members answer to report. The
+// prompt is stored, so replies and reactions to it can be traced back.
+type SendPromptUsecase struct {
+	prompts   domain.PromptRepository
+	sender    PromptSender
+	groupJID  string
+	challenge domain.Challenge
+	reactions bool
+	quotes    *DailyQuoteUsecase
+}
+
+func NewSendPromptUsecase(prompts domain.PromptRepository, sender PromptSender, groupJID string) *SendPromptUsecase {
+	return &SendPromptUsecase{prompts: prompts, sender: sender, groupJID: groupJID}
+}
+
+// SetChallenge numbers the prompts from the first day of the challenge. No
+// prompt is sent before the challenge starts or after it ends.
+func (uc *SendPromptUsecase) SetChallenge(challenge domain.Challenge) {
+	uc.challenge = challenge
+}
+
+// SetReactions mentions in the prompt that a reaction counts as a report.
+func (uc *SendPromptUsecase) SetReactions(enabled bool) {
+	uc.reactions = enabled
+}
+
+// SetQuotes appends the quote of the day to the prompt.
+func (uc *SendPromptUsecase) SetQuotes(quotes *DailyQuoteUsecase) {
+	uc.quotes = quotes
+}
+
+// Text returns the prompt for the day of now, and false when the challenge
+// is not running that day.
+func (uc *SendPromptUsecase) Text(now time.Time) (string, bool) {
+	answer := "Balas #lapor"
+	if uc.reactions {
+		answer += " / react 🔥"
+	}
+	if uc.challenge.Start.IsZero() {
+		return fmt.Sprintf("☀️ Selamat pagi! Sudah olahraga? %s", answer), true
+	}
+	if !uc.challenge.Running(now) {
+		return "", false
+	}
+	return fmt.Sprintf("☀️ Hari ke-%d! Sudah olahraga? %s", uc.challenge.Day(now), answer), true
+}
+
+// Execute posts today's prompt in the group.
+func (uc *SendPromptUsecase) Execute(ctx context.Context) error {
+	text, ok := uc.Text(time.Now())
+	if !ok {
+		return nil
+	}
+	return sendPrompt(ctx, uc.sender, uc.prompts, uc.groupJID, withQuote(ctx, uc.quotes, text), nil)
+}
@@ -0,0 +1,69 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// DAILY PROMPT TESTS
+// =============================================================================
+
+func TestSendPrompt_NumbersTheChallengeDays(t *testing.T) {
+	uc := usecase.NewSendPromptUsecase(&mockPromptRepo{}, &mockMentionSender{}, "111@g.us")
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 6, 0, 0, 0, time.Local) }
+
+	if text, ok := uc.Text(day(17)); !ok || text != "☀️ Selamat pagi! Sudah olahraga? Balas #lapor" {
+		t.Errorf("Expected an unnumbered prompt without a start date, got %q", text)
+	}
+
+	uc.SetChallenge(domain.Challenge{Start: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Days: 30})
+	uc.SetReactions(true)
+	if text, ok := uc.Text(day(17)); !ok || text != "☀️ Hari ke-17! Sudah olahraga? Balas #lapor / react 🔥" {
+		t.Errorf("Expected day 17 with the reaction hint, got %q", text)
+	}
+	if _, ok := uc.Text(time.Date(2026, 2, 28, 6, 0, 0, 0, time.Local)); ok {
+		t.Error("Expected no prompt before the challenge starts")
+	}
+	if _, ok := uc.Text(day(31)); ok {
+		t.Error("Expected no prompt after the last day")
+	}
+}
+
+type mockQuoteRepo struct {
+	uses []*domain.QuoteUse
+}
+
+func (m *mockQuoteRepo) GetQuoteUses(ctx context.Context) ([]*domain.QuoteUse, error) {
+	return m.uses, nil
+}
+
+func (m *mockQuoteRepo) SaveQuoteUse(ctx context.Context, use *domain.QuoteUse) error {
+	m.uses = append(m.uses, use)
+	return nil
+}
+
+func (m *mockQuoteRepo) DeleteQuoteUses(ctx context.Context) error {
+	m.uses = nil
+	return nil
+}
+
+func (m *mockQuoteRepo) InitTable(ctx context.Context) error { return nil }
+
+func TestSendPrompt_QuoteOfTheDay(t *testing.T) {
+	sender := &mockMentionSender{}
+	uc := usecase.NewSendPromptUsecase(&mockPromptRepo{prompts: map[string]*domain.ReportPrompt{}}, sender, "111@g.us")
+	uc.SetQuotes(usecase.NewDailyQuoteUsecase(usecase.QuoteList{"Konsisten mengalahkan intensitas."}, &mockQuoteRepo{}))
+
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(sender.text, "Balas #lapor\n\n💬 Konsisten mengalahkan intensitas.") {
+		t.Errorf("Expected the quote below the prompt, got %q", sender.text)
+	}
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+)
+
+// SendRecapUsecase posts the weekly recap to the group, the same text as
+// #recap.
+type SendRecapUsecase struct {
+	recap    *GetRecapUsecase
+	sender   MentionSender
+	groupJID string
+	quotes   *DailyQuoteUsecase
+}
+
+func NewSendRecapUsecase(recap *GetRecapUsecase, sender MentionSender, groupJID string) *SendRecapUsecase {
+	return &SendRecapUsecase{recap: recap, sender: sender, groupJID: groupJID}
+}
+
+// SetQuotes appends the quote of the day to the recap.
+func (uc *SendRecapUsecase) SetQuotes(quotes *DailyQuoteUsecase) {
+	uc.quotes = quotes
+}
+
+func (uc *SendRecapUsecase) Execute(ctx context.Context) error {
+	text, err := uc.recap.ExecuteWeekly(ctx, uc.groupJID)
+	if err != nil {
+		return err
+	}
+	return uc.sender.SendMention(ctx, uc.groupJID, withQuote(ctx, uc.quotes, text), nil)
+}
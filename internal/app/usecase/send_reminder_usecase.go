@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// MentionSender posts a group message that mentions members by phone number.
+type MentionSender interface {
+	SendMention(ctx context.Context, chatJID, text string, userIDs []string) error
+}
+
+// SendReminderUsecase posts the evening nudge. Only members with a streak of
+// at least minStreak that would break tonight are mentioned, so casual
+// members are not pinged every day.
+type SendReminderUsecase struct {
+	repo      domain.ReportRepository
+	settings  domain.SettingsRepository
+	sender    MentionSender
+	groupJID  string
+	minStreak int
+
+	prompts      domain.PromptRepository
+	promptSender PromptSender
+}
+
+func NewSendReminderUsecase(repo domain.ReportRepository, sender MentionSender, groupJID string, minStreak int) *SendReminderUsecase {
+	return &SendReminderUsecase{repo: repo, sender: sender, groupJID: groupJID, minStreak: minStreak}
+}
+
+// SetSettingsRepository makes the reminder skip members who used #snooze or
+// #privat.
+func (uc *SendReminderUsecase) SetSettingsRepository(settings domain.SettingsRepository) {
+	uc.settings = settings
+}
+
+// SetPrompts stores the reminder as a prompt, so members can report by
+// reacting to it.
+func (uc *SendReminderUsecase) SetPrompts(prompts domain.PromptRepository, sender PromptSender) {
+	uc.prompts = prompts
+	uc.promptSender = sender
+}
+
+// AtRisk returns the members who reported yesterday but not yet today and
+// whose streak is at least minStreak, longest streak first. Snoozed,
+// unverified and #privat members are left out.
+func (uc *SendReminderUsecase) AtRisk(ctx context.Context, now time.Time) ([]*domain.Report, error) {
+	reports, err := uc.repo.GetAllReports(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	var atRisk []*domain.Report
+	for _, r := range reports {
+		last := time.Date(r.LastReportDate.Year(), r.LastReportDate.Month(), r.LastReportDate.Day(), 0, 0, 0, 0, time.UTC)
+		if !last.Equal(yesterday) || r.Streak < uc.minStreak {
+			continue
+		}
+		skip, err := uc.skip(ctx, r.UserID, now)
+		if err != nil {
+			return nil, err
+		}
+		if !skip {
+			atRisk = append(atRisk, r)
+		}
+	}
+	sort.SliceStable(atRisk, func(i, j int) bool {
+		return atRisk[i].Streak > atRisk[j].Streak
+	})
+	return atRisk, nil
+}
+
+// skip reports whether the member used #snooze, has not answered the
+// new-member verification yet, or is in #privat mode, whose streak must not
+// be posted in the group.
+func (uc *SendReminderUsecase) skip(ctx context.Context, userID string, now time.Time) (bool, error) {
+	if uc.settings == nil {
+		return false, nil
+	}
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil || settings == nil {
+		return false, err
+	}
+	return now.Before(settings.SnoozeUntil) || unverified(settings) || settings.Private, nil
+}
+
+// Execute mentions the at-risk members in the group, by name only for those
+// who used #jangan-tag. Nothing is sent when nobody is at risk.
+func (uc *SendReminderUsecase) Execute(ctx context.Context) error {
+	atRisk, err := uc.AtRisk(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(atRisk) == 0 {
+		return nil
+	}
+
+	mentions := newMentionList(uc.settings)
+	sb := strings.Builder{}
+	sb.WriteString("⏰ Pengingat! Streak kalian bisa putus malam ini, jangan lupa #lapor:\n\n")
+	for _, r := range atRisk {
+		who, err := mentions.add(ctx, r.UserID, r.Name)
+		if err != nil {
+			return err
+		}
+		sb.WriteString(fmt.Sprintf("%s – streak %d hari 🔥\n", who, r.Streak))
+	}
+
+	text := strings.TrimRight(sb.String(), "\n")
+	if uc.prompts != nil {
+		return sendPrompt(ctx, uc.promptSender, uc.prompts, uc.groupJID, text, mentions.userIDs)
+	}
+	return uc.sender.SendMention(ctx, uc.groupJID, text, mentions.userIDs)
+}
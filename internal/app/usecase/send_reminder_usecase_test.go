@@ -0,0 +1,72 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// mockMentionSender records the last mention message
+type mockMentionSender struct {
+	sent     int
+	chatJID  string
+	text     string
+	mentions []string
+}
+
+func (m *mockMentionSender) SendMention(ctx context.Context, chatJID, text string, userIDs []string) error {
+	m.sent++
+	m.chatJID, m.text, m.mentions = chatJID, text, userIDs
+	return nil
+}
+
+func (m *mockMentionSender) SendMentionIDs(ctx context.Context, chatJID, text string, userIDs []string) ([]string, error) {
+	m.SendMention(ctx, chatJID, text, userIDs)
+	return []string{fmt.Sprintf("MSG%d", m.sent)}, nil
+}
+
+func TestReminder_MentionsOnlyAtRiskStreaks(t *testing.T) {
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", Streak: 7, LastReportDate: yesterday},
+		"628222": {UserID: "628222", Name: "Bob", Streak: 12, LastReportDate: yesterday},
+		"628333": {UserID: "628333", Name: "Casual", Streak: 2, LastReportDate: yesterday},
+		"628444": {UserID: "628444", Name: "Done", Streak: 9, LastReportDate: now},
+		"628555": {UserID: "628555", Name: "Broken", Streak: 9, LastReportDate: now.AddDate(0, 0, -3)},
+	}}
+	sender := &mockMentionSender{}
+	uc := usecase.NewSendReminderUsecase(repo, sender, "111@g.us", 5)
+
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sender.sent != 1 || sender.chatJID != "111@g.us" {
+		t.Fatalf("Expected one reminder to the group, got %d to '%s'", sender.sent, sender.chatJID)
+	}
+	if len(sender.mentions) != 2 || sender.mentions[0] != "628222" || sender.mentions[1] != "628111" {
+		t.Errorf("Expected Bob then Alice mentioned, got %v", sender.mentions)
+	}
+	if !containsSubstring(sender.text, "@628222 – streak 12 hari") || containsSubstring(sender.text, "628333") {
+		t.Errorf("Unexpected reminder text '%s'", sender.text)
+	}
+}
+
+func TestReminder_NothingAtRisk(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", Streak: 7, LastReportDate: time.Now()},
+	}}
+	sender := &mockMentionSender{}
+	uc := usecase.NewSendReminderUsecase(repo, sender, "111@g.us", 5)
+
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sender.sent != 0 {
+		t.Errorf("Expected no reminder when nobody is at risk, got '%s'", sender.text)
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// SetEmailUsecase lets members opt in to the weekly email digest.
+type SetEmailUsecase struct {
+	settings domain.SettingsRepository
+}
+
+func NewSetEmailUsecase(settings domain.SettingsRepository) *SetEmailUsecase {
+	return &SetEmailUsecase{settings: settings}
+}
+
+// Execute handles "#email alamat@contoh.com", "#email off", and "#email" to
+// show the current address. The reply goes to the member's private chat so
+// the address is not repeated in the group.
+func (uc *SetEmailUsecase) Execute(ctx context.Context, userID, name string, args []string) (*Reply, error) {
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &domain.UserSettings{UserID: userID}
+	}
+
+	if len(args) == 0 {
+		if settings.Email != "" {
+			return privateReply(fmt.Sprintf("📧 Digest mingguan %s dikirim ke %s. Ketik #email off untuk berhenti.", name, settings.Email)), nil
+		}
+		return privateReply(fmt.Sprintf("%s belum menerima digest mingguan. Ketik #email alamat@contoh.com untuk berlangganan klasemen dan highlight tiap minggu lewat email.", name)), nil
+	}
+
+	switch args[0] {
+	case "off", "tidak", "mati":
+		settings.Email = ""
+	default:
+		addr, err := mail.ParseAddress(args[0])
+		if err != nil || addr.Name != "" {
+			return privateReply("Alamat email tidak valid. Format: #email alamat@contoh.com atau #email off"), nil
+		}
+		settings.Email = addr.Address
+	}
+	if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	if settings.Email == "" {
+		return privateReply(fmt.Sprintf("Digest mingguan %s dihentikan.", name)), nil
+	}
+	return privateReply(fmt.Sprintf("📧 Sip, %s! Klasemen dan highlight mingguan akan dikirim ke %s.", name, settings.Email)), nil
+}
+
+func privateReply(text string) *Reply {
+	return &Reply{Text: text, Private: true}
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// SetNoMentionUsecase lets members who don't want to be notified in the
+// group keep taking part without the bot ever tagging them.
+type SetNoMentionUsecase struct {
+	settings domain.SettingsRepository
+}
+
+func NewSetNoMentionUsecase(settings domain.SettingsRepository) *SetNoMentionUsecase {
+	return &SetNoMentionUsecase{settings: settings}
+}
+
+// Execute handles "#jangan-tag" and "#jangan-tag on" to stop the mentions,
+// and "#jangan-tag off" to allow them again.
+func (uc *SetNoMentionUsecase) Execute(ctx context.Context, userID, name string, args []string) (string, error) {
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if settings == nil {
+		settings = &domain.UserSettings{UserID: userID}
+	}
+
+	on := true
+	if len(args) > 0 {
+		switch args[0] {
+		case "on", "ya", "aktif":
+		case "off", "tidak", "mati":
+			on = false
+		default:
+			return "Format: #jangan-tag [on | off]", nil
+		}
+	}
+	if settings.NoMention != on {
+		settings.NoMention = on
+		if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+			return "", err
+		}
+	}
+
+	if on {
+		return fmt.Sprintf("🔕 Sip, %s. Bot tidak akan men-tag kamu lagi di pengingat dan ucapan; namamu tetap ditulis tanpa notifikasi. Ketik #jangan-tag off untuk di-tag lagi.", name), nil
+	}
+	return fmt.Sprintf("🔔 %s akan di-tag lagi di pengingat dan ucapan.", name), nil
+}
+
+// mentionList collects the members a group message mentions. Members who
+// used #jangan-tag are written by name and left out of the list, so
+// WhatsApp does not notify them.
+type mentionList struct {
+	settings domain.SettingsRepository
+	userIDs  []string
+}
+
+func newMentionList(settings domain.SettingsRepository) *mentionList {
+	return &mentionList{settings: settings}
+}
+
+// add returns how to write the member in the text: "@<userID>", or name when
+// they opted out of mentions.
+func (m *mentionList) add(ctx context.Context, userID, name string) (string, error) {
+	if m.settings != nil {
+		settings, err := m.settings.GetSettings(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		if settings != nil && settings.NoMention {
+			return name, nil
+		}
+	}
+	m.userIDs = append(m.userIDs, userID)
+	return "@" + userID, nil
+}
@@ -0,0 +1,84 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// MENTION OPT-OUT TESTS
+// =============================================================================
+
+func TestNoMention_NamedButNotTagged(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1)
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", Streak: 7, ActivityCount: 100, LastReportDate: yesterday},
+		"628222": {UserID: "628222", Name: "Bob", Streak: 12, ActivityCount: 100, LastReportDate: yesterday},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{
+		"628222": {UserID: "628222", Target: 20},
+	}}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	handleUC.SetNoMentionUsecase(usecase.NewSetNoMentionUsecase(settings))
+	ctx := context.Background()
+
+	result, err := handleUC.Execute(ctx, "628222", "Bob", "#jangan-tag")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "tidak akan men-tag") || !settings.settings["628222"].NoMention {
+		t.Fatalf("Expected mentions turned off, got '%s'", result)
+	}
+	if settings.settings["628222"].Target != 20 {
+		t.Errorf("Expected the other settings kept, got %+v", settings.settings["628222"])
+	}
+
+	// The reminder names Bob without tagging him
+	sender := &mockMentionSender{}
+	reminderUC := usecase.NewSendReminderUsecase(repo, sender, "111@g.us", 5)
+	reminderUC.SetSettingsRepository(settings)
+	if err := reminderUC.Execute(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(sender.text, "\nBob – streak 12 hari") || containsSubstring(sender.text, "@628222") {
+		t.Errorf("Expected Bob named without a mention, got '%s'", sender.text)
+	}
+	if len(sender.mentions) != 1 || sender.mentions[0] != "628111" {
+		t.Errorf("Expected only Alice mentioned, got %v", sender.mentions)
+	}
+
+	// So do the milestone greetings
+	sender = &mockMentionSender{}
+	greetingsUC := usecase.NewSendGreetingsUsecase(repo, sender, "111@g.us", usecase.DefaultGreetingRules())
+	greetingsUC.SetSettingsRepository(settings)
+	if err := greetingsUC.Execute(ctx, yesterday.Add(time.Hour)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(sender.text, "💯 Bob baru saja") || !containsSubstring(sender.text, "💯 @628111 baru saja") {
+		t.Errorf("Expected Bob named and Alice mentioned, got '%s'", sender.text)
+	}
+	if len(sender.mentions) != 1 || sender.mentions[0] != "628111" {
+		t.Errorf("Expected only Alice mentioned, got %v", sender.mentions)
+	}
+
+	if _, err := handleUC.Execute(ctx, "628222", "Bob", "#jangan-tag off"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sender = &mockMentionSender{}
+	reminderUC = usecase.NewSendReminderUsecase(repo, sender, "111@g.us", 5)
+	reminderUC.SetSettingsRepository(settings)
+	if err := reminderUC.Execute(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sender.mentions) != 2 {
+		t.Errorf("Expected Bob mentioned again, got %v", sender.mentions)
+	}
+
+	if result, _ := handleUC.Execute(ctx, "628222", "Bob", "#jangan-tag kadang"); !containsSubstring(result, "Format: #jangan-tag") {
+		t.Errorf("Expected the usage, got '%s'", result)
+	}
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// SetPrivateModeUsecase lets members who want accountability without an
+// audience keep reporting while staying out of leaderboards and recaps.
+type SetPrivateModeUsecase struct {
+	settings domain.SettingsRepository
+}
+
+func NewSetPrivateModeUsecase(settings domain.SettingsRepository) *SetPrivateModeUsecase {
+	return &SetPrivateModeUsecase{settings: settings}
+}
+
+// Enabled reports whether the member is in private mode. Errors count as
+// not private.
+func (uc *SetPrivateModeUsecase) Enabled(ctx context.Context, userID string) bool {
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load settings of %s: %v", userID, err)
+		return false
+	}
+	return settings != nil && settings.Private
+}
+
+// Execute handles "#privat on", "#privat off", and "#privat" to show the
+// current mode.
+func (uc *SetPrivateModeUsecase) Execute(ctx context.Context, userID, name string, args []string) (string, error) {
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if settings == nil {
+		settings = &domain.UserSettings{UserID: userID}
+	}
+
+	if len(args) == 0 {
+		if settings.Private {
+			return fmt.Sprintf("🔒 Mode privat %s aktif: laporan tetap dicatat, tapi tidak muncul di klasemen dan recap. Ketik #privat off untuk tampil lagi.", name), nil
+		}
+		return fmt.Sprintf("Mode privat %s tidak aktif. Ketik #privat on agar laporanmu tetap dicatat tanpa muncul di klasemen dan recap.", name), nil
+	}
+
+	switch args[0] {
+	case "on", "ya", "aktif":
+		settings.Private = true
+	case "off", "tidak", "mati":
+		settings.Private = false
+	default:
+		return "Format: #privat [on | off]", nil
+	}
+	if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+		return "", err
+	}
+
+	if settings.Private {
+		return fmt.Sprintf("🔒 Mode privat aktif, %s. Laporanmu tetap dicatat dan #stats tetap jalan, tapi kamu tidak muncul di klasemen dan recap. Tips: #lapor lewat chat pribadi ke bot agar tidak terlihat di grup.", name), nil
+	}
+	return fmt.Sprintf("🔓 Mode privat mati, %s kembali muncul di klasemen dan recap.", name), nil
+}
@@ -0,0 +1,157 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// =============================================================================
+// PRIVATE MODE TESTS
+// =============================================================================
+
+func TestPrivateMode_TrackedButNotRanked(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 5, ActivityCount: 5, LastReportDate: now},
+		"user2": {UserID: "user2", Name: "Bob", Streak: 3, ActivityCount: 3, LastReportDate: now.AddDate(0, 0, -1)},
+	}}
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ReportedAt: now},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{
+		"user2": {UserID: "user2", Target: 20},
+	}}
+
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	reportUC.SetActivityRepository(activities)
+	leaderboardUC := usecase.NewGetLeaderboardUsecase(repo)
+	leaderboardUC.SetSettingsRepository(settings)
+	recapUC := usecase.NewGetRecapUsecase(activities)
+	recapUC.SetSettingsRepository(settings)
+	handleUC := usecase.NewHandleMessageUsecase(reportUC, leaderboardUC)
+	handleUC.SetRecapUsecase(recapUC)
+	handleUC.SetStatsUsecase(usecase.NewGetStatsUsecase(repo, activities))
+	handleUC.SetPrivateModeUsecase(usecase.NewSetPrivateModeUsecase(settings))
+	ctx := context.Background()
+
+	result, err := handleUC.Execute(ctx, "user2", "Bob", "#privat")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "tidak aktif") || handleUC.InPrivateMode(ctx, "user2") {
+		t.Errorf("Expected private mode off by default, got '%s'", result)
+	}
+
+	result, err = handleUC.Execute(ctx, "user2", "Bob", "#privat on")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Mode privat aktif") || !handleUC.InPrivateMode(ctx, "user2") {
+		t.Fatalf("Expected private mode on, got '%s'", result)
+	}
+	if settings.settings["user2"].Target != 20 {
+		t.Errorf("Expected the other settings kept, got %+v", settings.settings["user2"])
+	}
+
+	result, err = handleUC.Execute(ctx, "user2", "Bob", "#lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Laporan diterima") {
+		t.Errorf("Expected the report accepted, got '%s'", result)
+	}
+	result, err = handleUC.Execute(ctx, "user2", "Bob", "#stats")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Bob") {
+		t.Errorf("Expected Bob's stats, got '%s'", result)
+	}
+
+	result, err = handleUC.Execute(ctx, "user1", "Alice", "#leaderboard")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if containsSubstring(result, "Bob") {
+		t.Errorf("Expected Bob left out of the leaderboard, got '%s'", result)
+	}
+	result, err = handleUC.Execute(ctx, "user1", "Alice", "#recap")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Total laporan: 1") {
+		t.Errorf("Expected Bob's report left out of the recap, got '%s'", result)
+	}
+
+	if _, err := handleUC.Execute(ctx, "user2", "Bob", "#privat off"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err = handleUC.Execute(ctx, "user1", "Alice", "#leaderboard")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Bob – 4🔥 (total 4)") {
+		t.Errorf("Expected Bob back in the leaderboard, got '%s'", result)
+	}
+}
+
+func TestPrivateMode_NotGreetedOrSearchable(t *testing.T) {
+	now := time.Date(2026, 4, 15, 8, 0, 0, 0, time.Local)
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", ActivityCount: 100, LastReportDate: now.Add(-2 * time.Hour)},
+		"628222": {UserID: "628222", Name: "Bob", ActivityCount: 100, LastReportDate: now.Add(-time.Hour)},
+	}}
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		{UserID: "628111", Name: "Alice", Message: "#lapor lari pagi", ReportedAt: now},
+		{UserID: "628222", Name: "Bob", Message: "#lapor lari sore", ReportedAt: now},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{
+		"628222": {UserID: "628222", Private: true},
+	}}
+	ctx := context.Background()
+
+	sender := &mockMentionSender{}
+	greetingsUC := usecase.NewSendGreetingsUsecase(repo, sender, "111@g.us", usecase.DefaultGreetingRules())
+	greetingsUC.SetSettingsRepository(settings)
+	if err := greetingsUC.Execute(ctx, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(sender.text, "@628111") || containsSubstring(sender.text, "628222") || len(sender.mentions) != 1 {
+		t.Errorf("Expected only Alice greeted, got %q mentioning %v", sender.text, sender.mentions)
+	}
+
+	searchUC := usecase.NewSearchArchiveUsecase(activities)
+	searchUC.SetSettingsRepository(settings)
+	result, err := searchUC.Execute(ctx, []string{"lari"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "Alice") || containsSubstring(result, "Bob") {
+		t.Errorf("Expected Bob's report left out of the search, got '%s'", result)
+	}
+}
+
+func TestPrivateMode_NotRemindedInGroup(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1)
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", Streak: 7, LastReportDate: yesterday},
+		"628222": {UserID: "628222", Name: "Bob", Streak: 8, LastReportDate: yesterday},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{
+		"628222": {UserID: "628222", Private: true},
+	}}
+
+	sender := &mockMentionSender{}
+	reminderUC := usecase.NewSendReminderUsecase(repo, sender, "111@g.us", 5)
+	reminderUC.SetSettingsRepository(settings)
+	if err := reminderUC.Execute(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sender.mentions) != 1 || sender.mentions[0] != "628111" || containsSubstring(sender.text, "Bob") {
+		t.Errorf("Expected only Alice's streak posted, got %q mentioning %v", sender.text, sender.mentions)
+	}
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const defaultTimezone = "WIB"
+
+// timezones are the Indonesian time zones accepted by #ingatkan. None of
+// them observe daylight saving, so fixed offsets are enough.
+var timezones = map[string]*time.Location{
+	"WIB":  time.FixedZone("WIB", 7*60*60),
+	"WITA": time.FixedZone("WITA", 8*60*60),
+	"WIT":  time.FixedZone("WIT", 9*60*60),
+}
+
+// timezoneLocation returns the location of a stored timezone, WIB if unset.
+func timezoneLocation(name string) *time.Location {
+	if loc, ok := timezones[name]; ok {
+		return loc
+	}
+	return timezones[defaultTimezone]
+}
+
+type SetReminderUsecase struct {
+	settings domain.SettingsRepository
+}
+
+func NewSetReminderUsecase(settings domain.SettingsRepository) *SetReminderUsecase {
+	return &SetReminderUsecase{settings: settings}
+}
+
+// Execute handles "#ingatkan <HH:MM> [WIB|WITA|WIT]" to get a daily DM
+// reminder, "#ingatkan off" to stop it, and a bare "#ingatkan" to show it.
+func (uc *SetReminderUsecase) Execute(ctx context.Context, userID, name string, args []string) (string, error) {
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if settings == nil {
+		settings = &domain.UserSettings{UserID: userID}
+	}
+
+	if len(args) == 0 {
+		if settings.ReminderTime == "" {
+			return "Kamu belum punya pengingat pribadi. Contoh: #ingatkan 19:30 WIB", nil
+		}
+		return fmt.Sprintf("Pengingat %s: setiap hari jam %s %s ⏰", name, settings.ReminderTime, settings.Timezone), nil
+	}
+
+	if args[0] == "off" || args[0] == "hapus" {
+		settings.ReminderTime = ""
+		if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Pengingat pribadi %s sudah dimatikan.", name), nil
+	}
+
+	at, err := time.Parse("15:04", strings.ReplaceAll(args[0], ".", ":"))
+	if err != nil {
+		return "Format jam salah. Contoh: #ingatkan 19:30 WIB", nil
+	}
+
+	timezone := defaultTimezone
+	if len(args) > 1 {
+		timezone = strings.ToUpper(args[1])
+		if _, ok := timezones[timezone]; !ok {
+			return "Zona waktu harus WIB, WITA, atau WIT. Contoh: #ingatkan 19:30 WITA", nil
+		}
+	}
+
+	settings.ReminderTime = at.Format("15:04")
+	settings.Timezone = timezone
+	if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Siap! %s akan diingatkan lewat chat pribadi setiap hari jam %s %s jika belum lapor ⏰", name, settings.ReminderTime, timezone), nil
+}
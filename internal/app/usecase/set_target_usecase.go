@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const maxTargetDays = 365
+
+type SetTargetUsecase struct {
+	repo       domain.ReportRepository
+	settings   domain.SettingsRepository
+	activities domain.ActivityRepository
+	challenge  domain.Challenge
+}
+
+func NewSetTargetUsecase(repo domain.ReportRepository, settings domain.SettingsRepository) *SetTargetUsecase {
+	return &SetTargetUsecase{repo: repo, settings: settings}
+}
+
+// SetChallenge counts the progress shown by "#target" from the first day of
+// the challenge in the activity log, like the #lapor acknowledgment.
+func (uc *SetTargetUsecase) SetChallenge(challenge domain.Challenge, activities domain.ActivityRepository) {
+	uc.challenge = challenge
+	uc.activities = activities
+}
+
+// Execute handles "#target <hari>" to set a personal goal, "#target hapus" to
+// clear it, and a bare "#target" to show the current progress.
+func (uc *SetTargetUsecase) Execute(ctx context.Context, userID, name string, args []string) (string, error) {
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if settings == nil {
+		settings = &domain.UserSettings{UserID: userID}
+	}
+
+	if len(args) == 0 {
+		if settings.Target == 0 {
+			return "Kamu belum punya target. Contoh: #target 25", nil
+		}
+		report, err := uc.repo.GetReport(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		count := 0
+		if report != nil {
+			if count, err = challengeDays(ctx, uc.activities, uc.challenge, report); err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("Target %s: %d/%d hari 🎯", name, count, settings.Target), nil
+	}
+
+	if args[0] == "hapus" || args[0] == "0" {
+		settings.Target = 0
+		if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Target %s sudah dihapus.", name), nil
+	}
+
+	target, err := strconv.Atoi(args[0])
+	if err != nil || target < 1 || target > maxTargetDays {
+		return fmt.Sprintf("Target harus angka 1-%d. Contoh: #target 25", maxTargetDays), nil
+	}
+
+	settings.Target = target
+	if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Target %s diset: %d hari 🎯 Semangat!", name, target), nil
+}
@@ -0,0 +1,292 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// mockSettingsRepo implements domain.SettingsRepository for testing
+type mockSettingsRepo struct {
+	settings map[string]*domain.UserSettings
+}
+
+func (m *mockSettingsRepo) GetSettings(ctx context.Context, userID string) (*domain.UserSettings, error) {
+	return m.settings[userID], nil
+}
+
+func (m *mockSettingsRepo) GetReminderSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	var list []*domain.UserSettings
+	for _, s := range m.settings {
+		if s.ReminderTime != "" {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockSettingsRepo) GetUnrankedSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	var list []*domain.UserSettings
+	for _, s := range m.settings {
+		if s.Unranked || s.Private {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockSettingsRepo) GetUnverifiedSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	var list []*domain.UserSettings
+	for _, s := range m.settings {
+		if !s.VerifyBy.IsZero() && s.JoinedAt.IsZero() {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockSettingsRepo) GetEmailSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	var list []*domain.UserSettings
+	for _, s := range m.settings {
+		if s.Email != "" {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockSettingsRepo) GetSettingsByCalendarToken(ctx context.Context, token string) (*domain.UserSettings, error) {
+	for _, s := range m.settings {
+		if token != "" && s.CalendarToken == token {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockSettingsRepo) SaveSettings(ctx context.Context, settings *domain.UserSettings) error {
+	m.settings[settings.UserID] = settings
+	return nil
+}
+
+func (m *mockSettingsRepo) DeleteSettings(ctx context.Context, userID string) error {
+	delete(m.settings, userID)
+	return nil
+}
+
+func (m *mockSettingsRepo) InitTable(ctx context.Context) error {
+	return nil
+}
+
+// =============================================================================
+// PERSONAL TARGET TESTS
+// =============================================================================
+
+func TestTarget_SetAndShow(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 18, LastReportDate: time.Now()},
+	}}
+	settings := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+	handleUC := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(repo), usecase.NewGetLeaderboardUsecase(repo))
+	handleUC.SetTargetUsecase(usecase.NewSetTargetUsecase(repo, settings))
+	ctx := context.Background()
+
+	result, err := handleUC.Execute(ctx, "user1", "Alice", "#target")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "belum punya target") {
+		t.Errorf("Expected no-target hint, got '%s'", result)
+	}
+
+	if _, err := handleUC.Execute(ctx, "user1", "Alice", "#target 25"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings.settings["user1"].Target != 25 {
+		t.Fatalf("Expected target 25 to be saved, got %+v", settings.settings["user1"])
+	}
+
+	result, err = handleUC.Execute(ctx, "user1", "Alice", "#target")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "18/25") {
+		t.Errorf("Expected progress 18/25, got '%s'", result)
+	}
+
+	for _, invalid := range []string{"#target abc", "#target -1", "#target 1000"} {
+		result, err = handleUC.Execute(ctx, "user1", "Alice", invalid)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !containsSubstring(result, "Target harus angka") {
+			t.Errorf("'%s' should be rejected, got '%s'", invalid, result)
+		}
+	}
+
+	if _, err := handleUC.Execute(ctx, "user1", "Alice", "#target hapus"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings.settings["user1"].Target != 0 {
+		t.Errorf("Expected target to be cleared")
+	}
+}
+
+func TestTarget_ProgressInReportAndCelebration(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 24, LastReportDate: time.Now().AddDate(0, 0, -1)},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{
+		"user1": {UserID: "user1", Target: 25},
+	}}
+	uc := usecase.NewReportActivityUsecase(repo)
+	uc.SetSettingsRepository(settings)
+
+	result, err := uc.Execute(context.Background(), "user1", "Alice")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "🎯 ▓▓▓▓▓▓▓▓▓▓ 25/25") {
+		t.Errorf("Expected target progress in acknowledgment, got '%s'", result)
+	}
+	if !containsSubstring(result, "target 25 hari tercapai") {
+		t.Errorf("Expected celebration when target is hit, got '%s'", result)
+	}
+}
+
+func TestTarget_ProgressCountsOnlyTheChallenge(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 2, ActivityCount: 40, LastReportDate: now.AddDate(0, 0, -1)},
+	}}
+	activities := &mockActivityRepo{activities: []*domain.Activity{
+		// Reported in an earlier challenge
+		{UserID: "user1", ActivityType: "lari", ReportedAt: now.AddDate(0, 0, -20)},
+		{UserID: "user1", ActivityType: "lari", ReportedAt: now.AddDate(0, 0, -2)},
+		{UserID: "user1", ActivityType: "lari", ReportedAt: now.AddDate(0, 0, -1)},
+		{UserID: "user2", ActivityType: "lari", ReportedAt: now.AddDate(0, 0, -1)},
+	}}
+	uc := usecase.NewReportActivityUsecase(repo)
+	uc.SetActivityRepository(activities)
+	challenge := domain.Challenge{Start: now.AddDate(0, 0, -2), Days: 30}
+	uc.SetChallenge(challenge)
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{
+		"user1": {UserID: "user1", Target: 5},
+	}}
+	uc.SetSettingsRepository(settings)
+
+	result, err := uc.Execute(context.Background(), "user1", "Alice")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, " 3/5") || containsSubstring(result, "tercapai") {
+		t.Errorf("Expected progress of this challenge only, got '%s'", result)
+	}
+
+	targetUC := usecase.NewSetTargetUsecase(repo, settings)
+	targetUC.SetChallenge(challenge, activities)
+	result, err = targetUC.Execute(context.Background(), "user1", "Alice", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "Target Alice: 3/5 hari 🎯" {
+		t.Errorf("Expected #target to count this challenge only, got '%s'", result)
+	}
+}
+
+func TestReport_ProgressBarTowardChallenge(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 14, LastReportDate: time.Now().AddDate(0, 0, -1)},
+	}}
+	uc := usecase.NewReportActivityUsecase(repo)
+	uc.SetChallenge(domain.Challenge{Days: 30})
+
+	result, err := uc.Execute(context.Background(), "user1", "Alice")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "\n▓▓▓▓▓░░░░░ 15/30") {
+		t.Errorf("Expected challenge progress bar, got '%s'", result)
+	}
+}
+
+func TestReport_CountdownAndPace(t *testing.T) {
+	now := time.Now()
+	// Day 17 of 30
+	challenge := domain.Challenge{Start: now.AddDate(0, 0, -16), Days: 30}
+	report := func(count, target int) string {
+		repo := &mockRepo{reports: map[string]*domain.Report{
+			"user1": {UserID: "user1", Name: "Alice", Streak: 1, ActivityCount: count - 1, LastReportDate: now.AddDate(0, 0, -1)},
+		}}
+		uc := usecase.NewReportActivityUsecase(repo)
+		uc.SetChallenge(challenge)
+		uc.SetSettingsRepository(&mockSettingsRepo{settings: map[string]*domain.UserSettings{
+			"user1": {UserID: "user1", Target: target},
+		}})
+		result, err := uc.Execute(context.Background(), "user1", "Alice")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result
+	}
+
+	if result := report(17, 0); !containsSubstring(result, "⏳ 13 hari tersisa, sesuai target 30 hari 👍") {
+		t.Errorf("Expected the countdown and on pace, got '%s'", result)
+	}
+	// A personal target of 20 days needs 11 reports by day 17
+	if result := report(12, 20); !containsSubstring(result, "sesuai target 20 hari") {
+		t.Errorf("Expected on pace for the personal target, got '%s'", result)
+	}
+	if result := report(8, 20); !containsSubstring(result, "⏳ 13 hari tersisa, tertinggal 3 hari dari target 20 hari 🏃") {
+		t.Errorf("Expected behind the personal target, got '%s'", result)
+	}
+	if result := report(5, 0); !containsSubstring(result, "target 30 hari tidak terkejar lagi") {
+		t.Errorf("Expected an out-of-reach target, got '%s'", result)
+	}
+
+	// Without a start date there is nothing to count down
+	repo := &mockRepo{reports: map[string]*domain.Report{}}
+	uc := usecase.NewReportActivityUsecase(repo)
+	uc.SetChallenge(domain.Challenge{Days: 30})
+	if result, _ := uc.Execute(context.Background(), "user1", "Alice"); containsSubstring(result, "⏳") {
+		t.Errorf("Expected no countdown without a start date, got '%s'", result)
+	}
+}
+
+type fakeCelebrations struct {
+	data     []byte
+	mimeType string
+}
+
+func (f *fakeCelebrations) Random(ctx context.Context) ([]byte, string, error) {
+	return f.data, f.mimeType, nil
+}
+
+func TestReport_CelebratesMilestonesWithMedia(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 29, LastReportDate: time.Now().AddDate(0, 0, -1)},
+		"user2": {UserID: "user2", Name: "Bob", Streak: 3, ActivityCount: 10, LastReportDate: time.Now().AddDate(0, 0, -1)},
+	}}
+	uc := usecase.NewReportActivityUsecase(repo)
+	uc.SetChallenge(domain.Challenge{Days: 30})
+	uc.SetCelebrations(&fakeCelebrations{data: []byte("mp4"), mimeType: "video/mp4"})
+
+	reply, err := uc.ExecuteReply(context.Background(), "111@g.us", "user1", "Alice", "#lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(reply.GIF) != "mp4" || !containsSubstring(reply.Text, "🏁 Selamat Alice, 30 hari tantangan tuntas!") {
+		t.Errorf("Expected a GIF with the completed challenge, got %+v", reply)
+	}
+
+	reply, err = uc.ExecuteReply(context.Background(), "111@g.us", "user2", "Bob", "#lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reply.GIF != nil || reply.Image != nil {
+		t.Errorf("Expected no media for an ordinary day, got %+v", reply)
+	}
+}
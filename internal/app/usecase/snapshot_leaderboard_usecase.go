@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// SnapshotLeaderboardUsecase stores the standings at the end of the day, so
+// past boards and rank changes can be shown after the streaks moved on.
+type SnapshotLeaderboardUsecase struct {
+	reports   domain.ReportRepository
+	settings  domain.SettingsRepository
+	snapshots domain.SnapshotRepository
+	boards    *BoardBuilder
+}
+
+func NewSnapshotLeaderboardUsecase(reports domain.ReportRepository, settings domain.SettingsRepository, snapshots domain.SnapshotRepository) *SnapshotLeaderboardUsecase {
+	return &SnapshotLeaderboardUsecase{reports: reports, settings: settings, snapshots: snapshots}
+}
+
+// SetBoards ranks the snapshots like #leaderboard.
+func (uc *SnapshotLeaderboardUsecase) SetBoards(boards *BoardBuilder) {
+	uc.boards = boards
+}
+
+// Execute ranks the members like #leaderboard as of now and saves them as
+// the snapshot of now's day, replacing one taken earlier that day.
+func (uc *SnapshotLeaderboardUsecase) Execute(ctx context.Context, now time.Time) error {
+	board, err := boardsOr(uc.boards, uc.reports, uc.settings).Board(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]*domain.SnapshotEntry, 0, len(board.Members))
+	for _, m := range board.Members {
+		entries = append(entries, &domain.SnapshotEntry{
+			Day:      board.Date,
+			UserID:   m.UserID,
+			Name:     m.Name,
+			Rank:     m.Rank,
+			Streak:   m.Streak,
+			Total:    m.Total,
+			Reported: m.Today,
+		})
+	}
+	if err := uc.snapshots.SaveSnapshot(ctx, board.Date, entries); err != nil {
+		return err
+	}
+	log.Printf("Leaderboard snapshot of %s saved with %d members", board.Date, len(entries))
+	return nil
+}
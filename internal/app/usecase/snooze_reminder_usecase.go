@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const maxSnoozeDays = 30
+
+type SnoozeReminderUsecase struct {
+	settings domain.SettingsRepository
+}
+
+func NewSnoozeReminderUsecase(settings domain.SettingsRepository) *SnoozeReminderUsecase {
+	return &SnoozeReminderUsecase{settings: settings}
+}
+
+// Execute handles "#snooze" to skip today's reminder, "#snooze <hari>" to
+// skip the next N days including today, and "#snooze off" to undo it.
+func (uc *SnoozeReminderUsecase) Execute(ctx context.Context, userID, name string, args []string) (string, error) {
+	settings, err := uc.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if settings == nil {
+		settings = &domain.UserSettings{UserID: userID}
+	}
+
+	if len(args) > 0 && (args[0] == "off" || args[0] == "batal" || args[0] == "0") {
+		settings.SnoozeUntil = time.Time{}
+		if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Pengingat untuk %s aktif lagi 🔔", name), nil
+	}
+
+	days := 1
+	if len(args) > 0 {
+		days, err = strconv.Atoi(args[0])
+		if err != nil || days < 1 || days > maxSnoozeDays {
+			return fmt.Sprintf("Jumlah hari harus angka 1-%d. Contoh: #snooze 3", maxSnoozeDays), nil
+		}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	settings.SnoozeUntil = today.AddDate(0, 0, days)
+	if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+		return "", err
+	}
+
+	if days == 1 {
+		return fmt.Sprintf("Pengingat untuk %s dimatikan hari ini 😴", name), nil
+	}
+	lastDay := settings.SnoozeUntil.AddDate(0, 0, -1)
+	return fmt.Sprintf("Pengingat untuk %s dimatikan sampai %s 😴 Ketik #snooze off untuk mengaktifkan lagi.", name, lastDay.Format("02-01-2006")), nil
+}
@@ -0,0 +1,93 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestSnooze_SkipsReminder(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1)
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", Streak: 7, LastReportDate: yesterday},
+		"628222": {UserID: "628222", Name: "Bob", Streak: 8, LastReportDate: yesterday},
+	}}
+	settings := &mockSettingsRepo{settings: map[string]*domain.UserSettings{
+		"628222": {UserID: "628222", Target: 20},
+	}}
+
+	handleUC := usecase.NewHandleMessageUsecase(nil, nil)
+	handleUC.SetSnoozeUsecase(usecase.NewSnoozeReminderUsecase(settings))
+	ctx := context.Background()
+
+	result, err := handleUC.Execute(ctx, "628222", "Bob", "#snooze 3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lastDay := time.Now().AddDate(0, 0, 2).Format("02-01-2006")
+	if !containsSubstring(result, "sampai "+lastDay) {
+		t.Errorf("Expected snooze until %s, got '%s'", lastDay, result)
+	}
+	if settings.settings["628222"].Target != 20 {
+		t.Errorf("Snoozing must keep the target")
+	}
+
+	sender := &mockMentionSender{}
+	reminderUC := usecase.NewSendReminderUsecase(repo, sender, "111@g.us", 5)
+	reminderUC.SetSettingsRepository(settings)
+	if err := reminderUC.Execute(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sender.mentions) != 1 || sender.mentions[0] != "628111" {
+		t.Errorf("Expected only Alice mentioned while Bob snoozes, got %v", sender.mentions)
+	}
+
+	// Still snoozed on the last day, active again the day after
+	until := settings.settings["628222"].SnoozeUntil
+	if !time.Now().AddDate(0, 0, 2).Before(until) || time.Now().AddDate(0, 0, 3).Before(until) {
+		t.Errorf("Expected snooze to cover today and the next 2 days, until %v", until)
+	}
+
+	if _, err := handleUC.Execute(ctx, "628222", "Bob", "#snooze off"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sender = &mockMentionSender{}
+	reminderUC = usecase.NewSendReminderUsecase(repo, sender, "111@g.us", 5)
+	reminderUC.SetSettingsRepository(settings)
+	if err := reminderUC.Execute(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sender.mentions) != 2 {
+		t.Errorf("Expected Bob mentioned again after #snooze off, got %v", sender.mentions)
+	}
+}
+
+func TestSnooze_TodayAndInvalid(t *testing.T) {
+	settings := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+	uc := usecase.NewSnoozeReminderUsecase(settings)
+	ctx := context.Background()
+
+	result, err := uc.Execute(ctx, "628111", "Alice", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "hari ini") {
+		t.Errorf("Expected snooze for today, got '%s'", result)
+	}
+	now := time.Now()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	if !settings.settings["628111"].SnoozeUntil.Equal(tomorrow) {
+		t.Errorf("Expected snooze until midnight, got %v", settings.settings["628111"].SnoozeUntil)
+	}
+
+	result, err = uc.Execute(ctx, "628111", "Alice", []string{"99"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(result, "1-30") {
+		t.Errorf("Expected range hint, got '%s'", result)
+	}
+}
@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// withTx runs fn in a transaction, or directly when the backend has none.
+func withTx(ctx context.Context, tx domain.Transactor, fn func(ctx context.Context) error) error {
+	if tx == nil {
+		return fn(ctx)
+	}
+	return tx.WithTx(ctx, fn)
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// verifyWindow is how long a new member has to answer with #join.
+const verifyWindow = 48 * time.Hour
+
+// overdueNotice is how long after their deadline members are still told it
+// passed, so a restart does not bring up old ones.
+const overdueNotice = 24 * time.Hour
+
+// unverified reports whether a member joined a group while the verification
+// gate was on and has not answered with #join yet. Such members are not
+// counted: #lapor asks them to #join first and reminders skip them.
+func unverified(s *domain.UserSettings) bool {
+	return s != nil && !s.VerifyBy.IsZero() && s.JoinedAt.IsZero()
+}
+
+// VerifyMembersUsecase greets members who just joined a group and asks them
+// to confirm with #join. Until they do, they are not counted.
+type VerifyMembersUsecase struct {
+	repo     domain.ReportRepository
+	settings domain.SettingsRepository
+	sender   MentionSender
+
+	mu      sync.Mutex
+	overdue map[string]bool // Members already told their deadline passed
+}
+
+func NewVerifyMembersUsecase(repo domain.ReportRepository, settings domain.SettingsRepository, sender MentionSender) *VerifyMembersUsecase {
+	return &VerifyMembersUsecase{repo: repo, settings: settings, sender: sender, overdue: make(map[string]bool)}
+}
+
+// MemberJoined marks new members as unverified and asks them in the group
+// to type #join. Members who reported or joined before are left alone, so
+// rejoining the group does not reset them.
+func (uc *VerifyMembersUsecase) MemberJoined(ctx context.Context, groupJID string, userIDs []string) error {
+	deadline := time.Now().Add(verifyWindow)
+
+	var pending []string
+	for _, userID := range userIDs {
+		report, err := uc.repo.GetReport(ctx, userID)
+		if err != nil {
+			return err
+		}
+		settings, err := uc.settings.GetSettings(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if report != nil || (settings != nil && !settings.JoinedAt.IsZero()) {
+			continue
+		}
+
+		if settings == nil {
+			settings = &domain.UserSettings{UserID: userID}
+		}
+		settings.VerifyBy = deadline
+		if err := uc.settings.SaveSettings(ctx, settings); err != nil {
+			return err
+		}
+		pending = append(pending, userID)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// Members who used #jangan-tag before joining are welcomed without a name
+	mentions := newMentionList(uc.settings)
+	var names []string
+	for _, userID := range pending {
+		who, err := mentions.add(ctx, userID, "")
+		if err != nil {
+			return err
+		}
+		if who != "" {
+			names = append(names, who)
+		}
+	}
+	welcome := "Selamat datang"
+	if len(names) > 0 {
+		welcome += " " + strings.Join(names, " ")
+	}
+	text := fmt.Sprintf("%s! 👋\nKetik #join dalam 48 jam untuk ikut tantangan. Sebelum itu laporan kamu belum dihitung.", welcome)
+	return uc.sender.SendMention(ctx, groupJID, text, mentions.userIDs)
+}
+
+// ExpireOverdue tells the members whose deadline passed by now without
+// #join, in groupJID, that they are not counted. Each member is told once;
+// they can still answer with #join later.
+func (uc *VerifyMembersUsecase) ExpireOverdue(ctx context.Context, groupJID string, now time.Time) error {
+	list, err := uc.settings.GetUnverifiedSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	mentions := newMentionList(uc.settings)
+	var names, told []string
+	for _, s := range list {
+		late := now.Sub(s.VerifyBy)
+		if late <= 0 || late > overdueNotice || uc.overdue[s.UserID] {
+			continue
+		}
+		who, err := mentions.add(ctx, s.UserID, "")
+		if err != nil {
+			return err
+		}
+		if who != "" {
+			names = append(names, who)
+		}
+		told = append(told, s.UserID)
+	}
+	if len(told) == 0 {
+		return nil
+	}
+
+	text := "⏰ Batas 48 jam untuk #join sudah lewat, jadi laporan kamu belum dihitung. Ketik #join kapan saja kalau masih mau ikut tantangan."
+	if len(names) > 0 {
+		text = strings.Join(names, " ") + "\n" + text
+	}
+	if err := uc.sender.SendMention(ctx, groupJID, text, mentions.userIDs); err != nil {
+		return err
+	}
+	for _, userID := range told {
+		uc.overdue[userID] = true
+	}
+	return nil
+}
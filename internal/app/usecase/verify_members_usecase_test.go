@@ -0,0 +1,109 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestVerifyMembers_NewMemberMustJoinBeforeCounted(t *testing.T) {
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628222": {UserID: "628222", Name: "Bob", Streak: 3, ActivityCount: 3},
+	}}
+	settingsRepo := &mockSettingsRepo{settings: make(map[string]*domain.UserSettings)}
+	convRepo := &mockConversationRepo{conversations: make(map[string]*domain.Conversation)}
+	sender := &mockMentionSender{}
+	verifyUC := usecase.NewVerifyMembersUsecase(repo, settingsRepo, sender)
+	handleUC := newOnboardingHandler(repo, settingsRepo, convRepo)
+	ctx := context.Background()
+
+	// Bob rejoins and already reported before, only Alice is asked
+	if err := verifyUC.MemberJoined(ctx, "111@g.us", []string{"628111", "628222"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sender.chatJID != "111@g.us" || len(sender.mentions) != 1 || sender.mentions[0] != "628111" || !containsSubstring(sender.text, "#join dalam 48 jam") {
+		t.Fatalf("Expected Alice to be asked in the group, got %+v", sender)
+	}
+	if s := settingsRepo.settings["628111"]; s == nil || time.Until(s.VerifyBy) < 47*time.Hour {
+		t.Fatalf("Expected a 48 hour deadline, got %+v", s)
+	}
+	if settingsRepo.settings["628222"] != nil {
+		t.Error("Expected existing member left alone")
+	}
+
+	// Not counted until #join is answered
+	reply, err := handleUC.ExecuteInChat(ctx, "111@g.us", "628111", "Alice", "#lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(reply.Text, "ketik #join dulu") || repo.reports["628111"] != nil {
+		t.Fatalf("Expected #lapor refused before #join, got '%s'", reply.Text)
+	}
+
+	if _, err := handleUC.ExecuteInChat(ctx, "111@g.us", "628111", "Alice", "#join"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, answer := range []string{"ya", "WIB", "tidak"} {
+		if _, err := handleUC.ExecuteInChat(ctx, "628111@s.whatsapp.net", "628111", "Alice", answer); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	reply, err = handleUC.ExecuteInChat(ctx, "111@g.us", "628111", "Alice", "#lapor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !containsSubstring(reply.Text, "Laporan diterima") {
+		t.Errorf("Expected #lapor counted after #join, got '%s'", reply.Text)
+	}
+}
+
+func TestVerifyMembers_UnverifiedSkippedByReminders(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{reports: map[string]*domain.Report{
+		"628111": {UserID: "628111", Name: "Alice", Streak: 7, LastReportDate: now.AddDate(0, 0, -1)},
+	}}
+	settingsRepo := &mockSettingsRepo{settings: map[string]*domain.UserSettings{
+		"628111": {UserID: "628111", VerifyBy: now.Add(time.Hour)},
+	}}
+	reminderUC := usecase.NewSendReminderUsecase(repo, &mockMentionSender{}, "111@g.us", 5)
+	reminderUC.SetSettingsRepository(settingsRepo)
+
+	atRisk, err := reminderUC.AtRisk(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(atRisk) != 0 {
+		t.Errorf("Expected unverified member skipped, got %d", len(atRisk))
+	}
+}
+
+func TestVerifyMembers_OverdueToldOnce(t *testing.T) {
+	now := time.Now()
+	settingsRepo := &mockSettingsRepo{settings: map[string]*domain.UserSettings{
+		"628111": {UserID: "628111", VerifyBy: now.Add(-time.Hour)},                                  // overdue
+		"628222": {UserID: "628222", VerifyBy: now.Add(time.Hour)},                                   // still has time
+		"628333": {UserID: "628333", VerifyBy: now.Add(-time.Hour), JoinedAt: now.AddDate(0, 0, -1)}, // answered
+		"628444": {UserID: "628444", VerifyBy: now.AddDate(0, 0, -10)},                               // told long ago
+	}}
+	sender := &mockMentionSender{}
+	verifyUC := usecase.NewVerifyMembersUsecase(&mockRepo{reports: make(map[string]*domain.Report)}, settingsRepo, sender)
+	ctx := context.Background()
+
+	if err := verifyUC.ExpireOverdue(ctx, "111@g.us", now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sender.chatJID != "111@g.us" || len(sender.mentions) != 1 || sender.mentions[0] != "628111" || !containsSubstring(sender.text, "sudah lewat") {
+		t.Fatalf("Expected only the overdue member told, got %+v", sender)
+	}
+
+	if err := verifyUC.ExpireOverdue(ctx, "111@g.us", now.Add(time.Hour)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sender.sent != 1 {
+		t.Errorf("Expected the overdue member told only once, sent %d", sender.sent)
+	}
+}
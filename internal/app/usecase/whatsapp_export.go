@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+)
+
+// ExportMessage is one message of a WhatsApp chat export.
+type ExportMessage struct {
+	Sender string // contact name, or the phone number for unsaved contacts
+	Text   string
+	At     time.Time
+}
+
+// exportHeader matches the first line of a message in both export formats:
+//
+//	Android: 12/03/26, 19.05 - Alice: #lapor
+//	iPhone:  [12/03/26 19.05.23] Alice: #lapor
+var exportHeader = regexp.MustCompile(`^\[?(\d{1,2})[/.-](\d{1,2})[/.-](\d{2,4}),?\s+(\d{1,2})[.:](\d{2})(?:[.:](\d{2}))?(?:\s*([AaPp])\.?\s?[Mm]\.?)?(?:\]\s*|\s+-\s+)(.*)$`)
+
+// exportInvisible are the marks WhatsApp puts around names, numbers, and
+// attachments in an export.
+var exportInvisible = strings.NewReplacer("\u200e", "", "\u200f", "", "\u202a", "", "\u202c", "", "\ufeff", "", "\u00a0", " ", "\u202f", " ")
+
+type exportLine struct {
+	fields [7]string // first number, second number, year, hour, minute, second, am/pm
+	sender string
+	text   []string
+}
+
+// ParseWhatsAppExport reads the .txt of "Export chat" in local time loc.
+// Lines without a timestamp continue the previous message, and system
+// messages such as "Alice added Bob" are left out. Whether dates are day or
+// month first follows the locale of the phone that exported the chat; it is
+// guessed from dates that only work one way, and is day first, as in
+// Indonesia, when every date works both ways.
+func ParseWhatsAppExport(r io.Reader, loc *time.Location) ([]ExportMessage, error) {
+	var lines []*exportLine
+	var current *exportLine
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := exportInvisible.Replace(scanner.Text())
+		m := exportHeader.FindStringSubmatch(line)
+		if m == nil {
+			if current != nil {
+				current.text = append(current.text, line)
+			}
+			continue
+		}
+
+		current = nil
+		sender, text, ok := strings.Cut(m[8], ": ")
+		if !ok {
+			continue
+		}
+		current = &exportLine{sender: strings.TrimSpace(sender), text: []string{text}}
+		copy(current.fields[:], m[1:8])
+		lines = append(lines, current)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	dayFirst, monthFirst := false, false
+	for _, l := range lines {
+		if first, _ := strconv.Atoi(l.fields[0]); first > 12 {
+			dayFirst = true
+		}
+		if second, _ := strconv.Atoi(l.fields[1]); second > 12 {
+			monthFirst = true
+		}
+	}
+	monthFirst = monthFirst && !dayFirst
+
+	messages := make([]ExportMessage, 0, len(lines))
+	for _, l := range lines {
+		at, ok := exportTime(l.fields, monthFirst, loc)
+		if !ok {
+			continue
+		}
+		messages = append(messages, ExportMessage{
+			Sender: l.sender,
+			Text:   strings.TrimRight(strings.Join(l.text, "\n"), "\n"),
+			At:     at,
+		})
+	}
+	return messages, nil
+}
+
+func exportTime(f [7]string, monthFirst bool, loc *time.Location) (time.Time, bool) {
+	n := make([]int, 6)
+	for i := range n {
+		if f[i] == "" {
+			continue
+		}
+		n[i], _ = strconv.Atoi(f[i])
+	}
+	day, month, year, hour := n[0], n[1], n[2], n[3]
+	if monthFirst {
+		day, month = month, day
+	}
+	if year < 100 {
+		year += 2000
+	}
+	switch strings.ToLower(f[6]) {
+	case "a":
+		if hour == 12 {
+			hour = 0
+		}
+	case "p":
+		if hour < 12 {
+			hour += 12
+		}
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || n[4] > 59 || n[5] > 59 {
+		return time.Time{}, false
+	}
+
+	at := time.Date(year, time.Month(month), day, hour, n[4], n[5], 0, loc)
+	// time.Date normalizes e.g. 31/02 into March
+	if at.Day() != day {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// WhatsAppImportResult summarizes an import of a chat export.
+type WhatsAppImportResult struct {
+	Reports int      // #lapor found in the export
+	Added   int      // of those, days not counted yet
+	Unknown []string // senders that could not be matched to a member
+}
+
+// ImportWhatsAppExport backfills the #lapor of a chat export, for groups
+// that ran the challenge by hand before the bot. isReport tells which texts
+// are a #lapor.
+//
+// Unsaved contacts appear in the export as their phone number. Saved ones
+// only appear with the name in the exporter's contacts, which is matched
+// against the names of members the bot knows, e.g. from "bot import --csv";
+// names that match no member, or several, are listed as unknown.
+func (uc *BackfillReportsUsecase) ImportWhatsAppExport(ctx context.Context, r io.Reader, loc *time.Location, isReport func(text string) bool) (*WhatsAppImportResult, error) {
+	messages, err := ParseWhatsAppExport(r, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := uc.events.repo.GetAllReports(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string)
+	byID := make(map[string]string)
+	for _, m := range members {
+		byID[m.UserID] = m.Name
+		key := strings.ToLower(strings.TrimSpace(m.Name))
+		if _, taken := names[key]; taken {
+			names[key] = "" // ambiguous
+			continue
+		}
+		names[key] = m.UserID
+	}
+
+	result := &WhatsAppImportResult{}
+	unknown := make(map[string]bool)
+	var reports []HistoricalReport
+	for _, msg := range messages {
+		if !isReport(msg.Text) {
+			continue
+		}
+
+		userID, name := "", msg.Sender
+		if number, err := phone.Normalize(msg.Sender); err == nil {
+			id := uc.hasher.UserID(number)
+			userID, name = id, byID[id]
+			if name == "" {
+				name = "Unknown"
+			}
+		} else {
+			userID = names[strings.ToLower(msg.Sender)]
+			name = byID[userID]
+		}
+		if userID == "" {
+			unknown[msg.Sender] = true
+			continue
+		}
+		reports = append(reports, HistoricalReport{UserID: userID, Name: name, Message: msg.Text, At: msg.At})
+	}
+
+	for sender := range unknown {
+		result.Unknown = append(result.Unknown, sender)
+	}
+	sort.Strings(result.Unknown)
+
+	result.Reports = len(reports)
+	if len(reports) == 0 {
+		return result, nil
+	}
+	result.Added, err = uc.Backfill(ctx, reports)
+	return result, err
+}
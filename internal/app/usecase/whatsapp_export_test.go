@@ -0,0 +1,45 @@
+package usecase_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+func TestParseWhatsAppExport_AndroidAndIPhone(t *testing.T) {
+	android := "12/03/26, 19.05 - Pesan dan panggilan terenkripsi secara end-to-end.\n" +
+		"12/03/26, 19.05 - Alice: #lapor lari 5km\n" +
+		"pagi tadi\n" +
+		"\n" +
+		"13/03/26, 06.30 - \u202a+62 812\u00a03456-7890\u202c: #lapor\n" +
+		"13/03/26, 07.00 - Budi menambahkan Citra\n"
+	messages, err := usecase.ParseWhatsAppExport(strings.NewReader(android), time.UTC)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Expected the 2 member messages, got %+v", messages)
+	}
+	if messages[0].Sender != "Alice" || messages[0].Text != "#lapor lari 5km\npagi tadi" ||
+		!messages[0].At.Equal(time.Date(2026, 3, 12, 19, 5, 0, 0, time.UTC)) {
+		t.Errorf("Expected Alice's two-line report on 12 March, got %+v", messages[0])
+	}
+	if messages[1].Sender != "+62 812 3456-7890" {
+		t.Errorf("Expected the unsaved sender's number, got %q", messages[1].Sender)
+	}
+
+	// US phones put the month first, with AM/PM
+	iphone := "[3/1/26, 7:05:12\u202fPM] Alice: #lapor\n" +
+		"[3/14/26, 12:10:00 AM] Budi: \u200e#lapor renang\n"
+	messages, err = usecase.ParseWhatsAppExport(strings.NewReader(iphone), time.UTC)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(messages) != 2 ||
+		!messages[0].At.Equal(time.Date(2026, 3, 1, 19, 5, 12, 0, time.UTC)) ||
+		!messages[1].At.Equal(time.Date(2026, 3, 14, 0, 10, 0, 0, time.UTC)) || messages[1].Text != "#lapor renang" {
+		t.Errorf("Expected month-first dates in 24-hour time, got %+v", messages)
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// LoadTenant returns the configuration of a tenant from "bot tenants": the
+// environment with the tenant's overrides applied. Unless overridden, a
+// tenant gets its own SQLite file, session file, backup directory and
+// backup folder in the bucket next to the environment's, so tenants never
+// share data or a WhatsApp session.
+func LoadTenant(base Config, id string, env map[string]string) (Config, error) {
+	vars := map[string]string{
+		"TENANT":           id,
+		"SQLITE_PATH":      filepath.Join(filepath.Dir(base.SQLitePath), "tenants", id+".db"),
+		"BACKUP_DIR":       filepath.Join(base.BackupDir, id),
+		"BACKUP_S3_PREFIX": path.Join(base.BackupPrefix, id),
+	}
+	// The replicas of the environment's database hold another bot's data
+	for _, key := range []string{"SUPABASE_READ_URL", "DATABASE_READ_URL"} {
+		vars[key] = ""
+	}
+	for key, value := range env {
+		vars[key] = value
+	}
+	// A separate session file gets one per tenant too
+	if _, ok := env["WA_SESSION_PATH"]; !ok {
+		vars["WA_SESSION_PATH"] = vars["SQLITE_PATH"]
+		if base.WASessionPath != base.SQLitePath {
+			vars["WA_SESSION_PATH"] = filepath.Join(filepath.Dir(base.WASessionPath), "tenants", id+"-session.db")
+		}
+	}
+
+	// Supabase holds the data and the session of a whole bot
+	if base.SupabaseURL != "" && vars["DB_DRIVER"] != "sqlite" && (vars["SUPABASE_URL"] == "" || vars["SUPABASE_URL"] == base.SupabaseURL) {
+		return Config{}, fmt.Errorf("tenant %s would share the Supabase project of the environment, set its own SUPABASE_URL and SUPABASE_KEY", id)
+	}
+	// So does a Postgres database
+	driver := vars["DB_DRIVER"]
+	if driver == "" {
+		driver = base.DBDriver
+	}
+	if driver == "postgres" && base.DatabaseURL != "" && (vars["DATABASE_URL"] == "" || vars["DATABASE_URL"] == base.DatabaseURL) {
+		return Config{}, fmt.Errorf("tenant %s would share the Postgres database of the environment, set its own DATABASE_URL", id)
+	}
+	for _, key := range []string{"SQLITE_PATH", "WA_SESSION_PATH"} {
+		if err := os.MkdirAll(filepath.Dir(vars[key]), 0o755); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for key, value := range vars {
+		if err := os.Setenv(key, value); err != nil {
+			return Config{}, err
+		}
+	}
+	return Load(), nil
+}
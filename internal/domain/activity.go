@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Activity is a single accepted #lapor, kept as an append-only log next to the
+// aggregated Report row so rankings can be computed per type or per period.
+type Activity struct {
+	ID              int64     `json:"id" db:"id"`
+	UserID          string    `json:"user_id" db:"user_id"`
+	Name            string    `json:"name" db:"name"`
+	ActivityType    string    `json:"activity_type" db:"activity_type"`
+	DurationMinutes int       `json:"duration_minutes" db:"duration_minutes"`
+	DistanceKm      float64   `json:"distance_km" db:"distance_km"`
+	Message         string    `json:"message" db:"message"`
+	ReportedAt      time.Time `json:"reported_at" db:"reported_at"`
+}
+
+// ActivityFilter narrows GetActivities. Zero values mean "no filter".
+type ActivityFilter struct {
+	UserID       string
+	ActivityType string
+	Since        time.Time // inclusive
+	Until        time.Time // exclusive
+}
+
+type ActivityRepository interface {
+	AddActivity(ctx context.Context, activity *Activity) error
+	GetActivities(ctx context.Context, filter ActivityFilter) ([]*Activity, error)
+	// DeleteActivities removes every entry matching the filter.
+	DeleteActivities(ctx context.Context, filter ActivityFilter) error
+	InitTable(ctx context.Context) error
+}
@@ -0,0 +1,206 @@
+package activity
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	TypeLari    = "lari"
+	TypeGym     = "gym"
+	TypeSepeda  = "sepeda"
+	TypeRenang  = "renang"
+	TypeJalan   = "jalan"
+	TypeYoga    = "yoga"
+	TypeLainnya = "lainnya"
+)
+
+// Types lists the known categories in display order. TypeLainnya is the
+// fallback for reports that don't mention any known keyword.
+var Types = []string{TypeLari, TypeGym, TypeSepeda, TypeRenang, TypeJalan, TypeYoga, TypeLainnya}
+
+var keywords = map[string]string{
+	"lari":     TypeLari,
+	"run":      TypeLari,
+	"running":  TypeLari,
+	"jogging":  TypeLari,
+	"gym":      TypeGym,
+	"fitness":  TypeGym,
+	"beban":    TypeGym,
+	"sepeda":   TypeSepeda,
+	"gowes":    TypeSepeda,
+	"cycling":  TypeSepeda,
+	"bike":     TypeSepeda,
+	"renang":   TypeRenang,
+	"swim":     TypeRenang,
+	"swimming": TypeRenang,
+	"jalan":    TypeJalan,
+	"walk":     TypeJalan,
+	"walking":  TypeJalan,
+	"hiking":   TypeJalan,
+	"yoga":     TypeYoga,
+	"pilates":  TypeYoga,
+}
+
+var emojis = map[string]string{
+	TypeLari:    "🏃",
+	TypeGym:     "🏋️",
+	TypeSepeda:  "🚴",
+	TypeRenang:  "🏊",
+	TypeJalan:   "🚶",
+	TypeYoga:    "🧘",
+	TypeLainnya: "💪",
+}
+
+// metValues are rough metabolic equivalents per type, taken from the
+// Compendium of Physical Activities. Good enough for a motivational estimate,
+// not for medical use.
+var metValues = map[string]float64{
+	TypeLari:    9.8,
+	TypeGym:     5.0,
+	TypeSepeda:  7.5,
+	TypeRenang:  7.0,
+	TypeJalan:   3.5,
+	TypeYoga:    2.5,
+	TypeLainnya: 5.0,
+}
+
+// referenceWeightKg is used for calorie estimates since members' weights are
+// not collected.
+const referenceWeightKg = 70.0
+
+var (
+	minutesPattern  = regexp.MustCompile(`(?i)\b(\d+)\s*(m|mnt|menit|min|mins|minute|minutes)\b`)
+	hoursPattern    = regexp.MustCompile(`(?i)\b(\d+(?:[.,]\d+)?)\s*(j|jam|h|hr|hrs|hour|hours)\b`)
+	distancePattern = regexp.MustCompile(`(?i)\b(\d+(?:[.,]\d+)?)\s*(km|k|kilometer)\b`)
+	// Glued forms like "1j30m" need splitting so both parts get a word
+	// boundary; the m there is always minutes
+	gluedPattern = regexp.MustCompile(`(?i)(\d)(j|jam|h)(\d+)(m\b)?`)
+)
+
+// A bare m of at least this much is read as meters, e.g. "lari 400m"
+const minMeters = 100
+
+// Detail is the structured data extracted from a report message.
+type Detail struct {
+	Type            string
+	DurationMinutes int
+	DistanceKm      float64
+}
+
+// Parse extracts the activity type and optional suffixes such as duration
+// from a report message, e.g. "#lapor gym 45 menit".
+func Parse(message string) Detail {
+	return Detail{
+		Type:            ParseType(message),
+		DurationMinutes: ParseDuration(message),
+		DistanceKm:      ParseDistance(message),
+	}
+}
+
+// ParseDistance sums every distance mentioned in the message, in kilometers.
+// Accepts "5km", "10.5 km", "10,5 km" and "5k". Returns 0 if none is found.
+func ParseDistance(message string) float64 {
+	total := 0.0
+	for _, m := range distancePattern.FindAllStringSubmatch(message, -1) {
+		if km, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", "."), 64); err == nil {
+			total += km
+		}
+	}
+	return total
+}
+
+// FormatDistance renders kilometers with at most one decimal, e.g. "10.5 km".
+func FormatDistance(km float64) string {
+	return strconv.FormatFloat(math.Round(km*10)/10, 'f', -1, 64) + " km"
+}
+
+// ParseDuration sums every duration mentioned in the message, in minutes.
+// Accepts "30m", "45 menit", "1 jam", "1.5 jam", "1j30m" and English variants.
+// A bare m means meters for a swim or from 100 up, so "renang 400m" has no
+// duration. Returns 0 if no duration is found.
+func ParseDuration(message string) int {
+	message = gluedPattern.ReplaceAllStringFunc(message, func(s string) string {
+		m := gluedPattern.FindStringSubmatch(s)
+		if m[4] != "" {
+			return m[1] + m[2] + " " + m[3] + " menit"
+		}
+		return m[1] + m[2] + " " + m[3]
+	})
+
+	swim := ParseType(message) == TypeRenang
+	total := 0.0
+	for _, m := range hoursPattern.FindAllStringSubmatch(message, -1) {
+		if h, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", "."), 64); err == nil {
+			total += h * 60
+		}
+	}
+	for _, m := range minutesPattern.FindAllStringSubmatch(message, -1) {
+		min, err := strconv.Atoi(m[1])
+		if err != nil || (strings.EqualFold(m[2], "m") && (swim || min >= minMeters)) {
+			continue
+		}
+		total += float64(min)
+	}
+	return int(total + 0.5)
+}
+
+// FormatDuration renders minutes as "1 jam 30 menit".
+func FormatDuration(minutes int) string {
+	h, m := minutes/60, minutes%60
+	switch {
+	case h > 0 && m > 0:
+		return strconv.Itoa(h) + " jam " + strconv.Itoa(m) + " menit"
+	case h > 0:
+		return strconv.Itoa(h) + " jam"
+	default:
+		return strconv.Itoa(m) + " menit"
+	}
+}
+
+// EstimateCalories returns the rough kcal burned for an activity of the given
+// type and duration: MET × weight (kg) × hours.
+func EstimateCalories(activityType string, durationMinutes int) int {
+	met, ok := metValues[activityType]
+	if !ok {
+		met = metValues[TypeLainnya]
+	}
+	return int(math.Round(met * referenceWeightKg * float64(durationMinutes) / 60))
+}
+
+// ParseType returns the activity category mentioned in a report message,
+// e.g. "#lapor lari pagi" -> "lari". Unknown or missing types map to TypeLainnya.
+func ParseType(message string) string {
+	for _, word := range strings.Fields(strings.ToLower(message)) {
+		word = strings.Trim(word, ".,!?#")
+		if t, ok := keywords[word]; ok {
+			return t
+		}
+	}
+	return TypeLainnya
+}
+
+// LookupType resolves a user-supplied category name (or alias) such as the
+// argument of "#leaderboard lari". ok is false if the name is not a known type.
+func LookupType(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == TypeLainnya {
+		return TypeLainnya, true
+	}
+	t, ok := keywords[name]
+	return t, ok
+}
+
+// Label formats a type for display, e.g. "Lari 🏃".
+func Label(activityType string) string {
+	if activityType == "" {
+		activityType = TypeLainnya
+	}
+	label := strings.ToUpper(activityType[:1]) + activityType[1:]
+	if emoji, ok := emojis[activityType]; ok {
+		label += " " + emoji
+	}
+	return label
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AdminAccount is a local login for the admin API.
+type AdminAccount struct {
+	Username     string    `json:"username" db:"username"`
+	PasswordHash string    `json:"-" db:"password_hash"` // bcrypt
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+type AdminAccountRepository interface {
+	// GetAdminAccount returns nil if there is no account with that username.
+	GetAdminAccount(ctx context.Context, username string) (*AdminAccount, error)
+	SaveAdminAccount(ctx context.Context, account *AdminAccount) error
+	InitTable(ctx context.Context) error
+}
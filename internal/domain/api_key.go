@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// APIKey gives a script or integration access to the admin API. Only the
+// SHA-256 hash of the key is stored.
+type APIKey struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	KeyHash   string    `json:"-" db:"key_hash"`
+	Scope     string    `json:"scope" db:"scope"` // "read" or "admin"
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	RevokedAt time.Time `json:"revoked_at" db:"revoked_at"` // zero while the key is active
+}
+
+type APIKeyRepository interface {
+	// GetAPIKey returns nil if there is no key with that ID.
+	GetAPIKey(ctx context.Context, id string) (*APIKey, error)
+	// GetAPIKeyByHash returns nil if no key has that hash.
+	GetAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*APIKey, error)
+	SaveAPIKey(ctx context.Context, key *APIKey) error
+	InitTable(ctx context.Context) error
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// Challenge is the period the group exercises together. Start is zero when
+// the first day is not configured, in which case only the length is known.
+type Challenge struct {
+	Start time.Time // first day, only the date is used
+	Days  int       // length in days, 0 = open-ended
+}
+
+// Day returns the day of the challenge now falls on, the first day being 1.
+// It is 0 without a start date and negative before the start.
+func (c Challenge) Day(now time.Time) int {
+	if c.Start.IsZero() {
+		return 0
+	}
+	start := time.Date(c.Start.Year(), c.Start.Month(), c.Start.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(today.Sub(start).Hours()/24) + 1
+}
+
+// Running reports whether now falls within a challenge with a start date.
+func (c Challenge) Running(now time.Time) bool {
+	day := c.Day(now)
+	return day >= 1 && (c.Days <= 0 || day <= c.Days)
+}
+
+// DaysLeft returns the days after today until the challenge ends, 0 on the
+// last day. It is -1 when the end is unknown or the challenge is not
+// running.
+func (c Challenge) DaysLeft(now time.Time) int {
+	if c.Days <= 0 || !c.Running(now) {
+		return -1
+	}
+	return c.Days - c.Day(now)
+}
+
+// Expected returns how many reports a member aiming for target days should
+// have by the end of today to reach it evenly by the last day.
+func (c Challenge) Expected(target int, now time.Time) int {
+	if c.Days <= 0 || !c.Running(now) {
+		return 0
+	}
+	return target * c.Day(now) / c.Days
+}
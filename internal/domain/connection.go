@@ -0,0 +1,13 @@
+package domain
+
+// ConnectionEvent is a change of the WhatsApp session state.
+type ConnectionEvent string
+
+const (
+	ConnectionConnected    ConnectionEvent = "connected"
+	ConnectionDisconnected ConnectionEvent = "disconnected"
+	ConnectionLoggedOut    ConnectionEvent = "logged_out"
+	ConnectionPaired       ConnectionEvent = "paired"
+	ConnectionReplaced     ConnectionEvent = "stream_replaced"
+	ConnectionBanned       ConnectionEvent = "banned"
+)
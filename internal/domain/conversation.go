@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Conversation is a multi-message flow a member is in the middle of, such as
+// the #join questions. It is stored so the flow survives a restart.
+type Conversation struct {
+	UserID    string            `json:"user_id" db:"user_id"`
+	Flow      string            `json:"flow" db:"flow"` // Name of the flow, e.g. "join"
+	Step      int               `json:"step" db:"step"`
+	Data      map[string]string `json:"data" db:"data"` // Answers so far, stored as JSON
+	ExpiresAt time.Time         `json:"expires_at" db:"expires_at"`
+}
+
+// SessionStore keeps the open conversations. The database is the default;
+// Redis lets several bot instances share them.
+type SessionStore interface {
+	// GetConversation returns nil if the user has no open conversation.
+	GetConversation(ctx context.Context, userID string) (*Conversation, error)
+	SaveConversation(ctx context.Context, conv *Conversation) error
+	DeleteConversation(ctx context.Context, userID string) error
+}
+
+// ConversationRepository is a SessionStore backed by a database table.
+type ConversationRepository interface {
+	SessionStore
+	InitTable(ctx context.Context) error
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Domain event types published for data pipelines, see DomainEvent.
+const (
+	EventReportAccepted    = "report_accepted"    // a #lapor counted
+	EventStreakBroken      = "streak_broken"      // a #lapor after a missed day started the streak over
+	EventChallengeFinished = "challenge_finished" // a #lapor reached the challenge length
+)
+
+// DomainEvent is what happened to a member's report, published to a
+// message broker as JSON when EVENTS_URL is set. Unlike ReportEvent it is
+// not stored: it is a notification for consumers outside the bot.
+type DomainEvent struct {
+	ID     string    `json:"id"` // Random, lets consumers drop a duplicate
+	Type   string    `json:"type"`
+	Tenant string    `json:"tenant,omitempty"`
+	At     time.Time `json:"at"`
+	UserID string    `json:"user_id"`
+	Name   string    `json:"name"`
+	// The report row after the #lapor
+	Streak        int `json:"streak"`
+	ActivityCount int `json:"activity_count"`
+	// The streak that ended, for EventStreakBroken
+	PreviousStreak int `json:"previous_streak,omitempty"`
+	// Parsed from the #lapor message, for EventReportAccepted
+	ActivityType    string  `json:"activity_type,omitempty"`
+	DurationMinutes int     `json:"duration_minutes,omitempty"`
+	DistanceKm      float64 `json:"distance_km,omitempty"`
+	// The challenge length, for EventChallengeFinished
+	ChallengeDays int `json:"challenge_days,omitempty"`
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Group is a WhatsApp group registered at runtime via "#admin add-group".
+// Groups registered this way are served in addition to GROUP_ID.
+type Group struct {
+	JID     string    `json:"jid" db:"jid"`
+	Name    string    `json:"name" db:"name"`
+	Enabled bool      `json:"enabled" db:"enabled"`
+	AddedBy string    `json:"added_by" db:"added_by"`
+	AddedAt time.Time `json:"added_at" db:"added_at"`
+	Prefix  string    `json:"prefix" db:"prefix"` // Command prefix for this group, empty = COMMAND_PREFIX
+	// Set by "#admin leave-group": when the bot left and the leaderboard
+	// it posted as the final standings.
+	LeftAt         time.Time `json:"left_at" db:"left_at"`
+	FinalStandings string    `json:"final_standings" db:"final_standings"`
+	// CommandHint answers unknown commands with a pointer to #help instead
+	// of ignoring them. Set with "#admin hint".
+	CommandHint bool `json:"command_hint" db:"command_hint"`
+	// LeaderboardStyle lists the extras of #leaderboard in this group, e.g.
+	// "podium,pemisah". Set with "#admin style".
+	LeaderboardStyle string `json:"leaderboard_style" db:"leaderboard_style"`
+}
+
+// JoinedGroup is a group the linked WhatsApp account is a member of,
+// registered or not.
+type JoinedGroup struct {
+	JID          string
+	Name         string
+	Participants int
+}
+
+type GroupRepository interface {
+	// GetGroup returns nil if the group is not registered.
+	GetGroup(ctx context.Context, jid string) (*Group, error)
+	GetGroups(ctx context.Context) ([]*Group, error)
+	SaveGroup(ctx context.Context, group *Group) error
+	InitTable(ctx context.Context) error
+}
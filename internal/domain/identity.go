@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// LinkedIdentity maps an account on another platform, e.g. a Telegram or
+// Discord user behind a bridge or a Strava athlete, to the WhatsApp member
+// whose report it counts for. While the link waits for the member to send
+// "#link <code>", UserID is empty and Code is set.
+type LinkedIdentity struct {
+	Platform      string    `json:"platform" db:"platform"`
+	ExternalID    string    `json:"external_id" db:"external_id"`
+	UserID        string    `json:"user_id,omitempty" db:"user_id"`
+	Code          string    `json:"-" db:"code"`
+	CodeExpiresAt time.Time `json:"-" db:"code_expires_at"`
+	LinkedAt      time.Time `json:"linked_at" db:"linked_at"` // zero until linked
+}
+
+type IdentityRepository interface {
+	// GetIdentity returns nil if the account never asked for a code.
+	GetIdentity(ctx context.Context, platform, externalID string) (*LinkedIdentity, error)
+	// GetIdentityByCode returns nil if no account waits with that code.
+	GetIdentityByCode(ctx context.Context, code string) (*LinkedIdentity, error)
+	// ListIdentities returns the accounts linked to a member.
+	ListIdentities(ctx context.Context, userID string) ([]*LinkedIdentity, error)
+	SaveIdentity(ctx context.Context, identity *LinkedIdentity) error
+	DeleteIdentities(ctx context.Context, userID string) error
+	InitTable(ctx context.Context) error
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Job is a scheduled task. Jobs are stored so the schedule survives restarts
+// and runs missed while the bot was offline can be caught up.
+type Job struct {
+	Name string `json:"name" db:"name"` // Unique, e.g. "prune" or "leave:120363...@g.us"
+	Kind string `json:"kind" db:"kind"` // Handler that runs the job
+	// A cron expression ("30 19 * * *") for recurring jobs, empty for
+	// one-shot jobs, which are deleted once they ran.
+	Schedule  string    `json:"schedule" db:"schedule"`
+	Payload   string    `json:"payload" db:"payload"` // Passed to the handler as is
+	NextRun   time.Time `json:"next_run" db:"next_run"`
+	LastRun   time.Time `json:"last_run" db:"last_run"`
+	LastError string    `json:"last_error" db:"last_error"`
+}
+
+type JobRepository interface {
+	// GetJob returns nil if there is no job with that name.
+	GetJob(ctx context.Context, name string) (*Job, error)
+	GetJobs(ctx context.Context) ([]*Job, error)
+	SaveJob(ctx context.Context, job *Job) error
+	DeleteJob(ctx context.Context, name string) error
+	InitTable(ctx context.Context) error
+}
+
+// DatabaseBackup copies the database to a file while the bot keeps running.
+type DatabaseBackup interface {
+	Backup(ctx context.Context, path string) error
+}
+
+// BackupStore keeps backups off the host, e.g. in an S3 bucket, so they
+// survive losing the server.
+type BackupStore interface {
+	Upload(ctx context.Context, name string, data []byte) error
+	// List returns the names of the stored backups.
+	List(ctx context.Context) ([]string, error)
+	Delete(ctx context.Context, name string) error
+}
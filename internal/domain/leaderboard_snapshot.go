@@ -0,0 +1,34 @@
+package domain
+
+import "context"
+
+// SnapshotEntry is a member's place on the leaderboard at the end of a day,
+// so past standings can be shown after the streaks moved on.
+type SnapshotEntry struct {
+	Day      string `json:"day" db:"day"` // YYYY-MM-DD, local date
+	UserID   string `json:"user_id" db:"user_id"`
+	Name     string `json:"name" db:"name"`
+	Rank     int    `json:"rank" db:"rank"`
+	Streak   int    `json:"streak" db:"streak"` // 0 once a day was missed
+	Total    int    `json:"total" db:"total"`
+	Reported bool   `json:"reported" db:"reported"` // reported on Day
+}
+
+type SnapshotFilter struct {
+	UserID string
+	Before string // YYYY-MM-DD, exclusive
+}
+
+type SnapshotRepository interface {
+	// SaveSnapshot replaces the snapshot of day with entries.
+	SaveSnapshot(ctx context.Context, day string, entries []*SnapshotEntry) error
+	// GetSnapshot returns the entries of day by rank, none when no snapshot
+	// was taken that day.
+	GetSnapshot(ctx context.Context, day string) ([]*SnapshotEntry, error)
+	// GetSnapshots returns every entry matching the filter by day, e.g. a
+	// member's places over time.
+	GetSnapshots(ctx context.Context, filter SnapshotFilter) ([]*SnapshotEntry, error)
+	// DeleteSnapshots removes every entry matching the filter.
+	DeleteSnapshots(ctx context.Context, filter SnapshotFilter) error
+	InitTable(ctx context.Context) error
+}
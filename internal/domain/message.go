@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ArchivedMessage is a raw group message kept for backfilling reports and
+// settling disputes about who posted what and when.
+type ArchivedMessage struct {
+	ID         int64     `json:"id" db:"id"`
+	MessageID  string    `json:"message_id" db:"message_id"` // WhatsApp message ID
+	ChatID     string    `json:"chat_id" db:"chat_id"`
+	SenderID   string    `json:"sender_id" db:"sender_id"`
+	SenderName string    `json:"sender_name" db:"sender_name"`
+	Text       string    `json:"text" db:"text"`
+	MediaType  string    `json:"media_type,omitempty" db:"media_type"` // image, video, document, audio, sticker
+	MediaMime  string    `json:"media_mime,omitempty" db:"media_mime"`
+	MediaSize  int64     `json:"media_size,omitempty" db:"media_size"`
+	SentAt     time.Time `json:"sent_at" db:"sent_at"`
+}
+
+// MessageFilter narrows GetMessages. Zero values mean "no filter".
+type MessageFilter struct {
+	ChatID   string
+	SenderID string
+	Since    time.Time // inclusive
+	Until    time.Time // exclusive
+	Limit    int
+}
+
+type MessageArchiveRepository interface {
+	// ArchiveMessage stores a message; storing the same MessageID twice is a no-op.
+	ArchiveMessage(ctx context.Context, msg *ArchivedMessage) error
+	GetMessages(ctx context.Context, filter MessageFilter) ([]*ArchivedMessage, error)
+	// SearchMessages returns messages containing every word of the keyword,
+	// newest first, narrowed by the filter.
+	SearchMessages(ctx context.Context, keyword string, filter MessageFilter) ([]*ArchivedMessage, error)
+	DeleteMessages(ctx context.Context, filter MessageFilter) error
+	InitTable(ctx context.Context) error
+}
+
+// DedupStore remembers keys for a while, e.g. the IDs of handled messages,
+// so a message WhatsApp or the queue delivers twice is handled once.
+type DedupStore interface {
+	// FirstSeen records key until ttl passes and reports whether it was
+	// new.
+	FirstSeen(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Seen reports whether key is recorded, without recording it.
+	Seen(ctx context.Context, key string) (bool, error)
+}
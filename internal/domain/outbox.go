@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxMessage is a message the bot sent and how far it got, from the
+// delivery and read receipts of its recipients. In a group every member
+// sends their own receipts, so the counts tell how many members got it.
+//
+// A reply can also be queued before it is sent, in the transaction of the
+// change it acknowledges, so the change is never saved without its reply.
+// It keeps its whole text and no SentAt until it goes out.
+type OutboxMessage struct {
+	MessageID      string    `json:"message_id" db:"message_id"` // WhatsApp message ID
+	ChatID         string    `json:"chat_id" db:"chat_id"`
+	Text           string    `json:"text" db:"text"`                 // Start of the text or caption
+	QueuedAt       time.Time `json:"queued_at" db:"queued_at"`       // Zero = recorded when sent
+	SentAt         time.Time `json:"sent_at" db:"sent_at"`           // Zero = queued, not sent yet
+	DeliveredAt    time.Time `json:"delivered_at" db:"delivered_at"` // First delivery receipt, zero = none yet
+	ReadAt         time.Time `json:"read_at" db:"read_at"`           // First read receipt, zero = none yet
+	DeliveredCount int       `json:"delivered_count" db:"delivered_count"`
+	ReadCount      int       `json:"read_count" db:"read_count"`
+}
+
+// OutboxFilter narrows GetOutbox. Zero values mean "no filter".
+type OutboxFilter struct {
+	ChatID string
+	Since  time.Time // inclusive
+	Until  time.Time // exclusive
+	Limit  int
+}
+
+type OutboxRepository interface {
+	// QueueReply records a reply before it is sent, under the ID it will be
+	// sent with, joining the transaction in ctx.
+	QueueReply(ctx context.Context, msg *OutboxMessage) error
+	// GetUnsent returns the replies queued in [since, until) that were never
+	// sent, oldest first.
+	GetUnsent(ctx context.Context, since, until time.Time) ([]*OutboxMessage, error)
+	// RecordSent records a sent message, or marks a queued reply with the
+	// same ID sent.
+	RecordSent(ctx context.Context, msg *OutboxMessage) error
+	// RecordReceipt counts one recipient's delivery or read receipt for the
+	// given messages. Messages that are not in the outbox are ignored.
+	RecordReceipt(ctx context.Context, messageIDs []string, read bool, at time.Time) error
+	// GetOutbox returns sent messages, newest first.
+	GetOutbox(ctx context.Context, filter OutboxFilter) ([]*OutboxMessage, error)
+	DeleteOutbox(ctx context.Context, filter OutboxFilter) error
+	InitTable(ctx context.Context) error
+}
@@ -0,0 +1,42 @@
+package phone
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// hashedPrefix marks a hashed user ID, which can never be a phone number.
+const hashedPrefix = "h"
+
+// Hasher turns phone numbers into pseudonymous user IDs: a salted hash, so a
+// leaked database does not reveal members' numbers. A nil Hasher keeps the
+// phone number as the user ID.
+type Hasher struct {
+	salt []byte
+}
+
+// NewHasher returns a Hasher for the salt, nil when the salt is empty.
+func NewHasher(salt string) *Hasher {
+	if salt == "" {
+		return nil
+	}
+	return &Hasher{salt: []byte(salt)}
+}
+
+// UserID returns the user ID stored for a phone number. IDs that are
+// already hashed are returned unchanged.
+func (h *Hasher) UserID(number string) string {
+	if h == nil || number == "" || IsHashed(number) {
+		return number
+	}
+	mac := hmac.New(sha256.New, h.salt)
+	mac.Write([]byte(number))
+	return hashedPrefix + hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// IsHashed reports whether a user ID is a hashed phone number.
+func IsHashed(userID string) bool {
+	return len(userID) == len(hashedPrefix)+32 && strings.HasPrefix(userID, hashedPrefix)
+}
@@ -28,3 +28,30 @@ func Normalize(raw string) (string, error) {
 
 	return p, nil
 }
+
+// numberLike matches what could be a phone number in free text: 8 or more
+// digits in a row (also inside a JID), an international number with spaces
+// or dashes, or a local one like "0812-3456-7890".
+var numberLike = regexp.MustCompile(`\+\d[\d \-]{7,}\d|\d{8,}|\b0\d{2,3}[ \-]\d{3,4}[ \-]\d{3,5}\b`)
+
+// Redact replaces the phone numbers in text with "[phone]", e.g. before it
+// leaves the bot in an error report. Group IDs ("120363...@g.us") are kept
+// since they identify no person.
+func Redact(text string) string {
+	matches := numberLike.FindAllStringIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		if strings.HasPrefix(text[m[1]:], "@g.us") {
+			continue
+		}
+		sb.WriteString(text[last:m[0]])
+		sb.WriteString("[phone]")
+		last = m[1]
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ReportPrompt is a group message of the bot that members can answer to
+// report, e.g. by reacting 🔥. A message split into parts has one prompt
+// per part.
+type ReportPrompt struct {
+	MessageID string    `json:"message_id" db:"message_id"`
+	ChatID    string    `json:"chat_id" db:"chat_id"`
+	SentAt    time.Time `json:"sent_at" db:"sent_at"`
+}
+
+type PromptRepository interface {
+	SavePrompt(ctx context.Context, prompt *ReportPrompt) error
+	// GetPrompt returns nil if the message is not a prompt.
+	GetPrompt(ctx context.Context, chatID, messageID string) (*ReportPrompt, error)
+	InitTable(ctx context.Context) error
+}
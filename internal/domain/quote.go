@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+// QuoteUse records the day a motivational quote was shown, so the rotation
+// does not repeat a quote before every quote had its turn.
+type QuoteUse struct {
+	// Hash of the quote text, so editing the list keeps the history of the
+	// quotes that did not change
+	Hash string `json:"hash" db:"hash"`
+	Day  string `json:"day" db:"day"` // YYYY-MM-DD, local date
+}
+
+type QuoteRepository interface {
+	GetQuoteUses(ctx context.Context) ([]*QuoteUse, error)
+	SaveQuoteUse(ctx context.Context, use *QuoteUse) error
+	// DeleteQuoteUses starts the rotation over.
+	DeleteQuoteUses(ctx context.Context) error
+	InitTable(ctx context.Context) error
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -9,14 +10,92 @@ type Report struct {
 	UserID         string    `json:"user_id" db:"user_id"`
 	Name           string    `json:"name" db:"name"`
 	Streak         int       `json:"streak" db:"streak"`
+	BestStreak     int       `json:"best_streak" db:"best_streak"` // longest streak so far, at least Streak
 	ActivityCount  int       `json:"activity_count" db:"activity_count"`
 	LastReportDate time.Time `json:"last_report_date" db:"last_report_date"`
+	// FirstReportDate is zero when unknown, for members who reported before
+	// it was tracked.
+	FirstReportDate time.Time `json:"first_report_date" db:"first_report_date"`
+	// Version counts the saved changes of the row. UpsertReport only writes
+	// over the version that was read; 0 means the row doesn't exist yet.
+	Version int `json:"version" db:"version"`
+}
+
+// ErrAlreadyReported is returned by SubmitReport when the member already
+// reported on that day.
+var ErrAlreadyReported = errors.New("already reported today")
+
+// ErrReportConflict is returned by UpsertReport when the row changed (or was
+// created) since it was read. Read it again and redo the change.
+var ErrReportConflict = errors.New("report was changed concurrently")
+
+// Sort orders for ListReports. Ties are broken by user ID.
+const (
+	ReportSortStreak = "streak" // longest streak first
+	ReportSortTotal  = "total"  // most days reported first
+	ReportSortName   = "name"   // A-Z
+)
+
+// ReportQuery selects one page of ListReports. Zero values mean "no filter".
+type ReportQuery struct {
+	UserIDs []string  // only these members, e.g. the participants of a group
+	Since   time.Time // last report at or after (inclusive)
+	Until   time.Time // last report before (exclusive)
+	Sort    string
+	After   *Report // continue after this row of the previous page
+	Limit   int
 }
 
 type ReportRepository interface {
 	GetReport(ctx context.Context, userID string) (*Report, error)
 	UpsertReport(ctx context.Context, report *Report) error
+	// SubmitReport counts a #lapor made at the given time in one atomic step
+	// and returns the updated row. See ApplyReport for the streak rules.
+	SubmitReport(ctx context.Context, userID, name string, at time.Time) (*Report, error)
 	GetAllReports(ctx context.Context) ([]*Report, error)
+	// ListReports returns a page of reports in query.Sort order.
+	ListReports(ctx context.Context, query ReportQuery) ([]*Report, error)
+	DeleteReport(ctx context.Context, userID string) error
 	InitTable(ctx context.Context) error
 	ResolveLIDToPhone(ctx context.Context, lid string) string
+	// DeleteLIDMappings forgets every LID that resolves to the given phone
+	// number, or to a number with the given hashed user ID.
+	DeleteLIDMappings(ctx context.Context, userID string) error
+}
+
+// ApplyReport returns the member's row after a #lapor made at the given
+// time, starting from the current row (nil for a first report):
+//
+//   - the first report starts a streak of 1
+//   - a report the day after the previous one grows the streak
+//   - after a missed day the streak starts over at 1
+//   - a second report on the same day is ErrAlreadyReported
+//
+// Days are compared on the calendar each timestamp was stored with.
+func ApplyReport(report *Report, userID, name string, at time.Time) (*Report, error) {
+	if report == nil {
+		return &Report{UserID: userID, Name: name, Streak: 1, BestStreak: 1, ActivityCount: 1, LastReportDate: at, FirstReportDate: at}, nil
+	}
+
+	last := calendarDay(report.LastReportDate)
+	today := calendarDay(at)
+	if last.Equal(today) {
+		return nil, ErrAlreadyReported
+	}
+
+	next := *report
+	if last.Equal(today.AddDate(0, 0, -1)) {
+		next.Streak++
+	} else {
+		next.Streak = 1
+	}
+	next.BestStreak = max(next.BestStreak, next.Streak)
+	next.ActivityCount++
+	next.Name = name // the member may have changed their name
+	next.LastReportDate = at
+	return &next, nil
+}
+
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
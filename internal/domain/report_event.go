@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Report event types. The event stream is the history behind user_reports:
+// replaying a member's events with ProjectReport gives their report row.
+const (
+	ReportSubmitted = "report_submitted" // a #lapor was counted
+	StreakReset     = "streak_reset"     // an admin set the streak back to 0
+	AdminAdjusted   = "admin_adjusted"   // the row was set to the values in the event
+	ReportRevoked   = "report_revoked"   // the ReportSubmitted in RefID no longer counts
+)
+
+// ReportEvent is one append-only change to a member's report.
+type ReportEvent struct {
+	ID     int64     `json:"id" db:"id"`
+	UserID string    `json:"user_id" db:"user_id"`
+	Type   string    `json:"type" db:"type"`
+	At     time.Time `json:"at" db:"at"` // when the report was made or the change happened
+	Name   string    `json:"name,omitempty" db:"name"`
+	// The row after an AdminAdjusted
+	Streak         int       `json:"streak,omitempty" db:"streak"`
+	ActivityCount  int       `json:"activity_count,omitempty" db:"activity_count"`
+	LastReportDate time.Time `json:"last_report_date,omitzero" db:"last_report_date"`
+
+	RefID int64  `json:"ref_id,omitempty" db:"ref_id"`
+	Actor string `json:"actor,omitempty" db:"actor"` // member, admin or "import"
+	Note  string `json:"note,omitempty" db:"note"`
+}
+
+type ReportEventRepository interface {
+	// AppendEvent stores the event and sets its ID.
+	AppendEvent(ctx context.Context, event *ReportEvent) error
+	// GetEvents returns a member's events, oldest first.
+	GetEvents(ctx context.Context, userID string) ([]*ReportEvent, error)
+	// EventUserIDs returns every member with at least one event.
+	EventUserIDs(ctx context.Context) ([]string, error)
+	DeleteEvents(ctx context.Context, userID string) error
+	InitTable(ctx context.Context) error
+}
+
+// ProjectReport replays a member's events in time order into their report
+// row with the current streak rules. Events are usually stored in that
+// order; reports backfilled from chat history are older than the events
+// around them. Returns nil when nothing counts, e.g. every report was
+// revoked.
+func ProjectReport(userID string, events []*ReportEvent) *Report {
+	return ReplayReport(userID, events, nil)
+}
+
+// ReplayReport is ProjectReport, calling counted with the event and the
+// report row after it for every ReportSubmitted that counts. counted may be
+// nil.
+func ReplayReport(userID string, events []*ReportEvent, counted func(e *ReportEvent, report *Report)) *Report {
+	events = append([]*ReportEvent(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+
+	revoked := make(map[int64]bool)
+	for _, e := range events {
+		if e.Type == ReportRevoked {
+			revoked[e.RefID] = true
+		}
+	}
+
+	var report *Report
+	for _, e := range events {
+		switch e.Type {
+		case ReportSubmitted:
+			if revoked[e.ID] {
+				continue
+			}
+			// A same-day duplicate can't be in the stream; skip it if it is
+			if next, err := ApplyReport(report, userID, e.Name, e.At); err == nil {
+				report = next
+				if counted != nil {
+					counted(e, report)
+				}
+			}
+		case AdminAdjusted:
+			// A snapshot does not change when the member started or the
+			// streaks they had
+			var first time.Time
+			best := e.Streak
+			if report != nil {
+				first = report.FirstReportDate
+				best = max(best, report.BestStreak)
+			}
+			report = &Report{
+				UserID:          userID,
+				Name:            e.Name,
+				Streak:          e.Streak,
+				BestStreak:      best,
+				ActivityCount:   e.ActivityCount,
+				LastReportDate:  e.LastReportDate,
+				FirstReportDate: first,
+			}
+		case StreakReset:
+			if report != nil {
+				report.Streak = 0
+			}
+		}
+	}
+	return report
+}
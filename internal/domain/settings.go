@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// UserSettings holds per-member preferences that are not part of the
+// streak/count aggregate.
+type UserSettings struct {
+	UserID      string    `json:"user_id" db:"user_id"`
+	Target      int       `json:"target" db:"target"`             // Personal goal in days, 0 = none
+	SnoozeUntil time.Time `json:"snooze_until" db:"snooze_until"` // No reminders before this, zero = not snoozed
+	// Personal DM reminder as HH:MM in Timezone (WIB, WITA or WIT), empty = off
+	ReminderTime string `json:"reminder_time" db:"reminder_time"`
+	Timezone     string `json:"timezone" db:"timezone"`
+	// Set by #join: the name shown instead of the WhatsApp name, and when
+	// onboarding was completed (zero = never joined)
+	DisplayName string    `json:"display_name" db:"display_name"`
+	JoinedAt    time.Time `json:"joined_at" db:"joined_at"`
+	// Set when the member joined a group with VERIFY_NEW_MEMBERS on: they
+	// should answer with #join before this. Zero = no verification needed
+	VerifyBy time.Time `json:"verify_by" db:"verify_by"`
+	// Set by admins for e.g. the coach or a test account: left out of
+	// leaderboards and recaps, but can still use every command
+	Unranked bool `json:"unranked" db:"unranked"`
+	// Set by the member with #privat: tracked as usual, but kept out of
+	// leaderboards and recaps
+	Private bool `json:"private" db:"private"`
+	// Set by the member with #jangan-tag: named without an @-mention in
+	// reminders and greetings, so WhatsApp does not notify them
+	NoMention bool `json:"no_mention" db:"no_mention"`
+	// Set by the member with #email: the weekly digest is sent there,
+	// empty = no digest
+	Email string `json:"email" db:"email"`
+	// Set by the member with #kalender: the secret in their calendar URL,
+	// empty = no calendar
+	CalendarToken string `json:"-" db:"calendar_token"`
+}
+
+type SettingsRepository interface {
+	// GetSettings returns nil if the user has no stored settings.
+	GetSettings(ctx context.Context, userID string) (*UserSettings, error)
+	// GetReminderSettings returns every user with a personal reminder time.
+	GetReminderSettings(ctx context.Context) ([]*UserSettings, error)
+	// GetUnrankedSettings returns every user left out of the ranking, by
+	// admins or in private mode.
+	GetUnrankedSettings(ctx context.Context) ([]*UserSettings, error)
+	// GetUnverifiedSettings returns every user asked to confirm with #join
+	// who has not answered yet.
+	GetUnverifiedSettings(ctx context.Context) ([]*UserSettings, error)
+	// GetEmailSettings returns every user who gave an email address.
+	GetEmailSettings(ctx context.Context) ([]*UserSettings, error)
+	// GetSettingsByCalendarToken returns nil if no user has that token.
+	GetSettingsByCalendarToken(ctx context.Context, token string) (*UserSettings, error)
+	SaveSettings(ctx context.Context, settings *UserSettings) error
+	DeleteSettings(ctx context.Context, userID string) error
+	InitTable(ctx context.Context) error
+}
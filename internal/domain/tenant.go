@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Tenant is a community hosted by the same deployment, with its own
+// WhatsApp account, groups, challenge and database. Tenants are managed
+// with "bot tenants"; once one is registered, "bot" runs every enabled
+// tenant instead of the bot configured by the environment.
+type Tenant struct {
+	ID      string `json:"id" db:"id"` // Lowercase letters, digits and "-", used in file names
+	Name    string `json:"name" db:"name"`
+	Enabled bool   `json:"enabled" db:"enabled"`
+	// Env overrides environment variables for this tenant only, e.g.
+	// GROUP_ID, BOT_PHONE or CHALLENGE_DAYS. Stored as JSON.
+	Env       map[string]string `json:"env" db:"env"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+}
+
+type TenantRepository interface {
+	// GetTenant returns nil if the tenant does not exist.
+	GetTenant(ctx context.Context, id string) (*Tenant, error)
+	GetTenants(ctx context.Context) ([]*Tenant, error)
+	SaveTenant(ctx context.Context, tenant *Tenant) error
+	DeleteTenant(ctx context.Context, id string) error
+	InitTable(ctx context.Context) error
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "context"
+
+// Transactor runs fn inside one database transaction. Repository calls made
+// with the ctx passed to fn commit together, or not at all when fn returns
+// an error. A WithTx inside fn joins the outer transaction.
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
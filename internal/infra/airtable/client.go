@@ -0,0 +1,155 @@
+// Package airtable keeps the reports and standings in an Airtable base, for
+// organizers who build their own views and forms on top of them.
+package airtable
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+// DefaultBaseURL is the Airtable REST API.
+const DefaultBaseURL = "https://api.airtable.com/v0"
+
+// batchSize is the most records Airtable accepts in one request.
+const batchSize = 10
+
+// Client writes to two tables of a base, which need these fields:
+//
+//	reports    Waktu, Member, Nama, Aktivitas, Durasi, Jarak, Streak, Total
+//	standings  Member, Peringkat, Nama, Streak, Total, Hari Ini
+//
+// Member is an ID that does not show the phone number; standings rows are
+// matched on it. Aktivitas may be a single select, new options are added.
+type Client struct {
+	baseURL   string
+	token     string
+	base      string
+	reports   string
+	standings string
+	http      *http.Client
+}
+
+// NewClient writes with a personal access token (or API key) to the tables
+// of a base, e.g. NewClient(DefaultBaseURL, token, "appXXXX", "Laporan",
+// "Klasemen").
+func NewClient(baseURL, token, base, reports, standings string) *Client {
+	return &Client{
+		baseURL:   baseURL,
+		token:     token,
+		base:      base,
+		reports:   reports,
+		standings: standings,
+		http:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *Client) Name() string {
+	return "Airtable"
+}
+
+type record struct {
+	Fields map[string]interface{} `json:"fields"`
+}
+
+type upsert struct {
+	FieldsToMergeOn []string `json:"fieldsToMergeOn"`
+}
+
+type request struct {
+	Records       []record `json:"records"`
+	Typecast      bool     `json:"typecast"`
+	PerformUpsert *upsert  `json:"performUpsert,omitempty"`
+}
+
+func (c *Client) AddReports(ctx context.Context, rows []usecase.SyncReport) error {
+	records := make([]record, 0, len(rows))
+	for _, r := range rows {
+		fields := map[string]interface{}{
+			"Waktu":  r.At.UTC().Format(time.RFC3339),
+			"Member": r.Member,
+			"Nama":   r.Name,
+			"Streak": r.Streak,
+			"Total":  r.Total,
+		}
+		if r.ActivityType != "" {
+			fields["Aktivitas"] = r.ActivityType
+		}
+		if r.DurationMinutes > 0 {
+			fields["Durasi"] = r.DurationMinutes
+		}
+		if r.DistanceKm > 0 {
+			fields["Jarak"] = r.DistanceKm
+		}
+		records = append(records, record{Fields: fields})
+	}
+	return c.write(ctx, http.MethodPost, c.reports, records, nil)
+}
+
+func (c *Client) UpdateStandings(ctx context.Context, rows []usecase.SyncStanding) error {
+	records := make([]record, 0, len(rows))
+	for _, r := range rows {
+		records = append(records, record{Fields: map[string]interface{}{
+			"Member":    r.Member,
+			"Peringkat": r.Rank,
+			"Nama":      r.Name,
+			"Streak":    r.Streak,
+			"Total":     r.Total,
+			"Hari Ini":  r.Today,
+		}})
+	}
+	return c.write(ctx, http.MethodPatch, c.standings, records, &upsert{FieldsToMergeOn: []string{"Member"}})
+}
+
+// write sends the records in batches. Airtable allows 5 requests per
+// second per base, so batches are spaced out.
+func (c *Client) write(ctx context.Context, method, table string, records []record, merge *upsert) error {
+	endpoint := fmt.Sprintf("%s/%s/%s", c.baseURL, url.PathEscape(c.base), url.PathEscape(table))
+	for start := 0; start < len(records); start += batchSize {
+		if start > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(250 * time.Millisecond):
+			}
+		}
+		end := min(start+batchSize, len(records))
+		body, err := json.Marshal(request{Records: records[start:end], Typecast: true, PerformUpsert: merge})
+		if err != nil {
+			return err
+		}
+		if err := c.do(ctx, method, endpoint, body); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Airtable explains the error, e.g. an unknown field name
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("airtable returned status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package airtable_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/airtable"
+)
+
+type request struct {
+	Method string
+	Path   string
+	Auth   string
+	Body   struct {
+		Records []struct {
+			Fields map[string]interface{} `json:"fields"`
+		} `json:"records"`
+		Typecast      bool `json:"typecast"`
+		PerformUpsert *struct {
+			FieldsToMergeOn []string `json:"fieldsToMergeOn"`
+		} `json:"performUpsert"`
+	}
+}
+
+func newTestServer(t *testing.T, status int) (*httptest.Server, *[]request) {
+	var mu sync.Mutex
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{Method: r.Method, Path: r.URL.EscapedPath(), Auth: r.Header.Get("Authorization")}
+		if err := json.NewDecoder(r.Body).Decode(&req.Body); err != nil {
+			t.Errorf("Expected a JSON body: %v", err)
+		}
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+		w.WriteHeader(status)
+		fmt.Fprint(w, `{"error":{"type":"UNKNOWN_FIELD_NAME","message":"Unknown field name: \"Jarak\""}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+// =============================================================================
+// AIRTABLE TESTS
+// =============================================================================
+
+func TestClient_AddReportsInBatches(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusOK)
+	client := airtable.NewClient(srv.URL, "pat123", "appBase", "Laporan Harian", "Klasemen")
+
+	rows := make([]usecase.SyncReport, 12)
+	for i := range rows {
+		rows[i] = usecase.SyncReport{Member: fmt.Sprint("m", i), Name: "Budi", At: time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC), Streak: i + 1, Total: i + 1}
+	}
+	rows[0].ActivityType, rows[0].DistanceKm = "lari", 5.5
+
+	if err := client.AddReports(context.Background(), rows); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("Expected 12 rows sent in batches of 10, got %d requests", len(*requests))
+	}
+	first := (*requests)[0]
+	if first.Method != http.MethodPost || first.Path != "/appBase/Laporan%20Harian" || first.Auth != "Bearer pat123" {
+		t.Errorf("Expected a POST to the reports table with the token, got %s %s %q", first.Method, first.Path, first.Auth)
+	}
+	if len(first.Body.Records) != 10 || len((*requests)[1].Body.Records) != 2 || !first.Body.Typecast {
+		t.Errorf("Expected batches of 10 and 2 with typecast, got %+v", first.Body)
+	}
+	fields := first.Body.Records[0].Fields
+	if fields["Aktivitas"] != "lari" || fields["Jarak"] != 5.5 || fields["Waktu"] != "2026-03-01T06:00:00Z" || fields["Member"] != "m0" {
+		t.Errorf("Expected the report fields, got %v", fields)
+	}
+	if _, ok := fields["Durasi"]; ok {
+		t.Errorf("Expected no duration when none was reported, got %v", fields)
+	}
+}
+
+func TestClient_UpdateStandingsUpserts(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusOK)
+	client := airtable.NewClient(srv.URL, "pat123", "appBase", "Laporan", "Klasemen")
+
+	err := client.UpdateStandings(context.Background(), []usecase.SyncStanding{{Member: "m1", Rank: 1, Name: "Budi", Streak: 4, Total: 9, Today: true}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	req := (*requests)[0]
+	if req.Method != http.MethodPatch || req.Path != "/appBase/Klasemen" {
+		t.Errorf("Expected a PATCH to the standings table, got %s %s", req.Method, req.Path)
+	}
+	if req.Body.PerformUpsert == nil || len(req.Body.PerformUpsert.FieldsToMergeOn) != 1 || req.Body.PerformUpsert.FieldsToMergeOn[0] != "Member" {
+		t.Errorf("Expected an upsert on Member, got %+v", req.Body.PerformUpsert)
+	}
+	if fields := req.Body.Records[0].Fields; fields["Peringkat"] != float64(1) || fields["Hari Ini"] != true {
+		t.Errorf("Expected the standing fields, got %v", fields)
+	}
+}
+
+func TestClient_Error(t *testing.T) {
+	srv, _ := newTestServer(t, http.StatusUnprocessableEntity)
+	client := airtable.NewClient(srv.URL, "pat123", "appBase", "Laporan", "Klasemen")
+
+	err := client.AddReports(context.Background(), []usecase.SyncReport{{Member: "m1"}})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if !strings.Contains(err.Error(), "422") || !strings.Contains(err.Error(), "UNKNOWN_FIELD_NAME") {
+		t.Errorf("Expected Airtable's explanation in the error, got %v", err)
+	}
+}
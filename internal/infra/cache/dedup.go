@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Dedup implements domain.DedupStore in memory, for a single process.
+type Dedup struct {
+	mu    sync.Mutex
+	swept time.Time
+	seen  map[string]time.Time // key -> expiry
+}
+
+func NewDedup() *Dedup {
+	return &Dedup{seen: make(map[string]time.Time)}
+}
+
+func (d *Dedup) FirstSeen(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Drop expired keys once a minute, so the map does not grow forever
+	if now.Sub(d.swept) > time.Minute {
+		for k, expires := range d.seen {
+			if !now.Before(expires) {
+				delete(d.seen, k)
+			}
+		}
+		d.swept = now
+	}
+
+	if expires, ok := d.seen[key]; ok && now.Before(expires) {
+		return false, nil
+	}
+	d.seen[key] = now.Add(ttl)
+	return true, nil
+}
+
+func (d *Dedup) Seen(ctx context.Context, key string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	expires, ok := d.seen[key]
+	return ok && time.Now().Before(expires), nil
+}
@@ -0,0 +1,59 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/cache"
+)
+
+// =============================================================================
+// DEDUP TESTS
+// =============================================================================
+
+func TestDedup_FirstSeenOnce(t *testing.T) {
+	d := cache.NewDedup()
+	ctx := context.Background()
+
+	for i, want := range []bool{true, false, false} {
+		first, err := d.FirstSeen(ctx, "message:1", time.Hour)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if first != want {
+			t.Errorf("Call %d: expected %v, got %v", i+1, want, first)
+		}
+	}
+	if first, _ := d.FirstSeen(ctx, "message:2", time.Hour); !first {
+		t.Error("Expected another key to be new")
+	}
+}
+
+func TestDedup_Expires(t *testing.T) {
+	d := cache.NewDedup()
+	ctx := context.Background()
+
+	if first, _ := d.FirstSeen(ctx, "message:1", 10*time.Millisecond); !first {
+		t.Fatal("Expected the key to be new")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if first, _ := d.FirstSeen(ctx, "message:1", time.Hour); !first {
+		t.Error("Expected the key to be new again after the TTL")
+	}
+}
+
+func TestDedup_SeenDoesNotRecord(t *testing.T) {
+	d := cache.NewDedup()
+	ctx := context.Background()
+
+	if seen, _ := d.Seen(ctx, "message:1"); seen {
+		t.Fatal("Expected a new key not to be seen")
+	}
+	if first, _ := d.FirstSeen(ctx, "message:1", time.Hour); !first {
+		t.Fatal("Expected Seen not to record the key")
+	}
+	if seen, _ := d.Seen(ctx, "message:1"); !seen {
+		t.Error("Expected the recorded key to be seen")
+	}
+}
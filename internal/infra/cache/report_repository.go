@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// ReportRepository keeps reports in memory in front of another
+// ReportRepository, so #leaderboard and #stats under load don't hit the
+// database on every message. Writes through this repository update or drop
+// the cached rows; the TTL bounds how long writes made elsewhere (another
+// instance, or the CLI) stay invisible.
+type ReportRepository struct {
+	domain.ReportRepository
+
+	ttl time.Duration
+
+	mu      sync.Mutex
+	gen     uint64 // bumped on every write, so a slow read can't cache old rows
+	users   map[string]reportEntry
+	all     []*domain.Report
+	allTill time.Time
+}
+
+type reportEntry struct {
+	report  *domain.Report // nil when the member has no report yet
+	expires time.Time
+}
+
+func NewReportRepository(next domain.ReportRepository, ttl time.Duration) *ReportRepository {
+	return &ReportRepository{
+		ReportRepository: next,
+		ttl:              ttl,
+		users:            make(map[string]reportEntry),
+	}
+}
+
+func (r *ReportRepository) GetReport(ctx context.Context, userID string) (*domain.Report, error) {
+	r.mu.Lock()
+	entry, ok := r.users[userID]
+	gen := r.gen
+	r.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return copyReport(entry.report), nil
+	}
+
+	report, err := r.ReportRepository.GetReport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.gen == gen {
+		r.users[userID] = reportEntry{report: copyReport(report), expires: time.Now().Add(r.ttl)}
+	}
+	r.mu.Unlock()
+	return report, nil
+}
+
+func (r *ReportRepository) GetAllReports(ctx context.Context) ([]*domain.Report, error) {
+	r.mu.Lock()
+	all, fresh := r.all, time.Now().Before(r.allTill)
+	gen := r.gen
+	r.mu.Unlock()
+	if all != nil && fresh {
+		return copyReports(all), nil
+	}
+
+	reports, err := r.ReportRepository.GetAllReports(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.gen == gen {
+		r.all = copyReports(reports)
+		if r.all == nil {
+			r.all = []*domain.Report{}
+		}
+		r.allTill = time.Now().Add(r.ttl)
+	}
+	r.mu.Unlock()
+	return reports, nil
+}
+
+func (r *ReportRepository) UpsertReport(ctx context.Context, report *domain.Report) error {
+	err := r.ReportRepository.UpsertReport(ctx, report)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gen++
+	r.all = nil
+	if err != nil {
+		// The row may or may not have changed, read it again next time
+		delete(r.users, report.UserID)
+		return err
+	}
+	r.users[report.UserID] = reportEntry{report: copyReport(report), expires: time.Now().Add(r.ttl)}
+	return nil
+}
+
+func (r *ReportRepository) SubmitReport(ctx context.Context, userID, name string, at time.Time) (*domain.Report, error) {
+	report, err := r.ReportRepository.SubmitReport(ctx, userID, name, at)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gen++
+	r.all = nil
+	if err != nil {
+		delete(r.users, userID)
+		return nil, err
+	}
+	r.users[userID] = reportEntry{report: copyReport(report), expires: time.Now().Add(r.ttl)}
+	return report, nil
+}
+
+func (r *ReportRepository) DeleteReport(ctx context.Context, userID string) error {
+	err := r.ReportRepository.DeleteReport(ctx, userID)
+	r.Invalidate()
+	return err
+}
+
+// Invalidate drops everything, e.g. after a bulk change.
+func (r *ReportRepository) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gen++
+	r.all = nil
+	r.users = make(map[string]reportEntry)
+}
+
+// copyReport keeps callers, which usually modify a report before saving
+// it, from changing the cached row.
+func copyReport(report *domain.Report) *domain.Report {
+	if report == nil {
+		return nil
+	}
+	c := *report
+	return &c
+}
+
+func copyReports(reports []*domain.Report) []*domain.Report {
+	if reports == nil {
+		return nil
+	}
+	out := make([]*domain.Report, len(reports))
+	for i, report := range reports {
+		out[i] = copyReport(report)
+	}
+	return out
+}
+
+// Transactor drops the report cache after every transaction: rows cached
+// while it ran may have been rolled back, or read from before it committed.
+type Transactor struct {
+	domain.Transactor
+	reports *ReportRepository
+}
+
+func NewTransactor(next domain.Transactor, reports *ReportRepository) *Transactor {
+	return &Transactor{Transactor: next, reports: reports}
+}
+
+func (t *Transactor) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	defer t.reports.Invalidate()
+	return t.Transactor.WithTx(ctx, fn)
+}
@@ -0,0 +1,136 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/cache"
+)
+
+// =============================================================================
+// REPORT CACHE TESTS
+// =============================================================================
+
+// countingRepo is an in-memory ReportRepository that counts reads.
+type countingRepo struct {
+	domain.ReportRepository
+	reports map[string]domain.Report
+	gets    int
+	lists   int
+}
+
+func (r *countingRepo) GetReport(ctx context.Context, userID string) (*domain.Report, error) {
+	r.gets++
+	report, ok := r.reports[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &report, nil
+}
+
+func (r *countingRepo) GetAllReports(ctx context.Context) ([]*domain.Report, error) {
+	r.lists++
+	var all []*domain.Report
+	for _, report := range r.reports {
+		report := report
+		all = append(all, &report)
+	}
+	return all, nil
+}
+
+func (r *countingRepo) UpsertReport(ctx context.Context, report *domain.Report) error {
+	r.reports[report.UserID] = *report
+	return nil
+}
+
+func (r *countingRepo) DeleteReport(ctx context.Context, userID string) error {
+	delete(r.reports, userID)
+	return nil
+}
+
+func newCountingRepo() *countingRepo {
+	return &countingRepo{reports: map[string]domain.Report{
+		"user1": {UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 10},
+	}}
+}
+
+func TestReportCache_ServesReadsFromMemory(t *testing.T) {
+	repo := newCountingRepo()
+	c := cache.NewReportRepository(repo, time.Hour)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		report, err := c.GetReport(ctx, "user1")
+		if err != nil || report == nil || report.Streak != 3 {
+			t.Fatalf("GetReport = %+v, %v", report, err)
+		}
+		// Callers modify reports before saving them; that must not leak into the cache
+		report.Streak = 99
+
+		missing, err := c.GetReport(ctx, "nobody")
+		if err != nil || missing != nil {
+			t.Fatalf("GetReport(nobody) = %+v, %v", missing, err)
+		}
+		if _, err := c.GetAllReports(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if repo.gets != 2 {
+		t.Errorf("Expected 2 database reads (user1, nobody), got %d", repo.gets)
+	}
+	if repo.lists != 1 {
+		t.Errorf("Expected 1 GetAllReports on the database, got %d", repo.lists)
+	}
+}
+
+func TestReportCache_WritesInvalidate(t *testing.T) {
+	repo := newCountingRepo()
+	c := cache.NewReportRepository(repo, time.Hour)
+	ctx := context.Background()
+
+	if _, err := c.GetAllReports(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UpsertReport(ctx, &domain.Report{UserID: "user2", Name: "Bob", Streak: 1, ActivityCount: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, _ := c.GetReport(ctx, "user2")
+	if report == nil || report.Name != "Bob" {
+		t.Errorf("Expected the upserted report, got %+v", report)
+	}
+	if repo.gets != 0 {
+		t.Errorf("Expected the upserted report to be served from memory, got %d reads", repo.gets)
+	}
+	all, _ := c.GetAllReports(ctx)
+	if len(all) != 2 || repo.lists != 2 {
+		t.Errorf("Expected a fresh leaderboard with 2 reports, got %d (%d reads)", len(all), repo.lists)
+	}
+
+	if err := c.DeleteReport(ctx, "user1"); err != nil {
+		t.Fatal(err)
+	}
+	if report, _ := c.GetReport(ctx, "user1"); report != nil {
+		t.Errorf("Expected user1 to be gone, got %+v", report)
+	}
+	if all, _ := c.GetAllReports(ctx); len(all) != 1 {
+		t.Errorf("Expected 1 report after delete, got %d", len(all))
+	}
+}
+
+func TestReportCache_Expires(t *testing.T) {
+	repo := newCountingRepo()
+	c := cache.NewReportRepository(repo, time.Millisecond)
+	ctx := context.Background()
+
+	c.GetReport(ctx, "user1")
+	time.Sleep(5 * time.Millisecond)
+	c.GetReport(ctx, "user1")
+
+	if repo.gets != 2 {
+		t.Errorf("Expected expired entry to be read again, got %d reads", repo.gets)
+	}
+}
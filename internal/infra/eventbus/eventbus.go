@@ -0,0 +1,43 @@
+// Package eventbus publishes domain events to a message broker, so data
+// pipelines can follow the reports without polling the database. Each
+// event type goes to its own subject or topic, "<prefix>.<type>", e.g.
+// lapor-bot.report_accepted, as JSON.
+package eventbus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+// Publisher is an EventPublisher that holds a broker connection.
+type Publisher interface {
+	usecase.EventPublisher
+	// Close sends the events still buffered and disconnects.
+	Close() error
+}
+
+// New connects to the broker of an EVENTS_URL:
+//
+//	nats://localhost:4222         NATS, also tls:// and several URLs separated by commas
+//	kafka://broker1:9092,broker2:9092
+//
+// The tenant is added to every event so tenants can share the topics.
+func New(url, prefix, tenant string) (Publisher, error) {
+	scheme, rest, ok := strings.Cut(url, "://")
+	if !ok {
+		return nil, fmt.Errorf("EVENTS_URL %q has no scheme, use nats:// or kafka://", url)
+	}
+	switch scheme {
+	case "nats", "tls":
+		return newNATS(url, prefix, tenant)
+	case "kafka":
+		return newKafka(strings.Split(rest, ","), prefix, tenant)
+	}
+	return nil, fmt.Errorf("unsupported EVENTS_URL scheme %q, use nats:// or kafka://", scheme)
+}
+
+func subject(prefix, eventType string) string {
+	return prefix + "." + eventType
+}
@@ -0,0 +1,53 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaPublisher writes to Kafka in the background, in batches. Messages
+// are keyed by user ID, so the events of one member stay in order on one
+// partition. Missing topics are created when the brokers allow it.
+type kafkaPublisher struct {
+	writer *kafka.Writer
+	prefix string
+	tenant string
+}
+
+func newKafka(brokers []string, prefix, tenant string) (*kafkaPublisher, error) {
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Balancer:               &kafka.Hash{},
+		BatchTimeout:           100 * time.Millisecond,
+		Async:                  true,
+		AllowAutoTopicCreation: true,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				log.Printf("Failed to publish %d events to Kafka: %v", len(messages), err)
+			}
+		},
+	}
+	return &kafkaPublisher{writer: writer, prefix: prefix, tenant: tenant}, nil
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	event.Tenant = p.tenant
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Topic: subject(p.prefix, event.Type),
+		Key:   []byte(event.UserID),
+		Value: data,
+	})
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
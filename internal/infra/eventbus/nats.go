@@ -0,0 +1,53 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/nats-io/nats.go"
+)
+
+// natsPublisher publishes core NATS messages. They are buffered by the
+// client and sent in the background; while the server is unreachable the
+// client reconnects and keeps buffering.
+type natsPublisher struct {
+	conn   *nats.Conn
+	prefix string
+	tenant string
+}
+
+func newNATS(url, prefix, tenant string) (*natsPublisher, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("lapor-bot"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Printf("Disconnected from NATS: %v", err)
+			}
+		}),
+		nats.ReconnectHandler(func(c *nats.Conn) {
+			log.Printf("Reconnected to NATS at %s", c.ConnectedUrl())
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &natsPublisher{conn: conn, prefix: prefix, tenant: tenant}, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	event.Tenant = p.tenant
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.conn.Publish(subject(p.prefix, event.Type), data)
+}
+
+func (p *natsPublisher) Close() error {
+	defer p.conn.Close()
+	return p.conn.FlushTimeout(5 * time.Second)
+}
@@ -0,0 +1,90 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+// Calendars reads a member's report history by the secret token in their
+// calendar URL.
+type Calendars interface {
+	// Calendar returns usecase.ErrCalendarNotFound for unknown tokens.
+	Calendar(ctx context.Context, token string) (*usecase.Calendar, error)
+}
+
+// handleCalendar serves GET /calendar/{token}.ics as iCalendar, with every
+// reported day as an all-day event.
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	calendar, err := s.calendars.Calendar(r.Context(), token)
+	if errors.Is(err, usecase.ErrCalendarNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("Calendar: %v", err)
+		http.Error(w, "calendar unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	var sb strings.Builder
+	line := func(name, value string) {
+		writeICalLine(&sb, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//lapor-bot//kalender laporan//ID")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escapeICal("Lapor "+calendar.Name))
+	line("REFRESH-INTERVAL;VALUE=DURATION", "PT6H")
+	for _, day := range calendar.Days {
+		line("BEGIN", "VEVENT")
+		line("UID", day.UID)
+		line("DTSTAMP", day.At.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE", day.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE", day.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY", escapeICal(day.Summary))
+		line("DESCRIPTION", escapeICal(day.Description))
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// escapeICal escapes a TEXT value (RFC 5545 3.3.11).
+func escapeICal(text string) string {
+	return icalEscaper.Replace(text)
+}
+
+// writeICalLine folds lines longer than 75 octets (RFC 5545 3.1), without
+// splitting a UTF-8 character. Continuation lines start with a space.
+func writeICalLine(sb *strings.Builder, text string) {
+	limit := 75
+	for len(text) > limit {
+		cut := limit
+		for !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		sb.WriteString(text[:cut] + "\r\n ")
+		text = text[cut:]
+		limit = 74
+	}
+	sb.WriteString(text + "\r\n")
+}
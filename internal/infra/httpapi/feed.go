@@ -0,0 +1,84 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/xml"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+// Feed lists the entries of the public feed, newest first.
+type Feed interface {
+	Entries(ctx context.Context, now time.Time) ([]usecase.FeedEntry, error)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// handleFeed serves the feed as Atom. Entry IDs are the feed URL with the
+// entry's ID as fragment, so they stay the same between requests.
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.feed.Entries(r.Context(), time.Now())
+	if err != nil {
+		log.Printf("Feed: %v", err)
+		http.Error(w, "feed unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	feed := atomFeed{
+		ID:     s.feedURL,
+		Title:  s.feedTitle,
+		Author: atomAuthor{Name: s.feedTitle},
+		Link:   atomLink{Rel: "self", Href: s.feedURL},
+	}
+	updated := time.Unix(0, 0)
+	for _, e := range entries {
+		if e.Updated.After(updated) {
+			updated = e.Updated
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      s.feedURL + "#" + e.ID,
+			Title:   e.Title,
+			Updated: e.Updated.Format(time.RFC3339),
+			Content: atomContent{Type: "text", Text: e.Text},
+		})
+	}
+	feed.Updated = updated.Format(time.RFC3339)
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	_, _ = w.Write([]byte(xml.Header))
+	if err := xml.NewEncoder(w).Encode(feed); err != nil {
+		log.Printf("Feed: %v", err)
+	}
+}
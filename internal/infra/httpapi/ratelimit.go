@@ -0,0 +1,67 @@
+package httpapi
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter counts the requests of each client, e.g. per IP address.
+type Limiter interface {
+	// Allow takes one request from key's allowance. When it is used up,
+	// Allow returns false and how long until the next request is allowed.
+	Allow(ctx context.Context, key string) (bool, time.Duration)
+}
+
+// rateLimiter is a token bucket per client: each one holds up to limit
+// requests and refills at limit per minute, so short bursts are fine but a
+// client cannot keep the database busy.
+type rateLimiter struct {
+	limit float64
+	mu    sync.Mutex
+	swept time.Time
+	byKey map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	return &rateLimiter{limit: float64(perMinute), byKey: make(map[string]*bucket)}
+}
+
+func (l *rateLimiter) Allow(_ context.Context, key string) (bool, time.Duration) {
+	now := time.Now()
+	rate := l.limit / time.Minute.Seconds()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Buckets idle for a minute are full again and can be dropped
+	if now.Sub(l.swept) > time.Minute {
+		for k, b := range l.byKey {
+			if now.Sub(b.last) > time.Minute {
+				delete(l.byKey, k)
+			}
+		}
+		l.swept = now
+	}
+
+	b, ok := l.byKey[key]
+	if !ok {
+		b = &bucket{tokens: l.limit, last: now}
+		l.byKey[key] = b
+	}
+	b.tokens = math.Min(l.limit, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
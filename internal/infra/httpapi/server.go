@@ -0,0 +1,956 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+)
+
+// UserDataDeleter permanently removes everything stored about a user.
+type UserDataDeleter interface {
+	Delete(ctx context.Context, userID string) error
+}
+
+// MemberProfiles reads and corrects member profiles.
+type MemberProfiles interface {
+	Get(ctx context.Context, userID string) (*usecase.MemberProfile, error)
+	Chart(ctx context.Context, userID string) ([]byte, error)
+	Update(ctx context.Context, userID string, update usecase.MemberUpdate) (*domain.Report, error)
+}
+
+// ReportEvents reads a member's report history and undoes or resets it.
+type ReportEvents interface {
+	History(ctx context.Context, userID string) ([]*domain.ReportEvent, error)
+	Undo(ctx context.Context, userID, actor string) (*domain.Report, error)
+	ResetStreak(ctx context.Context, userID, actor string) (*domain.Report, error)
+}
+
+// ReportLister pages through all reports.
+type ReportLister interface {
+	Execute(ctx context.Context, opts usecase.ReportListOptions) (*usecase.ReportPage, error)
+}
+
+// MemberImporter pre-registers members from a CSV file.
+type MemberImporter interface {
+	Import(ctx context.Context, r io.Reader) (*usecase.ImportResult, error)
+}
+
+// maxImportSize limits the CSV body of POST /api/import.
+const maxImportSize = 5 << 20
+
+// Authenticator logs admins in and checks the session tokens it issued.
+type Authenticator interface {
+	Login(ctx context.Context, username, password string) (token string, expiresAt time.Time, err error)
+	// LoginEmail logs in an email address verified by an OAuth provider.
+	LoginEmail(ctx context.Context, email string) (token string, expiresAt time.Time, err error)
+	Verify(token string) (username, role string, err error)
+}
+
+// OAuthProvider signs admins in with an external account, e.g. Google.
+type OAuthProvider interface {
+	AuthCodeURL(state string) string
+	Email(ctx context.Context, code string) (string, error)
+}
+
+// APIKeys checks API keys for scripts and integrations.
+type APIKeys interface {
+	// Role returns the role a key grants, or "" for unknown and revoked keys.
+	Role(ctx context.Context, key string) (string, error)
+}
+
+// Outbox reads the messages the bot sent and their delivery status.
+type Outbox interface {
+	GetOutbox(ctx context.Context, filter domain.OutboxFilter) ([]*domain.OutboxMessage, error)
+}
+
+// Page size of GET /api/outbox.
+const (
+	defaultOutboxLimit = 50
+	maxOutboxLimit     = 200
+)
+
+// Snapshots reads the standings stored at the end of each day.
+type Snapshots interface {
+	GetSnapshot(ctx context.Context, day string) ([]*domain.SnapshotEntry, error)
+}
+
+// FinalReports renders the final report of the challenge.
+type FinalReports interface {
+	PDF(ctx context.Context, now time.Time) (*usecase.FinalReport, []byte, error)
+}
+
+// Spreadsheets renders the spreadsheet export of the challenge.
+type Spreadsheets interface {
+	XLSX(ctx context.Context, now time.Time) (*usecase.Spreadsheet, []byte, error)
+}
+
+// Identities links accounts on other platforms, such as a Telegram bridge
+// or Strava, to members.
+type Identities interface {
+	// RequestCode returns the code the person sends as "#link <code>" from
+	// WhatsApp.
+	RequestCode(ctx context.Context, platform, externalID string) (string, time.Time, error)
+	// Resolve returns the member an account is linked to, or "".
+	Resolve(ctx context.Context, platform, externalID string) (string, error)
+	// MemberName returns the name the member reports under, or fallback
+	// when they have none yet.
+	MemberName(ctx context.Context, userID, fallback string) (string, error)
+}
+
+// Commands runs a chat command as a member, e.g. a #lapor a bridge forwards.
+type Commands interface {
+	Execute(ctx context.Context, userID, name, message string) (string, error)
+}
+
+// Session is the WhatsApp session behind the bot.
+type Session interface {
+	// Ready returns why the bot cannot send, or nil.
+	Ready() error
+	// Repair starts pairing again after the device was unlinked and returns
+	// a pairing code for phone, or a QR code when phone is empty.
+	Repair(ctx context.Context, phone string) (string, error)
+}
+
+// repairTimeout bounds how long POST /api/session/pair waits for WhatsApp.
+const repairTimeout = 30 * time.Second
+
+const oauthStateCookie = "oauth_state"
+
+// Server is the admin HTTP API. Every request except the login, /readyz,
+// /feed.xml and the calendars must carry "Authorization: Bearer <token>",
+// where the token is ADMIN_TOKEN, a session token from POST /api/login or
+// the OAuth callback, or an API key. Sessions with the viewer role and
+// read-scoped keys may only read.
+type Server struct {
+	token      string
+	deleter    UserDataDeleter
+	profiles   MemberProfiles
+	events     ReportEvents
+	reports    ReportLister
+	importer   MemberImporter
+	auth       Authenticator
+	oauth      OAuthProvider
+	apiKeys    APIKeys
+	session    Session
+	outbox     Outbox
+	snapshots  Snapshots
+	identities Identities
+	commands   Commands
+	feed       Feed
+	feedURL    string
+	feedTitle  string
+	calendars  Calendars
+	final      FinalReports
+	sheets     Spreadsheets
+	hasher     *phone.Hasher
+	perIP      Limiter
+	perToken   Limiter
+	srv        *http.Server
+}
+
+func NewServer(addr, token string, deleter UserDataDeleter) *Server {
+	s := &Server{token: token, deleter: deleter}
+	s.srv = &http.Server{
+		Addr:              addr,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// SetProfiles enables the member profile endpoints.
+func (s *Server) SetProfiles(profiles MemberProfiles) {
+	s.profiles = profiles
+}
+
+// SetUserIDHasher lets the /api/users/{id} endpoints take a member's phone
+// number when user IDs are hashed.
+func (s *Server) SetUserIDHasher(hasher *phone.Hasher) {
+	s.hasher = hasher
+}
+
+// userID returns the member in the request path as a user ID.
+func (s *Server) userID(r *http.Request) string {
+	return s.hasher.UserID(r.PathValue("id"))
+}
+
+// SetReportEvents enables the report history, undo and streak reset
+// endpoints.
+func (s *Server) SetReportEvents(events ReportEvents) {
+	s.events = events
+}
+
+// SetReports enables GET /api/reports.
+func (s *Server) SetReports(reports ReportLister) {
+	s.reports = reports
+}
+
+// SetImporter enables POST /api/import.
+func (s *Server) SetImporter(importer MemberImporter) {
+	s.importer = importer
+}
+
+// SetAuthenticator enables POST /api/login for local admin accounts.
+func (s *Server) SetAuthenticator(auth Authenticator) {
+	s.auth = auth
+}
+
+// SetOAuth enables login with provider under /api/oauth/google. It needs an
+// Authenticator to issue the session tokens.
+func (s *Server) SetOAuth(provider OAuthProvider) {
+	s.oauth = provider
+}
+
+// SetAPIKeys lets API keys authorize requests.
+func (s *Server) SetAPIKeys(keys APIKeys) {
+	s.apiKeys = keys
+}
+
+// SetSession enables GET /readyz, which needs no token so load balancers
+// and uptime checks can use it, and POST /api/session/pair.
+func (s *Server) SetSession(session Session) {
+	s.session = session
+}
+
+// SetOutbox enables GET /api/outbox.
+func (s *Server) SetOutbox(outbox Outbox) {
+	s.outbox = outbox
+}
+
+// SetSnapshots enables GET /api/leaderboard/{date}.
+func (s *Server) SetSnapshots(snapshots Snapshots) {
+	s.snapshots = snapshots
+}
+
+// SetIdentities enables the /api/identities endpoints, through which a
+// bridge or integration links its accounts and forwards their commands.
+func (s *Server) SetIdentities(identities Identities, commands Commands) {
+	s.identities = identities
+	s.commands = commands
+}
+
+// SetFeed enables the public Atom feed at GET /feed.xml. url is where
+// readers fetch it, and identifies the feed and its entries.
+func (s *Server) SetFeed(feed Feed, url, title string) {
+	s.feed = feed
+	s.feedURL = url
+	s.feedTitle = title
+}
+
+// SetCalendars enables GET /calendar/{token}.ics, where members subscribe
+// to their report history. The token in the URL is the only credential.
+func (s *Server) SetCalendars(calendars Calendars) {
+	s.calendars = calendars
+}
+
+// SetFinalReports enables GET /api/final-report.pdf.
+func (s *Server) SetFinalReports(final FinalReports) {
+	s.final = final
+}
+
+// SetSpreadsheets enables GET /api/export.xlsx.
+func (s *Server) SetSpreadsheets(sheets Spreadsheets) {
+	s.sheets = sheets
+}
+
+// SetRateLimits limits requests per minute from one IP address and with one
+// token or API key. 0 turns a limit off. Over the limit the API answers 429.
+func (s *Server) SetRateLimits(perIP, perToken int) {
+	s.perIP, s.perToken = nil, nil
+	if perIP > 0 {
+		s.perIP = newRateLimiter(perIP)
+	}
+	if perToken > 0 {
+		s.perToken = newRateLimiter(perToken)
+	}
+}
+
+// SetLimiters replaces the in-memory limits of SetRateLimits, e.g. with
+// limits counted in Redis that every instance shares. A nil limiter turns
+// that limit off.
+func (s *Server) SetLimiters(perIP, perToken Limiter) {
+	s.perIP, s.perToken = perIP, perToken
+}
+
+// Handler returns the routes of the admin API.
+func (s *Server) Handler() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("DELETE /api/users/{id}", s.handleDeleteUser)
+	if s.profiles != nil {
+		api.HandleFunc("GET /api/users/{id}", s.handleGetProfile)
+		api.HandleFunc("GET /api/users/{id}/chart.png", s.handleGetChart)
+		api.HandleFunc("PATCH /api/users/{id}", s.handleUpdateProfile)
+	}
+	if s.events != nil {
+		api.HandleFunc("GET /api/users/{id}/events", s.handleGetEvents)
+		api.HandleFunc("POST /api/users/{id}/undo", s.handleUndoReport)
+		api.HandleFunc("POST /api/users/{id}/reset-streak", s.handleResetStreak)
+	}
+	if s.reports != nil {
+		api.HandleFunc("GET /api/reports", s.handleListReports)
+	}
+	if s.importer != nil {
+		api.HandleFunc("POST /api/import", s.handleImport)
+	}
+	if s.session != nil {
+		api.HandleFunc("POST /api/session/pair", s.handlePair)
+	}
+	if s.outbox != nil {
+		api.HandleFunc("GET /api/outbox", s.handleOutbox)
+	}
+	if s.snapshots != nil {
+		api.HandleFunc("GET /api/leaderboard/{date}", s.handleLeaderboardOn)
+	}
+	if s.final != nil {
+		api.HandleFunc("GET /api/final-report.pdf", s.handleFinalReport)
+	}
+	if s.sheets != nil {
+		api.HandleFunc("GET /api/export.xlsx", s.handleExportXLSX)
+	}
+	if s.identities != nil {
+		api.HandleFunc("POST /api/identities/{platform}/{id}/code", s.handleLinkCode)
+		api.HandleFunc("GET /api/identities/{platform}/{id}", s.handleGetIdentity)
+		api.HandleFunc("POST /api/identities/{platform}/{id}/messages", s.handleIdentityMessage)
+	}
+
+	mux := http.NewServeMux()
+	if s.session != nil {
+		mux.HandleFunc("GET /readyz", s.handleReady)
+	}
+	if s.feed != nil {
+		mux.HandleFunc("GET /feed.xml", s.handleFeed)
+	}
+	if s.calendars != nil {
+		mux.HandleFunc("GET /calendar/{file}", s.handleCalendar)
+	}
+	if s.auth != nil {
+		mux.HandleFunc("POST /api/login", s.handleLogin)
+		if s.oauth != nil {
+			mux.HandleFunc("GET /api/oauth/google/login", s.handleOAuthLogin)
+			mux.HandleFunc("GET /api/oauth/google/callback", s.handleOAuthCallback)
+		}
+	}
+	mux.Handle("/", s.requireToken(api))
+	return s.limitIP(mux)
+}
+
+// Start serves the API in the background.
+func (s *Server) Start() {
+	s.srv.Handler = s.Handler()
+	go func() {
+		log.Printf("Admin API listening on %s", s.srv.Addr)
+		if err := s.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Admin API stopped: %v", err)
+		}
+	}()
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+// limitIP applies the per-IP limit to every request, including logins.
+func (s *Server) limitIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.perIP != nil {
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
+			if ok, wait := s.perIP.Allow(r.Context(), ip); !ok {
+				tooManyRequests(w, wait)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func tooManyRequests(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "too many requests"})
+}
+
+func (s *Server) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		role := ""
+		if ok {
+			role = s.tokenRole(r.Context(), token)
+		}
+		if role == "" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		if s.perToken != nil {
+			if ok, wait := s.perToken.Allow(r.Context(), token); !ok {
+				tooManyRequests(w, wait)
+				return
+			}
+		}
+		if role != usecase.RoleAdmin && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "read-only account"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tokenRole returns the role of ADMIN_TOKEN, when set, of an unexpired
+// session token or of an active API key, and "" for any other token.
+func (s *Server) tokenRole(ctx context.Context, token string) string {
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+		return usecase.RoleAdmin
+	}
+	if s.auth != nil {
+		if _, role, err := s.auth.Verify(token); err == nil {
+			return role
+		}
+	}
+	if s.apiKeys != nil {
+		role, err := s.apiKeys.Role(ctx, token)
+		if err != nil {
+			log.Printf("Admin API: failed to check API key: %v", err)
+		}
+		return role
+	}
+	return ""
+}
+
+// handleLogin exchanges a username and password for a session token.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+		return
+	}
+
+	token, expiresAt, err := s.auth.Login(r.Context(), body.Username, body.Password)
+	if errors.Is(err, usecase.ErrInvalidLogin) {
+		log.Printf("Admin API: failed login for %q", body.Username)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Admin API: login error for %q: %v", body.Username, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	log.Printf("Admin API: %s logged in", body.Username)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"token": token, "expires_at": expiresAt.UTC().Format(time.RFC3339)})
+}
+
+// handleOAuthLogin redirects the browser to the provider. The state is kept
+// in a short-lived cookie and checked in the callback.
+func (s *Server) handleOAuthLogin(w http.ResponseWriter, r *http.Request) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	state := hex.EncodeToString(b)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/api/oauth",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, s.oauth.AuthCodeURL(state), http.StatusFound)
+}
+
+// handleOAuthCallback turns the provider's code into a session token for
+// allowlisted email addresses.
+func (s *Server) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(oauthStateCookie)
+	state := r.URL.Query().Get("state")
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid OAuth state"})
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Path: "/api/oauth", MaxAge: -1})
+
+	email, err := s.oauth.Email(r.Context(), r.URL.Query().Get("code"))
+	if err != nil {
+		log.Printf("Admin API: OAuth login failed: %v", err)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "OAuth login failed"})
+		return
+	}
+
+	token, expiresAt, err := s.auth.LoginEmail(r.Context(), email)
+	if errors.Is(err, usecase.ErrInvalidLogin) {
+		log.Printf("Admin API: OAuth login refused for %s, not in allowlist", email)
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "email is not allowed"})
+		return
+	}
+	if err != nil {
+		log.Printf("Admin API: login error for %s: %v", email, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	log.Printf("Admin API: %s logged in with OAuth", email)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"token": token, "expires_at": expiresAt.UTC().Format(time.RFC3339)})
+}
+
+// handleDeleteUser is the admin equivalent of #hapusdata.
+func (s *Server) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID := s.userID(r)
+	if err := s.deleter.Delete(r.Context(), userID); err != nil {
+		log.Printf("Admin API: failed to delete user %s: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	log.Printf("Admin API: deleted all data of user %s", userID)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted", "user_id": userID})
+}
+
+// handleGetProfile returns streak, chart and calendar data, recent reports
+// and the avatar URL of a member.
+func (s *Server) handleGetProfile(w http.ResponseWriter, r *http.Request) {
+	userID := s.userID(r)
+	profile, err := s.profiles.Get(r.Context(), userID)
+	if err != nil {
+		log.Printf("Admin API: failed to get profile of %s: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if profile == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// handleGetChart serves the 30-day chart that #grafik sends.
+func (s *Server) handleGetChart(w http.ResponseWriter, r *http.Request) {
+	userID := s.userID(r)
+	img, err := s.profiles.Chart(r.Context(), userID)
+	if err != nil {
+		log.Printf("Admin API: failed to render chart of %s: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(img)
+}
+
+// handleFinalReport renders the final report as of now, so it can be
+// downloaded before the challenge ends too.
+func (s *Server) handleFinalReport(w http.ResponseWriter, r *http.Request) {
+	report, data, err := s.final.PDF(r.Context(), time.Now())
+	if err != nil {
+		log.Printf("Admin API: failed to render the final report: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": report.FileName()}))
+	_, _ = w.Write(data)
+}
+
+// handleExportXLSX renders the spreadsheet export as of now.
+func (s *Server) handleExportXLSX(w http.ResponseWriter, r *http.Request) {
+	sheet, data, err := s.sheets.XLSX(r.Context(), time.Now())
+	if err != nil {
+		log.Printf("Admin API: failed to render the spreadsheet export: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": sheet.FileName()}))
+	_, _ = w.Write(data)
+}
+
+// handleUpdateProfile lets an admin correct a member's name, streak or
+// total days, e.g. after a missed report was reported late.
+func (s *Server) handleUpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID := s.userID(r)
+
+	var update usecase.MemberUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+		return
+	}
+	if (update.Name != nil && strings.TrimSpace(*update.Name) == "") ||
+		(update.Streak != nil && *update.Streak < 0) ||
+		(update.ActivityCount != nil && *update.ActivityCount < 0) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name must not be empty and counts must not be negative"})
+		return
+	}
+
+	report, err := s.profiles.Update(r.Context(), userID, update)
+	if errors.Is(err, domain.ErrReportConflict) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "user was updated at the same time, try again"})
+		return
+	}
+	if err != nil {
+		log.Printf("Admin API: failed to update user %s: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if report == nil && (update.Name != nil || update.Streak != nil || update.ActivityCount != nil) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+
+	log.Printf("Admin API: updated user %s", userID)
+	if report == nil {
+		// Only left out of the ranking, before the first report
+		writeJSON(w, http.StatusOK, map[string]interface{}{"user_id": userID, "unranked": *update.Unranked})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleGetEvents returns every change to a member's report, oldest first.
+func (s *Server) handleGetEvents(w http.ResponseWriter, r *http.Request) {
+	userID := s.userID(r)
+	events, err := s.events.History(r.Context(), userID)
+	if err != nil {
+		log.Printf("Admin API: failed to get report events of %s: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if events == nil {
+		events = []*domain.ReportEvent{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user_id": userID, "events": events})
+}
+
+// handleUndoReport takes back a member's latest counted #lapor, e.g. one
+// sent by mistake, and returns the recomputed report.
+func (s *Server) handleUndoReport(w http.ResponseWriter, r *http.Request) {
+	userID := s.userID(r)
+	report, err := s.events.Undo(r.Context(), userID, "admin")
+	if errors.Is(err, usecase.ErrNothingToUndo) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Admin API: failed to undo report of %s: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	log.Printf("Admin API: undid latest report of user %s", userID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user_id": userID, "report": report})
+}
+
+// handleResetStreak sets a member's streak back to 0.
+func (s *Server) handleResetStreak(w http.ResponseWriter, r *http.Request) {
+	userID := s.userID(r)
+	report, err := s.events.ResetStreak(r.Context(), userID, "admin")
+	if err != nil {
+		log.Printf("Admin API: failed to reset streak of %s: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if report == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+
+	log.Printf("Admin API: reset streak of user %s", userID)
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleListReports serves one page of reports. Dates are YYYY-MM-DD in the
+// server's timezone and "until" is inclusive.
+func (s *Server) handleListReports(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := usecase.ReportListOptions{
+		Sort:     q.Get("sort"),
+		GroupJID: q.Get("group"),
+		Cursor:   q.Get("cursor"),
+	}
+
+	var err error
+	if v := q.Get("active"); v != "" {
+		if opts.ActiveOnly, err = strconv.ParseBool(v); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "active must be true or false"})
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if opts.Limit, err = strconv.Atoi(v); err != nil || opts.Limit < 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be a positive number"})
+			return
+		}
+	}
+	if v := q.Get("since"); v != "" {
+		if opts.Since, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "since must be YYYY-MM-DD"})
+			return
+		}
+	}
+	if v := q.Get("until"); v != "" {
+		until, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "until must be YYYY-MM-DD"})
+			return
+		}
+		opts.Until = until.AddDate(0, 0, 1)
+	}
+
+	page, err := s.reports.Execute(r.Context(), opts)
+	if errors.Is(err, usecase.ErrInvalidCursor) || errors.Is(err, usecase.ErrInvalidSort) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Admin API: failed to list reports: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// handleImport pre-registers members from a CSV body, like "bot import".
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	result, err := s.importer.Import(r.Context(), http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		log.Printf("Admin API: import failed: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error(), "result": result})
+		return
+	}
+
+	log.Printf("Admin API: imported %d members (%d skipped, %d invalid)", result.Imported, result.Skipped, len(result.Errors))
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleReady answers 503 while the bot cannot send, e.g. after the device
+// was unlinked or the number banned.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if err := s.session.Ready(); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable", "reason": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// handlePair starts pairing a new device. With {"phone": "628..."} it
+// returns a pairing code, otherwise a QR code to render.
+func (s *Server) handlePair(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Phone string `json:"phone"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), repairTimeout)
+	defer cancel()
+	code, err := s.session.Repair(ctx, body.Phone)
+	if err != nil {
+		log.Printf("Admin API: pairing failed: %v", err)
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+
+	log.Printf("Admin API: pairing started")
+	if body.Phone != "" {
+		writeJSON(w, http.StatusOK, map[string]string{"pair_code": code})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"qr": code})
+}
+
+// handleLinkCode starts linking an account on another platform. The
+// bridge shows the code to the person, who confirms from WhatsApp.
+func (s *Server) handleLinkCode(w http.ResponseWriter, r *http.Request) {
+	platform, externalID := r.PathValue("platform"), r.PathValue("id")
+	code, expiresAt, err := s.identities.RequestCode(r.Context(), platform, externalID)
+	if err != nil {
+		log.Printf("Admin API: failed to create link code for %s %s: %v", platform, externalID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"code": code, "expires_at": expiresAt.UTC().Format(time.RFC3339)})
+}
+
+// handleGetIdentity returns the member an account is linked to.
+func (s *Server) handleGetIdentity(w http.ResponseWriter, r *http.Request) {
+	platform, externalID := r.PathValue("platform"), r.PathValue("id")
+	userID, ok := s.resolveIdentity(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"platform": platform, "external_id": externalID, "user_id": userID})
+}
+
+// handleIdentityMessage runs a chat command as the member an account is
+// linked to and returns the bot's reply, so "#lapor" sent through a bridge
+// counts for the member's streak.
+func (s *Server) handleIdentityMessage(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name string `json:"name"`
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Text) == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "body must be {\"name\", \"text\"}"})
+		return
+	}
+	userID, ok := s.resolveIdentity(w, r)
+	if !ok {
+		return
+	}
+	if s.commands == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "commands are not available"})
+		return
+	}
+
+	// The name on the other platform only counts until the member has one
+	name, err := s.identities.MemberName(r.Context(), userID, body.Name)
+	if err != nil {
+		log.Printf("Admin API: failed to get the name of %s: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	reply, err := s.commands.Execute(r.Context(), userID, name, body.Text)
+	if err != nil {
+		log.Printf("Admin API: failed to run command for %s: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"user_id": userID, "reply": reply})
+}
+
+// resolveIdentity writes 404 for accounts that are not linked.
+func (s *Server) resolveIdentity(w http.ResponseWriter, r *http.Request) (string, bool) {
+	platform, externalID := r.PathValue("platform"), r.PathValue("id")
+	userID, err := s.identities.Resolve(r.Context(), platform, externalID)
+	if err != nil {
+		log.Printf("Admin API: failed to resolve %s %s: %v", platform, externalID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return "", false
+	}
+	if userID == "" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "account is not linked"})
+		return "", false
+	}
+	return userID, true
+}
+
+// outboxEntry leaves out the times a message has not got yet: a queued
+// reply that was never sent has no sent_at.
+type outboxEntry struct {
+	MessageID      string `json:"message_id"`
+	ChatID         string `json:"chat_id"`
+	Text           string `json:"text"`
+	QueuedAt       string `json:"queued_at,omitempty"`
+	SentAt         string `json:"sent_at,omitempty"`
+	DeliveredAt    string `json:"delivered_at,omitempty"`
+	ReadAt         string `json:"read_at,omitempty"`
+	DeliveredCount int    `json:"delivered_count"`
+	ReadCount      int    `json:"read_count"`
+}
+
+// handleOutbox lists sent messages, newest first, with how many recipients
+// got and read them. Query: chat (JID), since (YYYY-MM-DD) and limit.
+func (s *Server) handleOutbox(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := domain.OutboxFilter{ChatID: q.Get("chat"), Limit: defaultOutboxLimit}
+
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be a positive number"})
+			return
+		}
+		filter.Limit = min(filter.Limit, maxOutboxLimit)
+	}
+	if v := q.Get("since"); v != "" {
+		if filter.Since, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "since must be YYYY-MM-DD"})
+			return
+		}
+	}
+
+	messages, err := s.outbox.GetOutbox(r.Context(), filter)
+	if err != nil {
+		log.Printf("Admin API: failed to list outbox: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	entries := make([]outboxEntry, 0, len(messages))
+	for _, m := range messages {
+		entry := outboxEntry{
+			MessageID:      m.MessageID,
+			ChatID:         m.ChatID,
+			Text:           m.Text,
+			DeliveredCount: m.DeliveredCount,
+			ReadCount:      m.ReadCount,
+		}
+		if !m.QueuedAt.IsZero() {
+			entry.QueuedAt = m.QueuedAt.UTC().Format(time.RFC3339)
+		}
+		if !m.SentAt.IsZero() {
+			entry.SentAt = m.SentAt.UTC().Format(time.RFC3339)
+		}
+		if !m.DeliveredAt.IsZero() {
+			entry.DeliveredAt = m.DeliveredAt.UTC().Format(time.RFC3339)
+		}
+		if !m.ReadAt.IsZero() {
+			entry.ReadAt = m.ReadAt.UTC().Format(time.RFC3339)
+		}
+		entries = append(entries, entry)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"messages": entries})
+}
+
+// handleLeaderboardOn returns the standings at the end of a past day, by
+// rank.
+func (s *Server) handleLeaderboardOn(w http.ResponseWriter, r *http.Request) {
+	day, err := time.ParseInLocation("2006-01-02", r.PathValue("date"), time.Local)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "date must be YYYY-MM-DD"})
+		return
+	}
+	date := day.Format("2006-01-02")
+
+	entries, err := s.snapshots.GetSnapshot(r.Context(), date)
+	if err != nil {
+		log.Printf("Admin API: failed to get leaderboard snapshot: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if len(entries) == 0 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no leaderboard snapshot for " + date})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"date": date, "members": entries})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
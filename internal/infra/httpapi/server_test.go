@@ -0,0 +1,774 @@
+package httpapi_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/httpapi"
+)
+
+type mockDeleter struct {
+	deleted []string
+}
+
+func (m *mockDeleter) Delete(ctx context.Context, userID string) error {
+	m.deleted = append(m.deleted, userID)
+	return nil
+}
+
+func TestDeleteUser_RequiresToken(t *testing.T) {
+	deleter := &mockDeleter{}
+	handler := httpapi.NewServer(":0", "secret", deleter).Handler()
+
+	for _, auth := range []string{"", "Bearer wrong", "secret"} {
+		req := httptest.NewRequest(http.MethodDelete, "/api/users/628123", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization '%s': expected 401, got %d", auth, rec.Code)
+		}
+	}
+	if len(deleter.deleted) != 0 {
+		t.Errorf("Nothing should be deleted without a valid token, got %v", deleter.deleted)
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	deleter := &mockDeleter{}
+	handler := httpapi.NewServer(":0", "secret", deleter).Handler()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/users/628123", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(deleter.deleted) != 1 || deleter.deleted[0] != "628123" {
+		t.Errorf("Expected user 628123 deleted, got %v", deleter.deleted)
+	}
+}
+
+// mockProfiles serves a single known member
+type mockProfiles struct {
+	report *domain.Report
+}
+
+func (m *mockProfiles) Get(ctx context.Context, userID string) (*usecase.MemberProfile, error) {
+	if userID != m.report.UserID {
+		return nil, nil
+	}
+	return &usecase.MemberProfile{UserID: m.report.UserID, Name: m.report.Name, Streak: m.report.Streak}, nil
+}
+
+func (m *mockProfiles) Chart(ctx context.Context, userID string) ([]byte, error) {
+	return []byte("\x89PNG"), nil
+}
+
+func (m *mockProfiles) Update(ctx context.Context, userID string, update usecase.MemberUpdate) (*domain.Report, error) {
+	if userID != m.report.UserID {
+		return nil, nil
+	}
+	if update.Streak != nil {
+		m.report.Streak = *update.Streak
+	}
+	return m.report, nil
+}
+
+func TestMemberProfile(t *testing.T) {
+	profiles := &mockProfiles{report: &domain.Report{UserID: "628123", Name: "Alice", Streak: 4}}
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	server.SetProfiles(profiles)
+	handler := server.Handler()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/api/users/628123", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"streak":4`) {
+		t.Errorf("Expected profile, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/api/users/999", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown user, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/users/628123/chart.png", ""); rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("Expected PNG chart, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	if rec := do(http.MethodPatch, "/api/users/628123", `{"streak": -1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for negative streak, got %d", rec.Code)
+	}
+	rec = do(http.MethodPatch, "/api/users/628123", `{"streak": 7}`)
+	if rec.Code != http.StatusOK || profiles.report.Streak != 7 {
+		t.Errorf("Expected streak updated to 7, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// mockReportEvents has one member with a single counted report
+type mockReportEvents struct {
+	undone bool
+}
+
+func (m *mockReportEvents) History(ctx context.Context, userID string) ([]*domain.ReportEvent, error) {
+	return []*domain.ReportEvent{{ID: 1, UserID: userID, Type: domain.ReportSubmitted, Actor: userID}}, nil
+}
+
+func (m *mockReportEvents) Undo(ctx context.Context, userID, actor string) (*domain.Report, error) {
+	if m.undone {
+		return nil, usecase.ErrNothingToUndo
+	}
+	m.undone = true
+	return nil, nil
+}
+
+func (m *mockReportEvents) ResetStreak(ctx context.Context, userID, actor string) (*domain.Report, error) {
+	return &domain.Report{UserID: userID, ActivityCount: 5}, nil
+}
+
+func TestReportEvents(t *testing.T) {
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	server.SetReportEvents(&mockReportEvents{})
+	handler := server.Handler()
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/api/users/628123/events")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"type":"report_submitted"`) {
+		t.Errorf("Expected the event history, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/users/628123/undo"); rec.Code != http.StatusOK {
+		t.Errorf("Expected the first undo to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/users/628123/undo"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 with nothing left to undo, got %d", rec.Code)
+	}
+	rec = do(http.MethodPost, "/api/users/628123/reset-streak")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"streak":0`) {
+		t.Errorf("Expected the reset report, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+type mockAuthenticator struct{}
+
+func (mockAuthenticator) Login(ctx context.Context, username, password string) (string, time.Time, error) {
+	if username != "faris" || password != "rahasia123" {
+		return "", time.Time{}, usecase.ErrInvalidLogin
+	}
+	return "session-token", time.Now().Add(time.Hour), nil
+}
+
+func (mockAuthenticator) LoginEmail(ctx context.Context, email string) (string, time.Time, error) {
+	if email != "ani@gmail.com" {
+		return "", time.Time{}, usecase.ErrInvalidLogin
+	}
+	return "viewer-token", time.Now().Add(time.Hour), nil
+}
+
+func (mockAuthenticator) Verify(token string) (string, string, error) {
+	switch token {
+	case "session-token":
+		return "faris", usecase.RoleAdmin, nil
+	case "viewer-token":
+		return "ani@gmail.com", usecase.RoleViewer, nil
+	}
+	return "", "", errors.New("invalid token")
+}
+
+func TestLogin_SessionTokenProtectsEndpoints(t *testing.T) {
+	deleter := &mockDeleter{}
+	// No ADMIN_TOKEN: only session tokens are accepted
+	server := httpapi.NewServer(":0", "", deleter)
+	server.SetAuthenticator(mockAuthenticator{})
+	handler := server.Handler()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(`{"username":"faris","password":"salah"}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for wrong password, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(`{"username":"faris","password":"rahasia123"}`))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"token":"session-token"`) {
+		t.Fatalf("Expected session token, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	for _, auth := range []string{"", "Bearer ", "Bearer forged"} {
+		req := httptest.NewRequest(http.MethodDelete, "/api/users/628123", nil)
+		req.Header.Set("Authorization", auth)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization '%s': expected 401, got %d", auth, rec.Code)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/users/628123", nil)
+	req.Header.Set("Authorization", "Bearer session-token")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || len(deleter.deleted) != 1 {
+		t.Errorf("Expected delete with session token, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+type mockOAuth struct{}
+
+func (mockOAuth) AuthCodeURL(state string) string {
+	return "https://accounts.example.com/auth?state=" + state
+}
+
+func (mockOAuth) Email(ctx context.Context, code string) (string, error) {
+	if code == "" {
+		return "", errors.New("missing code")
+	}
+	return code + "@gmail.com", nil
+}
+
+func TestOAuthLogin_AllowlistAndViewerRole(t *testing.T) {
+	deleter := &mockDeleter{}
+	server := httpapi.NewServer(":0", "", deleter)
+	server.SetProfiles(&mockProfiles{report: &domain.Report{UserID: "628123", Name: "Alice"}})
+	server.SetAuthenticator(mockAuthenticator{})
+	server.SetOAuth(mockOAuth{})
+	handler := server.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/oauth/google/login", nil))
+	if rec.Code != http.StatusFound || len(rec.Result().Cookies()) != 1 {
+		t.Fatalf("Expected redirect with state cookie, got %d", rec.Code)
+	}
+	state := rec.Result().Cookies()[0]
+	if !strings.HasSuffix(rec.Header().Get("Location"), "state="+state.Value) {
+		t.Errorf("Expected state in redirect, got %s", rec.Header().Get("Location"))
+	}
+
+	callback := func(code, queryState string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/oauth/google/callback?code="+code+"&state="+queryState, nil)
+		req.AddCookie(state)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := callback("ani", "forged"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for wrong state, got %d", rec.Code)
+	}
+	if rec := callback("budi", state.Value); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for email not in allowlist, got %d", rec.Code)
+	}
+	rec = callback("ani", state.Value)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"token":"viewer-token"`) {
+		t.Fatalf("Expected session token, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Viewers may read but not change anything
+	do := func(method string) int {
+		req := httptest.NewRequest(method, "/api/users/628123", nil)
+		req.Header.Set("Authorization", "Bearer viewer-token")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := do(http.MethodGet); code != http.StatusOK {
+		t.Errorf("Expected viewer to read profile, got %d", code)
+	}
+	if code := do(http.MethodDelete); code != http.StatusForbidden || len(deleter.deleted) != 0 {
+		t.Errorf("Expected 403 for viewer delete, got %d (deleted %v)", code, deleter.deleted)
+	}
+}
+
+type mockAPIKeys map[string]string
+
+func (m mockAPIKeys) Role(ctx context.Context, key string) (string, error) {
+	return m[key], nil
+}
+
+func TestAPIKeys_ScopeLimitsMethods(t *testing.T) {
+	deleter := &mockDeleter{}
+	server := httpapi.NewServer(":0", "", deleter)
+	server.SetProfiles(&mockProfiles{report: &domain.Report{UserID: "628123", Name: "Alice"}})
+	server.SetAPIKeys(mockAPIKeys{"lb_read": usecase.RoleViewer, "lb_admin": usecase.RoleAdmin})
+	handler := server.Handler()
+
+	do := func(method, key string) int {
+		req := httptest.NewRequest(method, "/api/users/628123", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := do(http.MethodGet, "lb_unknown"); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for unknown key, got %d", code)
+	}
+	if code := do(http.MethodGet, "lb_read"); code != http.StatusOK {
+		t.Errorf("Expected read key to read, got %d", code)
+	}
+	if code := do(http.MethodDelete, "lb_read"); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for read key delete, got %d", code)
+	}
+	if code := do(http.MethodDelete, "lb_admin"); code != http.StatusOK || len(deleter.deleted) != 1 {
+		t.Errorf("Expected admin key to delete, got %d", code)
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	server.SetAPIKeys(mockAPIKeys{"lb_admin": usecase.RoleAdmin})
+	server.SetRateLimits(3, 2)
+	handler := server.Handler()
+
+	do := func(ip, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/users/628123", nil)
+		req.RemoteAddr = ip + ":4321"
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Per token: the key is limited even when requests come from several IPs
+	for i, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		if rec := do(ip, "lb_admin"); rec.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected 200, got %d", i+1, rec.Code)
+		}
+	}
+	rec := do("10.0.0.3", "lb_admin")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 429 with Retry-After for the key, got %d", rec.Code)
+	}
+	if rec := do("10.0.0.3", "secret"); rec.Code != http.StatusOK {
+		t.Errorf("Expected other tokens to be unaffected, got %d", rec.Code)
+	}
+
+	// Per IP: counts every request, even unauthorized ones
+	for i := 0; i < 3; i++ {
+		do("10.0.0.9", "wrong")
+	}
+	if rec := do("10.0.0.9", "secret"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 for the IP, got %d", rec.Code)
+	}
+}
+
+type mockReportLister struct {
+	opts usecase.ReportListOptions
+}
+
+func (m *mockReportLister) Execute(ctx context.Context, opts usecase.ReportListOptions) (*usecase.ReportPage, error) {
+	m.opts = opts
+	if opts.Cursor == "bad" {
+		return nil, usecase.ErrInvalidCursor
+	}
+	return &usecase.ReportPage{Reports: []*domain.Report{{UserID: "628123", Name: "Alice"}}, NextCursor: "next"}, nil
+}
+
+func TestListReports(t *testing.T) {
+	lister := &mockReportLister{}
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	server.SetReports(lister)
+	handler := server.Handler()
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/reports"+query, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("?sort=streak&active=true&group=grup@g.us&since=2026-03-01&until=2026-03-31&limit=20&cursor=abc")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"next_cursor":"next"`) {
+		t.Fatalf("Expected a page, got %d: %s", rec.Code, rec.Body.String())
+	}
+	o := lister.opts
+	if o.Sort != "streak" || !o.ActiveOnly || o.GroupJID != "grup@g.us" || o.Limit != 20 || o.Cursor != "abc" {
+		t.Errorf("Unexpected options: %+v", o)
+	}
+	if o.Since.Format("2006-01-02") != "2026-03-01" || o.Until.Format("2006-01-02") != "2026-04-01" {
+		t.Errorf("Expected until to include the whole day, got %s to %s", o.Since, o.Until)
+	}
+
+	for _, query := range []string{"?limit=0", "?active=maybe", "?since=01-03-2026", "?cursor=bad"} {
+		if rec := get(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+type mockImporter struct {
+	body string
+}
+
+func (m *mockImporter) Import(ctx context.Context, r io.Reader) (*usecase.ImportResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return &usecase.ImportResult{}, err
+	}
+	m.body = string(data)
+	return &usecase.ImportResult{Imported: 1}, nil
+}
+
+func TestImportMembers(t *testing.T) {
+	importer := &mockImporter{}
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	server.SetImporter(importer)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/import", strings.NewReader("628123456,Alice\n"))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"imported":1`) {
+		t.Fatalf("Expected import result, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if importer.body != "628123456,Alice\n" {
+		t.Errorf("Expected CSV body passed to importer, got %q", importer.body)
+	}
+}
+
+type mockSession struct {
+	err    error
+	phones []string
+}
+
+func (m *mockSession) Ready() error { return m.err }
+
+func (m *mockSession) Repair(ctx context.Context, phone string) (string, error) {
+	if m.err == nil {
+		return "", errors.New("already logged in")
+	}
+	m.phones = append(m.phones, phone)
+	if phone != "" {
+		return "ABCD-EFGH", nil
+	}
+	return "2@qr", nil
+}
+
+func TestReadyz_NoTokenAndReflectsSession(t *testing.T) {
+	session := &mockSession{}
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	server.SetSession(session)
+	handler := server.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 while ready, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	session.err = errors.New("whatsapp session lost: logged out")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "logged out") {
+		t.Errorf("Expected 503 with the reason, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSessionPair(t *testing.T) {
+	session := &mockSession{}
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	server.SetSession(session)
+	handler := server.Handler()
+
+	pair := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/session/pair", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := pair(""); rec.Code != http.StatusConflict {
+		t.Fatalf("Expected 409 while logged in, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	session.err = errors.New("whatsapp session lost: logged out")
+	if rec := pair(`{"phone": "628123"}`); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"pair_code":"ABCD-EFGH"`) {
+		t.Errorf("Expected a pairing code, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := pair(""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"qr":"2@qr"`) {
+		t.Errorf("Expected a QR code, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(session.phones) != 2 || session.phones[0] != "628123" || session.phones[1] != "" {
+		t.Errorf("Expected both pairing attempts, got %v", session.phones)
+	}
+}
+
+type mockOutbox struct {
+	filter domain.OutboxFilter
+}
+
+func (m *mockOutbox) GetOutbox(ctx context.Context, filter domain.OutboxFilter) ([]*domain.OutboxMessage, error) {
+	m.filter = filter
+	sent := time.Date(2026, 3, 1, 19, 30, 0, 0, time.UTC)
+	return []*domain.OutboxMessage{
+		{MessageID: "MSG2", ChatID: "120363@g.us", Text: "Recap", SentAt: sent.Add(time.Hour)},
+		{MessageID: "MSG1", ChatID: "120363@g.us", Text: "Pengumuman", SentAt: sent, DeliveredAt: sent.Add(time.Minute), DeliveredCount: 12, ReadCount: 5},
+	}, nil
+}
+
+func TestOutbox(t *testing.T) {
+	outbox := &mockOutbox{}
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	server.SetOutbox(outbox)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/outbox?chat=120363@g.us&limit=500", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if outbox.filter.ChatID != "120363@g.us" || outbox.filter.Limit != 200 {
+		t.Errorf("Expected the chat filter and a capped limit, got %+v", outbox.filter)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `"delivered_at":"2026-03-01T19:31:00Z"`) || !strings.Contains(body, `"delivered_count":12`) {
+		t.Errorf("Expected delivery status, got %s", body)
+	}
+	if strings.Count(body, "delivered_at") != 1 || strings.Contains(body, "read_at") {
+		t.Errorf("Expected missing receipt times left out, got %s", body)
+	}
+}
+
+type mockSnapshots map[string][]*domain.SnapshotEntry
+
+func (m mockSnapshots) GetSnapshot(ctx context.Context, day string) ([]*domain.SnapshotEntry, error) {
+	return m[day], nil
+}
+
+func TestLeaderboardOnDate(t *testing.T) {
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	server.SetSnapshots(mockSnapshots{"2026-02-01": {
+		{Day: "2026-02-01", UserID: "628111", Name: "Budi", Rank: 1, Streak: 4, Total: 9, Reported: true},
+	}})
+	handler := server.Handler()
+
+	for _, tc := range []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/api/leaderboard/2026-02-01", http.StatusOK, `"name":"Budi","rank":1,"streak":4,"total":9,"reported":true`},
+		{"/api/leaderboard/2026-02-02", http.StatusNotFound, "no leaderboard snapshot for 2026-02-02"},
+		{"/api/leaderboard/kemarin", http.StatusBadRequest, "YYYY-MM-DD"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tc.code || !strings.Contains(rec.Body.String(), tc.body) {
+			t.Errorf("%s: expected %d with %s, got %d: %s", tc.path, tc.code, tc.body, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+type mockIdentities struct {
+	linked map[string]string
+	names  map[string]string
+}
+
+func (m *mockIdentities) RequestCode(ctx context.Context, platform, externalID string) (string, time.Time, error) {
+	return "K7QX2M", time.Date(2026, 3, 1, 19, 45, 0, 0, time.UTC), nil
+}
+
+func (m *mockIdentities) Resolve(ctx context.Context, platform, externalID string) (string, error) {
+	return m.linked[platform+"/"+externalID], nil
+}
+
+func (m *mockIdentities) MemberName(ctx context.Context, userID, fallback string) (string, error) {
+	if name, ok := m.names[userID]; ok {
+		return name, nil
+	}
+	return fallback, nil
+}
+
+type mockCommands struct {
+	userID, name, message string
+}
+
+func (m *mockCommands) Execute(ctx context.Context, userID, name, message string) (string, error) {
+	m.userID, m.name, m.message = userID, name, message
+	return "Mantap! Streak kamu 5 hari", nil
+}
+
+func TestIdentities(t *testing.T) {
+	commands := &mockCommands{}
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	identities := &mockIdentities{
+		linked: map[string]string{"telegram/12345": "628111", "strava/777": "628222"},
+		names:  map[string]string{"628111": "Alice"},
+	}
+	server.SetIdentities(identities, commands)
+	handler := server.Handler()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/identities/telegram/999/code", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"code":"K7QX2M"`) {
+		t.Errorf("Expected a link code, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := do(http.MethodGet, "/api/identities/telegram/999", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an account that is not linked, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/identities/telegram/12345", ""); !strings.Contains(rec.Body.String(), `"user_id":"628111"`) {
+		t.Errorf("Expected the linked member, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := do(http.MethodPost, "/api/identities/telegram/999/messages", `{"text": "#lapor"}`); rec.Code != http.StatusNotFound || commands.userID != "" {
+		t.Errorf("Expected no command run for an account that is not linked, got %d", rec.Code)
+	}
+	rec = do(http.MethodPost, "/api/identities/telegram/12345/messages", `{"name": "alice_tg", "text": "#lapor lari"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Streak kamu 5 hari") {
+		t.Errorf("Expected the bot's reply, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if commands.userID != "628111" || commands.name != "Alice" || commands.message != "#lapor lari" {
+		t.Errorf("Expected the command run as 628111 under their own name, got %+v", commands)
+	}
+
+	// A member without a report yet goes by the name the bridge sent
+	do(http.MethodPost, "/api/identities/strava/777/messages", `{"name": "Bob Runner", "text": "#lapor"}`)
+	if commands.userID != "628222" || commands.name != "Bob Runner" {
+		t.Errorf("Expected the bridge's name for a new member, got %+v", commands)
+	}
+}
+
+type mockFeed struct {
+	entries []usecase.FeedEntry
+}
+
+func (m *mockFeed) Entries(ctx context.Context, now time.Time) ([]usecase.FeedEntry, error) {
+	return m.entries, nil
+}
+
+func TestFeed_PublicAtom(t *testing.T) {
+	updated := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	feed := &mockFeed{entries: []usecase.FeedEntry{
+		{ID: "recap/2026-10-15", Title: "Recap Harian 15-10-2026", Text: "Yang lapor: Alice & <Bob>", Updated: updated},
+	}}
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	server.SetFeed(feed, "https://bot.example.com/feed.xml", "Lapor Bot")
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed.xml", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 without a token, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/atom+xml") {
+		t.Errorf("Expected an Atom content type, got %q", ct)
+	}
+
+	var parsed struct {
+		Title   string `xml:"title"`
+		Updated string `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &parsed); err != nil {
+		t.Fatalf("Expected valid XML: %v\n%s", err, rec.Body.String())
+	}
+	if parsed.Title != "Lapor Bot" || parsed.Updated != "2026-10-16T00:00:00Z" {
+		t.Errorf("Unexpected feed header: %+v", parsed)
+	}
+	if len(parsed.Entries) != 1 || parsed.Entries[0].ID != "https://bot.example.com/feed.xml#recap/2026-10-15" || parsed.Entries[0].Content != "Yang lapor: Alice & <Bob>" {
+		t.Errorf("Unexpected entries: %+v", parsed.Entries)
+	}
+}
+
+type mockCalendars struct {
+	calendar *usecase.Calendar
+}
+
+func (m *mockCalendars) Calendar(ctx context.Context, token string) (*usecase.Calendar, error) {
+	if token != "rahasia" {
+		return nil, usecase.ErrCalendarNotFound
+	}
+	return m.calendar, nil
+}
+
+func TestCalendar_ICal(t *testing.T) {
+	date := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	calendars := &mockCalendars{calendar: &usecase.Calendar{Name: "Alice", Days: []usecase.CalendarDay{{
+		UID:         "report-1@lapor-bot",
+		Date:        date,
+		At:          date.Add(7 * time.Hour),
+		Summary:     "Lari 🏃 (30 menit, 5 km)",
+		Description: strings.Repeat("Streak 1 hari; ", 8),
+	}}}}
+	server := httpapi.NewServer(":0", "secret", &mockDeleter{})
+	server.SetCalendars(calendars)
+	handler := server.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/calendar/salah.ics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown token, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/calendar/rahasia.ics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 without an API token, got %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Lapor Alice\r\n",
+		"DTSTART;VALUE=DATE:20261015\r\n",
+		"DTEND;VALUE=DATE:20261016\r\n",
+		`SUMMARY:Lari 🏃 (30 menit\, 5 km)`,
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in the calendar:\n%s", want, body)
+		}
+	}
+	for _, line := range strings.Split(body, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Expected lines folded at 75 octets, got %d: %q", len(line), line)
+		}
+	}
+	unfolded := strings.ReplaceAll(body, "\r\n ", "")
+	if !strings.Contains(unfolded, "DESCRIPTION:"+strings.Repeat(`Streak 1 hari\; `, 8)+"\r\n") {
+		t.Errorf("Expected the long description folded and escaped:\n%s", body)
+	}
+}
@@ -0,0 +1,147 @@
+// Package media picks the celebration GIFs, videos, and images the bot sends
+// on big milestones, from a local directory or a list of URLs.
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxSize caps a file of the library; WhatsApp rejects larger videos anyway.
+const maxSize = 16 << 20
+
+// mimeTypes are the files the library picks from. WhatsApp has no animated
+// GIF messages: an .mp4 is sent as a looping GIF, a .gif as a still image.
+var mimeTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".gif":  "image/gif",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+}
+
+var errEmpty = errors.New("no celebration media found")
+
+// Library is a directory, read again on every pick so files can be added
+// while the bot runs, or a fixed list of URLs.
+type Library struct {
+	dir  string
+	urls []string
+	http *http.Client
+}
+
+// New returns the library for CELEBRATION_MEDIA: a directory, or URLs
+// separated by commas.
+func New(source string) *Library {
+	l := &Library{http: &http.Client{Timeout: 30 * time.Second}}
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		for _, u := range strings.Split(source, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				l.urls = append(l.urls, u)
+			}
+		}
+		return l
+	}
+	l.dir = source
+	return l
+}
+
+// Random returns a random file of the library and its MIME type.
+func (l *Library) Random(ctx context.Context) ([]byte, string, error) {
+	if l.urls != nil {
+		return l.download(ctx, l.urls[rand.Intn(len(l.urls))])
+	}
+
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, "", err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || mimeTypes[strings.ToLower(filepath.Ext(e.Name()))] == "" {
+			continue
+		}
+		if info, err := e.Info(); err != nil || info.Size() > maxSize {
+			continue
+		}
+		files = append(files, e.Name())
+	}
+	if len(files) == 0 {
+		return nil, "", errEmpty
+	}
+
+	name := files[rand.Intn(len(files))]
+	data, err := os.ReadFile(filepath.Join(l.dir, name))
+	if err != nil {
+		return nil, "", err
+	}
+	return data, mimeTypes[strings.ToLower(filepath.Ext(name))], nil
+}
+
+// Load returns a single file or http(s) URL and its MIME type, such as the
+// media of a reward rule. The same types and size cap apply as for Random.
+func (l *Library) Load(ctx context.Context, source string) ([]byte, string, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return l.download(ctx, source)
+	}
+
+	mimeType := mimeTypes[strings.ToLower(filepath.Ext(source))]
+	if mimeType == "" {
+		return nil, "", fmt.Errorf("%s: unsupported file type", source)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.Size() > maxSize {
+		return nil, "", fmt.Errorf("%s: larger than %d MB", source, maxSize>>20)
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, mimeType, nil
+}
+
+func (l *Library) download(ctx context.Context, url string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := l.http.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+
+	// The extension is more reliable than what CDNs send as Content-Type
+	mimeType := mimeTypes[strings.ToLower(path.Ext(req.URL.Path))]
+	if mimeType == "" {
+		mimeType = strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	}
+	if mimeType != "video/mp4" && !strings.HasPrefix(mimeType, "image/") {
+		return nil, "", fmt.Errorf("download %s: unsupported type %q", url, mimeType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxSize {
+		return nil, "", fmt.Errorf("download %s: larger than %d MB", url, maxSize>>20)
+	}
+	return data, mimeType, nil
+}
@@ -0,0 +1,91 @@
+package media_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/media"
+)
+
+// =============================================================================
+// CELEBRATION MEDIA TESTS
+// =============================================================================
+
+func TestLibrary_Directory(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	if _, _, err := media.New(dir).Random(ctx); err == nil {
+		t.Error("Expected an error for a directory without media")
+	}
+
+	for name, data := range map[string]string{"party.mp4": "mp4", "notes.txt": "skip"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "more.gif"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		data, mimeType, err := media.New(dir).Random(ctx)
+		if err != nil || string(data) != "mp4" || mimeType != "video/mp4" {
+			t.Fatalf("Expected only party.mp4, got %q (%s), %v", data, mimeType, err)
+		}
+	}
+}
+
+func TestLibrary_URLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/confetti":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("png"))
+		case "/page.html":
+			w.Write([]byte("<html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	data, mimeType, err := media.New(server.URL + "/confetti").Random(ctx)
+	if err != nil || string(data) != "png" || mimeType != "image/png" {
+		t.Errorf("Expected the PNG by its content type, got %q (%s), %v", data, mimeType, err)
+	}
+	if _, _, err := media.New(server.URL + "/page.html").Random(ctx); err == nil {
+		t.Error("Expected an error for a page that is not media")
+	}
+	if _, _, err := media.New(server.URL + "/gone.mp4").Random(ctx); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestLibrary_LoadSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	sticker := filepath.Join(dir, "medal.webp")
+	if err := os.WriteFile(sticker, []byte("webp"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("skip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, mimeType, err := media.New("").Load(ctx, sticker)
+	if err != nil || string(data) != "webp" || mimeType != "image/webp" {
+		t.Errorf("Expected the sticker, got %q (%s), %v", data, mimeType, err)
+	}
+	if _, _, err := media.New("").Load(ctx, notes); err == nil {
+		t.Error("Expected an error for a file that is not media")
+	}
+	if _, _, err := media.New("").Load(ctx, filepath.Join(dir, "gone.mp4")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
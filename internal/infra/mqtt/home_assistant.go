@@ -0,0 +1,96 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// entity is the Home Assistant MQTT discovery config of one sensor, see
+// https://www.home-assistant.io/integrations/mqtt/#mqtt-discovery.
+type entity struct {
+	Name          string `json:"name"`
+	UniqueID      string `json:"unique_id"`
+	StateTopic    string `json:"state_topic"`
+	ValueTemplate string `json:"value_template"`
+	Unit          string `json:"unit_of_measurement,omitempty"`
+	Icon          string `json:"icon,omitempty"`
+	Device        device `json:"device"`
+}
+
+type device struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+}
+
+func (p *Publisher) PublishMemberState(ctx context.Context, key string, state []byte) error {
+	return p.publish(ctx, p.memberTopic(key), state)
+}
+
+// AddMemberEntities announces a "Lapor Bot <name>" device with a streak
+// sensor and a "reported today" binary sensor.
+func (p *Publisher) AddMemberEntities(ctx context.Context, key, name string) error {
+	id := p.nodeID(key)
+	dev := device{Identifiers: []string{id}, Name: "Lapor Bot " + name, Manufacturer: "lapor-bot"}
+	entities := map[string]entity{
+		p.configTopic("sensor", key, "streak"): {
+			Name:          "Streak",
+			UniqueID:      id + "_streak",
+			StateTopic:    p.memberTopic(key),
+			ValueTemplate: "{{ value_json.streak }}",
+			Unit:          "hari",
+			Icon:          "mdi:fire",
+			Device:        dev,
+		},
+		p.configTopic("binary_sensor", key, "today"): {
+			Name:          "Lapor hari ini",
+			UniqueID:      id + "_today",
+			StateTopic:    p.memberTopic(key),
+			ValueTemplate: "{{ 'ON' if value_json.today else 'OFF' }}",
+			Icon:          "mdi:run",
+			Device:        dev,
+		},
+	}
+	for topic, e := range entities {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if err := p.publish(ctx, topic, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveMemberEntities clears the retained configs, which makes Home
+// Assistant delete the entities.
+func (p *Publisher) RemoveMemberEntities(ctx context.Context, key string) error {
+	for _, topic := range []string{p.configTopic("sensor", key, "streak"), p.configTopic("binary_sensor", key, "today")} {
+		if err := p.publish(ctx, topic, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Publisher) memberTopic(key string) string {
+	return p.topic + "/members/" + key
+}
+
+func (p *Publisher) configTopic(component, key, object string) string {
+	return p.discovery + "/" + component + "/" + p.nodeID(key) + "/" + object + "/config"
+}
+
+// nodeID includes the topic so a member of two tenants gets two devices.
+// Discovery IDs only allow letters, digits, _ and -.
+func (p *Publisher) nodeID(key string) string {
+	id := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '_'
+	}, p.topic)
+	return id + "_" + key
+}
@@ -0,0 +1,118 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type ActivityRepository struct {
+	db    *sql.DB
+	reads *sql.DB
+}
+
+func NewActivityRepository(db *sql.DB) *ActivityRepository {
+	return &ActivityRepository{db: db, reads: db}
+}
+
+// SetReadReplica sends GetActivities, which backs the leaderboards, stats
+// and recaps, to a read replica outside of a transaction.
+func (r *ActivityRepository) SetReadReplica(db *sql.DB) {
+	r.reads = db
+}
+
+func (r *ActivityRepository) AddActivity(ctx context.Context, activity *domain.Activity) error {
+	query := `
+		INSERT INTO activity_logs (user_id, name, activity_type, duration_minutes, distance_km, message, reported_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	id, err := insertID(ctx, r.db, query, activity.UserID, activity.Name, activity.ActivityType, activity.DurationMinutes, activity.DistanceKm, activity.Message, activity.ReportedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	activity.ID = id
+	return nil
+}
+
+func (r *ActivityRepository) GetActivities(ctx context.Context, filter domain.ActivityFilter) ([]*domain.Activity, error) {
+	where, args := activityWhere(filter)
+	query := `SELECT id, user_id, name, activity_type, duration_minutes, distance_km, message, reported_at FROM activity_logs` + where
+	query += " ORDER BY reported_at ASC, id ASC"
+
+	rows, err := conn(ctx, r.reads).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activities []*domain.Activity
+	for rows.Next() {
+		var a domain.Activity
+		var reportedAt string
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Name, &a.ActivityType, &a.DurationMinutes, &a.DistanceKm, &a.Message, &reportedAt); err != nil {
+			return nil, err
+		}
+		a.ReportedAt, err = time.Parse(time.RFC3339, reportedAt)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, &a)
+	}
+	return activities, rows.Err()
+}
+
+func (r *ActivityRepository) DeleteActivities(ctx context.Context, filter domain.ActivityFilter) error {
+	where, args := activityWhere(filter)
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM activity_logs`+where, args...)
+	return err
+}
+
+// activityWhere builds the WHERE clause for a filter. Timestamps are stored
+// as UTC RFC3339 so string comparison matches time order.
+func activityWhere(filter domain.ActivityFilter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if filter.UserID != "" {
+		conds = append(conds, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.ActivityType != "" {
+		conds = append(conds, "activity_type = ?")
+		args = append(args, filter.ActivityType)
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, "reported_at >= ?")
+		args = append(args, filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		conds = append(conds, "reported_at < ?")
+		args = append(args, filter.Until.UTC().Format(time.RFC3339))
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (r *ActivityRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS activity_logs (
+			id bigint AUTO_INCREMENT PRIMARY KEY,
+			user_id varchar(255) NOT NULL,
+			name varchar(255) NOT NULL DEFAULT '',
+			activity_type varchar(64) NOT NULL DEFAULT 'lainnya',
+			duration_minutes integer NOT NULL DEFAULT 0,
+			distance_km double precision NOT NULL DEFAULT 0,
+			message text NOT NULL,
+			reported_at varchar(40) NOT NULL,
+			INDEX idx_activity_logs_reported_at (reported_at),
+			INDEX idx_activity_logs_user_id (user_id)
+		) DEFAULT CHARSET = utf8mb4
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
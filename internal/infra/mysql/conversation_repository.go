@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type ConversationRepository struct {
+	db *sql.DB
+}
+
+func NewConversationRepository(db *sql.DB) *ConversationRepository {
+	return &ConversationRepository{db: db}
+}
+
+func (r *ConversationRepository) GetConversation(ctx context.Context, userID string) (*domain.Conversation, error) {
+	query := `SELECT user_id, flow, step, data, expires_at FROM conversations WHERE user_id = ?`
+
+	var conv domain.Conversation
+	var data, expiresAt string
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(&conv.UserID, &conv.Flow, &conv.Step, &data, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(data), &conv.Data); err != nil {
+		return nil, err
+	}
+	conv.ExpiresAt, err = time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+func (r *ConversationRepository) SaveConversation(ctx context.Context, conv *domain.Conversation) error {
+	data, err := json.Marshal(conv.Data)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO conversations (user_id, flow, step, data, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			flow = VALUES(flow),
+			step = VALUES(step),
+			data = VALUES(data),
+			expires_at = VALUES(expires_at)
+	`
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, conv.UserID, conv.Flow, conv.Step, string(data), conv.ExpiresAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (r *ConversationRepository) DeleteConversation(ctx context.Context, userID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM conversations WHERE user_id = ?`, userID)
+	return err
+}
+
+func (r *ConversationRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS conversations (
+			user_id varchar(255) PRIMARY KEY,
+			flow varchar(64) NOT NULL,
+			step integer NOT NULL DEFAULT 0,
+			data json NOT NULL,
+			expires_at varchar(40) NOT NULL
+		) DEFAULT CHARSET = utf8mb4
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
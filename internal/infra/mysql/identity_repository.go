@@ -0,0 +1,120 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type IdentityRepository struct {
+	db *sql.DB
+}
+
+func NewIdentityRepository(db *sql.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+const identityColumns = `platform, external_id, user_id, code, code_expires_at, linked_at`
+
+func (r *IdentityRepository) GetIdentity(ctx context.Context, platform, externalID string) (*domain.LinkedIdentity, error) {
+	query := `SELECT ` + identityColumns + ` FROM linked_identities WHERE platform = ? AND external_id = ?`
+	return scanIdentity(conn(ctx, r.db).QueryRowContext(ctx, query, platform, externalID))
+}
+
+func (r *IdentityRepository) GetIdentityByCode(ctx context.Context, code string) (*domain.LinkedIdentity, error) {
+	if code == "" {
+		return nil, nil
+	}
+	query := `SELECT ` + identityColumns + ` FROM linked_identities WHERE code = ?`
+	return scanIdentity(conn(ctx, r.db).QueryRowContext(ctx, query, code))
+}
+
+func (r *IdentityRepository) ListIdentities(ctx context.Context, userID string) ([]*domain.LinkedIdentity, error) {
+	query := `SELECT ` + identityColumns + ` FROM linked_identities WHERE user_id = ? ORDER BY platform, external_id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []*domain.LinkedIdentity
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, rows.Err()
+}
+
+func scanIdentity(row rowScanner) (*domain.LinkedIdentity, error) {
+	var identity domain.LinkedIdentity
+	var codeExpiresAt, linkedAt string
+	err := row.Scan(&identity.Platform, &identity.ExternalID, &identity.UserID, &identity.Code, &codeExpiresAt, &linkedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if codeExpiresAt != "" {
+		if identity.CodeExpiresAt, err = time.Parse(time.RFC3339, codeExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+	if linkedAt != "" {
+		if identity.LinkedAt, err = time.Parse(time.RFC3339, linkedAt); err != nil {
+			return nil, err
+		}
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepository) SaveIdentity(ctx context.Context, identity *domain.LinkedIdentity) error {
+	query := `
+		INSERT INTO linked_identities (platform, external_id, user_id, code, code_expires_at, linked_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			user_id = VALUES(user_id),
+			code = VALUES(code),
+			code_expires_at = VALUES(code_expires_at),
+			linked_at = VALUES(linked_at)
+	`
+	codeExpiresAt, linkedAt := "", ""
+	if !identity.CodeExpiresAt.IsZero() {
+		codeExpiresAt = identity.CodeExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if !identity.LinkedAt.IsZero() {
+		linkedAt = identity.LinkedAt.UTC().Format(time.RFC3339)
+	}
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, identity.Platform, identity.ExternalID, identity.UserID,
+		identity.Code, codeExpiresAt, linkedAt)
+	return err
+}
+
+func (r *IdentityRepository) DeleteIdentities(ctx context.Context, userID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM linked_identities WHERE user_id = ?`, userID)
+	return err
+}
+
+func (r *IdentityRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS linked_identities (
+			platform varchar(64) NOT NULL,
+			external_id varchar(255) NOT NULL,
+			user_id varchar(255) NOT NULL DEFAULT '',
+			code varchar(64) NOT NULL DEFAULT '',
+			code_expires_at varchar(40) NOT NULL DEFAULT '',
+			linked_at varchar(40) NOT NULL DEFAULT '',
+			PRIMARY KEY (platform, external_id),
+			INDEX idx_linked_identities_user_id (user_id),
+			INDEX idx_linked_identities_code (code)
+		) DEFAULT CHARSET = utf8mb4
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,161 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type MessageArchiveRepository struct {
+	db *sql.DB
+}
+
+func NewMessageArchiveRepository(db *sql.DB) *MessageArchiveRepository {
+	return &MessageArchiveRepository{db: db}
+}
+
+func (r *MessageArchiveRepository) ArchiveMessage(ctx context.Context, msg *domain.ArchivedMessage) error {
+	query := `
+		INSERT INTO message_archive (message_id, chat_id, sender_id, sender_name, text, media_type, media_mime, media_size, sent_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE message_id = message_id
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, msg.MessageID, msg.ChatID, msg.SenderID, msg.SenderName, msg.Text, msg.MediaType, msg.MediaMime, msg.MediaSize, msg.SentAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		// Already archived
+		return err
+	}
+	msg.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *MessageArchiveRepository) GetMessages(ctx context.Context, filter domain.MessageFilter) ([]*domain.ArchivedMessage, error) {
+	where, args := messageWhere(filter)
+	query := `SELECT id, message_id, chat_id, sender_id, sender_name, text, media_type, media_mime, media_size, sent_at FROM message_archive` + where
+	query += " ORDER BY sent_at ASC, id ASC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	return r.queryMessages(ctx, query, args...)
+}
+
+func (r *MessageArchiveRepository) queryMessages(ctx context.Context, query string, args ...interface{}) ([]*domain.ArchivedMessage, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*domain.ArchivedMessage
+	for rows.Next() {
+		var m domain.ArchivedMessage
+		var sentAt string
+		if err := rows.Scan(&m.ID, &m.MessageID, &m.ChatID, &m.SenderID, &m.SenderName, &m.Text, &m.MediaType, &m.MediaMime, &m.MediaSize, &sentAt); err != nil {
+			return nil, err
+		}
+		m.SentAt, err = time.Parse(time.RFC3339, sentAt)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, &m)
+	}
+	return messages, rows.Err()
+}
+
+// SearchMessages matches every word case-insensitively, like the LIKE
+// fallback of the SQLite driver, whatever the collation of the table.
+func (r *MessageArchiveRepository) SearchMessages(ctx context.Context, keyword string, filter domain.MessageFilter) ([]*domain.ArchivedMessage, error) {
+	words := strings.Fields(keyword)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	where, args := messageWhere(filter)
+	var match []string
+	for _, w := range words {
+		match = append(match, `LOWER(text) LIKE ?`)
+		args = append(args, "%"+escapeLike(strings.ToLower(w))+"%")
+	}
+	if where == "" {
+		where = " WHERE " + strings.Join(match, " AND ")
+	} else {
+		where += " AND " + strings.Join(match, " AND ")
+	}
+
+	query := `SELECT id, message_id, chat_id, sender_id, sender_name, text, media_type, media_mime, media_size, sent_at FROM message_archive` + where
+	query += " ORDER BY sent_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	return r.queryMessages(ctx, query, args...)
+}
+
+// escapeLike escapes LIKE wildcards with a backslash, MySQL's default
+// escape character, so they match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *MessageArchiveRepository) DeleteMessages(ctx context.Context, filter domain.MessageFilter) error {
+	where, args := messageWhere(filter)
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM message_archive`+where, args...)
+	return err
+}
+
+// messageWhere builds the WHERE clause for a filter. Timestamps are stored
+// as UTC RFC3339 so string comparison matches time order.
+func messageWhere(filter domain.MessageFilter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if filter.ChatID != "" {
+		conds = append(conds, "chat_id = ?")
+		args = append(args, filter.ChatID)
+	}
+	if filter.SenderID != "" {
+		conds = append(conds, "sender_id = ?")
+		args = append(args, filter.SenderID)
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, "sent_at >= ?")
+		args = append(args, filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		conds = append(conds, "sent_at < ?")
+		args = append(args, filter.Until.UTC().Format(time.RFC3339))
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (r *MessageArchiveRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS message_archive (
+			id bigint AUTO_INCREMENT PRIMARY KEY,
+			message_id varchar(255) NOT NULL UNIQUE,
+			chat_id varchar(255) NOT NULL,
+			sender_id varchar(255) NOT NULL,
+			sender_name varchar(255) NOT NULL DEFAULT '',
+			text text NOT NULL,
+			media_type varchar(64) NOT NULL DEFAULT '',
+			media_mime varchar(255) NOT NULL DEFAULT '',
+			media_size bigint NOT NULL DEFAULT 0,
+			sent_at varchar(40) NOT NULL,
+			INDEX idx_message_archive_sent_at (sent_at),
+			INDEX idx_message_archive_sender_id (sender_id)
+		) DEFAULT CHARSET = utf8mb4
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type PromptRepository struct {
+	db *sql.DB
+}
+
+func NewPromptRepository(db *sql.DB) *PromptRepository {
+	return &PromptRepository{db: db}
+}
+
+func (r *PromptRepository) SavePrompt(ctx context.Context, prompt *domain.ReportPrompt) error {
+	query := `INSERT INTO report_prompts (message_id, chat_id, sent_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE message_id = message_id`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, prompt.MessageID, prompt.ChatID, prompt.SentAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (r *PromptRepository) GetPrompt(ctx context.Context, chatID, messageID string) (*domain.ReportPrompt, error) {
+	query := `SELECT message_id, chat_id, sent_at FROM report_prompts WHERE chat_id = ? AND message_id = ?`
+	var prompt domain.ReportPrompt
+	var sentAt string
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, chatID, messageID).Scan(&prompt.MessageID, &prompt.ChatID, &sentAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	prompt.SentAt, err = time.Parse(time.RFC3339, sentAt)
+	if err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+func (r *PromptRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS report_prompts (
+			message_id varchar(255) PRIMARY KEY,
+			chat_id varchar(255) NOT NULL,
+			sent_at varchar(40) NOT NULL
+		) DEFAULT CHARSET = utf8mb4
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
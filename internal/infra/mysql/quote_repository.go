@@ -0,0 +1,56 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type QuoteRepository struct {
+	db *sql.DB
+}
+
+func NewQuoteRepository(db *sql.DB) *QuoteRepository {
+	return &QuoteRepository{db: db}
+}
+
+func (r *QuoteRepository) GetQuoteUses(ctx context.Context) ([]*domain.QuoteUse, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT hash, day FROM quote_uses ORDER BY day`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var uses []*domain.QuoteUse
+	for rows.Next() {
+		var use domain.QuoteUse
+		if err := rows.Scan(&use.Hash, &use.Day); err != nil {
+			return nil, err
+		}
+		uses = append(uses, &use)
+	}
+	return uses, rows.Err()
+}
+
+func (r *QuoteRepository) SaveQuoteUse(ctx context.Context, use *domain.QuoteUse) error {
+	query := `INSERT INTO quote_uses (hash, day) VALUES (?, ?) ON DUPLICATE KEY UPDATE day = VALUES(day)`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, use.Hash, use.Day)
+	return err
+}
+
+func (r *QuoteRepository) DeleteQuoteUses(ctx context.Context) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM quote_uses`)
+	return err
+}
+
+func (r *QuoteRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS quote_uses (
+			hash varchar(64) PRIMARY KEY,
+			day varchar(10) NOT NULL
+		) DEFAULT CHARSET = utf8mb4
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
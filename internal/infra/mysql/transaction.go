@@ -0,0 +1,65 @@
+// Package mysql stores the bot's data in a MySQL or MariaDB database over
+// database/sql. The tables have the columns of the Postgres ones, times
+// included as RFC3339 text, in VARCHAR where they are keys or indexed since
+// MySQL can't index TEXT without a prefix length.
+package mysql
+
+import (
+	"context"
+	"database/sql"
+)
+
+type txKey struct{}
+
+// Transactor implements domain.Transactor. The transaction travels in the
+// context, and repositories built on the same *sql.DB pick it up through
+// conn.
+type Transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+func (t *Transactor) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier is what *sql.DB and *sql.Tx have in common.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn returns the transaction in ctx, or db outside of WithTx. MySQL takes
+// the ? placeholders of the SQLite repositories as they are.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// insertID runs an INSERT and returns the AUTO_INCREMENT id of the new row;
+// MySQL has no RETURNING.
+func insertID(ctx context.Context, db *sql.DB, query string, args ...interface{}) (int64, error) {
+	res, err := conn(ctx, db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
@@ -0,0 +1,65 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+
+// Google signs admins in with their Google account. Only the verified email
+// address is used; who may log in is decided by the allowlist.
+type Google struct {
+	config *oauth2.Config
+}
+
+func NewGoogle(clientID, clientSecret, redirectURL string) *Google {
+	return &Google{config: &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Endpoint:     google.Endpoint,
+		Scopes:       []string{"openid", "email"},
+	}}
+}
+
+// AuthCodeURL is the Google consent page to redirect the browser to.
+func (g *Google) AuthCodeURL(state string) string {
+	return g.config.AuthCodeURL(state)
+}
+
+// Email exchanges the code from the callback and returns the verified
+// email address of the account.
+func (g *Google) Email(ctx context.Context, code string) (string, error) {
+	token, err := g.config.Exchange(ctx, code)
+	if err != nil {
+		return "", fmt.Errorf("exchange code: %w", err)
+	}
+
+	resp, err := g.config.Client(ctx, token).Get(googleUserInfoURL)
+	if err != nil {
+		return "", fmt.Errorf("get user info: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("get user info: status %d", resp.StatusCode)
+	}
+
+	var info struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("decode user info: %w", err)
+	}
+	if info.Email == "" || !info.EmailVerified {
+		return "", errors.New("google account has no verified email")
+	}
+	return info.Email, nil
+}
@@ -0,0 +1,173 @@
+// Package pdf lays reports out as PDF documents.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/format"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/activity"
+)
+
+const (
+	pageWidth    = 210.0 // A4, mm
+	margin       = 15.0
+	contentWidth = pageWidth - 2*margin
+	lineHeight   = 6.0
+)
+
+// Renderer renders reports with the PDF core fonts, which cover Latin
+// text. Emoji are dropped and other characters the fonts lack show as ".".
+type Renderer struct{}
+
+func NewRenderer() *Renderer {
+	return &Renderer{}
+}
+
+// RenderFinalReport lays the report out on A4 pages: the totals, the
+// highlights and the standings, then a chart per member.
+func (r *Renderer) RenderFinalReport(report *usecase.FinalReport) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	text := func(s string) string { return tr(stripSymbols(s)) }
+
+	pdf.SetTitle(text(report.Title), false)
+	pdf.SetCreator("lapor-bot", false)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-margin + 2)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(0x71, 0x71, 0x7a)
+		pdf.CellFormat(0, 4, fmt.Sprintf("Halaman %d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(0x18, 0x18, 0x1b)
+	pdf.CellFormat(0, 10, text(report.Title), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.SetTextColor(0x52, 0x52, 0x5b)
+	pdf.CellFormat(0, lineHeight, text(formatDate(report.Since)+" - "+formatDate(report.Until)), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	heading := func(title string) {
+		pdf.Ln(3)
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.SetTextColor(0x18, 0x18, 0x1b)
+		pdf.CellFormat(0, 8, text(title), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+	}
+	row := func(label, value string) {
+		pdf.CellFormat(contentWidth*0.6, lineHeight, text(label), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(contentWidth*0.4, lineHeight, text(value), "", 1, "R", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+	}
+
+	heading("Ringkasan")
+	row("Total laporan", fmt.Sprintf("%d", report.Reports))
+	row("Member aktif", fmt.Sprintf("%d", report.Members))
+	row("Lapor setiap hari", fmt.Sprintf("%d member", report.Finished))
+	if report.Km > 0 {
+		row("Total jarak", activity.FormatDistance(report.Km))
+	}
+	if report.Minutes > 0 {
+		row("Total durasi", activity.FormatDuration(report.Minutes))
+	}
+
+	if len(report.Highlights) > 0 {
+		heading("Highlight")
+		for _, h := range report.Highlights {
+			row(h.Title+": "+h.Name, h.Value)
+		}
+	}
+
+	if len(report.Types) > 0 {
+		heading("Breakdown aktivitas")
+		for _, t := range report.Types {
+			row(t.Label, fmt.Sprintf("%d laporan", t.Count))
+		}
+	}
+
+	if len(report.Standings) > 0 {
+		heading("Klasemen akhir")
+		widths := []float64{14, contentWidth - 14 - 35 - 35, 35, 35}
+		header := []string{"#", "Nama", "Total", "Streak"}
+		align := []string{"C", "L", "R", "R"}
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.SetFillColor(0xf4, 0xf4, 0xf5)
+		for i, h := range header {
+			pdf.CellFormat(widths[i], 7, h, "B", 0, align[i], true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 10)
+		for _, m := range report.Standings {
+			streak := "-"
+			if m.Streak > 0 {
+				streak = fmt.Sprintf("%d hari", m.Streak)
+			}
+			cells := []string{fmt.Sprintf("%d", m.Rank), m.Name, fmt.Sprintf("%d hari", m.Total), streak}
+			for i, c := range cells {
+				pdf.CellFormat(widths[i], 7, text(c), "B", 0, align[i], false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+	}
+
+	if len(report.Charts) > 0 {
+		pdf.AddPage()
+		heading("Grafik per member")
+		pdf.SetTextColor(0x52, 0x52, 0x5b)
+		pdf.MultiCell(0, 5, text("Batang hijau adalah hari lapor, makin tinggi makin lama durasinya. Batang abu-abu adalah hari yang terlewat."), "", "L", false)
+		pdf.Ln(2)
+		_, pageHeight := pdf.GetPageSize()
+		for i, c := range report.Charts {
+			name := fmt.Sprintf("chart-%d", i)
+			info := pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(c.PNG))
+			if info == nil {
+				break
+			}
+			height := contentWidth * info.Height() / info.Width()
+			if pdf.GetY()+lineHeight+height > pageHeight-margin {
+				pdf.AddPage()
+			}
+			pdf.SetFont("Helvetica", "B", 10)
+			pdf.SetTextColor(0x18, 0x18, 0x1b)
+			pdf.CellFormat(contentWidth*0.7, lineHeight, text(c.Name), "", 0, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.CellFormat(contentWidth*0.3, lineHeight, fmt.Sprintf("%d/%d hari", c.Reported, c.Days), "", 1, "R", false, 0, "")
+			pdf.ImageOptions(name, margin, pdf.GetY(), contentWidth, height, true, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+			pdf.Ln(4)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// formatDate renders e.g. "1 Oktober 2026".
+func formatDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), format.MonthName(t.Month()), t.Year())
+}
+
+// stripSymbols drops emoji and other symbols the core fonts cannot show,
+// e.g. "Lari 🏃" becomes "Lari".
+func stripSymbols(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.So, r) || unicode.Is(unicode.Variation_Selector, r) || r == '\u200d' {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,52 @@
+package pdf_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/format"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/pdf"
+)
+
+func TestRenderFinalReport(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	days := make([]format.ChartDay, 30)
+	for i := range days {
+		days[i] = format.ChartDay{Date: start.AddDate(0, 0, i), Reported: i%3 != 0, Minutes: 10 * i}
+	}
+	chart, err := format.RenderActivityChart(days)
+	if err != nil {
+		t.Fatalf("Failed to render chart: %v", err)
+	}
+
+	report := &usecase.FinalReport{
+		Title:      "Laporan Akhir Tantangan 30 Hari",
+		Since:      start,
+		Until:      start.AddDate(0, 0, 29),
+		Reports:    120,
+		Members:    6,
+		Km:         321.5,
+		Minutes:    4000,
+		Finished:   2,
+		Types:      []usecase.TypeCount{{Type: "lari", Label: "Lari 🏃", Count: 80}},
+		Highlights: []usecase.Highlight{{Title: "Paling rajin", Name: "Siti 💪", Value: "30 laporan"}},
+	}
+	for i := 0; i < 12; i++ {
+		report.Standings = append(report.Standings, usecase.BoardMember{Rank: i + 1, Name: "Member", Total: 30 - i, Streak: i})
+		report.Charts = append(report.Charts, usecase.MemberChart{Name: "Member", Reported: 20, Days: 30, PNG: chart})
+	}
+
+	data, err := pdf.NewRenderer().RenderFinalReport(report)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatalf("Expected a PDF, got %q", data[:min(len(data), 16)])
+	}
+	// The summary page, and the charts over several pages
+	if pages := bytes.Count(data, []byte("/Type /Page\n")); pages < 3 {
+		t.Errorf("Expected at least 3 pages, got %d", pages)
+	}
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type AdminAccountRepository struct {
+	db *sql.DB
+}
+
+func NewAdminAccountRepository(db *sql.DB) *AdminAccountRepository {
+	return &AdminAccountRepository{db: db}
+}
+
+func (r *AdminAccountRepository) GetAdminAccount(ctx context.Context, username string) (*domain.AdminAccount, error) {
+	query := `SELECT username, password_hash, created_at FROM admin_accounts WHERE username = ?`
+
+	var account domain.AdminAccount
+	var createdAt string
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, username).Scan(&account.Username, &account.PasswordHash, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	account.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *AdminAccountRepository) SaveAdminAccount(ctx context.Context, account *domain.AdminAccount) error {
+	query := `
+		INSERT INTO admin_accounts (username, password_hash, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(username) DO UPDATE SET
+			password_hash = excluded.password_hash
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, account.Username, account.PasswordHash, account.CreatedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (r *AdminAccountRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS admin_accounts (
+			username text PRIMARY KEY,
+			password_hash text NOT NULL,
+			created_at text NOT NULL
+		);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
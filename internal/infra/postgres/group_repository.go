@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type GroupRepository struct {
+	db *sql.DB
+}
+
+func NewGroupRepository(db *sql.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+const groupColumns = `jid, name, enabled, added_by, added_at, prefix, left_at, final_standings, command_hint, leaderboard_style`
+
+func (r *GroupRepository) GetGroup(ctx context.Context, jid string) (*domain.Group, error) {
+	query := `SELECT ` + groupColumns + ` FROM bot_groups WHERE jid = ?`
+	group, err := scanGroup(conn(ctx, r.db).QueryRowContext(ctx, query, jid))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return group, err
+}
+
+func (r *GroupRepository) GetGroups(ctx context.Context) ([]*domain.Group, error) {
+	query := `SELECT ` + groupColumns + ` FROM bot_groups ORDER BY added_at ASC`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []*domain.Group
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+func (r *GroupRepository) SaveGroup(ctx context.Context, group *domain.Group) error {
+	query := `
+		INSERT INTO bot_groups (jid, name, enabled, added_by, added_at, prefix, left_at, final_standings, command_hint, leaderboard_style)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(jid) DO UPDATE SET
+			name = excluded.name,
+			enabled = excluded.enabled,
+			prefix = excluded.prefix,
+			left_at = excluded.left_at,
+			final_standings = excluded.final_standings,
+			command_hint = excluded.command_hint,
+			leaderboard_style = excluded.leaderboard_style
+	`
+	leftAt := ""
+	if !group.LeftAt.IsZero() {
+		leftAt = group.LeftAt.UTC().Format(time.RFC3339)
+	}
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, group.JID, group.Name, group.Enabled, group.AddedBy,
+		group.AddedAt.UTC().Format(time.RFC3339), group.Prefix, leftAt, group.FinalStandings, group.CommandHint, group.LeaderboardStyle)
+	return err
+}
+
+func (r *GroupRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS bot_groups (
+			jid text PRIMARY KEY,
+			name text NOT NULL DEFAULT '',
+			enabled boolean NOT NULL DEFAULT true,
+			added_by text NOT NULL DEFAULT '',
+			added_at text NOT NULL,
+			prefix text NOT NULL DEFAULT '',
+			left_at text NOT NULL DEFAULT '',
+			final_standings text NOT NULL DEFAULT '',
+			command_hint boolean NOT NULL DEFAULT false,
+			leaderboard_style text NOT NULL DEFAULT ''
+		);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+func scanGroup(row rowScanner) (*domain.Group, error) {
+	var group domain.Group
+	var addedAt, leftAt string
+	if err := row.Scan(&group.JID, &group.Name, &group.Enabled, &group.AddedBy, &addedAt, &group.Prefix, &leftAt, &group.FinalStandings, &group.CommandHint, &group.LeaderboardStyle); err != nil {
+		return nil, err
+	}
+
+	var err error
+	group.AddedAt, err = time.Parse(time.RFC3339, addedAt)
+	if err != nil {
+		return nil, err
+	}
+	if leftAt != "" {
+		group.LeftAt, err = time.Parse(time.RFC3339, leftAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &group, nil
+}
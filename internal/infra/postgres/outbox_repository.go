@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+func (r *OutboxRepository) QueueReply(ctx context.Context, msg *domain.OutboxMessage) error {
+	query := `INSERT INTO outbox (message_id, chat_id, text, queued_at, sent_at) VALUES (?, ?, ?, ?, '')`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, msg.MessageID, msg.ChatID, msg.Text, msg.QueuedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (r *OutboxRepository) GetUnsent(ctx context.Context, since, until time.Time) ([]*domain.OutboxMessage, error) {
+	query := `
+		SELECT message_id, chat_id, text, queued_at FROM outbox
+		WHERE sent_at = '' AND queued_at >= ? AND queued_at < ?
+		ORDER BY queued_at, message_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, since.UTC().Format(time.RFC3339), until.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*domain.OutboxMessage
+	for rows.Next() {
+		var m domain.OutboxMessage
+		var queuedAt string
+		if err := rows.Scan(&m.MessageID, &m.ChatID, &m.Text, &queuedAt); err != nil {
+			return nil, err
+		}
+		if m.QueuedAt, err = time.Parse(time.RFC3339, queuedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, &m)
+	}
+	return messages, rows.Err()
+}
+
+// RecordSent keeps the first send of a message. A queued reply is marked
+// sent and its text shortened like the others.
+func (r *OutboxRepository) RecordSent(ctx context.Context, msg *domain.OutboxMessage) error {
+	query := `
+		INSERT INTO outbox (message_id, chat_id, text, sent_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(message_id) DO UPDATE SET text = excluded.text, sent_at = excluded.sent_at
+		WHERE outbox.sent_at = ''
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, msg.MessageID, msg.ChatID, msg.Text, msg.SentAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (r *OutboxRepository) RecordReceipt(ctx context.Context, messageIDs []string, read bool, at time.Time) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	// A read receipt also means the message arrived, even when the
+	// delivery receipt got lost
+	stamp := at.UTC().Format(time.RFC3339)
+	query := `UPDATE outbox SET delivered_at = COALESCE(NULLIF(delivered_at, ''), ?), delivered_count = delivered_count + 1`
+	if read {
+		query = `UPDATE outbox SET delivered_at = COALESCE(NULLIF(delivered_at, ''), ?), read_at = COALESCE(NULLIF(read_at, ''), ?), read_count = read_count + 1`
+	}
+	args := []interface{}{stamp}
+	if read {
+		args = append(args, stamp)
+	}
+	query += ` WHERE message_id IN (?` + strings.Repeat(", ?", len(messageIDs)-1) + `)`
+	for _, id := range messageIDs {
+		args = append(args, id)
+	}
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *OutboxRepository) GetOutbox(ctx context.Context, filter domain.OutboxFilter) ([]*domain.OutboxMessage, error) {
+	where, args := outboxWhere(filter)
+	query := `SELECT message_id, chat_id, text, queued_at, sent_at, delivered_at, read_at, delivered_count, read_count FROM outbox` + where
+	query += " ORDER BY sent_at DESC, message_id DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*domain.OutboxMessage
+	for rows.Next() {
+		var m domain.OutboxMessage
+		var queuedAt, sentAt, deliveredAt, readAt string
+		if err := rows.Scan(&m.MessageID, &m.ChatID, &m.Text, &queuedAt, &sentAt, &deliveredAt, &readAt, &m.DeliveredCount, &m.ReadCount); err != nil {
+			return nil, err
+		}
+		if queuedAt != "" {
+			if m.QueuedAt, err = time.Parse(time.RFC3339, queuedAt); err != nil {
+				return nil, err
+			}
+		}
+		if sentAt != "" {
+			if m.SentAt, err = time.Parse(time.RFC3339, sentAt); err != nil {
+				return nil, err
+			}
+		}
+		if deliveredAt != "" {
+			if m.DeliveredAt, err = time.Parse(time.RFC3339, deliveredAt); err != nil {
+				return nil, err
+			}
+		}
+		if readAt != "" {
+			if m.ReadAt, err = time.Parse(time.RFC3339, readAt); err != nil {
+				return nil, err
+			}
+		}
+		messages = append(messages, &m)
+	}
+	return messages, rows.Err()
+}
+
+func (r *OutboxRepository) DeleteOutbox(ctx context.Context, filter domain.OutboxFilter) error {
+	where, args := outboxWhere(filter)
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM outbox`+where, args...)
+	return err
+}
+
+func outboxWhere(filter domain.OutboxFilter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if filter.ChatID != "" {
+		conds = append(conds, "chat_id = ?")
+		args = append(args, filter.ChatID)
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, "sent_at >= ?")
+		args = append(args, filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		conds = append(conds, "sent_at < ?")
+		args = append(args, filter.Until.UTC().Format(time.RFC3339))
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (r *OutboxRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS outbox (
+			message_id text PRIMARY KEY,
+			chat_id text NOT NULL,
+			text text NOT NULL DEFAULT '',
+			queued_at text NOT NULL DEFAULT '',
+			sent_at text NOT NULL,
+			delivered_at text NOT NULL DEFAULT '',
+			read_at text NOT NULL DEFAULT '',
+			delivered_count integer NOT NULL DEFAULT 0,
+			read_count integer NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_outbox_sent_at ON outbox(sent_at);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
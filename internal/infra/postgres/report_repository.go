@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const reportColumns = `user_id, name, streak, activity_count, last_report_date, version, first_report_date, best_streak`
+
+func scanReport(row rowScanner) (*domain.Report, error) {
+	var report domain.Report
+	var lastReportDate, firstReportDate string
+	if err := row.Scan(&report.UserID, &report.Name, &report.Streak, &report.ActivityCount, &lastReportDate, &report.Version, &firstReportDate, &report.BestStreak); err != nil {
+		return nil, err
+	}
+
+	var err error
+	report.LastReportDate, err = time.Parse(time.RFC3339, lastReportDate)
+	if err != nil {
+		return nil, err
+	}
+	if firstReportDate != "" {
+		report.FirstReportDate, err = time.Parse(time.RFC3339, firstReportDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &report, nil
+}
+
+type ReportRepository struct {
+	db    *sql.DB
+	reads *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{db: db, reads: db}
+}
+
+// SetReadReplica sends GetAllReports and ListReports to a read replica,
+// outside of a transaction. GetReport stays on the primary: a #lapor reads
+// the row right before updating it, and a lagging replica would break the
+// streak.
+func (r *ReportRepository) SetReadReplica(db *sql.DB) {
+	r.reads = db
+}
+
+func (r *ReportRepository) GetReport(ctx context.Context, userID string) (*domain.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM user_reports WHERE user_id = ?`
+	report, err := scanReport(conn(ctx, r.db).QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return report, err
+}
+
+func (r *ReportRepository) UpsertReport(ctx context.Context, report *domain.Report) error {
+	lastReportDate := report.LastReportDate.Format(time.RFC3339)
+	firstReportDate := ""
+	if !report.FirstReportDate.IsZero() {
+		firstReportDate = report.FirstReportDate.Format(time.RFC3339)
+	}
+
+	var res sql.Result
+	var err error
+	if report.Version == 0 {
+		query := `
+			INSERT INTO user_reports (user_id, name, streak, activity_count, last_report_date, version, first_report_date, best_streak)
+			VALUES (?, ?, ?, ?, ?, 1, ?, ?)
+			ON CONFLICT(user_id) DO NOTHING
+		`
+		res, err = conn(ctx, r.db).ExecContext(ctx, query, report.UserID, report.Name, report.Streak, report.ActivityCount, lastReportDate, firstReportDate, report.BestStreak)
+	} else {
+		query := `
+			UPDATE user_reports
+			SET name = ?, streak = ?, activity_count = ?, last_report_date = ?, first_report_date = ?, best_streak = ?, version = version + 1
+			WHERE user_id = ? AND version = ?
+		`
+		res, err = conn(ctx, r.db).ExecContext(ctx, query, report.Name, report.Streak, report.ActivityCount, lastReportDate, firstReportDate, report.BestStreak, report.UserID, report.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrReportConflict
+	}
+	report.Version++
+	return nil
+}
+
+// SubmitReport does the whole streak update in one UPSERT, like the SQLite
+// driver. Day comparisons use the date part of the stored RFC3339 text,
+// i.e. the calendar it was saved in, like domain.ApplyReport.
+func (r *ReportRepository) SubmitReport(ctx context.Context, userID, name string, at time.Time) (*domain.Report, error) {
+	query := `
+		INSERT INTO user_reports (user_id, name, streak, activity_count, last_report_date, version, first_report_date, best_streak)
+		VALUES (?, ?, 1, 1, ?, 1, ?, 1)
+		ON CONFLICT(user_id) DO UPDATE SET
+			name = excluded.name,
+			streak = CASE WHEN left(user_reports.last_report_date, 10) = ? THEN COALESCE(user_reports.streak, 0) + 1 ELSE 1 END,
+			best_streak = GREATEST(user_reports.best_streak, CASE WHEN left(user_reports.last_report_date, 10) = ? THEN COALESCE(user_reports.streak, 0) + 1 ELSE 1 END),
+			activity_count = COALESCE(user_reports.activity_count, 0) + 1,
+			last_report_date = excluded.last_report_date,
+			version = user_reports.version + 1
+		WHERE COALESCE(left(user_reports.last_report_date, 10), '') <> ?
+		RETURNING streak, activity_count, version, first_report_date, best_streak
+	`
+	today := at.Format("2006-01-02")
+	yesterday := at.AddDate(0, 0, -1).Format("2006-01-02")
+
+	report := domain.Report{UserID: userID, Name: name, LastReportDate: at}
+	var firstReportDate string
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userID, name, at.Format(time.RFC3339), at.Format(time.RFC3339), yesterday, yesterday, today).
+		Scan(&report.Streak, &report.ActivityCount, &report.Version, &firstReportDate, &report.BestStreak)
+	if err == sql.ErrNoRows {
+		// The update was skipped: today is already counted
+		return nil, domain.ErrAlreadyReported
+	}
+	if err != nil {
+		return nil, err
+	}
+	if firstReportDate != "" {
+		report.FirstReportDate, err = time.Parse(time.RFC3339, firstReportDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &report, nil
+}
+
+func (r *ReportRepository) GetAllReports(ctx context.Context) ([]*domain.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM user_reports ORDER BY activity_count DESC`
+	return r.list(ctx, query)
+}
+
+func (r *ReportRepository) ListReports(ctx context.Context, q domain.ReportQuery) ([]*domain.Report, error) {
+	var conds []string
+	var args []interface{}
+	if q.UserIDs != nil {
+		if len(q.UserIDs) == 0 {
+			return nil, nil
+		}
+		conds = append(conds, "user_id IN (?"+strings.Repeat(", ?", len(q.UserIDs)-1)+")")
+		for _, id := range q.UserIDs {
+			args = append(args, id)
+		}
+	}
+	// last_report_date keeps its UTC offset, so compare as timestamps
+	if !q.Since.IsZero() {
+		conds = append(conds, "NULLIF(last_report_date, '')::timestamptz >= ?")
+		args = append(args, q.Since)
+	}
+	if !q.Until.IsZero() {
+		conds = append(conds, "NULLIF(last_report_date, '')::timestamptz < ?")
+		args = append(args, q.Until)
+	}
+
+	// Keyset pagination: rows strictly after the last row of the previous page
+	order := "activity_count DESC, user_id"
+	switch q.Sort {
+	case domain.ReportSortStreak:
+		order = "streak DESC, user_id"
+		if q.After != nil {
+			conds = append(conds, "(streak < ? OR (streak = ? AND user_id > ?))")
+			args = append(args, q.After.Streak, q.After.Streak, q.After.UserID)
+		}
+	case domain.ReportSortName:
+		order = "name, user_id"
+		if q.After != nil {
+			conds = append(conds, "(name > ? OR (name = ? AND user_id > ?))")
+			args = append(args, q.After.Name, q.After.Name, q.After.UserID)
+		}
+	default:
+		if q.After != nil {
+			conds = append(conds, "(activity_count < ? OR (activity_count = ? AND user_id > ?))")
+			args = append(args, q.After.ActivityCount, q.After.ActivityCount, q.After.UserID)
+		}
+	}
+
+	query := `SELECT ` + reportColumns + ` FROM user_reports`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY " + order
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+	return r.list(ctx, query, args...)
+}
+
+func (r *ReportRepository) list(ctx context.Context, query string, args ...interface{}) ([]*domain.Report, error) {
+	rows, err := conn(ctx, r.reads).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*domain.Report
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, rows.Err()
+}
+
+func (r *ReportRepository) DeleteReport(ctx context.Context, userID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM user_reports WHERE user_id = ?`, userID)
+	return err
+}
+
+func (r *ReportRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS user_reports (
+			user_id text PRIMARY KEY,
+			name text NOT NULL DEFAULT '',
+			streak integer NOT NULL DEFAULT 0,
+			activity_count integer NOT NULL DEFAULT 0,
+			last_report_date text NOT NULL DEFAULT '',
+			version integer NOT NULL DEFAULT 1,
+			first_report_date text NOT NULL DEFAULT '',
+			best_streak integer NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_user_reports_streak ON user_reports(streak DESC, user_id);
+		CREATE INDEX IF NOT EXISTS idx_user_reports_activity_count ON user_reports(activity_count DESC, user_id);
+		CREATE INDEX IF NOT EXISTS idx_user_reports_name ON user_reports(name, user_id);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+// ResolveLIDToPhone returns lid unchanged: the LID map belongs to the
+// WhatsApp session, which stays in its SQLite file next to Postgres.
+func (r *ReportRepository) ResolveLIDToPhone(ctx context.Context, lid string) string {
+	return lid
+}
+
+// DeleteLIDMappings does nothing for the same reason; the session deletes
+// its own mappings.
+func (r *ReportRepository) DeleteLIDMappings(ctx context.Context, userID string) error {
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
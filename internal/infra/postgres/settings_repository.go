@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+func NewSettingsRepository(db *sql.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+const settingsColumns = `user_id, target, snooze_until, reminder_time, timezone, display_name, joined_at, verify_by, unranked, private, email, calendar_token, no_mention`
+
+func (r *SettingsRepository) GetSettings(ctx context.Context, userID string) (*domain.UserSettings, error) {
+	query := `SELECT ` + settingsColumns + ` FROM user_settings WHERE user_id = ?`
+	settings, err := scanSettings(conn(ctx, r.db).QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return settings, err
+}
+
+func (r *SettingsRepository) GetReminderSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	return r.list(ctx, `SELECT `+settingsColumns+` FROM user_settings WHERE reminder_time <> ''`)
+}
+
+func (r *SettingsRepository) GetUnrankedSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	return r.list(ctx, `SELECT `+settingsColumns+` FROM user_settings WHERE unranked OR private`)
+}
+
+func (r *SettingsRepository) GetUnverifiedSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	return r.list(ctx, `SELECT `+settingsColumns+` FROM user_settings WHERE verify_by <> '' AND joined_at = ''`)
+}
+
+func (r *SettingsRepository) GetEmailSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	return r.list(ctx, `SELECT `+settingsColumns+` FROM user_settings WHERE email <> ''`)
+}
+
+func (r *SettingsRepository) GetSettingsByCalendarToken(ctx context.Context, token string) (*domain.UserSettings, error) {
+	query := `SELECT ` + settingsColumns + ` FROM user_settings WHERE calendar_token = ? AND calendar_token <> ''`
+	settings, err := scanSettings(conn(ctx, r.db).QueryRowContext(ctx, query, token))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return settings, err
+}
+
+func (r *SettingsRepository) list(ctx context.Context, query string) ([]*domain.UserSettings, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*domain.UserSettings
+	for rows.Next() {
+		settings, err := scanSettings(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, settings)
+	}
+	return list, rows.Err()
+}
+
+func (r *SettingsRepository) SaveSettings(ctx context.Context, settings *domain.UserSettings) error {
+	query := `
+		INSERT INTO user_settings (user_id, target, snooze_until, reminder_time, timezone, display_name, joined_at, verify_by, unranked, private, email, calendar_token, no_mention)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			target = excluded.target,
+			snooze_until = excluded.snooze_until,
+			reminder_time = excluded.reminder_time,
+			timezone = excluded.timezone,
+			display_name = excluded.display_name,
+			joined_at = excluded.joined_at,
+			verify_by = excluded.verify_by,
+			unranked = excluded.unranked,
+			private = excluded.private,
+			email = excluded.email,
+			calendar_token = excluded.calendar_token,
+			no_mention = excluded.no_mention
+	`
+	snoozeUntil := ""
+	if !settings.SnoozeUntil.IsZero() {
+		snoozeUntil = settings.SnoozeUntil.UTC().Format(time.RFC3339)
+	}
+	joinedAt := ""
+	if !settings.JoinedAt.IsZero() {
+		joinedAt = settings.JoinedAt.UTC().Format(time.RFC3339)
+	}
+	verifyBy := ""
+	if !settings.VerifyBy.IsZero() {
+		verifyBy = settings.VerifyBy.UTC().Format(time.RFC3339)
+	}
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, settings.UserID, settings.Target, snoozeUntil, settings.ReminderTime, settings.Timezone,
+		settings.DisplayName, joinedAt, verifyBy, settings.Unranked, settings.Private, settings.Email, settings.CalendarToken, settings.NoMention)
+	return err
+}
+
+func (r *SettingsRepository) DeleteSettings(ctx context.Context, userID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM user_settings WHERE user_id = ?`, userID)
+	return err
+}
+
+func (r *SettingsRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS user_settings (
+			user_id text PRIMARY KEY,
+			target integer NOT NULL DEFAULT 0,
+			snooze_until text NOT NULL DEFAULT '',
+			reminder_time text NOT NULL DEFAULT '',
+			timezone text NOT NULL DEFAULT '',
+			display_name text NOT NULL DEFAULT '',
+			joined_at text NOT NULL DEFAULT '',
+			verify_by text NOT NULL DEFAULT '',
+			unranked boolean NOT NULL DEFAULT false,
+			private boolean NOT NULL DEFAULT false,
+			email text NOT NULL DEFAULT '',
+			calendar_token text NOT NULL DEFAULT '',
+			no_mention boolean NOT NULL DEFAULT false
+		);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+func scanSettings(row rowScanner) (*domain.UserSettings, error) {
+	var settings domain.UserSettings
+	var snoozeUntil, joinedAt, verifyBy string
+	if err := row.Scan(&settings.UserID, &settings.Target, &snoozeUntil, &settings.ReminderTime, &settings.Timezone,
+		&settings.DisplayName, &joinedAt, &verifyBy, &settings.Unranked, &settings.Private, &settings.Email, &settings.CalendarToken, &settings.NoMention); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if snoozeUntil != "" {
+		settings.SnoozeUntil, err = time.Parse(time.RFC3339, snoozeUntil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if joinedAt != "" {
+		settings.JoinedAt, err = time.Parse(time.RFC3339, joinedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if verifyBy != "" {
+		settings.VerifyBy, err = time.Parse(time.RFC3339, verifyBy)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &settings, nil
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type SnapshotRepository struct {
+	db *sql.DB
+}
+
+func NewSnapshotRepository(db *sql.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+func (r *SnapshotRepository) SaveSnapshot(ctx context.Context, day string, entries []*domain.SnapshotEntry) error {
+	return NewTransactor(r.db).WithTx(ctx, func(ctx context.Context) error {
+		if _, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM leaderboard_snapshots WHERE day = ?`, day); err != nil {
+			return err
+		}
+		query := `
+			INSERT INTO leaderboard_snapshots (day, user_id, name, rank, streak, total, reported)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`
+		for _, e := range entries {
+			if _, err := conn(ctx, r.db).ExecContext(ctx, query, day, e.UserID, e.Name, e.Rank, e.Streak, e.Total, e.Reported); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *SnapshotRepository) GetSnapshot(ctx context.Context, day string) ([]*domain.SnapshotEntry, error) {
+	query := `
+		SELECT day, user_id, name, rank, streak, total, reported
+		FROM leaderboard_snapshots WHERE day = ? ORDER BY rank, name
+	`
+	return r.query(ctx, query, day)
+}
+
+func (r *SnapshotRepository) GetSnapshots(ctx context.Context, filter domain.SnapshotFilter) ([]*domain.SnapshotEntry, error) {
+	where, args := snapshotWhere(filter)
+	query := `SELECT day, user_id, name, rank, streak, total, reported FROM leaderboard_snapshots` + where + ` ORDER BY day, rank`
+	return r.query(ctx, query, args...)
+}
+
+func (r *SnapshotRepository) query(ctx context.Context, query string, args ...interface{}) ([]*domain.SnapshotEntry, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.SnapshotEntry
+	for rows.Next() {
+		var e domain.SnapshotEntry
+		if err := rows.Scan(&e.Day, &e.UserID, &e.Name, &e.Rank, &e.Streak, &e.Total, &e.Reported); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *SnapshotRepository) DeleteSnapshots(ctx context.Context, filter domain.SnapshotFilter) error {
+	where, args := snapshotWhere(filter)
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM leaderboard_snapshots`+where, args...)
+	return err
+}
+
+func snapshotWhere(filter domain.SnapshotFilter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if filter.UserID != "" {
+		conds = append(conds, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.Before != "" {
+		conds = append(conds, "day < ?")
+		args = append(args, filter.Before)
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (r *SnapshotRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS leaderboard_snapshots (
+			day text NOT NULL,
+			user_id text NOT NULL,
+			name text NOT NULL DEFAULT '',
+			rank integer NOT NULL,
+			streak integer NOT NULL DEFAULT 0,
+			total integer NOT NULL DEFAULT 0,
+			reported boolean NOT NULL DEFAULT false,
+			PRIMARY KEY (day, user_id)
+		);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type TenantRepository struct {
+	db *sql.DB
+}
+
+func NewTenantRepository(db *sql.DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+func (r *TenantRepository) GetTenant(ctx context.Context, id string) (*domain.Tenant, error) {
+	query := `SELECT id, name, enabled, env, created_at FROM bot_tenants WHERE id = ?`
+	tenant, err := scanTenant(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return tenant, err
+}
+
+func (r *TenantRepository) GetTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	query := `SELECT id, name, enabled, env, created_at FROM bot_tenants ORDER BY created_at ASC, id ASC`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []*domain.Tenant
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, tenant)
+	}
+	return tenants, rows.Err()
+}
+
+func (r *TenantRepository) SaveTenant(ctx context.Context, tenant *domain.Tenant) error {
+	env, err := json.Marshal(tenant.Env)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO bot_tenants (id, name, enabled, env, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			enabled = excluded.enabled,
+			env = excluded.env
+	`
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, tenant.ID, tenant.Name, tenant.Enabled, string(env), tenant.CreatedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (r *TenantRepository) DeleteTenant(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM bot_tenants WHERE id = ?`, id)
+	return err
+}
+
+func (r *TenantRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS bot_tenants (
+			id text PRIMARY KEY,
+			name text NOT NULL DEFAULT '',
+			enabled boolean NOT NULL DEFAULT true,
+			env jsonb NOT NULL DEFAULT '{}',
+			created_at text NOT NULL
+		);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+func scanTenant(row rowScanner) (*domain.Tenant, error) {
+	var tenant domain.Tenant
+	var env, createdAt string
+	if err := row.Scan(&tenant.ID, &tenant.Name, &tenant.Enabled, &env, &createdAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(env), &tenant.Env); err != nil {
+		return nil, err
+	}
+	if tenant.Env == nil {
+		tenant.Env = make(map[string]string)
+	}
+	var err error
+	tenant.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
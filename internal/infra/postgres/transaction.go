@@ -0,0 +1,96 @@
+// Package postgres stores the bot's data in a Postgres database over
+// database/sql. The tables have the columns of the Supabase ones, times
+// included as RFC3339 text, so it also runs on the database of a Supabase
+// project.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+type txKey struct{}
+
+// Transactor implements domain.Transactor. The transaction travels in the
+// context, and repositories built on the same *sql.DB pick it up through
+// conn.
+type Transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+func (t *Transactor) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier is what *sql.DB and *sql.Tx have in common.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn returns the transaction in ctx, or db outside of WithTx. Queries are
+// written with ? placeholders like the SQLite repositories and numbered for
+// Postgres on the way through.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return numbered{tx}
+	}
+	return numbered{db}
+}
+
+// numbered rewrites the ? placeholders of every query to $1, $2, ...
+type numbered struct {
+	q querier
+}
+
+func (n numbered) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return n.q.ExecContext(ctx, rebind(query), args...)
+}
+
+func (n numbered) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return n.q.QueryContext(ctx, rebind(query), args...)
+}
+
+func (n numbered) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return n.q.QueryRowContext(ctx, rebind(query), args...)
+}
+
+// rebind numbers the ? placeholders of query, leaving quoted literals alone.
+func rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	n, quoted := 0, false
+	for _, c := range query {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == '?' && !quoted:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
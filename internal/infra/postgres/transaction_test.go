@@ -0,0 +1,24 @@
+package postgres
+
+import "testing"
+
+// =============================================================================
+// PLACEHOLDER TESTS
+// =============================================================================
+
+func TestRebind_NumbersPlaceholders(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"SELECT * FROM t WHERE a = ? AND b IN (?, ?)", "SELECT * FROM t WHERE a = $1 AND b IN ($2, $3)"},
+		{"SELECT * FROM t WHERE a = '?' AND b = ?", "SELECT * FROM t WHERE a = '?' AND b = $1"},
+		{"text ILIKE ? ESCAPE '\\' AND sender_id = ?", "text ILIKE $1 ESCAPE '\\' AND sender_id = $2"},
+	}
+	for _, tt := range tests {
+		if got := rebind(tt.query); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Package queue hands incoming WhatsApp messages from the bot process,
+// which holds the session, to worker processes over Redis streams, and the
+// workers' replies back. Workers share a consumer group, so each message is
+// handled by one of them; a message a worker took but never finished, e.g.
+// because it crashed, is handed to another worker after a minute.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	workersGroup = "workers"
+	botGroup     = "bot"
+	// Streams are trimmed to about this many entries
+	maxLen = 10000
+	// A message that was not acknowledged for this long is handed to
+	// another consumer
+	claimAfter = time.Minute
+	// How long a read waits for new messages before checking ctx again
+	blockFor = 5 * time.Second
+)
+
+// Incoming is a chat message the bot received, with the sender already
+// resolved to their user ID.
+type Incoming struct {
+	MessageID string    `json:"message_id"`
+	ChatID    string    `json:"chat_id"`
+	SenderJID string    `json:"sender_jid"` // Private replies go to this chat
+	UserID    string    `json:"user_id"`
+	PushName  string    `json:"push_name"`
+	IsGroup   bool      `json:"is_group"`
+	Text      string    `json:"text,omitempty"`      // Text or caption
+	QuotedID  string    `json:"quoted_id,omitempty"` // Message this one replies to
+	Reaction  *Reaction `json:"reaction,omitempty"`
+	MediaType string    `json:"media_type,omitempty"` // image, video, document, audio, sticker
+	MediaMime string    `json:"media_mime,omitempty"`
+	MediaSize int64     `json:"media_size,omitempty"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// Reaction is an emoji reaction to another message.
+type Reaction struct {
+	MessageID string `json:"message_id"`
+	Text      string `json:"text"`
+}
+
+// Outgoing is a worker's reply to an incoming message.
+type Outgoing struct {
+	To    Incoming      `json:"to"`
+	Reply usecase.Reply `json:"reply"`
+}
+
+// Redis is a message queue on Redis streams.
+type Redis struct {
+	client   *goredis.Client
+	incoming string
+	replies  string
+}
+
+// NewRedis connects to a Redis URL such as redis://localhost:6379/1.
+// Tenants pass their ID as namespace so they can share the instance.
+func NewRedis(url, namespace string) (*Redis, error) {
+	opts, err := goredis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := goredis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	prefix := "lapor-bot:queue:"
+	if namespace != "" {
+		prefix += namespace + ":"
+	}
+	return &Redis{client: client, incoming: prefix + "incoming", replies: prefix + "replies"}, nil
+}
+
+// PublishIncoming queues a message for the workers.
+func (q *Redis) PublishIncoming(ctx context.Context, in *Incoming) error {
+	return q.publish(ctx, q.incoming, in)
+}
+
+// PublishReply queues a reply for the bot process to send.
+func (q *Redis) PublishReply(ctx context.Context, out *Outgoing) error {
+	return q.publish(ctx, q.replies, out)
+}
+
+// ConsumeIncoming hands queued messages to handle, one at a time, until ctx
+// is done. Every worker goroutine passes its own consumer name.
+func (q *Redis) ConsumeIncoming(ctx context.Context, consumer string, handle func(ctx context.Context, in *Incoming) error) error {
+	return q.consume(ctx, q.incoming, workersGroup, consumer, func(ctx context.Context, data []byte) error {
+		var in Incoming
+		if err := json.Unmarshal(data, &in); err != nil {
+			return err
+		}
+		return handle(ctx, &in)
+	})
+}
+
+// ConsumeReplies hands queued replies to send, one at a time, until ctx is
+// done.
+func (q *Redis) ConsumeReplies(ctx context.Context, send func(ctx context.Context, out *Outgoing) error) error {
+	return q.consume(ctx, q.replies, botGroup, "bot", func(ctx context.Context, data []byte) error {
+		var out Outgoing
+		if err := json.Unmarshal(data, &out); err != nil {
+			return err
+		}
+		return send(ctx, &out)
+	})
+}
+
+func (q *Redis) Close() error {
+	return q.client.Close()
+}
+
+func (q *Redis) publish(ctx context.Context, stream string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return q.client.XAdd(ctx, &goredis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"data": data},
+	}).Err()
+}
+
+// consume reads the stream as a member of group. A message is acknowledged
+// once handle returns, also when it fails: the failure is logged, and
+// handling it again would most likely fail the same way. Only a message
+// handled while ctx was cancelled is left unacknowledged.
+func (q *Redis) consume(ctx context.Context, stream, group, consumer string, handle func(ctx context.Context, data []byte) error) error {
+	err := q.client.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	var lastClaim time.Time
+	for ctx.Err() == nil {
+		var msgs []goredis.XMessage
+		if time.Since(lastClaim) >= claimAfter {
+			lastClaim = time.Now()
+			msgs, err = q.claimStale(ctx, stream, group, consumer)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Failed to claim stale messages of %s: %v", stream, err)
+			}
+		}
+		if len(msgs) == 0 {
+			msgs, err = q.read(ctx, stream, group, consumer)
+			if err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				log.Printf("Failed to read %s: %v", stream, err)
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+				continue
+			}
+		}
+
+		for _, msg := range msgs {
+			data, _ := msg.Values["data"].(string)
+			stop := q.keepClaimed(ctx, stream, group, consumer, msg.ID)
+			if err := handle(ctx, []byte(data)); err != nil {
+				log.Printf("Failed to handle queued message %s: %v", msg.ID, err)
+			}
+			stop()
+			// A handler stopped by shutdown may have left the message half
+			// done, so it stays pending for another consumer to claim
+			if ctx.Err() != nil {
+				break
+			}
+			if err := q.client.XAck(ctx, stream, group, msg.ID).Err(); err != nil {
+				log.Printf("Failed to acknowledge queued message %s: %v", msg.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// keepClaimed resets the idle time of a message while it is handled, so a
+// slow handler does not get it claimed by another consumer. The returned
+// func stops it.
+func (q *Redis) keepClaimed(ctx context.Context, stream, group, consumer, id string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(claimAfter / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := q.client.XClaimJustID(ctx, &goredis.XClaimArgs{
+					Stream:   stream,
+					Group:    group,
+					Consumer: consumer,
+					Messages: []string{id},
+				}).Err()
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Failed to keep queued message %s claimed: %v", id, err)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// read takes one message at a time: messages a consumer holds wait for it
+// even while other consumers are idle.
+func (q *Redis) read(ctx context.Context, stream, group, consumer string) ([]goredis.XMessage, error) {
+	streams, err := q.client.XReadGroup(ctx, &goredis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{stream, ">"},
+		Count:    1,
+		Block:    blockFor,
+	}).Result()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []goredis.XMessage
+	for _, s := range streams {
+		msgs = append(msgs, s.Messages...)
+	}
+	return msgs, nil
+}
+
+// claimStale takes over the messages another consumer, or an earlier run
+// of this one, read but never acknowledged.
+func (q *Redis) claimStale(ctx context.Context, stream, group, consumer string) ([]goredis.XMessage, error) {
+	msgs, _, err := q.client.XAutoClaim(ctx, &goredis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  claimAfter,
+		Start:    "0-0",
+		Count:    1,
+	}).Result()
+	return msgs, err
+}
@@ -0,0 +1,191 @@
+package queue_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/queue"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// =============================================================================
+// QUEUE TESTS
+// =============================================================================
+
+// The queue needs a Redis server; the test runs when REDIS_TEST_URL points
+// at one.
+func newTestQueue(t *testing.T) *queue.Redis {
+	url := os.Getenv("REDIS_TEST_URL")
+	if url == "" {
+		t.Skip("REDIS_TEST_URL not set")
+	}
+	q, err := queue.NewRedis(url, fmt.Sprintf("test%d", time.Now().UnixNano()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = q.Close() })
+	return q
+}
+
+func TestRedis_RoundTrip(t *testing.T) {
+	q := newTestQueue(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	in := &queue.Incoming{MessageID: "m1", ChatID: "1@g.us", SenderJID: "628111@s.whatsapp.net", UserID: "628111", PushName: "Budi", IsGroup: true, Text: "#lapor", SentAt: time.Now().Truncate(time.Second)}
+	if err := q.PublishIncoming(ctx, in); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+
+	// A worker answers with an image
+	go func() {
+		_ = q.ConsumeIncoming(ctx, "worker-1", func(ctx context.Context, in *queue.Incoming) error {
+			return q.PublishReply(ctx, &queue.Outgoing{To: *in, Reply: usecase.Reply{Text: "Mantap " + in.PushName, Image: []byte{1, 2, 3}, Private: true}})
+		})
+	}()
+
+	replies := make(chan *queue.Outgoing, 1)
+	go func() {
+		_ = q.ConsumeReplies(ctx, func(ctx context.Context, out *queue.Outgoing) error {
+			replies <- out
+			return nil
+		})
+	}()
+
+	select {
+	case out := <-replies:
+		if out.To.MessageID != "m1" || out.To.SenderJID != in.SenderJID || !out.To.SentAt.Equal(in.SentAt) {
+			t.Errorf("Expected the reply to carry the incoming message, got %+v", out.To)
+		}
+		if out.Reply.Text != "Mantap Budi" || len(out.Reply.Image) != 3 || !out.Reply.Private {
+			t.Errorf("Expected the worker's reply, got %+v", out.Reply)
+		}
+	case <-ctx.Done():
+		t.Fatal("Expected a reply")
+	}
+}
+
+func TestRedis_EachMessageHandledOnce(t *testing.T) {
+	q := newTestQueue(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 20; i++ {
+		if err := q.PublishIncoming(ctx, &queue.Incoming{MessageID: fmt.Sprint(i)}); err != nil {
+			t.Fatalf("Failed to publish: %v", err)
+		}
+	}
+
+	handled := make(chan string, 40)
+	for w := 0; w < 3; w++ {
+		consumer := fmt.Sprint("worker-", w)
+		go func() {
+			_ = q.ConsumeIncoming(ctx, consumer, func(ctx context.Context, in *queue.Incoming) error {
+				handled <- in.MessageID
+				return nil
+			})
+		}()
+	}
+
+	seen := make(map[string]int)
+	timeout := time.After(10 * time.Second)
+	for len(seen) < 20 {
+		select {
+		case id := <-handled:
+			seen[id]++
+		case <-timeout:
+			t.Fatalf("Expected 20 messages handled, got %d", len(seen))
+		}
+	}
+	time.Sleep(200 * time.Millisecond)
+	if len(handled) != 0 {
+		t.Errorf("Expected every message handled by one worker only, %d handled again", len(handled))
+	}
+}
+
+func TestRedis_BusyWorkerHoldsOneMessage(t *testing.T) {
+	q := newTestQueue(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		if err := q.PublishIncoming(ctx, &queue.Incoming{MessageID: fmt.Sprint(i)}); err != nil {
+			t.Fatalf("Failed to publish: %v", err)
+		}
+	}
+
+	// The first worker gets stuck on its message
+	busy := make(chan string, 1)
+	go func() {
+		_ = q.ConsumeIncoming(ctx, "worker-busy", func(ctx context.Context, in *queue.Incoming) error {
+			busy <- in.MessageID
+			<-ctx.Done()
+			return nil
+		})
+	}()
+	var stuck string
+	select {
+	case stuck = <-busy:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the busy worker to take a message")
+	}
+
+	handled := make(chan string, 3)
+	go func() {
+		_ = q.ConsumeIncoming(ctx, "worker-idle", func(ctx context.Context, in *queue.Incoming) error {
+			handled <- in.MessageID
+			return nil
+		})
+	}()
+
+	timeout := time.After(10 * time.Second)
+	for i := 0; i < 2; i++ {
+		select {
+		case id := <-handled:
+			if id == stuck {
+				t.Errorf("Expected message %s to stay with the busy worker", id)
+			}
+		case <-timeout:
+			t.Fatalf("Expected the idle worker to handle the other messages, got %d", i)
+		}
+	}
+}
+
+func TestRedis_ShutdownLeavesMessagePending(t *testing.T) {
+	url := os.Getenv("REDIS_TEST_URL")
+	if url == "" {
+		t.Skip("REDIS_TEST_URL not set")
+	}
+	namespace := fmt.Sprintf("test%d", time.Now().UnixNano())
+	q, err := queue.NewRedis(url, namespace)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer q.Close()
+	if err := q.PublishIncoming(context.Background(), &queue.Incoming{MessageID: "m1"}); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+
+	// The worker is stopped while handling the message
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = q.ConsumeIncoming(ctx, "worker-1", func(ctx context.Context, in *queue.Incoming) error {
+		cancel()
+		return ctx.Err()
+	})
+
+	opts, _ := goredis.ParseURL(url)
+	client := goredis.NewClient(opts)
+	defer client.Close()
+	pending, err := client.XPending(context.Background(), "lapor-bot:queue:"+namespace+":incoming", "workers").Result()
+	if err != nil {
+		t.Fatalf("Failed to read pending messages: %v", err)
+	}
+	if pending.Count != 1 {
+		t.Errorf("Expected the half-handled message to stay pending, got %d pending", pending.Count)
+	}
+}
@@ -0,0 +1,50 @@
+// Package redis keeps state that every process of a deployment must share
+// in Redis: conversations, the report cache, rate limits and the IDs of
+// handled messages.
+package redis
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Client is the connection the stores of this package share.
+type Client struct {
+	rdb    *goredis.Client
+	prefix string
+}
+
+// Connect connects to a Redis URL such as redis://localhost:6379/0.
+// Tenants pass their ID as namespace so they can share the instance.
+func Connect(url, namespace string) (*Client, error) {
+	opts, err := goredis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	rdb := goredis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	prefix := "lapor-bot:"
+	if namespace != "" {
+		prefix += namespace + ":"
+	}
+	return &Client{rdb: rdb, prefix: prefix}, nil
+}
+
+// key namespaces a key so the Redis instance can be shared, e.g.
+// key("conversation", userID) is "lapor-bot:conversation:<userID>".
+func (c *Client) key(parts ...string) string {
+	return c.prefix + strings.Join(parts, ":")
+}
+
+func (c *Client) Close() error {
+	return c.rdb.Close()
+}
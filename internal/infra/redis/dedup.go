@@ -0,0 +1,24 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+// Dedup implements domain.DedupStore with keys that expire after the TTL.
+type Dedup struct {
+	client *Client
+}
+
+func NewDedup(client *Client) *Dedup {
+	return &Dedup{client: client}
+}
+
+func (d *Dedup) FirstSeen(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return d.client.rdb.SetNX(ctx, d.client.key("seen", key), 1, ttl).Result()
+}
+
+func (d *Dedup) Seen(ctx context.Context, key string) (bool, error) {
+	n, err := d.client.rdb.Exists(ctx, d.client.key("seen", key)).Result()
+	return n > 0, err
+}
@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// tokenBucket is the token bucket of the admin API's in-memory limiter as
+// a script, so checking and taking a token is one atomic step.
+var tokenBucket = goredis.NewScript(`
+local limit = tonumber(ARGV[1])
+local now = tonumber(ARGV[2])
+local rate = limit / 60000
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(bucket[1]) or limit
+local last = tonumber(bucket[2]) or now
+tokens = math.min(limit, tokens + math.max(0, now - last) * rate)
+local wait = 0
+if tokens < 1 then
+	wait = math.ceil((1 - tokens) / rate)
+else
+	tokens = tokens - 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', now)
+redis.call('PEXPIRE', KEYS[1], 60000)
+return wait
+`)
+
+// RateLimiter allows perMinute requests per key, counted in Redis so every
+// instance behind a load balancer applies the same limit. A key may burst up
+// to the limit and refills at perMinute per minute.
+type RateLimiter struct {
+	client    *Client
+	name      string
+	perMinute int
+}
+
+// NewRateLimiter counts under name, e.g. "ip" or "token", so several
+// limiters can share the instance.
+func NewRateLimiter(client *Client, name string, perMinute int) *RateLimiter {
+	return &RateLimiter{client: client, name: name, perMinute: perMinute}
+}
+
+// Allow takes one request from key's bucket. When it is empty, Allow
+// returns false and how long until the next request is allowed. Requests
+// are allowed while Redis is unavailable.
+func (l *RateLimiter) Allow(ctx context.Context, key string) (bool, time.Duration) {
+	// Keys may be API tokens, which must not end up in Redis
+	sum := sha256.Sum256([]byte(key))
+	wait, err := tokenBucket.Run(ctx, l.client.rdb, []string{l.client.key("ratelimit", l.name, hex.EncodeToString(sum[:16]))},
+		l.perMinute, time.Now().UnixMilli()).Int64()
+	if err != nil {
+		log.Printf("Rate limit unavailable: %v", err)
+		return true, 0
+	}
+	if wait > 0 {
+		return false, time.Duration(wait) * time.Millisecond
+	}
+	return true, 0
+}
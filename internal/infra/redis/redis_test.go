@@ -0,0 +1,143 @@
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/redis"
+)
+
+// =============================================================================
+// REDIS TESTS
+// =============================================================================
+
+// The stores need a Redis server; the tests run when REDIS_TEST_URL points
+// at one. Each test gets a namespace of its own.
+func newTestClient(t *testing.T) *redis.Client {
+	url := os.Getenv("REDIS_TEST_URL")
+	if url == "" {
+		t.Skip("REDIS_TEST_URL not set")
+	}
+	client, err := redis.Connect(url, fmt.Sprintf("test%d", time.Now().UnixNano()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// countingRepo is an in-memory ReportRepository that counts reads.
+type countingRepo struct {
+	domain.ReportRepository
+	reports map[string]domain.Report
+	lists   int
+}
+
+func (r *countingRepo) GetAllReports(ctx context.Context) ([]*domain.Report, error) {
+	r.lists++
+	var all []*domain.Report
+	for _, report := range r.reports {
+		report := report
+		all = append(all, &report)
+	}
+	return all, nil
+}
+
+func (r *countingRepo) UpsertReport(ctx context.Context, report *domain.Report) error {
+	r.reports[report.UserID] = *report
+	return nil
+}
+
+func TestReportRepository_SharedBetweenProcesses(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	db := &countingRepo{reports: map[string]domain.Report{"user1": {UserID: "user1", Name: "Alice", Streak: 3}}}
+	// Two processes on the same database and Redis
+	a := redis.NewReportRepository(db, client, time.Minute)
+	b := redis.NewReportRepository(db, client, time.Minute)
+
+	if _, err := a.GetAllReports(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	all, err := b.GetAllReports(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if db.lists != 1 || len(all) != 1 || all[0].Name != "Alice" {
+		t.Fatalf("Expected the second process to read the cache, got %d reads and %+v", db.lists, all)
+	}
+
+	// A write in one process is seen by the other
+	if err := a.UpsertReport(ctx, &domain.Report{UserID: "user2", Name: "Budi"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	all, _ = b.GetAllReports(ctx)
+	if db.lists != 2 || len(all) != 2 {
+		t.Errorf("Expected the write to clear the cache, got %d reads and %d reports", db.lists, len(all))
+	}
+}
+
+func TestRateLimiter_SharedLimit(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	a := redis.NewRateLimiter(client, "ip", 2)
+	b := redis.NewRateLimiter(client, "ip", 2)
+
+	for i, l := range []*redis.RateLimiter{a, b} {
+		if ok, _ := l.Allow(ctx, "10.0.0.1"); !ok {
+			t.Fatalf("Request %d: expected to be allowed", i+1)
+		}
+	}
+	ok, wait := a.Allow(ctx, "10.0.0.1")
+	if ok || wait <= 0 {
+		t.Errorf("Expected the third request to wait, got %v and %v", ok, wait)
+	}
+	if ok, _ := b.Allow(ctx, "10.0.0.2"); !ok {
+		t.Error("Expected another key to have its own limit")
+	}
+}
+
+func TestDedup_FirstSeenOnce(t *testing.T) {
+	d := redis.NewDedup(newTestClient(t))
+	ctx := context.Background()
+
+	for i, want := range []bool{true, false} {
+		first, err := d.FirstSeen(ctx, "message:1", time.Minute)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if first != want {
+			t.Errorf("Call %d: expected %v, got %v", i+1, want, first)
+		}
+	}
+	if seen, err := d.Seen(ctx, "message:1"); err != nil || !seen {
+		t.Errorf("Expected the recorded key to be seen, got %v, %v", seen, err)
+	}
+	if seen, _ := d.Seen(ctx, "message:2"); seen {
+		t.Error("Expected a new key not to be seen")
+	}
+}
+
+func TestSessionStore_RoundTrip(t *testing.T) {
+	store := redis.NewSessionStore(newTestClient(t))
+	ctx := context.Background()
+
+	conv := &domain.Conversation{UserID: "user1", Flow: "join", Step: 1, ExpiresAt: time.Now().Add(time.Minute)}
+	if err := store.SaveConversation(ctx, conv); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := store.GetConversation(ctx, "user1")
+	if err != nil || got == nil || got.Flow != "join" {
+		t.Fatalf("Expected the saved conversation, got %+v, %v", got, err)
+	}
+	if err := store.DeleteConversation(ctx, "user1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, _ := store.GetConversation(ctx, "user1"); got != nil {
+		t.Errorf("Expected no conversation after delete, got %+v", got)
+	}
+}
@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// ReportRepository caches reports in Redis in front of another
+// ReportRepository, like cache.ReportRepository but shared by every
+// process, so a #lapor handled by one worker shows on the leaderboard of
+// the others right away. Every key contains a generation number that each
+// write bumps, which drops the whole cache at once; old keys expire after
+// the TTL. When Redis is unavailable, reads go to the database.
+type ReportRepository struct {
+	domain.ReportRepository
+	client *Client
+	ttl    time.Duration
+}
+
+func NewReportRepository(next domain.ReportRepository, client *Client, ttl time.Duration) *ReportRepository {
+	return &ReportRepository{ReportRepository: next, client: client, ttl: ttl}
+}
+
+// generation returns the current generation number, "" when Redis cannot
+// be reached.
+func (r *ReportRepository) generation(ctx context.Context) string {
+	gen, err := r.client.rdb.Get(ctx, r.client.key("reports", "gen")).Int64()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		log.Printf("Report cache unavailable: %v", err)
+		return ""
+	}
+	return strconv.FormatInt(gen, 10)
+}
+
+// cached reads key into v, or fills it with read and stores the result.
+func (r *ReportRepository) cached(ctx context.Context, v interface{}, read func() error, parts ...string) error {
+	gen := r.generation(ctx)
+	if gen == "" {
+		return read()
+	}
+	key := r.client.key(append([]string{"reports", gen}, parts...)...)
+	if data, err := r.client.rdb.Get(ctx, key).Bytes(); err == nil && json.Unmarshal(data, v) == nil {
+		return nil
+	}
+
+	if err := read(); err != nil {
+		return err
+	}
+	if data, err := json.Marshal(v); err == nil {
+		if err := r.client.rdb.Set(ctx, key, data, r.ttl).Err(); err != nil {
+			log.Printf("Failed to cache reports: %v", err)
+		}
+	}
+	return nil
+}
+
+func (r *ReportRepository) GetReport(ctx context.Context, userID string) (*domain.Report, error) {
+	var report *domain.Report
+	err := r.cached(ctx, &report, func() (err error) {
+		report, err = r.ReportRepository.GetReport(ctx, userID)
+		return err
+	}, "user", userID)
+	return report, err
+}
+
+func (r *ReportRepository) GetAllReports(ctx context.Context) ([]*domain.Report, error) {
+	var reports []*domain.Report
+	err := r.cached(ctx, &reports, func() (err error) {
+		reports, err = r.ReportRepository.GetAllReports(ctx)
+		return err
+	}, "all")
+	return reports, err
+}
+
+func (r *ReportRepository) UpsertReport(ctx context.Context, report *domain.Report) error {
+	defer r.Invalidate(ctx)
+	return r.ReportRepository.UpsertReport(ctx, report)
+}
+
+func (r *ReportRepository) SubmitReport(ctx context.Context, userID, name string, at time.Time) (*domain.Report, error) {
+	defer r.Invalidate(ctx)
+	return r.ReportRepository.SubmitReport(ctx, userID, name, at)
+}
+
+func (r *ReportRepository) DeleteReport(ctx context.Context, userID string) error {
+	defer r.Invalidate(ctx)
+	return r.ReportRepository.DeleteReport(ctx, userID)
+}
+
+// Invalidate drops everything for every process, e.g. after a bulk change.
+func (r *ReportRepository) Invalidate(ctx context.Context) {
+	if err := r.client.rdb.Incr(context.WithoutCancel(ctx), r.client.key("reports", "gen")).Err(); err != nil {
+		log.Printf("Failed to clear the report cache: %v", err)
+	}
+}
+
+// Transactor drops the report cache after every transaction, see
+// cache.Transactor.
+type Transactor struct {
+	domain.Transactor
+	reports *ReportRepository
+}
+
+func NewTransactor(next domain.Transactor, reports *ReportRepository) *Transactor {
+	return &Transactor{Transactor: next, reports: reports}
+}
+
+func (t *Transactor) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	defer t.reports.Invalidate(ctx)
+	return t.Transactor.WithTx(ctx, fn)
+}
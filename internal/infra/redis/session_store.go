@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// SessionStore keeps conversations in Redis so several bot instances share
+// them. Keys expire together with the conversation.
+type SessionStore struct {
+	client *Client
+}
+
+func NewSessionStore(client *Client) *SessionStore {
+	return &SessionStore{client: client}
+}
+
+func (s *SessionStore) GetConversation(ctx context.Context, userID string) (*domain.Conversation, error) {
+	data, err := s.client.rdb.Get(ctx, s.client.key("conversation", userID)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var conv domain.Conversation
+	if err := json.Unmarshal(data, &conv); err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+func (s *SessionStore) SaveConversation(ctx context.Context, conv *domain.Conversation) error {
+	data, err := json.Marshal(conv)
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Until(conv.ExpiresAt)
+	if ttl <= 0 {
+		return s.DeleteConversation(ctx, conv.UserID)
+	}
+	return s.client.rdb.Set(ctx, s.client.key("conversation", conv.UserID), data, ttl).Err()
+}
+
+func (s *SessionStore) DeleteConversation(ctx context.Context, userID string) error {
+	return s.client.rdb.Del(ctx, s.client.key("conversation", userID)).Err()
+}
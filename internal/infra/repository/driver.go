@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// Driver opens every repository on one storage backend. Drivers register
+// themselves by name and are chosen with DB_DRIVER. A new driver must pass
+// the conformance suite in storetest, which spells out the behavior the
+// use cases rely on, e.g. which getters return nil instead of an error.
+type Driver interface {
+	// Open returns the repositories with their tables ready to use.
+	Open(cfg config.Config) (*Repositories, error)
+	// OpenTenants returns only the tenant registry of "bot tenants".
+	OpenTenants(cfg config.Config) (domain.TenantRepository, error)
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Driver)
+)
+
+// Register makes a driver available under name. It panics when the name is
+// taken, like database/sql.Register.
+func Register(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if driver == nil {
+		panic("repository: Register driver is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("repository: Register called twice for driver " + name)
+	}
+	drivers[name] = driver
+}
+
+// Drivers returns the names of the registered drivers, sorted.
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens the repositories of the driver in cfg.DBDriver.
+func Open(cfg config.Config) (*Repositories, error) {
+	driver, err := driverFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return driver.Open(cfg)
+}
+
+// OpenTenants opens the tenant registry of the driver in cfg.DBDriver.
+func OpenTenants(cfg config.Config) (domain.TenantRepository, error) {
+	driver, err := driverFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return driver.OpenTenants(cfg)
+}
+
+func driverFor(cfg config.Config) (Driver, error) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	driver, ok := drivers[cfg.DBDriver]
+	if !ok {
+		names := make([]string, 0, len(drivers))
+		for name := range drivers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown DB_DRIVER %q, available: %s", cfg.DBDriver, strings.Join(names, ", "))
+	}
+	return driver, nil
+}
@@ -0,0 +1,109 @@
+package repository_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/repository"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/repository/storetest"
+)
+
+// =============================================================================
+// DRIVER TESTS
+// =============================================================================
+
+func TestDrivers_ListsBuiltInDrivers(t *testing.T) {
+	if got := repository.Drivers(); !reflect.DeepEqual(got, []string{"mysql", "postgres", "sqlite", "supabase"}) {
+		t.Errorf("Expected the mysql, postgres, sqlite and supabase drivers, got %v", got)
+	}
+}
+
+func TestOpen_UnknownDriver(t *testing.T) {
+	_, err := repository.Open(config.Config{DBDriver: "oracle"})
+	if err == nil || !strings.Contains(err.Error(), "available: mysql, postgres, sqlite, supabase") {
+		t.Errorf("Expected an error listing the drivers, got %v", err)
+	}
+}
+
+func TestSQLiteDriver_Conformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) config.Config {
+		return config.Config{DBDriver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "bot.db"), DBMaxOpenConns: 1}
+	})
+}
+
+// The Supabase driver needs a project with the tables of its doc comments;
+// it runs when SUPABASE_TEST_URL and SUPABASE_TEST_KEY point at one.
+func TestSupabaseDriver_Conformance(t *testing.T) {
+	url, key := os.Getenv("SUPABASE_TEST_URL"), os.Getenv("SUPABASE_TEST_KEY")
+	if url == "" || key == "" {
+		t.Skip("SUPABASE_TEST_URL and SUPABASE_TEST_KEY not set")
+	}
+	storetest.Run(t, func(t *testing.T) config.Config {
+		return config.Config{DBDriver: "supabase", SupabaseURL: url, SupabaseKey: key}
+	})
+}
+
+func TestPostgresDriver_NeedsDatabaseURL(t *testing.T) {
+	_, err := repository.Open(config.Config{DBDriver: "postgres"})
+	if err == nil || !strings.Contains(err.Error(), "DATABASE_URL") {
+		t.Errorf("Expected an error asking for DATABASE_URL, got %v", err)
+	}
+}
+
+// The Postgres driver creates its own tables; it runs when
+// POSTGRES_TEST_URL points at a database set aside for tests.
+func TestPostgresDriver_Conformance(t *testing.T) {
+	url := os.Getenv("POSTGRES_TEST_URL")
+	if url == "" {
+		t.Skip("POSTGRES_TEST_URL not set")
+	}
+	storetest.Run(t, func(t *testing.T) config.Config {
+		return config.Config{DBDriver: "postgres", DatabaseURL: url}
+	})
+}
+
+// The same database as its own replica, so the reads that go there are
+// checked too
+func TestPostgresDriver_ReadReplica(t *testing.T) {
+	url := os.Getenv("POSTGRES_TEST_URL")
+	if url == "" {
+		t.Skip("POSTGRES_TEST_URL not set")
+	}
+	storetest.Run(t, func(t *testing.T) config.Config {
+		return config.Config{DBDriver: "postgres", DatabaseURL: url, DatabaseReadURL: url}
+	})
+}
+
+func TestMySQLDriver_NeedsDatabaseURL(t *testing.T) {
+	_, err := repository.Open(config.Config{DBDriver: "mysql"})
+	if err == nil || !strings.Contains(err.Error(), "DATABASE_URL") {
+		t.Errorf("Expected an error asking for DATABASE_URL, got %v", err)
+	}
+}
+
+// The MySQL driver creates its own tables; it runs when MYSQL_TEST_URL is
+// the DSN of a database set aside for tests, e.g.
+// bot:pass@tcp(localhost:3306)/lapor_test.
+func TestMySQLDriver_Conformance(t *testing.T) {
+	dsn := os.Getenv("MYSQL_TEST_URL")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_URL not set")
+	}
+	storetest.Run(t, func(t *testing.T) config.Config {
+		return config.Config{DBDriver: "mysql", DatabaseURL: dsn}
+	})
+}
+
+func TestMySQLDriver_ReadReplica(t *testing.T) {
+	dsn := os.Getenv("MYSQL_TEST_URL")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_URL not set")
+	}
+	storetest.Run(t, func(t *testing.T) config.Config {
+		return config.Config{DBDriver: "mysql", DatabaseURL: dsn, DatabaseReadURL: dsn}
+	})
+}
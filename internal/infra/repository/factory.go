@@ -2,39 +2,125 @@ package repository
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
 	"log"
+	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/config"
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
-	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
-	"github.com/fardannozami/whatsapp-gateway/internal/infra/supabase"
-	supa "github.com/nedpals/supabase-go"
-	_ "modernc.org/sqlite"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/cache"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/redis"
 )
 
-func NewReportRepository(cfg config.Config) domain.ReportRepository {
-	// Use Supabase if configured, otherwise fall back to SQLite
-	if cfg.SupabaseURL != "" && cfg.SupabaseKey != "" {
-		log.Println("Using Supabase database")
-		client := supa.CreateClient(cfg.SupabaseURL, cfg.SupabaseKey)
-		return supabase.NewReportRepository(client)
-	}
+// Repositories groups every repository backed by the same database.
+type Repositories struct {
+	Reports    domain.ReportRepository
+	Activities domain.ActivityRepository
+	Events     domain.ReportEventRepository // report history; Reports holds its projection
+	Settings   domain.SettingsRepository
+	Messages   domain.MessageArchiveRepository
+	Groups     domain.GroupRepository
+	Admins     domain.AdminAccountRepository
+	APIKeys    domain.APIKeyRepository
+	Jobs       domain.JobRepository
+	Outbox     domain.OutboxRepository
+	Identities domain.IdentityRepository
+	Prompts    domain.PromptRepository
+	Quotes     domain.QuoteRepository
+	Snapshots  domain.SnapshotRepository // the standings at the end of each day
+	// Backup copies the database for BACKUP_SCHEDULE. Nil on Supabase,
+	// which is backed up by Supabase itself.
+	Backup domain.DatabaseBackup
+	// Tx makes report and activity-log writes atomic. Nil on Supabase,
+	// whose REST API has no transactions.
+	Tx domain.Transactor
+	// Sessions holds open conversations: the database, or Redis when
+	// REDIS_URL is set
+	Sessions domain.SessionStore
+	// Dedup remembers the IDs of handled messages: in memory, or Redis
+	// when REDIS_URL is set
+	Dedup domain.DedupStore
+	// Redis is shared by every process of the deployment. Nil unless
+	// REDIS_URL is set.
+	Redis *redis.Client
+}
 
-	log.Println("Using SQLite database")
-	// Enable WAL mode and busy timeout to avoid "database is locked" errors
-	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", cfg.SQLitePath)
-	db, err := sql.Open("sqlite", dsn)
+// NewRepositories opens the repositories of the DB_DRIVER storage driver,
+// exiting if it cannot.
+func NewRepositories(cfg config.Config) *Repositories {
+	repos, err := Open(cfg)
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
+	return repos
+}
+
+// NewTenantRepository opens the tenant registry of "bot tenants" in the
+// database of the environment's configuration, not of any tenant.
+func NewTenantRepository(cfg config.Config) domain.TenantRepository {
+	tenants, err := OpenTenants(cfg)
+	if err != nil {
+		log.Fatalf("Failed to open tenant registry: %v", err)
+	}
+	if err := tenants.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init tenants table: %v", err)
+	}
+	return tenants
+}
+
+// connectRedis connects to REDIS_URL, exiting if it cannot. It returns nil
+// when REDIS_URL is empty and everything stays in the process.
+func connectRedis(cfg config.Config) *redis.Client {
+	if cfg.RedisURL == "" {
+		return nil
+	}
+	client, err := redis.Connect(cfg.RedisURL, cfg.Tenant)
+	if err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	log.Println("Using Redis for conversations, caches, rate limits and dedup")
+	return client
+}
 
-	repo := sqlite.NewReportRepository(db)
-	// Initialize table if needed
-	if err := repo.InitTable(context.Background()); err != nil {
-		log.Printf("Failed to init table: %v", err)
+// cachedReports puts the report cache in front of the database unless
+// REPORT_CACHE_TTL_SECONDS is 0: in Redis when it is set up, so every
+// process sees the same cache, in memory otherwise.
+func cachedReports(cfg config.Config, rdb *redis.Client, repo domain.ReportRepository) domain.ReportRepository {
+	if cfg.ReportCacheTTL <= 0 {
+		return repo
+	}
+	ttl := time.Duration(cfg.ReportCacheTTL) * time.Second
+	if rdb != nil {
+		return redis.NewReportRepository(repo, rdb, ttl)
 	}
+	return cache.NewReportRepository(repo, ttl)
+}
 
-	return repo
+// cachedTx drops the report cache after transactions, which write through
+// the database directly.
+func cachedTx(tx domain.Transactor, reports domain.ReportRepository) domain.Transactor {
+	switch c := reports.(type) {
+	case *cache.ReportRepository:
+		return cache.NewTransactor(tx, c)
+	case *redis.ReportRepository:
+		return redis.NewTransactor(tx, c)
+	}
+	return tx
+}
+
+// sessionStore returns the Redis session store when REDIS_URL is set, and
+// the database otherwise.
+func sessionStore(rdb *redis.Client, db domain.SessionStore) domain.SessionStore {
+	if rdb == nil {
+		return db
+	}
+	return redis.NewSessionStore(rdb)
+}
+
+// dedupStore remembers handled messages in Redis when REDIS_URL is set, and
+// in memory otherwise.
+func dedupStore(rdb *redis.Client) domain.DedupStore {
+	if rdb == nil {
+		return cache.NewDedup()
+	}
+	return redis.NewDedup(rdb)
 }
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/mysql"
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func init() {
+	Register("mysql", mysqlDriver{})
+}
+
+// mysqlDriver connects to the MySQL or MariaDB database in DATABASE_URL, a
+// Go MySQL DSN such as bot:pass@tcp(localhost:3306)/lapor, and creates its
+// tables on open. Like the postgres driver it keeps the WhatsApp session in
+// its SQLite file and leaves backups to mysqldump.
+type mysqlDriver struct{}
+
+func (mysqlDriver) Open(cfg config.Config) (*Repositories, error) {
+	log.Println("Using MySQL database")
+	db, err := openMySQL(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	myReports := mysql.NewReportRepository(db)
+	activities := mysql.NewActivityRepository(db)
+	if cfg.DatabaseReadURL != "" {
+		log.Println("Using MySQL read replica for leaderboards and dashboards")
+		replica, err := openMySQLDSN(cfg.DatabaseReadURL)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		myReports.SetReadReplica(replica)
+		activities.SetReadReplica(replica)
+	}
+	conversations := mysql.NewConversationRepository(db)
+	rdb := connectRedis(cfg)
+	reports := cachedReports(cfg, rdb, myReports)
+	repos := &Repositories{
+		Reports:    reports,
+		Activities: activities,
+		Events:     mysql.NewReportEventRepository(db),
+		Settings:   mysql.NewSettingsRepository(db),
+		Messages:   mysql.NewMessageArchiveRepository(db),
+		Groups:     mysql.NewGroupRepository(db),
+		Admins:     mysql.NewAdminAccountRepository(db),
+		APIKeys:    mysql.NewAPIKeyRepository(db),
+		Jobs:       mysql.NewJobRepository(db),
+		Outbox:     mysql.NewOutboxRepository(db),
+		Identities: mysql.NewIdentityRepository(db),
+		Prompts:    mysql.NewPromptRepository(db),
+		Quotes:     mysql.NewQuoteRepository(db),
+		Snapshots:  mysql.NewSnapshotRepository(db),
+		Sessions:   sessionStore(rdb, conversations),
+		Dedup:      dedupStore(rdb),
+		Redis:      rdb,
+		Tx:         cachedTx(mysql.NewTransactor(db), reports),
+	}
+
+	// The report events start from user_reports, so it goes first
+	ctx := context.Background()
+	tables := []struct {
+		name string
+		init func(context.Context) error
+	}{
+		{"reports", repos.Reports.InitTable},
+		{"report events", repos.Events.InitTable},
+		{"activity", repos.Activities.InitTable},
+		{"settings", repos.Settings.InitTable},
+		{"message archive", repos.Messages.InitTable},
+		{"groups", repos.Groups.InitTable},
+		{"admin accounts", repos.Admins.InitTable},
+		{"API keys", repos.APIKeys.InitTable},
+		{"scheduled jobs", repos.Jobs.InitTable},
+		{"outbox", repos.Outbox.InitTable},
+		{"linked identities", repos.Identities.InitTable},
+		{"report prompts", repos.Prompts.InitTable},
+		{"quote rotation", repos.Quotes.InitTable},
+		{"leaderboard snapshots", repos.Snapshots.InitTable},
+		{"conversations", conversations.InitTable},
+	}
+	for _, table := range tables {
+		if err := table.init(ctx); err != nil {
+			log.Printf("Failed to init %s table: %v", table.name, err)
+		}
+	}
+	return repos, nil
+}
+
+func (mysqlDriver) OpenTenants(cfg config.Config) (domain.TenantRepository, error) {
+	db, err := openMySQL(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return mysql.NewTenantRepository(db), nil
+}
+
+// openMySQL opens cfg.DatabaseURL and checks that the server answers.
+func openMySQL(cfg config.Config) (*sql.DB, error) {
+	if cfg.DatabaseURL == "" {
+		return nil, errors.New("DB_DRIVER=mysql needs DATABASE_URL")
+	}
+	return openMySQLDSN(cfg.DatabaseURL)
+}
+
+func openMySQLDSN(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(context.Background()); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/postgres"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func init() {
+	Register("postgres", postgresDriver{})
+}
+
+// postgresDriver connects to the Postgres database in DATABASE_URL and
+// creates its tables on open, with the same columns as the Supabase ones.
+// The WhatsApp session stays in its SQLite file, and backups are left to
+// pg_dump.
+type postgresDriver struct{}
+
+func (postgresDriver) Open(cfg config.Config) (*Repositories, error) {
+	log.Println("Using Postgres database")
+	db, err := openPostgres(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pgReports := postgres.NewReportRepository(db)
+	activities := postgres.NewActivityRepository(db)
+	if cfg.DatabaseReadURL != "" {
+		log.Println("Using Postgres read replica for leaderboards and dashboards")
+		replica, err := openPostgresURL(cfg.DatabaseReadURL)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		pgReports.SetReadReplica(replica)
+		activities.SetReadReplica(replica)
+	}
+	conversations := postgres.NewConversationRepository(db)
+	rdb := connectRedis(cfg)
+	reports := cachedReports(cfg, rdb, pgReports)
+	repos := &Repositories{
+		Reports:    reports,
+		Activities: activities,
+		Events:     postgres.NewReportEventRepository(db),
+		Settings:   postgres.NewSettingsRepository(db),
+		Messages:   postgres.NewMessageArchiveRepository(db),
+		Groups:     postgres.NewGroupRepository(db),
+		Admins:     postgres.NewAdminAccountRepository(db),
+		APIKeys:    postgres.NewAPIKeyRepository(db),
+		Jobs:       postgres.NewJobRepository(db),
+		Outbox:     postgres.NewOutboxRepository(db),
+		Identities: postgres.NewIdentityRepository(db),
+		Prompts:    postgres.NewPromptRepository(db),
+		Quotes:     postgres.NewQuoteRepository(db),
+		Snapshots:  postgres.NewSnapshotRepository(db),
+		Sessions:   sessionStore(rdb, conversations),
+		Dedup:      dedupStore(rdb),
+		Redis:      rdb,
+		Tx:         cachedTx(postgres.NewTransactor(db), reports),
+	}
+
+	// The report events start from user_reports, so it goes first
+	ctx := context.Background()
+	tables := []struct {
+		name string
+		init func(context.Context) error
+	}{
+		{"reports", repos.Reports.InitTable},
+		{"report events", repos.Events.InitTable},
+		{"activity", repos.Activities.InitTable},
+		{"settings", repos.Settings.InitTable},
+		{"message archive", repos.Messages.InitTable},
+		{"groups", repos.Groups.InitTable},
+		{"admin accounts", repos.Admins.InitTable},
+		{"API keys", repos.APIKeys.InitTable},
+		{"scheduled jobs", repos.Jobs.InitTable},
+		{"outbox", repos.Outbox.InitTable},
+		{"linked identities", repos.Identities.InitTable},
+		{"report prompts", repos.Prompts.InitTable},
+		{"quote rotation", repos.Quotes.InitTable},
+		{"leaderboard snapshots", repos.Snapshots.InitTable},
+		{"conversations", conversations.InitTable},
+	}
+	for _, table := range tables {
+		if err := table.init(ctx); err != nil {
+			log.Printf("Failed to init %s table: %v", table.name, err)
+		}
+	}
+	return repos, nil
+}
+
+func (postgresDriver) OpenTenants(cfg config.Config) (domain.TenantRepository, error) {
+	db, err := openPostgres(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return postgres.NewTenantRepository(db), nil
+}
+
+// openPostgres opens cfg.DatabaseURL and checks that the server answers.
+func openPostgres(cfg config.Config) (*sql.DB, error) {
+	if cfg.DatabaseURL == "" {
+		return nil, errors.New("DB_DRIVER=postgres needs DATABASE_URL")
+	}
+	return openPostgresURL(cfg.DatabaseURL)
+}
+
+func openPostgresURL(url string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(context.Background()); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	Register("sqlite", sqliteDriver{})
+}
+
+// sqliteDriver keeps everything in the SQLITE_PATH file, next to the
+// WhatsApp session, and creates or migrates its tables on open.
+type sqliteDriver struct{}
+
+func (sqliteDriver) Open(cfg config.Config) (*Repositories, error) {
+	log.Println("Using SQLite database")
+	db, err := openSQLite(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	conversations := sqlite.NewConversationRepository(db)
+	reportRepo := sqlite.NewReportRepository(db)
+	reportRepo.SetUserIDHasher(phone.NewHasher(cfg.UserIDSalt))
+	rdb := connectRedis(cfg)
+	reports := cachedReports(cfg, rdb, reportRepo)
+	repos := &Repositories{
+		Reports:    reports,
+		Activities: sqlite.NewActivityRepository(db),
+		Events:     sqlite.NewReportEventRepository(db),
+		Settings:   sqlite.NewSettingsRepository(db),
+		Messages:   sqlite.NewMessageArchiveRepository(db),
+		Groups:     sqlite.NewGroupRepository(db),
+		Admins:     sqlite.NewAdminAccountRepository(db),
+		APIKeys:    sqlite.NewAPIKeyRepository(db),
+		Jobs:       sqlite.NewJobRepository(db),
+		Outbox:     sqlite.NewOutboxRepository(db),
+		Identities: sqlite.NewIdentityRepository(db),
+		Prompts:    sqlite.NewPromptRepository(db),
+		Quotes:     sqlite.NewQuoteRepository(db),
+		Snapshots:  sqlite.NewSnapshotRepository(db),
+		Backup:     sqlite.NewBackup(db),
+		Sessions:   sessionStore(rdb, conversations),
+		Dedup:      dedupStore(rdb),
+		Redis:      rdb,
+		Tx:         cachedTx(sqlite.NewTransactor(db), reports),
+	}
+
+	// Initialize tables if needed
+	if err := repos.Reports.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init table: %v", err)
+	}
+	if err := repos.Events.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init report events table: %v", err)
+	}
+	if err := repos.Activities.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init activity table: %v", err)
+	}
+	if err := repos.Settings.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init settings table: %v", err)
+	}
+	if err := repos.Messages.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init message archive table: %v", err)
+	}
+	if err := repos.Groups.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init groups table: %v", err)
+	}
+	if err := repos.Admins.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init admin accounts table: %v", err)
+	}
+	if err := repos.APIKeys.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init API keys table: %v", err)
+	}
+	if err := repos.Jobs.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init scheduled jobs table: %v", err)
+	}
+	if err := repos.Outbox.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init outbox table: %v", err)
+	}
+	if err := repos.Identities.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init linked identities table: %v", err)
+	}
+	if err := repos.Prompts.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init report prompts table: %v", err)
+	}
+	if err := repos.Quotes.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init quote rotation table: %v", err)
+	}
+	if err := repos.Snapshots.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init leaderboard snapshots table: %v", err)
+	}
+	if err := conversations.InitTable(context.Background()); err != nil {
+		log.Printf("Failed to init conversations table: %v", err)
+	}
+
+	// Members stored under their number before USER_ID_SALT was set move
+	// to their hashed ID
+	moved, skipped, err := sqlite.RehashUserIDs(context.Background(), db, phone.NewHasher(cfg.UserIDSalt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash stored user IDs: %w", err)
+	}
+	if moved > 0 {
+		log.Printf("Moved %d rows from phone numbers to hashed user IDs", moved)
+	}
+	if skipped > 0 {
+		log.Printf("Warning: %d rows still use a phone number because the member already has rows under the hashed ID", skipped)
+	}
+
+	return repos, nil
+}
+
+func (sqliteDriver) OpenTenants(cfg config.Config) (domain.TenantRepository, error) {
+	db, err := openSQLite(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sqlite.NewTenantRepository(db), nil
+}
+
+// openSQLite opens cfg.SQLitePath.
+func openSQLite(cfg config.Config) (*sql.DB, error) {
+	// Enable WAL mode and busy timeout to avoid "database is locked" errors
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)", cfg.SQLitePath, cfg.DBBusyTimeoutMs)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer at a time. With a single connection, writes
+	// from the bot and the admin API queue up in Go instead of failing once
+	// the busy timeout runs out.
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	return db, nil
+}
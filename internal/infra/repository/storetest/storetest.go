@@ -0,0 +1,952 @@
+// Package storetest is the conformance suite of the storage drivers. It
+// spells out the repository behavior the use cases rely on, so a new
+// driver is done when Run passes against it instead of when it looks like
+// the SQLite code.
+//
+// The suite only touches rows with IDs it made up for the run, so it can
+// run against a shared test database; it never cleans up the rows of
+// other runs.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/repository"
+)
+
+// Run opens the driver of the config returned by open, once per subtest,
+// and checks every repository.
+func Run(t *testing.T, open func(t *testing.T) config.Config) {
+	suites := []struct {
+		name string
+		run  func(t *testing.T, s *suite)
+	}{
+		{"Reports", testReports},
+		{"ListReports", testListReports},
+		{"Activities", testActivities},
+		{"Events", testEvents},
+		{"Settings", testSettings},
+		{"Messages", testMessages},
+		{"Groups", testGroups},
+		{"Admins", testAdmins},
+		{"APIKeys", testAPIKeys},
+		{"Jobs", testJobs},
+		{"Outbox", testOutbox},
+		{"Identities", testIdentities},
+		{"Prompts", testPrompts},
+		{"Quotes", testQuotes},
+		{"Snapshots", testSnapshots},
+		{"Sessions", testSessions},
+		{"Tx", testTx},
+		{"Tenants", testTenants},
+	}
+	for _, tc := range suites {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := open(t)
+			repos, err := repository.Open(cfg)
+			if err != nil {
+				t.Fatalf("Failed to open driver %q: %v", cfg.DBDriver, err)
+			}
+			tc.run(t, &suite{cfg: cfg, repos: repos, prefix: fmt.Sprintf("st%d-", time.Now().UnixNano())})
+		})
+	}
+}
+
+type suite struct {
+	cfg    config.Config
+	repos  *repository.Repositories
+	prefix string
+}
+
+// id returns an ID unique to this run.
+func (s *suite) id(name string) string {
+	return s.prefix + name
+}
+
+// day is a fixed local time n days after the start of the suite's calendar,
+// truncated to seconds like every driver stores it.
+func day(n int, hour int) time.Time {
+	return time.Date(2024, time.March, 1+n, hour, 0, 0, 0, time.Local)
+}
+
+func sameTime(t *testing.T, what string, got, want time.Time) {
+	t.Helper()
+	if !got.Equal(want) {
+		t.Errorf("Expected %s %s, got %s", what, want, got)
+	}
+}
+
+func testReports(t *testing.T, s *suite) {
+	ctx := context.Background()
+	reports := s.repos.Reports
+	userID := s.id("628111")
+
+	got, err := reports.GetReport(ctx, userID)
+	if err != nil || got != nil {
+		t.Fatalf("Expected nil for a member without report, got %+v, %v", got, err)
+	}
+
+	report := &domain.Report{UserID: userID, Name: "Budi", Streak: 2, BestStreak: 5, ActivityCount: 5, LastReportDate: day(3, 7), FirstReportDate: day(0, 7)}
+	if err := reports.UpsertReport(ctx, report); err != nil {
+		t.Fatalf("Failed to insert report: %v", err)
+	}
+	if report.Version != 1 {
+		t.Errorf("Expected version 1 after insert, got %d", report.Version)
+	}
+	if err := reports.UpsertReport(ctx, &domain.Report{UserID: userID, Name: "Other"}); !errors.Is(err, domain.ErrReportConflict) {
+		t.Errorf("Expected ErrReportConflict inserting an existing row, got %v", err)
+	}
+
+	got, err = reports.GetReport(ctx, userID)
+	if err != nil || got == nil {
+		t.Fatalf("Failed to get report: %+v, %v", got, err)
+	}
+	if got.Name != "Budi" || got.Streak != 2 || got.BestStreak != 5 || got.ActivityCount != 5 || got.Version != 1 {
+		t.Errorf("Expected the inserted row, got %+v", got)
+	}
+	sameTime(t, "last report date", got.LastReportDate, day(3, 7))
+	sameTime(t, "first report date", got.FirstReportDate, day(0, 7))
+
+	stale := *got
+	got.Streak = 3
+	if err := reports.UpsertReport(ctx, got); err != nil {
+		t.Fatalf("Failed to update report: %v", err)
+	}
+	if got.Version != 2 {
+		t.Errorf("Expected version 2 after update, got %d", got.Version)
+	}
+	stale.Streak = 9
+	if err := reports.UpsertReport(ctx, &stale); !errors.Is(err, domain.ErrReportConflict) {
+		t.Errorf("Expected ErrReportConflict updating a stale version, got %v", err)
+	}
+
+	// SubmitReport follows ApplyReport
+	next, err := reports.SubmitReport(ctx, userID, "Budi", day(4, 8))
+	if err != nil {
+		t.Fatalf("Failed to submit report: %v", err)
+	}
+	if next.Streak != 4 || next.BestStreak != 5 || next.ActivityCount != 6 {
+		t.Errorf("Expected streak 4, best 5 and 6 reports the next day, got %+v", next)
+	}
+	if _, err := reports.SubmitReport(ctx, userID, "Budi", day(4, 20)); !errors.Is(err, domain.ErrAlreadyReported) {
+		t.Errorf("Expected ErrAlreadyReported on the same day, got %v", err)
+	}
+	next, err = reports.SubmitReport(ctx, userID, "Budi", day(7, 8))
+	if err != nil {
+		t.Fatalf("Failed to submit report: %v", err)
+	}
+	if next.Streak != 1 || next.BestStreak != 5 || next.ActivityCount != 7 {
+		t.Errorf("Expected the streak to start over after a missed day, got %+v", next)
+	}
+
+	first, err := reports.SubmitReport(ctx, s.id("628222"), "Sari", day(1, 6))
+	if err != nil {
+		t.Fatalf("Failed to submit a first report: %v", err)
+	}
+	if first.Streak != 1 || first.BestStreak != 1 || first.ActivityCount != 1 {
+		t.Errorf("Expected a first report to start at 1, got %+v", first)
+	}
+	sameTime(t, "first report date", first.FirstReportDate, day(1, 6))
+
+	all, err := reports.GetAllReports(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get all reports: %v", err)
+	}
+	if n := countPrefixed(s, reportIDs(all)); n != 2 {
+		t.Errorf("Expected both reports in GetAllReports, got %d", n)
+	}
+
+	if err := reports.DeleteReport(ctx, userID); err != nil {
+		t.Fatalf("Failed to delete report: %v", err)
+	}
+	if got, err := reports.GetReport(ctx, userID); err != nil || got != nil {
+		t.Errorf("Expected the report deleted, got %+v, %v", got, err)
+	}
+
+	if phone := reports.ResolveLIDToPhone(ctx, s.id("lid")); phone != s.id("lid") {
+		t.Errorf("Expected an unknown LID returned as is, got %q", phone)
+	}
+}
+
+func testListReports(t *testing.T, s *suite) {
+	ctx := context.Background()
+	rows := []*domain.Report{
+		{UserID: s.id("a"), Name: "Citra", Streak: 3, ActivityCount: 10, LastReportDate: day(5, 7)},
+		{UserID: s.id("b"), Name: "Adi", Streak: 5, ActivityCount: 10, LastReportDate: day(2, 7)},
+		{UserID: s.id("c"), Name: "Bayu", Streak: 1, ActivityCount: 4, LastReportDate: day(9, 7)},
+	}
+	var ids []string
+	for _, r := range rows {
+		if err := s.repos.Reports.UpsertReport(ctx, r); err != nil {
+			t.Fatalf("Failed to insert report: %v", err)
+		}
+		ids = append(ids, r.UserID)
+	}
+
+	list := func(q domain.ReportQuery) []string {
+		t.Helper()
+		q.UserIDs = ids
+		got, err := s.repos.Reports.ListReports(ctx, q)
+		if err != nil {
+			t.Fatalf("Failed to list reports: %v", err)
+		}
+		return reportIDs(got)
+	}
+	expectIDs(t, "by total", list(domain.ReportQuery{}), s.id("a"), s.id("b"), s.id("c"))
+	expectIDs(t, "by streak", list(domain.ReportQuery{Sort: domain.ReportSortStreak}), s.id("b"), s.id("a"), s.id("c"))
+	expectIDs(t, "by name", list(domain.ReportQuery{Sort: domain.ReportSortName}), s.id("b"), s.id("c"), s.id("a"))
+	expectIDs(t, "second page", list(domain.ReportQuery{After: rows[0], Limit: 1}), s.id("b"))
+	expectIDs(t, "in period", list(domain.ReportQuery{Since: day(2, 7), Until: day(9, 7)}), s.id("a"), s.id("b"))
+
+	got, err := s.repos.Reports.ListReports(ctx, domain.ReportQuery{UserIDs: []string{}})
+	if err != nil || len(got) != 0 {
+		t.Errorf("Expected nothing for an empty member list, got %d, %v", len(got), err)
+	}
+}
+
+func testActivities(t *testing.T, s *suite) {
+	ctx := context.Background()
+	activities := s.repos.Activities
+	userID := s.id("628111")
+	for i, kind := range []string{"lari", "sepeda", "lari"} {
+		a := &domain.Activity{UserID: userID, Name: "Budi", ActivityType: kind, DurationMinutes: 30, DistanceKm: 5.5, Message: "#lapor", ReportedAt: day(2-i, 7)}
+		if err := activities.AddActivity(ctx, a); err != nil {
+			t.Fatalf("Failed to add activity: %v", err)
+		}
+	}
+
+	got, err := activities.GetActivities(ctx, domain.ActivityFilter{UserID: userID})
+	if err != nil {
+		t.Fatalf("Failed to get activities: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 activities, got %d", len(got))
+	}
+	sameTime(t, "oldest activity first", got[0].ReportedAt, day(0, 7))
+	if got[0].DistanceKm != 5.5 || got[0].DurationMinutes != 30 || got[0].Message != "#lapor" {
+		t.Errorf("Expected the stored activity, got %+v", got[0])
+	}
+
+	got, err = activities.GetActivities(ctx, domain.ActivityFilter{UserID: userID, ActivityType: "lari", Since: day(1, 0)})
+	if err != nil || len(got) != 1 {
+		t.Errorf("Expected 1 run since day 1, got %d, %v", len(got), err)
+	}
+
+	if err := activities.DeleteActivities(ctx, domain.ActivityFilter{UserID: userID, Until: day(1, 7)}); err != nil {
+		t.Fatalf("Failed to delete activities: %v", err)
+	}
+	got, err = activities.GetActivities(ctx, domain.ActivityFilter{UserID: userID})
+	if err != nil || len(got) != 2 {
+		t.Errorf("Expected 2 activities left, got %d, %v", len(got), err)
+	}
+}
+
+func testEvents(t *testing.T, s *suite) {
+	ctx := context.Background()
+	events := s.repos.Events
+	userID := s.id("628111")
+
+	submitted := &domain.ReportEvent{UserID: userID, Type: domain.ReportSubmitted, At: day(0, 7), Name: "Budi", Actor: "member"}
+	if err := events.AppendEvent(ctx, submitted); err != nil {
+		t.Fatalf("Failed to append event: %v", err)
+	}
+	if submitted.ID == 0 {
+		t.Fatal("Expected AppendEvent to set the ID")
+	}
+	revoked := &domain.ReportEvent{UserID: userID, Type: domain.ReportRevoked, At: day(1, 7), RefID: submitted.ID, Actor: "admin", Note: "salah kirim"}
+	if err := events.AppendEvent(ctx, revoked); err != nil {
+		t.Fatalf("Failed to append event: %v", err)
+	}
+
+	got, err := events.GetEvents(ctx, userID)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Expected 2 events, got %d, %v", len(got), err)
+	}
+	if got[0].ID != submitted.ID || got[1].RefID != submitted.ID || got[1].Note != "salah kirim" {
+		t.Errorf("Expected the events oldest first, got %+v, %+v", got[0], got[1])
+	}
+
+	ids, err := events.EventUserIDs(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list event users: %v", err)
+	}
+	if n := countPrefixed(s, ids); n != 1 {
+		t.Errorf("Expected the member once in EventUserIDs, got %d", n)
+	}
+
+	if err := events.DeleteEvents(ctx, userID); err != nil {
+		t.Fatalf("Failed to delete events: %v", err)
+	}
+	if got, err := events.GetEvents(ctx, userID); err != nil || len(got) != 0 {
+		t.Errorf("Expected no events left, got %d, %v", len(got), err)
+	}
+}
+
+func testSettings(t *testing.T, s *suite) {
+	ctx := context.Background()
+	settings := s.repos.Settings
+
+	if got, err := settings.GetSettings(ctx, s.id("none")); err != nil || got != nil {
+		t.Fatalf("Expected nil for a member without settings, got %+v, %v", got, err)
+	}
+
+	saved := []*domain.UserSettings{
+		{UserID: s.id("reminder"), Target: 20, ReminderTime: "06:30", Timezone: "WIB", DisplayName: "Budi", JoinedAt: day(0, 9), NoMention: true},
+		{UserID: s.id("unranked"), Unranked: true, SnoozeUntil: day(5, 0), CalendarToken: s.id("calendar")},
+		{UserID: s.id("private"), Private: true, VerifyBy: day(1, 12), Email: "rina@contoh.com"},
+	}
+	for _, st := range saved {
+		if err := settings.SaveSettings(ctx, st); err != nil {
+			t.Fatalf("Failed to save settings: %v", err)
+		}
+	}
+	// Saving again overwrites
+	saved[0].Target = 30
+	if err := settings.SaveSettings(ctx, saved[0]); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+
+	got, err := settings.GetSettings(ctx, s.id("reminder"))
+	if err != nil || got == nil {
+		t.Fatalf("Failed to get settings: %+v, %v", got, err)
+	}
+	if got.Target != 30 || got.ReminderTime != "06:30" || got.Timezone != "WIB" || got.DisplayName != "Budi" || got.Unranked || got.Private || !got.NoMention {
+		t.Errorf("Expected the saved settings, got %+v", got)
+	}
+	sameTime(t, "joined at", got.JoinedAt, day(0, 9))
+	if !got.SnoozeUntil.IsZero() || !got.VerifyBy.IsZero() {
+		t.Errorf("Expected unset times to stay zero, got %+v", got)
+	}
+
+	reminders, err := settings.GetReminderSettings(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get reminder settings: %v", err)
+	}
+	expectIDs(t, "with a reminder", settingsIDs(s, reminders), s.id("reminder"))
+
+	unverified, err := settings.GetUnverifiedSettings(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get unverified settings: %v", err)
+	}
+	expectIDs(t, "unverified", settingsIDs(s, unverified), s.id("private"))
+
+	emails, err := settings.GetEmailSettings(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get email settings: %v", err)
+	}
+	expectIDs(t, "with an email", settingsIDs(s, emails), s.id("private"))
+	if len(emails) == 1 && emails[0].Email != "rina@contoh.com" {
+		t.Errorf("Expected the saved email, got %q", emails[0].Email)
+	}
+
+	byToken, err := settings.GetSettingsByCalendarToken(ctx, s.id("calendar"))
+	if err != nil || byToken == nil || byToken.UserID != s.id("unranked") {
+		t.Errorf("Expected the member with the calendar token, got %+v, %v", byToken, err)
+	}
+	for _, token := range []string{"", s.id("unknown")} {
+		if got, err := settings.GetSettingsByCalendarToken(ctx, token); err != nil || got != nil {
+			t.Errorf("Expected nil for calendar token %q, got %+v, %v", token, got, err)
+		}
+	}
+
+	unranked, err := settings.GetUnrankedSettings(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get unranked settings: %v", err)
+	}
+	ids := settingsIDs(s, unranked)
+	sort.Strings(ids)
+	expectIDs(t, "unranked", ids, s.id("private"), s.id("unranked"))
+
+	if err := settings.DeleteSettings(ctx, s.id("reminder")); err != nil {
+		t.Fatalf("Failed to delete settings: %v", err)
+	}
+	if got, err := settings.GetSettings(ctx, s.id("reminder")); err != nil || got != nil {
+		t.Errorf("Expected the settings deleted, got %+v, %v", got, err)
+	}
+}
+
+func testMessages(t *testing.T, s *suite) {
+	ctx := context.Background()
+	messages := s.repos.Messages
+	chatID := s.id("chat@g.us")
+
+	for i, text := range []string{"#lapor lari pagi", "selamat pagi semua", "#lapor sepeda sore"} {
+		msg := &domain.ArchivedMessage{MessageID: s.id(fmt.Sprint("msg", i)), ChatID: chatID, SenderID: s.id("628111"), SenderName: "Budi", Text: text, SentAt: day(i, 7)}
+		if err := messages.ArchiveMessage(ctx, msg); err != nil {
+			t.Fatalf("Failed to archive message: %v", err)
+		}
+	}
+	dup := &domain.ArchivedMessage{MessageID: s.id("msg0"), ChatID: chatID, SenderID: s.id("628111"), Text: "duplikat", SentAt: day(0, 7)}
+	if err := messages.ArchiveMessage(ctx, dup); err != nil {
+		t.Errorf("Expected archiving the same message twice to be a no-op, got %v", err)
+	}
+
+	got, err := messages.GetMessages(ctx, domain.MessageFilter{ChatID: chatID})
+	if err != nil || len(got) != 3 {
+		t.Fatalf("Expected 3 messages, got %d, %v", len(got), err)
+	}
+	if got[0].Text != "#lapor lari pagi" {
+		t.Errorf("Expected the first message kept and oldest first, got %q", got[0].Text)
+	}
+
+	found, err := messages.SearchMessages(ctx, "lapor", domain.MessageFilter{ChatID: chatID})
+	if err != nil || len(found) != 2 {
+		t.Fatalf("Expected 2 messages with lapor, got %d, %v", len(found), err)
+	}
+	if found[0].Text != "#lapor sepeda sore" {
+		t.Errorf("Expected search results newest first, got %q", found[0].Text)
+	}
+
+	if err := messages.DeleteMessages(ctx, domain.MessageFilter{ChatID: chatID, Until: day(1, 0)}); err != nil {
+		t.Fatalf("Failed to delete messages: %v", err)
+	}
+	if got, err := messages.GetMessages(ctx, domain.MessageFilter{ChatID: chatID}); err != nil || len(got) != 2 {
+		t.Errorf("Expected 2 messages left, got %d, %v", len(got), err)
+	}
+}
+
+func testGroups(t *testing.T, s *suite) {
+	ctx := context.Background()
+	groups := s.repos.Groups
+	jid := s.id("group@g.us")
+
+	if got, err := groups.GetGroup(ctx, jid); err != nil || got != nil {
+		t.Fatalf("Expected nil for an unregistered group, got %+v, %v", got, err)
+	}
+
+	group := &domain.Group{JID: jid, Name: "Lari Pagi", Enabled: true, AddedBy: "628111", AddedAt: day(0, 9), Prefix: "!"}
+	if err := groups.SaveGroup(ctx, group); err != nil {
+		t.Fatalf("Failed to save group: %v", err)
+	}
+	group.LeftAt, group.FinalStandings, group.CommandHint, group.LeaderboardStyle = day(3, 9), "1. Budi", true, "podium,pemisah"
+	if err := groups.SaveGroup(ctx, group); err != nil {
+		t.Fatalf("Failed to save group: %v", err)
+	}
+
+	got, err := groups.GetGroup(ctx, jid)
+	if err != nil || got == nil {
+		t.Fatalf("Failed to get group: %+v, %v", got, err)
+	}
+	if got.Name != "Lari Pagi" || !got.Enabled || got.Prefix != "!" || got.FinalStandings != "1. Budi" || !got.CommandHint || got.LeaderboardStyle != "podium,pemisah" {
+		t.Errorf("Expected the saved group, got %+v", got)
+	}
+	sameTime(t, "left at", got.LeftAt, day(3, 9))
+
+	all, err := groups.GetGroups(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get groups: %v", err)
+	}
+	var n int
+	for _, g := range all {
+		if g.JID == jid {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("Expected the group once in GetGroups, got %d", n)
+	}
+}
+
+func testAdmins(t *testing.T, s *suite) {
+	ctx := context.Background()
+	admins := s.repos.Admins
+	username := s.id("admin")
+
+	if got, err := admins.GetAdminAccount(ctx, username); err != nil || got != nil {
+		t.Fatalf("Expected nil for an unknown admin, got %+v, %v", got, err)
+	}
+	if err := admins.SaveAdminAccount(ctx, &domain.AdminAccount{Username: username, PasswordHash: "old", CreatedAt: day(0, 9)}); err != nil {
+		t.Fatalf("Failed to save admin: %v", err)
+	}
+	if err := admins.SaveAdminAccount(ctx, &domain.AdminAccount{Username: username, PasswordHash: "new", CreatedAt: day(0, 9)}); err != nil {
+		t.Fatalf("Failed to save admin: %v", err)
+	}
+	got, err := admins.GetAdminAccount(ctx, username)
+	if err != nil || got == nil || got.PasswordHash != "new" {
+		t.Errorf("Expected the new password hash, got %+v, %v", got, err)
+	}
+}
+
+func testAPIKeys(t *testing.T, s *suite) {
+	ctx := context.Background()
+	keys := s.repos.APIKeys
+
+	key := &domain.APIKey{ID: s.id("key"), Name: "grafana", KeyHash: s.id("hash"), Scope: "read", CreatedAt: day(0, 9)}
+	if err := keys.SaveAPIKey(ctx, key); err != nil {
+		t.Fatalf("Failed to save API key: %v", err)
+	}
+	key.RevokedAt = day(2, 9)
+	if err := keys.SaveAPIKey(ctx, key); err != nil {
+		t.Fatalf("Failed to save API key: %v", err)
+	}
+
+	got, err := keys.GetAPIKeyByHash(ctx, s.id("hash"))
+	if err != nil || got == nil || got.ID != key.ID || got.Scope != "read" {
+		t.Fatalf("Expected the key by its hash, got %+v, %v", got, err)
+	}
+	sameTime(t, "revoked at", got.RevokedAt, day(2, 9))
+	if got, err := keys.GetAPIKey(ctx, s.id("missing")); err != nil || got != nil {
+		t.Errorf("Expected nil for an unknown key, got %+v, %v", got, err)
+	}
+
+	all, err := keys.ListAPIKeys(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list API keys: %v", err)
+	}
+	var n int
+	for _, k := range all {
+		if k.ID == key.ID {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("Expected the key once in ListAPIKeys, got %d", n)
+	}
+}
+
+func testJobs(t *testing.T, s *suite) {
+	ctx := context.Background()
+	jobs := s.repos.Jobs
+
+	if got, err := jobs.GetJob(ctx, s.id("prune")); err != nil || got != nil {
+		t.Fatalf("Expected nil for an unknown job, got %+v, %v", got, err)
+	}
+	saved := []*domain.Job{
+		{Name: s.id("later"), Kind: "leave", Payload: "group@g.us", NextRun: day(5, 9)},
+		{Name: s.id("prune"), Kind: "prune", Schedule: "0 3 * * *", NextRun: day(1, 3)},
+	}
+	for _, job := range saved {
+		if err := jobs.SaveJob(ctx, job); err != nil {
+			t.Fatalf("Failed to save job: %v", err)
+		}
+	}
+	saved[1].LastRun, saved[1].LastError = day(1, 3), "timeout"
+	if err := jobs.SaveJob(ctx, saved[1]); err != nil {
+		t.Fatalf("Failed to save job: %v", err)
+	}
+
+	got, err := jobs.GetJob(ctx, s.id("prune"))
+	if err != nil || got == nil || got.Schedule != "0 3 * * *" || got.LastError != "timeout" {
+		t.Fatalf("Expected the saved job, got %+v, %v", got, err)
+	}
+	sameTime(t, "last run", got.LastRun, day(1, 3))
+
+	all, err := jobs.GetJobs(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get jobs: %v", err)
+	}
+	var names []string
+	for _, job := range all {
+		names = append(names, job.Name)
+	}
+	expectIDs(t, "jobs by next run", filterPrefixed(s, names), s.id("prune"), s.id("later"))
+
+	if err := jobs.DeleteJob(ctx, s.id("later")); err != nil {
+		t.Fatalf("Failed to delete job: %v", err)
+	}
+	if got, err := jobs.GetJob(ctx, s.id("later")); err != nil || got != nil {
+		t.Errorf("Expected the job deleted, got %+v, %v", got, err)
+	}
+}
+
+func testOutbox(t *testing.T, s *suite) {
+	ctx := context.Background()
+	outbox := s.repos.Outbox
+	chatID := s.id("chat@g.us")
+
+	for i := 0; i < 2; i++ {
+		msg := &domain.OutboxMessage{MessageID: s.id(fmt.Sprint("out", i)), ChatID: chatID, Text: "Rekap harian", SentAt: day(i, 20)}
+		if err := outbox.RecordSent(ctx, msg); err != nil {
+			t.Fatalf("Failed to record sent message: %v", err)
+		}
+	}
+	ids := []string{s.id("out0"), s.id("unknown")}
+	if err := outbox.RecordReceipt(ctx, ids, false, day(0, 21)); err != nil {
+		t.Fatalf("Failed to record receipt: %v", err)
+	}
+	if err := outbox.RecordReceipt(ctx, ids, true, day(0, 22)); err != nil {
+		t.Fatalf("Failed to record receipt: %v", err)
+	}
+
+	got, err := outbox.GetOutbox(ctx, domain.OutboxFilter{ChatID: chatID})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Expected 2 sent messages, got %d, %v", len(got), err)
+	}
+	if got[0].MessageID != s.id("out1") {
+		t.Errorf("Expected the newest message first, got %s", got[0].MessageID)
+	}
+	read := got[1]
+	if read.DeliveredCount != 1 || read.ReadCount != 1 || read.ChatID != chatID {
+		t.Errorf("Expected one delivery and one read receipt, got %+v", read)
+	}
+	sameTime(t, "delivered at", read.DeliveredAt, day(0, 21))
+	sameTime(t, "read at", read.ReadAt, day(0, 22))
+
+	if got, err := outbox.GetOutbox(ctx, domain.OutboxFilter{ChatID: chatID, Limit: 1}); err != nil || len(got) != 1 {
+		t.Errorf("Expected the limit applied, got %d, %v", len(got), err)
+	}
+	if err := outbox.DeleteOutbox(ctx, domain.OutboxFilter{ChatID: chatID, Since: day(1, 0)}); err != nil {
+		t.Fatalf("Failed to delete outbox: %v", err)
+	}
+	if got, err := outbox.GetOutbox(ctx, domain.OutboxFilter{ChatID: chatID}); err != nil || len(got) != 1 {
+		t.Errorf("Expected 1 message left, got %d, %v", len(got), err)
+	}
+
+	// A queued reply is unsent until it is recorded as sent
+	replyChat := s.id("reply@g.us")
+	text := strings.Repeat("Laporan diterima ", 20)
+	queued := &domain.OutboxMessage{MessageID: s.id("reply0"), ChatID: replyChat, Text: text, QueuedAt: day(2, 7)}
+	if err := outbox.QueueReply(ctx, queued); err != nil {
+		t.Fatalf("Failed to queue reply: %v", err)
+	}
+	// Other runs may have left replies of their own
+	unsentIn := func(since, until time.Time) []*domain.OutboxMessage {
+		t.Helper()
+		all, err := outbox.GetUnsent(ctx, since, until)
+		if err != nil {
+			t.Fatalf("Failed to get unsent replies: %v", err)
+		}
+		var unsent []*domain.OutboxMessage
+		for _, m := range all {
+			if m.ChatID == replyChat {
+				unsent = append(unsent, m)
+			}
+		}
+		return unsent
+	}
+	unsent := unsentIn(day(2, 0), day(3, 0))
+	if len(unsent) != 1 || unsent[0].MessageID != queued.MessageID || unsent[0].Text != text {
+		t.Fatalf("Expected the queued reply unsent with its whole text, got %+v", unsent)
+	}
+	sameTime(t, "queued at", unsent[0].QueuedAt, day(2, 7))
+	if unsent := unsentIn(day(3, 0), day(4, 0)); len(unsent) != 0 {
+		t.Errorf("Expected no reply queued the day after, got %d", len(unsent))
+	}
+
+	if err := outbox.RecordSent(ctx, &domain.OutboxMessage{MessageID: queued.MessageID, ChatID: replyChat, Text: "Laporan", SentAt: day(2, 8)}); err != nil {
+		t.Fatalf("Failed to record sent reply: %v", err)
+	}
+	if unsent := unsentIn(day(2, 0), day(3, 0)); len(unsent) != 0 {
+		t.Errorf("Expected the reply sent, got %d unsent", len(unsent))
+	}
+	got, err = outbox.GetOutbox(ctx, domain.OutboxFilter{ChatID: replyChat})
+	if err != nil || len(got) != 1 || got[0].Text != "Laporan" {
+		t.Fatalf("Expected the sent reply with its short text, got %+v, %v", got, err)
+	}
+	sameTime(t, "sent at", got[0].SentAt, day(2, 8))
+}
+
+func testIdentities(t *testing.T, s *suite) {
+	ctx := context.Background()
+	identities := s.repos.Identities
+	externalID := s.id("tg1")
+
+	if got, err := identities.GetIdentity(ctx, "telegram", externalID); err != nil || got != nil {
+		t.Fatalf("Expected nil for an unknown account, got %+v, %v", got, err)
+	}
+	pending := &domain.LinkedIdentity{Platform: "telegram", ExternalID: externalID, Code: s.id("code"), CodeExpiresAt: day(0, 10)}
+	if err := identities.SaveIdentity(ctx, pending); err != nil {
+		t.Fatalf("Failed to save identity: %v", err)
+	}
+	got, err := identities.GetIdentityByCode(ctx, s.id("code"))
+	if err != nil || got == nil || got.ExternalID != externalID || got.UserID != "" {
+		t.Fatalf("Expected the pending account by its code, got %+v, %v", got, err)
+	}
+	sameTime(t, "code expiry", got.CodeExpiresAt, day(0, 10))
+
+	userID := s.id("628111")
+	linked := &domain.LinkedIdentity{Platform: "telegram", ExternalID: externalID, UserID: userID, LinkedAt: day(0, 9)}
+	if err := identities.SaveIdentity(ctx, linked); err != nil {
+		t.Fatalf("Failed to save identity: %v", err)
+	}
+	if got, err := identities.GetIdentityByCode(ctx, s.id("code")); err != nil || got != nil {
+		t.Errorf("Expected the code cleared once linked, got %+v, %v", got, err)
+	}
+	list, err := identities.ListIdentities(ctx, userID)
+	if err != nil || len(list) != 1 || list[0].Platform != "telegram" {
+		t.Fatalf("Expected the linked account, got %v, %v", list, err)
+	}
+
+	if err := identities.DeleteIdentities(ctx, userID); err != nil {
+		t.Fatalf("Failed to delete identities: %v", err)
+	}
+	if got, err := identities.GetIdentity(ctx, "telegram", externalID); err != nil || got != nil {
+		t.Errorf("Expected the identity deleted, got %+v, %v", got, err)
+	}
+}
+
+func testPrompts(t *testing.T, s *suite) {
+	ctx := context.Background()
+	prompts := s.repos.Prompts
+	chatID := s.id("chat@g.us")
+
+	if err := prompts.SavePrompt(ctx, &domain.ReportPrompt{MessageID: s.id("p1"), ChatID: chatID, SentAt: day(0, 6)}); err != nil {
+		t.Fatalf("Failed to save prompt: %v", err)
+	}
+	got, err := prompts.GetPrompt(ctx, chatID, s.id("p1"))
+	if err != nil || got == nil {
+		t.Fatalf("Expected the prompt, got %+v, %v", got, err)
+	}
+	sameTime(t, "sent at", got.SentAt, day(0, 6))
+	if got, err := prompts.GetPrompt(ctx, s.id("other@g.us"), s.id("p1")); err != nil || got != nil {
+		t.Errorf("Expected nil for the message in another chat, got %+v, %v", got, err)
+	}
+}
+
+func testQuotes(t *testing.T, s *suite) {
+	ctx := context.Background()
+	quotes := s.repos.Quotes
+
+	if err := quotes.SaveQuoteUse(ctx, &domain.QuoteUse{Hash: s.id("q1"), Day: "2024-03-01"}); err != nil {
+		t.Fatalf("Failed to save quote use: %v", err)
+	}
+	if err := quotes.SaveQuoteUse(ctx, &domain.QuoteUse{Hash: s.id("q2"), Day: "2024-03-02"}); err != nil {
+		t.Fatalf("Failed to save quote use: %v", err)
+	}
+	uses, err := quotes.GetQuoteUses(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get quote uses: %v", err)
+	}
+	var hashes []string
+	for _, use := range uses {
+		hashes = append(hashes, use.Hash)
+	}
+	expectIDs(t, "quote uses by day", filterPrefixed(s, hashes), s.id("q1"), s.id("q2"))
+
+	if err := quotes.DeleteQuoteUses(ctx); err != nil {
+		t.Fatalf("Failed to delete quote uses: %v", err)
+	}
+	if uses, err := quotes.GetQuoteUses(ctx); err != nil || len(uses) != 0 {
+		t.Errorf("Expected the rotation started over, got %d, %v", len(uses), err)
+	}
+}
+
+func testSnapshots(t *testing.T, s *suite) {
+	ctx := context.Background()
+	snapshots := s.repos.Snapshots
+	const today = "2024-03-02"
+
+	entries := []*domain.SnapshotEntry{
+		{UserID: s.id("628111"), Name: "Budi", Rank: 2, Streak: 0, Total: 4},
+		{UserID: s.id("628222"), Name: "Ani", Rank: 1, Streak: 6, Total: 6, Reported: true},
+	}
+	if err := snapshots.SaveSnapshot(ctx, "2024-03-01", entries[:1]); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+	if err := snapshots.SaveSnapshot(ctx, today, entries); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+
+	got, err := snapshots.GetSnapshot(ctx, today)
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	for _, e := range got {
+		if e.UserID == s.id("628222") && (e.Day != today || e.Name != "Ani" || e.Streak != 6 || e.Total != 6 || !e.Reported) {
+			t.Errorf("Expected the saved entry, got %+v", e)
+		}
+	}
+	expectIDs(t, "snapshot by rank", filterPrefixed(s, snapshotIDs(got)), s.id("628222"), s.id("628111"))
+
+	got, err = snapshots.GetSnapshots(ctx, domain.SnapshotFilter{UserID: s.id("628111")})
+	if err != nil {
+		t.Fatalf("Failed to get a member's snapshots: %v", err)
+	}
+	if len(got) != 2 || got[0].Day != "2024-03-01" || got[1].Day != today || got[1].Rank != 2 {
+		t.Errorf("Expected the member's two days in order, got %+v", got)
+	}
+
+	if err := snapshots.DeleteSnapshots(ctx, domain.SnapshotFilter{UserID: s.id("628111")}); err != nil {
+		t.Fatalf("Failed to delete snapshots: %v", err)
+	}
+	got, err = snapshots.GetSnapshot(ctx, today)
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	expectIDs(t, "snapshot after deleting a member", filterPrefixed(s, snapshotIDs(got)), s.id("628222"))
+
+	if err := snapshots.DeleteSnapshots(ctx, domain.SnapshotFilter{Before: today}); err != nil {
+		t.Fatalf("Failed to delete old snapshots: %v", err)
+	}
+	if got, err := snapshots.GetSnapshot(ctx, "2024-03-01"); err != nil || countPrefixed(s, snapshotIDs(got)) != 0 {
+		t.Errorf("Expected the older snapshot pruned, got %d, %v", len(got), err)
+	}
+	if got, err := snapshots.GetSnapshot(ctx, today); err != nil || countPrefixed(s, snapshotIDs(got)) != 1 {
+		t.Errorf("Expected today's snapshot kept, got %d, %v", len(got), err)
+	}
+}
+
+func snapshotIDs(entries []*domain.SnapshotEntry) []string {
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.UserID)
+	}
+	return ids
+}
+
+func testSessions(t *testing.T, s *suite) {
+	ctx := context.Background()
+	sessions := s.repos.Sessions
+	userID := s.id("628111")
+
+	if got, err := sessions.GetConversation(ctx, userID); err != nil || got != nil {
+		t.Fatalf("Expected nil without an open conversation, got %+v, %v", got, err)
+	}
+	conv := &domain.Conversation{UserID: userID, Flow: "join", Step: 1, Data: map[string]string{"name": "Budi"}, ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Second)}
+	if err := sessions.SaveConversation(ctx, conv); err != nil {
+		t.Fatalf("Failed to save conversation: %v", err)
+	}
+	conv.Step = 2
+	if err := sessions.SaveConversation(ctx, conv); err != nil {
+		t.Fatalf("Failed to save conversation: %v", err)
+	}
+	got, err := sessions.GetConversation(ctx, userID)
+	if err != nil || got == nil || got.Flow != "join" || got.Step != 2 || got.Data["name"] != "Budi" {
+		t.Fatalf("Expected the saved conversation, got %+v, %v", got, err)
+	}
+	sameTime(t, "expiry", got.ExpiresAt, conv.ExpiresAt)
+
+	if err := sessions.DeleteConversation(ctx, userID); err != nil {
+		t.Fatalf("Failed to delete conversation: %v", err)
+	}
+	if got, err := sessions.GetConversation(ctx, userID); err != nil || got != nil {
+		t.Errorf("Expected the conversation deleted, got %+v, %v", got, err)
+	}
+}
+
+// testTx checks that writes in a failed transaction are rolled back. A
+// driver without transactions leaves Tx nil and the use cases write step
+// by step.
+func testTx(t *testing.T, s *suite) {
+	if s.repos.Tx == nil {
+		t.Skipf("Driver %q has no transactions", s.cfg.DBDriver)
+	}
+	ctx := context.Background()
+	userID := s.id("628111")
+	failed := errors.New("failed")
+
+	err := s.repos.Tx.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := s.repos.Reports.SubmitReport(ctx, userID, "Budi", day(0, 7)); err != nil {
+			return err
+		}
+		if err := s.repos.Activities.AddActivity(ctx, &domain.Activity{UserID: userID, Name: "Budi", ReportedAt: day(0, 7)}); err != nil {
+			return err
+		}
+		if err := s.repos.Outbox.QueueReply(ctx, &domain.OutboxMessage{MessageID: s.id("txreply"), ChatID: s.id("tx@g.us"), Text: "Laporan diterima", QueuedAt: day(0, 7)}); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Expected the error of fn, got %v", err)
+	}
+	if got, err := s.repos.Reports.GetReport(ctx, userID); err != nil || got != nil {
+		t.Errorf("Expected the report rolled back, got %+v, %v", got, err)
+	}
+	if got, err := s.repos.Activities.GetActivities(ctx, domain.ActivityFilter{UserID: userID}); err != nil || len(got) != 0 {
+		t.Errorf("Expected the activity rolled back, got %d, %v", len(got), err)
+	}
+	if got, err := s.repos.Outbox.GetOutbox(ctx, domain.OutboxFilter{ChatID: s.id("tx@g.us")}); err != nil || len(got) != 0 {
+		t.Errorf("Expected the queued reply rolled back, got %d, %v", len(got), err)
+	}
+
+	err = s.repos.Tx.WithTx(ctx, func(ctx context.Context) error {
+		_, err := s.repos.Reports.SubmitReport(ctx, userID, "Budi", day(0, 7))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if got, err := s.repos.Reports.GetReport(ctx, userID); err != nil || got == nil {
+		t.Errorf("Expected the report committed, got %+v, %v", got, err)
+	}
+}
+
+func testTenants(t *testing.T, s *suite) {
+	ctx := context.Background()
+	tenants, err := repository.OpenTenants(s.cfg)
+	if err != nil {
+		t.Fatalf("Failed to open tenant registry: %v", err)
+	}
+	if err := tenants.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to init tenants table: %v", err)
+	}
+	id := s.id("lari")
+
+	if got, err := tenants.GetTenant(ctx, id); err != nil || got != nil {
+		t.Fatalf("Expected nil for an unknown tenant, got %+v, %v", got, err)
+	}
+	tenant := &domain.Tenant{ID: id, Name: "Lari", Enabled: true, Env: map[string]string{"GROUP_ID": "1@g.us"}, CreatedAt: day(0, 9)}
+	if err := tenants.SaveTenant(ctx, tenant); err != nil {
+		t.Fatalf("Failed to save tenant: %v", err)
+	}
+	tenant.Enabled = false
+	if err := tenants.SaveTenant(ctx, tenant); err != nil {
+		t.Fatalf("Failed to save tenant: %v", err)
+	}
+
+	got, err := tenants.GetTenant(ctx, id)
+	if err != nil || got == nil || got.Enabled || got.Env["GROUP_ID"] != "1@g.us" {
+		t.Fatalf("Expected the saved tenant, got %+v, %v", got, err)
+	}
+	sameTime(t, "created at", got.CreatedAt, day(0, 9))
+
+	if err := tenants.DeleteTenant(ctx, id); err != nil {
+		t.Fatalf("Failed to delete tenant: %v", err)
+	}
+	all, err := tenants.GetTenants(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get tenants: %v", err)
+	}
+	for _, tenant := range all {
+		if tenant.ID == id {
+			t.Error("Expected the tenant deleted")
+		}
+	}
+}
+
+func reportIDs(reports []*domain.Report) []string {
+	ids := make([]string, 0, len(reports))
+	for _, r := range reports {
+		ids = append(ids, r.UserID)
+	}
+	return ids
+}
+
+func settingsIDs(s *suite, settings []*domain.UserSettings) []string {
+	ids := make([]string, 0, len(settings))
+	for _, st := range settings {
+		ids = append(ids, st.UserID)
+	}
+	return filterPrefixed(s, ids)
+}
+
+// filterPrefixed keeps the IDs of this run, in order.
+func filterPrefixed(s *suite, ids []string) []string {
+	var own []string
+	for _, id := range ids {
+		if len(id) >= len(s.prefix) && id[:len(s.prefix)] == s.prefix {
+			own = append(own, id)
+		}
+	}
+	return own
+}
+
+func countPrefixed(s *suite, ids []string) int {
+	return len(filterPrefixed(s, ids))
+}
+
+func expectIDs(t *testing.T, what string, got []string, want ...string) {
+	t.Helper()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %s %v, got %v", what, want, got)
+	}
+}
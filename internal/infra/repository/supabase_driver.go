@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"log"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/supabase"
+	supa "github.com/nedpals/supabase-go"
+)
+
+func init() {
+	Register("supabase", supabaseDriver{})
+}
+
+// supabaseDriver talks to Supabase's REST API. Its tables are created from
+// the SQL in the doc comments of the supabase repositories; it has no
+// transactions and no backups of its own.
+type supabaseDriver struct{}
+
+func (supabaseDriver) Open(cfg config.Config) (*Repositories, error) {
+	log.Println("Using Supabase database")
+	client := supa.CreateClient(cfg.SupabaseURL, cfg.SupabaseKey)
+	reports := supabase.NewReportRepository(client)
+	reports.SetUserIDHasher(phone.NewHasher(cfg.UserIDSalt))
+	activities := supabase.NewActivityRepository(client)
+	if cfg.SupabaseReadURL != "" {
+		log.Println("Using Supabase read replica for leaderboards and dashboards")
+		replica := supa.CreateClient(cfg.SupabaseReadURL, cfg.SupabaseKey)
+		reports.SetReadReplica(replica)
+		activities.SetReadReplica(replica)
+	}
+	rdb := connectRedis(cfg)
+	return &Repositories{
+		Reports:    cachedReports(cfg, rdb, reports),
+		Activities: activities,
+		Events:     supabase.NewReportEventRepository(client),
+		Settings:   supabase.NewSettingsRepository(client),
+		Messages:   supabase.NewMessageArchiveRepository(client),
+		Groups:     supabase.NewGroupRepository(client),
+		Admins:     supabase.NewAdminAccountRepository(client),
+		APIKeys:    supabase.NewAPIKeyRepository(client),
+		Jobs:       supabase.NewJobRepository(client),
+		Outbox:     supabase.NewOutboxRepository(client),
+		Identities: supabase.NewIdentityRepository(client),
+		Prompts:    supabase.NewPromptRepository(client),
+		Quotes:     supabase.NewQuoteRepository(client),
+		Snapshots:  supabase.NewSnapshotRepository(client),
+		Sessions:   sessionStore(rdb, supabase.NewConversationRepository(client)),
+		Dedup:      dedupStore(rdb),
+		Redis:      rdb,
+	}, nil
+}
+
+func (supabaseDriver) OpenTenants(cfg config.Config) (domain.TenantRepository, error) {
+	return supabase.NewTenantRepository(supa.CreateClient(cfg.SupabaseURL, cfg.SupabaseKey)), nil
+}
@@ -0,0 +1,79 @@
+// Package s3 keeps backups in a bucket of an S3-compatible storage, such as
+// AWS S3, MinIO or Backblaze B2.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Store implements domain.BackupStore with the objects under a prefix of a
+// bucket.
+type Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewStore connects to endpoint, a URL such as
+// "https://s3.us-west-004.backblazeb2.com"; http:// is for a MinIO on the
+// same network. The backups are kept under prefix, e.g. "lapor-bot".
+func NewStore(endpoint, region, accessKey, secretKey, bucket, prefix string) (*Store, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("invalid endpoint %q, expected e.g. https://s3.amazonaws.com", endpoint)
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("no bucket set")
+	}
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: u.Scheme == "https",
+		Region: region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{client: client, bucket: bucket, prefix: strings.Trim(prefix, "/")}, nil
+}
+
+func (s *Store) key(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "/" + name
+}
+
+func (s *Store) Upload(ctx context.Context, name string, data []byte) error {
+	_, err := s.client.PutObject(ctx, s.bucket, s.key(name), bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	return err
+}
+
+// List returns the names of the objects directly under the prefix, leaving
+// out those of tenants, which have a prefix of their own inside it.
+func (s *Store) List(ctx context.Context) ([]string, error) {
+	var names []string
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: s.key("")}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		name := strings.TrimPrefix(obj.Key, s.key(""))
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (s *Store) Delete(ctx context.Context, name string) error {
+	return s.client.RemoveObject(ctx, s.bucket, s.key(name), minio.RemoveObjectOptions{})
+}
@@ -0,0 +1,118 @@
+package s3_test
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/s3"
+)
+
+// fakeBucket serves the S3 calls the store makes, for a single bucket.
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string]bool
+}
+
+type listResult struct {
+	XMLName        xml.Name `xml:"ListBucketResult"`
+	Name           string
+	Prefix         string
+	KeyCount       int
+	IsTruncated    bool
+	Contents       []struct{ Key string }
+	CommonPrefixes []struct{ Prefix string }
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/backups/")
+	switch {
+	case r.Method == http.MethodPut:
+		b.objects[key] = true
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+	case r.Method == http.MethodDelete:
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		prefix, delimiter := r.URL.Query().Get("prefix"), r.URL.Query().Get("delimiter")
+		result := listResult{Name: "backups", Prefix: prefix}
+		seen := make(map[string]bool)
+		var keys []string
+		for k := range b.objects {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if !strings.HasPrefix(k, prefix) {
+				continue
+			}
+			if i := strings.Index(k[len(prefix):], delimiter); delimiter != "" && i >= 0 {
+				sub := k[:len(prefix)+i+1]
+				if !seen[sub] {
+					seen[sub] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, struct{ Prefix string }{sub})
+				}
+				continue
+			}
+			result.Contents = append(result.Contents, struct{ Key string }{k})
+		}
+		result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(result)
+	default:
+		http.Error(w, "unexpected request", http.StatusNotImplemented)
+	}
+}
+
+// =============================================================================
+// S3 STORE TESTS
+// =============================================================================
+
+func TestStore_UploadListDelete(t *testing.T) {
+	bucket := &fakeBucket{objects: map[string]bool{
+		"lapor-bot/tenant1/backup-20261001-020000.db.enc": true,
+		"other/backup-20261001-020000.db.enc":             true,
+	}}
+	srv := httptest.NewServer(bucket)
+	t.Cleanup(srv.Close)
+
+	store, err := s3.NewStore(srv.URL, "us-east-1", "key", "secret", "backups", "/lapor-bot/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	for _, name := range []string{"backup-20261015-020000.db.enc", "backup-20261016-020000.db.enc"} {
+		if err := store.Upload(ctx, name, []byte("sealed")); err != nil {
+			t.Fatalf("Failed to upload %s: %v", name, err)
+		}
+	}
+	if !bucket.objects["lapor-bot/backup-20261016-020000.db.enc"] {
+		t.Fatalf("Expected the backup under the prefix, got %v", bucket.objects)
+	}
+
+	if err := store.Delete(ctx, "backup-20261015-020000.db.enc"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	names, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	// The tenant's and other prefixes are left out
+	if len(names) != 1 || names[0] != "backup-20261016-020000.db.enc" {
+		t.Errorf("Expected only the remaining backup, got %v", names)
+	}
+}
+
+func TestNewStore_RejectsEndpointWithoutScheme(t *testing.T) {
+	if _, err := s3.NewStore("s3.amazonaws.com", "us-east-1", "key", "secret", "backups", ""); err == nil {
+		t.Error("Expected an error for an endpoint without https://")
+	}
+}
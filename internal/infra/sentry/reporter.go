@@ -0,0 +1,109 @@
+// Package sentry reports errors and panics to Sentry, or a server that
+// speaks its protocol such as GlitchTip, so operators learn about failures
+// without tailing the logs.
+package sentry
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	sdk "github.com/getsentry/sentry-go"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+)
+
+// maxTagLength is the longest tag value Sentry accepts.
+const maxTagLength = 200
+
+// Reporter sends events to one DSN. A nil Reporter does nothing, so callers
+// need no checks when SENTRY_DSN is empty.
+type Reporter struct {
+	hub *sdk.Hub
+}
+
+// New reports to dsn. The environment and release are read from
+// SENTRY_ENVIRONMENT and SENTRY_RELEASE, or the VCS revision of the build.
+// Every event is tagged with tenant when it is not empty.
+func New(dsn, tenant string) (*Reporter, error) {
+	client, err := sdk.NewClient(sdk.ClientOptions{
+		Dsn:              dsn,
+		AttachStacktrace: true,
+		BeforeSend:       redactEvent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	scope := sdk.NewScope()
+	if tenant != "" {
+		scope.SetTag("tenant", tenant)
+	}
+	return &Reporter{hub: sdk.NewHub(client, scope)}, nil
+}
+
+// Capture reports err with the context it happened in, e.g. the chat and
+// the command of the message being handled.
+func (r *Reporter) Capture(err error, tags map[string]string) {
+	if r == nil || err == nil {
+		return
+	}
+	r.hub.WithScope(func(scope *sdk.Scope) {
+		scope.SetTags(truncateTags(tags))
+		r.hub.CaptureException(err)
+	})
+}
+
+// Recover reports a panic and stops it, so one bad message does not take
+// the bot down. Use it as "defer reporter.Recover(tags)". Without a
+// Reporter the panic goes on as before.
+func (r *Reporter) Recover(tags map[string]string) {
+	if r == nil {
+		return
+	}
+	if v := recover(); v != nil {
+		log.Printf("Recovered from panic: %v", v)
+		r.hub.WithScope(func(scope *sdk.Scope) {
+			scope.SetTags(truncateTags(tags))
+			scope.SetLevel(sdk.LevelFatal)
+			r.hub.Recover(v)
+		})
+	}
+}
+
+// Flush waits up to timeout for queued events to be sent, e.g. before the
+// bot exits.
+func (r *Reporter) Flush(timeout time.Duration) {
+	if r == nil {
+		return
+	}
+	r.hub.Flush(timeout)
+}
+
+func truncateTags(tags map[string]string) map[string]string {
+	out := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if len(v) > maxTagLength {
+			v = strings.ToValidUTF8(v[:maxTagLength-3], "") + "..."
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// redactEvent masks phone numbers everywhere an event carries text: member
+// IDs are phone numbers unless USER_ID_SALT is set, and errors and messages
+// may quote them.
+func redactEvent(event *sdk.Event, _ *sdk.EventHint) *sdk.Event {
+	event.Message = phone.Redact(event.Message)
+	for i := range event.Exception {
+		event.Exception[i].Value = phone.Redact(event.Exception[i].Value)
+	}
+	for k, v := range event.Tags {
+		event.Tags[k] = phone.Redact(v)
+	}
+	for _, b := range event.Breadcrumbs {
+		b.Message = phone.Redact(b.Message)
+	}
+	event.User = sdk.User{}
+	return event
+}
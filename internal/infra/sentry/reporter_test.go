@@ -0,0 +1,133 @@
+package sentry_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sentry"
+)
+
+type event struct {
+	Level     string            `json:"level"`
+	Tags      map[string]string `json:"tags"`
+	Exception []struct {
+		Value string `json:"value"`
+	} `json:"exception"`
+}
+
+// newTestServer receives envelopes and keeps the events in them.
+func newTestServer(t *testing.T) (string, func() []event) {
+	var mu sync.Mutex
+	var events []event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// An envelope is a header line, then an item header and payload per item
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(make([]byte, 1<<20), 1<<20)
+		var lines []string
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		for i := 1; i+1 < len(lines); i += 2 {
+			var header struct{ Type string }
+			_ = json.Unmarshal([]byte(lines[i]), &header)
+			if header.Type != "event" {
+				continue
+			}
+			var e event
+			if err := json.Unmarshal([]byte(lines[i+1]), &e); err != nil {
+				t.Errorf("Expected an event: %v", err)
+			}
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	dsn := strings.Replace(srv.URL, "http://", "http://public@", 1) + "/1"
+	return dsn, func() []event {
+		mu.Lock()
+		defer mu.Unlock()
+		return events
+	}
+}
+
+// =============================================================================
+// SENTRY TESTS
+// =============================================================================
+
+func TestReporter_CaptureRedactsPhoneNumbers(t *testing.T) {
+	dsn, received := newTestServer(t)
+	reporter, err := sentry.New(dsn, "lari-pagi")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reporter.Capture(errors.New("failed to save report of 6281234567890"), map[string]string{
+		"chat": "120363012345678901@g.us",
+		"user": "6281234567890@s.whatsapp.net",
+		"text": "#lapor lari, hubungi +62 812-3456-7890",
+	})
+	reporter.Flush(5 * time.Second)
+
+	events := received()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	e := events[0]
+	if len(e.Exception) == 0 || e.Exception[0].Value != "failed to save report of [phone]" {
+		t.Errorf("Expected the number masked in the error, got %+v", e.Exception)
+	}
+	want := map[string]string{
+		"tenant": "lari-pagi",
+		"chat":   "120363012345678901@g.us",
+		"user":   "[phone]@s.whatsapp.net",
+		"text":   "#lapor lari, hubungi [phone]",
+	}
+	for k, v := range want {
+		if e.Tags[k] != v {
+			t.Errorf("Expected tag %s to be %q, got %q", k, v, e.Tags[k])
+		}
+	}
+}
+
+func TestReporter_RecoverStopsPanic(t *testing.T) {
+	dsn, received := newTestServer(t)
+	reporter, err := sentry.New(dsn, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	func() {
+		defer reporter.Recover(map[string]string{"command": "#grafik"})
+		panic("boom")
+	}()
+	reporter.Flush(5 * time.Second)
+
+	events := received()
+	if len(events) != 1 || events[0].Level != "fatal" || events[0].Tags["command"] != "#grafik" {
+		t.Errorf("Expected the panic reported, got %+v", events)
+	}
+}
+
+func TestReporter_NilDoesNothing(t *testing.T) {
+	var reporter *sentry.Reporter
+	reporter.Capture(errors.New("boom"), nil)
+	reporter.Flush(time.Second)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected the panic to go on without a reporter")
+		}
+	}()
+	func() {
+		defer reporter.Recover(nil)
+		panic("boom")
+	}()
+}
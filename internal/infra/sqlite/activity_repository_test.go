@@ -0,0 +1,113 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE ACTIVITY REPOSITORY TESTS
+// =============================================================================
+
+func setupActivityRepo(t *testing.T) (*sqlite.ActivityRepository, func()) {
+	t.Helper()
+
+	db, _, cleanup := setupTestDB(t)
+	repo := sqlite.NewActivityRepository(db)
+	if err := repo.InitTable(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize activity table: %v", err)
+	}
+	return repo, cleanup
+}
+
+func TestActivityRepository_AddAndFilter(t *testing.T) {
+	repo, cleanup := setupActivityRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+
+	entries := []*domain.Activity{
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", Message: "#lapor lari", ReportedAt: base},
+		{UserID: "user2", Name: "Bob", ActivityType: "gym", Message: "#lapor gym", ReportedAt: base.Add(time.Hour)},
+		{UserID: "user1", Name: "Alice", ActivityType: "lari", Message: "#lapor lari", ReportedAt: base.AddDate(0, 0, 1)},
+		{UserID: "user1", Name: "Alice", ActivityType: "yoga", Message: "#lapor yoga", ReportedAt: base.AddDate(0, 0, 7)},
+	}
+	for _, e := range entries {
+		if err := repo.AddActivity(ctx, e); err != nil {
+			t.Fatalf("Failed to add activity: %v", err)
+		}
+		if e.ID == 0 {
+			t.Errorf("Expected ID to be assigned")
+		}
+	}
+
+	all, err := repo.GetActivities(ctx, domain.ActivityFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get activities: %v", err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("Expected 4 activities, got %d", len(all))
+	}
+	if !all[0].ReportedAt.Equal(base) {
+		t.Errorf("Expected oldest first, got %v", all[0].ReportedAt)
+	}
+
+	lari, err := repo.GetActivities(ctx, domain.ActivityFilter{ActivityType: "lari"})
+	if err != nil {
+		t.Fatalf("Failed to filter by type: %v", err)
+	}
+	if len(lari) != 2 {
+		t.Errorf("Expected 2 lari activities, got %d", len(lari))
+	}
+
+	bob, err := repo.GetActivities(ctx, domain.ActivityFilter{UserID: "user2"})
+	if err != nil {
+		t.Fatalf("Failed to filter by user: %v", err)
+	}
+	if len(bob) != 1 || bob[0].Name != "Bob" {
+		t.Errorf("Expected only Bob's activity, got %+v", bob)
+	}
+
+	// Since is inclusive, Until is exclusive
+	window, err := repo.GetActivities(ctx, domain.ActivityFilter{Since: base, Until: base.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to filter by range: %v", err)
+	}
+	if len(window) != 2 {
+		t.Errorf("Expected 2 activities in first day window, got %d", len(window))
+	}
+}
+
+func TestActivityRepository_Delete(t *testing.T) {
+	repo, cleanup := setupActivityRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+	for _, e := range []*domain.Activity{
+		{UserID: "user1", ActivityType: "lari", ReportedAt: base},
+		{UserID: "user2", ActivityType: "gym", ReportedAt: base},
+		{UserID: "user1", ActivityType: "gym", ReportedAt: base.AddDate(0, 0, 1)},
+	} {
+		if err := repo.AddActivity(ctx, e); err != nil {
+			t.Fatalf("Failed to add activity: %v", err)
+		}
+	}
+
+	if err := repo.DeleteActivities(ctx, domain.ActivityFilter{UserID: "user1"}); err != nil {
+		t.Fatalf("Failed to delete activities: %v", err)
+	}
+
+	left, err := repo.GetActivities(ctx, domain.ActivityFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get activities: %v", err)
+	}
+	if len(left) != 1 || left[0].UserID != "user2" {
+		t.Errorf("Expected only user2's activity left, got %+v", left)
+	}
+}
@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, name, key_hash, scope, created_at, revoked_at`
+
+func (r *APIKeyRepository) GetAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = ?`
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?`
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, hash))
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var createdAt, revokedAt string
+	err := row.Scan(&key.ID, &key.Name, &key.KeyHash, &key.Scope, &createdAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt != "" {
+		key.RevokedAt, err = time.Parse(time.RFC3339, revokedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) SaveAPIKey(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, name, key_hash, scope, created_at, revoked_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			scope = excluded.scope,
+			revoked_at = excluded.revoked_at
+	`
+	revokedAt := ""
+	if !key.RevokedAt.IsZero() {
+		revokedAt = key.RevokedAt.UTC().Format(time.RFC3339)
+	}
+	_, err := r.db.ExecContext(ctx, query, key.ID, key.Name, key.KeyHash, key.Scope,
+		key.CreatedAt.UTC().Format(time.RFC3339), revokedAt)
+	return err
+}
+
+func (r *APIKeyRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			key_hash TEXT NOT NULL UNIQUE,
+			scope TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			revoked_at TEXT NOT NULL DEFAULT ''
+		);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,56 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE API KEY REPOSITORY TESTS
+// =============================================================================
+
+func TestAPIKeyRepository_SaveListRevoke(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := sqlite.NewAPIKeyRepository(db)
+	if err := repo.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize api_keys table: %v", err)
+	}
+
+	created := time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)
+	key := &domain.APIKey{ID: "a1b2c3d4", Name: "sheets", KeyHash: "hash1", Scope: "read", CreatedAt: created}
+	if err := repo.SaveAPIKey(ctx, key); err != nil {
+		t.Fatalf("Failed to save key: %v", err)
+	}
+
+	got, err := repo.GetAPIKeyByHash(ctx, "hash1")
+	if err != nil || got == nil {
+		t.Fatalf("Expected key by hash, got %+v, %v", got, err)
+	}
+	if got.ID != "a1b2c3d4" || got.Scope != "read" || !got.CreatedAt.Equal(created) || !got.RevokedAt.IsZero() {
+		t.Errorf("Unexpected key: %+v", got)
+	}
+	if missing, err := repo.GetAPIKeyByHash(ctx, "other"); err != nil || missing != nil {
+		t.Errorf("Expected nil for unknown hash, got %+v, %v", missing, err)
+	}
+
+	key.RevokedAt = created.Add(time.Hour)
+	if err := repo.SaveAPIKey(ctx, key); err != nil {
+		t.Fatalf("Failed to revoke key: %v", err)
+	}
+	got, err = repo.GetAPIKey(ctx, "a1b2c3d4")
+	if err != nil || got == nil || !got.RevokedAt.Equal(key.RevokedAt) {
+		t.Errorf("Expected revoked key, got %+v, %v", got, err)
+	}
+
+	keys, err := repo.ListAPIKeys(ctx)
+	if err != nil || len(keys) != 1 {
+		t.Errorf("Expected 1 key, got %d, %v", len(keys), err)
+	}
+}
@@ -0,0 +1,218 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE REPOSITORY BENCHMARKS
+// =============================================================================
+//
+// Run with: go test ./internal/infra/sqlite -run '^$' -bench . -benchmem
+//
+// Each benchmark fills a WAL-mode database file with 10k and 100k rows, the
+// same setup the bot uses in production, so schema or query changes that
+// slow down the hot paths show up before a release.
+//
+// =============================================================================
+
+var benchSizes = []int{10_000, 100_000}
+
+var benchTypes = []string{"lari", "gym", "sepeda", "renang", "yoga"}
+
+func openBenchDB(b *testing.B) *sql.DB {
+	b.Helper()
+
+	path := filepath.Join(b.TempDir(), "bench.db")
+	db, err := sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		b.Fatalf("Failed to open database: %v", err)
+	}
+	b.Cleanup(func() { db.Close() })
+	return db
+}
+
+// seedReports inserts n members in one transaction, far faster than n
+// UpsertReport calls.
+func seedReports(b *testing.B, n int) (*sqlite.ReportRepository, time.Time) {
+	b.Helper()
+
+	db := openBenchDB(b)
+	repo := sqlite.NewReportRepository(db)
+	if err := repo.InitTable(context.Background()); err != nil {
+		b.Fatalf("Failed to initialize table: %v", err)
+	}
+
+	now := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+	tx, err := db.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO user_reports (user_id, name, streak, activity_count, last_report_date) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		last := now.AddDate(0, 0, -(i % 90))
+		if _, err := stmt.Exec(benchUserID(i), fmt.Sprintf("Member %d", i), i%30, i%365, last.Format(time.RFC3339)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+	return repo, now
+}
+
+// seedActivities inserts n log entries spread over n/20 members and a year.
+func seedActivities(b *testing.B, n int) (*sqlite.ActivityRepository, time.Time) {
+	b.Helper()
+
+	db := openBenchDB(b)
+	repo := sqlite.NewActivityRepository(db)
+	if err := repo.InitTable(context.Background()); err != nil {
+		b.Fatalf("Failed to initialize activity table: %v", err)
+	}
+
+	now := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+	tx, err := db.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO activity_logs (user_id, name, activity_type, duration_minutes, distance_km, message, reported_at) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	users := n / 20
+	for i := 0; i < n; i++ {
+		at := now.Add(-time.Duration(i%(365*24)) * time.Hour)
+		activityType := benchTypes[i%len(benchTypes)]
+		if _, err := stmt.Exec(benchUserID(i%users), "Member", activityType, 30, 5.0, "#lapor "+activityType, at.Format(time.RFC3339)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+	return repo, now
+}
+
+func benchUserID(i int) string {
+	return fmt.Sprintf("62812%08d", i)
+}
+
+func BenchmarkReportRepository_UpsertReport(b *testing.B) {
+	for _, size := range benchSizes {
+		repo, now := seedReports(b, size)
+		ctx := context.Background()
+
+		// Seeded rows start at version 1
+		versions := make([]int, size)
+		for i := range versions {
+			versions[i] = 1
+		}
+
+		b.Run(fmt.Sprintf("update/rows=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				u := i % size
+				report := &domain.Report{UserID: benchUserID(u), Name: "Member", Streak: 3, ActivityCount: 10, LastReportDate: now, Version: versions[u]}
+				if err := repo.UpsertReport(ctx, report); err != nil {
+					b.Fatal(err)
+				}
+				versions[u] = report.Version
+			}
+		})
+		b.Run(fmt.Sprintf("insert/rows=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				report := &domain.Report{UserID: fmt.Sprintf("new-%d-%d", b.N, i), Name: "New", Streak: 1, ActivityCount: 1, LastReportDate: now}
+				if err := repo.UpsertReport(ctx, report); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReportRepository_GetAllReports(b *testing.B) {
+	for _, size := range benchSizes {
+		repo, _ := seedReports(b, size)
+		ctx := context.Background()
+
+		b.Run(fmt.Sprintf("rows=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				reports, err := repo.GetAllReports(ctx)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(reports) < size {
+					b.Fatalf("got %d reports, want at least %d", len(reports), size)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReportRepository_ListReports(b *testing.B) {
+	for _, size := range benchSizes {
+		repo, now := seedReports(b, size)
+		ctx := context.Background()
+
+		b.Run(fmt.Sprintf("rows=%d", size), func(b *testing.B) {
+			q := domain.ReportQuery{Since: now.AddDate(0, 0, -7), Sort: domain.ReportSortStreak, Limit: 50}
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.ListReports(ctx, q); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkActivityRepository_AddActivity(b *testing.B) {
+	for _, size := range benchSizes {
+		repo, now := seedActivities(b, size)
+		ctx := context.Background()
+
+		b.Run(fmt.Sprintf("rows=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				activity := &domain.Activity{UserID: benchUserID(i % 100), Name: "Member", ActivityType: "lari", Message: "#lapor lari", ReportedAt: now}
+				if err := repo.AddActivity(ctx, activity); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkActivityRepository_GetActivities(b *testing.B) {
+	for _, size := range benchSizes {
+		repo, now := seedActivities(b, size)
+		ctx := context.Background()
+
+		filters := []struct {
+			name   string
+			filter domain.ActivityFilter
+		}{
+			{"user", domain.ActivityFilter{UserID: benchUserID(7)}},
+			{"week", domain.ActivityFilter{Since: now.AddDate(0, 0, -7), Until: now}},
+			{"type_month", domain.ActivityFilter{ActivityType: "lari", Since: now.AddDate(0, -1, 0), Until: now}},
+		}
+		for _, f := range filters {
+			b.Run(fmt.Sprintf("%s/rows=%d", f.name, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := repo.GetActivities(ctx, f.filter); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE CONVERSATION REPOSITORY TESTS
+// =============================================================================
+
+func TestConversationRepository_SaveGetDelete(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := sqlite.NewConversationRepository(db)
+	if err := repo.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize conversations table: %v", err)
+	}
+
+	missing, err := repo.GetConversation(ctx, "user1")
+	if err != nil || missing != nil {
+		t.Fatalf("Expected nil for no conversation, got %+v, %v", missing, err)
+	}
+
+	expires := time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)
+	conv := &domain.Conversation{UserID: "user1", Flow: "join", Data: map[string]string{"name": "Alice"}, ExpiresAt: expires}
+	if err := repo.SaveConversation(ctx, conv); err != nil {
+		t.Fatalf("Failed to save conversation: %v", err)
+	}
+	conv.Step = 1
+	conv.Data["timezone"] = "WIT"
+	if err := repo.SaveConversation(ctx, conv); err != nil {
+		t.Fatalf("Failed to update conversation: %v", err)
+	}
+
+	got, err := repo.GetConversation(ctx, "user1")
+	if err != nil {
+		t.Fatalf("Failed to get conversation: %v", err)
+	}
+	if got.Flow != "join" || got.Step != 1 || got.Data["name"] != "Alice" || got.Data["timezone"] != "WIT" || !got.ExpiresAt.Equal(expires) {
+		t.Errorf("Unexpected conversation %+v", got)
+	}
+
+	if err := repo.DeleteConversation(ctx, "user1"); err != nil {
+		t.Fatalf("Failed to delete conversation: %v", err)
+	}
+	if got, _ := repo.GetConversation(ctx, "user1"); got != nil {
+		t.Errorf("Expected conversation deleted, got %+v", got)
+	}
+}
@@ -0,0 +1,69 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE GROUP REPOSITORY TESTS
+// =============================================================================
+
+func TestGroupRepository_SaveAndGet(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := sqlite.NewGroupRepository(db)
+	if err := repo.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize groups table: %v", err)
+	}
+
+	missing, err := repo.GetGroup(ctx, "nope@g.us")
+	if err != nil || missing != nil {
+		t.Fatalf("Expected nil for unknown group, got %+v, %v", missing, err)
+	}
+
+	added := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	group := &domain.Group{JID: "222@g.us", Name: "Lari Pagi", Enabled: true, AddedBy: "admin1", AddedAt: added}
+	if err := repo.SaveGroup(ctx, group); err != nil {
+		t.Fatalf("Failed to save group: %v", err)
+	}
+
+	group.Enabled = false
+	group.Name = "Lari Sore"
+	if err := repo.SaveGroup(ctx, group); err != nil {
+		t.Fatalf("Failed to update group: %v", err)
+	}
+
+	groups, err := repo.GetGroups(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list groups: %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("Expected 1 group, got %d", len(groups))
+	}
+	if g := groups[0]; g.Name != "Lari Sore" || g.Enabled || g.AddedBy != "admin1" || !g.AddedAt.Equal(added) || !g.LeftAt.IsZero() {
+		t.Errorf("Unexpected group %+v", g)
+	}
+
+	// Leaving archives the final standings
+	left := time.Date(2026, 4, 1, 20, 0, 0, 0, time.UTC)
+	group.LeftAt = left
+	group.FinalStandings = "1. Alice - 30 hari"
+	group.CommandHint = true
+	if err := repo.SaveGroup(ctx, group); err != nil {
+		t.Fatalf("Failed to archive group: %v", err)
+	}
+	archived, err := repo.GetGroup(ctx, "222@g.us")
+	if err != nil {
+		t.Fatalf("Failed to get group: %v", err)
+	}
+	if !archived.LeftAt.Equal(left) || archived.FinalStandings != "1. Alice - 30 hari" || !archived.CommandHint {
+		t.Errorf("Expected archived standings, got %+v", archived)
+	}
+}
@@ -0,0 +1,111 @@
+package sqlite_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE LINKED IDENTITY TESTS
+// =============================================================================
+
+func TestIdentityRepository_LinkFlow(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := sqlite.NewIdentityRepository(db)
+	if err := repo.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize linked identities table: %v", err)
+	}
+	uc := usecase.NewLinkIdentityUsecase(repo)
+	uc.SetReportRepository(reports)
+
+	// A code asked for twice: only the newest works
+	old, _, err := uc.RequestCode(ctx, "Telegram", "12345")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	code, _, err := uc.RequestCode(ctx, "telegram", "12345")
+	if err != nil || len(code) != 6 {
+		t.Fatalf("Expected a 6-character code, got %q, %v", code, err)
+	}
+	if old != code {
+		if reply, _ := uc.Execute(ctx, "628111", []string{strings.ToLower(old)}); !strings.Contains(reply, "tidak dikenal") {
+			t.Errorf("Expected the old code refused, got %q", reply)
+		}
+	}
+	if userID, _ := uc.Resolve(ctx, "telegram", "12345"); userID != "" {
+		t.Errorf("Expected no member before #link, got %q", userID)
+	}
+
+	// Codes are not case-sensitive and work once
+	if reply, _ := uc.Execute(ctx, "628111", []string{strings.ToLower(code)}); !strings.Contains(reply, "telegram 12345 sekarang terhubung") {
+		t.Errorf("Expected the account linked, got %q", reply)
+	}
+	if reply, _ := uc.Execute(ctx, "628222", []string{code}); !strings.Contains(reply, "tidak dikenal") {
+		t.Errorf("Expected a used code refused, got %q", reply)
+	}
+	if userID, _ := uc.Resolve(ctx, "telegram", "12345"); userID != "628111" {
+		t.Errorf("Expected the account to resolve to 628111, got %q", userID)
+	}
+	if reply, _ := uc.Execute(ctx, "628111", nil); !strings.Contains(reply, "- telegram 12345") {
+		t.Errorf("Expected the linked account listed, got %q", reply)
+	}
+
+	// Reports through the link keep the name the member reports under
+	if name, _ := uc.MemberName(ctx, "628111", "alice_tg"); name != "alice_tg" {
+		t.Errorf("Expected the platform's name before the first report, got %q", name)
+	}
+	if err := reports.UpsertReport(ctx, &domain.Report{UserID: "628111", Name: "Alice", Streak: 1, ActivityCount: 1, LastReportDate: time.Now()}); err != nil {
+		t.Fatalf("Failed to seed report: %v", err)
+	}
+	if name, _ := uc.MemberName(ctx, "628111", "alice_tg"); name != "Alice" {
+		t.Errorf("Expected the stored name, got %q", name)
+	}
+
+	if err := repo.DeleteIdentities(ctx, "628111"); err != nil {
+		t.Fatalf("Failed to delete identities: %v", err)
+	}
+	if userID, _ := uc.Resolve(ctx, "telegram", "12345"); userID != "" {
+		t.Errorf("Expected the link gone after deleting, got %q", userID)
+	}
+}
+
+func TestIdentityRepository_InMyData(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := sqlite.NewIdentityRepository(db)
+	if err := repo.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize linked identities table: %v", err)
+	}
+	linkUC := usecase.NewLinkIdentityUsecase(repo)
+	code, _, err := linkUC.RequestCode(ctx, "telegram", "12345")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := linkUC.Execute(ctx, "628111", []string{code}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	exportUC := usecase.NewExportUserDataUsecase(reports, nil, nil)
+	exportUC.SetIdentities(repo)
+	export, err := exportUC.Collect(ctx, "628111")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(export.Identities) != 1 || export.Identities[0].Platform != "telegram" || export.Identities[0].ExternalID != "12345" {
+		t.Errorf("Expected the linked account in the export, got %+v", export.Identities)
+	}
+	if other, _ := exportUC.Collect(ctx, "628222"); len(other.Identities) != 0 {
+		t.Errorf("Expected no linked accounts for another member, got %+v", other.Identities)
+	}
+}
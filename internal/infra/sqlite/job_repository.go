@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+func (r *JobRepository) GetJob(ctx context.Context, name string) (*domain.Job, error) {
+	query := `SELECT name, kind, schedule, payload, next_run, last_run, last_error FROM scheduled_jobs WHERE name = ?`
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (r *JobRepository) GetJobs(ctx context.Context) ([]*domain.Job, error) {
+	query := `SELECT name, kind, schedule, payload, next_run, last_run, last_error FROM scheduled_jobs ORDER BY next_run ASC`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*domain.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *JobRepository) SaveJob(ctx context.Context, job *domain.Job) error {
+	query := `
+		INSERT INTO scheduled_jobs (name, kind, schedule, payload, next_run, last_run, last_error)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			kind = excluded.kind,
+			schedule = excluded.schedule,
+			payload = excluded.payload,
+			next_run = excluded.next_run,
+			last_run = excluded.last_run,
+			last_error = excluded.last_error
+	`
+	lastRun := ""
+	if !job.LastRun.IsZero() {
+		lastRun = job.LastRun.UTC().Format(time.RFC3339)
+	}
+	_, err := r.db.ExecContext(ctx, query, job.Name, job.Kind, job.Schedule, job.Payload,
+		job.NextRun.UTC().Format(time.RFC3339), lastRun, job.LastError)
+	return err
+}
+
+func (r *JobRepository) DeleteJob(ctx context.Context, name string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM scheduled_jobs WHERE name = ?`, name)
+	return err
+}
+
+func (r *JobRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS scheduled_jobs (
+			name TEXT PRIMARY KEY,
+			kind TEXT NOT NULL,
+			schedule TEXT NOT NULL DEFAULT '',
+			payload TEXT NOT NULL DEFAULT '',
+			next_run TEXT NOT NULL,
+			last_run TEXT NOT NULL DEFAULT '',
+			last_error TEXT NOT NULL DEFAULT ''
+		);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+func scanJob(row rowScanner) (*domain.Job, error) {
+	var job domain.Job
+	var nextRun, lastRun string
+	if err := row.Scan(&job.Name, &job.Kind, &job.Schedule, &job.Payload, &nextRun, &lastRun, &job.LastError); err != nil {
+		return nil, err
+	}
+
+	var err error
+	job.NextRun, err = time.Parse(time.RFC3339, nextRun)
+	if err != nil {
+		return nil, err
+	}
+	if lastRun != "" {
+		job.LastRun, err = time.Parse(time.RFC3339, lastRun)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &job, nil
+}
+
+// Backup implements domain.DatabaseBackup with VACUUM INTO, which writes a
+// consistent copy without stopping the bot.
+type Backup struct {
+	db *sql.DB
+}
+
+func NewBackup(db *sql.DB) *Backup {
+	return &Backup{db: db}
+}
+
+func (b *Backup) Backup(ctx context.Context, path string) error {
+	_, err := b.db.ExecContext(ctx, `VACUUM INTO ?`, path)
+	return err
+}
@@ -0,0 +1,107 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE JOB REPOSITORY TESTS
+// =============================================================================
+
+func TestJobRepository_SaveGetDelete(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := sqlite.NewJobRepository(db)
+	if err := repo.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize jobs table: %v", err)
+	}
+
+	missing, err := repo.GetJob(ctx, "recap")
+	if err != nil || missing != nil {
+		t.Fatalf("Expected nil for unknown job, got %+v, %v", missing, err)
+	}
+
+	next := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	recap := &domain.Job{Name: "recap", Kind: "recap", Schedule: "0 20 * * 0", NextRun: next}
+	once := &domain.Job{Name: "announce:1", Kind: "announce", Payload: "halo", NextRun: next.Add(-time.Hour)}
+	for _, job := range []*domain.Job{recap, once} {
+		if err := repo.SaveJob(ctx, job); err != nil {
+			t.Fatalf("Failed to save job: %v", err)
+		}
+	}
+
+	recap.LastRun, recap.LastError, recap.NextRun = next, "boom", next.AddDate(0, 0, 7)
+	if err := repo.SaveJob(ctx, recap); err != nil {
+		t.Fatalf("Failed to update job: %v", err)
+	}
+	got, err := repo.GetJob(ctx, "recap")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Schedule != "0 20 * * 0" || !got.LastRun.Equal(next) || got.LastError != "boom" || !got.NextRun.Equal(next.AddDate(0, 0, 7)) {
+		t.Errorf("Expected the updated job, got %+v", got)
+	}
+
+	jobs, err := repo.GetJobs(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].Name != "announce:1" || jobs[0].Payload != "halo" || !jobs[0].LastRun.IsZero() {
+		t.Fatalf("Expected the one-shot job first, got %+v", jobs)
+	}
+
+	if err := repo.DeleteJob(ctx, "announce:1"); err != nil {
+		t.Fatalf("Failed to delete job: %v", err)
+	}
+	if jobs, _ := repo.GetJobs(ctx); len(jobs) != 1 {
+		t.Errorf("Expected 1 job after delete, got %d", len(jobs))
+	}
+}
+
+func TestBackupDatabaseUsecase_KeepsNewestBackups(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	if err := reports.UpsertReport(ctx, &domain.Report{UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 3, LastReportDate: time.Now()}); err != nil {
+		t.Fatalf("Failed to seed report: %v", err)
+	}
+
+	dir := t.TempDir()
+	uc := usecase.NewBackupDatabaseUsecase(sqlite.NewBackup(db), dir, 2)
+	start := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	var last string
+	for day := 0; day < 3; day++ {
+		path, err := uc.Execute(ctx, start.AddDate(0, 0, day))
+		if err != nil {
+			t.Fatalf("Backup failed: %v", err)
+		}
+		last = path
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "*.db"))
+	if len(backups) != 2 || filepath.Base(backups[0]) != "backup-20260302-030000.db" {
+		t.Fatalf("Expected the two newest backups, got %v", backups)
+	}
+
+	copied, err := sql.Open("sqlite3", last)
+	if err != nil {
+		t.Fatalf("Failed to open backup: %v", err)
+	}
+	defer copied.Close()
+	report, err := sqlite.NewReportRepository(copied).GetReport(ctx, "user1")
+	if err != nil || report == nil || report.Streak != 3 {
+		t.Errorf("Expected the report in the backup, got %+v, %v", report, err)
+	}
+}
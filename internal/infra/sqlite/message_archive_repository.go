@@ -0,0 +1,205 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type MessageArchiveRepository struct {
+	db  *sql.DB
+	fts bool // FTS5 index available, otherwise search falls back to LIKE
+}
+
+func NewMessageArchiveRepository(db *sql.DB) *MessageArchiveRepository {
+	return &MessageArchiveRepository{db: db}
+}
+
+func (r *MessageArchiveRepository) ArchiveMessage(ctx context.Context, msg *domain.ArchivedMessage) error {
+	query := `
+		INSERT INTO message_archive (message_id, chat_id, sender_id, sender_name, text, media_type, media_mime, media_size, sent_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(message_id) DO NOTHING
+	`
+	res, err := r.db.ExecContext(ctx, query, msg.MessageID, msg.ChatID, msg.SenderID, msg.SenderName, msg.Text, msg.MediaType, msg.MediaMime, msg.MediaSize, msg.SentAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		msg.ID, _ = res.LastInsertId()
+	}
+	return nil
+}
+
+func (r *MessageArchiveRepository) GetMessages(ctx context.Context, filter domain.MessageFilter) ([]*domain.ArchivedMessage, error) {
+	where, args := messageWhere(filter)
+	query := `SELECT id, message_id, chat_id, sender_id, sender_name, text, media_type, media_mime, media_size, sent_at FROM message_archive` + where
+	query += " ORDER BY sent_at ASC, id ASC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	return r.queryMessages(ctx, query, args...)
+}
+
+func (r *MessageArchiveRepository) queryMessages(ctx context.Context, query string, args ...interface{}) ([]*domain.ArchivedMessage, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*domain.ArchivedMessage
+	for rows.Next() {
+		var m domain.ArchivedMessage
+		var sentAt string
+		if err := rows.Scan(&m.ID, &m.MessageID, &m.ChatID, &m.SenderID, &m.SenderName, &m.Text, &m.MediaType, &m.MediaMime, &m.MediaSize, &sentAt); err != nil {
+			return nil, err
+		}
+		m.SentAt, err = time.Parse(time.RFC3339, sentAt)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, &m)
+	}
+	return messages, rows.Err()
+}
+
+func (r *MessageArchiveRepository) SearchMessages(ctx context.Context, keyword string, filter domain.MessageFilter) ([]*domain.ArchivedMessage, error) {
+	words := strings.Fields(keyword)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	where, args := messageWhere(filter)
+	var match []string
+	var matchArgs []interface{}
+	if r.fts {
+		// Quote every word so FTS5 operators in user input are taken literally
+		quoted := make([]string, len(words))
+		for i, w := range words {
+			quoted[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+		}
+		match = append(match, "id IN (SELECT rowid FROM message_archive_fts WHERE message_archive_fts MATCH ?)")
+		matchArgs = append(matchArgs, strings.Join(quoted, " "))
+	} else {
+		for _, w := range words {
+			match = append(match, "text LIKE ? ESCAPE '\\'")
+			matchArgs = append(matchArgs, "%"+escapeLike(w)+"%")
+		}
+	}
+
+	if where == "" {
+		where = " WHERE " + strings.Join(match, " AND ")
+	} else {
+		where += " AND " + strings.Join(match, " AND ")
+	}
+	args = append(args, matchArgs...)
+
+	query := `SELECT id, message_id, chat_id, sender_id, sender_name, text, media_type, media_mime, media_size, sent_at FROM message_archive` + where
+	query += " ORDER BY sent_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	return r.queryMessages(ctx, query, args...)
+}
+
+// escapeLike escapes LIKE wildcards so they match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *MessageArchiveRepository) DeleteMessages(ctx context.Context, filter domain.MessageFilter) error {
+	where, args := messageWhere(filter)
+	_, err := r.db.ExecContext(ctx, `DELETE FROM message_archive`+where, args...)
+	return err
+}
+
+// messageWhere builds the WHERE clause for a filter. Timestamps are stored
+// as UTC RFC3339 so string comparison matches time order.
+func messageWhere(filter domain.MessageFilter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if filter.ChatID != "" {
+		conds = append(conds, "chat_id = ?")
+		args = append(args, filter.ChatID)
+	}
+	if filter.SenderID != "" {
+		conds = append(conds, "sender_id = ?")
+		args = append(args, filter.SenderID)
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, "sent_at >= ?")
+		args = append(args, filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		conds = append(conds, "sent_at < ?")
+		args = append(args, filter.Until.UTC().Format(time.RFC3339))
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (r *MessageArchiveRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS message_archive (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			message_id TEXT NOT NULL UNIQUE,
+			chat_id TEXT NOT NULL,
+			sender_id TEXT NOT NULL,
+			sender_name TEXT NOT NULL DEFAULT '',
+			text TEXT NOT NULL DEFAULT '',
+			media_type TEXT NOT NULL DEFAULT '',
+			media_mime TEXT NOT NULL DEFAULT '',
+			media_size INTEGER NOT NULL DEFAULT 0,
+			sent_at TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_message_archive_sent_at ON message_archive(sent_at);
+		CREATE INDEX IF NOT EXISTS idx_message_archive_sender_id ON message_archive(sender_id);
+	`
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	r.fts = r.initFTS(ctx) == nil
+	return nil
+}
+
+// initFTS sets up an FTS5 index kept in sync by triggers. Drivers built
+// without FTS5 fail here, and search falls back to LIKE.
+func (r *MessageArchiveRepository) initFTS(ctx context.Context) error {
+	var exists int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE name = 'message_archive_fts'`).Scan(&exists); err != nil {
+		return err
+	}
+
+	query := `
+		CREATE VIRTUAL TABLE IF NOT EXISTS message_archive_fts USING fts5(
+			text, sender_name, content='message_archive', content_rowid='id'
+		);
+		CREATE TRIGGER IF NOT EXISTS message_archive_fts_insert AFTER INSERT ON message_archive BEGIN
+			INSERT INTO message_archive_fts(rowid, text, sender_name) VALUES (new.id, new.text, new.sender_name);
+		END;
+		CREATE TRIGGER IF NOT EXISTS message_archive_fts_delete AFTER DELETE ON message_archive BEGIN
+			INSERT INTO message_archive_fts(message_archive_fts, rowid, text, sender_name) VALUES ('delete', old.id, old.text, old.sender_name);
+		END;
+	`
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	// Index messages archived before the FTS table existed
+	if exists == 0 {
+		_, err := r.db.ExecContext(ctx, `INSERT INTO message_archive_fts(message_archive_fts) VALUES ('rebuild')`)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE MESSAGE ARCHIVE REPOSITORY TESTS
+// =============================================================================
+
+func setupMessageArchiveRepo(t *testing.T) (*sqlite.MessageArchiveRepository, func()) {
+	t.Helper()
+
+	db, _, cleanup := setupTestDB(t)
+	repo := sqlite.NewMessageArchiveRepository(db)
+	if err := repo.InitTable(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize message archive table: %v", err)
+	}
+	return repo, cleanup
+}
+
+func TestMessageArchiveRepository_ArchiveAndFilter(t *testing.T) {
+	repo, cleanup := setupMessageArchiveRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+	messages := []*domain.ArchivedMessage{
+		{MessageID: "A1", ChatID: "group", SenderID: "user1", SenderName: "Alice", Text: "#lapor lari", SentAt: base},
+		{MessageID: "B1", ChatID: "group", SenderID: "user2", SenderName: "Bob", MediaType: "image", MediaMime: "image/jpeg", MediaSize: 2048, SentAt: base.Add(time.Hour)},
+		{MessageID: "A2", ChatID: "group", SenderID: "user1", SenderName: "Alice", Text: "semangat!", SentAt: base.AddDate(0, 0, 1)},
+	}
+	for _, m := range messages {
+		if err := repo.ArchiveMessage(ctx, m); err != nil {
+			t.Fatalf("Failed to archive message: %v", err)
+		}
+	}
+
+	// Redelivered messages are stored once
+	if err := repo.ArchiveMessage(ctx, &domain.ArchivedMessage{MessageID: "A1", ChatID: "group", SenderID: "user1", SentAt: base}); err != nil {
+		t.Fatalf("Failed to archive duplicate: %v", err)
+	}
+
+	all, err := repo.GetMessages(ctx, domain.MessageFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 archived messages, got %d", len(all))
+	}
+	if all[1].MediaType != "image" || all[1].MediaSize != 2048 {
+		t.Errorf("Expected media metadata to round-trip, got %+v", all[1])
+	}
+
+	alice, err := repo.GetMessages(ctx, domain.MessageFilter{SenderID: "user1", Until: base.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(alice) != 1 || alice[0].Text != "#lapor lari" {
+		t.Errorf("Expected Alice's first message only, got %+v", alice)
+	}
+
+	if err := repo.DeleteMessages(ctx, domain.MessageFilter{Until: base.AddDate(0, 0, 1)}); err != nil {
+		t.Fatalf("Failed to delete messages: %v", err)
+	}
+	left, _ := repo.GetMessages(ctx, domain.MessageFilter{})
+	if len(left) != 1 || left[0].MessageID != "A2" {
+		t.Errorf("Expected only A2 left, got %+v", left)
+	}
+}
@@ -0,0 +1,87 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// The test driver (mattn/go-sqlite3) is built without FTS5 and exercises the
+// LIKE fallback; the runtime driver (modernc.org/sqlite) ships FTS5.
+func TestMessageArchiveRepository_Search(t *testing.T) {
+	for _, driver := range []string{"sqlite3", "sqlite"} {
+		t.Run(driver, func(t *testing.T) {
+			db, err := sql.Open(driver, ":memory:")
+			if err != nil {
+				t.Fatalf("Failed to open database: %v", err)
+			}
+			defer db.Close()
+			db.SetMaxOpenConns(1)
+
+			ctx := context.Background()
+			repo := sqlite.NewMessageArchiveRepository(db)
+
+			base := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+			if err := repo.InitTable(ctx); err != nil {
+				t.Fatalf("Failed to initialize table: %v", err)
+			}
+			messages := []*domain.ArchivedMessage{
+				{MessageID: "1", ChatID: "g", SenderID: "user1", SenderName: "Alice", Text: "#lapor lari pagi 5km", SentAt: base},
+				{MessageID: "2", ChatID: "g", SenderID: "user2", SenderName: "Bob", Text: "#lapor gym", SentAt: base.Add(time.Hour)},
+				{MessageID: "3", ChatID: "g", SenderID: "user1", SenderName: "Alice", Text: "Lari sore 100% santai", SentAt: base.AddDate(0, 0, 1)},
+			}
+			for _, m := range messages {
+				if err := repo.ArchiveMessage(ctx, m); err != nil {
+					t.Fatalf("Failed to archive message: %v", err)
+				}
+			}
+
+			found, err := repo.SearchMessages(ctx, "lari", domain.MessageFilter{})
+			if err != nil {
+				t.Fatalf("Failed to search: %v", err)
+			}
+			if len(found) != 2 || found[0].MessageID != "3" {
+				t.Errorf("Expected 2 matches newest first, got %+v", found)
+			}
+
+			found, err = repo.SearchMessages(ctx, "lari pagi", domain.MessageFilter{})
+			if err != nil {
+				t.Fatalf("Failed to search: %v", err)
+			}
+			if len(found) != 1 || found[0].MessageID != "1" {
+				t.Errorf("Expected all words to match, got %+v", found)
+			}
+
+			found, err = repo.SearchMessages(ctx, "lari", domain.MessageFilter{Since: base.AddDate(0, 0, 1)})
+			if err != nil {
+				t.Fatalf("Failed to search: %v", err)
+			}
+			if len(found) != 1 || found[0].MessageID != "3" {
+				t.Errorf("Expected date filter to apply, got %+v", found)
+			}
+
+			// Operators in user input must not break the query
+			if _, err := repo.SearchMessages(ctx, `"lari" OR -`, domain.MessageFilter{}); err != nil {
+				t.Errorf("Expected special characters to be escaped, got %v", err)
+			}
+
+			// Deleted messages disappear from the index
+			if err := repo.DeleteMessages(ctx, domain.MessageFilter{SenderID: "user1"}); err != nil {
+				t.Fatalf("Failed to delete: %v", err)
+			}
+			found, err = repo.SearchMessages(ctx, "lari", domain.MessageFilter{})
+			if err != nil {
+				t.Fatalf("Failed to search: %v", err)
+			}
+			if len(found) != 0 {
+				t.Errorf("Expected no matches after delete, got %+v", found)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE OUTBOX REPOSITORY TESTS
+// =============================================================================
+
+func TestOutboxRepository_Receipts(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := sqlite.NewOutboxRepository(db)
+	if err := repo.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize outbox table: %v", err)
+	}
+
+	sent := time.Date(2026, 3, 1, 19, 30, 0, 0, time.UTC)
+	for i, id := range []string{"MSG1", "MSG2"} {
+		msg := &domain.OutboxMessage{MessageID: id, ChatID: "120363@g.us", Text: "Pengumuman", SentAt: sent.Add(time.Duration(i) * time.Minute)}
+		if err := repo.RecordSent(ctx, msg); err != nil {
+			t.Fatalf("Failed to record sent message: %v", err)
+		}
+	}
+
+	// Two members get MSG1, one reads it; nobody gets MSG2. Unknown IDs
+	// are ignored.
+	delivered, read := sent.Add(time.Minute), sent.Add(5*time.Minute)
+	if err := repo.RecordReceipt(ctx, []string{"MSG1", "OTHER"}, false, delivered); err != nil {
+		t.Fatalf("Failed to record receipt: %v", err)
+	}
+	if err := repo.RecordReceipt(ctx, []string{"MSG1"}, false, delivered.Add(time.Minute)); err != nil {
+		t.Fatalf("Failed to record receipt: %v", err)
+	}
+	if err := repo.RecordReceipt(ctx, []string{"MSG1"}, true, read); err != nil {
+		t.Fatalf("Failed to record receipt: %v", err)
+	}
+
+	messages, err := repo.GetOutbox(ctx, domain.OutboxFilter{ChatID: "120363@g.us"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(messages) != 2 || messages[0].MessageID != "MSG2" {
+		t.Fatalf("Expected both messages, newest first, got %+v", messages)
+	}
+	if m := messages[1]; !m.DeliveredAt.Equal(delivered) || !m.ReadAt.Equal(read) || m.DeliveredCount != 2 || m.ReadCount != 1 {
+		t.Errorf("Expected MSG1 delivered twice and read once, got %+v", m)
+	}
+	if m := messages[0]; !m.DeliveredAt.IsZero() || m.DeliveredCount != 0 {
+		t.Errorf("Expected MSG2 undelivered, got %+v", m)
+	}
+
+	if err := repo.DeleteOutbox(ctx, domain.OutboxFilter{Until: sent.Add(time.Minute)}); err != nil {
+		t.Fatalf("Failed to delete outbox: %v", err)
+	}
+	if messages, _ := repo.GetOutbox(ctx, domain.OutboxFilter{}); len(messages) != 1 || messages[0].MessageID != "MSG2" {
+		t.Errorf("Expected only MSG2 left, got %+v", messages)
+	}
+}
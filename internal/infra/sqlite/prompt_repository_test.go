@@ -0,0 +1,43 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE REPORT PROMPT TESTS
+// =============================================================================
+
+func TestPromptRepository_SaveAndGet(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := sqlite.NewPromptRepository(db)
+	if err := repo.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize prompts table: %v", err)
+	}
+
+	sent := time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)
+	prompt := &domain.ReportPrompt{MessageID: "MSG1", ChatID: "120363@g.us", SentAt: sent}
+	if err := repo.SavePrompt(ctx, prompt); err != nil {
+		t.Fatalf("Failed to save prompt: %v", err)
+	}
+	// Saving again is a no-op
+	if err := repo.SavePrompt(ctx, prompt); err != nil {
+		t.Fatalf("Failed to save prompt twice: %v", err)
+	}
+
+	got, err := repo.GetPrompt(ctx, "120363@g.us", "MSG1")
+	if err != nil || got == nil || !got.SentAt.Equal(sent) {
+		t.Errorf("Expected the prompt back, got %+v, %v", got, err)
+	}
+	if got, _ := repo.GetPrompt(ctx, "other@g.us", "MSG1"); got != nil {
+		t.Errorf("Expected no prompt in another chat, got %+v", got)
+	}
+}
@@ -0,0 +1,58 @@
+package sqlite_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE QUOTE ROTATION TESTS
+// =============================================================================
+
+func TestDailyQuote_RotatesWithoutRepeats(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := sqlite.NewQuoteRepository(db)
+	if err := repo.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize quotes table: %v", err)
+	}
+
+	quotes, err := usecase.ParseQuoteList(strings.NewReader("# Kutipan\nSedikit demi sedikit.\n\nTubuh kuat, pikiran sehat.\nKonsisten mengalahkan intensitas.\n"))
+	if err != nil || len(quotes) != 3 {
+		t.Fatalf("Expected 3 quotes without the comment and blank line, got %q, %v", quotes, err)
+	}
+	uc := usecase.NewDailyQuoteUsecase(quotes, repo)
+
+	day1 := time.Date(2026, 3, 1, 6, 0, 0, 0, time.Local)
+	seen := make(map[string]bool)
+	var last string
+	for i := 0; i < 3; i++ {
+		quote, err := uc.Today(ctx, day1.AddDate(0, 0, i))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if seen[quote] {
+			t.Fatalf("Expected no repeat within the rotation, got %q twice", quote)
+		}
+		seen[quote], last = true, quote
+	}
+	if again, _ := uc.Today(ctx, day1.AddDate(0, 0, 2).Add(12*time.Hour)); again != last {
+		t.Errorf("Expected the same quote all day, got %q and %q", last, again)
+	}
+
+	// Every quote had its turn: the rotation starts over
+	next, err := uc.Today(ctx, day1.AddDate(0, 0, 3))
+	if err != nil || next == "" || next == last {
+		t.Errorf("Expected a new rotation not starting with %q, got %q, %v", last, next, err)
+	}
+	if uses, _ := repo.GetQuoteUses(ctx); len(uses) != 1 {
+		t.Errorf("Expected only the new rotation stored, got %d uses", len(uses))
+	}
+}
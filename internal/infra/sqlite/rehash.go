@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+)
+
+// userIDColumns are the columns that hold a member's user ID.
+var userIDColumns = []struct{ table, column string }{
+	{"user_reports", "user_id"},
+	{"user_settings", "user_id"},
+	{"activity_logs", "user_id"},
+	{"report_events", "user_id"},
+	{"conversations", "user_id"},
+	{"linked_identities", "user_id"},
+	{"leaderboard_snapshots", "user_id"},
+	{"message_archive", "sender_id"},
+}
+
+// RehashUserIDs moves members stored under their phone number to the hashed
+// user ID, for bots that set USER_ID_SALT after members started reporting.
+// IDs that are already hashed are left alone, so it can run on every start.
+// A row whose hashed ID is taken, e.g. a member who reported again before
+// the move, keeps the number and is counted in skipped.
+func RehashUserIDs(ctx context.Context, db *sql.DB, hasher *phone.Hasher) (moved, skipped int64, err error) {
+	if hasher == nil {
+		return 0, 0, nil
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, c := range userIDColumns {
+		ids, err := plainUserIDs(ctx, tx, c.table, c.column)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read %s: %w", c.table, err)
+		}
+		for _, id := range ids {
+			query := fmt.Sprintf(`UPDATE OR IGNORE %s SET %s = ? WHERE %s = ?`, c.table, c.column, c.column)
+			res, err := tx.ExecContext(ctx, query, hasher.UserID(id), id)
+			if err != nil {
+				return 0, 0, fmt.Errorf("failed to rehash %s: %w", c.table, err)
+			}
+			n, _ := res.RowsAffected()
+			moved += n
+
+			var left int64
+			query = fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s = ?`, c.table, c.column)
+			if err := tx.QueryRowContext(ctx, query, id).Scan(&left); err != nil {
+				return 0, 0, err
+			}
+			skipped += left
+		}
+	}
+	return moved, skipped, tx.Commit()
+}
+
+// plainUserIDs returns the user IDs in a column that are not hashed yet,
+// none when the table does not exist.
+func plainUserIDs(ctx context.Context, tx *sql.Tx, table, column string) ([]string, error) {
+	var exists int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&exists); err != nil || exists == 0 {
+		return nil, err
+	}
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT DISTINCT %s FROM %s`, column, table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		if id != "" && !phone.IsHashed(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,77 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// USER ID REHASH TESTS
+// =============================================================================
+
+func TestRehashUserIDs_MovesNumbersToHashes(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	settings := sqlite.NewSettingsRepository(db)
+	if err := settings.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize settings table: %v", err)
+	}
+	activities := sqlite.NewActivityRepository(db)
+	if err := activities.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize activity table: %v", err)
+	}
+
+	// Alice and Bob reported before USER_ID_SALT was set; Bob reported
+	// again after, under his hash
+	hasher := phone.NewHasher("rahasia")
+	now := time.Now()
+	for _, r := range []*domain.Report{
+		{UserID: "628111", Name: "Alice", Streak: 5, ActivityCount: 5, LastReportDate: now},
+		{UserID: "628222", Name: "Bob", Streak: 3, ActivityCount: 3, LastReportDate: now},
+		{UserID: hasher.UserID("628222"), Name: "Bob", Streak: 1, ActivityCount: 1, LastReportDate: now},
+	} {
+		if err := reports.UpsertReport(ctx, r); err != nil {
+			t.Fatalf("Failed to seed report: %v", err)
+		}
+	}
+	if err := settings.SaveSettings(ctx, &domain.UserSettings{UserID: "628111", Target: 20}); err != nil {
+		t.Fatalf("Failed to seed settings: %v", err)
+	}
+	if err := activities.AddActivity(ctx, &domain.Activity{UserID: "628111", Name: "Alice", ActivityType: "lari", ReportedAt: now}); err != nil {
+		t.Fatalf("Failed to seed activity: %v", err)
+	}
+
+	moved, skipped, err := sqlite.RehashUserIDs(ctx, db, hasher)
+	if err != nil {
+		t.Fatalf("RehashUserIDs failed: %v", err)
+	}
+	if moved != 3 || skipped != 1 {
+		t.Errorf("Expected 3 rows moved and Bob's old row skipped, got %d moved, %d skipped", moved, skipped)
+	}
+
+	alice, _ := reports.GetReport(ctx, hasher.UserID("628111"))
+	if alice == nil || alice.Streak != 5 {
+		t.Errorf("Expected Alice's report under her hash, got %+v", alice)
+	}
+	if old, _ := reports.GetReport(ctx, "628111"); old != nil {
+		t.Errorf("Expected no report left under Alice's number, got %+v", old)
+	}
+	if s, _ := settings.GetSettings(ctx, hasher.UserID("628111")); s == nil || s.Target != 20 {
+		t.Errorf("Expected Alice's settings under her hash, got %+v", s)
+	}
+	if logged, _ := activities.GetActivities(ctx, domain.ActivityFilter{UserID: hasher.UserID("628111")}); len(logged) != 1 {
+		t.Errorf("Expected Alice's activity under her hash, got %+v", logged)
+	}
+
+	// A second run has nothing left to move
+	if moved, _, err := sqlite.RehashUserIDs(ctx, db, hasher); err != nil || moved != 0 {
+		t.Errorf("Expected nothing moved on the next start, got %d, %v", moved, err)
+	}
+}
@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type ReportEventRepository struct {
+	db *sql.DB
+}
+
+func NewReportEventRepository(db *sql.DB) *ReportEventRepository {
+	return &ReportEventRepository{db: db}
+}
+
+const reportEventColumns = `id, user_id, type, at, name, streak, activity_count, last_report_date, ref_id, actor, note`
+
+func (r *ReportEventRepository) AppendEvent(ctx context.Context, event *domain.ReportEvent) error {
+	query := `
+		INSERT INTO report_events (user_id, type, at, name, streak, activity_count, last_report_date, ref_id, actor, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	var lastReportDate string
+	if !event.LastReportDate.IsZero() {
+		lastReportDate = event.LastReportDate.Format(time.RFC3339)
+	}
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, event.UserID, event.Type, event.At.Format(time.RFC3339),
+		event.Name, event.Streak, event.ActivityCount, lastReportDate, event.RefID, event.Actor, event.Note)
+	if err != nil {
+		return err
+	}
+	event.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *ReportEventRepository) GetEvents(ctx context.Context, userID string) ([]*domain.ReportEvent, error) {
+	query := `SELECT ` + reportEventColumns + ` FROM report_events WHERE user_id = ? ORDER BY id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.ReportEvent
+	for rows.Next() {
+		event, err := scanReportEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func scanReportEvent(row rowScanner) (*domain.ReportEvent, error) {
+	var event domain.ReportEvent
+	var at, lastReportDate string
+	err := row.Scan(&event.ID, &event.UserID, &event.Type, &at, &event.Name, &event.Streak,
+		&event.ActivityCount, &lastReportDate, &event.RefID, &event.Actor, &event.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	event.At, err = time.Parse(time.RFC3339, at)
+	if err != nil {
+		return nil, err
+	}
+	if lastReportDate != "" {
+		event.LastReportDate, err = time.Parse(time.RFC3339, lastReportDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &event, nil
+}
+
+func (r *ReportEventRepository) EventUserIDs(ctx context.Context) ([]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT DISTINCT user_id FROM report_events ORDER BY user_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+func (r *ReportEventRepository) DeleteEvents(ctx context.Context, userID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM report_events WHERE user_id = ?`, userID)
+	return err
+}
+
+func (r *ReportEventRepository) InitTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS report_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			at TEXT NOT NULL,
+			name TEXT NOT NULL DEFAULT '',
+			streak INTEGER NOT NULL DEFAULT 0,
+			activity_count INTEGER NOT NULL DEFAULT 0,
+			last_report_date TEXT NOT NULL DEFAULT '',
+			ref_id INTEGER NOT NULL DEFAULT 0,
+			actor TEXT NOT NULL DEFAULT '',
+			note TEXT NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_report_events_user_id ON report_events(user_id, id);
+	`
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	// Reports written before the event stream existed, or by tools that
+	// write user_reports directly, start their history with a snapshot of
+	// the current row
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO report_events (user_id, type, at, name, streak, activity_count, last_report_date, actor, note)
+		SELECT user_id, ?, ?, IFNULL(name, ''), IFNULL(streak, 0), IFNULL(activity_count, 0), IFNULL(last_report_date, ''), 'system', 'baseline'
+		FROM user_reports
+		WHERE user_id NOT IN (SELECT user_id FROM report_events)
+	`, domain.AdminAdjusted, time.Now().Format(time.RFC3339))
+	return err
+}
@@ -0,0 +1,382 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE REPORT EVENT TESTS
+// =============================================================================
+
+func TestReportEventRepository_InitTable_BaselineForExistingReports(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	yesterday := time.Now().AddDate(0, 0, -1).Truncate(time.Second)
+	if err := reports.UpsertReport(ctx, &domain.Report{UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 10, LastReportDate: yesterday}); err != nil {
+		t.Fatalf("Failed to seed report: %v", err)
+	}
+
+	events := sqlite.NewReportEventRepository(db)
+	// Twice, as on every start: the baseline is only added once
+	for i := 0; i < 2; i++ {
+		if err := events.InitTable(ctx); err != nil {
+			t.Fatalf("Failed to initialize events table: %v", err)
+		}
+	}
+
+	history, err := events.GetEvents(ctx, "user1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(history) != 1 || history[0].Type != domain.AdminAdjusted || history[0].Actor != "system" {
+		t.Fatalf("Expected one baseline event, got %+v", history)
+	}
+
+	projected := domain.ProjectReport("user1", history)
+	if projected.Streak != 3 || projected.ActivityCount != 10 || !projected.LastReportDate.Equal(yesterday) {
+		t.Errorf("Expected the baseline to project the seeded report, got %+v", projected)
+	}
+}
+
+func TestReportEventsUsecase_UndoAndResetStreak(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	yesterday := time.Now().AddDate(0, 0, -1).Truncate(time.Second)
+	if err := reports.UpsertReport(ctx, &domain.Report{UserID: "user1", Name: "Alice", Streak: 3, ActivityCount: 10, LastReportDate: yesterday}); err != nil {
+		t.Fatalf("Failed to seed report: %v", err)
+	}
+	activities := sqlite.NewActivityRepository(db)
+	events := sqlite.NewReportEventRepository(db)
+	for _, init := range []func(context.Context) error{activities.InitTable, events.InitTable} {
+		if err := init(ctx); err != nil {
+			t.Fatalf("Failed to initialize table: %v", err)
+		}
+	}
+	tx := sqlite.NewTransactor(db)
+
+	reportUC := usecase.NewReportActivityUsecase(reports)
+	reportUC.SetActivityRepository(activities)
+	reportUC.SetEventRepository(events)
+	reportUC.SetTransactor(tx)
+	eventsUC := usecase.NewReportEventsUsecase(reports, events)
+	eventsUC.SetActivityRepository(activities)
+	eventsUC.SetTransactor(tx)
+
+	if _, err := reportUC.ExecuteWithMessage(ctx, "user1", "Alice", "#lapor lari 30 menit"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report, _ := reports.GetReport(ctx, "user1"); report.Streak != 4 || report.ActivityCount != 11 {
+		t.Fatalf("Expected streak 4 and 11 days after #lapor, got %+v", report)
+	}
+
+	report, err := eventsUC.Undo(ctx, "user1", "admin")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Streak != 3 || report.ActivityCount != 10 || !report.LastReportDate.Equal(yesterday) {
+		t.Errorf("Expected the report from before #lapor, got %+v", report)
+	}
+	stored, _ := reports.GetReport(ctx, "user1")
+	if stored.Streak != 3 || stored.ActivityCount != 10 {
+		t.Errorf("Expected the undo to be stored, got %+v", stored)
+	}
+	logged, err := activities.GetActivities(ctx, domain.ActivityFilter{UserID: "user1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(logged) != 0 {
+		t.Errorf("Expected the activity of the undone report to be removed, got %d", len(logged))
+	}
+
+	if _, err := eventsUC.Undo(ctx, "user1", "admin"); !errors.Is(err, usecase.ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo for the baseline, got %v", err)
+	}
+
+	report, err = eventsUC.ResetStreak(ctx, "user1", "admin")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Streak != 0 || report.ActivityCount != 10 {
+		t.Errorf("Expected streak 0 and the total kept, got %+v", report)
+	}
+
+	// The stream and user_reports agree, so a rebuild changes nothing
+	changed, err := eventsUC.RebuildAll(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if changed != 0 {
+		t.Errorf("Expected no changes from a rebuild, got %d", changed)
+	}
+}
+
+func TestReportEventsUsecase_RebuildAll_RestoresProjection(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	events := sqlite.NewReportEventRepository(db)
+	if err := events.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize events table: %v", err)
+	}
+
+	start := time.Now().AddDate(0, 0, -3)
+	for day := 0; day < 3; day++ {
+		event := &domain.ReportEvent{UserID: "user1", Type: domain.ReportSubmitted, At: start.AddDate(0, 0, day), Name: "Alice", Actor: "user1"}
+		if err := events.AppendEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to append event: %v", err)
+		}
+	}
+	// A row edited outside the bot
+	if err := reports.UpsertReport(ctx, &domain.Report{UserID: "user1", Name: "Alice", Streak: 9, ActivityCount: 9, LastReportDate: start}); err != nil {
+		t.Fatalf("Failed to seed report: %v", err)
+	}
+
+	uc := usecase.NewReportEventsUsecase(reports, events)
+	changed, err := uc.RebuildAll(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("Expected 1 changed report, got %d", changed)
+	}
+
+	report, _ := reports.GetReport(ctx, "user1")
+	if report.Streak != 3 || report.ActivityCount != 3 {
+		t.Errorf("Expected streak 3 and 3 days from the events, got %+v", report)
+	}
+}
+
+func TestBackfillReports_FromHistory(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	events := sqlite.NewReportEventRepository(db)
+	if err := events.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize events table: %v", err)
+	}
+	activities := sqlite.NewActivityRepository(db)
+	if err := activities.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize activity table: %v", err)
+	}
+
+	// Alice reported live today, after the bot was linked
+	today := time.Date(2026, 3, 5, 19, 0, 0, 0, time.UTC)
+	live := &domain.ReportEvent{UserID: "user1", Type: domain.ReportSubmitted, At: today, Name: "Alice", Actor: "user1"}
+	if err := events.AppendEvent(ctx, live); err != nil {
+		t.Fatalf("Failed to seed event: %v", err)
+	}
+	if err := reports.UpsertReport(ctx, &domain.Report{UserID: "user1", Name: "Alice", Streak: 1, ActivityCount: 1, LastReportDate: today}); err != nil {
+		t.Fatalf("Failed to seed report: %v", err)
+	}
+
+	eventsUC := usecase.NewReportEventsUsecase(reports, events)
+	uc := usecase.NewBackfillReportsUsecase(eventsUC)
+	uc.SetActivityRepository(activities)
+
+	// The four days before, with a duplicate on one of them, plus today again
+	var history []usecase.HistoricalReport
+	for day := 4; day >= 1; day-- {
+		history = append(history, usecase.HistoricalReport{UserID: "user1", Name: "Alice", Message: "#lapor lari", At: today.AddDate(0, 0, -day)})
+	}
+	history = append(history,
+		usecase.HistoricalReport{UserID: "user1", Name: "Alice", Message: "#lapor", At: today.AddDate(0, 0, -1).Add(time.Hour)},
+		usecase.HistoricalReport{UserID: "user1", Name: "Alice", Message: "#lapor", At: today.Add(-time.Hour)},
+		usecase.HistoricalReport{UserID: "user2", Name: "Bob", Message: "#lapor", At: today.AddDate(0, 0, -10)},
+	)
+
+	added, err := uc.Backfill(ctx, history)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if added != 5 {
+		t.Errorf("Expected 5 reports added, got %d", added)
+	}
+
+	alice, _ := reports.GetReport(ctx, "user1")
+	if alice.Streak != 5 || alice.ActivityCount != 5 || !alice.LastReportDate.Equal(today) {
+		t.Errorf("Expected Alice's streak rebuilt to 5 days up to today, got %+v", alice)
+	}
+	bob, _ := reports.GetReport(ctx, "user2")
+	if bob == nil || bob.Streak != 1 || bob.ActivityCount != 1 {
+		t.Errorf("Expected Bob counted from history, got %+v", bob)
+	}
+	logged, _ := activities.GetActivities(ctx, domain.ActivityFilter{UserID: "user1"})
+	if len(logged) != 4 || logged[0].ActivityType != "lari" {
+		t.Errorf("Expected the 4 backfilled reports in the activity log, got %+v", logged)
+	}
+
+	// Syncing the same history again adds nothing
+	if added, err := uc.Backfill(ctx, history); err != nil || added != 0 {
+		t.Errorf("Expected nothing added on a second sync, got %d, %v", added, err)
+	}
+}
+
+func TestBackfillReports_AdminCommandFromArchive(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	events := sqlite.NewReportEventRepository(db)
+	if err := events.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize events table: %v", err)
+	}
+	archive := sqlite.NewMessageArchiveRepository(db)
+	if err := archive.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize archive table: %v", err)
+	}
+
+	// Two reports the bot archived but never counted, one older than the
+	// window, and a chat message that is not a report
+	now := time.Now()
+	messages := []*domain.ArchivedMessage{
+		{MessageID: "m1", ChatID: "group@g.us", SenderID: "user1", SenderName: "Alice", Text: "#lapor lari", SentAt: now.AddDate(0, 0, -1)},
+		{MessageID: "m2", ChatID: "group@g.us", SenderID: "user1", SenderName: "Alice", Text: "#LAPOR", SentAt: now},
+		{MessageID: "m3", ChatID: "group@g.us", SenderID: "user2", SenderName: "Bob", Text: "#lapor", SentAt: now.AddDate(0, 0, -10)},
+		{MessageID: "m4", ChatID: "group@g.us", SenderID: "user2", SenderName: "Bob", Text: "semangat!", SentAt: now},
+	}
+	for _, m := range messages {
+		if err := archive.ArchiveMessage(ctx, m); err != nil {
+			t.Fatalf("Failed to archive message: %v", err)
+		}
+	}
+
+	backfillUC := usecase.NewBackfillReportsUsecase(usecase.NewReportEventsUsecase(reports, events))
+	backfillUC.SetMessageArchive(archive)
+	uc := usecase.NewHandleMessageUsecase(usecase.NewReportActivityUsecase(reports), usecase.NewGetLeaderboardUsecase(reports))
+	uc.SetAdmins([]string{"admin"})
+	uc.SetBackfillUsecase(backfillUC)
+
+	if reply, _ := uc.Execute(ctx, "user1", "Alice", "#admin backfill 3"); reply != "Perintah ini khusus admin." {
+		t.Errorf("Expected the command to be admin-only, got %q", reply)
+	}
+	if reply, _ := uc.Execute(ctx, "admin", "Admin", "#admin backfill seminggu"); !strings.HasPrefix(reply, "Format:") {
+		t.Errorf("Expected the usage for a bad number of days, got %q", reply)
+	}
+
+	reply, err := uc.Execute(ctx, "admin", "Admin", "#admin backfill 3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(reply, "2 #lapor yang terlewat") {
+		t.Errorf("Expected 2 reports recovered, got %q", reply)
+	}
+	alice, _ := reports.GetReport(ctx, "user1")
+	if alice == nil || alice.Streak != 2 || alice.ActivityCount != 2 {
+		t.Errorf("Expected Alice's 2 days counted, got %+v", alice)
+	}
+	if bob, _ := reports.GetReport(ctx, "user2"); bob != nil {
+		t.Errorf("Expected Bob's report outside the window left alone, got %+v", bob)
+	}
+
+	// Running it again finds everything counted
+	if reply, _ := uc.Execute(ctx, "admin", "Admin", "#admin backfill 3"); !strings.Contains(reply, "sudah tercatat") {
+		t.Errorf("Expected nothing left to recover, got %q", reply)
+	}
+}
+
+func TestBackfillReports_ImportWhatsAppExport(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	events := sqlite.NewReportEventRepository(db)
+	if err := events.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize events table: %v", err)
+	}
+	// Alice was imported from the spreadsheet, the exporter saved her contact
+	if err := reports.UpsertReport(ctx, &domain.Report{UserID: "628111111111", Name: "Alice"}); err != nil {
+		t.Fatalf("Failed to seed report: %v", err)
+	}
+
+	export := "01/03/26, 06.00 - alice: #lapor lari\n" +
+		"02/03/26, 06.10 - alice: #lapor\n" +
+		"02/03/26, 20.00 - alice: #lapor lagi\n" +
+		"03/03/26, 06.05 - alice: keren semua!\n" +
+		"03/03/26, 07.00 - +62 822-2222-2222: #lapor\n" +
+		"03/03/26, 08.00 - Om Dedi: #lapor\n"
+	uc := usecase.NewBackfillReportsUsecase(usecase.NewReportEventsUsecase(reports, events))
+	isReport := func(text string) bool { return strings.HasPrefix(strings.ToLower(text), "#lapor") }
+	result, err := uc.ImportWhatsAppExport(ctx, strings.NewReader(export), time.UTC, isReport)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Reports != 4 || result.Added != 3 || len(result.Unknown) != 1 || result.Unknown[0] != "Om Dedi" {
+		t.Errorf("Expected 4 reports, 3 days added, Om Dedi unknown, got %+v", result)
+	}
+
+	alice, _ := reports.GetReport(ctx, "628111111111")
+	if alice.Name != "Alice" || alice.Streak != 2 || alice.ActivityCount != 2 {
+		t.Errorf("Expected Alice's 2 days counted under her name, got %+v", alice)
+	}
+	if budi, _ := reports.GetReport(ctx, "6282222222222"); budi == nil || budi.ActivityCount != 1 {
+		t.Errorf("Expected the unsaved number counted, got %+v", budi)
+	}
+}
+
+func TestBackfillReports_FromArchiveAfterLocalMidnight(t *testing.T) {
+	// The bot runs in UTC+7 while the archive keeps times in UTC
+	local := time.Local
+	time.Local = time.FixedZone("WIB", 7*3600)
+	defer func() { time.Local = local }()
+
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	events := sqlite.NewReportEventRepository(db)
+	if err := events.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize events table: %v", err)
+	}
+	archive := sqlite.NewMessageArchiveRepository(db)
+	if err := archive.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize archive table: %v", err)
+	}
+
+	// Alice's #lapor at 00:30 on 3 March was counted live; the one just
+	// after midnight a day later was missed. In UTC both are a day earlier
+	counted := time.Date(2026, 3, 3, 0, 30, 0, 0, time.Local)
+	missed := time.Date(2026, 3, 4, 0, 15, 0, 0, time.Local)
+	if err := events.AppendEvent(ctx, &domain.ReportEvent{UserID: "user1", Type: domain.ReportSubmitted, At: counted, Name: "Alice", Actor: "user1"}); err != nil {
+		t.Fatalf("Failed to seed event: %v", err)
+	}
+	messages := []*domain.ArchivedMessage{
+		{MessageID: "m1", ChatID: "group@g.us", SenderID: "user1", SenderName: "Alice", Text: "#lapor", SentAt: counted},
+		{MessageID: "m2", ChatID: "group@g.us", SenderID: "user1", SenderName: "Alice", Text: "#lapor", SentAt: missed},
+	}
+	for _, m := range messages {
+		if err := archive.ArchiveMessage(ctx, m); err != nil {
+			t.Fatalf("Failed to archive message: %v", err)
+		}
+	}
+
+	uc := usecase.NewBackfillReportsUsecase(usecase.NewReportEventsUsecase(reports, events))
+	uc.SetMessageArchive(archive)
+	isReport := func(chatID, text string) bool { return text == "#lapor" }
+
+	found, added, err := uc.FromArchive(ctx, counted.AddDate(0, 0, -1), isReport)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if found != 2 || added != 1 {
+		t.Errorf("Expected 2 found and only the missed one added, got %d found, %d added", found, added)
+	}
+	alice, _ := reports.GetReport(ctx, "user1")
+	if alice == nil || alice.Streak != 2 || alice.ActivityCount != 2 || !alice.LastReportDate.Equal(missed) {
+		t.Errorf("Expected Alice's 3 and 4 March counted, got %+v", alice)
+	}
+}
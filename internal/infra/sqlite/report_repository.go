@@ -3,58 +3,144 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
 )
 
+const reportColumns = `user_id, name, streak, activity_count, last_report_date, version, first_report_date, best_streak`
+
+func scanReport(row rowScanner) (*domain.Report, error) {
+	var report domain.Report
+	var lastReportDate, firstReportDate string
+	if err := row.Scan(&report.UserID, &report.Name, &report.Streak, &report.ActivityCount, &lastReportDate, &report.Version, &firstReportDate, &report.BestStreak); err != nil {
+		return nil, err
+	}
+
+	var err error
+	report.LastReportDate, err = time.Parse(time.RFC3339, lastReportDate)
+	if err != nil {
+		return nil, err
+	}
+	// Empty for members whose first report predates the column and the activity log
+	if firstReportDate != "" {
+		report.FirstReportDate, err = time.Parse(time.RFC3339, firstReportDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &report, nil
+}
+
 type ReportRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	hasher *phone.Hasher
 }
 
 func NewReportRepository(db *sql.DB) *ReportRepository {
 	return &ReportRepository{db: db}
 }
 
-func (r *ReportRepository) GetReport(ctx context.Context, userID string) (*domain.Report, error) {
-	query := `SELECT user_id, name, streak, activity_count, last_report_date FROM user_reports WHERE user_id = ?`
-	row := r.db.QueryRowContext(ctx, query, userID)
+// SetUserIDHasher lets DeleteLIDMappings find the mappings of a hashed user
+// ID.
+func (r *ReportRepository) SetUserIDHasher(hasher *phone.Hasher) {
+	r.hasher = hasher
+}
 
-	var report domain.Report
-	var lastReportDate string
-	err := row.Scan(&report.UserID, &report.Name, &report.Streak, &report.ActivityCount, &lastReportDate)
+func (r *ReportRepository) GetReport(ctx context.Context, userID string) (*domain.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM user_reports WHERE user_id = ?`
+	report, err := scanReport(conn(ctx, r.db).QueryRowContext(ctx, query, userID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	if err != nil {
-		return nil, err
+	return report, err
+}
+
+func (r *ReportRepository) UpsertReport(ctx context.Context, report *domain.Report) error {
+	lastReportDate := report.LastReportDate.Format(time.RFC3339)
+	firstReportDate := ""
+	if !report.FirstReportDate.IsZero() {
+		firstReportDate = report.FirstReportDate.Format(time.RFC3339)
 	}
 
-	report.LastReportDate, err = time.Parse(time.RFC3339, lastReportDate)
+	var res sql.Result
+	var err error
+	if report.Version == 0 {
+		query := `
+			INSERT INTO user_reports (user_id, name, streak, activity_count, last_report_date, version, first_report_date, best_streak)
+			VALUES (?, ?, ?, ?, ?, 1, ?, ?)
+			ON CONFLICT(user_id) DO NOTHING
+		`
+		res, err = conn(ctx, r.db).ExecContext(ctx, query, report.UserID, report.Name, report.Streak, report.ActivityCount, lastReportDate, firstReportDate, report.BestStreak)
+	} else {
+		query := `
+			UPDATE user_reports
+			SET name = ?, streak = ?, activity_count = ?, last_report_date = ?, first_report_date = ?, best_streak = ?, version = version + 1
+			WHERE user_id = ? AND version = ?
+		`
+		res, err = conn(ctx, r.db).ExecContext(ctx, query, report.Name, report.Streak, report.ActivityCount, lastReportDate, firstReportDate, report.BestStreak, report.UserID, report.Version)
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return &report, nil
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrReportConflict
+	}
+	report.Version++
+	return nil
 }
 
-func (r *ReportRepository) UpsertReport(ctx context.Context, report *domain.Report) error {
+// SubmitReport does the whole streak update in one UPSERT, so two handlers
+// can't both count the same day. Day comparisons use the date part of the
+// stored RFC3339 text, i.e. the calendar it was saved in, like
+// domain.ApplyReport.
+func (r *ReportRepository) SubmitReport(ctx context.Context, userID, name string, at time.Time) (*domain.Report, error) {
 	query := `
-		INSERT INTO user_reports (user_id, name, streak, activity_count, last_report_date)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO user_reports (user_id, name, streak, activity_count, last_report_date, version, first_report_date, best_streak)
+		VALUES (?, ?, 1, 1, ?, 1, ?, 1)
 		ON CONFLICT(user_id) DO UPDATE SET
 			name = excluded.name,
-			streak = excluded.streak,
-			activity_count = excluded.activity_count,
-			last_report_date = excluded.last_report_date
+			streak = CASE WHEN substr(last_report_date, 1, 10) = ? THEN COALESCE(streak, 0) + 1 ELSE 1 END,
+			best_streak = MAX(best_streak, CASE WHEN substr(last_report_date, 1, 10) = ? THEN COALESCE(streak, 0) + 1 ELSE 1 END),
+			activity_count = COALESCE(activity_count, 0) + 1,
+			last_report_date = excluded.last_report_date,
+			version = version + 1
+		WHERE IFNULL(substr(last_report_date, 1, 10), '') <> ?
+		RETURNING streak, activity_count, version, first_report_date, best_streak
 	`
-	_, err := r.db.ExecContext(ctx, query, report.UserID, report.Name, report.Streak, report.ActivityCount, report.LastReportDate.Format(time.RFC3339))
-	return err
+	today := at.Format("2006-01-02")
+	yesterday := at.AddDate(0, 0, -1).Format("2006-01-02")
+
+	report := domain.Report{UserID: userID, Name: name, LastReportDate: at}
+	var firstReportDate string
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userID, name, at.Format(time.RFC3339), at.Format(time.RFC3339), yesterday, yesterday, today).
+		Scan(&report.Streak, &report.ActivityCount, &report.Version, &firstReportDate, &report.BestStreak)
+	if err == sql.ErrNoRows {
+		// The update was skipped: today is already counted
+		return nil, domain.ErrAlreadyReported
+	}
+	if err != nil {
+		return nil, err
+	}
+	if firstReportDate != "" {
+		report.FirstReportDate, err = time.Parse(time.RFC3339, firstReportDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &report, nil
 }
 
 func (r *ReportRepository) GetAllReports(ctx context.Context) ([]*domain.Report, error) {
-	query := `SELECT user_id, name, streak, activity_count, last_report_date FROM user_reports ORDER BY activity_count DESC`
-	rows, err := r.db.QueryContext(ctx, query)
+	query := `SELECT ` + reportColumns + ` FROM user_reports ORDER BY activity_count DESC`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -62,18 +148,89 @@ func (r *ReportRepository) GetAllReports(ctx context.Context) ([]*domain.Report,
 
 	var reports []*domain.Report
 	for rows.Next() {
-		var report domain.Report
-		var lastReportDate string
-		if err := rows.Scan(&report.UserID, &report.Name, &report.Streak, &report.ActivityCount, &lastReportDate); err != nil {
+		report, err := scanReport(rows)
+		if err != nil {
 			return nil, err
 		}
-		report.LastReportDate, err = time.Parse(time.RFC3339, lastReportDate)
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (r *ReportRepository) ListReports(ctx context.Context, q domain.ReportQuery) ([]*domain.Report, error) {
+	var conds []string
+	var args []interface{}
+	if q.UserIDs != nil {
+		if len(q.UserIDs) == 0 {
+			return nil, nil
+		}
+		conds = append(conds, "user_id IN (?"+strings.Repeat(", ?", len(q.UserIDs)-1)+")")
+		for _, id := range q.UserIDs {
+			args = append(args, id)
+		}
+	}
+	// last_report_date keeps its UTC offset, so compare through datetime()
+	if !q.Since.IsZero() {
+		conds = append(conds, "datetime(last_report_date) >= datetime(?)")
+		args = append(args, q.Since.UTC().Format(time.RFC3339))
+	}
+	if !q.Until.IsZero() {
+		conds = append(conds, "datetime(last_report_date) < datetime(?)")
+		args = append(args, q.Until.UTC().Format(time.RFC3339))
+	}
+
+	// Keyset pagination: rows strictly after the last row of the previous page
+	order := "activity_count DESC, user_id"
+	switch q.Sort {
+	case domain.ReportSortStreak:
+		order = "streak DESC, user_id"
+		if q.After != nil {
+			conds = append(conds, "(streak < ? OR (streak = ? AND user_id > ?))")
+			args = append(args, q.After.Streak, q.After.Streak, q.After.UserID)
+		}
+	case domain.ReportSortName:
+		order = "name, user_id"
+		if q.After != nil {
+			conds = append(conds, "(name > ? OR (name = ? AND user_id > ?))")
+			args = append(args, q.After.Name, q.After.Name, q.After.UserID)
+		}
+	default:
+		if q.After != nil {
+			conds = append(conds, "(activity_count < ? OR (activity_count = ? AND user_id > ?))")
+			args = append(args, q.After.ActivityCount, q.After.ActivityCount, q.After.UserID)
+		}
+	}
+
+	query := `SELECT ` + reportColumns + ` FROM user_reports`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY " + order
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*domain.Report
+	for rows.Next() {
+		report, err := scanReport(rows)
 		if err != nil {
 			return nil, err
 		}
-		reports = append(reports, &report)
+		reports = append(reports, report)
 	}
-	return reports, nil
+	return reports, rows.Err()
+}
+
+func (r *ReportRepository) DeleteReport(ctx context.Context, userID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM user_reports WHERE user_id = ?`, userID)
+	return err
 }
 
 func (r *ReportRepository) InitTable(ctx context.Context) error {
@@ -83,7 +240,10 @@ func (r *ReportRepository) InitTable(ctx context.Context) error {
 			name TEXT,
 			streak INTEGER,
 			activity_count INTEGER DEFAULT 0,
-			last_report_date TEXT
+			last_report_date TEXT,
+			version INTEGER NOT NULL DEFAULT 1,
+			first_report_date TEXT NOT NULL DEFAULT '',
+			best_streak INTEGER NOT NULL DEFAULT 0
 		);
 	`
 	_, err := r.db.ExecContext(ctx, query)
@@ -94,8 +254,29 @@ func (r *ReportRepository) InitTable(ctx context.Context) error {
 	// Simple migration: try to add activity_count column if it doesn't exist
 	// Ignore error if it already exists
 	_, _ = r.db.ExecContext(ctx, "ALTER TABLE user_reports ADD COLUMN activity_count INTEGER DEFAULT 0")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE user_reports ADD COLUMN version INTEGER NOT NULL DEFAULT 1")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE user_reports ADD COLUMN first_report_date TEXT NOT NULL DEFAULT ''")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE user_reports ADD COLUMN best_streak INTEGER NOT NULL DEFAULT 0")
 
-	return nil
+	// The best streak of members from before best_streak is the one they
+	// have now
+	_, _ = r.db.ExecContext(ctx, `UPDATE user_reports SET best_streak = streak WHERE best_streak < streak`)
+
+	// Members from before first_report_date get their oldest logged
+	// activity; the activity log is missing on a new database
+	_, _ = r.db.ExecContext(ctx, `
+		UPDATE user_reports SET first_report_date = IFNULL(
+			(SELECT MIN(reported_at) FROM activity_logs WHERE activity_logs.user_id = user_reports.user_id), '')
+		WHERE first_report_date = ''
+	`)
+
+	// Indexes for the sort orders of ListReports
+	_, err = r.db.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_user_reports_streak ON user_reports(streak DESC, user_id);
+		CREATE INDEX IF NOT EXISTS idx_user_reports_activity_count ON user_reports(activity_count DESC, user_id);
+		CREATE INDEX IF NOT EXISTS idx_user_reports_name ON user_reports(name, user_id);
+	`)
+	return err
 }
 
 // ResolveLIDToPhone looks up a LID in the whatsmeow_lid_map table and returns the phone number.
@@ -104,10 +285,51 @@ func (r *ReportRepository) ResolveLIDToPhone(ctx context.Context, lid string) st
 	// Query the lid_map table
 	query := `SELECT pn FROM whatsmeow_lid_map WHERE lid = ?`
 	var phone string
-	err := r.db.QueryRowContext(ctx, query, lid).Scan(&phone)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, lid).Scan(&phone)
 	if err == nil && phone != "" {
 		return phone
 	}
 	// Not found or error, return original
 	return lid
 }
+
+// DeleteLIDMappings removes the whatsmeow_lid_map rows pointing at a phone
+// number, so the member can no longer be linked to their LID. A hashed user
+// ID is matched by hashing every mapped number.
+func (r *ReportRepository) DeleteLIDMappings(ctx context.Context, userID string) error {
+	numbers := []string{userID}
+	if r.hasher != nil && phone.IsHashed(userID) {
+		var err error
+		if numbers, err = r.mappedNumbers(ctx, userID); err != nil {
+			return err
+		}
+	}
+	for _, number := range numbers {
+		if _, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM whatsmeow_lid_map WHERE pn = ?`, number); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mappedNumbers returns the numbers in whatsmeow_lid_map whose hash is
+// userID.
+func (r *ReportRepository) mappedNumbers(ctx context.Context, userID string) ([]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT DISTINCT pn FROM whatsmeow_lid_map`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var numbers []string
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return nil, err
+		}
+		if r.hasher.UserID(number) == userID {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers, rows.Err()
+}
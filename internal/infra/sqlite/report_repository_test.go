@@ -3,12 +3,14 @@ package sqlite_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
 	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
 )
 
@@ -306,6 +308,39 @@ func TestReportRepository_ResolveLIDToPhone_Found(t *testing.T) {
 	}
 }
 
+func TestReportRepository_DeleteLIDMappings_Hashed(t *testing.T) {
+	db, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS whatsmeow_lid_map (
+			lid TEXT PRIMARY KEY,
+			pn TEXT
+		)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create lid_map table: %v", err)
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO whatsmeow_lid_map (lid, pn) VALUES ('lid1', '628111'), ('lid2', '628222')`)
+	if err != nil {
+		t.Fatalf("Failed to insert mappings: %v", err)
+	}
+
+	hasher := phone.NewHasher("rahasia")
+	repo.SetUserIDHasher(hasher)
+	if err := repo.DeleteLIDMappings(ctx, hasher.UserID("628111")); err != nil {
+		t.Fatalf("DeleteLIDMappings failed: %v", err)
+	}
+
+	if result := repo.ResolveLIDToPhone(ctx, "lid1"); result != "lid1" {
+		t.Errorf("Expected the mapping of the hashed member deleted, got '%s'", result)
+	}
+	if result := repo.ResolveLIDToPhone(ctx, "lid2"); result != "628222" {
+		t.Errorf("Expected other mappings kept, got '%s'", result)
+	}
+}
+
 func TestReportRepository_ConcurrentAccess(t *testing.T) {
 	_, repo, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -346,3 +381,233 @@ func TestReportRepository_ConcurrentAccess(t *testing.T) {
 		t.Errorf("Expected ActivityCount=10, got %d", final.ActivityCount)
 	}
 }
+
+func TestReportRepository_ListReports_KeysetAndFilters(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	wib := time.FixedZone("WIB", 7*3600)
+	day := time.Date(2026, 3, 10, 6, 0, 0, 0, wib) // 2026-03-09 23:00 UTC
+
+	reports := []*domain.Report{
+		{UserID: "a", Name: "Ani", Streak: 3, ActivityCount: 10, LastReportDate: day},
+		{UserID: "b", Name: "Budi", Streak: 5, ActivityCount: 10, LastReportDate: day.AddDate(0, 0, -3)},
+		{UserID: "c", Name: "Citra", Streak: 1, ActivityCount: 12, LastReportDate: day.AddDate(0, 0, -1)},
+		{UserID: "d", Name: "Dewi", Streak: 5, ActivityCount: 2, LastReportDate: day},
+	}
+	for _, r := range reports {
+		if err := repo.UpsertReport(ctx, r); err != nil {
+			t.Fatalf("Failed to insert: %v", err)
+		}
+	}
+
+	ids := func(q domain.ReportQuery) string {
+		got, err := repo.ListReports(ctx, q)
+		if err != nil {
+			t.Fatalf("ListReports failed: %v", err)
+		}
+		s := ""
+		for _, r := range got {
+			s += r.UserID
+		}
+		return s
+	}
+
+	if got := ids(domain.ReportQuery{Sort: domain.ReportSortTotal}); got != "cabd" {
+		t.Errorf("By total: expected cabd, got %s", got)
+	}
+	// Continue after "a" (10 days): the tie "b" comes next
+	if got := ids(domain.ReportQuery{Sort: domain.ReportSortTotal, After: reports[0], Limit: 1}); got != "b" {
+		t.Errorf("After a: expected b, got %s", got)
+	}
+	if got := ids(domain.ReportQuery{Sort: domain.ReportSortStreak, After: reports[1]}); got != "dac" {
+		t.Errorf("By streak after b: expected dac, got %s", got)
+	}
+	if got := ids(domain.ReportQuery{Sort: domain.ReportSortName, UserIDs: []string{"d", "b"}}); got != "bd" {
+		t.Errorf("By name in b, d: expected bd, got %s", got)
+	}
+	if got := ids(domain.ReportQuery{UserIDs: []string{}}); got != "" {
+		t.Errorf("Empty member list: expected nothing, got %s", got)
+	}
+
+	// Stored with a +07:00 offset; the filter must compare actual instants
+	since := time.Date(2026, 3, 9, 23, 0, 0, 0, time.UTC)
+	if got := ids(domain.ReportQuery{Since: since}); got != "ad" {
+		t.Errorf("Since: expected ad, got %s", got)
+	}
+	if got := ids(domain.ReportQuery{Until: since}); got != "cb" {
+		t.Errorf("Until: expected cb, got %s", got)
+	}
+}
+
+func TestReportRepository_UpsertReport_VersionConflict(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	first := &domain.Report{UserID: "user1", Name: "Alice", Streak: 1, ActivityCount: 1, LastReportDate: time.Now()}
+	if err := repo.UpsertReport(ctx, first); err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+	if first.Version != 1 {
+		t.Errorf("Expected version 1 after insert, got %d", first.Version)
+	}
+
+	// A second insert of the same member loses
+	dup := &domain.Report{UserID: "user1", Name: "Alice", Streak: 1, ActivityCount: 1, LastReportDate: time.Now()}
+	if err := repo.UpsertReport(ctx, dup); !errors.Is(err, domain.ErrReportConflict) {
+		t.Errorf("Expected ErrReportConflict for duplicate insert, got %v", err)
+	}
+
+	// Two handlers read version 1; the first write wins, the second conflicts
+	a, _ := repo.GetReport(ctx, "user1")
+	b, _ := repo.GetReport(ctx, "user1")
+	a.ActivityCount = 2
+	if err := repo.UpsertReport(ctx, a); err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	b.ActivityCount = 2
+	if err := repo.UpsertReport(ctx, b); !errors.Is(err, domain.ErrReportConflict) {
+		t.Errorf("Expected ErrReportConflict for stale update, got %v", err)
+	}
+
+	got, _ := repo.GetReport(ctx, "user1")
+	if got.Version != 2 || got.ActivityCount != 2 {
+		t.Errorf("Expected version 2 with 2 days, got %+v", got)
+	}
+}
+
+func TestReportRepository_SubmitReport_Streak(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	// Stored with a local offset: days follow the local calendar, not UTC
+	wib := time.FixedZone("WIB", 7*3600)
+	day1 := time.Date(2026, 3, 2, 6, 30, 0, 0, wib)
+
+	steps := []struct {
+		at       time.Time
+		streak   int
+		count    int
+		rejected bool
+	}{
+		{at: day1, streak: 1, count: 1},
+		{at: day1.Add(10 * time.Hour), rejected: true},                     // same local day
+		{at: time.Date(2026, 3, 3, 0, 30, 0, 0, wib), streak: 2, count: 2}, // 2 March in UTC
+		{at: time.Date(2026, 3, 4, 23, 0, 0, 0, wib), streak: 3, count: 3},
+		{at: time.Date(2026, 3, 7, 8, 0, 0, 0, wib), streak: 1, count: 4}, // missed days
+	}
+	for i, step := range steps {
+		report, err := repo.SubmitReport(ctx, "user1", "Alice", step.at)
+		if step.rejected {
+			if !errors.Is(err, domain.ErrAlreadyReported) {
+				t.Errorf("step %d: expected ErrAlreadyReported, got %v", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+		if report.Streak != step.streak || report.ActivityCount != step.count {
+			t.Errorf("step %d: expected streak %d of %d days, got %d of %d", i, step.streak, step.count, report.Streak, report.ActivityCount)
+		}
+	}
+
+	got, _ := repo.GetReport(ctx, "user1")
+	if got.Streak != 1 || got.ActivityCount != 4 || got.Version != 4 {
+		t.Errorf("Expected stored streak 1 of 4 days at version 4, got %+v", got)
+	}
+}
+
+func TestReportRepository_FirstReportDate(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	day1 := time.Date(2026, 3, 2, 6, 30, 0, 0, time.UTC)
+	for _, at := range []time.Time{day1, day1.AddDate(0, 0, 1)} {
+		report, err := repo.SubmitReport(ctx, "user1", "Alice", at)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !report.FirstReportDate.Equal(day1) {
+			t.Errorf("Expected the first report on %v, got %v", day1, report.FirstReportDate)
+		}
+	}
+	if got, _ := repo.GetReport(ctx, "user1"); !got.FirstReportDate.Equal(day1) {
+		t.Errorf("Expected the first report date stored, got %v", got.FirstReportDate)
+	}
+}
+
+func TestReportRepository_FirstReportDate_FromActivityLog(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open in-memory database: %v", err)
+	}
+	defer db.Close()
+
+	// A database from before first_report_date
+	ctx := context.Background()
+	if _, err := db.Exec(`CREATE TABLE user_reports (user_id TEXT PRIMARY KEY, name TEXT, streak INTEGER, activity_count INTEGER DEFAULT 0, last_report_date TEXT, version INTEGER NOT NULL DEFAULT 1)`); err != nil {
+		t.Fatalf("Failed to create old table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO user_reports VALUES ('user1', 'Alice', 1, 2, '2026-03-05T07:00:00Z', 1), ('user2', 'Bob', 1, 1, '2026-03-05T07:00:00Z', 1)`); err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+	activities := sqlite.NewActivityRepository(db)
+	if err := activities.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize activities: %v", err)
+	}
+	first := time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{first.AddDate(0, 0, 4), first} {
+		if err := activities.AddActivity(ctx, &domain.Activity{UserID: "user1", Name: "Alice", ActivityType: "lari", ReportedAt: at}); err != nil {
+			t.Fatalf("Failed to add activity: %v", err)
+		}
+	}
+
+	repo := sqlite.NewReportRepository(db)
+	if err := repo.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if alice, _ := repo.GetReport(ctx, "user1"); !alice.FirstReportDate.Equal(first) {
+		t.Errorf("Expected the oldest activity as first report, got %v", alice.FirstReportDate)
+	}
+	if bob, _ := repo.GetReport(ctx, "user2"); !bob.FirstReportDate.IsZero() {
+		t.Errorf("Expected an unknown first report without activities, got %v", bob.FirstReportDate)
+	}
+}
+
+func TestReportRepository_SubmitReport_Concurrent(t *testing.T) {
+	db, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	now := time.Now()
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			_, err := repo.SubmitReport(ctx, "user1", "Alice", now)
+			errs <- err
+		}()
+	}
+
+	accepted := 0
+	for i := 0; i < 10; i++ {
+		err := <-errs
+		switch {
+		case err == nil:
+			accepted++
+		case !errors.Is(err, domain.ErrAlreadyReported):
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if accepted != 1 {
+		t.Errorf("Expected exactly 1 accepted report, got %d", accepted)
+	}
+	if got, _ := repo.GetReport(ctx, "user1"); got.ActivityCount != 1 {
+		t.Errorf("Expected 1 day counted, got %d", got.ActivityCount)
+	}
+}
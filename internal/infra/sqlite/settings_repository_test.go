@@ -0,0 +1,139 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE SETTINGS REPOSITORY TESTS
+// =============================================================================
+
+func setupSettingsRepo(t *testing.T) (*sqlite.SettingsRepository, func()) {
+	t.Helper()
+
+	db, _, cleanup := setupTestDB(t)
+	repo := sqlite.NewSettingsRepository(db)
+	if err := repo.InitTable(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize settings table: %v", err)
+	}
+	return repo, cleanup
+}
+
+func TestSettingsRepository_GetSettings_NotFound(t *testing.T) {
+	repo, cleanup := setupSettingsRepo(t)
+	defer cleanup()
+
+	settings, err := repo.GetSettings(context.Background(), "nobody")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings != nil {
+		t.Errorf("Expected nil for user without settings, got %+v", settings)
+	}
+}
+
+func TestSettingsRepository_SaveSettings_Upsert(t *testing.T) {
+	repo, cleanup := setupSettingsRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := repo.SaveSettings(ctx, &domain.UserSettings{UserID: "user1", Target: 25}); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+	if err := repo.SaveSettings(ctx, &domain.UserSettings{UserID: "user1", Target: 30}); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	got, err := repo.GetSettings(ctx, "user1")
+	if err != nil {
+		t.Fatalf("Failed to get settings: %v", err)
+	}
+	if got == nil || got.Target != 30 {
+		t.Errorf("Expected target 30, got %+v", got)
+	}
+}
+
+func TestSettingsRepository_SnoozeUntil(t *testing.T) {
+	repo, cleanup := setupSettingsRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	until := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	if err := repo.SaveSettings(ctx, &domain.UserSettings{UserID: "user1", Target: 25, SnoozeUntil: until}); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+
+	got, err := repo.GetSettings(ctx, "user1")
+	if err != nil {
+		t.Fatalf("Failed to get settings: %v", err)
+	}
+	if !got.SnoozeUntil.Equal(until) || got.Target != 25 {
+		t.Errorf("Expected snooze until %v and target 25, got %+v", until, got)
+	}
+
+	// Clearing the snooze stores the zero time
+	got.SnoozeUntil = time.Time{}
+	if err := repo.SaveSettings(ctx, got); err != nil {
+		t.Fatalf("Failed to clear snooze: %v", err)
+	}
+	if got, _ = repo.GetSettings(ctx, "user1"); !got.SnoozeUntil.IsZero() {
+		t.Errorf("Expected snooze cleared, got %v", got.SnoozeUntil)
+	}
+}
+
+func TestSettingsRepository_GetReminderSettings(t *testing.T) {
+	repo, cleanup := setupSettingsRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, s := range []*domain.UserSettings{
+		{UserID: "user1", ReminderTime: "19:30", Timezone: "WITA"},
+		{UserID: "user2", Target: 20},
+	} {
+		if err := repo.SaveSettings(ctx, s); err != nil {
+			t.Fatalf("Failed to save settings: %v", err)
+		}
+	}
+
+	list, err := repo.GetReminderSettings(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list reminder settings: %v", err)
+	}
+	if len(list) != 1 || list[0].UserID != "user1" || list[0].ReminderTime != "19:30" || list[0].Timezone != "WITA" {
+		t.Errorf("Expected only user1 with 19:30 WITA, got %+v", list)
+	}
+}
+
+func TestSettingsRepository_GetUnrankedSettings(t *testing.T) {
+	repo, cleanup := setupSettingsRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, s := range []*domain.UserSettings{
+		{UserID: "coach", Unranked: true},
+		{UserID: "user1", Target: 30},
+		{UserID: "user2", Private: true},
+	} {
+		if err := repo.SaveSettings(ctx, s); err != nil {
+			t.Fatalf("Failed to save settings: %v", err)
+		}
+	}
+
+	list, err := repo.GetUnrankedSettings(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("Expected the coach and the private member, got %+v", list)
+	}
+	for _, s := range list {
+		if !(s.UserID == "coach" && s.Unranked) && !(s.UserID == "user2" && s.Private) {
+			t.Errorf("Unexpected settings %+v", s)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE LEADERBOARD SNAPSHOT TESTS
+// =============================================================================
+
+func TestSnapshotLeaderboard_StoresTheDaysStandings(t *testing.T) {
+	db, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	settings := sqlite.NewSettingsRepository(db)
+	snapshots := sqlite.NewSnapshotRepository(db)
+	for _, init := range []func(context.Context) error{settings.InitTable, snapshots.InitTable} {
+		if err := init(ctx); err != nil {
+			t.Fatalf("Failed to initialize table: %v", err)
+		}
+	}
+
+	now := time.Date(2026, 3, 12, 23, 55, 0, 0, time.Local)
+	for _, r := range []*domain.Report{
+		{UserID: "628111", Name: "Budi", Streak: 5, ActivityCount: 5, LastReportDate: now.Add(-2 * time.Hour)},
+		{UserID: "628222", Name: "Ani", Streak: 7, ActivityCount: 7, LastReportDate: now.AddDate(0, 0, -1)},
+		{UserID: "628333", Name: "Cici", Streak: 3, ActivityCount: 3, LastReportDate: now.AddDate(0, 0, -3)},
+	} {
+		if err := repo.UpsertReport(ctx, r); err != nil {
+			t.Fatalf("Failed to save report: %v", err)
+		}
+	}
+
+	uc := usecase.NewSnapshotLeaderboardUsecase(repo, settings, snapshots)
+	if err := uc.Execute(ctx, now); err != nil {
+		t.Fatalf("Failed to take snapshot: %v", err)
+	}
+	// A second run the same day replaces the first
+	if err := uc.Execute(ctx, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Failed to take snapshot: %v", err)
+	}
+
+	entries, err := snapshots.GetSnapshot(ctx, "2026-03-12")
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, fmt.Sprintf("%d %s %d/%d %v", e.Rank, e.Name, e.Streak, e.Total, e.Reported))
+	}
+	want := []string{"1 Ani 7/7 false", "2 Budi 5/5 true", "3 Cici 0/3 false"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if entries, err := snapshots.GetSnapshot(ctx, "2026-03-11"); err != nil || len(entries) != 0 {
+		t.Errorf("Expected no snapshot for a day without one, got %d, %v", len(entries), err)
+	}
+
+	// #hapusdata takes the member out of past boards too
+	if _, err := db.ExecContext(ctx, `CREATE TABLE whatsmeow_lid_map (lid TEXT PRIMARY KEY, pn TEXT NOT NULL)`); err != nil {
+		t.Fatalf("Failed to create LID table: %v", err)
+	}
+	deleteUC := usecase.NewDeleteUserDataUsecase(repo, nil, nil)
+	deleteUC.SetSnapshots(snapshots)
+	if err := deleteUC.Delete(ctx, "628111"); err != nil {
+		t.Fatalf("Failed to delete user data: %v", err)
+	}
+	entries, err = snapshots.GetSnapshot(ctx, "2026-03-12")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Expected 2 entries left, got %d, %v", len(entries), err)
+	}
+	for _, e := range entries {
+		if e.UserID == "628111" {
+			t.Errorf("Expected the deleted member gone from the snapshot, got %+v", e)
+		}
+	}
+}
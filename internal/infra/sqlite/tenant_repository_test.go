@@ -0,0 +1,65 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE TENANT REPOSITORY TESTS
+// =============================================================================
+
+func TestTenantRepository_SaveGetDelete(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := sqlite.NewTenantRepository(db)
+	if err := repo.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize tenants table: %v", err)
+	}
+
+	missing, err := repo.GetTenant(ctx, "nope")
+	if err != nil || missing != nil {
+		t.Fatalf("Expected nil for unknown tenant, got %+v, %v", missing, err)
+	}
+
+	created := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	tenant := &domain.Tenant{ID: "lari-pagi", Name: "Lari Pagi", Enabled: true, CreatedAt: created}
+	if err := repo.SaveTenant(ctx, tenant); err != nil {
+		t.Fatalf("Failed to save tenant: %v", err)
+	}
+	other := &domain.Tenant{ID: "yoga", Name: "Yoga", Enabled: true, Env: map[string]string{}, CreatedAt: created.Add(time.Hour)}
+	if err := repo.SaveTenant(ctx, other); err != nil {
+		t.Fatalf("Failed to save tenant: %v", err)
+	}
+
+	tenant.Enabled = false
+	tenant.Env = map[string]string{"GROUP_ID": "111@g.us", "CHALLENGE_DAYS": "60"}
+	if err := repo.SaveTenant(ctx, tenant); err != nil {
+		t.Fatalf("Failed to update tenant: %v", err)
+	}
+
+	got, err := repo.GetTenant(ctx, "lari-pagi")
+	if err != nil {
+		t.Fatalf("Failed to get tenant: %v", err)
+	}
+	if got.Name != "Lari Pagi" || got.Enabled || got.Env["GROUP_ID"] != "111@g.us" || got.Env["CHALLENGE_DAYS"] != "60" || !got.CreatedAt.Equal(created) {
+		t.Errorf("Unexpected tenant: %+v", got)
+	}
+
+	if err := repo.DeleteTenant(ctx, "yoga"); err != nil {
+		t.Fatalf("Failed to delete tenant: %v", err)
+	}
+	tenants, err := repo.GetTenants(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list tenants: %v", err)
+	}
+	if len(tenants) != 1 || tenants[0].ID != "lari-pagi" {
+		t.Errorf("Expected only lari-pagi left, got %d tenants", len(tenants))
+	}
+}
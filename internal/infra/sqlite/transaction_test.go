@@ -0,0 +1,175 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
+)
+
+// =============================================================================
+// SQLITE TRANSACTION TESTS
+// =============================================================================
+
+// failingActivities writes the entry, then fails as if the commit of a
+// later step had gone wrong.
+type failingActivities struct {
+	*sqlite.ActivityRepository
+}
+
+func (r failingActivities) AddActivity(ctx context.Context, activity *domain.Activity) error {
+	if err := r.ActivityRepository.AddActivity(ctx, activity); err != nil {
+		return err
+	}
+	return errors.New("disk full")
+}
+
+func TestTransactor_ReportAndActivityCommitTogether(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+	// One connection, as in production, so ":memory:" stays a single database
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	activities := sqlite.NewActivityRepository(db)
+	if err := activities.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize activity table: %v", err)
+	}
+
+	uc := usecase.NewReportActivityUsecase(reports)
+	uc.SetActivityRepository(failingActivities{activities})
+	uc.SetTransactor(sqlite.NewTransactor(db))
+
+	if _, err := uc.ExecuteWithMessage(ctx, "user1", "Alice", "#lapor lari"); err == nil {
+		t.Fatal("Expected the activity-log error")
+	}
+
+	report, err := reports.GetReport(ctx, "user1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report != nil {
+		t.Errorf("Expected the report to be rolled back, got %+v", report)
+	}
+	logged, err := activities.GetActivities(ctx, domain.ActivityFilter{UserID: "user1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(logged) != 0 {
+		t.Errorf("Expected the activity to be rolled back, got %d entries", len(logged))
+	}
+
+	// Without the failure both rows are written
+	uc.SetActivityRepository(activities)
+	if _, err := uc.ExecuteWithMessage(ctx, "user1", "Alice", "#lapor lari"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report, _ := reports.GetReport(ctx, "user1"); report == nil || report.ActivityCount != 1 {
+		t.Errorf("Expected a report with 1 day, got %+v", report)
+	}
+	if logged, _ := activities.GetActivities(ctx, domain.ActivityFilter{UserID: "user1"}); len(logged) != 1 {
+		t.Errorf("Expected 1 activity entry, got %d", len(logged))
+	}
+}
+
+func TestTransactor_NestedJoinsOuter(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	tx := sqlite.NewTransactor(db)
+	err := tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := reports.UpsertReport(ctx, &domain.Report{UserID: "user1", Name: "Alice"}); err != nil {
+			return err
+		}
+		return tx.WithTx(ctx, func(ctx context.Context) error {
+			if err := reports.UpsertReport(ctx, &domain.Report{UserID: "user2", Name: "Bob"}); err != nil {
+				return err
+			}
+			return errors.New("abort")
+		})
+	})
+	if err == nil {
+		t.Fatal("Expected the inner error")
+	}
+
+	all, err := reports.GetAllReports(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(all) != 0 {
+		t.Errorf("Expected both writes to be rolled back, got %d reports", len(all))
+	}
+}
+
+// failingOutbox queues the reply, then fails like failingActivities.
+type failingOutbox struct {
+	*sqlite.OutboxRepository
+}
+
+func (r failingOutbox) QueueReply(ctx context.Context, msg *domain.OutboxMessage) error {
+	if err := r.OutboxRepository.QueueReply(ctx, msg); err != nil {
+		return err
+	}
+	return errors.New("disk full")
+}
+
+func TestTransactor_ReportActivityAndReplyCommitTogether(t *testing.T) {
+	db, reports, cleanup := setupTestDB(t)
+	defer cleanup()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	activities := sqlite.NewActivityRepository(db)
+	if err := activities.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize activity table: %v", err)
+	}
+	outbox := sqlite.NewOutboxRepository(db)
+	if err := outbox.InitTable(ctx); err != nil {
+		t.Fatalf("Failed to initialize outbox table: %v", err)
+	}
+
+	ids := 0
+	newID := func() string { ids++; return fmt.Sprintf("3EB0%d", ids) }
+	uc := usecase.NewReportActivityUsecase(reports)
+	uc.SetActivityRepository(activities)
+	uc.SetOutbox(failingOutbox{outbox}, newID)
+	uc.SetTransactor(sqlite.NewTransactor(db))
+
+	if _, err := uc.ExecuteReply(ctx, "111@g.us", "user1", "Alice", "#lapor lari"); err == nil {
+		t.Fatal("Expected the outbox error")
+	}
+	if report, _ := reports.GetReport(ctx, "user1"); report != nil {
+		t.Errorf("Expected the report to be rolled back, got %+v", report)
+	}
+	if logged, _ := activities.GetActivities(ctx, domain.ActivityFilter{UserID: "user1"}); len(logged) != 0 {
+		t.Errorf("Expected the activity to be rolled back, got %d entries", len(logged))
+	}
+	if queued, _ := outbox.GetOutbox(ctx, domain.OutboxFilter{}); len(queued) != 0 {
+		t.Errorf("Expected the reply to be rolled back, got %+v", queued)
+	}
+
+	// Without the failure the reply is queued under the ID it is sent with
+	uc.SetOutbox(outbox, newID)
+	reply, err := uc.ExecuteReply(ctx, "111@g.us", "user1", "Alice", "#lapor lari")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	queued, err := outbox.GetOutbox(ctx, domain.OutboxFilter{ChatID: "111@g.us"})
+	if err != nil || len(queued) != 1 {
+		t.Fatalf("Expected 1 queued reply, got %d, %v", len(queued), err)
+	}
+	if queued[0].MessageID != reply.OutboxID || queued[0].Text != reply.Text || !queued[0].SentAt.IsZero() {
+		t.Errorf("Expected the unsent reply %q queued as %q, got %+v", reply.Text, reply.OutboxID, queued[0])
+	}
+
+	// A second report of the day saves nothing and queues nothing
+	if reply, err := uc.ExecuteReply(ctx, "111@g.us", "user1", "Alice", "#lapor"); err != nil || reply.OutboxID != "" {
+		t.Errorf("Expected no queued reply for a second report, got %+v, %v", reply, err)
+	}
+}
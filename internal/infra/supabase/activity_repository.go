@@ -0,0 +1,120 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+type ActivityRepository struct {
+	client *supa.Client
+	reads  *supa.Client // GetActivities, defaults to client
+}
+
+type ActivityLog struct {
+	ID              int64   `json:"id,omitempty"`
+	UserID          string  `json:"user_id"`
+	Name            string  `json:"name"`
+	ActivityType    string  `json:"activity_type"`
+	DurationMinutes int     `json:"duration_minutes"`
+	DistanceKm      float64 `json:"distance_km"`
+	Message         string  `json:"message"`
+	ReportedAt      string  `json:"reported_at"`
+}
+
+func NewActivityRepository(client *supa.Client) *ActivityRepository {
+	return &ActivityRepository{client: client, reads: client}
+}
+
+// SetReadReplica sends GetActivities, which backs the leaderboards, stats
+// and recaps, to a read replica.
+func (r *ActivityRepository) SetReadReplica(client *supa.Client) {
+	r.reads = client
+}
+
+func (r *ActivityRepository) AddActivity(ctx context.Context, activity *domain.Activity) error {
+	data := ActivityLog{
+		UserID:          activity.UserID,
+		Name:            activity.Name,
+		ActivityType:    activity.ActivityType,
+		DurationMinutes: activity.DurationMinutes,
+		DistanceKm:      activity.DistanceKm,
+		Message:         activity.Message,
+		ReportedAt:      activity.ReportedAt.UTC().Format(time.RFC3339),
+	}
+
+	var results []ActivityLog
+	err := r.client.DB.From("activity_logs").
+		Insert(data).
+		Execute(&results)
+	if err != nil {
+		return err
+	}
+
+	if len(results) > 0 {
+		activity.ID = results[0].ID
+	}
+	return nil
+}
+
+func (r *ActivityRepository) GetActivities(ctx context.Context, filter domain.ActivityFilter) ([]*domain.Activity, error) {
+	query := r.reads.DB.From("activity_logs").Select("*")
+	if filter.UserID != "" {
+		query.Eq("user_id", filter.UserID)
+	}
+	if filter.ActivityType != "" {
+		query.Eq("activity_type", filter.ActivityType)
+	}
+	if !filter.Since.IsZero() {
+		query.Gte("reported_at", filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		query.Lt("reported_at", filter.Until.UTC().Format(time.RFC3339))
+	}
+
+	var results []ActivityLog
+	if err := query.OrderBy("reported_at", "asc").Execute(&results); err != nil {
+		return nil, err
+	}
+
+	var activities []*domain.Activity
+	for _, result := range results {
+		activities = append(activities, &domain.Activity{
+			ID:              result.ID,
+			UserID:          result.UserID,
+			Name:            result.Name,
+			ActivityType:    result.ActivityType,
+			DurationMinutes: result.DurationMinutes,
+			DistanceKm:      result.DistanceKm,
+			Message:         result.Message,
+			ReportedAt:      parseTime(result.ReportedAt),
+		})
+	}
+
+	return activities, nil
+}
+
+func (r *ActivityRepository) DeleteActivities(ctx context.Context, filter domain.ActivityFilter) error {
+	query := r.client.DB.From("activity_logs").Delete()
+	if filter.UserID != "" {
+		query.Eq("user_id", filter.UserID)
+	}
+	if filter.ActivityType != "" {
+		query.Eq("activity_type", filter.ActivityType)
+	}
+	if !filter.Since.IsZero() {
+		query.Gte("reported_at", filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		query.Lt("reported_at", filter.Until.UTC().Format(time.RFC3339))
+	}
+
+	return query.Execute(nil)
+}
+
+func (r *ActivityRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
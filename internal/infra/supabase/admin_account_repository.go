@@ -0,0 +1,62 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+type AdminAccountRepository struct {
+	client *supa.Client
+}
+
+type AdminAccount struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
+	CreatedAt    string `json:"created_at"`
+}
+
+func NewAdminAccountRepository(client *supa.Client) *AdminAccountRepository {
+	return &AdminAccountRepository{client: client}
+}
+
+func (r *AdminAccountRepository) GetAdminAccount(ctx context.Context, username string) (*domain.AdminAccount, error) {
+	var results []AdminAccount
+
+	err := r.client.DB.From("admin_accounts").
+		Select("*").
+		Eq("username", username).
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return &domain.AdminAccount{
+		Username:     results[0].Username,
+		PasswordHash: results[0].PasswordHash,
+		CreatedAt:    parseTime(results[0].CreatedAt),
+	}, nil
+}
+
+func (r *AdminAccountRepository) SaveAdminAccount(ctx context.Context, account *domain.AdminAccount) error {
+	data := AdminAccount{
+		Username:     account.Username,
+		PasswordHash: account.PasswordHash,
+		CreatedAt:    account.CreatedAt.UTC().Format(time.RFC3339),
+	}
+
+	var results []AdminAccount
+	return r.client.DB.From("admin_accounts").
+		Upsert(data).
+		Execute(&results)
+}
+
+func (r *AdminAccountRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
@@ -0,0 +1,103 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+type APIKeyRepository struct {
+	client *supa.Client
+}
+
+type APIKey struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	KeyHash   string `json:"key_hash"`
+	Scope     string `json:"scope"`
+	CreatedAt string `json:"created_at"`
+	RevokedAt string `json:"revoked_at"`
+}
+
+func NewAPIKeyRepository(client *supa.Client) *APIKeyRepository {
+	return &APIKeyRepository{client: client}
+}
+
+func (r *APIKeyRepository) GetAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	return r.getBy("id", id)
+}
+
+func (r *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	return r.getBy("key_hash", hash)
+}
+
+func (r *APIKeyRepository) getBy(column, value string) (*domain.APIKey, error) {
+	var results []APIKey
+
+	err := r.client.DB.From("api_keys").
+		Select("*").
+		Eq(column, value).
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return toAPIKey(results[0]), nil
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	var results []APIKey
+
+	err := r.client.DB.From("api_keys").
+		Select("*").
+		OrderBy("created_at", "asc").
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*domain.APIKey, 0, len(results))
+	for _, result := range results {
+		keys = append(keys, toAPIKey(result))
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) SaveAPIKey(ctx context.Context, key *domain.APIKey) error {
+	data := APIKey{
+		ID:        key.ID,
+		Name:      key.Name,
+		KeyHash:   key.KeyHash,
+		Scope:     key.Scope,
+		CreatedAt: key.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if !key.RevokedAt.IsZero() {
+		data.RevokedAt = key.RevokedAt.UTC().Format(time.RFC3339)
+	}
+
+	var results []APIKey
+	return r.client.DB.From("api_keys").
+		Upsert(data).
+		Execute(&results)
+}
+
+func (r *APIKeyRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
+
+func toAPIKey(result APIKey) *domain.APIKey {
+	return &domain.APIKey{
+		ID:        result.ID,
+		Name:      result.Name,
+		KeyHash:   result.KeyHash,
+		Scope:     result.Scope,
+		CreatedAt: parseTime(result.CreatedAt),
+		RevokedAt: parseTime(result.RevokedAt),
+	}
+}
@@ -0,0 +1,75 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+type ConversationRepository struct {
+	client *supa.Client
+}
+
+type Conversation struct {
+	UserID    string            `json:"user_id"`
+	Flow      string            `json:"flow"`
+	Step      int               `json:"step"`
+	Data      map[string]string `json:"data"`
+	ExpiresAt string            `json:"expires_at"`
+}
+
+func NewConversationRepository(client *supa.Client) *ConversationRepository {
+	return &ConversationRepository{client: client}
+}
+
+func (r *ConversationRepository) GetConversation(ctx context.Context, userID string) (*domain.Conversation, error) {
+	var results []Conversation
+
+	err := r.client.DB.From("conversations").
+		Select("*").
+		Eq("user_id", userID).
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return &domain.Conversation{
+		UserID:    results[0].UserID,
+		Flow:      results[0].Flow,
+		Step:      results[0].Step,
+		Data:      results[0].Data,
+		ExpiresAt: parseTime(results[0].ExpiresAt),
+	}, nil
+}
+
+func (r *ConversationRepository) SaveConversation(ctx context.Context, conv *domain.Conversation) error {
+	data := Conversation{
+		UserID:    conv.UserID,
+		Flow:      conv.Flow,
+		Step:      conv.Step,
+		Data:      conv.Data,
+		ExpiresAt: conv.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+
+	var results []Conversation
+	return r.client.DB.From("conversations").
+		Upsert(data).
+		Execute(&results)
+}
+
+func (r *ConversationRepository) DeleteConversation(ctx context.Context, userID string) error {
+	return r.client.DB.From("conversations").
+		Delete().
+		Eq("user_id", userID).
+		Execute(nil)
+}
+
+func (r *ConversationRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
@@ -0,0 +1,107 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+type GroupRepository struct {
+	client *supa.Client
+}
+
+type BotGroup struct {
+	JID              string `json:"jid"`
+	Name             string `json:"name"`
+	Enabled          bool   `json:"enabled"`
+	AddedBy          string `json:"added_by"`
+	AddedAt          string `json:"added_at"`
+	Prefix           string `json:"prefix"`
+	LeftAt           string `json:"left_at"`
+	FinalStandings   string `json:"final_standings"`
+	CommandHint      bool   `json:"command_hint"`
+	LeaderboardStyle string `json:"leaderboard_style"`
+}
+
+func NewGroupRepository(client *supa.Client) *GroupRepository {
+	return &GroupRepository{client: client}
+}
+
+func (r *GroupRepository) GetGroup(ctx context.Context, jid string) (*domain.Group, error) {
+	var results []BotGroup
+
+	err := r.client.DB.From("bot_groups").
+		Select("*").
+		Eq("jid", jid).
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return toGroup(results[0]), nil
+}
+
+func (r *GroupRepository) GetGroups(ctx context.Context) ([]*domain.Group, error) {
+	var results []BotGroup
+
+	err := r.client.DB.From("bot_groups").
+		Select("*").
+		OrderBy("added_at", "asc").
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*domain.Group
+	for _, result := range results {
+		groups = append(groups, toGroup(result))
+	}
+	return groups, nil
+}
+
+func (r *GroupRepository) SaveGroup(ctx context.Context, group *domain.Group) error {
+	data := BotGroup{
+		JID:              group.JID,
+		Name:             group.Name,
+		Enabled:          group.Enabled,
+		AddedBy:          group.AddedBy,
+		AddedAt:          group.AddedAt.UTC().Format(time.RFC3339),
+		Prefix:           group.Prefix,
+		FinalStandings:   group.FinalStandings,
+		CommandHint:      group.CommandHint,
+		LeaderboardStyle: group.LeaderboardStyle,
+	}
+	if !group.LeftAt.IsZero() {
+		data.LeftAt = group.LeftAt.UTC().Format(time.RFC3339)
+	}
+
+	var results []BotGroup
+	return r.client.DB.From("bot_groups").
+		Upsert(data).
+		Execute(&results)
+}
+
+func (r *GroupRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
+
+func toGroup(result BotGroup) *domain.Group {
+	return &domain.Group{
+		JID:              result.JID,
+		Name:             result.Name,
+		Enabled:          result.Enabled,
+		AddedBy:          result.AddedBy,
+		AddedAt:          parseTime(result.AddedAt),
+		Prefix:           result.Prefix,
+		LeftAt:           parseTime(result.LeftAt),
+		FinalStandings:   result.FinalStandings,
+		CommandHint:      result.CommandHint,
+		LeaderboardStyle: result.LeaderboardStyle,
+	}
+}
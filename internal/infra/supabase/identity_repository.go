@@ -0,0 +1,138 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+// IdentityRepository needs the linked_identities table in Supabase:
+//
+//	CREATE TABLE linked_identities (
+//		platform text NOT NULL,
+//		external_id text NOT NULL,
+//		user_id text NOT NULL DEFAULT '',
+//		code text NOT NULL DEFAULT '',
+//		code_expires_at text NOT NULL DEFAULT '',
+//		linked_at text NOT NULL DEFAULT '',
+//		PRIMARY KEY (platform, external_id)
+//	);
+//	CREATE INDEX idx_linked_identities_user_id ON linked_identities(user_id);
+//	CREATE INDEX idx_linked_identities_code ON linked_identities(code);
+type IdentityRepository struct {
+	client *supa.Client
+}
+
+type LinkedIdentity struct {
+	Platform      string `json:"platform"`
+	ExternalID    string `json:"external_id"`
+	UserID        string `json:"user_id"`
+	Code          string `json:"code"`
+	CodeExpiresAt string `json:"code_expires_at"`
+	LinkedAt      string `json:"linked_at"`
+}
+
+func NewIdentityRepository(client *supa.Client) *IdentityRepository {
+	return &IdentityRepository{client: client}
+}
+
+func (r *IdentityRepository) GetIdentity(ctx context.Context, platform, externalID string) (*domain.LinkedIdentity, error) {
+	var results []LinkedIdentity
+
+	err := r.client.DB.From("linked_identities").
+		Select("*").
+		Eq("platform", platform).
+		Eq("external_id", externalID).
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return toLinkedIdentity(results[0]), nil
+}
+
+func (r *IdentityRepository) GetIdentityByCode(ctx context.Context, code string) (*domain.LinkedIdentity, error) {
+	if code == "" {
+		return nil, nil
+	}
+	var results []LinkedIdentity
+
+	err := r.client.DB.From("linked_identities").
+		Select("*").
+		Eq("code", code).
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return toLinkedIdentity(results[0]), nil
+}
+
+func (r *IdentityRepository) ListIdentities(ctx context.Context, userID string) ([]*domain.LinkedIdentity, error) {
+	var results []LinkedIdentity
+
+	query := r.client.DB.From("linked_identities").Select("*")
+	query.Eq("user_id", userID)
+	query.OrderBy("platform", "asc")
+	if err := query.Execute(&results); err != nil {
+		return nil, err
+	}
+
+	identities := make([]*domain.LinkedIdentity, 0, len(results))
+	for _, result := range results {
+		identities = append(identities, toLinkedIdentity(result))
+	}
+	return identities, nil
+}
+
+func (r *IdentityRepository) SaveIdentity(ctx context.Context, identity *domain.LinkedIdentity) error {
+	data := LinkedIdentity{
+		Platform:   identity.Platform,
+		ExternalID: identity.ExternalID,
+		UserID:     identity.UserID,
+		Code:       identity.Code,
+	}
+	if !identity.CodeExpiresAt.IsZero() {
+		data.CodeExpiresAt = identity.CodeExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if !identity.LinkedAt.IsZero() {
+		data.LinkedAt = identity.LinkedAt.UTC().Format(time.RFC3339)
+	}
+
+	var results []LinkedIdentity
+	return r.client.DB.From("linked_identities").
+		Upsert(data).
+		Execute(&results)
+}
+
+func (r *IdentityRepository) DeleteIdentities(ctx context.Context, userID string) error {
+	var results []LinkedIdentity
+	return r.client.DB.From("linked_identities").
+		Delete().
+		Eq("user_id", userID).
+		Execute(&results)
+}
+
+func (r *IdentityRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
+
+func toLinkedIdentity(result LinkedIdentity) *domain.LinkedIdentity {
+	return &domain.LinkedIdentity{
+		Platform:      result.Platform,
+		ExternalID:    result.ExternalID,
+		UserID:        result.UserID,
+		Code:          result.Code,
+		CodeExpiresAt: parseTime(result.CodeExpiresAt),
+		LinkedAt:      parseTime(result.LinkedAt),
+	}
+}
@@ -0,0 +1,116 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+// JobRepository needs the scheduled_jobs table in Supabase:
+//
+//	CREATE TABLE scheduled_jobs (
+//		name text PRIMARY KEY,
+//		kind text NOT NULL,
+//		schedule text NOT NULL DEFAULT '',
+//		payload text NOT NULL DEFAULT '',
+//		next_run text NOT NULL,
+//		last_run text NOT NULL DEFAULT '',
+//		last_error text NOT NULL DEFAULT ''
+//	);
+type JobRepository struct {
+	client *supa.Client
+}
+
+type ScheduledJob struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Schedule  string `json:"schedule"`
+	Payload   string `json:"payload"`
+	NextRun   string `json:"next_run"`
+	LastRun   string `json:"last_run"`
+	LastError string `json:"last_error"`
+}
+
+func NewJobRepository(client *supa.Client) *JobRepository {
+	return &JobRepository{client: client}
+}
+
+func (r *JobRepository) GetJob(ctx context.Context, name string) (*domain.Job, error) {
+	var results []ScheduledJob
+
+	err := r.client.DB.From("scheduled_jobs").
+		Select("*").
+		Eq("name", name).
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return toJob(results[0]), nil
+}
+
+func (r *JobRepository) GetJobs(ctx context.Context) ([]*domain.Job, error) {
+	var results []ScheduledJob
+
+	err := r.client.DB.From("scheduled_jobs").
+		Select("*").
+		OrderBy("next_run", "asc").
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*domain.Job
+	for _, result := range results {
+		jobs = append(jobs, toJob(result))
+	}
+	return jobs, nil
+}
+
+func (r *JobRepository) SaveJob(ctx context.Context, job *domain.Job) error {
+	data := ScheduledJob{
+		Name:      job.Name,
+		Kind:      job.Kind,
+		Schedule:  job.Schedule,
+		Payload:   job.Payload,
+		NextRun:   job.NextRun.UTC().Format(time.RFC3339),
+		LastError: job.LastError,
+	}
+	if !job.LastRun.IsZero() {
+		data.LastRun = job.LastRun.UTC().Format(time.RFC3339)
+	}
+
+	var results []ScheduledJob
+	return r.client.DB.From("scheduled_jobs").
+		Upsert(data).
+		Execute(&results)
+}
+
+func (r *JobRepository) DeleteJob(ctx context.Context, name string) error {
+	return r.client.DB.From("scheduled_jobs").
+		Delete().
+		Eq("name", name).
+		Execute(nil)
+}
+
+func (r *JobRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
+
+func toJob(result ScheduledJob) *domain.Job {
+	return &domain.Job{
+		Name:      result.Name,
+		Kind:      result.Kind,
+		Schedule:  result.Schedule,
+		Payload:   result.Payload,
+		NextRun:   parseTime(result.NextRun),
+		LastRun:   parseTime(result.LastRun),
+		LastError: result.LastError,
+	}
+}
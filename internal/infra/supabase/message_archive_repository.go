@@ -0,0 +1,161 @@
+package supabase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+type MessageArchiveRepository struct {
+	client *supa.Client
+}
+
+type MessageArchive struct {
+	ID         int64  `json:"id,omitempty"`
+	MessageID  string `json:"message_id"`
+	ChatID     string `json:"chat_id"`
+	SenderID   string `json:"sender_id"`
+	SenderName string `json:"sender_name"`
+	Text       string `json:"text"`
+	MediaType  string `json:"media_type"`
+	MediaMime  string `json:"media_mime"`
+	MediaSize  int64  `json:"media_size"`
+	SentAt     string `json:"sent_at"`
+}
+
+func NewMessageArchiveRepository(client *supa.Client) *MessageArchiveRepository {
+	return &MessageArchiveRepository{client: client}
+}
+
+func (r *MessageArchiveRepository) ArchiveMessage(ctx context.Context, msg *domain.ArchivedMessage) error {
+	data := MessageArchive{
+		MessageID:  msg.MessageID,
+		ChatID:     msg.ChatID,
+		SenderID:   msg.SenderID,
+		SenderName: msg.SenderName,
+		Text:       msg.Text,
+		MediaType:  msg.MediaType,
+		MediaMime:  msg.MediaMime,
+		MediaSize:  msg.MediaSize,
+		SentAt:     msg.SentAt.UTC().Format(time.RFC3339),
+	}
+
+	// message_id is unique, so a redelivered message merges into the same row
+	var results []MessageArchive
+	err := r.client.DB.From("message_archive").
+		Upsert(data).
+		Execute(&results)
+	if err != nil {
+		return err
+	}
+
+	if len(results) > 0 {
+		msg.ID = results[0].ID
+	}
+	return nil
+}
+
+func (r *MessageArchiveRepository) GetMessages(ctx context.Context, filter domain.MessageFilter) ([]*domain.ArchivedMessage, error) {
+	query := r.client.DB.From("message_archive").Select("*")
+	if filter.ChatID != "" {
+		query.Eq("chat_id", filter.ChatID)
+	}
+	if filter.SenderID != "" {
+		query.Eq("sender_id", filter.SenderID)
+	}
+	if !filter.Since.IsZero() {
+		query.Gte("sent_at", filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		query.Lt("sent_at", filter.Until.UTC().Format(time.RFC3339))
+	}
+	query.OrderBy("sent_at", "asc")
+	if filter.Limit > 0 {
+		query.Limit(filter.Limit)
+	}
+
+	var results []MessageArchive
+	if err := query.Execute(&results); err != nil {
+		return nil, err
+	}
+	return toArchivedMessages(results), nil
+}
+
+func toArchivedMessages(results []MessageArchive) []*domain.ArchivedMessage {
+	var messages []*domain.ArchivedMessage
+	for _, result := range results {
+		messages = append(messages, &domain.ArchivedMessage{
+			ID:         result.ID,
+			MessageID:  result.MessageID,
+			ChatID:     result.ChatID,
+			SenderID:   result.SenderID,
+			SenderName: result.SenderName,
+			Text:       result.Text,
+			MediaType:  result.MediaType,
+			MediaMime:  result.MediaMime,
+			MediaSize:  result.MediaSize,
+			SentAt:     parseTime(result.SentAt),
+		})
+	}
+	return messages
+}
+
+func (r *MessageArchiveRepository) SearchMessages(ctx context.Context, keyword string, filter domain.MessageFilter) ([]*domain.ArchivedMessage, error) {
+	words := strings.Fields(keyword)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	query := r.client.DB.From("message_archive").Select("*")
+	for _, w := range words {
+		query.Ilike("text", "*"+w+"*")
+	}
+	if filter.ChatID != "" {
+		query.Eq("chat_id", filter.ChatID)
+	}
+	if filter.SenderID != "" {
+		query.Eq("sender_id", filter.SenderID)
+	}
+	if !filter.Since.IsZero() {
+		query.Gte("sent_at", filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		query.Lt("sent_at", filter.Until.UTC().Format(time.RFC3339))
+	}
+	query.OrderBy("sent_at", "desc")
+	if filter.Limit > 0 {
+		query.Limit(filter.Limit)
+	}
+
+	var results []MessageArchive
+	if err := query.Execute(&results); err != nil {
+		return nil, err
+	}
+	return toArchivedMessages(results), nil
+}
+
+func (r *MessageArchiveRepository) DeleteMessages(ctx context.Context, filter domain.MessageFilter) error {
+	query := r.client.DB.From("message_archive").Delete()
+	if filter.ChatID != "" {
+		query.Eq("chat_id", filter.ChatID)
+	}
+	if filter.SenderID != "" {
+		query.Eq("sender_id", filter.SenderID)
+	}
+	if !filter.Since.IsZero() {
+		query.Gte("sent_at", filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		query.Lt("sent_at", filter.Until.UTC().Format(time.RFC3339))
+	}
+
+	return query.Execute(nil)
+}
+
+func (r *MessageArchiveRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
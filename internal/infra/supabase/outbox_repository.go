@@ -0,0 +1,215 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+// OutboxRepository needs the outbox table in Supabase:
+//
+//	CREATE TABLE outbox (
+//		message_id text PRIMARY KEY,
+//		chat_id text NOT NULL,
+//		text text NOT NULL DEFAULT '',
+//		queued_at text NOT NULL DEFAULT '',
+//		sent_at text NOT NULL,
+//		delivered_at text NOT NULL DEFAULT '',
+//		read_at text NOT NULL DEFAULT '',
+//		delivered_count integer NOT NULL DEFAULT 0,
+//		read_count integer NOT NULL DEFAULT 0
+//	);
+//	CREATE INDEX idx_outbox_sent_at ON outbox(sent_at);
+//
+// Tables created before queued replies need the column added:
+//
+//	ALTER TABLE outbox ADD COLUMN queued_at text NOT NULL DEFAULT '';
+type OutboxRepository struct {
+	client *supa.Client
+}
+
+type Outbox struct {
+	MessageID      string `json:"message_id"`
+	ChatID         string `json:"chat_id"`
+	Text           string `json:"text"`
+	QueuedAt       string `json:"queued_at"`
+	SentAt         string `json:"sent_at"`
+	DeliveredAt    string `json:"delivered_at"`
+	ReadAt         string `json:"read_at"`
+	DeliveredCount int    `json:"delivered_count"`
+	ReadCount      int    `json:"read_count"`
+}
+
+func NewOutboxRepository(client *supa.Client) *OutboxRepository {
+	return &OutboxRepository{client: client}
+}
+
+// QueueReply writes right away: Supabase has no transactions.
+func (r *OutboxRepository) QueueReply(ctx context.Context, msg *domain.OutboxMessage) error {
+	data := Outbox{
+		MessageID: msg.MessageID,
+		ChatID:    msg.ChatID,
+		Text:      msg.Text,
+		QueuedAt:  msg.QueuedAt.UTC().Format(time.RFC3339),
+	}
+
+	var results []Outbox
+	return r.client.DB.From("outbox").
+		Insert(data).
+		Execute(&results)
+}
+
+func (r *OutboxRepository) GetUnsent(ctx context.Context, since, until time.Time) ([]*domain.OutboxMessage, error) {
+	query := r.client.DB.From("outbox").Select("*")
+	query.Eq("sent_at", "")
+	query.Gte("queued_at", since.UTC().Format(time.RFC3339))
+	query.Lt("queued_at", until.UTC().Format(time.RFC3339))
+	query.OrderBy("queued_at", "asc")
+
+	var results []Outbox
+	if err := query.Execute(&results); err != nil {
+		return nil, err
+	}
+
+	var messages []*domain.OutboxMessage
+	for _, result := range results {
+		messages = append(messages, &domain.OutboxMessage{
+			MessageID: result.MessageID,
+			ChatID:    result.ChatID,
+			Text:      result.Text,
+			QueuedAt:  parseTime(result.QueuedAt),
+		})
+	}
+	return messages, nil
+}
+
+// RecordSent marks a queued reply sent, or inserts the message when it was
+// not queued.
+func (r *OutboxRepository) RecordSent(ctx context.Context, msg *domain.OutboxMessage) error {
+	sentAt := msg.SentAt.UTC().Format(time.RFC3339)
+	var updated []Outbox
+	err := r.client.DB.From("outbox").
+		Update(map[string]string{"text": msg.Text, "sent_at": sentAt}).
+		Eq("message_id", msg.MessageID).
+		Eq("sent_at", "").
+		Execute(&updated)
+	if err != nil || len(updated) > 0 {
+		return err
+	}
+
+	data := Outbox{
+		MessageID: msg.MessageID,
+		ChatID:    msg.ChatID,
+		Text:      msg.Text,
+		SentAt:    sentAt,
+	}
+
+	var results []Outbox
+	return r.client.DB.From("outbox").
+		Insert(data).
+		Execute(&results)
+}
+
+// RecordReceipt reads and writes back each row, as the REST API cannot
+// increment a column. Two receipts for the same message at the same moment
+// may count as one.
+func (r *OutboxRepository) RecordReceipt(ctx context.Context, messageIDs []string, read bool, at time.Time) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	var results []Outbox
+	err := r.client.DB.From("outbox").
+		Select("*").
+		In("message_id", messageIDs).
+		Execute(&results)
+	if err != nil {
+		return err
+	}
+
+	stamp := at.UTC().Format(time.RFC3339)
+	for _, row := range results {
+		// A read receipt also means the message arrived
+		if row.DeliveredAt == "" {
+			row.DeliveredAt = stamp
+		}
+		if read {
+			if row.ReadAt == "" {
+				row.ReadAt = stamp
+			}
+			row.ReadCount++
+		} else {
+			row.DeliveredCount++
+		}
+
+		var updated []Outbox
+		err := r.client.DB.From("outbox").
+			Update(row).
+			Eq("message_id", row.MessageID).
+			Execute(&updated)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *OutboxRepository) GetOutbox(ctx context.Context, filter domain.OutboxFilter) ([]*domain.OutboxMessage, error) {
+	query := r.client.DB.From("outbox").Select("*")
+	if filter.ChatID != "" {
+		query.Eq("chat_id", filter.ChatID)
+	}
+	if !filter.Since.IsZero() {
+		query.Gte("sent_at", filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		query.Lt("sent_at", filter.Until.UTC().Format(time.RFC3339))
+	}
+	query.OrderBy("sent_at", "desc")
+	if filter.Limit > 0 {
+		query.Limit(filter.Limit)
+	}
+
+	var results []Outbox
+	if err := query.Execute(&results); err != nil {
+		return nil, err
+	}
+
+	var messages []*domain.OutboxMessage
+	for _, result := range results {
+		messages = append(messages, &domain.OutboxMessage{
+			MessageID:      result.MessageID,
+			ChatID:         result.ChatID,
+			Text:           result.Text,
+			QueuedAt:       parseTime(result.QueuedAt),
+			SentAt:         parseTime(result.SentAt),
+			DeliveredAt:    parseTime(result.DeliveredAt),
+			ReadAt:         parseTime(result.ReadAt),
+			DeliveredCount: result.DeliveredCount,
+			ReadCount:      result.ReadCount,
+		})
+	}
+	return messages, nil
+}
+
+func (r *OutboxRepository) DeleteOutbox(ctx context.Context, filter domain.OutboxFilter) error {
+	query := r.client.DB.From("outbox").Delete()
+	if filter.ChatID != "" {
+		query.Eq("chat_id", filter.ChatID)
+	}
+	if !filter.Since.IsZero() {
+		query.Gte("sent_at", filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		query.Lt("sent_at", filter.Until.UTC().Format(time.RFC3339))
+	}
+
+	return query.Execute(nil)
+}
+
+func (r *OutboxRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
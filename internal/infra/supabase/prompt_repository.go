@@ -0,0 +1,70 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+// PromptRepository needs the report_prompts table in Supabase:
+//
+//	CREATE TABLE report_prompts (
+//		message_id text PRIMARY KEY,
+//		chat_id text NOT NULL,
+//		sent_at text NOT NULL
+//	);
+type PromptRepository struct {
+	client *supa.Client
+}
+
+type ReportPrompt struct {
+	MessageID string `json:"message_id"`
+	ChatID    string `json:"chat_id"`
+	SentAt    string `json:"sent_at"`
+}
+
+func NewPromptRepository(client *supa.Client) *PromptRepository {
+	return &PromptRepository{client: client}
+}
+
+func (r *PromptRepository) SavePrompt(ctx context.Context, prompt *domain.ReportPrompt) error {
+	data := ReportPrompt{
+		MessageID: prompt.MessageID,
+		ChatID:    prompt.ChatID,
+		SentAt:    prompt.SentAt.UTC().Format(time.RFC3339),
+	}
+
+	var results []ReportPrompt
+	return r.client.DB.From("report_prompts").
+		Upsert(data).
+		Execute(&results)
+}
+
+func (r *PromptRepository) GetPrompt(ctx context.Context, chatID, messageID string) (*domain.ReportPrompt, error) {
+	var results []ReportPrompt
+
+	err := r.client.DB.From("report_prompts").
+		Select("*").
+		Eq("chat_id", chatID).
+		Eq("message_id", messageID).
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return &domain.ReportPrompt{
+		MessageID: results[0].MessageID,
+		ChatID:    results[0].ChatID,
+		SentAt:    parseTime(results[0].SentAt),
+	}, nil
+}
+
+func (r *PromptRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
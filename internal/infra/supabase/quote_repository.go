@@ -0,0 +1,64 @@
+package supabase
+
+import (
+	"context"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+// QuoteRepository needs the quote_uses table in Supabase:
+//
+//	CREATE TABLE quote_uses (
+//		hash text PRIMARY KEY,
+//		day text NOT NULL
+//	);
+type QuoteRepository struct {
+	client *supa.Client
+}
+
+type QuoteUse struct {
+	Hash string `json:"hash"`
+	Day  string `json:"day"`
+}
+
+func NewQuoteRepository(client *supa.Client) *QuoteRepository {
+	return &QuoteRepository{client: client}
+}
+
+func (r *QuoteRepository) GetQuoteUses(ctx context.Context) ([]*domain.QuoteUse, error) {
+	var results []QuoteUse
+	err := r.client.DB.From("quote_uses").
+		Select("*").
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	uses := make([]*domain.QuoteUse, 0, len(results))
+	for _, result := range results {
+		uses = append(uses, &domain.QuoteUse{Hash: result.Hash, Day: result.Day})
+	}
+	return uses, nil
+}
+
+func (r *QuoteRepository) SaveQuoteUse(ctx context.Context, use *domain.QuoteUse) error {
+	var results []QuoteUse
+	return r.client.DB.From("quote_uses").
+		Upsert(QuoteUse{Hash: use.Hash, Day: use.Day}).
+		Execute(&results)
+}
+
+func (r *QuoteRepository) DeleteQuoteUses(ctx context.Context) error {
+	var results []QuoteUse
+	// PostgREST refuses a DELETE without a filter
+	return r.client.DB.From("quote_uses").
+		Delete().
+		Neq("hash", "").
+		Execute(&results)
+}
+
+func (r *QuoteRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
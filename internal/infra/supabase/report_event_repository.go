@@ -0,0 +1,143 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+// ReportEventRepository needs the report_events table in Supabase, started
+// with a snapshot of every existing report:
+//
+//	CREATE TABLE report_events (
+//		id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+//		user_id text NOT NULL,
+//		type text NOT NULL,
+//		at text NOT NULL,
+//		name text NOT NULL DEFAULT '',
+//		streak integer NOT NULL DEFAULT 0,
+//		activity_count integer NOT NULL DEFAULT 0,
+//		last_report_date text NOT NULL DEFAULT '',
+//		ref_id bigint NOT NULL DEFAULT 0,
+//		actor text NOT NULL DEFAULT '',
+//		note text NOT NULL DEFAULT ''
+//	);
+//	CREATE INDEX ON report_events (user_id, id);
+//	INSERT INTO report_events (user_id, type, at, name, streak, activity_count, last_report_date, actor, note)
+//	SELECT user_id, 'admin_adjusted', to_char(now(), 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), name, streak, activity_count, last_report_date, 'system', 'baseline'
+//	FROM user_reports;
+type ReportEventRepository struct {
+	client *supa.Client
+}
+
+type ReportEventRow struct {
+	ID             int64  `json:"id,omitempty"`
+	UserID         string `json:"user_id"`
+	Type           string `json:"type"`
+	At             string `json:"at"`
+	Name           string `json:"name"`
+	Streak         int    `json:"streak"`
+	ActivityCount  int    `json:"activity_count"`
+	LastReportDate string `json:"last_report_date"`
+	RefID          int64  `json:"ref_id"`
+	Actor          string `json:"actor"`
+	Note           string `json:"note"`
+}
+
+func NewReportEventRepository(client *supa.Client) *ReportEventRepository {
+	return &ReportEventRepository{client: client}
+}
+
+func (r *ReportEventRepository) AppendEvent(ctx context.Context, event *domain.ReportEvent) error {
+	data := ReportEventRow{
+		UserID:        event.UserID,
+		Type:          event.Type,
+		At:            event.At.Format(time.RFC3339),
+		Name:          event.Name,
+		Streak:        event.Streak,
+		ActivityCount: event.ActivityCount,
+		RefID:         event.RefID,
+		Actor:         event.Actor,
+		Note:          event.Note,
+	}
+	if !event.LastReportDate.IsZero() {
+		data.LastReportDate = event.LastReportDate.Format(time.RFC3339)
+	}
+
+	var results []ReportEventRow
+	err := r.client.DB.From("report_events").
+		Insert(data).
+		Execute(&results)
+	if err != nil {
+		return err
+	}
+
+	if len(results) > 0 {
+		event.ID = results[0].ID
+	}
+	return nil
+}
+
+func (r *ReportEventRepository) GetEvents(ctx context.Context, userID string) ([]*domain.ReportEvent, error) {
+	query := r.client.DB.From("report_events").Select("*")
+	query.Eq("user_id", userID)
+
+	var results []ReportEventRow
+	if err := query.OrderBy("id", "asc").Execute(&results); err != nil {
+		return nil, err
+	}
+
+	var events []*domain.ReportEvent
+	for _, result := range results {
+		event := &domain.ReportEvent{
+			ID:            result.ID,
+			UserID:        result.UserID,
+			Type:          result.Type,
+			At:            parseTime(result.At),
+			Name:          result.Name,
+			Streak:        result.Streak,
+			ActivityCount: result.ActivityCount,
+			RefID:         result.RefID,
+			Actor:         result.Actor,
+			Note:          result.Note,
+		}
+		if result.LastReportDate != "" {
+			event.LastReportDate = parseTime(result.LastReportDate)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (r *ReportEventRepository) EventUserIDs(ctx context.Context) ([]string, error) {
+	var results []ReportEventRow
+	err := r.client.DB.From("report_events").
+		Select("user_id").
+		OrderBy("user_id", "asc").
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs []string
+	for i, result := range results {
+		if i == 0 || result.UserID != results[i-1].UserID {
+			userIDs = append(userIDs, result.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+func (r *ReportEventRepository) DeleteEvents(ctx context.Context, userID string) error {
+	return r.client.DB.From("report_events").
+		Delete().
+		Eq("user_id", userID).
+		Execute(nil)
+}
+
+func (r *ReportEventRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
@@ -2,14 +2,20 @@ package supabase
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
 	supa "github.com/nedpals/supabase-go"
+	postgrest "github.com/nedpals/supabase-go/postgrest/pkg"
 )
 
 type ReportRepository struct {
 	client *supa.Client
+	reads  *supa.Client // leaderboard and dashboard reads, defaults to client
+	hasher *phone.Hasher
 }
 
 type UserReport struct {
@@ -18,6 +24,35 @@ type UserReport struct {
 	Streak         int    `json:"streak"`
 	ActivityCount  int    `json:"activity_count"`
 	LastReportDate string `json:"last_report_date"`
+	Version        int    `json:"version,omitempty"`
+	// Needs the column in Supabase:
+	//
+	//	ALTER TABLE user_reports ADD COLUMN first_report_date text NOT NULL DEFAULT '';
+	FirstReportDate string `json:"first_report_date"`
+	// Needs the column in Supabase:
+	//
+	//	ALTER TABLE user_reports ADD COLUMN best_streak integer NOT NULL DEFAULT 0;
+	//	UPDATE user_reports SET best_streak = streak WHERE best_streak < streak;
+	BestStreak int `json:"best_streak"`
+}
+
+// toDomain converts a row, leaving unset dates zero.
+func (u UserReport) toDomain() *domain.Report {
+	report := &domain.Report{
+		UserID:        u.UserID,
+		Name:          u.Name,
+		Streak:        u.Streak,
+		BestStreak:    u.BestStreak,
+		ActivityCount: u.ActivityCount,
+		Version:       u.Version,
+	}
+	if u.LastReportDate != "" {
+		report.LastReportDate = parseTime(u.LastReportDate)
+	}
+	if u.FirstReportDate != "" {
+		report.FirstReportDate = parseTime(u.FirstReportDate)
+	}
+	return report
 }
 
 type LIDMap struct {
@@ -26,7 +61,20 @@ type LIDMap struct {
 }
 
 func NewReportRepository(client *supa.Client) *ReportRepository {
-	return &ReportRepository{client: client}
+	return &ReportRepository{client: client, reads: client}
+}
+
+// SetUserIDHasher lets DeleteLIDMappings find the mappings of a hashed user
+// ID.
+func (r *ReportRepository) SetUserIDHasher(hasher *phone.Hasher) {
+	r.hasher = hasher
+}
+
+// SetReadReplica sends GetAllReports and ListReports to a read replica.
+// GetReport stays on the primary: a #lapor reads the row right before
+// updating it, and a lagging replica would break the streak.
+func (r *ReportRepository) SetReadReplica(client *supa.Client) {
+	r.reads = client
 }
 
 func (r *ReportRepository) GetReport(ctx context.Context, userID string) (*domain.Report, error) {
@@ -45,21 +93,12 @@ func (r *ReportRepository) GetReport(ctx context.Context, userID string) (*domai
 		return nil, nil
 	}
 
-	result := results[0]
-	report := &domain.Report{
-		UserID:        result.UserID,
-		Name:          result.Name,
-		Streak:        result.Streak,
-		ActivityCount: result.ActivityCount,
-	}
-
-	if result.LastReportDate != "" {
-		report.LastReportDate = parseTime(result.LastReportDate)
-	}
-
-	return report, nil
+	return results[0].toDomain(), nil
 }
 
+// UpsertReport needs the version column in Supabase:
+//
+//	ALTER TABLE user_reports ADD COLUMN version integer NOT NULL DEFAULT 1;
 func (r *ReportRepository) UpsertReport(ctx context.Context, report *domain.Report) error {
 	data := UserReport{
 		UserID:         report.UserID,
@@ -67,20 +106,69 @@ func (r *ReportRepository) UpsertReport(ctx context.Context, report *domain.Repo
 		Streak:         report.Streak,
 		ActivityCount:  report.ActivityCount,
 		LastReportDate: report.LastReportDate.Format("2006-01-02T15:04:05Z07:00"),
+		Version:        report.Version + 1,
+		BestStreak:     report.BestStreak,
+	}
+	if !report.FirstReportDate.IsZero() {
+		data.FirstReportDate = report.FirstReportDate.Format("2006-01-02T15:04:05Z07:00")
 	}
 
 	var results []UserReport
-	err := r.client.DB.From("user_reports").
-		Upsert(data).
-		Execute(&results)
+	var err error
+	if report.Version == 0 {
+		err = r.client.DB.From("user_reports").
+			Insert(data).
+			Execute(&results)
+		var reqErr *postgrest.RequestError
+		if errors.As(err, &reqErr) && reqErr.Code == "23505" { // unique_violation
+			return domain.ErrReportConflict
+		}
+	} else {
+		err = r.client.DB.From("user_reports").
+			Update(data).
+			Eq("user_id", report.UserID).
+			Eq("version", strconv.Itoa(report.Version)).
+			Execute(&results)
+	}
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return domain.ErrReportConflict
+	}
+	report.Version++
+	return nil
+}
 
-	return err
+// SubmitReport can't be a single statement over the REST API. It repeats the
+// version-checked read-modify-write instead when another write got there
+// first.
+func (r *ReportRepository) SubmitReport(ctx context.Context, userID, name string, at time.Time) (*domain.Report, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := r.GetReport(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		next, err := domain.ApplyReport(current, userID, name, at)
+		if err != nil {
+			return nil, err
+		}
+
+		err = r.UpsertReport(ctx, next)
+		if errors.Is(err, domain.ErrReportConflict) && attempt < 3 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return next, nil
+	}
 }
 
 func (r *ReportRepository) GetAllReports(ctx context.Context) ([]*domain.Report, error) {
 	var results []UserReport
 
-	err := r.client.DB.From("user_reports").
+	err := r.reads.DB.From("user_reports").
 		Select("*").
 		Execute(&results)
 
@@ -90,23 +178,77 @@ func (r *ReportRepository) GetAllReports(ctx context.Context) ([]*domain.Report,
 
 	var reports []*domain.Report
 	for _, result := range results {
-		report := &domain.Report{
-			UserID:        result.UserID,
-			Name:          result.Name,
-			Streak:        result.Streak,
-			ActivityCount: result.ActivityCount,
+		reports = append(reports, result.toDomain())
+	}
+
+	return reports, nil
+}
+
+func (r *ReportRepository) ListReports(ctx context.Context, q domain.ReportQuery) ([]*domain.Report, error) {
+	query := r.reads.DB.From("user_reports").Select("*")
+	if q.UserIDs != nil {
+		if len(q.UserIDs) == 0 {
+			return nil, nil
 		}
+		query.In("user_id", q.UserIDs)
+	}
+	if !q.Since.IsZero() {
+		query.Gte("last_report_date", q.Since.UTC().Format(time.RFC3339))
+	}
+	if !q.Until.IsZero() {
+		query.Lt("last_report_date", q.Until.UTC().Format(time.RFC3339))
+	}
 
-		if result.LastReportDate != "" {
-			report.LastReportDate = parseTime(result.LastReportDate)
+	// Keyset pagination. PostgREST takes "or=(a,b)" and "order=col.dir,col.dir";
+	// the builder only joins one column and value, so the rest is passed inline.
+	column, direction, after := "activity_count", "desc", ""
+	switch q.Sort {
+	case domain.ReportSortStreak:
+		column = "streak"
+		if q.After != nil {
+			after = strconv.Itoa(q.After.Streak)
+		}
+	case domain.ReportSortName:
+		column, direction = "name", "asc"
+		if q.After != nil {
+			after = postgrest.SanitizeParam(q.After.Name)
+		}
+	default:
+		if q.After != nil {
+			after = strconv.Itoa(q.After.ActivityCount)
 		}
+	}
+	if q.After != nil {
+		cmp := "lt"
+		if direction == "asc" {
+			cmp = "gt"
+		}
+		query.Filter("or", "("+column+"."+cmp, after+",and("+column+".eq."+after+",user_id.gt."+postgrest.SanitizeParam(q.After.UserID)+"))")
+	}
+	sel := query.OrderBy(column+"."+direction+",user_id", "asc")
+	if q.Limit > 0 {
+		sel.Limit(q.Limit)
+	}
 
-		reports = append(reports, report)
+	var results []UserReport
+	if err := sel.Execute(&results); err != nil {
+		return nil, err
 	}
 
+	reports := make([]*domain.Report, 0, len(results))
+	for _, result := range results {
+		reports = append(reports, result.toDomain())
+	}
 	return reports, nil
 }
 
+func (r *ReportRepository) DeleteReport(ctx context.Context, userID string) error {
+	return r.client.DB.From("user_reports").
+		Delete().
+		Eq("user_id", userID).
+		Execute(nil)
+}
+
 func (r *ReportRepository) InitTable(ctx context.Context) error {
 	// Table initialization is handled by the SQL schema in Supabase
 	// This method is kept for compatibility but does nothing
@@ -132,6 +274,35 @@ func (r *ReportRepository) ResolveLIDToPhone(ctx context.Context, lid string) st
 	return lid
 }
 
+// DeleteLIDMappings removes the whatsmeow_lid_map rows pointing at a phone
+// number, so the member can no longer be linked to their LID. A hashed user
+// ID is matched by hashing every mapped number.
+func (r *ReportRepository) DeleteLIDMappings(ctx context.Context, userID string) error {
+	numbers := []string{userID}
+	if r.hasher != nil && phone.IsHashed(userID) {
+		var mappings []LIDMap
+		if err := r.client.DB.From("whatsmeow_lid_map").Select("pn").Execute(&mappings); err != nil {
+			return err
+		}
+		numbers = nil
+		for _, m := range mappings {
+			if r.hasher.UserID(m.PN) == userID {
+				numbers = append(numbers, m.PN)
+			}
+		}
+	}
+	for _, number := range numbers {
+		err := r.client.DB.From("whatsmeow_lid_map").
+			Delete().
+			Eq("pn", number).
+			Execute(nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Helper function to parse time strings
 func parseTime(timeStr string) time.Time {
 	t, err := time.Parse(time.RFC3339, timeStr)
@@ -0,0 +1,218 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+// SettingsRepository stores member settings in the user_settings table.
+// Tables created before the ranking exclusion, #privat, #email, #kalender
+// and #jangan-tag need:
+//
+//	ALTER TABLE user_settings ADD COLUMN unranked boolean NOT NULL DEFAULT false;
+//	ALTER TABLE user_settings ADD COLUMN private boolean NOT NULL DEFAULT false;
+//	ALTER TABLE user_settings ADD COLUMN email text NOT NULL DEFAULT '';
+//	ALTER TABLE user_settings ADD COLUMN calendar_token text NOT NULL DEFAULT '';
+//	ALTER TABLE user_settings ADD COLUMN no_mention boolean NOT NULL DEFAULT false;
+type SettingsRepository struct {
+	client *supa.Client
+}
+
+type UserSettings struct {
+	UserID        string `json:"user_id"`
+	Target        int    `json:"target"`
+	SnoozeUntil   string `json:"snooze_until"`
+	ReminderTime  string `json:"reminder_time"`
+	Timezone      string `json:"timezone"`
+	DisplayName   string `json:"display_name"`
+	JoinedAt      string `json:"joined_at"`
+	VerifyBy      string `json:"verify_by"`
+	Unranked      bool   `json:"unranked"`
+	Private       bool   `json:"private"`
+	Email         string `json:"email"`
+	CalendarToken string `json:"calendar_token"`
+	NoMention     bool   `json:"no_mention"`
+}
+
+func NewSettingsRepository(client *supa.Client) *SettingsRepository {
+	return &SettingsRepository{client: client}
+}
+
+func (r *SettingsRepository) GetSettings(ctx context.Context, userID string) (*domain.UserSettings, error) {
+	var results []UserSettings
+
+	err := r.client.DB.From("user_settings").
+		Select("*").
+		Eq("user_id", userID).
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	return toUserSettings(results[0]), nil
+}
+
+func (r *SettingsRepository) GetReminderSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	var results []UserSettings
+
+	err := r.client.DB.From("user_settings").
+		Select("*").
+		Neq("reminder_time", "").
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*domain.UserSettings
+	for _, result := range results {
+		list = append(list, toUserSettings(result))
+	}
+	return list, nil
+}
+
+func (r *SettingsRepository) GetUnrankedSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	// The client has no OR filter, so both flags are queried on their own
+	var list []*domain.UserSettings
+	seen := make(map[string]bool)
+	for _, column := range []string{"unranked", "private"} {
+		var results []UserSettings
+		err := r.client.DB.From("user_settings").
+			Select("*").
+			Eq(column, "true").
+			Execute(&results)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if !seen[result.UserID] {
+				seen[result.UserID] = true
+				list = append(list, toUserSettings(result))
+			}
+		}
+	}
+	return list, nil
+}
+
+func (r *SettingsRepository) GetUnverifiedSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	var results []UserSettings
+
+	err := r.client.DB.From("user_settings").
+		Select("*").
+		Neq("verify_by", "").
+		Eq("joined_at", "").
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*domain.UserSettings
+	for _, result := range results {
+		list = append(list, toUserSettings(result))
+	}
+	return list, nil
+}
+
+func (r *SettingsRepository) GetEmailSettings(ctx context.Context) ([]*domain.UserSettings, error) {
+	var results []UserSettings
+
+	err := r.client.DB.From("user_settings").
+		Select("*").
+		Neq("email", "").
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*domain.UserSettings
+	for _, result := range results {
+		list = append(list, toUserSettings(result))
+	}
+	return list, nil
+}
+
+func (r *SettingsRepository) GetSettingsByCalendarToken(ctx context.Context, token string) (*domain.UserSettings, error) {
+	if token == "" {
+		return nil, nil
+	}
+	var results []UserSettings
+
+	err := r.client.DB.From("user_settings").
+		Select("*").
+		Eq("calendar_token", token).
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	return toUserSettings(results[0]), nil
+}
+
+func (r *SettingsRepository) SaveSettings(ctx context.Context, settings *domain.UserSettings) error {
+	data := UserSettings{
+		UserID:        settings.UserID,
+		Target:        settings.Target,
+		ReminderTime:  settings.ReminderTime,
+		Timezone:      settings.Timezone,
+		DisplayName:   settings.DisplayName,
+		Unranked:      settings.Unranked,
+		Private:       settings.Private,
+		Email:         settings.Email,
+		CalendarToken: settings.CalendarToken,
+		NoMention:     settings.NoMention,
+	}
+	if !settings.SnoozeUntil.IsZero() {
+		data.SnoozeUntil = settings.SnoozeUntil.UTC().Format(time.RFC3339)
+	}
+	if !settings.JoinedAt.IsZero() {
+		data.JoinedAt = settings.JoinedAt.UTC().Format(time.RFC3339)
+	}
+	if !settings.VerifyBy.IsZero() {
+		data.VerifyBy = settings.VerifyBy.UTC().Format(time.RFC3339)
+	}
+
+	var results []UserSettings
+	return r.client.DB.From("user_settings").
+		Upsert(data).
+		Execute(&results)
+}
+
+func (r *SettingsRepository) DeleteSettings(ctx context.Context, userID string) error {
+	return r.client.DB.From("user_settings").
+		Delete().
+		Eq("user_id", userID).
+		Execute(nil)
+}
+
+func (r *SettingsRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
+
+func toUserSettings(result UserSettings) *domain.UserSettings {
+	return &domain.UserSettings{
+		UserID:        result.UserID,
+		Target:        result.Target,
+		SnoozeUntil:   parseTime(result.SnoozeUntil),
+		ReminderTime:  result.ReminderTime,
+		Timezone:      result.Timezone,
+		DisplayName:   result.DisplayName,
+		JoinedAt:      parseTime(result.JoinedAt),
+		VerifyBy:      parseTime(result.VerifyBy),
+		Unranked:      result.Unranked,
+		Private:       result.Private,
+		Email:         result.Email,
+		CalendarToken: result.CalendarToken,
+		NoMention:     result.NoMention,
+	}
+}
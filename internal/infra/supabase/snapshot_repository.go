@@ -0,0 +1,133 @@
+package supabase
+
+import (
+	"context"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+// SnapshotRepository needs the leaderboard_snapshots table in Supabase:
+//
+//	CREATE TABLE leaderboard_snapshots (
+//		day text NOT NULL,
+//		user_id text NOT NULL,
+//		name text NOT NULL DEFAULT '',
+//		rank integer NOT NULL,
+//		streak integer NOT NULL DEFAULT 0,
+//		total integer NOT NULL DEFAULT 0,
+//		reported boolean NOT NULL DEFAULT false,
+//		PRIMARY KEY (day, user_id)
+//	);
+type SnapshotRepository struct {
+	client *supa.Client
+}
+
+type SnapshotRow struct {
+	Day      string `json:"day"`
+	UserID   string `json:"user_id"`
+	Name     string `json:"name"`
+	Rank     int    `json:"rank"`
+	Streak   int    `json:"streak"`
+	Total    int    `json:"total"`
+	Reported bool   `json:"reported"`
+}
+
+func NewSnapshotRepository(client *supa.Client) *SnapshotRepository {
+	return &SnapshotRepository{client: client}
+}
+
+// SaveSnapshot deletes the day's rows and inserts the new ones in a single
+// request; without transactions a failed insert leaves the day empty until
+// the next snapshot.
+func (r *SnapshotRepository) SaveSnapshot(ctx context.Context, day string, entries []*domain.SnapshotEntry) error {
+	err := r.client.DB.From("leaderboard_snapshots").
+		Delete().
+		Eq("day", day).
+		Execute(nil)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	data := make([]SnapshotRow, 0, len(entries))
+	for _, e := range entries {
+		data = append(data, SnapshotRow{
+			Day:      day,
+			UserID:   e.UserID,
+			Name:     e.Name,
+			Rank:     e.Rank,
+			Streak:   e.Streak,
+			Total:    e.Total,
+			Reported: e.Reported,
+		})
+	}
+	var results []SnapshotRow
+	return r.client.DB.From("leaderboard_snapshots").
+		Insert(data).
+		Execute(&results)
+}
+
+func (r *SnapshotRepository) GetSnapshot(ctx context.Context, day string) ([]*domain.SnapshotEntry, error) {
+	query := r.client.DB.From("leaderboard_snapshots").Select("*")
+	query.Eq("day", day)
+
+	var results []SnapshotRow
+	if err := query.OrderBy("rank", "asc").Execute(&results); err != nil {
+		return nil, err
+	}
+	return snapshotEntries(results), nil
+}
+
+func (r *SnapshotRepository) GetSnapshots(ctx context.Context, filter domain.SnapshotFilter) ([]*domain.SnapshotEntry, error) {
+	query := r.client.DB.From("leaderboard_snapshots").Select("*")
+	if filter.UserID != "" {
+		query.Eq("user_id", filter.UserID)
+	}
+	if filter.Before != "" {
+		query.Lt("day", filter.Before)
+	}
+
+	var results []SnapshotRow
+	if err := query.OrderBy("day", "asc").Execute(&results); err != nil {
+		return nil, err
+	}
+	return snapshotEntries(results), nil
+}
+
+func snapshotEntries(results []SnapshotRow) []*domain.SnapshotEntry {
+
+	var entries []*domain.SnapshotEntry
+	for _, result := range results {
+		entries = append(entries, &domain.SnapshotEntry{
+			Day:      result.Day,
+			UserID:   result.UserID,
+			Name:     result.Name,
+			Rank:     result.Rank,
+			Streak:   result.Streak,
+			Total:    result.Total,
+			Reported: result.Reported,
+		})
+	}
+	return entries
+}
+
+func (r *SnapshotRepository) DeleteSnapshots(ctx context.Context, filter domain.SnapshotFilter) error {
+	query := r.client.DB.From("leaderboard_snapshots").Delete()
+	if filter.UserID != "" {
+		query.Eq("user_id", filter.UserID)
+	}
+	if filter.Before != "" {
+		query.Lt("day", filter.Before)
+	}
+	// PostgREST refuses a DELETE without a filter
+	query.Neq("day", "")
+	return query.Execute(nil)
+}
+
+func (r *SnapshotRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
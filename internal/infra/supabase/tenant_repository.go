@@ -0,0 +1,114 @@
+package supabase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	supa "github.com/nedpals/supabase-go"
+)
+
+// TenantRepository needs the bot_tenants table in Supabase:
+//
+//	CREATE TABLE bot_tenants (
+//		id text PRIMARY KEY,
+//		name text NOT NULL DEFAULT '',
+//		enabled boolean NOT NULL DEFAULT true,
+//		env jsonb NOT NULL DEFAULT '{}',
+//		created_at text NOT NULL
+//	);
+type TenantRepository struct {
+	client *supa.Client
+}
+
+type BotTenant struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Enabled   bool              `json:"enabled"`
+	Env       map[string]string `json:"env"`
+	CreatedAt string            `json:"created_at"`
+}
+
+func NewTenantRepository(client *supa.Client) *TenantRepository {
+	return &TenantRepository{client: client}
+}
+
+func (r *TenantRepository) GetTenant(ctx context.Context, id string) (*domain.Tenant, error) {
+	var results []BotTenant
+
+	err := r.client.DB.From("bot_tenants").
+		Select("*").
+		Eq("id", id).
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return toTenant(results[0]), nil
+}
+
+func (r *TenantRepository) GetTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	var results []BotTenant
+
+	err := r.client.DB.From("bot_tenants").
+		Select("*").
+		OrderBy("created_at", "asc").
+		Execute(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenants []*domain.Tenant
+	for _, result := range results {
+		tenants = append(tenants, toTenant(result))
+	}
+	return tenants, nil
+}
+
+func (r *TenantRepository) SaveTenant(ctx context.Context, tenant *domain.Tenant) error {
+	data := BotTenant{
+		ID:        tenant.ID,
+		Name:      tenant.Name,
+		Enabled:   tenant.Enabled,
+		Env:       tenant.Env,
+		CreatedAt: tenant.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if data.Env == nil {
+		data.Env = make(map[string]string)
+	}
+
+	var results []BotTenant
+	return r.client.DB.From("bot_tenants").
+		Upsert(data).
+		Execute(&results)
+}
+
+func (r *TenantRepository) DeleteTenant(ctx context.Context, id string) error {
+	var results []BotTenant
+	return r.client.DB.From("bot_tenants").
+		Delete().
+		Eq("id", id).
+		Execute(&results)
+}
+
+func (r *TenantRepository) InitTable(ctx context.Context) error {
+	// Table initialization is handled by the SQL schema in Supabase
+	return nil
+}
+
+func toTenant(result BotTenant) *domain.Tenant {
+	tenant := &domain.Tenant{
+		ID:        result.ID,
+		Name:      result.Name,
+		Enabled:   result.Enabled,
+		Env:       result.Env,
+		CreatedAt: parseTime(result.CreatedAt),
+	}
+	if tenant.Env == nil {
+		tenant.Env = make(map[string]string)
+	}
+	return tenant
+}
@@ -0,0 +1,78 @@
+package wa
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// GroupCache keeps group metadata (subject and participants) for a while so
+// reminders, recaps and mentions don't ask WhatsApp on every message, which
+// is slow and rate-limited. Entries are dropped when they expire or when a
+// GroupInfo event says the group changed.
+type GroupCache struct {
+	fetch func(ctx context.Context, jid types.JID) (*types.GroupInfo, error)
+
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[types.JID]groupCacheEntry
+}
+
+type groupCacheEntry struct {
+	info    *types.GroupInfo
+	expires time.Time
+}
+
+func NewGroupCache(ttl time.Duration, fetch func(ctx context.Context, jid types.JID) (*types.GroupInfo, error)) *GroupCache {
+	return &GroupCache{
+		fetch:   fetch,
+		ttl:     ttl,
+		entries: make(map[types.JID]groupCacheEntry),
+	}
+}
+
+// SetTTL changes how long new entries stay fresh.
+func (c *GroupCache) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+}
+
+// Get returns the cached metadata of a group, fetching it when missing or
+// expired.
+func (c *GroupCache) Get(ctx context.Context, jid types.JID) (*types.GroupInfo, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[jid]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.info, nil
+	}
+
+	info, err := c.fetch(ctx, jid)
+	if err != nil {
+		return nil, err
+	}
+	c.Put(info)
+	return info, nil
+}
+
+// Put stores metadata that arrived some other way, e.g. from
+// GetJoinedGroups or a JoinedGroup event.
+func (c *GroupCache) Put(info *types.GroupInfo) {
+	if info == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[info.JID] = groupCacheEntry{info: info, expires: time.Now().Add(c.ttl)}
+}
+
+// Invalidate drops a group so the next Get fetches it again.
+func (c *GroupCache) Invalidate(jid types.JID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, jid)
+}
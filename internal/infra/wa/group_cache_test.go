@@ -0,0 +1,73 @@
+package wa_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// =============================================================================
+// GROUP METADATA CACHE TESTS
+// =============================================================================
+
+func TestGroupCache_FetchesOncePerTTL(t *testing.T) {
+	jid := types.NewJID("222", types.GroupServer)
+	fetches := 0
+	cache := wa.NewGroupCache(time.Hour, func(ctx context.Context, jid types.JID) (*types.GroupInfo, error) {
+		fetches++
+		return &types.GroupInfo{JID: jid, GroupName: types.GroupName{Name: "Lari Pagi"}}, nil
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		info, err := cache.Get(ctx, jid)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if info.Name != "Lari Pagi" {
+			t.Errorf("Expected cached name, got '%s'", info.Name)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected 1 fetch, got %d", fetches)
+	}
+
+	// A GroupInfo event invalidates the entry
+	cache.Invalidate(jid)
+	if _, err := cache.Get(ctx, jid); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("Expected a refetch after invalidation, got %d fetches", fetches)
+	}
+
+	// Expired entries are fetched again
+	cache.SetTTL(time.Millisecond)
+	cache.Invalidate(jid)
+	_, _ = cache.Get(ctx, jid)
+	time.Sleep(5 * time.Millisecond)
+	_, _ = cache.Get(ctx, jid)
+	if fetches != 4 {
+		t.Errorf("Expected a refetch after expiry, got %d fetches", fetches)
+	}
+}
+
+func TestGroupCache_PutSkipsFetch(t *testing.T) {
+	jid := types.NewJID("333", types.GroupServer)
+	cache := wa.NewGroupCache(time.Hour, func(ctx context.Context, jid types.JID) (*types.GroupInfo, error) {
+		t.Fatalf("Unexpected fetch for %s", jid)
+		return nil, nil
+	})
+
+	cache.Put(&types.GroupInfo{JID: jid, Participants: make([]types.GroupParticipant, 5)})
+	info, err := cache.Get(context.Background(), jid)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(info.Participants) != 5 {
+		t.Errorf("Expected 5 participants, got %d", len(info.Participants))
+	}
+}
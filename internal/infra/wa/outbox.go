@@ -0,0 +1,65 @@
+package wa
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// NewMessageID returns an ID to send a message with, for replies queued in
+// the outbox before they are sent. It needs no session, so workers can
+// queue replies too.
+func NewMessageID() string {
+	return whatsmeow.GenerateMessageID()
+}
+
+type messageIDKey struct{}
+
+// queuedID hands its ID to the first message sent, so the other parts of a
+// split reply get their own.
+type queuedID struct {
+	mu sync.Mutex
+	id string
+}
+
+func (q *queuedID) take() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	id := q.id
+	q.id = ""
+	return id
+}
+
+// WithMessageID sends the first message of ctx with the ID its reply was
+// queued with in the outbox. An empty ID changes nothing.
+func WithMessageID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, messageIDKey{}, &queuedID{id: id})
+}
+
+// sendExtra returns the ID set with WithMessageID, once.
+func sendExtra(ctx context.Context) []whatsmeow.SendRequestExtra {
+	q, ok := ctx.Value(messageIDKey{}).(*queuedID)
+	if !ok {
+		return nil
+	}
+	if id := q.take(); id != "" {
+		return []whatsmeow.SendRequestExtra{{ID: types.MessageID(id)}}
+	}
+	return nil
+}
+
+// SendQueued sends the text of a reply that was queued in the outbox but
+// never sent, under the ID it was queued with.
+func (s *Service) SendQueued(ctx context.Context, msg *domain.OutboxMessage) error {
+	to, err := types.ParseJID(msg.ChatID)
+	if err != nil {
+		return err
+	}
+	return s.SendText(WithMessageID(ctx, msg.MessageID), to, msg.Text)
+}
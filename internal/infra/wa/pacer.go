@@ -0,0 +1,202 @@
+package wa
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// PacingProfile is how fast messages go out. It has the fields of
+// humanize.PacingProfile, which converts to it, so the policy stays with
+// the humanize settings.
+type PacingProfile struct {
+	PerMinute int           // Messages in any 60 seconds, 0 = no cap
+	MinGap    time.Duration // Random gap between two messages
+	MaxGap    time.Duration
+	Smooth    bool // The gap grows as the minute fills up
+}
+
+// Lane is the priority of an outgoing message. When messages are waiting
+// for their turn, a lower lane always goes first, so a member's #lapor is
+// answered right away even while the evening reminder DMs are going out.
+type Lane int
+
+const (
+	LaneReply  Lane = iota // Answers to commands
+	LaneNotice             // Celebrations, welcome messages and admin alerts; the default
+	LaneBulk               // Scheduled reminders, recaps and other broadcasts
+)
+
+type laneKey struct{}
+
+// WithLane sends the messages of ctx in the given lane.
+func WithLane(ctx context.Context, lane Lane) context.Context {
+	return context.WithValue(ctx, laneKey{}, lane)
+}
+
+// LaneFrom returns the lane set with WithLane, or LaneNotice.
+func LaneFrom(ctx context.Context) Lane {
+	if lane, ok := ctx.Value(laneKey{}).(Lane); ok {
+		return lane
+	}
+	return LaneNotice
+}
+
+// Pacer hands out send times following a PacingProfile. A message that
+// cannot go out right away waits in a queue; whenever a slot opens, the
+// waiting message in the lowest lane gets it, oldest first within a lane.
+type Pacer struct {
+	profile PacingProfile
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	sent     []time.Time // Send times within the last minute, oldest first
+	next     time.Time   // Earliest time of the next send, after the gap
+	queue    []*ticket
+	seq      uint64
+	draining bool // The dispatcher goroutine is running
+}
+
+type ticket struct {
+	lane  Lane
+	seq   uint64
+	ready chan struct{}
+}
+
+func NewPacer(profile PacingProfile) *Pacer {
+	return &Pacer{profile: profile, rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Reserve returns how long a message ready at now has to wait and books
+// that send time, bypassing the queue.
+func (p *Pacer) Reserve(now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	at := p.slot(now)
+	p.book(at)
+	return at.Sub(now)
+}
+
+// slot returns the earliest send time at or after now.
+func (p *Pacer) slot(now time.Time) time.Time {
+	at := now
+	if p.next.After(at) {
+		at = p.next
+	}
+	if n := p.profile.PerMinute; n > 0 {
+		recent := 0
+		for _, t := range p.sent {
+			if t.After(at.Add(-time.Minute)) {
+				recent++
+			}
+		}
+		if recent >= n {
+			at = p.sent[len(p.sent)-n].Add(time.Minute)
+		}
+	}
+	return at
+}
+
+// book records a send at at and draws the gap before the next one.
+func (p *Pacer) book(at time.Time) {
+	p.sent = append(p.sent, at)
+	for len(p.sent) > 0 && !p.sent[0].After(at.Add(-time.Minute)) {
+		p.sent = p.sent[1:]
+	}
+
+	gap := p.profile.MinGap
+	if spread := p.profile.MaxGap - p.profile.MinGap; spread > 0 {
+		gap += time.Duration(p.rand.Int63n(int64(spread) + 1))
+	}
+	if p.profile.Smooth && p.profile.PerMinute > 0 {
+		gap += gap * time.Duration(len(p.sent)) / time.Duration(p.profile.PerMinute)
+	}
+	p.next = at.Add(gap)
+}
+
+// Wait blocks until the next message in ctx's lane may be sent or ctx is
+// done.
+func (p *Pacer) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	if len(p.queue) == 0 && !p.slot(now).After(now) {
+		p.book(now)
+		p.mu.Unlock()
+		return nil
+	}
+
+	p.seq++
+	t := &ticket{lane: LaneFrom(ctx), seq: p.seq, ready: make(chan struct{})}
+	p.queue = append(p.queue, t)
+	if !p.draining {
+		p.draining = true
+		go p.drain()
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-t.ready:
+		return nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for i, queued := range p.queue {
+			if queued == t {
+				p.queue = append(p.queue[:i], p.queue[i+1:]...)
+				break
+			}
+		}
+		return ctx.Err()
+	}
+}
+
+// drain hands out slots until the queue is empty.
+func (p *Pacer) drain() {
+	for {
+		p.mu.Lock()
+		if len(p.queue) == 0 {
+			p.draining = false
+			p.mu.Unlock()
+			return
+		}
+		now := time.Now()
+		at := p.slot(now)
+		if !at.After(now) {
+			t := p.pop()
+			p.book(now)
+			close(t.ready)
+			p.mu.Unlock()
+			continue
+		}
+		p.mu.Unlock()
+
+		// A message cancelled meanwhile just leaves the slot to the next one
+		time.Sleep(at.Sub(now))
+	}
+}
+
+// pop removes the waiting message with the highest priority.
+func (p *Pacer) pop() *ticket {
+	best := 0
+	for i, t := range p.queue {
+		if t.lane < p.queue[best].lane || (t.lane == p.queue[best].lane && t.seq < p.queue[best].seq) {
+			best = i
+		}
+	}
+	t := p.queue[best]
+	p.queue = append(p.queue[:best], p.queue[best+1:]...)
+	return t
+}
+
+// Waiting is the number of messages waiting for their turn, for #botstats.
+func (p *Pacer) Waiting() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queue)
+}
@@ -0,0 +1,115 @@
+package wa_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/wa"
+)
+
+// =============================================================================
+// SEND PACING TESTS
+// =============================================================================
+
+func TestPacer_GapAndCap(t *testing.T) {
+	pacer := wa.NewPacer(wa.PacingProfile{PerMinute: 3, MinGap: 2 * time.Second, MaxGap: 2 * time.Second})
+	now := time.Date(2026, 3, 1, 19, 30, 0, 0, time.UTC)
+
+	// Four messages ready at the same time: gaps of 2s, then the cap
+	// holds the fourth until a minute after the first
+	want := []time.Duration{0, 2 * time.Second, 4 * time.Second, time.Minute}
+	for i, w := range want {
+		if got := pacer.Reserve(now); got != w {
+			t.Errorf("Message %d: expected to wait %s, got %s", i+1, w, got)
+		}
+	}
+
+	// After a quiet period a message goes out right away
+	if got := pacer.Reserve(now.Add(5 * time.Minute)); got != 0 {
+		t.Errorf("Expected no wait after a quiet period, got %s", got)
+	}
+}
+
+func TestPacer_SmoothingWidensGaps(t *testing.T) {
+	pacer := wa.NewPacer(wa.PacingProfile{PerMinute: 4, MinGap: 4 * time.Second, MaxGap: 4 * time.Second, Smooth: true})
+	now := time.Date(2026, 3, 1, 19, 30, 0, 0, time.UTC)
+
+	// Gaps grow by a quarter for every send in the last minute
+	want := []time.Duration{0, 5 * time.Second, 11 * time.Second, 18 * time.Second}
+	for i, w := range want {
+		if got := pacer.Reserve(now); got != w {
+			t.Errorf("Message %d: expected to wait %s, got %s", i+1, w, got)
+		}
+	}
+}
+
+func TestPacer_GapIsRandomWithinProfile(t *testing.T) {
+	profile := wa.PacingProfile{MinGap: time.Second, MaxGap: 3 * time.Second}
+	pacer := wa.NewPacer(profile)
+	now := time.Date(2026, 3, 1, 19, 30, 0, 0, time.UTC)
+
+	pacer.Reserve(now)
+	last := time.Duration(0)
+	for i := 0; i < 20; i++ {
+		next := pacer.Reserve(now)
+		if gap := next - last; gap < profile.MinGap || gap > profile.MaxGap {
+			t.Fatalf("Expected a gap between %s and %s, got %s", profile.MinGap, profile.MaxGap, gap)
+		}
+		last = next
+	}
+}
+
+func TestPacer_RepliesJumpTheQueue(t *testing.T) {
+	pacer := wa.NewPacer(wa.PacingProfile{MinGap: 50 * time.Millisecond, MaxGap: 50 * time.Millisecond})
+	ctx := context.Background()
+	bulk := wa.WithLane(ctx, wa.LaneBulk)
+
+	// The first message goes out at once and starts the gap
+	if err := pacer.Wait(bulk); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	send := func(ctx context.Context, name string, queued int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pacer.Wait(ctx); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}()
+		// Queue them in a known order
+		for pacer.Waiting() < queued {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	send(bulk, "reminder1", 1)
+	send(bulk, "reminder2", 2)
+	send(ctx, "welcome", 3)
+	send(wa.WithLane(ctx, wa.LaneReply), "lapor", 4)
+	wg.Wait()
+
+	want := []string{"lapor", "welcome", "reminder1", "reminder2"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, order)
+		}
+	}
+
+	// A cancelled message leaves the queue
+	cancelled, cancel := context.WithCancel(bulk)
+	cancel()
+	if err := pacer.Wait(cancelled); err == nil {
+		t.Error("Expected an error for a cancelled context")
+	}
+	if pacer.Waiting() != 0 {
+		t.Errorf("Expected an empty queue, got %d", pacer.Waiting())
+	}
+}
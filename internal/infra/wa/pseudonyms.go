@@ -0,0 +1,131 @@
+package wa
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// SetUserIDHasher makes the service accept hashed user IDs wherever it
+// takes a phone number: mentions, direct messages, and profile pictures.
+// The number behind a hash is found by hashing the numbers of the
+// participants of the chat mentioned in, or of groups, and is only kept in
+// memory with the group cache.
+func (s *Service) SetUserIDHasher(hasher *phone.Hasher, groups ...string) {
+	s.hasher = hasher
+	s.directory = groups
+}
+
+// phoneOf returns the phone number behind a user ID, which is the ID itself
+// unless it is hashed. ok is false when no participant of chats matches.
+func (s *Service) phoneOf(ctx context.Context, userID string, chats ...string) (string, bool) {
+	if s.hasher == nil || !phone.IsHashed(userID) {
+		return userID, true
+	}
+	for _, chat := range chats {
+		jid, err := types.ParseJID(chat)
+		if err != nil || jid.Server != types.GroupServer {
+			continue
+		}
+		info, err := s.groups.Get(ctx, jid)
+		if err != nil {
+			log.Printf("Failed to look up members of %s: %v", chat, err)
+			continue
+		}
+		for _, p := range info.Participants {
+			if number := s.participantPhone(ctx, p); number != "" && s.hasher.UserID(number) == userID {
+				return number, true
+			}
+		}
+	}
+	return "", false
+}
+
+// participantPhone returns the phone number of a group participant, "" when
+// WhatsApp only shows their LID and no mapping is known.
+func (s *Service) participantPhone(ctx context.Context, p types.GroupParticipant) string {
+	switch {
+	case !p.PhoneNumber.IsEmpty():
+		return p.PhoneNumber.User
+	case p.JID.Server == types.DefaultUserServer:
+		return p.JID.User
+	case p.JID.Server == types.HiddenUserServer && s.GetClient() != nil:
+		if pn, err := s.GetClient().Store.LIDs.GetPNForLID(ctx, p.JID); err == nil {
+			return pn.User
+		}
+	}
+	return ""
+}
+
+// revealMentions replaces hashed user IDs in a mention text and list with
+// the phone numbers WhatsApp needs. Members who are not in the chat are
+// left out of the list, and their "@" is dropped from the text.
+func (s *Service) revealMentions(ctx context.Context, chatJID, text string, userIDs []string) (string, []string) {
+	if s.hasher == nil {
+		return text, userIDs
+	}
+	phones := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		number, ok := s.phoneOf(ctx, userID, chatJID)
+		if !ok {
+			text = strings.ReplaceAll(text, "@"+userID, "member")
+			continue
+		}
+		text = strings.ReplaceAll(text, "@"+userID, "@"+number)
+		phones = append(phones, number)
+	}
+	return text, phones
+}
+
+// ResolveLIDToPhone returns the phone number WhatsApp mapped a LID to, or
+// the LID itself when no mapping is known. It reads the session, which may
+// be in another file than the bot's data (WA_SESSION_PATH).
+func (s *Service) ResolveLIDToPhone(ctx context.Context, lid string) string {
+	if s.container == nil {
+		return lid
+	}
+	pn, err := s.container.LIDMap.GetPNForLID(ctx, types.NewJID(lid, types.HiddenUserServer))
+	if err != nil || pn.IsEmpty() {
+		return lid
+	}
+	return pn.User
+}
+
+// DeleteLIDMappings removes the session's LID mappings of a user, given as
+// phone number or hashed user ID, so they can no longer be linked to their
+// LID.
+func (s *Service) DeleteLIDMappings(ctx context.Context, userID string) error {
+	if s.sessionDB == nil {
+		return nil
+	}
+	numbers := []string{userID}
+	if s.hasher != nil && phone.IsHashed(userID) {
+		rows, err := s.sessionDB.QueryContext(ctx, `SELECT DISTINCT pn FROM whatsmeow_lid_map`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		numbers = nil
+		for rows.Next() {
+			var number string
+			if err := rows.Scan(&number); err != nil {
+				return err
+			}
+			if s.hasher.UserID(number) == userID {
+				numbers = append(numbers, number)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	for _, number := range numbers {
+		if _, err := s.sessionDB.ExecContext(ctx, `DELETE FROM whatsmeow_lid_map WHERE pn = ?`, number); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,35 +2,142 @@ package wa
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
 	"github.com/fardannozami/whatsapp-gateway/internal/infra/supabase"
 	"github.com/mdp/qrterminal"
 	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/store/sqlstore"
+	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 	walog "go.mau.fi/whatsmeow/util/log"
+	"google.golang.org/protobuf/proto"
 	_ "modernc.org/sqlite"
 )
 
+// ErrSessionLost is returned for sends while the device is unlinked or the
+// number is banned, until the bot is paired again.
+var ErrSessionLost = errors.New("whatsapp session lost")
+
 type Service struct {
-	client         *whatsmeow.Client
+	client         atomic.Pointer[whatsmeow.Client] // Swapped by Repair
+	container      *sqlstore.Container
+	sessionDB      *sql.DB // The database of container, for the LID map
 	dbBasePath     string
 	log            walog.Logger
 	messageHandler func(ctx context.Context, client *whatsmeow.Client, evt *events.Message)
+	connHandler    func(ctx context.Context, evt domain.ConnectionEvent)
+	joinHandler    func(ctx context.Context, group types.JID, members []types.JID)
+	historyHandler func(ctx context.Context, group types.JID, messages []*events.Message)
+	groups         *GroupCache
+	pacer          *Pacer
+	presence       PresencePolicy
+	maxLength      int
+	outbox         domain.OutboxRepository
 	supabaseURL    string
 	supabaseKey    string
+	hasher         *phone.Hasher // User IDs are hashed phone numbers, nil = plain numbers
+	directory      []string      // Groups whose participants hashed IDs are looked up in
+
+	mu      sync.Mutex
+	problem string // Why the session cannot send, empty while healthy
+
+	historyMu sync.Mutex // History chunks are handled one at a time
+	repairMu  sync.Mutex // One Repair at a time
+
+	connEvents chan domain.ConnectionEvent // Handed to connHandler in order
+	connOnce   sync.Once
 }
 
+// defaultGroupCacheTTL is how long group metadata is reused when no
+// GroupInfo event invalidates it earlier.
+const defaultGroupCacheTTL = time.Hour
+
+// defaultMaxMessageLength keeps texts well under what WhatsApp clients
+// show without trouble.
+const defaultMaxMessageLength = 4000
+
 func NewService(dbBasePath string, logger walog.Logger, supabaseURL, supabaseKey string) *Service {
-	return &Service{
+	s := &Service{
 		dbBasePath:  dbBasePath,
 		log:         logger,
 		supabaseURL: supabaseURL,
 		supabaseKey: supabaseKey,
+		pacer:       NewPacer(PacingProfile{}),
+		maxLength:   defaultMaxMessageLength,
 	}
+	s.groups = NewGroupCache(defaultGroupCacheTTL, func(ctx context.Context, jid types.JID) (*types.GroupInfo, error) {
+		return s.GetClient().GetGroupInfo(ctx, jid)
+	})
+	return s
+}
+
+// SetGroupCacheTTL changes how long group metadata is cached.
+func (s *Service) SetGroupCacheTTL(ttl time.Duration) {
+	s.groups.SetTTL(ttl)
+}
+
+// SetPacing paces every outgoing message with profile.
+func (s *Service) SetPacing(profile PacingProfile) {
+	s.pacer = NewPacer(profile)
+}
+
+// SetMaxMessageLength splits outgoing texts and captions longer than n
+// characters into several messages. 0 sends them whole.
+func (s *Service) SetMaxMessageLength(n int) {
+	s.maxLength = n
+}
+
+// SetOutbox records every sent message and its delivery and read receipts.
+func (s *Service) SetOutbox(outbox domain.OutboxRepository) {
+	s.outbox = outbox
+}
+
+// PresencePolicy decides when the bot shows as online, e.g. a
+// humanize.PresenceSchedule.
+type PresencePolicy interface {
+	Online(t time.Time) bool
+}
+
+// SetPresenceSchedule shows the bot as online only when presence says so.
+// Without a schedule presence is left to WhatsApp.
+func (s *Service) SetPresenceSchedule(presence PresencePolicy) {
+	s.presence = presence
+}
+
+// UpdatePresence sends available or unavailable presence, whichever the
+// schedule says for now. It does nothing without a schedule.
+func (s *Service) UpdatePresence(ctx context.Context) error {
+	if s.presence == nil {
+		return nil
+	}
+	state := types.PresenceUnavailable
+	if s.presence.Online(time.Now()) {
+		state = types.PresenceAvailable
+	}
+	return s.GetClient().SendPresence(ctx, state)
+}
+
+// Pacer returns the pacer outgoing messages wait on.
+func (s *Service) Pacer() *Pacer {
+	return s.pacer
+}
+
+// GroupInfo returns the subject and participants of a group, served from
+// the cache when fresh.
+func (s *Service) GroupInfo(ctx context.Context, jid types.JID) (*types.GroupInfo, error) {
+	return s.groups.Get(ctx, jid)
 }
 
 func (s *Service) Initialize(ctx context.Context) error {
@@ -39,11 +146,15 @@ func (s *Service) Initialize(ctx context.Context) error {
 
 	// Always initialize SQLite container first
 	dbAddress := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", s.dbBasePath)
-	var err error
-	sqlContainer, err = sqlstore.New(context.Background(), "sqlite", dbAddress, s.log)
+	db, err := sql.Open("sqlite", dbAddress)
 	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	sqlContainer = sqlstore.NewWithDB(db, "sqlite", s.log)
+	if err := sqlContainer.Upgrade(context.Background()); err != nil {
 		return fmt.Errorf("failed to initialize database: %w", err)
 	}
+	s.sessionDB = db
 
 	// Try to get device from SQLite first
 	sqliteDevices, err := sqlContainer.GetAllDevices(context.Background())
@@ -80,25 +191,28 @@ func (s *Service) Initialize(ctx context.Context) error {
 	}
 
 	// Initialize client
-	s.client = whatsmeow.NewClient(device, s.log)
-	s.registerEventHandlers()
+	s.container = sqlContainer
+	client := whatsmeow.NewClient(device, s.log)
+	s.registerEventHandlers(client)
+	s.client.Store(client)
 
 	return nil
 }
 
 func (s *Service) Connect() error {
-	if s.client == nil {
+	client := s.GetClient()
+	if client == nil {
 		return fmt.Errorf("client not initialized")
 	}
-	if s.client.IsConnected() {
+	if client.IsConnected() {
 		return nil
 	}
-	return s.client.Connect()
+	return client.Connect()
 }
 
 func (s *Service) Disconnect() {
-	if s.client != nil {
-		s.client.Disconnect()
+	if client := s.GetClient(); client != nil {
+		client.Disconnect()
 	}
 }
 
@@ -106,15 +220,87 @@ func (s *Service) SetMessageHandler(handler func(ctx context.Context, client *wh
 	s.messageHandler = handler
 }
 
-func (s *Service) registerEventHandlers() {
-	s.client.AddEventHandler(func(evt interface{}) {
+// SetConnectionHandler is called whenever the session connects, disconnects,
+// is logged out, or gets paired.
+func (s *Service) SetConnectionHandler(handler func(ctx context.Context, evt domain.ConnectionEvent)) {
+	s.connHandler = handler
+}
+
+// SetMemberJoinHandler is called when people join or are added to a group
+// the bot is in.
+func (s *Service) SetMemberJoinHandler(handler func(ctx context.Context, group types.JID, members []types.JID)) {
+	s.joinHandler = handler
+}
+
+// SetHistoryHandler is called with the group messages the phone syncs to
+// the bot after linking. It also asks the phone for the full history
+// instead of the last few months, which only takes effect when pairing.
+func (s *Service) SetHistoryHandler(handler func(ctx context.Context, group types.JID, messages []*events.Message)) {
+	s.historyHandler = handler
+	store.DeviceProps.RequireFullSync = proto.Bool(true)
+}
+
+// handleHistory passes the group conversations of a history sync chunk to
+// the history handler.
+func (s *Service) handleHistory(client *whatsmeow.Client, evt *events.HistorySync) {
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	conversations := evt.Data.GetConversations()
+	s.log.Infof("History sync %s: %d conversations", evt.Data.GetSyncType(), len(conversations))
+	for _, conv := range conversations {
+		chat, err := types.ParseJID(conv.GetID())
+		if err != nil || chat.Server != types.GroupServer {
+			continue
+		}
+		var messages []*events.Message
+		for _, hist := range conv.GetMessages() {
+			msg, err := client.ParseWebMessage(chat, hist.GetMessage())
+			if err != nil {
+				continue
+			}
+			messages = append(messages, msg)
+		}
+		if len(messages) > 0 {
+			s.historyHandler(context.Background(), chat, messages)
+		}
+	}
+}
+
+// emitConnection queues evt for the connection handler. One goroutine
+// delivers the events, so a disconnect is never handled before the connect
+// that preceded it.
+func (s *Service) emitConnection(evt domain.ConnectionEvent) {
+	if s.connHandler == nil {
+		return
+	}
+	s.connOnce.Do(func() {
+		s.connEvents = make(chan domain.ConnectionEvent, 64)
+		go func() {
+			for evt := range s.connEvents {
+				s.connHandler(context.Background(), evt)
+			}
+		}()
+	})
+	s.connEvents <- evt
+}
+
+func (s *Service) registerEventHandlers(client *whatsmeow.Client) {
+	client.AddEventHandler(func(evt interface{}) {
 		switch v := evt.(type) {
 		case *events.Message:
 			if s.messageHandler != nil {
-				go s.messageHandler(context.Background(), s.client, v)
+				go s.messageHandler(context.Background(), client, v)
 			}
 		case *events.Connected:
 			s.log.Infof("WhatsApp connected successfully")
+			s.setProblem("")
+			s.emitConnection(domain.ConnectionConnected)
+			go func() {
+				if err := s.UpdatePresence(context.Background()); err != nil {
+					s.log.Warnf("Failed to send presence: %v", err)
+				}
+			}()
 			// Temporarily disable auto-save to test manual backup
 			s.log.Infof("Auto-save to Supabase temporarily disabled for testing")
 		// TODO: Re-enable after fixing duplicate key issue
@@ -133,18 +319,561 @@ func (s *Service) registerEventHandlers() {
 				}()
 			}
 		*/
+		case *events.Disconnected:
+			s.log.Warnf("WhatsApp disconnected")
+			s.emitConnection(domain.ConnectionDisconnected)
 		case *events.LoggedOut:
-			s.log.Infof("WhatsApp logged out")
+			problem := "logged out"
+			if v.OnConnect {
+				problem += ": " + v.Reason.String()
+			}
+			s.log.Errorf("WhatsApp %s", problem)
+			s.setProblem(problem)
+			// WhatsApp Web calls 406 BANNED
+			if v.Reason == events.ConnectFailureUnknownLogout {
+				s.emitConnection(domain.ConnectionBanned)
+			} else {
+				s.emitConnection(domain.ConnectionLoggedOut)
+			}
+		case *events.TemporaryBan:
+			s.log.Errorf("WhatsApp account banned: %s", v)
+			s.setProblem("temporarily banned: " + v.String())
+			s.emitConnection(domain.ConnectionBanned)
+		case *events.ClientOutdated:
+			s.log.Errorf("WhatsApp rejected this client version, update whatsmeow")
+			s.setProblem("client outdated")
+		case *events.StreamError:
+			s.log.Warnf("WhatsApp stream error %s", v.Code)
+		case *events.StreamReplaced:
+			s.log.Warnf("WhatsApp session replaced by another client")
+			s.setProblem("session replaced by another client")
+			s.emitConnection(domain.ConnectionReplaced)
+		case *events.GroupInfo:
+			// Subject, participant or settings change
+			s.groups.Invalidate(v.JID)
+			if len(v.Join) > 0 && s.joinHandler != nil {
+				go s.joinHandler(context.Background(), v.JID, v.Join)
+			}
+		case *events.HistorySync:
+			if s.historyHandler != nil {
+				go s.handleHistory(client, v)
+			}
+		case *events.Receipt:
+			go s.recordReceipt(v)
+		case *events.JoinedGroup:
+			s.groups.Put(&v.GroupInfo)
+		case *events.PairSuccess:
+			s.log.Infof("WhatsApp paired as %s", v.ID)
+			s.setProblem("")
+			s.emitConnection(domain.ConnectionPaired)
 		}
 	})
 }
 
+func (s *Service) setProblem(problem string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.problem = problem
+}
+
+func (s *Service) sessionProblem() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.problem
+}
+
+// Ready returns nil when the bot is logged in and connected, for /readyz,
+// and otherwise why it is not.
+func (s *Service) Ready() error {
+	if problem := s.sessionProblem(); problem != "" {
+		return fmt.Errorf("%w: %s", ErrSessionLost, problem)
+	}
+	client := s.GetClient()
+	if client == nil || client.Store.ID == nil {
+		return errors.New("not paired")
+	}
+	if !client.IsConnected() {
+		return errors.New("disconnected")
+	}
+	return nil
+}
+
+// Repair starts pairing a new device after the old one was unlinked,
+// without restarting the bot. With a phone number it returns the code to
+// enter under Linked Devices > Link with phone number; without one it
+// returns the first QR code, which is also printed to the terminal.
+func (s *Service) Repair(ctx context.Context, phone string) (string, error) {
+	s.repairMu.Lock()
+	defer s.repairMu.Unlock()
+
+	if s.IsLoggedIn() {
+		if s.sessionProblem() == "" {
+			return "", fmt.Errorf("already logged in")
+		}
+		// Banned or replaced, but the device is still there: WhatsApp
+		// decides when it may connect again
+		return "", fmt.Errorf("device is still linked (%s), reconnect after the ban or restart the bot", s.sessionProblem())
+	}
+
+	// whatsmeow deleted the unlinked device, pair a new one
+	client := whatsmeow.NewClient(s.container.NewDevice(), s.log)
+	s.registerEventHandlers(client)
+	s.client.Swap(client).Disconnect()
+
+	if phone != "" {
+		if err := client.Connect(); err != nil {
+			return "", fmt.Errorf("failed to connect for pairing: %w", err)
+		}
+		return s.Pair(phone)
+	}
+
+	qrChan, err := client.GetQRChannel(context.Background())
+	if err != nil {
+		return "", err
+	}
+	if err := client.Connect(); err != nil {
+		return "", fmt.Errorf("failed to connect for QR: %w", err)
+	}
+	first := make(chan string, 1)
+	go func() {
+		for evt := range qrChan {
+			if evt.Event == "code" {
+				qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, os.Stdout)
+				select {
+				case first <- evt.Code:
+				default:
+				}
+			} else {
+				s.log.Infof("Login event: %s", evt.Event)
+			}
+		}
+		close(first)
+	}()
+
+	select {
+	case code, ok := <-first:
+		if !ok {
+			return "", fmt.Errorf("pairing ended before a QR code was issued")
+		}
+		return code, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
 func (s *Service) GetClient() *whatsmeow.Client {
-	return s.client
+	return s.client.Load()
+}
+
+// send is the only place messages leave the bot, so all of them are paced.
+// Without a session they fail right away instead of waiting for a timeout.
+func (s *Service) send(ctx context.Context, to types.JID, msg *waE2E.Message) (string, error) {
+	if problem := s.sessionProblem(); problem != "" {
+		return "", fmt.Errorf("%w: %s", ErrSessionLost, problem)
+	}
+	if err := s.pacer.Wait(ctx); err != nil {
+		return "", err
+	}
+	resp, err := s.GetClient().SendMessage(ctx, to, msg, sendExtra(ctx)...)
+	if err != nil {
+		return "", err
+	}
+
+	if s.outbox != nil {
+		sent := &domain.OutboxMessage{MessageID: resp.ID, ChatID: to.String(), Text: outboxText(msg), SentAt: resp.Timestamp}
+		if err := s.outbox.RecordSent(ctx, sent); err != nil {
+			s.log.Warnf("Failed to record sent message %s: %v", resp.ID, err)
+		}
+	}
+	return resp.ID, nil
+}
+
+// outboxTextLength is how much of a message the outbox keeps, enough to
+// recognize it.
+const outboxTextLength = 200
+
+func outboxText(msg *waE2E.Message) string {
+	text := msg.GetConversation()
+	switch {
+	case msg.GetExtendedTextMessage() != nil:
+		text = msg.GetExtendedTextMessage().GetText()
+	case msg.GetImageMessage() != nil:
+		text = msg.GetImageMessage().GetCaption()
+	case msg.GetDocumentMessage() != nil:
+		text = msg.GetDocumentMessage().GetFileName()
+	}
+	if runes := []rune(text); len(runes) > outboxTextLength {
+		text = string(runes[:outboxTextLength])
+	}
+	return text
+}
+
+// recordReceipt counts receipts for the bot's own messages. Receipts from
+// the bot's other devices (read-self) and retry requests are not news.
+func (s *Service) recordReceipt(evt *events.Receipt) {
+	if s.outbox == nil || evt.IsFromMe {
+		return
+	}
+	var read bool
+	switch evt.Type {
+	case types.ReceiptTypeDelivered:
+	case types.ReceiptTypeRead, types.ReceiptTypePlayed:
+		read = true
+	default:
+		return
+	}
+	if err := s.outbox.RecordReceipt(context.Background(), evt.MessageIDs, read, evt.Timestamp); err != nil {
+		s.log.Warnf("Failed to record receipt: %v", err)
+	}
+}
+
+// sendTexts sends the parts of a split text one after the other, so they
+// arrive in order.
+func (s *Service) sendTexts(ctx context.Context, to types.JID, parts []string) error {
+	for _, part := range parts {
+		if _, err := s.send(ctx, to, &waE2E.Message{
+			Conversation: proto.String(part),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitCaption returns the part of caption sent with the media and the
+// parts that follow as text. A long caption of only blank lines splits into
+// nothing and is dropped.
+func (s *Service) splitCaption(caption string) (string, []string) {
+	captions := SplitText(caption, s.maxLength)
+	if len(captions) == 0 {
+		return "", nil
+	}
+	return captions[0], captions[1:]
+}
+
+// SendText sends a plain text message to a chat, split into several when it
+// is longer than the maximum message length.
+func (s *Service) SendText(ctx context.Context, to types.JID, text string) error {
+	return s.sendTexts(ctx, to, SplitText(text, s.maxLength))
+}
+
+// SendImage uploads an image to WhatsApp's media servers and sends it to a
+// chat with an optional caption. The rest of an over-long caption follows
+// as text.
+func (s *Service) SendImage(ctx context.Context, to types.JID, data []byte, mimeType, caption string) error {
+	caption, rest := s.splitCaption(caption)
+
+	uploaded, err := s.GetClient().Upload(ctx, data, whatsmeow.MediaImage)
+	if err != nil {
+		return fmt.Errorf("failed to upload image: %w", err)
+	}
+
+	msg := &waE2E.Message{
+		ImageMessage: &waE2E.ImageMessage{
+			Caption:       proto.String(caption),
+			Mimetype:      proto.String(mimeType),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uploaded.FileLength),
+		},
+	}
+	if _, err := s.send(ctx, to, msg); err != nil {
+		return err
+	}
+	return s.sendTexts(ctx, to, rest)
+}
+
+// SendGIF uploads an MP4 and sends it as a GIF: muted and looping, like the
+// GIFs picked in WhatsApp. The rest of an over-long caption follows as text.
+func (s *Service) SendGIF(ctx context.Context, to types.JID, data []byte, caption string) error {
+	caption, rest := s.splitCaption(caption)
+
+	uploaded, err := s.GetClient().Upload(ctx, data, whatsmeow.MediaVideo)
+	if err != nil {
+		return fmt.Errorf("failed to upload GIF: %w", err)
+	}
+
+	msg := &waE2E.Message{
+		VideoMessage: &waE2E.VideoMessage{
+			Caption:       proto.String(caption),
+			Mimetype:      proto.String("video/mp4"),
+			GifPlayback:   proto.Bool(true),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uploaded.FileLength),
+		},
+	}
+	if _, err := s.send(ctx, to, msg); err != nil {
+		return err
+	}
+	return s.sendTexts(ctx, to, rest)
+}
+
+// SendSticker uploads a WebP image and sends it as a sticker. WhatsApp
+// shows stickers best at 512x512 pixels.
+func (s *Service) SendSticker(ctx context.Context, to types.JID, data []byte) error {
+	uploaded, err := s.GetClient().Upload(ctx, data, whatsmeow.MediaImage)
+	if err != nil {
+		return fmt.Errorf("failed to upload sticker: %w", err)
+	}
+
+	msg := &waE2E.Message{
+		StickerMessage: &waE2E.StickerMessage{
+			Mimetype:      proto.String("image/webp"),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uploaded.FileLength),
+		},
+	}
+	_, err = s.send(ctx, to, msg)
+	return err
+}
+
+// SendMedia sends an MP4 as a GIF and anything else as an image, to a chat
+// given as a JID string.
+func (s *Service) SendMedia(ctx context.Context, chatJID string, data []byte, mimeType, caption string) error {
+	to, err := types.ParseJID(chatJID)
+	if err != nil {
+		return fmt.Errorf("invalid chat JID: %w", err)
+	}
+	if mimeType == "video/mp4" {
+		return s.SendGIF(ctx, to, data, caption)
+	}
+	return s.SendImage(ctx, to, data, mimeType, caption)
+}
+
+// SendDocument uploads a file to WhatsApp's media servers and sends it to a
+// chat as a document attachment. The rest of an over-long caption follows
+// as text.
+func (s *Service) SendDocument(ctx context.Context, to types.JID, data []byte, mimeType, fileName, caption string) error {
+	caption, rest := s.splitCaption(caption)
+
+	uploaded, err := s.GetClient().Upload(ctx, data, whatsmeow.MediaDocument)
+	if err != nil {
+		return fmt.Errorf("failed to upload document: %w", err)
+	}
+
+	msg := &waE2E.Message{
+		DocumentMessage: &waE2E.DocumentMessage{
+			Caption:       proto.String(caption),
+			Title:         proto.String(fileName),
+			FileName:      proto.String(fileName),
+			Mimetype:      proto.String(mimeType),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uploaded.FileLength),
+		},
+	}
+	if _, err := s.send(ctx, to, msg); err != nil {
+		return err
+	}
+	return s.sendTexts(ctx, to, rest)
+}
+
+// SendDocumentTo sends a document to a chat given as a JID string.
+func (s *Service) SendDocumentTo(ctx context.Context, chatJID string, data []byte, mimeType, fileName, caption string) error {
+	to, err := types.ParseJID(chatJID)
+	if err != nil {
+		return fmt.Errorf("invalid chat JID: %w", err)
+	}
+	return s.SendDocument(ctx, to, data, mimeType, fileName, caption)
+}
+
+// MarkRead sends a read receipt (blue ticks) for a received message, as the
+// phone would once someone opened the chat.
+func (s *Service) MarkRead(ctx context.Context, info types.MessageInfo) error {
+	return s.GetClient().MarkRead(ctx, []types.MessageID{info.ID}, time.Now(), info.Chat, info.Sender)
+}
+
+// SendDirect sends a text to a member's private chat by phone number or
+// user ID.
+func (s *Service) SendDirect(ctx context.Context, phone, text string) error {
+	number, ok := s.phoneOf(ctx, phone, s.directory...)
+	if !ok {
+		return fmt.Errorf("no group member matches user %s", phone)
+	}
+	return s.SendText(ctx, types.NewJID(number, types.DefaultUserServer), text)
+}
+
+// SendMention sends a text that mentions the given phone numbers. The text
+// should contain "@<phone>" for each of them so WhatsApp highlights them.
+func (s *Service) SendMention(ctx context.Context, chatJID, text string, phones []string) error {
+	_, err := s.SendMentionIDs(ctx, chatJID, text, phones)
+	return err
+}
+
+// SendMentionIDs is SendMention that returns the IDs of the parts sent, so
+// answers to the message can be traced back to it.
+func (s *Service) SendMentionIDs(ctx context.Context, chatJID, text string, phones []string) ([]string, error) {
+	to, err := types.ParseJID(chatJID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chat JID: %w", err)
+	}
+	text, phones = s.revealMentions(ctx, chatJID, text, phones)
+
+	// Each part only mentions the members it names
+	var ids []string
+	for _, part := range SplitText(text, s.maxLength) {
+		var mentioned []string
+		for _, phone := range phones {
+			if strings.Contains(part, "@"+phone) {
+				mentioned = append(mentioned, types.NewJID(phone, types.DefaultUserServer).String())
+			}
+		}
+		id, err := s.send(ctx, to, &waE2E.Message{
+			ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+				Text:        proto.String(part),
+				ContextInfo: &waE2E.ContextInfo{MentionedJID: mentioned},
+			},
+		})
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// JoinGroup joins a group via an invite link, or looks up a group the bot is
+// already a member of when given a group JID (…@g.us).
+func (s *Service) JoinGroup(ctx context.Context, linkOrJID string) (string, string, error) {
+	var jid types.JID
+	if strings.HasSuffix(linkOrJID, "@"+types.GroupServer) {
+		parsed, err := types.ParseJID(linkOrJID)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid group JID: %w", err)
+		}
+		jid = parsed
+	} else {
+		joined, err := s.GetClient().JoinGroupWithLink(ctx, strings.TrimPrefix(linkOrJID, whatsmeow.InviteLinkPrefix))
+		if err != nil {
+			return "", "", err
+		}
+		jid = joined
+	}
+
+	info, err := s.groups.Get(ctx, jid)
+	if err != nil {
+		return "", "", err
+	}
+	return jid.String(), info.Name, nil
+}
+
+// JoinedGroups lists every group the linked account is a member of.
+func (s *Service) JoinedGroups(ctx context.Context) ([]domain.JoinedGroup, error) {
+	infos, err := s.GetClient().GetJoinedGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]domain.JoinedGroup, 0, len(infos))
+	for _, info := range infos {
+		s.groups.Put(info)
+		groups = append(groups, domain.JoinedGroup{
+			JID:          info.JID.String(),
+			Name:         info.Name,
+			Participants: len(info.Participants),
+		})
+	}
+	return groups, nil
+}
+
+// LeaveGroup posts farewell to the group, if not empty, and then leaves it.
+func (s *Service) LeaveGroup(ctx context.Context, groupJID, farewell string) error {
+	jid, err := types.ParseJID(groupJID)
+	if err != nil {
+		return fmt.Errorf("invalid group JID: %w", err)
+	}
+
+	if farewell != "" {
+		if err := s.SendText(ctx, jid, farewell); err != nil {
+			return fmt.Errorf("failed to send farewell: %w", err)
+		}
+	}
+	if err := s.GetClient().LeaveGroup(ctx, jid); err != nil {
+		return err
+	}
+	s.groups.Invalidate(jid)
+	return nil
+}
+
+// GroupMembers returns the phone numbers of a group's participants, hashed
+// like user IDs when SetUserIDHasher was called. In groups that hide
+// numbers behind LIDs the number WhatsApp shares is used, falling back to
+// the LID.
+func (s *Service) GroupMembers(ctx context.Context, groupJID string) ([]string, error) {
+	jid, err := types.ParseJID(groupJID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group JID: %w", err)
+	}
+	info, err := s.groups.Get(ctx, jid)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]string, 0, len(info.Participants))
+	for _, p := range info.Participants {
+		if p.JID.Server == types.HiddenUserServer && !p.PhoneNumber.IsEmpty() {
+			members = append(members, s.hasher.UserID(p.PhoneNumber.User))
+		} else {
+			members = append(members, s.hasher.UserID(p.JID.User))
+		}
+	}
+	return members, nil
+}
+
+// AvatarURL returns the profile picture URL of a phone number, or "" when
+// the user has none or hides it from the bot.
+func (s *Service) AvatarURL(ctx context.Context, phone string) (string, error) {
+	number, ok := s.phoneOf(ctx, phone, s.directory...)
+	if !ok {
+		return "", nil
+	}
+	info, err := s.GetClient().GetProfilePictureInfo(ctx, types.NewJID(number, types.DefaultUserServer), &whatsmeow.GetProfilePictureParams{})
+	if errors.Is(err, whatsmeow.ErrProfilePictureNotSet) || errors.Is(err, whatsmeow.ErrProfilePictureUnauthorized) {
+		return "", nil
+	}
+	if err != nil || info == nil {
+		return "", err
+	}
+	return info.URL, nil
+}
+
+// AdminNotifier DMs a fixed list of phone numbers.
+type AdminNotifier struct {
+	service *Service
+	phones  []string
+}
+
+func NewAdminNotifier(service *Service, phones []string) *AdminNotifier {
+	return &AdminNotifier{service: service, phones: phones}
+}
+
+func (n *AdminNotifier) Notify(ctx context.Context, event, text string) error {
+	var firstErr error
+	for _, phone := range n.phones {
+		to := types.NewJID(phone, types.DefaultUserServer)
+		if err := n.service.SendText(ctx, to, text); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to notify %s: %w", phone, err)
+		}
+	}
+	return firstErr
 }
 
 func (s *Service) IsLoggedIn() bool {
-	return s.client.Store.ID != nil
+	return s.GetClient().Store.ID != nil
 }
 
 func (s *Service) Pair(phone string) (string, error) {
@@ -153,12 +882,12 @@ func (s *Service) Pair(phone string) (string, error) {
 	}
 
 	// Ensure connected before pairing
-	if !s.client.IsConnected() {
+	if !s.GetClient().IsConnected() {
 		return "", fmt.Errorf("client not connected")
 	}
 
 	// PairPhone(phone, showPushNotification, clientType, clientDisplayName)
-	code, err := s.client.PairPhone(context.Background(), phone, true, whatsmeow.PairClientChrome, "Chrome (Linux)")
+	code, err := s.GetClient().PairPhone(context.Background(), phone, true, whatsmeow.PairClientChrome, "Chrome (Linux)")
 	if err != nil {
 		return "", err
 	}
@@ -167,9 +896,10 @@ func (s *Service) Pair(phone string) (string, error) {
 }
 
 func (s *Service) PrintQR() {
-	if s.client.Store.ID == nil {
-		qrChan, _ := s.client.GetQRChannel(context.Background())
-		err := s.client.Connect()
+	client := s.GetClient()
+	if client.Store.ID == nil {
+		qrChan, _ := client.GetQRChannel(context.Background())
+		err := client.Connect()
 		if err != nil {
 			fmt.Println("Failed to connect for QR:", err)
 			return
@@ -190,11 +920,12 @@ func (s *Service) SaveDeviceToSupabase(ctx context.Context) error {
 		return fmt.Errorf("supabase credentials not provided")
 	}
 
-	if s.client == nil {
+	client := s.GetClient()
+	if client == nil {
 		return fmt.Errorf("client is nil")
 	}
 
-	if s.client.Store == nil {
+	if client.Store == nil {
 		return fmt.Errorf("client store is nil")
 	}
 
@@ -203,7 +934,7 @@ func (s *Service) SaveDeviceToSupabase(ctx context.Context) error {
 		return fmt.Errorf("failed to create supabase container: %w", err)
 	}
 
-	err = supabaseContainer.PutDevice(ctx, s.client.Store)
+	err = supabaseContainer.PutDevice(ctx, client.Store)
 	if err != nil {
 		return fmt.Errorf("failed to save device to supabase: %w", err)
 	}
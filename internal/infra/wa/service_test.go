@@ -0,0 +1,106 @@
+package wa_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/wa"
+	walog "go.mau.fi/whatsmeow/util/log"
+)
+
+// =============================================================================
+// REPAIR TESTS
+// =============================================================================
+
+func TestService_RepairSwapsClientSafely(t *testing.T) {
+	// Point the websocket at a closed port so Connect fails right away
+	t.Setenv("HTTPS_PROXY", "http://127.0.0.1:1")
+	t.Setenv("HTTP_PROXY", "http://127.0.0.1:1")
+
+	service := wa.NewService(filepath.Join(t.TempDir(), "wa.db"), walog.Noop, "", "")
+	if err := service.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	before := service.GetClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Readers keep using the client while two repairs replace it
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = service.Ready()
+				_ = service.IsLoggedIn()
+				if service.GetClient() == nil {
+					t.Error("Expected a client at all times")
+					return
+				}
+			}
+		}
+	}()
+
+	var repairs sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		repairs.Add(1)
+		go func() {
+			defer repairs.Done()
+			if _, err := service.Repair(ctx, ""); err == nil {
+				t.Error("Expected Repair to fail without a connection")
+			}
+		}()
+	}
+	repairs.Wait()
+	close(done)
+	readers.Wait()
+
+	if service.GetClient() == before {
+		t.Error("Expected Repair to replace the client")
+	}
+}
+
+// =============================================================================
+// SESSION LID MAP TESTS
+// =============================================================================
+
+func TestService_LIDMappingsInSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.db")
+	service := wa.NewService(path, walog.Noop, "", "")
+	ctx := context.Background()
+	if err := service.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open session: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `INSERT INTO whatsmeow_lid_map (lid, pn) VALUES ('111', '628111'), ('222', '628222')`); err != nil {
+		t.Fatalf("Failed to insert mappings: %v", err)
+	}
+
+	hasher := phone.NewHasher("rahasia")
+	service.SetUserIDHasher(hasher)
+	if err := service.DeleteLIDMappings(ctx, hasher.UserID("628111")); err != nil {
+		t.Fatalf("DeleteLIDMappings failed: %v", err)
+	}
+	if got := service.ResolveLIDToPhone(ctx, "111"); got != "111" {
+		t.Errorf("Expected the mapping of the hashed member deleted, got %q", got)
+	}
+	if got := service.ResolveLIDToPhone(ctx, "222"); got != "628222" {
+		t.Errorf("Expected other mappings kept, got %q", got)
+	}
+}
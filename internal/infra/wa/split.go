@@ -0,0 +1,65 @@
+package wa
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SplitText breaks text into parts of at most max characters, at line
+// boundaries where possible, so long leaderboards and recaps arrive as a
+// few messages instead of being rejected. A single line longer than max is
+// broken at its last space, or hard when it has none. max <= 0 keeps the
+// text whole.
+func SplitText(text string, max int) []string {
+	if max <= 0 || utf8.RuneCountInString(text) <= max {
+		return []string{text}
+	}
+
+	var parts []string
+	var current []string
+	size := 0
+	flush := func() {
+		if part := strings.TrimRight(strings.Join(current, "\n"), "\n"); part != "" {
+			parts = append(parts, part)
+		}
+		current, size = nil, 0
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		for _, piece := range splitLine(line, max) {
+			n := utf8.RuneCountInString(piece)
+			if len(current) > 0 && size+1+n > max {
+				flush()
+			}
+			// A part never starts with blank lines
+			if len(current) == 0 && strings.TrimSpace(piece) == "" {
+				continue
+			}
+			if len(current) > 0 {
+				size++
+			}
+			current = append(current, piece)
+			size += n
+		}
+	}
+	flush()
+	return parts
+}
+
+// splitLine breaks a single line into pieces of at most max characters.
+func splitLine(line string, max int) []string {
+	var pieces []string
+	for utf8.RuneCountInString(line) > max {
+		runes := []rune(line)
+		cut := max
+		for i := max; i > 0; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		pieces = append(pieces, strings.TrimRight(string(runes[:cut]), " "))
+		line = strings.TrimLeft(string(runes[cut:]), " ")
+	}
+	return append(pieces, line)
+}
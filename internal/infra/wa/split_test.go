@@ -0,0 +1,62 @@
+package wa_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/wa"
+)
+
+// =============================================================================
+// MESSAGE SPLITTING TESTS
+// =============================================================================
+
+func TestSplitText_AtLineBoundaries(t *testing.T) {
+	text := "🏆 Leaderboard\n1. Alice - 30\n\n2. Bob - 28\n3. Citra - 27"
+
+	if parts := wa.SplitText(text, 0); len(parts) != 1 || parts[0] != text {
+		t.Errorf("Expected the text whole without a limit, got %q", parts)
+	}
+	if parts := wa.SplitText(text, 100); len(parts) != 1 || parts[0] != text {
+		t.Errorf("Expected a short text whole, got %q", parts)
+	}
+
+	parts := wa.SplitText(text, 28)
+	// Blank lines at a break are dropped
+	want := []string{"🏆 Leaderboard\n1. Alice - 30", "2. Bob - 28\n3. Citra - 27"}
+	if len(parts) != len(want) {
+		t.Fatalf("Expected %q, got %q", want, parts)
+	}
+	for i := range want {
+		if parts[i] != want[i] {
+			t.Errorf("Part %d: expected %q, got %q", i+1, want[i], parts[i])
+		}
+	}
+}
+
+func TestSplitText_OnlyBlankLines(t *testing.T) {
+	// Media captions must not assume there is a first part
+	if parts := wa.SplitText(strings.Repeat("\n", 30), 10); len(parts) != 0 {
+		t.Errorf("Expected no parts for blank lines, got %q", parts)
+	}
+}
+
+func TestSplitText_LongLines(t *testing.T) {
+	words := strings.Repeat("semangat ", 10)
+	parts := wa.SplitText(strings.TrimSpace(words), 20)
+	for _, part := range parts {
+		if utf8.RuneCountInString(part) > 20 || strings.HasPrefix(part, " ") || strings.HasSuffix(part, " ") {
+			t.Errorf("Expected parts of whole words up to 20 characters, got %q", part)
+		}
+	}
+	if joined := strings.Join(parts, " "); joined != strings.TrimSpace(words) {
+		t.Errorf("Expected no words lost, got %q", joined)
+	}
+
+	// No space to break at
+	parts = wa.SplitText(strings.Repeat("a", 25), 10)
+	if len(parts) != 3 || parts[0] != strings.Repeat("a", 10) || parts[2] != strings.Repeat("a", 5) {
+		t.Errorf("Expected a hard break every 10 characters, got %q", parts)
+	}
+}
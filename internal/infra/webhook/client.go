@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Payload is the JSON body posted to the webhook.
+type Payload struct {
+	Event string    `json:"event"`
+	Text  string    `json:"text"`
+	Time  time.Time `json:"time"`
+}
+
+// Client posts bot events as JSON to a configured URL, e.g. a Slack/Discord
+// relay or an uptime monitor.
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+func NewClient(url string) *Client {
+	return &Client{url: url, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *Client) Notify(ctx context.Context, event, text string) error {
+	body, err := json.Marshal(Payload{Event: event, Text: text, Time: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
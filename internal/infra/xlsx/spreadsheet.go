@@ -0,0 +1,252 @@
+// Package xlsx lays exports out as Excel workbooks.
+package xlsx
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/format"
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+const (
+	sheetStandings = "Klasemen"
+	sheetDaily     = "Harian"
+	sheetTypes     = "Aktivitas"
+)
+
+// Renderer renders the spreadsheet export with one sheet each for the
+// standings, the per-day matrix and the activity types.
+type Renderer struct{}
+
+func NewRenderer() *Renderer {
+	return &Renderer{}
+}
+
+// styles are the cell formats shared by the sheets.
+type styles struct {
+	header   int
+	reported int
+	missed   int
+	percent  int
+	decimal  int
+	total    int
+}
+
+func newStyles(f *excelize.File) (*styles, error) {
+	var s styles
+	oneDecimal := "0.0"
+	border := []excelize.Border{{Type: "bottom", Color: "A1A1AA", Style: 1}}
+	defs := []struct {
+		id    *int
+		style *excelize.Style
+	}{
+		{&s.header, &excelize.Style{
+			Font:      &excelize.Font{Bold: true},
+			Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"F4F4F5"}},
+			Border:    border,
+			Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center", WrapText: true},
+		}},
+		{&s.reported, &excelize.Style{
+			Font:      &excelize.Font{Color: "14532D"},
+			Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"BBF7D0"}},
+			Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center", ShrinkToFit: true},
+		}},
+		{&s.missed, &excelize.Style{
+			Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"F4F4F5"}},
+		}},
+		{&s.percent, &excelize.Style{NumFmt: 9}}, // 0%
+		{&s.decimal, &excelize.Style{CustomNumFmt: &oneDecimal}},
+		{&s.total, &excelize.Style{
+			Font:   &excelize.Font{Bold: true},
+			Border: []excelize.Border{{Type: "top", Color: "A1A1AA", Style: 1}},
+		}},
+	}
+	for _, d := range defs {
+		id, err := f.NewStyle(d.style)
+		if err != nil {
+			return nil, err
+		}
+		*d.id = id
+	}
+	return &s, nil
+}
+
+// RenderSpreadsheet returns the export as an XLSX file.
+func (r *Renderer) RenderSpreadsheet(sheet *usecase.Spreadsheet) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetDocProps(&excelize.DocProperties{Title: sheet.Title, Creator: "lapor-bot"}); err != nil {
+		return nil, err
+	}
+	st, err := newStyles(f)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.SetSheetName("Sheet1", sheetStandings); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{sheetDaily, sheetTypes} {
+		if _, err := f.NewSheet(name); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, write := range []func(*excelize.File, *usecase.Spreadsheet, *styles) error{
+		writeStandings, writeDaily, writeTypes,
+	} {
+		if err := write(f, sheet, st); err != nil {
+			return nil, err
+		}
+	}
+	f.SetActiveSheet(0)
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeStandings fills the standings like #leaderboard, with how many days
+// of the period each member reported.
+func writeStandings(f *excelize.File, sheet *usecase.Spreadsheet, st *styles) error {
+	header := []interface{}{"#", "Nama", "Total hari", "Streak", "Hari lapor", "Persentase"}
+	if err := writeHeader(f, sheetStandings, header, st); err != nil {
+		return err
+	}
+	for i, m := range sheet.Standings {
+		row := i + 2
+		reported := sheet.Members[i].Reported
+		values := []interface{}{m.Rank, m.Name, m.Total, m.Streak, reported, 0.0}
+		if len(sheet.Days) > 0 {
+			values[5] = float64(reported) / float64(len(sheet.Days))
+		}
+		if err := f.SetSheetRow(sheetStandings, cell(1, row), &values); err != nil {
+			return err
+		}
+	}
+	last := len(sheet.Standings) + 1
+	if last > 1 {
+		if err := f.SetCellStyle(sheetStandings, cell(6, 2), cell(6, last), st.percent); err != nil {
+			return err
+		}
+	}
+	if err := f.AutoFilter(sheetStandings, cell(1, 1)+":"+cell(len(header), last), nil); err != nil {
+		return err
+	}
+	return setWidths(f, sheetStandings, 6, 28, 12, 12, 12, 12)
+}
+
+// writeDaily fills one row per member and one column per day, with the
+// activities of the days they reported in green.
+func writeDaily(f *excelize.File, sheet *usecase.Spreadsheet, st *styles) error {
+	header := []interface{}{"Nama"}
+	for _, day := range sheet.Days {
+		header = append(header, fmt.Sprintf("%d %s", day.Day(), format.MonthName(day.Month())[:3]))
+	}
+	header = append(header, "Total")
+	if err := writeHeader(f, sheetDaily, header, st); err != nil {
+		return err
+	}
+
+	perDay := make([]int, len(sheet.Days))
+	for i, m := range sheet.Members {
+		row := i + 2
+		values := []interface{}{m.Name}
+		for _, d := range m.Days {
+			values = append(values, d)
+		}
+		values = append(values, m.Reported)
+		if err := f.SetSheetRow(sheetDaily, cell(1, row), &values); err != nil {
+			return err
+		}
+		for j, d := range m.Days {
+			style := st.missed
+			if d != "" {
+				style = st.reported
+				perDay[j]++
+			}
+			if err := f.SetCellStyle(sheetDaily, cell(j+2, row), cell(j+2, row), style); err != nil {
+				return err
+			}
+		}
+	}
+
+	totals := []interface{}{"Jumlah lapor"}
+	for _, n := range perDay {
+		totals = append(totals, n)
+	}
+	row := len(sheet.Members) + 2
+	if err := f.SetSheetRow(sheetDaily, cell(1, row), &totals); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheetDaily, cell(1, row), cell(len(header), row), st.total); err != nil {
+		return err
+	}
+
+	// Keep the names and dates in view while scrolling
+	if err := f.SetPanes(sheetDaily, &excelize.Panes{Freeze: true, XSplit: 1, YSplit: 1, TopLeftCell: "B2", ActivePane: "bottomRight"}); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheetDaily, "A", "A", 28); err != nil {
+		return err
+	}
+	if len(sheet.Days) > 0 {
+		first, _ := excelize.ColumnNumberToName(2)
+		last, _ := excelize.ColumnNumberToName(len(sheet.Days) + 1)
+		if err := f.SetColWidth(sheetDaily, first, last, 10); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTypes fills the activity-type breakdown, most reported first.
+func writeTypes(f *excelize.File, sheet *usecase.Spreadsheet, st *styles) error {
+	header := []interface{}{"Aktivitas", "Laporan", "Member", "Jarak (km)", "Durasi (menit)"}
+	if err := writeHeader(f, sheetTypes, header, st); err != nil {
+		return err
+	}
+	for i, t := range sheet.Types {
+		values := []interface{}{t.Label, t.Reports, t.Members, t.Km, t.Minutes}
+		if err := f.SetSheetRow(sheetTypes, cell(1, i+2), &values); err != nil {
+			return err
+		}
+	}
+	if len(sheet.Types) > 0 {
+		if err := f.SetCellStyle(sheetTypes, cell(4, 2), cell(4, len(sheet.Types)+1), st.decimal); err != nil {
+			return err
+		}
+	}
+	return setWidths(f, sheetTypes, 24, 12, 12, 14, 16)
+}
+
+func writeHeader(f *excelize.File, name string, header []interface{}, st *styles) error {
+	if err := f.SetSheetRow(name, "A1", &header); err != nil {
+		return err
+	}
+	return f.SetCellStyle(name, "A1", cell(len(header), 1), st.header)
+}
+
+// setWidths sets the width of the columns from A on.
+func setWidths(f *excelize.File, name string, widths ...float64) error {
+	for i, w := range widths {
+		col, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetColWidth(name, col, col, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cell returns the name of a cell, e.g. cell(2, 3) is "B3".
+func cell(col, row int) string {
+	name, _ := excelize.CoordinatesToCellName(col, row)
+	return name
+}
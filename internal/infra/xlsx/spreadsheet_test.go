@@ -0,0 +1,63 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/xlsx"
+)
+
+func TestRenderSpreadsheet(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	sheet := &usecase.Spreadsheet{
+		Title: "Rekap Tantangan 3 Hari",
+		Since: start,
+		Until: start.AddDate(0, 0, 2),
+		Days:  []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2)},
+		Standings: []usecase.BoardMember{
+			{Rank: 1, Name: "Alice", Total: 3, Streak: 3},
+			{Rank: 2, Name: "Bob", Total: 2},
+		},
+		Members: []usecase.SpreadsheetMember{
+			{Name: "Alice", Days: []string{"Lari 🏃", "Lari 🏃", "Yoga 🧘"}, Reported: 3},
+			{Name: "Bob", Days: []string{"Sepeda 🚴", "", "Lari 🏃"}, Reported: 2},
+		},
+		Types: []usecase.TypeTotal{{Label: "Lari 🏃", Reports: 3, Members: 2, Km: 10.5, Minutes: 30}},
+	}
+
+	data, err := xlsx.NewRenderer().RenderSpreadsheet(sheet)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected a workbook: %v", err)
+	}
+	defer f.Close()
+
+	if got := f.GetSheetList(); len(got) != 3 || got[0] != "Klasemen" || got[1] != "Harian" || got[2] != "Aktivitas" {
+		t.Fatalf("Expected the standings, daily and activity sheets, got %v", got)
+	}
+	checks := []struct{ sheet, cell, want string }{
+		{"Klasemen", "B2", "Alice"},
+		{"Klasemen", "F3", "67%"},
+		{"Harian", "B1", "1 Okt"},
+		{"Harian", "B3", "Sepeda 🚴"},
+		{"Harian", "E3", "2"},
+		{"Harian", "C4", "1"},
+		{"Aktivitas", "D2", "10.5"},
+	}
+	for _, c := range checks {
+		got, err := f.GetCellValue(c.sheet, c.cell)
+		if err != nil {
+			t.Fatalf("Failed to read %s!%s: %v", c.sheet, c.cell, err)
+		}
+		if got != c.want {
+			t.Errorf("Expected %s!%s to be %q, got %q", c.sheet, c.cell, c.want, got)
+		}
+	}
+}